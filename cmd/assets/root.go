@@ -0,0 +1,86 @@
+package assets
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/assets"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+func NewAssetsCmd() *cobra.Command {
+	assetsCmd := &cobra.Command{
+		Use:   "assets",
+		Short: "Manage assets of provider releases",
+	}
+	assetsCmd.AddCommand(NewDownloadCmd())
+	return assetsCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewAssetsCmd())
+}
+
+func NewDownloadCmd() *cobra.Command {
+	opts := assets.Options{}
+	asJSON := false
+	downloadCmd := &cobra.Command{
+		Use:   "download <version>",
+		Short: "Download and verify the assets of a release from the configured provider",
+		Long: `Download the assets of the release to a directory, e.g. to re-publish them to another environment.
+
+Checksums are taken from the provider, a checksums.txt or SHA256SUMS asset, or a <name>.sha256 asset.
+Files already downloaded with the same checksum are skipped, and interrupted downloads are resumed.`,
+		Example: `  autoctl assets download 1.2.0
+  autoctl assets download v1.2.0 -p '*linux*' -p checksums.txt -o dist --require-checksum`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			p, err := provider.New(releaseOpts.Provider)
+			if err != nil {
+				return err
+			}
+			d, ok := p.(provider.AssetDownloader)
+			if !ok {
+				return fmt.Errorf("%s does not support downloading release assets", p.Name())
+			}
+			prefix := releaseOpts.TagPrefix
+			if prefix == "" {
+				prefix = "v"
+			}
+			tag := args[0]
+			if !strings.HasPrefix(tag, prefix) {
+				tag = prefix + tag
+			}
+			list, err := assets.Download(d, tag, opts)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				data, err := json.MarshalIndent(list, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			for _, a := range list {
+				_, _ = fmt.Fprintf(w, "%s\t%d\t%s\n", a.Path, a.Size, a.SHA256)
+			}
+			return w.Flush()
+		},
+	}
+	downloadCmd.Flags().StringVarP(&opts.Dir, "output", "o", ".", "directory to download the assets to")
+	downloadCmd.Flags().StringSliceVarP(&opts.Patterns, "pattern", "p", nil, "only download assets matching the glob patterns")
+	downloadCmd.Flags().BoolVar(&opts.RequireChecksum, "require-checksum", false, "fail when an asset has no checksum to verify")
+	downloadCmd.Flags().BoolVar(&asJSON, "json", false, "print the downloaded assets as json")
+	return downloadCmd
+}
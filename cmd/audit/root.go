@@ -0,0 +1,90 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/audit"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/internal/tags"
+	"github.com/spf13/cobra"
+)
+
+func NewAuditCmd() *cobra.Command {
+	var (
+		filter audit.Filter
+		since  string
+		asJSON bool
+	)
+	auditCmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Query the audit log of release actions",
+		Long: `Query the append-only audit log written when audit.enabled is set. Every release, release prune,
+promote and chatops action is recorded with who ran it, when, which flags were set, which release
+steps finished and whether it succeeded. The log is stored by the audit backend:
+
+  file  a JSON Lines file in the working directory, default ` + audit.DefaultFile + `
+  git   a ref in the release remote where each entry is a commit, default ` + audit.DefaultRef + `
+  http  entries are posted to a collector such as a SIEM, query them there`,
+		Example: `  autoctl audit --since 30d
+  autoctl audit --action release --status failed --json
+  autoctl audit --version 1.2.0`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if since != "" {
+				age, err := tags.ParseAge(since)
+				if err != nil {
+					return err
+				}
+				filter.Since = time.Now().Add(-age)
+			}
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			releaser := release.New(releaseOpts)
+			filter.Version = strings.TrimPrefix(filter.Version, releaser.Options().TagPrefix)
+			l, err := releaser.AuditLog()
+			if err != nil {
+				return err
+			}
+			entries, err := audit.Query(l, filter)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				data, err := json.MarshalIndent(entries, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			for _, e := range entries {
+				status := e.Status
+				if e.DryRun {
+					status += " (dry run)"
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.Actor, e.Action, e.Tag, status, strings.Join(e.Steps, ","))
+			}
+			return w.Flush()
+		},
+	}
+	auditCmd.Flags().StringVar(&filter.Action, "action", "", "only show the action, such as release, release prune, promote or chatops")
+	auditCmd.Flags().StringVar(&filter.Actor, "actor", "", "only show actions run by the actor")
+	auditCmd.Flags().StringVar(&filter.Version, "version", "", "only show actions on the version")
+	auditCmd.Flags().StringVar(&filter.Status, "status", "", fmt.Sprintf("only show actions with the status, one of %s|%s|%s", audit.StatusSuccess, audit.StatusFailed, audit.StatusSkipped))
+	auditCmd.Flags().StringVar(&since, "since", "", "only show actions within the age, e.g. 30d, 2w, 36h")
+	auditCmd.Flags().IntVar(&filter.Limit, "limit", 0, "only show the latest n actions")
+	auditCmd.Flags().BoolVar(&asJSON, "json", false, "print the entries as json")
+	return auditCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewAuditCmd())
+}
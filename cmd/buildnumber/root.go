@@ -0,0 +1,59 @@
+package buildnumber
+
+import (
+	"fmt"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/buildnum"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/spf13/cobra"
+)
+
+func NewBuildNumberCmd() *cobra.Command {
+	var next bool
+	buildNumberCmd := &cobra.Command{
+		Use:   "build-number",
+		Short: "Print or allocate the monotonic build number",
+		Long: `Print the last allocated build number, or allocate the next one with --next.
+
+Build numbers are strictly increasing integers independent of the semantic version, as required by
+app stores and platforms such as Android versionCode. They are stored by the release.buildNumber backend:
+
+  file      a file in the working directory, default ` + buildnum.DefaultFile + `
+  git       git notes on the built commits under ` + buildnum.DefaultRef + `, pushed to the release remote
+  provider  a GitHub Actions or GitLab CI/CD variable, default ` + buildnum.DefaultVariable + `
+
+With release.buildNumber.enabled, each release allocates one before tagging,
+and templates of gitops, homebrew, scoop and sync build refer to it as {{ .BuildNumber }}.`,
+		Example: `  autoctl build-number
+  autoctl build-number --next`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			counter, err := release.New(releaseOpts).BuildCounter()
+			if err != nil {
+				return err
+			}
+			var n int64
+			if next {
+				n, err = counter.Next()
+			} else {
+				n, err = counter.Current()
+			}
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), n)
+			return err
+		},
+	}
+	buildNumberCmd.Flags().BoolVar(&next, "next", false, "allocate and print the next build number")
+	return buildNumberCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewBuildNumberCmd())
+}
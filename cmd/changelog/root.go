@@ -1,72 +1,181 @@
 package changelog
 
 import (
-	"github.com/coffee377/autoctl/pkg/git"
-	"github.com/coffee377/autoctl/pkg/log"
-	"github.com/spf13/cobra"
+	"encoding/json"
+	"fmt"
 	"os"
-)
+	"path/filepath"
+	"strings"
 
-const (
-	JSON     = "json"
-	MARKDOWN = "markdown"
-	HTML     = "html"
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/fileutil"
+	"github.com/coffee377/autoctl/internal/golden"
+	"github.com/coffee377/autoctl/internal/i18n"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/spf13/cobra"
 )
 
-type changeLogOptions struct {
-	fetch  bool
-	format string
-	output bool
-	commit []string
+type backfillOptions struct {
+	output         string // 输出文件
+	stdout         bool   // 输出到控制台
+	skipPrerelease bool   // 忽略预发布版本
+	json           bool   // 以 JSON 格式输出
 }
 
-var logOpts = changeLogOptions{
-	fetch:  false,
-	format: "json",
-	output: false,
+type checkOptions struct {
+	check          bool   // 检查变更日志文件是否与生成的内容一致
+	file           string // 变更日志文件
+	skipPrerelease bool   // 忽略预发布版本
 }
 
-// changelogCmd represents the changelog command
-var changelogCmd = &cobra.Command{
-	Use:   "changelog",
-	Short: "command for git to generate logs",
-	Run: func(cmd *cobra.Command, args []string) {
-		//var err error
-		gitPlus := git.Plus{Cwd: "rootOpts.cwd", Verbose: false}
-		// 1. 拉取最新代码
-		if logOpts.fetch {
-			gitPlus.FetchAll()
-		}
-		// 获取 log
-		logs := gitPlus.FetchLogs("2.0.4", "v2.0.6")
-		//tag := gitPlus.FetchTags(true, "v2.7*", "*2.8*")
-		//tags := gitPlus.FetchTags("", true)
-		// 是否输出日志文件
-		if !logOpts.output {
-			_, err := cmd.OutOrStdout().Write(logs)
+func NewChangelogCmd() *cobra.Command {
+	opts := &checkOptions{}
+	changelogCmd := &cobra.Command{
+		Use:   "changelog",
+		Short: "Generate changelog from the commit history",
+		Long: `Generate changelog from the commit history.
+
+With --check the complete changelog is rendered as 'autoctl changelog backfill' would write it and
+compared with the committed file, the differences are printed and the command fails when they diverge,
+so CI catches manual edits drifting from the generated content. Files of the languages configured
+in release.locales, e.g. CHANGELOG.zh-CN.md, are checked as well.`,
+		Example: `  autoctl changelog --check
+  autoctl changelog --check --file docs/CHANGELOG.md`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !opts.check {
+				return cmd.Help()
+			}
+			doc, releaseOpts, logs, err := render(cmd, opts.skipPrerelease)
 			if err != nil {
-				log.Fatal("%s", err)
+				return err
 			}
-			os.Exit(0)
-		}
+			docs, err := localized(releaseOpts, opts.file, doc, logs)
+			if err != nil {
+				return err
+			}
+			var stale []string
+			for _, d := range docs {
+				data, err := os.ReadFile(resolve(releaseOpts.Cwd, d.file))
+				if err != nil {
+					return err
+				}
+				diff := golden.Diff(d.file, "generated", string(data), d.content)
+				if diff == "" {
+					log.Info(i18n.T("%s is up to date"), d.file)
+					continue
+				}
+				_, _ = fmt.Fprint(cmd.OutOrStdout(), diff)
+				stale = append(stale, d.file)
+			}
+			if len(stale) == 0 {
+				return nil
+			}
+			cmd.SilenceUsage = true
+			return fmt.Errorf("%s differs from the generated changelog, run 'autoctl changelog backfill' to regenerate it", strings.Join(stale, ", "))
+		},
+	}
+	changelogCmd.Flags().BoolVar(&opts.check, "check", false, "fail if the changelog file differs from the generated changelog")
+	changelogCmd.Flags().StringVar(&opts.file, "file", changelog.FileName, "changelog file to check, relative to the working directory")
+	changelogCmd.Flags().BoolVar(&opts.skipPrerelease, "skip-prerelease", false, "skip prerelease tags, their changes are listed under the next release")
+	changelogCmd.AddCommand(NewBackfillCmd())
+	return changelogCmd
+}
 
-		//logOpts.format
-	},
+// render 为所有历史版本生成完整的变更日志
+func render(cmd *cobra.Command, skipPrerelease bool) (string, release.Options, []*changelog.Changelog, error) {
+	releaseOpts, err := releasecmd.LoadConfig(cmd)
+	if err != nil {
+		return "", releaseOpts, nil, err
+	}
+	releaser := release.New(releaseOpts)
+	logs, err := releaser.Backfill(skipPrerelease)
+	if err != nil {
+		return "", releaseOpts, nil, err
+	}
+	if len(logs) == 0 {
+		return "", releaseOpts, nil, fmt.Errorf("no version tags with prefix %q found", releaser.Options().TagPrefix)
+	}
+	return changelog.Document(logs), releaseOpts, logs, nil
 }
 
-func init() {
-	//rootCmd.AddCommand(changelogCmd)
+// document 生成的变更日志文件
+type document struct {
+	file    string // 文件路径，相对路径基于工作目录
+	content string
+}
+
+// localized 默认语言的变更日志文件及 release.locales 配置的各语言版本，如 CHANGELOG.zh-CN.md
+func localized(releaseOpts release.Options, file, doc string, logs []*changelog.Changelog) ([]document, error) {
+	docs := []document{{file: file, content: doc}}
+	for _, l := range releaseOpts.Locales {
+		content, err := l.Document(logs)
+		if err != nil {
+			return nil, err
+		}
+		docs = append(docs, document{file: changelog.LocaleFile(file, l.Lang), content: content})
+	}
+	return docs, nil
+}
 
-	// 是否获取所有远程参考最新代码
-	changelogCmd.Flags().BoolVar(&logOpts.fetch, "fetch", false, "fetch the latest commits and tags for all branches")
-	// 输出格式 json markdown html
-	changelogCmd.Flags().StringVarP(&logOpts.format, "format", "f", "json", "output file format json, markdown or html(default json)")
-	// 是否输出到文件，默认 false ,输出到控制台
-	changelogCmd.Flags().BoolVarP(&logOpts.output, "output", "o", false, "whether to output logs as file")
-	//changelogCmd.Flags().StringArrayVarP(&logOpts.commit, "commit", "c", nil, "日志范围")
+func resolve(cwd, file string) string {
+	if filepath.IsAbs(file) {
+		return file
+	}
+	return filepath.Join(cwd, file)
+}
+
+func NewBackfillCmd() (backfillCmd *cobra.Command) {
+	opts := &backfillOptions{}
+	backfillCmd = &cobra.Command{
+		Use:   "backfill",
+		Short: "Regenerate the complete changelog with one section per historical release tag",
+		Long: `Walk all existing version tags in semver order and regenerate the changelog file
+with one section per release, dated by the tag. Useful when adopting autoctl on an
+existing repository, the file is overwritten. A file per language configured in release.locales
+is written next to it, e.g. CHANGELOG.zh-CN.md, with translated section titles, optional per-language
+templates and an optional external translation command.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			doc, releaseOpts, logs, err := render(cmd, opts.skipPrerelease)
+			if err != nil {
+				return err
+			}
+			if opts.json {
+				data, err := json.MarshalIndent(logs, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			}
+			if opts.stdout {
+				_, err = fmt.Fprint(cmd.OutOrStdout(), doc)
+				return err
+			}
+			docs, err := localized(releaseOpts, opts.output, doc, logs)
+			if err != nil {
+				return err
+			}
+			for _, d := range docs {
+				file := resolve(releaseOpts.Cwd, d.file)
+				if err = fileutil.WriteFile(file, []byte(d.content), 0o644); err != nil {
+					return err
+				}
+				log.Info(i18n.T("wrote %d releases to %s"), len(logs), file)
+			}
+			return nil
+		},
+	}
+	backfillCmd.Flags().StringVarP(&opts.output, "output", "o", changelog.FileName, "changelog file, relative to the working directory")
+	backfillCmd.Flags().BoolVar(&opts.stdout, "stdout", false, "print the changelog instead of writing the file")
+	backfillCmd.Flags().BoolVar(&opts.skipPrerelease, "skip-prerelease", false, "skip prerelease tags, their changes are listed under the next release")
+	backfillCmd.Flags().BoolVar(&opts.json, "json", false, "print the changelogs as json")
+	return backfillCmd
 }
 
-//type ChangeLog struct {
-//	Tag     string         `json:"tag"`     // tag 标签, 最近的标签到最新的提交为 Unreleased
-//	Commits []CommitRecord `json:"commits"` //
-//}
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewChangelogCmd())
+}
@@ -0,0 +1,121 @@
+package chatops
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/audit"
+	"github.com/coffee377/autoctl/internal/chatops"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewChatOpsCmd() *cobra.Command {
+	var (
+		event    string
+		platform string
+		noReply  bool
+	)
+	chatopsCmd := &cobra.Command{
+		Use:   "chatops",
+		Short: "Run the release requested by a /release comment",
+		Long: `Read a comment event, and when it contains a release command such as "/release minor",
+"/release prerelease --preid beta" or "/release --dry-run" from a user listed in chatops.users,
+run the release and reply with the result on the issue or pull request.
+
+In GitHub Actions run it on issue_comment events, the event file defaults to $GITHUB_EVENT_PATH.
+To receive webhooks directly, enable chatops and run 'autoctl serve'.`,
+		Example: `  autoctl chatops
+  autoctl chatops --platform gitlab --event note.json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if event == "" {
+				event = os.Getenv("GITHUB_EVENT_PATH")
+			}
+			if event == "" {
+				return errors.New("--event is required outside of github actions")
+			}
+			data, err := os.ReadFile(event)
+			if err != nil {
+				return err
+			}
+			var (
+				e  *chatops.Event
+				ok bool
+			)
+			switch platform {
+			case provider.GitHub:
+				e, ok, err = chatops.ParseGitHub(data)
+			case provider.GitLab:
+				e, ok, err = chatops.ParseGitLab(data)
+			default:
+				return fmt.Errorf("unsupported platform %q, one of %s|%s", platform, provider.GitHub, provider.GitLab)
+			}
+			if err != nil || !ok {
+				return err
+			}
+			cfg := chatops.Config{}
+			if err = viper.UnmarshalKey("chatops", &cfg); err != nil {
+				return err
+			}
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			var commenter provider.Commenter
+			if !noReply && releaseOpts.Provider.Repo != "" {
+				p, err := provider.New(releaseOpts.Provider)
+				if err != nil {
+					return err
+				}
+				commenter, _ = p.(provider.Commenter)
+			}
+			bot := chatops.NewBot(cfg, func(c *chatops.Command) (*release.Result, error) {
+				opts := releaseOpts
+				opts.DryRun = c.DryRun
+				if c.PreId != "" {
+					opts.PreId = c.PreId
+				}
+				changed, err := semver.ParseReleaseType(c.Release)
+				if err != nil {
+					return nil, err
+				}
+				opts.Release = changed
+				releaser := release.New(opts)
+				res, err := releaser.Release()
+				flags := map[string]string{"preid": c.PreId, "dry-run": strconv.FormatBool(c.DryRun)}
+				releaser.Audit(&audit.Entry{Action: "chatops", Actor: c.User, Args: []string{c.Release}, Flags: flags}, res, err)
+				return res, err
+			}, commenter)
+			res, err := bot.Handle(e)
+			if err != nil || res == nil {
+				if res == nil && err == nil {
+					log.Info("no release command in the comment")
+				}
+				return err
+			}
+			out, err := json.MarshalIndent(res, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			return err
+		},
+	}
+	chatopsCmd.Flags().StringVar(&event, "event", "", "comment event payload file (default $GITHUB_EVENT_PATH)")
+	chatopsCmd.Flags().StringVar(&platform, "platform", provider.GitHub, fmt.Sprintf("platform of the event payload, one of %s|%s", provider.GitHub, provider.GitLab))
+	chatopsCmd.Flags().BoolVar(&noReply, "no-reply", false, "do not reply with the result on the issue or pull request")
+	return chatopsCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewChatOpsCmd())
+}
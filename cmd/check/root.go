@@ -0,0 +1,158 @@
+package check
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/cmd/version"
+	"github.com/coffee377/autoctl/internal/httpclient"
+	"github.com/coffee377/autoctl/internal/kustomize"
+	"github.com/coffee377/autoctl/internal/registry"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/pkg/semver"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type readinessOptions struct {
+	release version.ReleaseTypeValue // 版本变动类型
+	preid   string                   // 预发布版本标识符
+	push    bool                     // 检查推送相关项
+	publish bool                     // 检查代码托管平台相关项
+	json    bool                     // 以 JSON 格式输出
+}
+
+func NewCheckCmd() *cobra.Command {
+	checkCmd := &cobra.Command{
+		Use:   "check",
+		Short: "Run preflight checks",
+	}
+	checkCmd.AddCommand(NewReadinessCmd())
+	return checkCmd
+}
+
+func NewReadinessCmd() (readinessCmd *cobra.Command) {
+	opts := &readinessOptions{}
+	readinessCmd = &cobra.Command{
+		Use:   "release-readiness [release-type]",
+		Short: "Check the repository is ready to release without performing any action",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				if err := opts.release.Set(args[0]); err != nil {
+					return err
+				}
+			}
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			releaseOpts.Release = opts.release.Changed
+			releaseOpts.DryRun = true
+			if opts.preid != "" {
+				releaseOpts.PreId = opts.preid
+			}
+			if cmd.Flags().Changed("push") {
+				releaseOpts.Push = opts.push
+			}
+			if cmd.Flags().Changed("publish") {
+				releaseOpts.Publish = opts.publish
+			}
+			extra, err := registryChecks()
+			if err != nil {
+				return err
+			}
+			results := release.New(releaseOpts).Readiness(extra...)
+			if err = printResults(cmd, results, opts.json); err != nil {
+				return err
+			}
+			failed := 0
+			for _, r := range results {
+				if !r.Passed {
+					failed++
+				}
+			}
+			if failed > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d checks failed", failed, len(results))
+			}
+			return nil
+		},
+	}
+	readinessCmd.Flags().VarP(&opts.release, "release-type", "r", fmt.Sprintf("release type, one of %s (default patch)", strings.Join(semver.ReleaseTypeNames(), "|")))
+	readinessCmd.Flags().StringVar(&opts.preid, "preid", "", "prerelease identifier, e.g. alpha, beta, rc")
+	readinessCmd.Flags().BoolVar(&opts.push, "push", false, "check pushing the tag to the remotes")
+	readinessCmd.Flags().BoolVar(&opts.publish, "publish", false, "check publishing the release to the provider (implies --push)")
+	readinessCmd.Flags().BoolVar(&opts.json, "json", false, "print the report as json")
+	_ = readinessCmd.RegisterFlagCompletionFunc("release-type", version.CompleteReleaseType)
+	return readinessCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewCheckCmd())
+}
+
+// registryChecks 配置了 kustomize 镜像校验时检查镜像仓库可访问
+func registryChecks() ([]release.Check, error) {
+	cfg := kustomize.Config{}
+	if err := viper.UnmarshalKey("kustomize", &cfg); err != nil {
+		return nil, err
+	}
+	var global httpclient.Config
+	if err := viper.UnmarshalKey("http", &global); err != nil {
+		return nil, err
+	}
+	cfg.Registry.HTTP = cfg.Registry.HTTP.Merge(global)
+	return []release.Check{{Name: "registry reachable", Run: func() (string, error) {
+		if !cfg.Verify || len(cfg.Images) == 0 {
+			return "", release.ErrSkipped
+		}
+		client, err := registry.New(cfg.Registry)
+		if err != nil {
+			return "", err
+		}
+		hosts := make([]string, 0)
+		seen := map[string]bool{}
+		for _, image := range cfg.Images {
+			ref, err := registry.ParseReference(image)
+			if err != nil {
+				return "", err
+			}
+			if seen[ref.Registry] {
+				continue
+			}
+			seen[ref.Registry] = true
+			if err = client.Ping(ref); err != nil {
+				return "", err
+			}
+			hosts = append(hosts, ref.Registry)
+		}
+		return strings.Join(hosts, ", "), nil
+	}}}, nil
+}
+
+func printResults(cmd *cobra.Command, results []release.CheckResult, asJSON bool) error {
+	if asJSON {
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return err
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	for _, r := range results {
+		state := "PASS"
+		switch {
+		case r.Skipped:
+			state = "SKIP"
+		case !r.Passed:
+			state = "FAIL"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", state, r.Name, r.Message)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,98 @@
+package classify
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/impact"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/spf13/cobra"
+)
+
+func NewClassifyCmd() *cobra.Command {
+	var asJSON, diff bool
+	classifyCmd := &cobra.Command{
+		Use:   "classify",
+		Short: "Show how each pending commit is classified and which release type is inferred",
+		Long: `Print every commit since the latest version tag with its detected type, scope, inferred impact and
+the rule that decided it, e.g. "BREAKING CHANGE footer", "type feat" or "bot author renovate[bot]",
+so it is easy to see why the inferred release type came out minor instead of patch.
+
+The inferred release type is what release uses when no release type is given; a release.labels label
+or an accepted --diff inference may adjust it.
+
+When no commit carries a release type, e.g. after squash merges with free-form titles, --diff (or
+release.impact.enabled) inspects the diff instead: removed or changed exported Go symbols suggest major,
+added ones minor, and major bumps of package.json dependencies major, each with a confidence score.`,
+		Example: `  autoctl classify
+  autoctl classify --diff
+  autoctl classify --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			if diff {
+				releaseOpts.Impact.Enabled = true
+			}
+			c, err := release.New(releaseOpts).Pending()
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				data, err := json.MarshalIndent(c, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			}
+			from := c.From
+			if from == "" {
+				from = "the first commit"
+			}
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%d commits since %s, inferred release type %s\n\n", len(c.Commits), from, c.Release)
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "COMMIT\tTYPE\tSCOPE\tIMPACT\tRULE\tSUBJECT")
+			for _, d := range c.Commits {
+				hash := d.Hash
+				if len(hash) > 7 {
+					hash = hash[:7]
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", hash, orDash(d.Type), orDash(d.Scope), d.Impact, d.Rule, d.Subject)
+			}
+			if err = w.Flush(); err != nil || c.Impact == nil {
+				return err
+			}
+			return printImpact(cmd, c.Impact)
+		},
+	}
+	classifyCmd.Flags().BoolVar(&asJSON, "json", false, "print the classification as json")
+	classifyCmd.Flags().BoolVar(&diff, "diff", false, "infer the release type from the diff when no commit carries one")
+	return classifyCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewClassifyCmd())
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+// printImpact 输出代码差异推断的版本变动类型及各项差异
+func printImpact(cmd *cobra.Command, r *impact.Report) error {
+	_, _ = fmt.Fprintf(cmd.OutOrStdout(), "\nno commit carries a release type, the diff suggests %s with confidence %.2f\n\n", orDash(r.Release), r.Confidence)
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "KIND\tIMPACT\tCONFIDENCE\tPACKAGE\tSYMBOL\tDETAIL")
+	for _, f := range r.Findings {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%.2f\t%s\t%s\t%s\n", f.Kind, f.Impact, f.Confidence, orDash(f.Package), orDash(f.Symbol), f.Detail)
+	}
+	return w.Flush()
+}
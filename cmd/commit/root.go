@@ -0,0 +1,232 @@
+package commit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/convention"
+	"github.com/coffee377/autoctl/internal/i18n"
+	"github.com/coffee377/autoctl/internal/prompt"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// historyLimit 推荐范围时统计的提交数量
+const historyLimit = 200
+
+type commitOptions struct {
+	typ      string   // 提交类型
+	scope    string   // 提交范围
+	subject  string   // 提交描述
+	body     string   // 提交正文
+	breaking string   // 不兼容变更说明，不为空时标记为不兼容变更
+	closes   []string // 关闭的问题
+	all      bool     // 提交所有已跟踪文件的变更
+	dryRun   bool     // 仅输出提交信息
+}
+
+func NewCommitCmd() (commitCmd *cobra.Command) {
+	opts := &commitOptions{}
+	commitCmd = &cobra.Command{
+		Use:   "commit",
+		Short: "Interactively compose a conventional commit and run git commit",
+		Long: `Compose a conventional commit message step by step and commit the staged changes.
+Prompts are skipped when both --type and --message are given. The message is
+validated with the same rules as 'autoctl lint', configured in the commit node, e.g.
+
+commit:
+  scopes: [deps, release]  # added to the scopes of the workspace packages
+  requireScope: true
+  gitmoji: emoji          # insert the gitmoji of the type before the subject, e.g. feat: ✨ add export`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, _ := cmd.Flags().GetString("directory")
+			rules, err := LoadRules(cwd)
+			if err != nil {
+				return err
+			}
+			verbose, _ := cmd.Flags().GetBool("verbose")
+			plus := &git.Plus{Cwd: cwd, Verbose: verbose}
+			if !opts.dryRun {
+				if err = checkStaged(plus, opts.all); err != nil {
+					return err
+				}
+			}
+			draft := convention.Draft{
+				Type: opts.typ, Scope: opts.scope, Subject: opts.subject, Body: opts.body,
+				Breaking: opts.breaking != "", BreakingNote: opts.breaking, Closes: opts.closes,
+			}
+			p := prompt.New(cmd.InOrStdin(), cmd.OutOrStdout())
+			interactive := opts.typ == "" || opts.subject == ""
+			if interactive {
+				if err = ask(p, plus, rules, &draft); err != nil {
+					return err
+				}
+			}
+			draft.Gitmoji = rules.GitmojiPrefix(draft.Type, draft.Breaking)
+			message := draft.String()
+			if problems := rules.Validate(message); len(problems) > 0 {
+				return i18n.Errorf("invalid commit message:\n  %s", strings.Join(problems, "\n  "))
+			}
+			if opts.dryRun {
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), message)
+				return err
+			}
+			if interactive {
+				p.Printf("\n%s\n\n", message)
+				ok, err := p.Confirm(i18n.T("Commit?"), true)
+				if err != nil || !ok {
+					return err
+				}
+			}
+			commitArgs := []string{"commit", "--quiet", "-m", message}
+			if opts.all {
+				commitArgs = append(commitArgs, "--all")
+			}
+			_, err = plus.Run(commitArgs...)
+			return err
+		},
+	}
+	commitCmd.Flags().StringVar(&opts.typ, "type", "", "commit type, e.g. feat, fix")
+	commitCmd.Flags().StringVar(&opts.scope, "scope", "", "commit scope")
+	commitCmd.Flags().StringVar(&opts.subject, "message", "", "short description of the change")
+	commitCmd.Flags().StringVar(&opts.body, "body", "", "longer description of the change")
+	commitCmd.Flags().StringVar(&opts.breaking, "breaking", "", "describe the breaking change, marks the commit as breaking")
+	commitCmd.Flags().StringSliceVar(&opts.closes, "closes", nil, "issues closed by the commit, e.g. 12 or owner/repo#12")
+	commitCmd.Flags().BoolVarP(&opts.all, "all", "a", false, "commit all changes of tracked files")
+	commitCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "print the commit message without committing")
+	_ = commitCmd.RegisterFlagCompletionFunc("type", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		rules, _ := LoadRules("")
+		return rules.TypeNames(), cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = commitCmd.RegisterFlagCompletionFunc("scope", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		cwd, _ := cmd.Flags().GetString("directory")
+		rules, _ := LoadRules(cwd)
+		return rules.Scopes, cobra.ShellCompDirectiveNoFileComp
+	})
+	return commitCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewCommitCmd())
+}
+
+// LoadRules 读取配置文件 commit 节点的提交信息规范，并合并 dir 工作区软件包对应的范围
+func LoadRules(dir string) (convention.Rules, error) {
+	rules := convention.Rules{}
+	if err := viper.UnmarshalKey("commit", &rules); err != nil {
+		return rules, err
+	}
+	if err := convention.ValidateGitmoji(rules.Gitmoji); err != nil {
+		return rules, err
+	}
+	return rules.WithDefaults().WithWorkspace(dir)
+}
+
+// checkStaged 检查是否有待提交的变更，避免填写完提交信息后才失败
+func checkStaged(plus *git.Plus, all bool) error {
+	args := []string{"diff", "--cached", "--quiet"}
+	if all {
+		args = []string{"diff", "HEAD", "--quiet"}
+	}
+	if _, err := plus.Run(args...); err == nil {
+		return i18n.Errorf("no changes added to commit, stage changes with git add or use --all")
+	}
+	return nil
+}
+
+// ask 依次询问未通过命令行参数指定的内容
+func ask(p *prompt.Prompter, plus *git.Plus, rules convention.Rules, draft *convention.Draft) (err error) {
+	if draft.Type == "" {
+		options := make([]prompt.Option, 0, len(rules.Types))
+		for _, t := range rules.Types {
+			options = append(options, prompt.Option{Value: t.Name, Description: t.Description})
+		}
+		if draft.Type, err = p.Select(i18n.T("Select the type of change:"), options, "", false); err != nil {
+			return err
+		}
+	}
+	if draft.Scope == "" {
+		if draft.Scope, err = askScope(p, plus, rules); err != nil {
+			return err
+		}
+	}
+	for draft.Subject == "" {
+		if draft.Subject, err = p.Input(i18n.T("Short description"), ""); err != nil {
+			return err
+		}
+	}
+	if draft.Body == "" {
+		if draft.Body, err = p.Input(i18n.T("Longer description (optional)"), ""); err != nil {
+			return err
+		}
+	}
+	if !draft.Breaking {
+		if draft.Breaking, err = p.Confirm(i18n.T("Is this a breaking change?"), false); err != nil {
+			return err
+		}
+		if draft.Breaking {
+			if draft.BreakingNote, err = p.Input(i18n.T("Describe the breaking change"), ""); err != nil {
+				return err
+			}
+		}
+	}
+	if len(draft.Closes) == 0 {
+		issues, err := p.Input(i18n.T("Issues closed, separated by comma (optional)"), "")
+		if err != nil {
+			return err
+		}
+		for _, issue := range strings.Split(issues, ",") {
+			if issue = strings.TrimSpace(issue); issue != "" {
+				draft.Closes = append(draft.Closes, issue)
+			}
+		}
+	}
+	return nil
+}
+
+// askScope 选择范围，存在允许的范围时仅能从中选择，否则推荐历史提交中使用过的范围
+func askScope(p *prompt.Prompter, plus *git.Plus, rules convention.Rules) (string, error) {
+	scopes := rules.Scopes
+	restricted := len(scopes) > 0
+	if !restricted {
+		var err error
+		if scopes, err = convention.HistoryScopes(plus, historyLimit); err != nil {
+			return "", err
+		}
+	}
+	label := i18n.T("Scope of the change (optional):")
+	if rules.RequireScope {
+		label = i18n.T("Scope of the change:")
+	}
+	if len(scopes) == 0 {
+		return p.Input(strings.TrimRight(label, ":："), "")
+	}
+	options := make([]prompt.Option, 0, len(scopes))
+	for _, s := range scopes {
+		options = append(options, prompt.Option{Value: s})
+	}
+	for {
+		scope, err := p.Select(label, options, "", true)
+		if err != nil {
+			return "", err
+		}
+		if scope == "" && !rules.RequireScope {
+			return "", nil
+		}
+		if scope != "" && (!restricted || contains(scopes, scope)) {
+			return scope, nil
+		}
+		p.Printf("%s", i18n.T("invalid scope %q\n", scope))
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/deps"
+	"github.com/coffee377/autoctl/internal/i18n"
+	"github.com/coffee377/autoctl/internal/multirepo"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/spf13/cobra"
+)
+
+type bumpOptions struct {
+	set    []string
+	plan   string
+	dryRun bool
+	pr     bool
+	asJSON bool
+	deps.Proposal
+}
+
+// bumpResult 依赖版本更新结果
+type bumpResult struct {
+	Versions    map[string]string `json:"versions"`
+	Manifests   []string          `json:"manifests"`
+	PullRequest string            `json:"pullRequest,omitempty"`
+	DryRun      bool              `json:"dryRun,omitempty"`
+}
+
+func NewDepsCmd() *cobra.Command {
+	depsCmd := &cobra.Command{
+		Use:   "deps",
+		Short: "Manage internal dependency versions",
+	}
+	depsCmd.AddCommand(NewBumpCmd())
+	return depsCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewDepsCmd())
+}
+
+func NewBumpCmd() *cobra.Command {
+	opts := bumpOptions{}
+	bumpCmd := &cobra.Command{
+		Use:   "bump",
+		Short: "Raise internal dependency versions to the released versions",
+		Long: `Raise the versions of internal dependencies declared in go.mod, package.json and pom.xml
+to the versions just released. The versions are read from the workspace packages, the repositories
+of a multi-repository release file (--plan) and --set, the latter taking precedence.
+With --pr the changes are committed to a branch and a pull request is opened.`,
+		Example: `  autoctl deps bump
+  autoctl deps bump --plan releases.yaml --pr
+  autoctl deps bump --set github.com/acme/lib=v1.2.0 --set com.acme:core=1.2.0`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, _ := cmd.Flags().GetString("directory")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+			if dir == "" {
+				dir = "."
+			}
+			plus := &git.Plus{Cwd: dir, Verbose: verbose}
+			versions, err := deps.WorkspaceVersions(plus, dir)
+			if err != nil {
+				return err
+			}
+			if opts.plan != "" {
+				name := opts.plan
+				if !filepath.IsAbs(name) {
+					name = filepath.Join(dir, name)
+				}
+				f, err := multirepo.Load(name)
+				if err != nil {
+					return err
+				}
+				released, err := multirepo.Versions(f)
+				if err != nil {
+					return err
+				}
+				for k, v := range released {
+					versions[k] = v
+				}
+			}
+			for _, s := range opts.set {
+				name, version, ok := strings.Cut(s, "=")
+				if !ok || name == "" || version == "" {
+					return fmt.Errorf("invalid --set %q, expected name=version", s)
+				}
+				versions[name] = version
+			}
+			manifests, err := deps.Bump(dir, versions, opts.dryRun)
+			if err != nil {
+				return err
+			}
+			res := &bumpResult{Versions: versions, Manifests: manifests, DryRun: opts.dryRun}
+			if opts.pr && len(manifests) > 0 && !opts.dryRun {
+				releaseOpts, err := releasecmd.LoadConfig(cmd)
+				if err != nil {
+					return err
+				}
+				p, err := provider.New(releaseOpts.Provider)
+				if err != nil {
+					return err
+				}
+				pr, err := deps.Propose(plus, p, manifests, versions, opts.Proposal)
+				if err != nil {
+					return err
+				}
+				res.PullRequest = pr.URL
+			}
+			if opts.asJSON {
+				return printJSON(cmd.OutOrStdout(), res)
+			}
+			return printResult(cmd.OutOrStdout(), res)
+		},
+	}
+	bumpCmd.Flags().StringArrayVar(&opts.set, "set", nil, "dependency version as name=version, the name is a go module path, npm package name or maven groupId:artifactId")
+	bumpCmd.Flags().StringVar(&opts.plan, "plan", "", "multi-repository release file whose repositories provide the versions")
+	bumpCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "print the manifests to change without writing them")
+	bumpCmd.Flags().BoolVar(&opts.pr, "pr", false, "commit the changes to a branch and open a pull request")
+	bumpCmd.Flags().StringVar(&opts.Branch, "branch", deps.DefaultBumpBranch, "source branch of the pull request")
+	bumpCmd.Flags().StringVar(&opts.Base, "base", "", "target branch of the pull request (default current branch)")
+	bumpCmd.Flags().StringVar(&opts.Remote, "remote", "origin", "remote to push the source branch to")
+	bumpCmd.Flags().StringVar(&opts.Message, "message", deps.DefaultBumpMessage, "commit message and pull request title")
+	bumpCmd.Flags().BoolVar(&opts.asJSON, "json", false, "print the result as json")
+	return bumpCmd
+}
+
+func printResult(out io.Writer, res *bumpResult) error {
+	if len(res.Manifests) == 0 {
+		_, err := fmt.Fprintln(out, i18n.T("dependencies are up to date"))
+		return err
+	}
+	names := make([]string, 0, len(res.Versions))
+	for name := range res.Versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, name := range names {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", name, res.Versions[name])
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	for _, m := range res.Manifests {
+		_, _ = fmt.Fprint(out, i18n.T("updated %s\n", m))
+	}
+	if res.PullRequest != "" {
+		_, _ = fmt.Fprint(out, i18n.T("pull request %s\n", res.PullRequest))
+	}
+	return nil
+}
+
+func printJSON(out io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
@@ -0,0 +1,116 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/coffee377/autoctl/internal/compare"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/spf13/cobra"
+)
+
+const (
+	Table    = "table"
+	Markdown = "markdown"
+	JSON     = "json"
+)
+
+type diffOptions struct {
+	format string // 输出格式
+}
+
+func NewDiffCmd() (diffCmd *cobra.Command) {
+	opts := &diffOptions{}
+	diffCmd = &cobra.Command{
+		Use:   "diff <from> [to]",
+		Short: "Compare two refs: commits, contributors, changed packages, breaking changes and versions",
+		Example: `  autoctl diff v1.2.0 v1.3.0
+  autoctl diff v1.3.0 --format markdown`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			from, to := args[0], "HEAD"
+			if len(args) == 2 {
+				to = args[1]
+			}
+			cwd, _ := cmd.Flags().GetString("directory")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+			report, err := compare.Compare(&git.Plus{Cwd: cwd, Verbose: verbose}, cwd, from, to)
+			if err != nil {
+				return err
+			}
+			switch opts.format {
+			case JSON:
+				data, err := json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			case Markdown:
+				_, err = fmt.Fprint(cmd.OutOrStdout(), report.Markdown())
+				return err
+			case Table:
+				return printTable(cmd.OutOrStdout(), report)
+			}
+			return fmt.Errorf("unsupported format %q, valid values are %s, %s, %s", opts.format, Table, Markdown, JSON)
+		},
+	}
+	diffCmd.Flags().StringVar(&opts.format, "format", Table, fmt.Sprintf("output format, one of %s|%s|%s", Table, Markdown, JSON))
+	_ = diffCmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{Table, Markdown, JSON}, cobra.ShellCompDirectiveNoFileComp
+	})
+	return diffCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewDiffCmd())
+}
+
+func printTable(out io.Writer, r *compare.Report) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintf(w, "%s...%s: %d commits, %d contributors, %d files changed\n", r.From, r.To, len(r.Commits), len(r.Contributors), r.Files)
+	if len(r.Breaking) > 0 {
+		_, _ = fmt.Fprintln(w, "\nBREAKING CHANGES")
+		for _, c := range r.Breaking {
+			note := c.BreakingNote
+			if note == "" {
+				note = c.Subject
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\n", c.ShortHash(), note)
+		}
+	}
+	if len(r.Packages) > 0 {
+		_, _ = fmt.Fprintln(w, "\nPACKAGE\tPATH\tFILES")
+		for _, p := range r.Packages {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%d\n", p.Name, p.Path, p.Files)
+		}
+	}
+	if len(r.Manifests) > 0 {
+		_, _ = fmt.Fprintln(w, "\nMANIFEST\tNAME\tFROM\tTO")
+		for _, m := range r.Manifests {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", m.File, m.Name, orNone(m.From), orNone(m.To))
+		}
+	}
+	if len(r.Contributors) > 0 {
+		_, _ = fmt.Fprintln(w, "\nCONTRIBUTOR\tEMAIL\tCOMMITS")
+		for _, c := range r.Contributors {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%d\n", c.Name, c.Email, c.Commits)
+		}
+	}
+	if len(r.Commits) > 0 {
+		_, _ = fmt.Fprintln(w, "\nCOMMIT\tTYPE\tSCOPE\tSUBJECT\tAUTHOR")
+		for _, c := range r.Commits {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", c.ShortHash(), c.Type, c.Scope, c.Subject, c.Author)
+		}
+	}
+	return w.Flush()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
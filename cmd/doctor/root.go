@@ -0,0 +1,111 @@
+package doctor
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/doctor"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewDoctorCmd() *cobra.Command {
+	var asJSON bool
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the environment and suggest fixes",
+		Long: `Diagnose the environment autoctl runs in: git version, repository and identity, the config file,
+the detected CI, workspace packages, and the credentials, token scopes and connectivity of the provider
+and gitops providers. Every problem comes with a suggested fix, the command fails when any check fails.`,
+		Example: `  autoctl doctor
+  autoctl doctor --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, _ := cmd.Flags().GetString("directory")
+			verbose, _ := cmd.Flags().GetBool("verbose")
+			plus := &git.Plus{Cwd: cwd, Verbose: verbose}
+			releaseOpts, configErr := releasecmd.LoadConfig(cmd)
+			checks := []doctor.Check{
+				doctor.GitVersion(plus),
+				doctor.Repository(plus, releaseOpts.Remote),
+				doctor.GitConfig(plus),
+				configCheck(releaseOpts, configErr),
+				doctor.CI(),
+				doctor.Workspace(cwd),
+				doctor.Credentials("provider", releaseOpts.Provider),
+				doctor.Connectivity("provider", releaseOpts.Provider),
+			}
+			for i, target := range releaseOpts.GitOps {
+				if target.Provider.Repo == "" {
+					continue
+				}
+				key := fmt.Sprintf("gitops[%d].provider", i)
+				checks = append(checks, doctor.Credentials(key, target.Provider), doctor.Connectivity(key, target.Provider))
+			}
+			findings := doctor.Run(checks)
+			if err := printFindings(cmd, findings, asJSON); err != nil {
+				return err
+			}
+			if failed := doctor.Failed(findings); failed > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d checks failed", failed, len(findings))
+			}
+			return nil
+		},
+	}
+	doctorCmd.Flags().BoolVar(&asJSON, "json", false, "print the findings as json")
+	return doctorCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewDoctorCmd())
+}
+
+// configCheck 检查配置文件存在、语法正确且各节点的取值有效
+func configCheck(opts release.Options, loadErr error) doctor.Check {
+	return doctor.Check{Name: "config file", Run: func() doctor.Finding {
+		file := viper.ConfigFileUsed()
+		if file == "" {
+			return doctor.Warn("no config file found, defaults are used", "create auto.yaml in the working directory, or pass --config")
+		}
+		if _, err := os.Stat(file); err != nil {
+			return doctor.Fail(err.Error(), "check the path given by --config")
+		}
+		if err := viper.ReadInConfig(); err != nil {
+			return doctor.Fail(err.Error(), "fix the syntax of "+file)
+		}
+		if loadErr != nil {
+			return doctor.Fail(loadErr.Error(), "fix the type of the reported field in "+file)
+		}
+		if err := releasecmd.Validate(opts); err != nil {
+			return doctor.Fail(err.Error(), "fix the reported value in "+file)
+		}
+		return doctor.OK("%s", file)
+	}}
+}
+
+var states = map[string]string{doctor.StatusOK: "OK", doctor.StatusWarn: "WARN", doctor.StatusFail: "FAIL", doctor.StatusSkip: "SKIP"}
+
+func printFindings(cmd *cobra.Command, findings []doctor.Finding, asJSON bool) error {
+	if asJSON {
+		data, err := json.MarshalIndent(findings, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return err
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	for _, f := range findings {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", states[f.Status], f.Name, f.Message)
+		if f.Fix != "" {
+			_, _ = fmt.Fprintf(w, "\t\t→ %s\n", f.Fix)
+		}
+	}
+	return w.Flush()
+}
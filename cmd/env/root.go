@@ -0,0 +1,298 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/audit"
+	"github.com/coffee377/autoctl/internal/environments"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/internal/tags"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// setup 读取环境配置，创建发布器及部署记录
+func setup(cmd *cobra.Command) (environments.Config, *release.Releaser, environments.Store, error) {
+	cfg := environments.Config{}
+	if err := viper.UnmarshalKey("environments", &cfg); err != nil {
+		return cfg, nil, nil, err
+	}
+	releaseOpts, err := releasecmd.LoadConfig(cmd)
+	if err != nil {
+		return cfg, nil, nil, err
+	}
+	store, err := environments.New(cfg, releaseOpts.Cwd, releaseOpts.Provider)
+	if err != nil {
+		return cfg, nil, nil, err
+	}
+	return cfg, release.New(releaseOpts), store, nil
+}
+
+// lookup 在版本标签中查找 version，version 可以带标签前缀
+func lookup(list []tags.Tag, prefix, version string) (tags.Tag, error) {
+	name := prefix + strings.TrimPrefix(version, prefix)
+	for _, t := range list {
+		if t.Name == name {
+			return t, nil
+		}
+	}
+	return tags.Tag{}, fmt.Errorf("tag %s does not exist", name)
+}
+
+func NewSetCmd() *cobra.Command {
+	var url string
+	setCmd := &cobra.Command{
+		Use:   "set <environment> [version]",
+		Short: "Record the version deployed to an environment",
+		Long: `Record the version (default the current version) deployed to the environment, in
+` + environments.DefaultFile + ` (environments.file) or as a deployment on the provider when environments.source is provider.
+Run it from the deploy job of each environment, the version must be a released tag.`,
+		Example: `  autoctl env set staging
+  autoctl env set prod 1.4.0 --url https://app.example.com`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, releaser, store, err := setup(cmd)
+			if err != nil {
+				return err
+			}
+			if err = cfg.Validate(args[0]); err != nil {
+				return err
+			}
+			prefix := releaser.Options().TagPrefix
+			list, _, err := tags.List(releaser.Git(), prefix)
+			if err != nil {
+				return err
+			}
+			version := ""
+			if len(args) == 2 {
+				version = args[1]
+			} else if len(list) > 0 {
+				version = list[0].Name
+			} else {
+				return errors.New("no version has been released yet")
+			}
+			tag, err := lookup(list, prefix, version)
+			if err != nil {
+				return err
+			}
+			d := &environments.Deployment{
+				Environment: args[0],
+				Tag:         tag.Name,
+				Commit:      tag.Commit,
+				By:          audit.Actor(releaser.Git()),
+				Time:        time.Now().UTC(),
+				URL:         url,
+			}
+			if err = store.Set(d); err != nil {
+				return err
+			}
+			log.Info("recorded %s deployed to %s", d.Tag, d.Environment)
+			return nil
+		},
+	}
+	setCmd.Flags().StringVar(&url, "url", "", "url of the environment")
+	return setCmd
+}
+
+func NewGetCmd() *cobra.Command {
+	var asJSON bool
+	getCmd := &cobra.Command{
+		Use:   "get [environment]",
+		Short: "Show the version deployed to each environment",
+		Example: `  autoctl env get
+  autoctl env get prod --json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, releaser, store, err := setup(cmd)
+			if err != nil {
+				return err
+			}
+			names := args
+			if len(names) == 0 {
+				if names, err = environments.Names(cfg, releaser.Options().Cwd); err != nil {
+					return err
+				}
+			}
+			deployments := make([]*environments.Deployment, 0, len(names))
+			for _, name := range names {
+				d, err := store.Get(name)
+				if err != nil {
+					return fmt.Errorf("get deployment of %s: %w", name, err)
+				}
+				if d == nil {
+					d = &environments.Deployment{Environment: name}
+				}
+				deployments = append(deployments, d)
+			}
+			if asJSON {
+				return printJSON(cmd, deployments)
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "ENVIRONMENT\tVERSION\tDEPLOYED\tBY")
+			for _, d := range deployments {
+				if d.Tag == "" {
+					_, _ = fmt.Fprintf(w, "%s\t-\t-\t-\n", d.Environment)
+					continue
+				}
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.Environment, d.Tag, d.Time.Local().Format("2006-01-02 15:04"), d.By)
+			}
+			return w.Flush()
+		},
+	}
+	getCmd.Flags().BoolVar(&asJSON, "json", false, "print the deployments as json")
+	return getCmd
+}
+
+func NewDiffCmd() *cobra.Command {
+	var (
+		asJSON   bool
+		exitCode bool
+	)
+	diffCmd := &cobra.Command{
+		Use:   "diff [environment] [environment]",
+		Short: "Show the drift between environments and the latest release",
+		Long: `Without arguments, compare the version deployed to each environment with the latest release and
+list the releases each environment is behind. With two environments, list the releases deployed to the
+second environment but not yet to the first one, e.g. what promoting staging to prod would ship.`,
+		Example: `  autoctl env diff
+  autoctl env diff prod staging
+  autoctl env diff --exit-code`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 || len(args) > 2 {
+				return errors.New("requires no environment or two environments")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, releaser, store, err := setup(cmd)
+			if err != nil {
+				return err
+			}
+			list, _, err := tags.List(releaser.Git(), releaser.Options().TagPrefix)
+			if err != nil {
+				return err
+			}
+			if len(args) == 2 {
+				return diffEnvironments(cmd, store, list, args[0], args[1], asJSON, exitCode)
+			}
+			names, err := environments.Names(cfg, releaser.Options().Cwd)
+			if err != nil {
+				return err
+			}
+			drifts := make([]environments.Drift, 0, len(names))
+			drifted := 0
+			for _, name := range names {
+				d, err := store.Get(name)
+				if err != nil {
+					return fmt.Errorf("get deployment of %s: %w", name, err)
+				}
+				drift := environments.Compare(name, d, list)
+				if !drift.Current() {
+					drifted++
+				}
+				drifts = append(drifts, drift)
+			}
+			if asJSON {
+				err = printJSON(cmd, drifts)
+			} else {
+				w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+				_, _ = fmt.Fprintln(w, "ENVIRONMENT\tVERSION\tBEHIND")
+				for _, d := range drifts {
+					version := "-"
+					if d.Deployed != nil {
+						version = d.Deployed.Tag
+					}
+					behind := "up to date"
+					if !d.Current() {
+						behind = fmt.Sprintf("%d (%s)", len(d.Behind), strings.Join(d.Behind, ", "))
+					}
+					_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", d.Environment, version, behind)
+				}
+				err = w.Flush()
+			}
+			if err == nil && exitCode && drifted > 0 {
+				return fmt.Errorf("%d of %d environments are behind the latest release", drifted, len(drifts))
+			}
+			return err
+		},
+	}
+	diffCmd.Flags().BoolVar(&asJSON, "json", false, "print the drift as json")
+	diffCmd.Flags().BoolVar(&exitCode, "exit-code", false, "exit with an error when any environment is behind")
+	return diffCmd
+}
+
+// diffEnvironments 列出 to 环境中已部署而 from 环境尚未部署的版本
+func diffEnvironments(cmd *cobra.Command, store environments.Store, list []tags.Tag, from, to string, asJSON, exitCode bool) error {
+	src, err := store.Get(from)
+	if err != nil {
+		return fmt.Errorf("get deployment of %s: %w", from, err)
+	}
+	dst, err := store.Get(to)
+	if err != nil {
+		return fmt.Errorf("get deployment of %s: %w", to, err)
+	}
+	if dst == nil {
+		return fmt.Errorf("nothing has been deployed to %s", to)
+	}
+	fromTag := ""
+	if src != nil {
+		fromTag = src.Tag
+	}
+	releases := environments.Between(fromTag, dst.Tag, list)
+	if asJSON {
+		err = printJSON(cmd, struct {
+			From     *environments.Deployment `json:"from,omitempty"`
+			To       *environments.Deployment `json:"to"`
+			Releases []string                 `json:"releases"`
+		}{src, dst, releases})
+	} else if len(releases) == 0 {
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s has everything deployed to %s\n", from, to)
+	} else {
+		_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s -> %s: %s\n", orNone(fromTag), dst.Tag, strings.Join(releases, ", "))
+	}
+	if err == nil && exitCode && len(releases) > 0 {
+		return fmt.Errorf("%s is %d releases behind %s", from, len(releases), to)
+	}
+	return err
+}
+
+func orNone(tag string) string {
+	if tag == "" {
+		return "(none)"
+	}
+	return tag
+}
+
+func printJSON(cmd *cobra.Command, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return err
+}
+
+func NewEnvCmd() *cobra.Command {
+	envCmd := &cobra.Command{
+		Use:   "env",
+		Short: "Track the version deployed to each environment",
+		Long: `Track which version is deployed to dev, staging, prod and so on, and compare the environments with
+the latest release. Environments are configured in the environments node, deployments are recorded in a
+state file committed to the repository or on the provider deployments api.`,
+	}
+	envCmd.AddCommand(NewSetCmd())
+	envCmd.AddCommand(NewGetCmd())
+	envCmd.AddCommand(NewDiffCmd())
+	return envCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewEnvCmd())
+}
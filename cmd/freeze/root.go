@@ -0,0 +1,137 @@
+package freeze
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/audit"
+	"github.com/coffee377/autoctl/internal/freeze"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+type freezeOptions struct {
+	until    string        // 冻结结束时间
+	duration time.Duration // 冻结时长
+	reason   string        // 冻结原因
+	status   bool          // 只查看冻结状态
+}
+
+func NewFreezeCmd() *cobra.Command {
+	opts := &freezeOptions{}
+	freezeCmd := &cobra.Command{
+		Use:   "freeze",
+		Short: "Start a code freeze that only allows patch releases",
+		Long: `Record a freeze window in ` + freeze.DefaultFile + ` (release.freeze.file), commit the file so every pipeline sees it.
+
+During the freeze release commands only allow patch and patch prerelease versions, minor and major
+releases fail, and the release notes of patch releases note that they were released during the freeze.
+Without --until or --for the freeze lasts until 'autoctl thaw' lifts it.`,
+		Example: `  autoctl freeze --for 72h --reason "year-end"
+  autoctl freeze --until 2024-12-31
+  autoctl freeze --status`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			releaser := release.New(releaseOpts)
+			file := releaser.FreezeFile()
+			if opts.status {
+				return printStatus(cmd, file)
+			}
+			now := time.Now()
+			state := &freeze.State{Since: now, Reason: opts.reason, By: audit.Actor(releaser.Git())}
+			switch {
+			case opts.until != "" && opts.duration != 0:
+				return errors.New("--until and --for can not be used together")
+			case opts.until != "":
+				until, err := parseTime(opts.until)
+				if err != nil {
+					return err
+				}
+				state.Until = &until
+			case opts.duration != 0:
+				until := now.Add(opts.duration)
+				state.Until = &until
+			}
+			if state.Until != nil && !state.Until.After(now) {
+				return fmt.Errorf("freeze end %s is in the past", state.Until.Format(time.RFC3339))
+			}
+			if err = freeze.Save(file, state); err != nil {
+				return err
+			}
+			log.Info("started %s, commit %s to share it", state, file)
+			return nil
+		},
+	}
+	freezeCmd.Flags().StringVar(&opts.until, "until", "", "end of the freeze, a RFC 3339 time or a date like 2024-12-31 (start of the day in local time)")
+	freezeCmd.Flags().DurationVar(&opts.duration, "for", 0, "duration of the freeze, e.g. 72h")
+	freezeCmd.Flags().StringVar(&opts.reason, "reason", "", "reason of the freeze, shown in errors and release notes")
+	freezeCmd.Flags().BoolVar(&opts.status, "status", false, "print the current freeze as json instead of starting one")
+	return freezeCmd
+}
+
+func NewThawCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "thaw",
+		Short: "Lift the code freeze",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			file := release.New(releaseOpts).FreezeFile()
+			removed, err := freeze.Remove(file)
+			if err != nil {
+				return err
+			}
+			if !removed {
+				log.Info("no code freeze to lift")
+				return nil
+			}
+			log.Info("lifted the code freeze, commit the removal of %s to share it", file)
+			return nil
+		},
+	}
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewFreezeCmd())
+	parent.AddCommand(NewThawCmd())
+}
+
+// printStatus 输出冻结状态，未冻结或冻结已结束时 active 为 false
+func printStatus(cmd *cobra.Command, file string) error {
+	state, err := freeze.Load(file)
+	if err != nil {
+		return err
+	}
+	out := struct {
+		Active bool `json:"active"`
+		*freeze.State
+	}{Active: state.Active(time.Now()), State: state}
+	data, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return err
+}
+
+func parseTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", s, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid time %q, expected RFC 3339 or YYYY-MM-DD", s)
+	}
+	return t, nil
+}
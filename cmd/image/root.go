@@ -2,6 +2,7 @@ package image
 
 import (
 	"fmt"
+	"github.com/coffee377/autoctl/internal/i18n"
 	"github.com/coffee377/autoctl/pkg/log"
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
@@ -74,7 +75,7 @@ func initConfig(cfgFile, configName string) {
 
 	if err = viper.ReadInConfig(); err != nil {
 		//fmt.Println("Can't read config:", err)
-		log.Info("初始化默认配置")
+		log.Info(i18n.T("initialize default config"))
 
 		//filename := path.Join(home, "automation.yaml")
 		//err = viper.WriteConfigAs(filename)
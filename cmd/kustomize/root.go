@@ -0,0 +1,196 @@
+package kustomize
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/httpclient"
+	"github.com/coffee377/autoctl/internal/kustomize"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/pkg/semver"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type setImageOptions struct {
+	images     []string // 覆盖配置中的镜像
+	paths      []string // 覆盖配置中的 kustomization 路径
+	commit     bool     // 提交变更
+	skipVerify bool     // 跳过镜像存在性检查
+	pin        bool     // 固定镜像摘要
+	json       bool     // 以 JSON 格式输出
+}
+
+func NewKustomizeCmd() *cobra.Command {
+	kustomizeCmd := &cobra.Command{
+		Use:   "kustomize",
+		Short: "Update kustomization files with the released version",
+	}
+	kustomizeCmd.AddCommand(NewSetImageCmd())
+	return kustomizeCmd
+}
+
+func NewSetImageCmd() (setImageCmd *cobra.Command) {
+	opts := &setImageOptions{}
+	setImageCmd = &cobra.Command{
+		Use:   "set-image [version]",
+		Short: "Set newTag of the configured images to the version (default current version)",
+		Long: `Set newTag of the configured images like 'kustomize edit set image', e.g.
+
+kustomize:
+  images: [ghcr.io/coffee377/autoctl]
+  paths: [deploy/overlays/staging, deploy/overlays/prod]
+  verify: true
+  pin: true       # also write images[].digest resolved from the registry
+  commit: true
+  registry:
+    credentials:
+      - registry: 123456789012.dkr.ecr.us-east-1.amazonaws.com
+        username: AWS
+        passwordCommand: aws ecr get-login-password --region us-east-1`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(cmd, opts)
+			if err != nil {
+				return err
+			}
+			cwd, _ := cmd.Flags().GetString("directory")
+			releaser, err := newReleaser(cmd)
+			if err != nil {
+				return err
+			}
+			tag, err := imageTag(releaser, args)
+			if err != nil {
+				return err
+			}
+			tag = cfg.TagPrefix + tag
+			updates, err := kustomize.Apply(cfg, cwd, tag)
+			if err != nil {
+				return err
+			}
+			if cfg.Commit {
+				if err = commit(releaser, cfg, cwd, tag, updates); err != nil {
+					return err
+				}
+			}
+			return printUpdates(cmd, updates, opts.json)
+		},
+	}
+	setImageCmd.Flags().StringSliceVar(&opts.images, "image", nil, "kustomization image name to update, can be repeated")
+	setImageCmd.Flags().StringSliceVar(&opts.paths, "path", nil, "kustomization file or directory, can be repeated")
+	setImageCmd.Flags().BoolVar(&opts.commit, "commit", false, "commit the updated kustomization files")
+	setImageCmd.Flags().BoolVar(&opts.skipVerify, "skip-verify", false, "skip checking the image exists in the registry")
+	setImageCmd.Flags().BoolVar(&opts.pin, "pin", false, "resolve the image digest and pin it in the kustomization files")
+	setImageCmd.Flags().BoolVar(&opts.json, "json", false, "print the updates as json")
+	return setImageCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewKustomizeCmd())
+}
+
+func loadConfig(cmd *cobra.Command, opts *setImageOptions) (kustomize.Config, error) {
+	cfg := kustomize.Config{}
+	if err := viper.UnmarshalKey("kustomize", &cfg); err != nil {
+		return cfg, err
+	}
+	var global httpclient.Config
+	if err := viper.UnmarshalKey("http", &global); err != nil {
+		return cfg, err
+	}
+	cfg.Registry.HTTP = cfg.Registry.HTTP.Merge(global)
+	if len(opts.images) > 0 {
+		cfg.Images = opts.images
+	}
+	if len(opts.paths) > 0 {
+		cfg.Paths = opts.paths
+	}
+	if cmd.Flags().Changed("commit") {
+		cfg.Commit = opts.commit
+	}
+	if opts.skipVerify {
+		cfg.Verify = false
+	}
+	if cmd.Flags().Changed("pin") {
+		cfg.Pin = opts.pin
+	}
+	return cfg, nil
+}
+
+func newReleaser(cmd *cobra.Command) (*release.Releaser, error) {
+	releaseOpts := release.Options{}
+	if err := viper.UnmarshalKey("release", &releaseOpts); err != nil {
+		return nil, err
+	}
+	releaseOpts.Cwd, _ = cmd.Flags().GetString("directory")
+	releaseOpts.Verbose, _ = cmd.Flags().GetBool("verbose")
+	return release.New(releaseOpts), nil
+}
+
+// imageTag 命令行指定的版本，未指定时为当前版本
+func imageTag(releaser *release.Releaser, args []string) (string, error) {
+	if len(args) == 1 {
+		v, err := semver.Version(strings.TrimPrefix(args[0], releaser.Options().TagPrefix))
+		if err != nil {
+			return "", err
+		}
+		return v.String(), nil
+	}
+	current, err := releaser.Current()
+	if err != nil {
+		return "", err
+	}
+	if current == nil {
+		return "", fmt.Errorf("no version tag found, specify the version to set")
+	}
+	return current.String(), nil
+}
+
+func commit(releaser *release.Releaser, cfg kustomize.Config, cwd, tag string, updates []kustomize.Update) error {
+	paths := make([]string, 0, len(updates))
+	seen := map[string]bool{}
+	for _, u := range updates {
+		if !u.Changed || seen[u.File] {
+			continue
+		}
+		seen[u.File] = true
+		if rel, err := filepath.Rel(cwd, u.File); err == nil && cwd != "" {
+			paths = append(paths, rel)
+		} else {
+			paths = append(paths, u.File)
+		}
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	message := cfg.Message
+	if message == "" {
+		message = kustomize.DefaultMessage
+	}
+	return releaser.Git().Commit(fmt.Sprintf(message, tag), paths...)
+}
+
+func printUpdates(cmd *cobra.Command, updates []kustomize.Update, asJSON bool) error {
+	if asJSON {
+		data, err := json.MarshalIndent(updates, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return err
+	}
+	for _, u := range updates {
+		state := "unchanged"
+		if u.Changed {
+			state = "updated"
+		}
+		ref := u.Ref
+		if u.Digest != "" {
+			ref += "@" + u.Digest
+		}
+		_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\t%s\n", u.File, ref, state)
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package lint
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	commitcmd "github.com/coffee377/autoctl/cmd/commit"
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/i18n"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/spf13/cobra"
+)
+
+type lintOptions struct {
+	file string // 提交信息文件，用于 commit-msg 钩子
+	json bool   // 以 JSON 格式输出
+}
+
+// Violation 不符合规范的提交
+type Violation struct {
+	Commit   string   `json:"commit,omitempty"`
+	Header   string   `json:"header"`
+	Problems []string `json:"problems"`
+}
+
+func NewLintCmd() (lintCmd *cobra.Command) {
+	opts := &lintOptions{}
+	lintCmd = &cobra.Command{
+		Use:   "lint [revision-range]",
+		Short: "Check commit messages follow the conventional commits specification",
+		Long: `Check commit messages in the revision range (default commits since the latest tag),
+or the message file given by --message-file, e.g. in the commit-msg hook
+(at "$(git rev-parse --git-path hooks)/commit-msg", which also works in worktrees and submodules):
+
+autoctl lint --message-file "$1"`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cwd, _ := cmd.Flags().GetString("directory")
+			rules, err := commitcmd.LoadRules(cwd)
+			if err != nil {
+				return err
+			}
+			violations := make([]Violation, 0)
+			if opts.file != "" {
+				data, err := os.ReadFile(opts.file)
+				if err != nil {
+					return err
+				}
+				message := string(data)
+				if problems := rules.Validate(message); len(problems) > 0 {
+					violations = append(violations, Violation{Header: header(message), Problems: problems})
+				}
+			} else {
+				verbose, _ := cmd.Flags().GetBool("verbose")
+				plus := &git.Plus{Cwd: cwd, Verbose: verbose}
+				var from, to string
+				if len(args) == 1 {
+					from, to, _ = strings.Cut(args[0], "..")
+					if to == "" && !strings.Contains(args[0], "..") {
+						from, to = "", args[0]
+					}
+				} else {
+					from, _ = plus.LatestTag()
+				}
+				records, err := changelog.Collect(plus, from, to)
+				if err != nil {
+					return err
+				}
+				for _, r := range records {
+					if problems := rules.Validate(r.RawMessage); len(problems) > 0 {
+						violations = append(violations, Violation{Commit: r.Commit, Header: header(r.RawMessage), Problems: problems})
+					}
+				}
+			}
+			if err = printViolations(cmd, violations, opts.json); err != nil {
+				return err
+			}
+			if len(violations) > 0 {
+				cmd.SilenceUsage = true
+				return i18n.Errorf("%d commit message(s) do not follow the conventional commits specification", len(violations))
+			}
+			return nil
+		},
+	}
+	lintCmd.Flags().StringVar(&opts.file, "message-file", "", "lint the commit message in the file instead of the history")
+	lintCmd.Flags().BoolVar(&opts.json, "json", false, "print the violations as json")
+	return lintCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewLintCmd())
+}
+
+func header(message string) string {
+	return strings.TrimSpace(strings.SplitN(strings.TrimSpace(message), "\n", 2)[0])
+}
+
+func printViolations(cmd *cobra.Command, violations []Violation, asJSON bool) error {
+	if asJSON {
+		data, err := json.MarshalIndent(violations, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return err
+	}
+	for _, v := range violations {
+		name := v.Header
+		if v.Commit != "" {
+			name = fmt.Sprintf("%.7s %s", v.Commit, v.Header)
+		}
+		_, _ = fmt.Fprintln(cmd.OutOrStdout(), name)
+		for _, p := range v.Problems {
+			_, _ = fmt.Fprintf(cmd.OutOrStdout(), "  ✖ %s\n", p)
+		}
+	}
+	return nil
+}
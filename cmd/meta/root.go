@@ -0,0 +1,125 @@
+package meta
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/coffee377/autoctl/internal/multirepo"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/spf13/cobra"
+)
+
+func NewMetaCmd() *cobra.Command {
+	metaCmd := &cobra.Command{
+		Use:   "meta",
+		Short: "Release several repositories in dependency order",
+		Long: `Release the repositories listed in releases.yaml in dependency order.
+Each repository is cloned or fast-forwarded, the versions of the repositories it depends on
+are bumped to the versions just released, then a new version tag is created.`,
+	}
+	metaCmd.PersistentFlags().String("plan", multirepo.DefaultFile, "multi-repository release file")
+	metaCmd.AddCommand(NewOrderCmd(), NewReleaseCmd())
+	return metaCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewMetaCmd())
+}
+
+// load 读取编排文件，相对路径基于 --directory
+func load(cmd *cobra.Command) (*multirepo.File, error) {
+	name, _ := cmd.Flags().GetString("plan")
+	if dir, _ := cmd.Flags().GetString("directory"); dir != "" && !filepath.IsAbs(name) {
+		name = filepath.Join(dir, name)
+	}
+	return multirepo.Load(name)
+}
+
+func NewOrderCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "order",
+		Short: "Print the repositories in release order",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := load(cmd)
+			if err != nil {
+				return err
+			}
+			ordered, err := f.Order()
+			if err != nil {
+				return err
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			for i, r := range ordered {
+				_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", i+1, r.Name, r.Release, strings.Join(r.DependsOn, ","))
+			}
+			return w.Flush()
+		},
+	}
+}
+
+func NewReleaseCmd() (releaseCmd *cobra.Command) {
+	opts := multirepo.Options{}
+	asJSON := false
+	releaseCmd = &cobra.Command{
+		Use:   "release",
+		Short: "Bump cross-repository dependencies and release every repository",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := load(cmd)
+			if err != nil {
+				return err
+			}
+			if _, err = release.ParseNoChange(opts.OnNoChange); err != nil {
+				return err
+			}
+			opts.Verbose, _ = cmd.Flags().GetBool("verbose")
+			results, err := multirepo.Release(f, opts)
+			if asJSON {
+				if e := printJSON(cmd.OutOrStdout(), results); e != nil {
+					return e
+				}
+			} else if e := printResults(cmd.OutOrStdout(), results); e != nil {
+				return e
+			}
+			return err
+		},
+	}
+	releaseCmd.Flags().BoolVar(&opts.Push, "push", false, "push the dependency bump commits and tags")
+	releaseCmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "compute the versions without changing any repository")
+	releaseCmd.Flags().StringVar(&opts.OnNoChange, "on-no-change", "", fmt.Sprintf("behavior when a repository has no releasable commits, one of %s (default patch)", strings.Join(release.NoChangeNames(), "|")))
+	releaseCmd.Flags().BoolVar(&asJSON, "json", false, "print the release results as json")
+	return releaseCmd
+}
+
+func printResults(out io.Writer, results []*multirepo.Result) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, r := range results {
+		status := r.Tag
+		if r.Skipped {
+			status = "no release"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Name, orDash(r.Previous), status, strings.Join(r.Bumped, ","))
+	}
+	return w.Flush()
+}
+
+func printJSON(out io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
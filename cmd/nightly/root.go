@@ -0,0 +1,178 @@
+package nightly
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/i18n"
+	"github.com/coffee377/autoctl/internal/nightly"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/internal/tags"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type nightlyOptions struct {
+	tag    bool // 创建快照标签
+	push   bool // 推送快照标签，清理时同时删除远程标签
+	prune  bool // 清理过期的快照标签及平台上的预发布版本
+	dryRun bool // 仅输出需要清理的标签
+	json   bool // 以 JSON 格式输出
+}
+
+func NewNightlyCmd() *cobra.Command {
+	opts := &nightlyOptions{}
+	cfg := nightly.Config{}
+	nightlyCmd := &cobra.Command{
+		Use:   "nightly",
+		Short: "Generate deterministic snapshot versions and clean up old snapshots",
+		Long: `Print the snapshot version of HEAD, e.g. 1.5.0-nightly.20240521+sha.abc1234, based on the next
+anticipated version after the latest stable tag. The date is the commit date in UTC, so the same
+commit always gets the same version.
+
+With --prune, snapshot tags beyond nightly.keep or older than nightly.maxAge are deleted together
+with their prereleases on the provider.`,
+		Example: `  autoctl nightly
+  autoctl nightly --tag --push
+  autoctl nightly --prune --keep 7 --max-age 30d --push --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fileCfg := nightly.Config{}
+			if err := viper.UnmarshalKey("nightly", &fileCfg); err != nil {
+				return err
+			}
+			merge(cmd, &cfg, fileCfg)
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			releaser := release.New(releaseOpts)
+			prefix := releaser.Options().TagPrefix
+			if opts.prune {
+				return prune(cmd.OutOrStdout(), releaser, cfg, opts)
+			}
+			s, err := nightly.New(releaser.Git(), prefix, cfg)
+			if err != nil {
+				return err
+			}
+			if opts.tag {
+				if err = releaser.Git().CreateTag(s.Tag, ""); err != nil {
+					return err
+				}
+				log.Info(i18n.T("created tag %s"), s.Tag)
+				if opts.push {
+					if err = releaser.Git().PushTag(releaser.Options().Remote, s.Tag); err != nil {
+						return err
+					}
+					log.Info(i18n.T("pushed tag %s to %s"), s.Tag, releaser.Options().Remote)
+				}
+			}
+			if opts.json {
+				return printJSON(cmd.OutOrStdout(), s)
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), s.Version)
+			return err
+		},
+	}
+	nightlyCmd.Flags().StringVar(&cfg.Channel, "channel", nightly.DefaultChannel, "prerelease identifier of the snapshot versions")
+	nightlyCmd.Flags().StringVar(&cfg.Release, "release", "patch", "anticipated release type of the next version, one of major|minor|patch")
+	nightlyCmd.Flags().IntVar(&cfg.Keep, "keep", 0, "keep the newest n snapshot tags when pruning")
+	nightlyCmd.Flags().StringVar(&cfg.MaxAge, "max-age", "", "delete snapshot tags older than the age when pruning, e.g. 30d")
+	nightlyCmd.Flags().BoolVar(&opts.tag, "tag", false, "create the snapshot tag on HEAD")
+	nightlyCmd.Flags().BoolVar(&opts.push, "push", false, "push the snapshot tag, or delete the pruned tags from the remote")
+	nightlyCmd.Flags().BoolVar(&opts.prune, "prune", false, "delete expired snapshot tags and their provider prereleases")
+	nightlyCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "print the snapshot tags to delete without deleting")
+	nightlyCmd.Flags().BoolVar(&opts.json, "json", false, "print the result as json")
+	return nightlyCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewNightlyCmd())
+}
+
+// merge 未通过命令行指定的选项使用配置文件中的值
+func merge(cmd *cobra.Command, cfg *nightly.Config, fileCfg nightly.Config) {
+	if !cmd.Flags().Changed("channel") && fileCfg.Channel != "" {
+		cfg.Channel = fileCfg.Channel
+	}
+	if !cmd.Flags().Changed("release") && fileCfg.Release != "" {
+		cfg.Release = fileCfg.Release
+	}
+	if !cmd.Flags().Changed("keep") && fileCfg.Keep > 0 {
+		cfg.Keep = fileCfg.Keep
+	}
+	if !cmd.Flags().Changed("max-age") && fileCfg.MaxAge != "" {
+		cfg.MaxAge = fileCfg.MaxAge
+	}
+}
+
+// prune 删除过期的快照标签，配置了代码托管平台时同时删除对应的版本发布
+func prune(out io.Writer, releaser *release.Releaser, cfg nightly.Config, opts *nightlyOptions) error {
+	plus := releaser.Git()
+	list, _, err := tags.List(plus, releaser.Options().TagPrefix)
+	if err != nil {
+		return err
+	}
+	expired, err := nightly.Expired(list, cfg, time.Now())
+	if err != nil {
+		return err
+	}
+	if opts.dryRun {
+		log.Info(i18n.T("dry run: %d tags would be deleted"), len(expired))
+		return printTags(out, expired, opts.json)
+	}
+	var manager provider.ReleaseManager
+	if pc := releaser.Options().Provider; pc.Repo != "" {
+		p, err := provider.New(pc)
+		if err != nil {
+			return err
+		}
+		manager, _ = p.(provider.ReleaseManager)
+	}
+	for _, t := range expired {
+		if manager != nil {
+			if err = manager.DeleteRelease(&provider.Release{Tag: t.Name}); err != nil {
+				return err
+			}
+		}
+		if opts.push {
+			if err = plus.DeleteRemoteTag(releaser.Options().Remote, t.Name); err != nil {
+				return err
+			}
+		}
+		if err = plus.DeleteTag(t.Name); err != nil {
+			return err
+		}
+		log.Info(i18n.T("deleted tag %s"), t.Name)
+	}
+	if opts.json {
+		return printJSON(out, expired)
+	}
+	return nil
+}
+
+func printTags(out io.Writer, list []tags.Tag, asJSON bool) error {
+	if asJSON {
+		return printJSON(out, list)
+	}
+	for _, t := range list {
+		if _, err := fmt.Fprintf(out, "%s\t%s\n", t.Name, t.Date.Format("2006-01-02")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func printJSON(out io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
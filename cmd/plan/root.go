@@ -0,0 +1,99 @@
+package plan
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/internal/train"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type planOptions struct {
+	count int    // 推算的发布次数
+	from  string // 起始日期
+	write bool   // 是否写入计划文件
+	json  bool   // 以 JSON 格式输出
+}
+
+func NewPlanCmd() (planCmd *cobra.Command) {
+	opts := &planOptions{}
+	planCmd = &cobra.Command{
+		Use:   "plan",
+		Short: "Compute upcoming release dates and versions from the release train cadence",
+		Long: `Compute upcoming release dates and versions from the cadences configured in the train section, e.g.
+
+train:
+  file: .autoctl/plan.json
+  cadences:
+    - release: minor
+      weekday: tuesday
+      interval: 2
+      start: 2024-01-02
+
+Cadences every few weeks count from start, so re-running plan keeps the same dates.
+
+Use 'autoctl release --train' to release the next planned entry.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := train.Config{}
+			if err := viper.UnmarshalKey("train", &cfg); err != nil {
+				return err
+			}
+			from := time.Now()
+			if opts.from != "" {
+				var err error
+				if from, err = time.Parse(train.DateLayout, opts.from); err != nil {
+					return err
+				}
+			}
+			releaseOpts := release.Options{}
+			if err := viper.UnmarshalKey("release", &releaseOpts); err != nil {
+				return err
+			}
+			releaseOpts.Cwd, _ = cmd.Flags().GetString("directory")
+			current, err := release.New(releaseOpts).Current()
+			if err != nil {
+				return err
+			}
+			plan, err := train.Compute(cfg, current, from, opts.count)
+			if err != nil {
+				return err
+			}
+			if opts.write {
+				if err = plan.Save(cfg.Path(releaseOpts.Cwd)); err != nil {
+					return err
+				}
+			}
+			return printPlan(cmd, plan, opts.json)
+		},
+	}
+	planCmd.Flags().IntVarP(&opts.count, "count", "n", 5, "number of upcoming releases to plan")
+	planCmd.Flags().StringVar(&opts.from, "from", "", "plan releases from the date (default today), format 2006-01-02")
+	planCmd.Flags().BoolVarP(&opts.write, "write", "w", false, "write the plan file")
+	planCmd.Flags().BoolVar(&opts.json, "json", false, "print the plan as json")
+	return planCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewPlanCmd())
+}
+
+func printPlan(cmd *cobra.Command, plan *train.Plan, asJSON bool) error {
+	if asJSON {
+		data, err := json.MarshalIndent(plan, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return err
+	}
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "DATE\tRELEASE\tVERSION")
+	for _, e := range plan.Entries {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", e.Date, e.Release, e.Version)
+	}
+	return w.Flush()
+}
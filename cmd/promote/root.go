@@ -0,0 +1,143 @@
+package promote
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/audit"
+	"github.com/coffee377/autoctl/internal/httpclient"
+	"github.com/coffee377/autoctl/internal/promote"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewPromoteCmd() *cobra.Command {
+	var (
+		from, to string
+		dryRun   bool
+		asJSON   bool
+	)
+	promoteCmd := &cobra.Command{
+		Use:   "promote [version]",
+		Short: "Promote a released version from one environment to another without rebuilding",
+		Long: `Copy the docker images and artifacts of the version (default the current version) from the source
+environment to the target environment configured in promote.environments, without rebuilding.
+
+Images are paired by the last segment of their names and tagged with the version, layers are mounted
+within the same registry and transferred between registries. The promotion is appended to the journal
+(default ` + promote.DefaultJournal + `) and noted on the provider release.`,
+		Example: `  autoctl promote --from staging --to production
+  autoctl promote 1.2.0 --from staging --to production --dry-run`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if from == "" || to == "" {
+				return errors.New("--from and --to are required")
+			}
+			cfg := promote.Config{}
+			if err := viper.UnmarshalKey("promote", &cfg); err != nil {
+				return err
+			}
+			var global httpclient.Config
+			if err := viper.UnmarshalKey("http", &global); err != nil {
+				return err
+			}
+			cfg.Registry.HTTP = cfg.Registry.HTTP.Merge(global)
+			log.AddSecret(cfg.Registry.Password)
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			releaser := release.New(releaseOpts)
+			prefix := releaser.Options().TagPrefix
+			version := ""
+			if len(args) == 1 {
+				version = strings.TrimPrefix(args[0], prefix)
+			} else {
+				current, err := releaser.Current()
+				if err != nil {
+					return err
+				}
+				if current == nil {
+					return errors.New("no version has been released yet")
+				}
+				version = current.String()
+			}
+			rec, err := promote.Promote(cfg, version, from, to, dryRun)
+			releasecmd.Audit(cmd, releaser, &audit.Entry{Version: version, Tag: prefix + version, DryRun: dryRun}, nil, err)
+			if err != nil {
+				return err
+			}
+			if !dryRun {
+				if err = promote.Append(cfg.Journal, rec); err != nil {
+					return err
+				}
+				if err = note(releaseOpts.Provider, prefix+version, rec); err != nil {
+					return err
+				}
+			}
+			if asJSON {
+				data, err := json.MarshalIndent(rec, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			}
+			for _, img := range rec.Images {
+				_, _ = fmt.Fprintf(cmd.OutOrStdout(), "%s -> %s\n", img.Source, img.Target)
+			}
+			for _, a := range rec.Artifacts {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), a.Path)
+			}
+			return nil
+		},
+	}
+	promoteCmd.Flags().StringVar(&from, "from", "", "source environment")
+	promoteCmd.Flags().StringVar(&to, "to", "", "target environment")
+	promoteCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print what would be promoted without copying")
+	promoteCmd.Flags().BoolVar(&asJSON, "json", false, "print the promotion record as json")
+	complete := func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var names []string
+		for name := range viper.GetStringMap("promote.environments") {
+			names = append(names, name)
+		}
+		return names, cobra.ShellCompDirectiveNoFileComp
+	}
+	_ = promoteCmd.RegisterFlagCompletionFunc("from", complete)
+	_ = promoteCmd.RegisterFlagCompletionFunc("to", complete)
+	return promoteCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewPromoteCmd())
+}
+
+// note 在代码托管平台的版本发布说明中追加晋级记录，未配置平台时跳过
+func note(cfg provider.Config, tag string, rec *promote.Record) error {
+	if cfg.Repo == "" {
+		return nil
+	}
+	p, err := provider.New(cfg)
+	if err != nil {
+		return err
+	}
+	m, ok := p.(provider.ReleaseManager)
+	if !ok {
+		return nil
+	}
+	r, err := m.GetRelease(tag)
+	if err != nil {
+		return fmt.Errorf("get %s release %s: %w", p.Name(), tag, err)
+	}
+	if err = m.UpdateReleaseBody(r, r.Body+promote.Note(rec)); err != nil {
+		return fmt.Errorf("update %s release %s: %w", p.Name(), tag, err)
+	}
+	log.Info("noted the promotion on %s release %s", p.Name(), tag)
+	return nil
+}
@@ -0,0 +1,197 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/coffee377/autoctl/internal/httpclient"
+	"github.com/coffee377/autoctl/internal/registry"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewRegistryCmd() *cobra.Command {
+	registryCmd := &cobra.Command{
+		Use:   "registry",
+		Short: "Manage image tags in Docker Hub, Harbor, ECR, GCR, ACR and other registries",
+		Long: `Manage image tags with the Docker Registry HTTP API V2, using the registry specific auth flow
+and tag deletion of Docker Hub, Harbor, Amazon ECR, Google GCR / Artifact Registry and Azure ACR, e.g.
+
+registry:
+  credentials:
+    - registry: harbor.example.com
+      type: harbor
+      username: robot$library+ci
+      password: ${HARBOR_ROBOT_SECRET}
+    - registry: ghcr.io
+      username: coffee377
+      password: ${GITHUB_TOKEN}
+
+ECR uses 'aws ecr get-login-password' with the IAM identity, GCR uses GOOGLE_APPLICATION_CREDENTIALS
+or 'gcloud auth print-access-token', ACR uses the service principal in AZURE_CLIENT_ID and
+AZURE_CLIENT_SECRET or 'az acr login --expose-token'.`,
+	}
+	registryCmd.AddCommand(newTagsCmd(), newExistsCmd(), newCopyCmd(), newDeleteCmd(), newPruneCmd())
+	return registryCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewRegistryCmd())
+}
+
+// newClient 按配置文件 registry 节点创建客户端
+func newClient() (*registry.Client, error) {
+	cfg := registry.Config{}
+	if err := viper.UnmarshalKey("registry", &cfg); err != nil {
+		return nil, err
+	}
+	var global httpclient.Config
+	if err := viper.UnmarshalKey("http", &global); err != nil {
+		return nil, err
+	}
+	cfg.HTTP = cfg.HTTP.Merge(global)
+	log.AddSecret(cfg.Password)
+	for _, c := range cfg.Credentials {
+		log.AddSecret(c.Password)
+	}
+	return registry.New(cfg)
+}
+
+func newTagsCmd() *cobra.Command {
+	var asJSON bool
+	tagsCmd := &cobra.Command{
+		Use:   "tags <image>",
+		Short: "List the tags of the image",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, ref, err := parse(args[0])
+			if err != nil {
+				return err
+			}
+			tags, err := client.Tags(ref)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return printJSON(cmd, tags)
+			}
+			for _, tag := range tags {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), tag)
+			}
+			return nil
+		},
+	}
+	tagsCmd.Flags().BoolVar(&asJSON, "json", false, "print the tags as json")
+	return tagsCmd
+}
+
+func newExistsCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "exists <image>",
+		Short: "Check the image exists and print its digest",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, ref, err := parse(args[0])
+			if err != nil {
+				return err
+			}
+			digest, err := client.Digest(ref)
+			if err != nil {
+				cmd.SilenceUsage = true
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), digest)
+			return err
+		},
+	}
+}
+
+func newCopyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "copy <source> <target>",
+		Short: "Copy the image to another tag or registry without pulling",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, src, err := parse(args[0])
+			if err != nil {
+				return err
+			}
+			dst, err := registry.ParseReference(args[1])
+			if err != nil {
+				return err
+			}
+			digest, err := client.Copy(src, dst)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s@%s\n", dst, digest)
+			return err
+		},
+	}
+}
+
+func newDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <image:tag>",
+		Short: "Delete the image tag",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, ref, err := parse(args[0])
+			if err != nil {
+				return err
+			}
+			return client.DeleteTag(ref)
+		},
+	}
+}
+
+func newPruneCmd() *cobra.Command {
+	opts := registry.PruneOptions{}
+	var asJSON bool
+	pruneCmd := &cobra.Command{
+		Use:   "prune <image>",
+		Short: "Delete prerelease tags older than the latest release, e.g. 1.2.0-rc.1 after 1.2.0",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, ref, err := parse(args[0])
+			if err != nil {
+				return err
+			}
+			deleted, err := client.PrunePrereleases(ref, opts)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				return printJSON(cmd, deleted)
+			}
+			for _, tag := range deleted {
+				_, _ = fmt.Fprintln(cmd.OutOrStdout(), tag)
+			}
+			return nil
+		},
+	}
+	pruneCmd.Flags().StringVar(&opts.TagPrefix, "tag-prefix", "", "prefix of the version tags, e.g. v")
+	pruneCmd.Flags().IntVar(&opts.Keep, "keep", 0, "number of the most recent prerelease tags to keep")
+	pruneCmd.Flags().BoolVar(&opts.DryRun, "dry-run", false, "print the tags would be deleted without deleting")
+	pruneCmd.Flags().BoolVar(&asJSON, "json", false, "print the deleted tags as json")
+	return pruneCmd
+}
+
+func parse(image string) (*registry.Client, registry.Reference, error) {
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return nil, ref, err
+	}
+	client, err := newClient()
+	return client, ref, err
+}
+
+func printJSON(cmd *cobra.Command, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+	return err
+}
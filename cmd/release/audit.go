@@ -0,0 +1,29 @@
+package release
+
+import (
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/audit"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// Audit 启用审计日志时记录命令执行的发布操作，操作名称为命令路径，如 release、release prune，
+// 同时记录位置参数及显式设置的命令行参数；e 为空时新建记录，并将发布结果写入执行报告
+func Audit(cmd *cobra.Command, releaser *release.Releaser, e *audit.Entry, res *release.Result, err error) {
+	if e == nil {
+		e = &audit.Entry{}
+	}
+	e.Action = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	e.Args = cmd.Flags().Args()
+	cmd.Flags().Visit(func(f *pflag.Flag) {
+		if e.Flags == nil {
+			e.Flags = map[string]string{}
+		}
+		e.Flags[f.Name] = log.Redact(f.Value.String())
+	})
+	releaser.Audit(e, res, err)
+	Report(res)
+}
@@ -0,0 +1,83 @@
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/spf13/cobra"
+)
+
+type hotfixOptions struct {
+	base        string // 回合并请求的目标分支
+	dryRun      bool   // 仅计算版本
+	push        bool   // 推送标签及热修复分支
+	publish     bool   // 在代码托管平台创建版本发布
+	noBackMerge bool   // 不创建回合并请求
+	json        bool   // 以 JSON 格式输出结果
+}
+
+func NewHotfixCmd() *cobra.Command {
+	opts := &hotfixOptions{}
+	hotfixCmd := &cobra.Command{
+		Use:   "hotfix <production-tag> <commit>...",
+		Short: "Release a patch of a production version with cherry-picked commits",
+		Long: `Run the emergency hotfix flow in one command:
+
+  1. create the branch ` + release.DefaultHotfixPrefix + `<version> (release.hotfix.prefix) from the production tag
+  2. cherry-pick the commits in order, a conflict aborts the cherry-pick and leaves you on the branch
+  3. release the next patch version of the production tag with the usual release pipeline
+  4. with --push, push the branch and open a pull request merging it back into the base branch
+
+The base branch defaults to release.hotfix.base or the current branch, which is checked out again at the end.`,
+		Example: `  autoctl hotfix v1.4.2 3f2c1ab 9e8d7c6 --push
+  autoctl hotfix v1.4.2 3f2c1ab --dry-run`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseOpts, err := LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			releaseOpts.DryRun = opts.dryRun
+			releaseOpts.Push = opts.push
+			releaseOpts.Publish = opts.publish
+			releaser := release.New(releaseOpts)
+			res, err := releaser.Hotfix(release.Hotfix{
+				Tag:       args[0],
+				Commits:   args[1:],
+				Base:      opts.base,
+				BackMerge: (opts.push || opts.publish) && !opts.noBackMerge,
+			})
+			var released *release.Result
+			if res != nil {
+				released = res.Release
+			}
+			Audit(cmd, releaser, nil, released, err)
+			if err != nil {
+				return err
+			}
+			if opts.json {
+				data, err := json.MarshalIndent(res, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			}
+			if err = printResult(cmd, res.Release, false); err != nil {
+				return err
+			}
+			if res.BackMerge != nil {
+				_, err = fmt.Fprintf(cmd.OutOrStdout(), "back-merge pull request: %s\n", res.BackMerge.URL)
+			}
+			return err
+		},
+	}
+	hotfixCmd.Flags().StringVar(&opts.base, "base", "", "branch the back-merge pull request targets (default release.hotfix.base or the current branch)")
+	hotfixCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "create the branch and cherry-pick locally, compute the version without creating tags")
+	hotfixCmd.Flags().BoolVar(&opts.push, "push", false, "push the created tag and the hotfix branch, and open the back-merge pull request")
+	hotfixCmd.Flags().BoolVar(&opts.publish, "publish", false, "create a release on the configured provider (implies --push)")
+	hotfixCmd.Flags().BoolVar(&opts.noBackMerge, "no-back-merge", false, "do not open the back-merge pull request")
+	hotfixCmd.Flags().BoolVar(&opts.json, "json", false, "print the hotfix result as json")
+	return hotfixCmd
+}
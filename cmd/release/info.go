@@ -0,0 +1,102 @@
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/pkg/semver"
+	"github.com/spf13/cobra"
+)
+
+func NewInfoCmd() *cobra.Command {
+	var asJSON bool
+	infoCmd := &cobra.Command{
+		Use:   "info <version>",
+		Short: "Show the release metadata recorded in git notes",
+		Long: `Show the metadata recorded by release.metadata when the version was released: the release type,
+the classification of each commit, the build number and when each step such as tagging, pushing
+and publishing finished. The metadata is stored as git notes on the tagged commit under
+` + release.DefaultMetadataRef + `, fetched from the remote when present, so no provider api is needed.`,
+		Example: `  autoctl release info 1.2.0
+  autoctl release info v1.2.0 --json`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseOpts, err := LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			releaser := release.New(releaseOpts)
+			version, err := semver.Version(strings.TrimPrefix(args[0], releaser.Options().TagPrefix))
+			if err != nil {
+				return err
+			}
+			m, err := releaser.Metadata(version)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				data, err := json.MarshalIndent(m, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			}
+			return printMetadata(cmd, m)
+		},
+	}
+	infoCmd.Flags().BoolVar(&asJSON, "json", false, "print the metadata as json")
+	return infoCmd
+}
+
+func printMetadata(cmd *cobra.Command, m *release.Metadata) error {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	rows := [][2]string{
+		{"tag", m.Tag},
+		{"previous", m.Previous},
+		{"commit", m.Commit},
+		{"branch", m.Branch},
+		{"release type", m.ReleaseType},
+		{"release url", m.ReleaseURL},
+		{"started", m.Started.Format(time.RFC3339)},
+	}
+	if m.BuildNumber > 0 {
+		rows = append(rows, [2]string{"build number", fmt.Sprint(m.BuildNumber)})
+	}
+	steps := make([]string, 0, len(m.Steps))
+	for step := range m.Steps {
+		steps = append(steps, step)
+	}
+	sort.Slice(steps, func(i, j int) bool { return m.Steps[steps[i]].Before(m.Steps[steps[j]]) })
+	for _, step := range steps {
+		rows = append(rows, [2]string{step, m.Steps[step].Format(time.RFC3339)})
+	}
+	for _, row := range rows {
+		if row[1] != "" {
+			_, _ = fmt.Fprintf(w, "%s:\t%s\n", row[0], row[1])
+		}
+	}
+	if len(m.Commits) > 0 {
+		_, _ = fmt.Fprintln(w)
+		for _, c := range m.Commits {
+			section := c.Section
+			if section == "" {
+				section = "-"
+			}
+			if c.Breaking {
+				section += "!"
+			}
+			hash := c.Hash
+			if len(hash) > 7 {
+				hash = hash[:7]
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", hash, section, c.Subject)
+		}
+	}
+	return w.Flush()
+}
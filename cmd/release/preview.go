@@ -0,0 +1,113 @@
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/coffee377/autoctl/cmd/version"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+	"github.com/spf13/cobra"
+)
+
+type previewOptions struct {
+	release   version.ReleaseTypeValue // 版本变动类型
+	preid     string                   // 预发布版本标识符
+	number    int                      // 合并请求编号
+	noComment bool                     // 只输出评论，不提交到平台
+}
+
+func NewPreviewCmd() *cobra.Command {
+	opts := &previewOptions{}
+	previewCmd := &cobra.Command{
+		Use:   "preview [release-type]",
+		Short: "Post the release preview as a pull request comment",
+		Long: `Compute the release as a dry run and keep a single comment on the pull request up to date with
+the release type it would trigger, the projected next version and a snippet of the changelog.
+
+The comment is recognized by the marker configured in release.preview.marker, each run edits it in place
+instead of adding a new comment, and no request is sent when the content is unchanged. The pull request
+defaults to $CI_MERGE_REQUEST_IID in GitLab CI or the pull request of $GITHUB_EVENT_PATH in GitHub Actions.`,
+		Example: `  autoctl release preview
+  autoctl release preview --pr 42
+  autoctl release preview --no-comment`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				if err := opts.release.Set(args[0]); err != nil {
+					return err
+				}
+			}
+			releaseOpts, err := LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			releaseOpts.Release = opts.release.Changed
+			releaseOpts.DryRun = true
+			releaseOpts.Push = false
+			releaseOpts.Publish = false
+			if opts.preid != "" {
+				releaseOpts.PreId = opts.preid
+			}
+			releaser := release.New(releaseOpts)
+			res, releaseErr := releaser.Release()
+			body := releaseOpts.Preview.Preview(res, releaseErr)
+			if opts.noComment {
+				_, err = fmt.Fprint(cmd.OutOrStdout(), body)
+				return err
+			}
+			number := opts.number
+			if number == 0 {
+				number = pullRequestNumber()
+			}
+			if number == 0 {
+				return fmt.Errorf("no pull request to comment on, use --pr to specify one")
+			}
+			changed, err := releaser.PostPreview(number, body)
+			if err != nil {
+				return err
+			}
+			if changed {
+				log.Info("release preview posted on pull request #%d", number)
+			} else {
+				log.Info("release preview on pull request #%d is up to date", number)
+			}
+			return nil
+		},
+	}
+	previewCmd.Flags().VarP(&opts.release, "release-type", "r", fmt.Sprintf("release type, one of %s (default inferred from commits)", strings.Join(semver.ReleaseTypeNames(), "|")))
+	previewCmd.Flags().StringVar(&opts.preid, "preid", "", "identifier to be used to prefix premajor, preminor, prepatch or prerelease version increments")
+	previewCmd.Flags().IntVar(&opts.number, "pr", 0, "pull request number to comment on (default the pull request of the ci pipeline)")
+	previewCmd.Flags().BoolVar(&opts.noComment, "no-comment", false, "print the comment without posting it on the provider")
+	_ = previewCmd.RegisterFlagCompletionFunc("release-type", version.CompleteReleaseType)
+	return previewCmd
+}
+
+// pullRequestNumber CI 中当前合并请求的编号，不在合并请求流水线中时为 0
+func pullRequestNumber() int {
+	if iid := os.Getenv("CI_MERGE_REQUEST_IID"); iid != "" {
+		n, _ := strconv.Atoi(iid)
+		return n
+	}
+	path := os.Getenv("GITHUB_EVENT_PATH")
+	if path == "" {
+		return 0
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0
+	}
+	event := struct {
+		PullRequest struct {
+			Number int `json:"number"`
+		} `json:"pull_request"`
+	}{}
+	if json.Unmarshal(data, &event) != nil {
+		return 0
+	}
+	return event.PullRequest.Number
+}
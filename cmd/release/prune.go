@@ -0,0 +1,69 @@
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/coffee377/autoctl/internal/i18n"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+func NewPruneCmd() *cobra.Command {
+	var (
+		rt     release.Retention
+		dryRun bool
+		asJSON bool
+	)
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Delete provider releases according to the retention policy",
+		Long: `Delete draft releases older than release.retention.drafts and prereleases beyond the newest
+release.retention.prereleases of each channel on the configured provider, together with their assets.
+Stable releases are always kept, and tags are not deleted, use 'autoctl tag prune' for tags.`,
+		Example: `  autoctl release prune --keep-prereleases 3 --drafts-older-than 30d --dry-run`,
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseOpts, err := LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("keep-prereleases") {
+				releaseOpts.Retention.Prereleases = rt.Prereleases
+			}
+			if cmd.Flags().Changed("drafts-older-than") {
+				releaseOpts.Retention.Drafts = rt.Drafts
+			}
+			releaseOpts.DryRun = dryRun
+			releaser := release.New(releaseOpts)
+			pruned, err := releaser.Prune(releaseOpts.Retention)
+			Audit(cmd, releaser, nil, nil, err)
+			if err != nil {
+				return err
+			}
+			if dryRun {
+				log.Info(i18n.T("dry run: %d releases would be deleted"), len(pruned))
+			}
+			if asJSON {
+				data, err := json.MarshalIndent(pruned, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			for _, p := range pruned {
+				_, _ = fmt.Fprintf(w, "%s\t%s\n", p.Release.Tag, p.Reason)
+			}
+			return w.Flush()
+		},
+	}
+	pruneCmd.Flags().IntVar(&rt.Prereleases, "keep-prereleases", 0, "keep the newest n prereleases of each channel (overrides release.retention.prereleases)")
+	pruneCmd.Flags().StringVar(&rt.Drafts, "drafts-older-than", "", "delete draft releases older than the age, e.g. 30d (overrides release.retention.drafts)")
+	pruneCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the releases to delete without deleting")
+	pruneCmd.Flags().BoolVar(&asJSON, "json", false, "print the pruned releases as json")
+	return pruneCmd
+}
@@ -0,0 +1,22 @@
+package release
+
+import (
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/internal/report"
+)
+
+// Report 将发布结果写入执行报告
+func Report(res *release.Result) {
+	if res == nil || !report.Enabled() {
+		return
+	}
+	if res.Previous != nil {
+		report.SetVersion("previous", res.Previous.String())
+	}
+	report.SetVersion("next", res.Version.String())
+	for _, t := range res.Timings {
+		report.AddStep(t.Step, t.Duration)
+	}
+	report.AddLink("release", res.ReleaseURL)
+	report.SetResult("release", res)
+}
@@ -1,22 +1,407 @@
 package release
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/coffee377/autoctl/cmd/version"
+	"github.com/coffee377/autoctl/internal/httpclient"
+	"github.com/coffee377/autoctl/internal/i18n"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/internal/train"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+type releaseOptions struct {
+	release   version.ReleaseTypeValue // 版本变动类型
+	preid     string                   // 预发布版本标识符
+	preMode   string                   // 预发布版本切换标识符时的计数方式
+	dryRun    bool                     // 仅计算版本
+	push      bool                     // 是否推送标签
+	publish   bool                     // 是否在代码托管平台创建版本发布
+	remote    string                   // 推送的远程仓库
+	mirrors   []string                 // 同时推送的镜像仓库
+	train     bool                     // 执行发布计划中的下一次发布
+	force     bool                     // 忽略计划发布日期
+	json      bool                     // 以 JSON 格式输出发布结果
+	noChange  string                   // 没有可发布的提交时的处理方式
+	approval  int                      // 等待审批的发布合并请求编号
+	sinceTag  bool                     // 从 HEAD 读取提交直到最近的版本标签
+	to        string                   // 人工指定的目标版本
+	reason    string                   // 人工指定目标版本的原因
+	downgrade bool                     // 允许目标版本不大于当前版本
+}
+
 func NewReleaseCmd() (releaseCmd *cobra.Command) {
+	opts := &releaseOptions{}
 	releaseCmd = &cobra.Command{
-		Use:   "release",
-		Short: "A brief description of your command",
-		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println("release called")
+		Use:     "release [release-type]",
+		Aliases: []string{"bump"},
+		Short:   "Create a new version tag from the latest release",
+		Example: `  autoctl release minor --push
+  autoctl bump --to 2.0.0 --reason "new licensing model" --push`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				if err := opts.release.Set(args[0]); err != nil {
+					return err
+				}
+			}
+			if opts.to != "" && (opts.release.Changed != 0 || opts.train) {
+				return errors.New("--to can not be used with a release type or --train")
+			}
+			releaseOpts, err := loadOptions(cmd, opts)
+			if err != nil {
+				return err
+			}
+			releaser := release.New(releaseOpts)
+			var res *release.Result
+			if opts.train {
+				res, err = releaseTrain(releaseOpts, opts.force)
+			} else {
+				res, err = releaser.Release()
+			}
+			Audit(cmd, releaser, nil, res, err)
+			var noChange *release.NoChangeError
+			if errors.As(err, &noChange) {
+				cmd.SilenceUsage = true
+			}
+			if err != nil {
+				return err
+			}
+			return printResult(cmd, res, opts.json)
 		},
 	}
+
+	releaseCmd.Flags().VarP(&opts.release, "release-type", "r", fmt.Sprintf("release type, one of %s (default inferred from commits)", strings.Join(semver.ReleaseTypeNames(), "|")))
+	releaseCmd.Flags().StringVar(&opts.preid, "preid", "", "identifier to be used to prefix premajor, preminor, prepatch or prerelease version increments")
+	releaseCmd.Flags().StringVar(&opts.preMode, "preid-mode", "", fmt.Sprintf("counter behavior when switching prerelease identifier, one of %s", strings.Join(semver.PreIdModeNames(), "|")))
+	releaseCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "compute the next version without creating tags")
+	releaseCmd.Flags().BoolVar(&opts.push, "push", false, "push the created tag to remote")
+	releaseCmd.Flags().BoolVar(&opts.publish, "publish", false, "create a release on the configured provider (implies --push)")
+	releaseCmd.Flags().StringVar(&opts.remote, "remote", "", "remote to push tags to (default origin)")
+	releaseCmd.Flags().StringSliceVar(&opts.mirrors, "mirror", nil, "additional remotes to push tags to, all pushes are rolled back if any fails")
+	releaseCmd.Flags().BoolVar(&opts.train, "train", false, "release the next planned entry of the release train")
+	releaseCmd.Flags().BoolVar(&opts.force, "force", false, "release the next planned entry even if its date has not come")
+	releaseCmd.Flags().BoolVar(&opts.json, "json", false, "print the release result as json")
+	releaseCmd.Flags().IntVar(&opts.approval, "approval-pr", 0, "wait for the approval label or comment on the pull request before tagging (enables release.approval)")
+	releaseCmd.Flags().BoolVar(&opts.sinceTag, "since-tag", false, "stream commits from HEAD and stop at the first version tag instead of computing the tag range, faster on huge linear histories")
+	releaseCmd.Flags().StringVar(&opts.to, "to", "", "release the given version instead of computing it, it must be greater than the current version")
+	releaseCmd.Flags().StringVar(&opts.reason, "reason", "", "reason for releasing the version given by --to, recorded in the tag message")
+	releaseCmd.Flags().BoolVar(&opts.downgrade, "allow-downgrade", false, "allow the version given by --to to be lower than the current version")
+	releaseCmd.Flags().StringVar(&opts.noChange, "on-no-change", "", fmt.Sprintf("behavior when there are no releasable commits, one of %s (default patch), fail exits with code %d", strings.Join(release.NoChangeNames(), "|"), release.ExitNoChange))
+	_ = releaseCmd.RegisterFlagCompletionFunc("release-type", version.CompleteReleaseType)
+	_ = releaseCmd.RegisterFlagCompletionFunc("on-no-change", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return release.NoChangeNames(), cobra.ShellCompDirectiveNoFileComp
+	})
 	return releaseCmd
 }
 
 func RegisterCommandRecursive(parent *cobra.Command) {
-	versionCmd := NewReleaseCmd()
-	parent.AddCommand(versionCmd)
+	releaseCmd := NewReleaseCmd()
+	releaseCmd.AddCommand(NewPruneCmd())
+	releaseCmd.AddCommand(NewInfoCmd())
+	releaseCmd.AddCommand(NewStatusCmd())
+	releaseCmd.AddCommand(NewPreviewCmd())
+	parent.AddCommand(releaseCmd)
+	parent.AddCommand(NewHotfixCmd())
+}
+
+// LoadConfig 读取配置文件中的 release、provider、gitops、webhooks、support 及全局 http 节点
+func LoadConfig(cmd *cobra.Command) (release.Options, error) {
+	releaseOpts := release.Options{}
+	if err := viper.UnmarshalKey("release", &releaseOpts); err != nil {
+		return releaseOpts, err
+	}
+	if err := viper.UnmarshalKey("provider", &releaseOpts.Provider); err != nil {
+		return releaseOpts, err
+	}
+	var global httpclient.Config
+	if err := viper.UnmarshalKey("http", &global); err != nil {
+		return releaseOpts, err
+	}
+	releaseOpts.Provider.HTTP = releaseOpts.Provider.HTTP.Merge(global)
+	releaseOpts.Summary.HTTP = releaseOpts.Summary.HTTP.Merge(global)
+	if err := viper.UnmarshalKey("gitops", &releaseOpts.GitOps); err != nil {
+		return releaseOpts, err
+	}
+	for i := range releaseOpts.GitOps {
+		releaseOpts.GitOps[i].Provider.HTTP = releaseOpts.GitOps[i].Provider.HTTP.Merge(global)
+	}
+	if err := viper.UnmarshalKey("webhooks", &releaseOpts.Webhooks); err != nil {
+		return releaseOpts, err
+	}
+	if err := viper.UnmarshalKey("support", &releaseOpts.Support); err != nil {
+		return releaseOpts, err
+	}
+	for i := range releaseOpts.Webhooks {
+		releaseOpts.Webhooks[i].HTTP = releaseOpts.Webhooks[i].HTTP.Merge(global)
+	}
+	if err := viper.UnmarshalKey("storage", &releaseOpts.Storage); err != nil {
+		return releaseOpts, err
+	}
+	for i := range releaseOpts.Storage {
+		releaseOpts.Storage[i].HTTP = releaseOpts.Storage[i].HTTP.Merge(global)
+	}
+	if err := viper.UnmarshalKey("repositories", &releaseOpts.Repositories); err != nil {
+		return releaseOpts, err
+	}
+	for i := range releaseOpts.Repositories {
+		releaseOpts.Repositories[i].HTTP = releaseOpts.Repositories[i].HTTP.Merge(global)
+	}
+	if err := viper.UnmarshalKey("oci", &releaseOpts.OCI); err != nil {
+		return releaseOpts, err
+	}
+	if err := viper.UnmarshalKey("homebrew", &releaseOpts.Homebrew); err != nil {
+		return releaseOpts, err
+	}
+	if err := viper.UnmarshalKey("scoop", &releaseOpts.Scoop); err != nil {
+		return releaseOpts, err
+	}
+	if err := viper.UnmarshalKey("audit", &releaseOpts.Audit); err != nil {
+		return releaseOpts, err
+	}
+	releaseOpts.Audit.HTTP = releaseOpts.Audit.HTTP.Merge(global)
+	if err := viper.UnmarshalKey("metrics", &releaseOpts.Metrics); err != nil {
+		return releaseOpts, err
+	}
+	releaseOpts.Metrics.HTTP = releaseOpts.Metrics.HTTP.Merge(global)
+	if err := viper.UnmarshalKey("hooks", &releaseOpts.Hooks); err != nil {
+		return releaseOpts, err
+	}
+	releaseOpts.Homebrew.Tap.Provider.HTTP = releaseOpts.Homebrew.Tap.Provider.HTTP.Merge(global)
+	releaseOpts.Scoop.Bucket.Provider.HTTP = releaseOpts.Scoop.Bucket.Provider.HTTP.Merge(global)
+	for i := range releaseOpts.OCI {
+		releaseOpts.OCI[i].Registry.HTTP = releaseOpts.OCI[i].Registry.HTTP.Merge(global)
+	}
+	addSecrets(releaseOpts)
+	releaseOpts.Cwd, _ = cmd.Flags().GetString("directory")
+	releaseOpts.Verbose, _ = cmd.Flags().GetBool("verbose")
+	return releaseOpts, nil
+}
+
+// addSecrets 配置文件中直接填写的令牌及签名密钥不出现在输出中
+func addSecrets(opts release.Options) {
+	log.AddSecret(opts.Provider.Token)
+	for _, g := range opts.GitOps {
+		log.AddSecret(g.Provider.Token)
+	}
+	for _, w := range opts.Webhooks {
+		log.AddSecret(w.Secret)
+	}
+	for _, s := range opts.Storage {
+		log.AddSecret(s.SecretAccessKey)
+	}
+	for _, repo := range opts.Repositories {
+		log.AddSecret(repo.Password)
+		log.AddSecret(repo.Token)
+	}
+	for _, target := range opts.OCI {
+		log.AddSecret(target.Registry.Password)
+	}
+	log.AddSecret(opts.Homebrew.Tap.Provider.Token, opts.Scoop.Bucket.Provider.Token, opts.Audit.Secret, opts.Metrics.Password)
+	for k, v := range opts.Summary.Headers {
+		if k = strings.ToLower(k); k == "authorization" || strings.Contains(k, "token") || strings.Contains(k, "key") {
+			log.AddSecret(v)
+		}
+	}
+}
+
+// Validate 检查发布选项，对象存储、制品仓库及 OCI 制品在打标签后上传，提前检查配置
+func Validate(opts release.Options) error {
+	_, err := release.ParseNoChange(opts.OnNoChange)
+	if err != nil {
+		return err
+	}
+	if err = semver.PreReleaseIdentifier(opts.PreId).Validate(); err != nil {
+		return err
+	}
+	if err = opts.Bots.Validate(); err != nil {
+		return err
+	}
+	if err = opts.Labels.Validate(); err != nil {
+		return err
+	}
+	if err = opts.Convention.Validate(); err != nil {
+		return err
+	}
+	if err = opts.MergeQueue.Validate(); err != nil {
+		return err
+	}
+	if err = opts.Milestones.Validate(); err != nil {
+		return err
+	}
+	if err = opts.Security.Validate(); err != nil {
+		return err
+	}
+	if err = opts.Aliases.Validate(); err != nil {
+		return err
+	}
+	if err = opts.APICheck.Validate(); err != nil {
+		return err
+	}
+	for _, l := range opts.Locales {
+		if err = l.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, s := range opts.Storage {
+		if err = s.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, repo := range opts.Repositories {
+		if err = repo.Validate(); err != nil {
+			return err
+		}
+	}
+	for _, target := range opts.OCI {
+		if err = target.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadOptions 合并配置文件与命令行参数，命令行参数优先
+func loadOptions(cmd *cobra.Command, opts *releaseOptions) (release.Options, error) {
+	releaseOpts, err := LoadConfig(cmd)
+	if err != nil {
+		return releaseOpts, err
+	}
+	releaseOpts.Release = opts.release.Changed
+	releaseOpts.DryRun = opts.dryRun
+	releaseOpts.Target = opts.to
+	releaseOpts.Reason = opts.reason
+	releaseOpts.Downgrade = opts.downgrade
+	if opts.preid != "" {
+		releaseOpts.PreId = opts.preid
+	}
+	if opts.remote != "" {
+		releaseOpts.Remote = opts.remote
+	}
+	if len(opts.mirrors) > 0 {
+		releaseOpts.Mirrors = opts.mirrors
+	}
+	if opts.approval > 0 {
+		releaseOpts.Approval.Enabled = true
+		releaseOpts.Approval.PullRequest = opts.approval
+	}
+	if opts.noChange != "" {
+		releaseOpts.OnNoChange = opts.noChange
+	}
+	if err = Validate(releaseOpts); err != nil {
+		return releaseOpts, err
+	}
+	if cmd.Flags().Changed("since-tag") {
+		releaseOpts.SinceTag = opts.sinceTag
+	}
+	if cmd.Flags().Changed("push") {
+		releaseOpts.Push = opts.push
+	}
+	if cmd.Flags().Changed("publish") {
+		releaseOpts.Publish = opts.publish
+	}
+	mode, err := version.PreIdMode(cmd, opts.preMode)
+	if err != nil {
+		return releaseOpts, err
+	}
+	releaseOpts.PreIdMode = mode
+	return releaseOpts, nil
+}
+
+// releaseTrain 发布计划中的下一次发布，并在计划文件中标记为已发布
+func releaseTrain(opts release.Options, force bool) (*release.Result, error) {
+	cfg := train.Config{}
+	if err := viper.UnmarshalKey("train", &cfg); err != nil {
+		return nil, err
+	}
+	file := cfg.Path(opts.Cwd)
+	plan, err := train.Load(file)
+	if err != nil {
+		return nil, i18n.Errorf("load release plan: %w, run 'autoctl plan --write' first", err)
+	}
+	entry, ok := plan.Next()
+	if !ok {
+		return nil, i18n.Errorf("all planned releases have been released, run 'autoctl plan --write' to plan more")
+	}
+	if !force && entry.Date > time.Now().Format(train.DateLayout) {
+		return nil, i18n.Errorf("next planned release %s is scheduled on %s, use --force to release now", entry.Version, entry.Date)
+	}
+	if opts.Release, err = semver.ParseReleaseType(entry.Release); err != nil {
+		return nil, err
+	}
+	releaser := release.New(opts)
+	res, err := releaser.WithLock(func() (*release.Result, error) {
+		current, err := releaser.Current()
+		if err != nil {
+			return nil, err
+		}
+		next, err := semver.Version(entry.Version)
+		if err != nil {
+			return nil, err
+		}
+		if current != nil && next.Compare(current) <= 0 {
+			return nil, i18n.Errorf("planned version %s is not greater than current version %s, the plan is stale, run 'autoctl plan --write'", next, current)
+		}
+		return releaser.ReleaseVersion(current, next)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !opts.DryRun {
+		entry.Released = true
+		entry.Tag = res.Tag
+		if err = plan.Save(file); err != nil {
+			return res, err
+		}
+	}
+	return res, nil
+}
+
+// printTimings 向标准错误输出各发布步骤的耗时，标准输出只保留版本号以便脚本读取
+func printTimings(cmd *cobra.Command, res *release.Result) {
+	if len(res.Timings) == 0 {
+		return
+	}
+	w := tabwriter.NewWriter(cmd.ErrOrStderr(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, i18n.T("release timings:"))
+	var total time.Duration
+	for _, t := range res.Timings {
+		total += t.Duration
+		_, _ = fmt.Fprintf(w, "  %s\t%s\n", t.Step, t.Duration.Round(time.Millisecond))
+	}
+	_, _ = fmt.Fprintf(w, "  %s\t%s\n", "total", total.Round(time.Millisecond))
+	_ = w.Flush()
+}
+
+func printResult(cmd *cobra.Command, res *release.Result, asJSON bool) error {
+	if asJSON {
+		data, err := json.MarshalIndent(res, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+		return err
+	}
+	if res.Skipped {
+		_, err := fmt.Fprint(cmd.OutOrStdout(), i18n.T("no release, current version is %s\n", res.Version))
+		return err
+	}
+	if _, err := fmt.Fprintln(cmd.OutOrStdout(), res.Version.String()); err != nil {
+		return err
+	}
+	printTimings(cmd, res)
+	if res.DryRun && res.Changelog != nil {
+		_, err := fmt.Fprint(cmd.OutOrStdout(), "\n"+res.Changelog.Markdown())
+		return err
+	}
+	return nil
 }
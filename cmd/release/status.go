@@ -0,0 +1,142 @@
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/coffee377/autoctl/cmd/version"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/pkg/semver"
+	"github.com/spf13/cobra"
+)
+
+type statusOptions struct {
+	release   version.ReleaseTypeValue // 版本变动类型
+	preid     string                   // 预发布版本标识符
+	sha       string                   // 设置状态的提交
+	targetURL string                   // 状态链接地址
+	noReport  bool                     // 只输出状态，不提交到平台
+}
+
+func NewStatusCmd() *cobra.Command {
+	opts := &statusOptions{}
+	statusCmd := &cobra.Command{
+		Use:   "status [release-type]",
+		Short: "Report the release impact of the commit as commit statuses",
+		Long: `Compute the release as a dry run and set two commit statuses on the configured provider:
+` + release.StatusVersion + ` with the next version, e.g. "autoctl: next version 1.4.0", and
+` + release.StatusChangelog + ` with the number of changes per changelog section.
+
+Run it in the pull request pipeline so reviewers see the release impact of their changes before merge,
+each push sets the statuses of the new head commit. The commit defaults to $CI_COMMIT_SHA in GitLab CI,
+the pull request head of $GITHUB_EVENT_PATH in GitHub Actions, otherwise HEAD.`,
+		Example: `  autoctl release status minor
+  autoctl release status --no-report`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				if err := opts.release.Set(args[0]); err != nil {
+					return err
+				}
+			}
+			releaseOpts, err := LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			releaseOpts.Release = opts.release.Changed
+			releaseOpts.DryRun = true
+			releaseOpts.Push = false
+			releaseOpts.Publish = false
+			if opts.preid != "" {
+				releaseOpts.PreId = opts.preid
+			}
+			releaser := release.New(releaseOpts)
+			res, releaseErr := releaser.Release()
+			statuses := release.Statuses(res, releaseErr)
+			sha := opts.sha
+			if sha == "" {
+				sha = headSHA()
+			}
+			if sha == "" && res != nil {
+				sha = res.Commit
+			}
+			if sha == "" {
+				if sha, err = releaser.Git().Head(); err != nil {
+					return err
+				}
+			}
+			targetURL := opts.targetURL
+			if targetURL == "" {
+				targetURL = jobURL()
+			}
+			for _, s := range statuses {
+				s.SHA = sha
+				s.TargetURL = targetURL
+			}
+			if err = printStatuses(cmd, statuses); err != nil {
+				return err
+			}
+			if opts.noReport {
+				return nil
+			}
+			return releaser.ReportStatus(statuses)
+		},
+	}
+	statusCmd.Flags().VarP(&opts.release, "release-type", "r", fmt.Sprintf("release type, one of %s (default inferred from commits)", strings.Join(semver.ReleaseTypeNames(), "|")))
+	statusCmd.Flags().StringVar(&opts.preid, "preid", "", "identifier to be used to prefix premajor, preminor, prepatch or prerelease version increments")
+	statusCmd.Flags().StringVar(&opts.sha, "sha", "", "commit to set the statuses on (default the pull request head or HEAD)")
+	statusCmd.Flags().StringVar(&opts.targetURL, "target-url", "", "url linked from the statuses (default the ci job url)")
+	statusCmd.Flags().BoolVar(&opts.noReport, "no-report", false, "print the statuses without setting them on the provider")
+	_ = statusCmd.RegisterFlagCompletionFunc("release-type", version.CompleteReleaseType)
+	return statusCmd
+}
+
+// headSHA CI 中合并请求源分支的最新提交，GitHub Actions 中 GITHUB_SHA 为合并提交，需要从事件中读取
+func headSHA() string {
+	if sha := os.Getenv("CI_COMMIT_SHA"); sha != "" {
+		return sha
+	}
+	path := os.Getenv("GITHUB_EVENT_PATH")
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	event := struct {
+		PullRequest struct {
+			Head struct {
+				SHA string `json:"sha"`
+			} `json:"head"`
+		} `json:"pull_request"`
+	}{}
+	if json.Unmarshal(data, &event) == nil && event.PullRequest.Head.SHA != "" {
+		return event.PullRequest.Head.SHA
+	}
+	return os.Getenv("GITHUB_SHA")
+}
+
+// jobURL 当前 CI 任务的地址
+func jobURL() string {
+	if u := os.Getenv("CI_JOB_URL"); u != "" {
+		return u
+	}
+	if run := os.Getenv("GITHUB_RUN_ID"); run != "" {
+		return fmt.Sprintf("%s/%s/actions/runs/%s", os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), run)
+	}
+	return ""
+}
+
+func printStatuses(cmd *cobra.Command, statuses []*provider.CommitStatus) error {
+	w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "CONTEXT\tSTATE\tDESCRIPTION")
+	for _, s := range statuses {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", s.Context, s.State, s.Description)
+	}
+	return w.Flush()
+}
@@ -2,15 +2,57 @@ package cmd
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
+	"github.com/coffee377/autoctl/cmd/assets"
+	"github.com/coffee377/autoctl/cmd/audit"
+	"github.com/coffee377/autoctl/cmd/buildnumber"
+	"github.com/coffee377/autoctl/cmd/changelog"
+	"github.com/coffee377/autoctl/cmd/chatops"
+	"github.com/coffee377/autoctl/cmd/check"
+	"github.com/coffee377/autoctl/cmd/classify"
+	"github.com/coffee377/autoctl/cmd/commit"
+	"github.com/coffee377/autoctl/cmd/deps"
+	"github.com/coffee377/autoctl/cmd/diff"
+	"github.com/coffee377/autoctl/cmd/doctor"
+	"github.com/coffee377/autoctl/cmd/env"
+	"github.com/coffee377/autoctl/cmd/freeze"
 	"github.com/coffee377/autoctl/cmd/image"
+	"github.com/coffee377/autoctl/cmd/kustomize"
+	"github.com/coffee377/autoctl/cmd/lint"
+	"github.com/coffee377/autoctl/cmd/meta"
+	"github.com/coffee377/autoctl/cmd/nightly"
+	"github.com/coffee377/autoctl/cmd/plan"
+	"github.com/coffee377/autoctl/cmd/promote"
+	"github.com/coffee377/autoctl/cmd/registry"
+	"github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/cmd/semver"
+	"github.com/coffee377/autoctl/cmd/serve"
+	"github.com/coffee377/autoctl/cmd/support"
+	"github.com/coffee377/autoctl/cmd/sync"
+	"github.com/coffee377/autoctl/cmd/tag"
+	"github.com/coffee377/autoctl/cmd/ui"
 	"github.com/coffee377/autoctl/cmd/version"
+	"github.com/coffee377/autoctl/cmd/yank"
+	"github.com/coffee377/autoctl/internal/checkout"
+	"github.com/coffee377/autoctl/internal/httpclient"
+	"github.com/coffee377/autoctl/internal/i18n"
+	"github.com/coffee377/autoctl/internal/preset"
+	"github.com/coffee377/autoctl/internal/progress"
+	"github.com/coffee377/autoctl/internal/report"
+	"github.com/coffee377/autoctl/internal/runctx"
+	"github.com/coffee377/autoctl/internal/shell"
+	"github.com/coffee377/autoctl/internal/telemetry"
+	"github.com/coffee377/autoctl/internal/tmpl"
+	"github.com/coffee377/autoctl/internal/workspace"
 	"github.com/coffee377/autoctl/pkg/log"
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"os"
 	"path"
+	"path/filepath"
+	"strings"
 	"text/template"
 )
 
@@ -106,21 +148,80 @@ var (
 )
 
 type RootOptions struct {
-	cwd       string // 当前工作目录
-	directory string // 子目录
-	config    string // 配置文件名称
-	verbose   bool   // 输出详细信息
+	cwd        string // 当前工作目录
+	directory  string // 子目录
+	config     string // 配置文件名称
+	verbose    bool   // 输出详细信息
+	trace      bool   // 输出执行的外部命令
+	traceFile  string // 外部命令执行记录文件
+	noCache    bool   // 禁用接口响应缓存
+	noProgress bool   // 不显示进度
+	report     string // 执行报告文件
+	lang       string // 界面语言
+	repo       string // 未在工作区中执行时检出的仓库地址或裸仓库路径
+	repoBranch string // 检出的分支
+}
+
+var (
+	traceOut     *os.File
+	workdir      *checkout.Checkout // --repo 检出的临时工作区
+	stopTimeouts = func() {}        // 释放中断信号监听
+)
+
+// RedactConfig 输出中需要屏蔽的密钥，对应配置文件 redact 节点
+type RedactConfig struct {
+	Patterns []string `mapstructure:"patterns"` // 密钥正则表达式，常见平台的令牌格式已内置
+	Env      []string `mapstructure:"env"`      // 值需要屏蔽的环境变量，名称包含 TOKEN、SECRET、PASSWORD 等的变量已自动屏蔽
 }
 
 func init() {
-	cobra.OnInitialize(loadConfig)
+	cobra.OnInitialize(loadConfig, setupLang, setupTrace, setupCache, setupProgress, setupReport, setupTelemetry, setupWorkspace, setupShell, setupTimeouts, setupCheckout)
 	rootCmd.PersistentFlags().StringVarP(&rooOpts.config, "file", "f", "", "config file (default is $HOME/auto.yml)")
 	rootCmd.PersistentFlags().StringVarP(&rooOpts.cwd, "directory", "C", "", "change execution directory")
 	rootCmd.PersistentFlags().StringVarP(&rooOpts.directory, "--module-path", "m", "", "change execution directory into submodule path")
 	rootCmd.PersistentFlags().BoolVarP(&rooOpts.verbose, "verbose", "v", false, "verbose output")
+	rootCmd.PersistentFlags().BoolVar(&rooOpts.trace, "trace", false, "log every executed git/external command with its arguments, directory, duration and exit code")
+	rootCmd.PersistentFlags().StringVar(&rooOpts.traceFile, "trace-file", "", "write the command trace as json lines to the file (implies --trace)")
+	rootCmd.PersistentFlags().BoolVar(&rooOpts.noCache, "no-cache", false, "do not use the http response cache configured by http.cache")
+	rootCmd.PersistentFlags().BoolVar(&rooOpts.noProgress, "no-progress", false, "do not show progress of clones, uploads and multi-repository releases (disabled when stderr is not a terminal or CI is set)")
+	rootCmd.PersistentFlags().StringVar(&rooOpts.report, "report", "", "write a json report of the inputs, versions, steps, timings, warnings and links to the file after the command, e.g. run-report.json for a ci artifact (default from report.file)")
+	rootCmd.PersistentFlags().StringVar(&rooOpts.repo, "repo", "", "run against a remote repository url or a bare repository, sparse checked out into a temporary directory (-C is then relative to it)")
+	rootCmd.PersistentFlags().StringVar(&rooOpts.repoBranch, "repo-branch", "", "branch to check out with --repo (default the remote HEAD)")
+	rootCmd.PersistentFlags().StringVar(&rooOpts.lang, "lang", "", fmt.Sprintf("language of messages and prompts, one of %s (default from %s, LC_ALL, LC_MESSAGES or LANG)", strings.Join(i18n.LangNames(), "|"), i18n.EnvLang))
+	_ = rootCmd.RegisterFlagCompletionFunc("lang", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return i18n.LangNames(), cobra.ShellCompDirectiveNoFileComp
+	})
 
 	image.RegisterCommandRecursive(rootCmd, image.RootOptions{})
 	version.RegisterCommandRecursive(rootCmd)
+	release.RegisterCommandRecursive(rootCmd)
+	plan.RegisterCommandRecursive(rootCmd)
+	kustomize.RegisterCommandRecursive(rootCmd)
+	check.RegisterCommandRecursive(rootCmd)
+	commit.RegisterCommandRecursive(rootCmd)
+	lint.RegisterCommandRecursive(rootCmd)
+	changelog.RegisterCommandRecursive(rootCmd)
+	diff.RegisterCommandRecursive(rootCmd)
+	tag.RegisterCommandRecursive(rootCmd)
+	meta.RegisterCommandRecursive(rootCmd)
+	deps.RegisterCommandRecursive(rootCmd)
+	semver.RegisterCommandRecursive(rootCmd)
+	ui.RegisterCommandRecursive(rootCmd)
+	serve.RegisterCommandRecursive(rootCmd)
+	chatops.RegisterCommandRecursive(rootCmd)
+	nightly.RegisterCommandRecursive(rootCmd)
+	support.RegisterCommandRecursive(rootCmd)
+	assets.RegisterCommandRecursive(rootCmd)
+	promote.RegisterCommandRecursive(rootCmd)
+	registry.RegisterCommandRecursive(rootCmd)
+	sync.RegisterCommandRecursive(rootCmd)
+	buildnumber.RegisterCommandRecursive(rootCmd)
+	audit.RegisterCommandRecursive(rootCmd)
+	classify.RegisterCommandRecursive(rootCmd)
+	freeze.RegisterCommandRecursive(rootCmd)
+	env.RegisterCommandRecursive(rootCmd)
+	yank.RegisterCommandRecursive(rootCmd)
+	doctor.RegisterCommandRecursive(rootCmd)
 }
 
 func loadConfig() {
@@ -146,17 +247,242 @@ func loadConfig() {
 
 	_ = viper.ReadInConfig()
 	configFile := viper.ConfigFileUsed()
+	loadPresets(configFile)
+	loadRedact()
+	loadTemplates()
 
 	if configFile != "" && log.IsDebugEnabled() {
-		log.Debug("Using config file: %s", configFile)
+		log.Debug(i18n.T("Using config file: %s"), configFile)
 	}
 
 	//_ = viper.Unmarshal(&serverOption)
 
 }
 
+// loadPresets 配置文件 extends 节点继承共享的预设配置，配置文件中的值覆盖预设；预设无法读取或校验失败时退出，
+// 避免以不完整的配置发布
+func loadPresets(file string) {
+	if file == "" || !viper.IsSet(preset.Key) {
+		return
+	}
+	opts := preset.Options{NoCache: rooOpts.noCache}
+	if err := viper.UnmarshalKey("http", &opts.HTTP); err != nil {
+		log.Warn(i18n.T("load presets: %v"), err)
+	}
+	// 只合并配置文件本身的内容，AllSettings 还包含环境变量的值
+	local := viper.New()
+	local.SetConfigFile(file)
+	err := local.ReadInConfig()
+	var settings map[string]interface{}
+	if err == nil {
+		settings, err = preset.Resolve(file, local.AllSettings(), opts)
+	}
+	if err == nil {
+		err = viper.MergeConfigMap(settings)
+	}
+	if err != nil {
+		log.Fatal(i18n.T("load presets: %v"), err)
+	}
+}
+
+// loadRedact 读取配置文件 redact 节点，日志、命令追踪及命令输出均会屏蔽其中的密钥
+func loadRedact() {
+	cfg := RedactConfig{}
+	if err := viper.UnmarshalKey("redact", &cfg); err != nil {
+		log.Warn(i18n.T("load redact config: %v"), err)
+		return
+	}
+	if err := log.AddPattern(cfg.Patterns...); err != nil {
+		log.Warn(i18n.T("invalid redact pattern: %v"), err)
+	}
+	log.AddSecretEnv(cfg.Env...)
+}
+
+// loadTemplates 读取配置文件 templates 节点中用户定义的模板片段，所有模板均可引用
+func loadTemplates() {
+	cfg := tmpl.Config{}
+	if err := viper.UnmarshalKey("templates", &cfg); err != nil {
+		log.Warn(i18n.T("load templates config: %v"), err)
+		return
+	}
+	if err := tmpl.SetHelpers(cfg.Helpers); err != nil {
+		log.Warn("%v", err)
+	}
+}
+
+// setupLang 确定界面语言，优先级依次为 --lang、配置文件 lang 节点及环境变量
+func setupLang() {
+	lang := rooOpts.lang
+	if lang == "" && os.Getenv(i18n.EnvLang) == "" {
+		lang = viper.GetString("lang")
+	}
+	if lang == "" {
+		lang = string(i18n.Detect())
+	}
+	if err := i18n.SetLang(lang); err != nil {
+		log.Warn("%v", err)
+	}
+}
+
+// setupTrace 开启外部命令追踪，便于排查 CI 中失败的发布
+func setupTrace() {
+	if rooOpts.traceFile != "" {
+		f, err := os.OpenFile(rooOpts.traceFile, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+		if err != nil {
+			log.Warn(i18n.T("open trace file: %v"), err)
+		} else {
+			traceOut = f
+		}
+	}
+	switch {
+	case traceOut != nil:
+		log.EnableTrace(traceOut)
+	case rooOpts.trace:
+		log.EnableTrace(nil)
+	}
+}
+
+// setupCache --no-cache 时禁用接口响应缓存，确保读取最新的发布及合并请求
+func setupCache() {
+	if rooOpts.noCache {
+		httpclient.DisableCache()
+	}
+}
+
+// setupProgress --no-progress 时不显示进度
+func setupProgress() {
+	if rooOpts.noProgress {
+		progress.Disable()
+	}
+}
+
+// setupReport --report 或配置文件 report.file 指定文件时，命令结束后写入执行报告
+func setupReport() {
+	file := rooOpts.report
+	if file == "" {
+		file = viper.GetString("report.file")
+	}
+	if file == "" {
+		return
+	}
+	report.Enable(file)
+	report.SetConfig(viper.ConfigFileUsed())
+	log.OnWarn(report.Warn)
+}
+
+// setupTelemetry 读取配置文件 telemetry 节点，设置了 OTLP 地址时命令结束后导出链路及指标
+func setupTelemetry() {
+	cfg := telemetry.Config{}
+	if err := viper.UnmarshalKey("telemetry", &cfg); err != nil {
+		log.Warn(i18n.T("load telemetry config: %v"), err)
+		return
+	}
+	global := httpclient.Config{}
+	_ = viper.UnmarshalKey("http", &global)
+	cfg.HTTP = cfg.HTTP.Merge(global)
+	telemetry.Setup(cfg)
+}
+
+// setupWorkspace 注册配置文件 workspace.detectors 中声明的软件包检测器
+func setupWorkspace() {
+	cfg := workspace.Config{}
+	if err := viper.UnmarshalKey("workspace", &cfg); err != nil {
+		log.Warn(i18n.T("load workspace config: %v"), err)
+		return
+	}
+	if err := cfg.Register(); err != nil {
+		log.Warn(i18n.T("load workspace config: %v"), err)
+	}
+}
+
+// setupShell 读取配置文件 shells 节点，指定执行钩子及外部命令脚本的 shell；
+// 节点不命名为 shell，避免 AutomaticEnv 时被 SHELL 环境变量覆盖
+func setupShell() {
+	cfg := shell.Config{}
+	if err := viper.UnmarshalKey("shells", &cfg); err != nil {
+		log.Warn(i18n.T("load shells config: %v"), err)
+		return
+	}
+	if err := shell.Setup(cfg); err != nil {
+		log.Warn(i18n.T("load shells config: %v"), err)
+	}
+}
+
+// setupTimeouts 读取配置文件 timeouts 节点，收到中断信号或超时时取消进行中的 git 命令、外部命令及 HTTP 请求
+func setupTimeouts() {
+	cfg := runctx.Config{}
+	if err := viper.UnmarshalKey("timeouts", &cfg); err != nil {
+		log.Warn(i18n.T("load timeouts config: %v"), err)
+	}
+	stopTimeouts = runctx.Setup(cfg)
+}
+
+// setupCheckout --repo 时将仓库稀疏检出到临时目录，并以其作为工作目录，检出的内容由配置文件 checkout 节点指定
+func setupCheckout() {
+	if rooOpts.repo == "" {
+		return
+	}
+	cfg := checkout.Config{}
+	if err := viper.UnmarshalKey("checkout", &cfg); err != nil {
+		log.Fatal(i18n.T("load checkout config: %v"), err)
+	}
+	c, err := checkout.New(rooOpts.repo, rooOpts.repoBranch, cfg, rooOpts.verbose)
+	if err != nil {
+		log.Fatal("%v", err)
+	}
+	workdir = c
+	dir := c.Dir
+	if rooOpts.cwd != "" && !filepath.IsAbs(rooOpts.cwd) {
+		dir = filepath.Join(dir, rooOpts.cwd)
+	}
+	_ = rootCmd.PersistentFlags().Set("directory", dir)
+}
+
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
-		os.Exit(1)
+	// 日志及命令输出前清除进度行
+	log.SetOutput(progress.Writer(os.Stdout))
+	rootCmd.SetOut(log.NewRedactWriter(progress.Writer(os.Stdout)))
+	rootCmd.SetErr(log.NewRedactWriter(progress.Writer(os.Stderr)))
+	executed, err := rootCmd.ExecuteC()
+	interrupted := runctx.Interrupted()
+	stopTimeouts()
+	code := exitCode(err, interrupted)
+	if rerr := report.Finish(executed, err, code, interrupted); rerr != nil {
+		log.Warn("%v", rerr)
+	}
+	if terr := telemetry.Finish(executed, err, interrupted); terr != nil {
+		log.Warn("%v", terr)
+	}
+	if traceOut != nil {
+		_ = traceOut.Close()
+	}
+	if workdir != nil {
+		if cerr := workdir.Close(); cerr != nil {
+			log.Warn("%v", cerr)
+		}
+	}
+	if code != 0 {
+		os.Exit(code)
+	}
+}
+
+// exitCode 进程退出码
+func exitCode(err error, interrupted bool) int {
+	if err == nil {
+		return 0
 	}
+	if interrupted {
+		// 与 shell 中被 SIGINT 终止的进程一致
+		return 130
+	}
+	var coder exitCoder
+	if errors.As(err, &coder) {
+		return coder.ExitStatus()
+	}
+	return 1
+}
+
+// exitCoder 需要以指定退出码结束进程的错误，便于流水线根据退出码分支
+type exitCoder interface {
+	ExitStatus() int
 }
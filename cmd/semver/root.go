@@ -0,0 +1,213 @@
+package semver
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/coffee377/autoctl/cmd/version"
+	"github.com/coffee377/autoctl/internal/i18n"
+	"github.com/coffee377/autoctl/internal/stamp"
+	"github.com/coffee377/autoctl/pkg/semver"
+	"github.com/spf13/cobra"
+)
+
+func NewSemverCmd() *cobra.Command {
+	semverCmd := &cobra.Command{
+		Use:   "semver",
+		Short: "Semantic version math and engine diagnostics",
+	}
+	semverCmd.AddCommand(NewIncrementCmd(), NewPreviousCmd(), NewDistanceCmd(), NewWindowsCmd(), NewSelfTestCmd())
+	return semverCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewSemverCmd())
+}
+
+// steps 解析可选的步数参数，默认为 1
+func steps(args []string, i int) (int, error) {
+	if len(args) <= i {
+		return 1, nil
+	}
+	n, err := strconv.Atoi(args[i])
+	if err != nil {
+		return 0, fmt.Errorf("invalid steps %q", args[i])
+	}
+	return n, nil
+}
+
+func NewIncrementCmd() *cobra.Command {
+	var preid string
+	incrementCmd := &cobra.Command{
+		Use:   "increment <version> <release-type> [n]",
+		Short: "Increment the version n steps, e.g. 1.2.3 minor 3 is 1.5.0",
+		Args:  cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v, err := semver.Version(args[0])
+			if err != nil {
+				return err
+			}
+			changed, err := semver.ParseReleaseType(args[1])
+			if err != nil {
+				return err
+			}
+			n, err := steps(args, 2)
+			if err != nil {
+				return err
+			}
+			if err = semver.PreReleaseIdentifier(preid).Validate(); err != nil {
+				return err
+			}
+			next, err := semver.IncrementBy(v, changed, n, semver.WithIdentifier(semver.PreReleaseIdentifier(preid)))
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), next)
+			return err
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return version.CompleteReleaseType(cmd, args, toComplete)
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	incrementCmd.Flags().StringVar(&preid, "preid", "", "identifier of premajor, preminor, prepatch or prerelease increments")
+	return incrementCmd
+}
+
+func NewPreviousCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "previous <version> <major|minor|patch> [n]",
+		Short: "Print the version n major, minor or patch steps before, e.g. 1.5.2 minor 3 is 1.2.0",
+		Long: `Print the version n major, minor or patch steps before the version, useful for deprecation windows
+such as supporting the last 3 minors. Prerelease and build metadata are ignored.`,
+		Args: cobra.RangeArgs(2, 3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v, err := semver.Version(args[0])
+			if err != nil {
+				return err
+			}
+			changed, err := semver.ParseReleaseType(args[1])
+			if err != nil {
+				return err
+			}
+			n, err := steps(args, 2)
+			if err != nil {
+				return err
+			}
+			for i := 0; i < n && err == nil; i++ {
+				v, err = semver.PreviousVersion(v, changed)
+			}
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), v)
+			return err
+		},
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) == 1 {
+				return []string{"major", "minor", "patch"}, cobra.ShellCompDirectiveNoFileComp
+			}
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+}
+
+func NewDistanceCmd() *cobra.Command {
+	asJSON := false
+	distanceCmd := &cobra.Command{
+		Use:   "distance <from> <to>",
+		Short: "Print how many major, minor and patch steps the versions are apart",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			a, err := semver.Version(args[0])
+			if err != nil {
+				return err
+			}
+			b, err := semver.Version(args[1])
+			if err != nil {
+				return err
+			}
+			d := semver.Distance(a, b)
+			if asJSON {
+				data, err := json.Marshal(d)
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			}
+			kind := d.Type
+			if kind == "" {
+				kind = "none"
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s\tmajor=%d minor=%d patch=%d\n", kind, d.Major, d.Minor, d.Patch)
+			return err
+		},
+	}
+	distanceCmd.Flags().BoolVar(&asJSON, "json", false, "print the distance as json")
+	return distanceCmd
+}
+
+func NewWindowsCmd() *cobra.Command {
+	var (
+		build  uint64
+		msi    bool
+		asJSON bool
+	)
+	windowsCmd := &cobra.Command{
+		Use:   "windows <version>",
+		Short: "Print the windows four-part file version and MSI ProductVersion of a version",
+		Long: `Map a semantic version to the four-part file version used by windows version resources
+and the three-part MSI ProductVersion. Each part is limited to 65535, and with --msi the
+major and minor versions are limited to 255. Prerelease versions cannot be expressed,
+use --build to tell them apart.`,
+		Example: `  autoctl semver windows 1.2.3-rc.1 --build $GITHUB_RUN_NUMBER --msi`,
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v, err := semver.Version(args[0])
+			if err != nil {
+				return err
+			}
+			w, err := stamp.NewWindowsVersion(v, build, msi)
+			if err != nil {
+				return err
+			}
+			if asJSON {
+				data, err := json.Marshal(map[string]string{"fileVersion": w.FileVersion(), "productVersion": w.ProductVersion()})
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			}
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "%s\t%s\n", w.FileVersion(), w.ProductVersion())
+			return err
+		},
+	}
+	windowsCmd.Flags().Uint64Var(&build, "build", 0, "fourth part of the file version, such as the ci build number")
+	windowsCmd.Flags().BoolVar(&msi, "msi", false, "check the MSI ProductVersion limits")
+	windowsCmd.Flags().BoolVar(&asJSON, "json", false, "print the versions as json")
+	return windowsCmd
+}
+
+func NewSelfTestCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "selftest",
+		Short: "Check parsing, comparison and increment of the semver engine against the semver.org corpus",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			checks, failures := semver.SelfTest()
+			for _, err := range failures {
+				_, _ = fmt.Fprintln(cmd.ErrOrStderr(), err)
+			}
+			if len(failures) > 0 {
+				return fmt.Errorf("%d of %d checks failed", len(failures), checks)
+			}
+			_, err := fmt.Fprint(cmd.OutOrStdout(), i18n.T("ok, %d checks passed\n", checks))
+			return err
+		},
+	}
+}
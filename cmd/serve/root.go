@@ -0,0 +1,105 @@
+package serve
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/chatops"
+	"github.com/coffee377/autoctl/internal/server"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func NewServeCmd() *cobra.Command {
+	var addr string
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve the version, changelog and release apis over http",
+		Long: `Serve a small REST api so that other tools and chatops bots can use autoctl without shelling out:
+
+  GET  /healthz                                 health check
+  GET  /metrics                                 release metrics in the Prometheus text format
+  GET  /api/v1/version                          current version
+  GET  /api/v1/next?release=minor&preid=beta    next version
+  GET  /api/v1/validate?version=1.2.3&constraint=^1.0.0
+  GET  /api/v1/changelog?format=markdown        next changelog, requires Authorization: Bearer <token>
+  POST /api/v1/release                          {"release":"minor","dryRun":false}, requires Authorization: Bearer <token>
+  POST /api/v1/chatops                          GitHub issue_comment or GitLab comment webhook, when chatops.enabled
+
+The release and changelog apis are disabled unless serve.token or ` + server.EnvToken + ` is set.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg := server.Config{}
+			if err := viper.UnmarshalKey("serve", &cfg); err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("addr") || cfg.Addr == "" {
+				cfg.Addr = addr
+			}
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			if err = releaseOpts.Bots.Validate(); err != nil {
+				return err
+			}
+			s := server.New(releaseOpts, cfg)
+			chatopsCfg := chatops.Config{}
+			if err = viper.UnmarshalKey("chatops", &chatopsCfg); err != nil {
+				return err
+			}
+			if chatopsCfg.Enabled {
+				if err = s.WithChatOps(chatopsCfg); err != nil {
+					return err
+				}
+			}
+			srv := &http.Server{
+				Addr:              cfg.Addr,
+				Handler:           s.Handler(),
+				ReadHeaderTimeout: 10 * time.Second,
+			}
+			return listen(cmd.Context(), srv)
+		},
+	}
+	serveCmd.Flags().StringVar(&addr, "addr", server.DefaultAddr, "address to listen on (overrides serve.addr)")
+	return serveCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewServeCmd())
+}
+
+// listen 启动服务，收到中断信号时等待处理中的请求完成后退出
+func listen(ctx context.Context, srv *http.Server) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("listening on %s", srv.Addr)
+		errCh <- srv.ListenAndServe()
+	}()
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+	shutdown, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdown); err != nil {
+		return err
+	}
+	if err := <-errCh; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
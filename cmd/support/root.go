@@ -0,0 +1,130 @@
+package support
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/cmd/version"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/internal/support"
+	"github.com/coffee377/autoctl/internal/tags"
+	"github.com/coffee377/autoctl/pkg/semver"
+	"github.com/spf13/cobra"
+)
+
+// Status 版本线的支持状态
+type Status struct {
+	support.Line
+	Status string `json:"status"` // supported | eol | dropped，dropped 表示发布下一个版本后停止支持
+}
+
+func NewSupportCmd() *cobra.Command {
+	var (
+		changed version.ReleaseTypeValue
+		policy  support.Policy
+		asJSON  bool
+	)
+	supportCmd := &cobra.Command{
+		Use:   "support [next-version]",
+		Short: "List released versions in and out of the support policy",
+		Long: `List the release lines of stable versions with their support status according to the support policy,
+e.g. support.majors: 2 and support.minors: 3 supports the last 3 minors of the latest 2 majors.
+
+With a next version or --release-type, the lines that fall out of support after releasing it are marked
+as dropped, and are noted in the changelog and release event when releasing.`,
+		Example: `  autoctl support
+  autoctl support -r minor
+  autoctl support 2.0.0 --majors 2 --minors 3`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			if cmd.Flags().Changed("majors") {
+				releaseOpts.Support.Majors = policy.Majors
+			}
+			if cmd.Flags().Changed("minors") {
+				releaseOpts.Support.Minors = policy.Minors
+			}
+			if !releaseOpts.Support.Enabled() {
+				return errors.New("support.majors or support.minors is required")
+			}
+			releaseOpts.Release = changed.Changed
+			releaser := release.New(releaseOpts)
+			list, _, err := tags.List(releaser.Git(), releaser.Options().TagPrefix)
+			if err != nil {
+				return err
+			}
+			versions := make([]semver.Semver, 0, len(list))
+			for _, t := range list {
+				versions = append(versions, t.Version)
+			}
+			var next semver.Semver
+			switch {
+			case len(args) == 1:
+				if next, err = semver.Version(strings.TrimPrefix(args[0], releaser.Options().TagPrefix)); err != nil {
+					return err
+				}
+			case changed.Changed != 0:
+				current, err := releaser.Current()
+				if err != nil {
+					return err
+				}
+				if next, err = releaser.Next(current); err != nil {
+					return err
+				}
+			}
+			statuses := statusOf(releaseOpts.Support, versions, next)
+			if asJSON {
+				data, err := json.MarshalIndent(statuses, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			_, _ = fmt.Fprintln(w, "LINE\tLATEST\tSTATUS")
+			for _, s := range statuses {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", s.Line, s.Latest, s.Status)
+			}
+			return w.Flush()
+		},
+	}
+	supportCmd.Flags().VarP(&changed, "release-type", "r", fmt.Sprintf("release type of the next version, one of %s", strings.Join(semver.ReleaseTypeNames(), "|")))
+	supportCmd.Flags().IntVar(&policy.Majors, "majors", 0, "number of supported majors (overrides support.majors)")
+	supportCmd.Flags().IntVar(&policy.Minors, "minors", 0, "number of supported minors of each major (overrides support.minors)")
+	supportCmd.Flags().BoolVar(&asJSON, "json", false, "print the support status as json")
+	_ = supportCmd.RegisterFlagCompletionFunc("release-type", version.CompleteReleaseType)
+	return supportCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewSupportCmd())
+}
+
+// statusOf 版本线的支持状态，next 不为空时标记发布后停止支持的版本线
+func statusOf(p support.Policy, versions []semver.Semver, next semver.Semver) []Status {
+	dropped := map[support.Line]bool{}
+	for _, l := range p.Dropped(versions, next) {
+		dropped[l] = true
+	}
+	supported, eol := p.Split(support.Lines(versions))
+	statuses := make([]Status, 0, len(supported)+len(eol))
+	for _, l := range supported {
+		s := Status{Line: l, Status: "supported"}
+		if dropped[l] {
+			s.Status = "dropped"
+		}
+		statuses = append(statuses, s)
+	}
+	for _, l := range eol {
+		statuses = append(statuses, Status{Line: l, Status: "eol"})
+	}
+	return statuses
+}
@@ -0,0 +1,130 @@
+package sync
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/i18n"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/internal/stamp"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// syncResult 打包文件的同步结果
+type syncResult struct {
+	Type    string `json:"type"`
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Changed bool   `json:"changed"`
+}
+
+func NewSyncCmd() *cobra.Command {
+	var asJSON bool
+	syncCmd := &cobra.Command{
+		Use:   "sync [version]",
+		Short: "Write the version into OS packaging, windows version resource and mobile manifest files",
+		Long: `Write the version (default is the current version) into the packaging files listed in the
+sync config, so that OS packages stay aligned with the upstream version:
+
+  debian       add a dch compatible entry to debian/changelog, such as 1.2.0~rc.1-1
+  rpm          update the Version and Release fields of the spec file
+  versioninfo  update the versions in the goversioninfo versioninfo.json
+  rc           update the VERSIONINFO resource of a windows resource script
+  android      update versionCode and versionName in build.gradle(.kts) or gradle.properties
+  ios          update the versions in Info.plist or the xcode project.pbxproj
+
+Android versionCode and iOS CFBundleVersion are computed by the versionCode formula,
+default "` + stamp.DefaultVersionCode + `", where pre is 99 for final releases.
+With release.buildNumber.enabled, build may refer to the last build number as {{ .BuildNumber }}.
+
+Prerelease versions use ~ so that they sort before the final release in dpkg and rpm.`,
+		Example: `  autoctl sync
+  autoctl sync 1.2.0-rc.1`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			targets := make([]stamp.Target, 0)
+			if err := viper.UnmarshalKey("sync", &targets); err != nil {
+				return err
+			}
+			if len(targets) == 0 {
+				return errors.New("no sync targets configured")
+			}
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			releaser := release.New(releaseOpts)
+			var version semver.Semver
+			if len(args) == 1 {
+				version, err = semver.Version(strings.TrimPrefix(args[0], releaser.Options().TagPrefix))
+			} else {
+				version, err = releaser.Current()
+			}
+			if err != nil {
+				return err
+			}
+			if version == nil {
+				return errors.New("no version tag found, specify the version")
+			}
+			cl, err := releaser.VersionChangelog(version)
+			if err != nil {
+				return err
+			}
+			data := stamp.Data{Version: version, Changelog: cl, Date: time.Now(), Maintainer: maintainer(releaser)}
+			if releaseOpts.BuildNumber.Enabled {
+				counter, err := releaser.BuildCounter()
+				if err != nil {
+					return err
+				}
+				if data.BuildNumber, err = counter.Current(); err != nil {
+					return err
+				}
+			}
+			results := make([]syncResult, 0, len(targets))
+			for _, t := range targets {
+				path, changed, err := stamp.Apply(releaseOpts.Cwd, t, data)
+				if err != nil {
+					return err
+				}
+				rel, _ := filepath.Rel(releaseOpts.Cwd, path)
+				results = append(results, syncResult{Type: t.Type, Path: filepath.ToSlash(rel), Version: version.String(), Changed: changed})
+				if changed && !asJSON {
+					log.Info(i18n.T("updated %s to %s"), rel, version)
+				}
+			}
+			if !asJSON {
+				return nil
+			}
+			out, err := json.MarshalIndent(results, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), string(out))
+			return err
+		},
+	}
+	syncCmd.Flags().BoolVar(&asJSON, "json", false, "print the results as json")
+	return syncCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewSyncCmd())
+}
+
+// maintainer git 用户作为默认维护者
+func maintainer(r *release.Releaser) string {
+	name, _ := r.Git().Run("config", "user.name")
+	email, _ := r.Git().Run("config", "user.email")
+	if len(email) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s <%s>", strings.TrimSpace(string(name)), strings.TrimSpace(string(email)))
+}
@@ -0,0 +1,266 @@
+package tag
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/i18n"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/internal/tags"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+const dateLayout = "2006-01-02"
+
+type filterOptions struct {
+	channels   []string // 发布通道
+	prerelease bool     // 仅预发布版本
+	stable     bool     // 仅正式版本
+	olderThan  string   // 创建时间早于
+}
+
+func (o *filterOptions) register(cmd *cobra.Command) {
+	cmd.Flags().StringSliceVar(&o.channels, "channel", nil, "only tags of the channels, e.g. rc, beta, stable")
+	cmd.Flags().BoolVar(&o.prerelease, "prerelease", false, "only prerelease tags")
+	cmd.Flags().BoolVar(&o.stable, "stable", false, "only stable tags")
+	cmd.Flags().StringVar(&o.olderThan, "older-than", "", "only tags created before the age, e.g. 90d, 2w, 36h")
+}
+
+func (o *filterOptions) filter() (tags.Filter, error) {
+	if o.prerelease && o.stable {
+		return tags.Filter{}, errors.New("--prerelease and --stable are mutually exclusive")
+	}
+	f := tags.Filter{Channels: o.channels, Prerelease: o.prerelease, Stable: o.stable}
+	if o.olderThan != "" {
+		age, err := tags.ParseAge(o.olderThan)
+		if err != nil {
+			return f, err
+		}
+		f.OlderThan = age
+	}
+	return f, nil
+}
+
+func NewTagCmd() *cobra.Command {
+	tagCmd := &cobra.Command{
+		Use:   "tag",
+		Short: "List, prune and verify version tags",
+	}
+	tagCmd.AddCommand(NewListCmd(), NewPruneCmd(), NewVerifyCmd())
+	return tagCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewTagCmd())
+}
+
+// load 读取发布配置并列出版本标签
+func load(cmd *cobra.Command) (*release.Releaser, []tags.Tag, []string, error) {
+	opts, err := releasecmd.LoadConfig(cmd)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	releaser := release.New(opts)
+	list, invalid, err := tags.List(releaser.Git(), releaser.Options().TagPrefix)
+	return releaser, list, invalid, err
+}
+
+func NewListCmd() (listCmd *cobra.Command) {
+	opts := &filterOptions{}
+	asJSON := false
+	listCmd = &cobra.Command{
+		Use:   "list",
+		Short: "List version tags from newest to oldest",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			f, err := opts.filter()
+			if err != nil {
+				return err
+			}
+			_, list, _, err := load(cmd)
+			if err != nil {
+				return err
+			}
+			list = f.Select(list, time.Now())
+			if asJSON {
+				return printJSON(cmd.OutOrStdout(), list)
+			}
+			return printTags(cmd.OutOrStdout(), list)
+		},
+	}
+	opts.register(listCmd)
+	listCmd.Flags().BoolVar(&asJSON, "json", false, "print the tags as json")
+	return listCmd
+}
+
+func NewPruneCmd() (pruneCmd *cobra.Command) {
+	opts := &filterOptions{}
+	var (
+		keep   int
+		remote string
+		dryRun bool
+	)
+	pruneCmd = &cobra.Command{
+		Use:   "prune",
+		Short: "Delete prerelease tags accumulated by release automation",
+		Example: `  autoctl tag prune --prerelease --older-than 90d
+  autoctl tag prune --channel rc --keep 3 --remote origin`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !opts.prerelease && len(opts.channels) == 0 {
+				return errors.New("--prerelease or --channel is required")
+			}
+			f, err := opts.filter()
+			if err != nil {
+				return err
+			}
+			if opts.stable || contains(opts.channels, tags.Stable) {
+				return errors.New("stable tags can not be pruned")
+			}
+			f.Prerelease = true
+			releaser, list, _, err := load(cmd)
+			if err != nil {
+				return err
+			}
+			selected := f.Select(list, time.Now())
+			if keep > 0 && len(selected) > 0 {
+				// 列表按版本号从新到旧排列，保留最新的 keep 个
+				if keep >= len(selected) {
+					selected = nil
+				} else {
+					selected = selected[keep:]
+				}
+			}
+			if dryRun {
+				log.Info(i18n.T("dry run: %d tags would be deleted"), len(selected))
+				return printTags(cmd.OutOrStdout(), selected)
+			}
+			return prune(releaser.Git(), selected, remote)
+		},
+	}
+	opts.register(pruneCmd)
+	pruneCmd.Flags().IntVar(&keep, "keep", 0, "keep the newest n matched tags")
+	pruneCmd.Flags().StringVar(&remote, "remote", "", "also delete the tags from the remote")
+	pruneCmd.Flags().BoolVar(&dryRun, "dry-run", false, "print the tags to delete without deleting")
+	return pruneCmd
+}
+
+// prune 删除标签，指定远程仓库时先删除远程标签
+func prune(plus *git.Plus, list []tags.Tag, remote string) error {
+	for _, t := range list {
+		if remote != "" {
+			if err := plus.DeleteRemoteTag(remote, t.Name); err != nil {
+				return err
+			}
+		}
+		if err := plus.DeleteTag(t.Name); err != nil {
+			return err
+		}
+		log.Info(i18n.T("deleted tag %s"), t.Name)
+	}
+	return nil
+}
+
+func NewVerifyCmd() (verifyCmd *cobra.Command) {
+	rules := tags.Rules{}
+	asJSON := false
+	verifyCmd = &cobra.Command{
+		Use:   "verify [tag...]",
+		Short: "Check tags are valid versions with the configured prefix and have valid signatures",
+		Long: `Check the tags (default all tags) are semantic versions with the configured tag prefix.
+Signed tags are always verified with git verify-tag.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			releaser := release.New(opts)
+			refs, err := releaser.Git().TagRefs(args...)
+			if err != nil {
+				return err
+			}
+			if len(args) > len(refs) {
+				return fmt.Errorf("%d of %d tags not found", len(args)-len(refs), len(args))
+			}
+			results := make([]tags.Result, 0, len(refs))
+			failed := 0
+			for _, ref := range refs {
+				res := tags.Verify(releaser.Git(), ref, releaser.Options().TagPrefix, rules)
+				if len(res.Problems) > 0 {
+					failed++
+				}
+				results = append(results, res)
+			}
+			if asJSON {
+				err = printJSON(cmd.OutOrStdout(), results)
+			} else {
+				err = printResults(cmd.OutOrStdout(), results)
+			}
+			if err != nil {
+				return err
+			}
+			if failed > 0 {
+				cmd.SilenceUsage = true
+				return fmt.Errorf("%d of %d tags failed verification", failed, len(results))
+			}
+			return nil
+		},
+	}
+	verifyCmd.Flags().BoolVar(&rules.Annotated, "annotated", false, "require annotated tags")
+	verifyCmd.Flags().BoolVar(&rules.Signed, "signed", false, "require signed tags")
+	verifyCmd.Flags().BoolVar(&asJSON, "json", false, "print the results as json")
+	return verifyCmd
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func printJSON(out io.Writer, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(out, string(data))
+	return err
+}
+
+func printTags(out io.Writer, list []tags.Tag) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, t := range list {
+		kind := "lightweight"
+		if t.Signed {
+			kind = "signed"
+		} else if t.Annotated {
+			kind = "annotated"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%.7s\t%s\n", t.Name, t.Channel, t.Date.Format(dateLayout), t.Commit, kind)
+	}
+	return w.Flush()
+}
+
+func printResults(out io.Writer, results []tags.Result) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	for _, r := range results {
+		if len(r.Problems) == 0 {
+			_, _ = fmt.Fprintf(w, "PASS\t%s\t\n", r.Name)
+			continue
+		}
+		for _, p := range r.Problems {
+			_, _ = fmt.Fprintf(w, "FAIL\t%s\t%s\n", r.Name, p)
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/dashboard"
+	"github.com/coffee377/autoctl/internal/i18n"
+	"github.com/coffee377/autoctl/internal/prompt"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/pkg/semver"
+	"github.com/spf13/cobra"
+)
+
+// clearScreen 光标移动到左上角并清屏
+const clearScreen = "\033[H\033[2J"
+
+type uiOptions struct {
+	once    bool // 只输出一次状态
+	json    bool // 以 JSON 格式输出状态
+	noClear bool // 刷新时不清屏
+}
+
+func NewUICmd() *cobra.Command {
+	opts := &uiOptions{}
+	uiCmd := &cobra.Command{
+		Use:   "ui",
+		Short: "Terminal dashboard of the release status of workspace packages",
+		Long: `Show the current version, last release date, unreleased commits and uncommitted files of every
+workspace package, and bump or release the selected package from the dashboard.
+
+Commands, followed by Enter:
+  <n>          select the package in row n
+  b <type>     set the release type of the selected package, e.g. b minor
+  r            release the selected package
+  g            reload the status
+  q            quit`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			d := &board{opts: releaseOpts, out: cmd.OutOrStdout(), clear: !opts.noClear}
+			if err = d.reload(); err != nil {
+				return err
+			}
+			if opts.json {
+				data, err := json.MarshalIndent(d.list, "", "  ")
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(cmd.OutOrStdout(), string(data))
+				return err
+			}
+			if opts.once {
+				return dashboard.Render(cmd.OutOrStdout(), d.list, -1)
+			}
+			return d.run(prompt.New(cmd.InOrStdin(), cmd.OutOrStdout()))
+		},
+	}
+	uiCmd.Flags().BoolVar(&opts.once, "once", false, "print the status table once and exit")
+	uiCmd.Flags().BoolVar(&opts.json, "json", false, "print the status as json and exit")
+	uiCmd.Flags().BoolVar(&opts.noClear, "no-clear", false, "do not clear the screen before redrawing")
+	return uiCmd
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewUICmd())
+}
+
+// board 交互式面板状态
+type board struct {
+	opts     release.Options
+	out      io.Writer
+	clear    bool
+	list     []*dashboard.Status
+	selected int
+	message  string // 上一次操作的结果
+}
+
+func (d *board) reload() error {
+	r := release.New(d.opts)
+	list, err := dashboard.Load(r.Git(), d.opts.Cwd, r.Options().TagPrefix, r.Options().Paths)
+	if err != nil {
+		return err
+	}
+	// 保留已设置的版本变动类型
+	types := map[string]string{}
+	for _, s := range d.list {
+		types[s.Package.Path] = s.Release
+	}
+	for _, s := range list {
+		if t, ok := types[s.Package.Path]; ok {
+			_ = s.SetRelease(t)
+		}
+	}
+	d.list = list
+	if d.selected >= len(list) {
+		d.selected = 0
+	}
+	return nil
+}
+
+func (d *board) draw() {
+	if d.clear {
+		_, _ = fmt.Fprint(d.out, clearScreen)
+	}
+	_ = dashboard.Render(d.out, d.list, d.selected)
+	_, _ = fmt.Fprintln(d.out)
+	if d.message != "" {
+		_, _ = fmt.Fprintln(d.out, d.message)
+	}
+	_, _ = fmt.Fprintln(d.out, i18n.T("[n] select  [b <type>] release type  [r] release  [g] reload  [q] quit"))
+}
+
+// run 读取命令并刷新面板，输入结束或 q 时退出
+func (d *board) run(p *prompt.Prompter) error {
+	for {
+		d.draw()
+		line, err := p.Input(i18n.T("command"), "")
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		d.message = ""
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "q", "quit":
+			return nil
+		case "g", "reload":
+			err = d.reload()
+		case "b", "bump":
+			if len(fields) < 2 {
+				d.message = i18n.T("release type required, one of %s", strings.Join(semver.ReleaseTypeNames(), "|"))
+				continue
+			}
+			err = d.list[d.selected].SetRelease(fields[1])
+		case "r", "release":
+			err = d.release(p)
+		default:
+			n, convErr := strconv.Atoi(fields[0])
+			if convErr != nil || n < 1 || n > len(d.list) {
+				d.message = i18n.T("unknown command %q", line)
+				continue
+			}
+			d.selected = n - 1
+		}
+		if err != nil {
+			d.message = err.Error()
+		}
+	}
+}
+
+// release 确认后以选中软件包的标签前缀发布，完成后刷新状态
+func (d *board) release(p *prompt.Prompter) error {
+	s := d.list[d.selected]
+	ok, err := p.Confirm(i18n.T("Release %s %s?", s.Package.Name, s.TagPrefix+s.Next), false)
+	if err != nil || !ok {
+		return err
+	}
+	opts := d.opts
+	opts.TagPrefix = s.TagPrefix
+	if opts.Release, err = semver.ParseReleaseType(s.Release); err != nil {
+		return err
+	}
+	res, err := release.New(opts).Release()
+	if err != nil {
+		return err
+	}
+	if res.Skipped {
+		d.message = strings.TrimSpace(i18n.T("no release, current version is %s\n", res.Version))
+	} else {
+		d.message = i18n.T("released %s", res.Tag)
+	}
+	return d.reload()
+}
@@ -0,0 +1,45 @@
+package version
+
+import (
+	"github.com/coffee377/autoctl/pkg/semver"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// ReleaseTypeValue 实现 pflag.Value，用于在命令行中指定版本变动类型
+type ReleaseTypeValue struct {
+	Changed semver.VersionChanged
+}
+
+func (r *ReleaseTypeValue) String() string {
+	if r.Changed == 0 {
+		return ""
+	}
+	return r.Changed.String()
+}
+
+func (r *ReleaseTypeValue) Set(s string) error {
+	changed, err := semver.ParseReleaseType(s)
+	if err != nil {
+		return err
+	}
+	r.Changed = changed
+	return nil
+}
+
+func (r *ReleaseTypeValue) Type() string {
+	return "type"
+}
+
+// CompleteReleaseType 版本变动类型的自动补全
+func CompleteReleaseType(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return semver.ReleaseTypeNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// PreIdMode 解析预发布版本计数方式，命令行参数优先，其次为配置文件中的 version.preidMode
+func PreIdMode(cmd *cobra.Command, flagValue string) (semver.PreIdMode, error) {
+	if cmd.Flags().Changed("preid-mode") {
+		return semver.ParsePreIdMode(flagValue)
+	}
+	return semver.ParsePreIdMode(viper.GetString("version.preidMode"))
+}
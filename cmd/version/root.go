@@ -2,18 +2,63 @@ package version
 
 import (
 	"fmt"
+	"strings"
 
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/internal/report"
+	"github.com/coffee377/autoctl/pkg/semver"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 )
 
+type versionOptions struct {
+	release ReleaseTypeValue // 版本变动类型
+	preid   string           // 预发布版本标识符
+	preMode string           // 预发布版本切换标识符时的计数方式
+	current string           // 当前版本，默认取最近一次的标签
+}
+
 func NewVersionCmd() (versionCmd *cobra.Command) {
+	opts := &versionOptions{release: ReleaseTypeValue{Changed: semver.Patch}}
 	versionCmd = &cobra.Command{
-		Use:   "version",
-		Short: "Increments the version number according to the semantic version",
-		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Println("version called")
+		Use:       "version [release-type]",
+		Short:     "Increments the version number according to the semantic version",
+		Args:      cobra.MaximumNArgs(1),
+		ValidArgs: semver.ReleaseTypeNames(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				if err := opts.release.Set(args[0]); err != nil {
+					return err
+				}
+			}
+			current, err := currentVersion(cmd, opts.current)
+			if err != nil {
+				return err
+			}
+			mode, err := PreIdMode(cmd, opts.preMode)
+			if err != nil {
+				return err
+			}
+			next, err := current.IncrementE(
+				semver.WithReleaseType(opts.release.Changed),
+				semver.WithIdentifier(semver.PreReleaseIdentifier(opts.preid)),
+				semver.WithPreIdMode(mode),
+			)
+			if err != nil {
+				return err
+			}
+			report.SetVersion("current", current.String())
+			report.SetVersion("next", next.String())
+			_, err = fmt.Fprintln(cmd.OutOrStdout(), next.String())
+			return err
 		},
 	}
+
+	versionCmd.Flags().VarP(&opts.release, "release-type", "r", fmt.Sprintf("release type, one of %s", strings.Join(semver.ReleaseTypeNames(), "|")))
+	versionCmd.Flags().StringVar(&opts.preid, "preid", "", "identifier to be used to prefix premajor, preminor, prepatch or prerelease version increments")
+	versionCmd.Flags().StringVar(&opts.preMode, "preid-mode", "", fmt.Sprintf("counter behavior when switching prerelease identifier, one of %s (default replace)", strings.Join(semver.PreIdModeNames(), "|")))
+	versionCmd.Flags().StringVar(&opts.current, "current", "", "current version (default is the latest tag)")
+	_ = versionCmd.RegisterFlagCompletionFunc("release-type", CompleteReleaseType)
 	return versionCmd
 }
 
@@ -21,3 +66,20 @@ func RegisterCommandRecursive(parent *cobra.Command) {
 	versionCmd := NewVersionCmd()
 	parent.AddCommand(versionCmd)
 }
+
+// currentVersion 解析当前版本号，未指定时使用最近一次的标签，没有标签时为 0.0.0
+func currentVersion(cmd *cobra.Command, current string) (semver.Semver, error) {
+	if current != "" {
+		return semver.Version(strings.TrimPrefix(strings.TrimSpace(current), "v"))
+	}
+	opts := release.Options{}
+	if err := viper.UnmarshalKey("release", &opts); err != nil {
+		return nil, err
+	}
+	opts.Cwd, _ = cmd.Flags().GetString("directory")
+	v, err := release.New(opts).Current()
+	if err != nil || v != nil {
+		return v, err
+	}
+	return semver.Version("0.0.0")
+}
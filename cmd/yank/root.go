@@ -0,0 +1,229 @@
+package yank
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	releasecmd "github.com/coffee377/autoctl/cmd/release"
+	"github.com/coffee377/autoctl/internal/audit"
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/fileutil"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/internal/yank"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+type yankOptions struct {
+	reason      string // 撤回原因
+	file        string // 变更日志文件
+	dryRun      bool   // 只输出将要执行的操作
+	noChangelog bool
+	noRetract   bool
+	noNPM       bool
+	noProvider  bool
+}
+
+func NewYankCmd() *cobra.Command {
+	opts := &yankOptions{}
+	yankCmd := &cobra.Command{
+		Use:   "yank <version>",
+		Short: "Mark a released version as broken across changelog, go.mod, npm and the provider",
+		Long: `Retract a broken release everywhere it is consumed, the tag itself is kept:
+
+  changelog  the version heading in CHANGELOG.md is marked ` + yank.Marker + ` followed by the reason
+  go.mod     a retract directive with the reason as its comment, released with the next version
+  npm        npm deprecate <package>@<version> for public packages in package.json
+  provider   the release title is marked ` + yank.Marker + ` and the notes start with the reason
+
+Steps whose file or provider is missing are skipped, rerunning the command skips steps already done.
+Commit the changed files and release a new version so go and npm users pick up the retraction.`,
+		Example: `  autoctl yank 1.4.0 --reason "corrupts the cache on upgrade"
+  autoctl yank v1.4.0 --no-npm --dry-run`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			releaseOpts, err := releasecmd.LoadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			releaser := release.New(releaseOpts)
+			prefix := releaser.Options().TagPrefix
+			version := strings.TrimPrefix(args[0], prefix)
+			tag := prefix + version
+			if !releaser.Git().TagExists(tag) {
+				return fmt.Errorf("tag %s does not exist", tag)
+			}
+			cwd := releaseOpts.Cwd
+			var steps []step
+			if !opts.noChangelog {
+				steps = append(steps, step{"changelog", func() error {
+					return yankChangelog(filepath.Join(cwd, opts.file), version, opts.reason, opts.dryRun)
+				}})
+			}
+			if !opts.noRetract {
+				steps = append(steps, step{"go.mod", func() error {
+					return retract(filepath.Join(cwd, "go.mod"), version, opts.reason, opts.dryRun)
+				}})
+			}
+			if !opts.noNPM {
+				steps = append(steps, step{"npm", func() error {
+					return deprecate(cwd, version, opts.reason, opts.dryRun)
+				}})
+			}
+			if !opts.noProvider {
+				steps = append(steps, step{"provider", func() error {
+					return markRelease(releaseOpts.Provider, tag, version, opts.reason, opts.dryRun)
+				}})
+			}
+			// 某一步失败时继续执行其余步骤，尽量在所有生态中撤回
+			var failed []string
+			for _, s := range steps {
+				if err := s.run(); err != nil {
+					log.Warn("yank %s: %s", s.name, err)
+					failed = append(failed, s.name)
+				}
+			}
+			if len(failed) > 0 {
+				err = fmt.Errorf("failed to yank %s in %s", tag, strings.Join(failed, ", "))
+			}
+			releasecmd.Audit(cmd, releaser, &audit.Entry{Version: version, Tag: tag, DryRun: opts.dryRun}, nil, err)
+			return err
+		},
+	}
+	yankCmd.Flags().StringVar(&opts.reason, "reason", "", "why the version is yanked, shown to users of the version")
+	yankCmd.Flags().StringVar(&opts.file, "file", changelog.FileName, "changelog file to mark the version in")
+	yankCmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "print the steps without changing anything")
+	yankCmd.Flags().BoolVar(&opts.noChangelog, "no-changelog", false, "do not mark the version in the changelog")
+	yankCmd.Flags().BoolVar(&opts.noRetract, "no-retract", false, "do not add a retract directive to go.mod")
+	yankCmd.Flags().BoolVar(&opts.noNPM, "no-npm", false, "do not deprecate the npm version")
+	yankCmd.Flags().BoolVar(&opts.noProvider, "no-provider", false, "do not mark the provider release")
+	return yankCmd
+}
+
+// step 撤回版本的一个步骤
+type step struct {
+	name string
+	run  func() error
+}
+
+func RegisterCommandRecursive(parent *cobra.Command) {
+	parent.AddCommand(NewYankCmd())
+}
+
+// yankChangelog 在变更日志中标记撤回的版本
+func yankChangelog(file, version, reason string, dryRun bool) error {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		log.Info("skip changelog: %s not found", file)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	updated, changed := yank.Changelog(string(data), version, reason)
+	if !changed {
+		log.Info("skip changelog: %s is not listed or already yanked in %s", version, file)
+		return nil
+	}
+	if dryRun {
+		log.Info("dry run: skip marking %s yanked in %s", version, file)
+		return nil
+	}
+	if err = fileutil.WriteFile(file, []byte(updated), 0o644); err != nil {
+		return err
+	}
+	log.Info("marked %s yanked in %s", version, file)
+	return nil
+}
+
+// retract 在 go.mod 中撤回版本
+func retract(file, version, reason string, dryRun bool) error {
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		log.Info("skip retract: %s not found", file)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	updated, changed := yank.Retract(data, version, reason)
+	if !changed {
+		log.Info("skip retract: %s is already retracted in %s", version, file)
+		return nil
+	}
+	if dryRun {
+		log.Info("dry run: skip retracting %s in %s", version, file)
+		return nil
+	}
+	if err = fileutil.WriteFile(file, updated, 0o644); err != nil {
+		return err
+	}
+	log.Info("retracted %s in %s", version, file)
+	return nil
+}
+
+// deprecate 弃用 npm 包的版本
+func deprecate(dir, version, reason string, dryRun bool) error {
+	name, err := yank.PackageName(dir)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		log.Info("skip npm: no public package.json")
+		return nil
+	}
+	message := yank.Notice(version, reason)
+	message = strings.ReplaceAll(message, "**", "")
+	if dryRun {
+		log.Info("dry run: skip deprecating %s@%s", name, version)
+		return nil
+	}
+	if err = yank.Deprecate(dir, name, version, message); err != nil {
+		return err
+	}
+	log.Info("deprecated %s@%s", name, version)
+	return nil
+}
+
+// markRelease 在代码托管平台的版本发布标题中标记撤回，说明开头追加撤回原因，未配置平台时跳过
+func markRelease(cfg provider.Config, tag, version, reason string, dryRun bool) error {
+	if cfg.Repo == "" {
+		log.Info("skip provider: provider.repo is not configured")
+		return nil
+	}
+	p, err := provider.New(cfg)
+	if err != nil {
+		return err
+	}
+	m, ok := p.(provider.ReleaseManager)
+	if !ok {
+		return fmt.Errorf("provider %s does not support updating releases", p.Name())
+	}
+	r, err := m.GetRelease(tag)
+	if err != nil {
+		return fmt.Errorf("get %s release %s: %w", p.Name(), tag, err)
+	}
+	name := r.Name
+	if name == "" {
+		name = tag
+	}
+	if strings.Contains(name, yank.Marker) {
+		log.Info("skip provider: %s release %s is already yanked", p.Name(), tag)
+		return nil
+	}
+	if dryRun {
+		log.Info("dry run: skip marking %s release %s yanked", p.Name(), tag)
+		return nil
+	}
+	if err = m.UpdateReleaseName(r, yank.Title(name)); err != nil {
+		return fmt.Errorf("update %s release %s: %w", p.Name(), tag, err)
+	}
+	if err = m.UpdateReleaseBody(r, "> "+yank.Notice(version, reason)+"\n\n"+r.Body); err != nil {
+		return fmt.Errorf("update %s release %s: %w", p.Name(), tag, err)
+	}
+	log.Info("marked %s release %s yanked", p.Name(), tag)
+	return nil
+}
@@ -1,6 +1,6 @@
 module github.com/coffee377/autoctl
 
-go 1.18
+go 1.20
 
 require (
 	github.com/alibabacloud-go/darabonba-openapi/v2 v2.0.6
@@ -12,9 +12,12 @@ require (
 	github.com/coffee377/autoctl/pkg/semver v0.1.0
 	github.com/mitchellh/go-homedir v1.1.0
 	github.com/ory/x v0.0.581
+	github.com/pelletier/go-toml/v2 v2.0.8
 	github.com/spf13/cast v1.5.1
 	github.com/spf13/cobra v1.7.0
+	github.com/spf13/pflag v1.0.5
 	github.com/spf13/viper v1.16.0
+	gopkg.in/yaml.v3 v3.0.1
 	gorm.io/gorm v1.25.3
 )
 
@@ -55,7 +58,6 @@ require (
 	github.com/modern-go/concurrent v0.0.0-20180306012644-bacd9c7ef1dd // indirect
 	github.com/modern-go/reflect2 v1.0.2 // indirect
 	github.com/open-dingtalk/dingtalk-stream-sdk-go v0.9.0 // indirect
-	github.com/pelletier/go-toml/v2 v2.0.8 // indirect
 	github.com/pkg/errors v0.9.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/rogpeppe/go-internal v1.10.0 // indirect
@@ -63,7 +65,6 @@ require (
 	github.com/sirupsen/logrus v1.9.3 // indirect
 	github.com/spf13/afero v1.9.5 // indirect
 	github.com/spf13/jwalterweatherman v1.1.0 // indirect
-	github.com/spf13/pflag v1.0.5 // indirect
 	github.com/stretchr/testify v1.8.4 // indirect
 	github.com/subosito/gotenv v1.4.2 // indirect
 	github.com/tidwall/gjson v1.14.3 // indirect
@@ -84,5 +85,4 @@ require (
 	google.golang.org/grpc v1.56.1 // indirect
 	google.golang.org/protobuf v1.31.0 // indirect
 	gopkg.in/ini.v1 v1.67.0 // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
@@ -0,0 +1,105 @@
+package approval
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/runctx"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+const (
+	DefaultTimeout  = time.Hour
+	DefaultInterval = 30 * time.Second
+)
+
+// ErrRejected 审批被拒绝
+var ErrRejected = errors.New("release approval rejected")
+
+// Config 发布审批配置，对应配置文件 release.approval 节点
+type Config struct {
+	Enabled     bool          `mapstructure:"enabled"`     // 打标签前等待人工审批
+	Environment string        `mapstructure:"environment"` // GitHub 受保护的部署环境或 GitLab 手动作业名称，设置后在平台上创建待审批的部署
+	PullRequest int           `mapstructure:"pullRequest"` // 发布合并请求编号，与 label、comment 配合使用
+	Label       string        `mapstructure:"label"`       // 合并请求出现该标签时视为审批通过
+	Comment     string        `mapstructure:"comment"`     // 合并请求出现内容一致的评论时视为审批通过，如 /approve
+	Reviewers   []string      `mapstructure:"reviewers"`   // 允许通过评论审批的用户，为空时不限制
+	Timeout     time.Duration `mapstructure:"timeout"`     // 等待审批的最长时间，默认 1h
+	Interval    time.Duration `mapstructure:"interval"`    // 查询审批状态的间隔，默认 30s
+}
+
+func (c Config) withDefaults() Config {
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	if c.Interval <= 0 {
+		c.Interval = DefaultInterval
+	}
+	return c
+}
+
+// Validate 检查审批方式是否完整
+func (c Config) Validate() error {
+	if c.Environment == "" && c.Label == "" && c.Comment == "" {
+		return errors.New("release approval requires environment, label or comment")
+	}
+	if (c.Label != "" || c.Comment != "") && c.PullRequest <= 0 {
+		return errors.New("release approval by label or comment requires the pull request number")
+	}
+	return nil
+}
+
+// Wait 在平台上请求审批并轮询直到通过、被拒绝或超时，ref 为待发布的提交
+func Wait(cfg Config, p provider.Provider, ref, description string) error {
+	cfg = cfg.withDefaults()
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	approver, ok := p.(provider.Approver)
+	if !ok {
+		return fmt.Errorf("provider %s does not support release approval", p.Name())
+	}
+	checks := make([]func() (string, error), 0, 2)
+	if cfg.Environment != "" {
+		a, err := approver.RequestApproval(&provider.Approval{Ref: ref, Environment: cfg.Environment, Description: description})
+		if err != nil {
+			return fmt.Errorf("request release approval: %w", err)
+		}
+		log.Info("waiting for approval of %s %s", cfg.Environment, a.URL)
+		checks = append(checks, func() (string, error) { return approver.ApprovalStatus(a) })
+	}
+	if cfg.Label != "" || cfg.Comment != "" {
+		cond := provider.PullRequestApproval{Number: cfg.PullRequest, Label: cfg.Label, Comment: cfg.Comment, Reviewers: cfg.Reviewers}
+		log.Info("waiting for approval on pull request #%d", cfg.PullRequest)
+		checks = append(checks, func() (string, error) { return approver.PullRequestStatus(cond) })
+	}
+	deadline := time.Now().Add(cfg.Timeout)
+	for {
+		// 所有审批方式均需通过
+		approved := true
+		for _, check := range checks {
+			status, err := check()
+			if err != nil {
+				return err
+			}
+			if status == provider.ApprovalRejected {
+				return ErrRejected
+			}
+			if status != provider.ApprovalApproved {
+				approved = false
+			}
+		}
+		if approved {
+			log.Info("release approved")
+			return nil
+		}
+		if time.Now().Add(cfg.Interval).After(deadline) {
+			return fmt.Errorf("release approval timed out after %s", cfg.Timeout)
+		}
+		if err := runctx.Sleep(cfg.Interval); err != nil {
+			return fmt.Errorf("wait for release approval: %w", err)
+		}
+	}
+}
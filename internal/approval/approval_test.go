@@ -0,0 +1,93 @@
+package approval
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/provider"
+)
+
+func TestWait_Environment(t *testing.T) {
+	var polls int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/a/b/deployments":
+			_, _ = w.Write([]byte(`{"id":42,"url":"https://api.github.com/repos/a/b/deployments/42"}`))
+		case r.URL.Path == "/repos/a/b/deployments/42/statuses":
+			if atomic.AddInt32(&polls, 1) < 3 {
+				_, _ = w.Write([]byte(`[{"state":"waiting"}]`))
+				return
+			}
+			_, _ = w.Write([]byte(`[{"state":"success"},{"state":"waiting"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, _ := provider.New(provider.Config{Type: provider.GitHub, URL: server.URL, Repo: "a/b", Token: "token"})
+	cfg := Config{Enabled: true, Environment: "production", Interval: time.Millisecond}
+	if err := Wait(cfg, p, "abc123", "release v1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	if polls := atomic.LoadInt32(&polls); polls != 3 {
+		t.Errorf("expected 3 polls, but %d got", polls)
+	}
+}
+
+func TestWait_Comment(t *testing.T) {
+	comments := `[{"body":"/approve","user":{"login":"mallory"}}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/a/b/issues/7/comments" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(comments))
+	}))
+	defer server.Close()
+
+	p, _ := provider.New(provider.Config{Type: provider.GitHub, URL: server.URL, Repo: "a/b", Token: "token"})
+	cfg := Config{PullRequest: 7, Comment: "/approve", Reviewers: []string{"alice"}, Timeout: 20 * time.Millisecond, Interval: 5 * time.Millisecond}
+	if err := Wait(cfg, p, "abc123", ""); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected approval from unlisted reviewer ignored, but %v got", err)
+	}
+	comments = `[{"body":"/approve","user":{"login":"mallory"}},{"body":" /approve\n","user":{"login":"Alice"}}]`
+	if err := Wait(cfg, p, "abc123", ""); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWait_GitLabRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.RawPath {
+		case "/projects/g%2Fp/pipelines":
+			_, _ = w.Write([]byte(`[{"id":9}]`))
+		case "/projects/g%2Fp/pipelines/9/jobs":
+			_, _ = w.Write([]byte(`[{"id":1,"name":"build","status":"success"},{"id":2,"name":"approve","status":"manual"}]`))
+		case "/projects/g%2Fp/jobs/2":
+			_, _ = w.Write([]byte(`{"id":2,"name":"approve","status":"canceled"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, _ := provider.New(provider.Config{Type: provider.GitLab, URL: server.URL, Repo: "g/p", Token: "token"})
+	if err := Wait(Config{Environment: "approve", Interval: time.Millisecond}, p, "abc123", ""); !errors.Is(err, ErrRejected) {
+		t.Fatalf("expected rejected, but %v got", err)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	if err := (Config{}).Validate(); err == nil {
+		t.Error("expected missing approval method error, but nil got")
+	}
+	if err := (Config{Label: "approved"}).Validate(); err == nil {
+		t.Error("expected missing pull request error, but nil got")
+	}
+}
@@ -0,0 +1,74 @@
+package artifact
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// Artifact 发布产物文件
+type Artifact struct {
+	Name   string `json:"name"`          // 文件名
+	Path   string `json:"path"`          // 文件路径
+	Size   int64  `json:"size"`          // 文件大小（字节）
+	SHA256 string `json:"sha256"`        // 文件摘要
+	URL    string `json:"url,omitempty"` // 上传后的下载地址
+}
+
+// Collect 按通配符查找产物文件，相对路径基于 dir，结果按路径排序并去重
+func Collect(dir string, patterns []string) ([]Artifact, error) {
+	seen := map[string]bool{}
+	artifacts := make([]Artifact, 0)
+	for _, pattern := range patterns {
+		if !filepath.IsAbs(pattern) && dir != "" {
+			pattern = filepath.Join(dir, pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid artifact pattern %q: %w", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("no artifact matches %q", pattern)
+		}
+		for _, path := range matches {
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			a, err := New(path)
+			if err != nil {
+				return nil, err
+			}
+			if a != nil {
+				artifacts = append(artifacts, *a)
+			}
+		}
+	}
+	sort.Slice(artifacts, func(i, j int) bool { return artifacts[i].Path < artifacts[j].Path })
+	return artifacts, nil
+}
+
+// New 读取文件信息并计算摘要，目录返回 nil
+func New(path string) (*Artifact, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		return nil, nil
+	}
+	h := sha256.New()
+	if _, err = io.Copy(h, f); err != nil {
+		return nil, err
+	}
+	return &Artifact{Name: info.Name(), Path: path, Size: info.Size(), SHA256: hex.EncodeToString(h.Sum(nil))}, nil
+}
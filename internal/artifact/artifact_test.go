@@ -0,0 +1,29 @@
+package artifact
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCollect(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, "dist", "sub"), 0o755)
+	_ = os.WriteFile(filepath.Join(dir, "dist", "autoctl-linux"), []byte("bin"), 0o644)
+	_ = os.WriteFile(filepath.Join(dir, "dist", "checksums.txt"), []byte("sum"), 0o644)
+
+	artifacts, err := Collect(dir, []string{"dist/*", "dist/autoctl-*"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(artifacts) != 2 || artifacts[0].Name != "autoctl-linux" || artifacts[0].Size != 3 {
+		t.Errorf("unexpected artifacts %+v", artifacts)
+	}
+	if artifacts[0].SHA256 != "51a1f05af85e342e3c849b47d387086476282d5f50dc240c19216d6edfb1eb5a" {
+		t.Errorf("unexpected sha256 %s", artifacts[0].SHA256)
+	}
+
+	if _, err = Collect(dir, []string{"build/*"}); err == nil {
+		t.Error("expected no artifact matches error, got nil")
+	}
+}
@@ -0,0 +1,210 @@
+package assets
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/artifact"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// partSuffix 未下载完成的文件后缀，再次下载时从该文件末尾继续
+const partSuffix = ".part"
+
+// Options 下载选项
+type Options struct {
+	Dir             string   // 下载目录
+	Patterns        []string // 附件名称通配符，为空时下载全部附件
+	RequireChecksum bool     // 没有摘要可校验时失败
+}
+
+// checksumFile 是否为包含多个文件摘要的校验文件，如 checksums.txt、SHA256SUMS
+func checksumFile(name string) bool {
+	lower := strings.ToLower(name)
+	return lower == "sha256sums" || lower == "sha256sums.txt" || strings.HasSuffix(lower, "checksums.txt")
+}
+
+// ParseChecksums 解析 sha256sum 格式的校验文件，每行为 <摘要> <文件名>，文件名前的 * 表示二进制模式
+func ParseChecksums(r io.Reader) (map[string]string, error) {
+	sums := map[string]string{}
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || len(fields[0]) != 64 {
+			continue
+		}
+		sums[path.Base(strings.TrimPrefix(fields[1], "*"))] = strings.ToLower(fields[0])
+	}
+	return sums, scanner.Err()
+}
+
+// Match 附件名称是否匹配任一通配符，没有通配符时全部匹配
+func Match(name string, patterns []string) (bool, error) {
+	if len(patterns) == 0 {
+		return true, nil
+	}
+	for _, p := range patterns {
+		matched, err := path.Match(p, name)
+		if err != nil {
+			return false, fmt.Errorf("invalid asset pattern %q: %w", p, err)
+		}
+		if matched {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Download 下载版本发布中匹配的附件并校验摘要；已下载且摘要一致的文件跳过，未完成的文件断点续传
+// 摘要依次取平台提供的摘要、附件中的校验文件及 <name>.sha256 文件
+func Download(d provider.AssetDownloader, tag string, opts Options) ([]artifact.Artifact, error) {
+	list, err := d.ListAssets(tag)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.MkdirAll(opts.Dir, 0o755); err != nil {
+		return nil, err
+	}
+	byName := map[string]*provider.Asset{}
+	for _, a := range list {
+		byName[a.Name] = a
+	}
+	sums := map[string]string{}
+	var selected []*provider.Asset
+	for _, a := range list {
+		matched, err := Match(a.Name, opts.Patterns)
+		if err != nil {
+			return nil, err
+		}
+		if checksumFile(a.Name) {
+			if err = loadChecksums(d, a, sums); err != nil {
+				return nil, err
+			}
+		}
+		if matched {
+			selected = append(selected, a)
+		}
+	}
+	if len(selected) == 0 {
+		return nil, fmt.Errorf("no asset of release %s matches %s", tag, strings.Join(opts.Patterns, ", "))
+	}
+	downloaded := make([]artifact.Artifact, 0, len(selected))
+	for _, a := range selected {
+		want := a.SHA256
+		if want == "" {
+			want = sums[a.Name]
+		}
+		if sidecar, ok := byName[a.Name+".sha256"]; want == "" && ok {
+			s := map[string]string{}
+			if err = loadChecksums(d, sidecar, s); err != nil {
+				return nil, err
+			}
+			want = s[a.Name]
+		}
+		if want == "" {
+			if opts.RequireChecksum {
+				return downloaded, fmt.Errorf("no checksum found for %s", a.Name)
+			}
+			if !checksumFile(a.Name) && !strings.HasSuffix(a.Name, ".sha256") {
+				log.Warn("no checksum found for %s, skip verification", a.Name)
+			}
+		}
+		f, err := fetch(d, a, opts.Dir, want)
+		if err != nil {
+			return downloaded, err
+		}
+		downloaded = append(downloaded, *f)
+	}
+	return downloaded, nil
+}
+
+func loadChecksums(d provider.AssetDownloader, a *provider.Asset, sums map[string]string) error {
+	body, _, err := d.DownloadAsset(a, 0)
+	if err != nil {
+		return fmt.Errorf("download %s: %w", a.Name, err)
+	}
+	defer body.Close()
+	parsed, err := ParseChecksums(body)
+	if err != nil {
+		return err
+	}
+	for k, v := range parsed {
+		sums[k] = v
+	}
+	return nil
+}
+
+// fetch 下载单个附件到 dir 并校验摘要，want 为空时不校验
+func fetch(d provider.AssetDownloader, a *provider.Asset, dir, want string) (*artifact.Artifact, error) {
+	target := filepath.Join(dir, filepath.Base(a.Name))
+	// 没有摘要时按文件大小判断是否已下载
+	if existing, err := artifact.New(target); err == nil && existing != nil &&
+		(want != "" && existing.SHA256 == want || want == "" && a.Size > 0 && existing.Size == a.Size) {
+		log.Info("%s already downloaded", a.Name)
+		return existing, nil
+	}
+	part := target + partSuffix
+	var offset int64
+	if info, err := os.Stat(part); err == nil {
+		offset = info.Size()
+	}
+	if a.Size > 0 && offset > a.Size {
+		offset = 0
+	}
+	if a.Size == 0 || offset < a.Size {
+		if err := resume(d, a, part, offset); err != nil {
+			return nil, err
+		}
+	}
+	f, err := artifact.New(part)
+	if err != nil {
+		return nil, err
+	}
+	if want != "" && f.SHA256 != want {
+		_ = os.Remove(part)
+		return nil, fmt.Errorf("checksum mismatch for %s, expected %s, but %s got", a.Name, want, f.SHA256)
+	}
+	if err = os.Rename(part, target); err != nil {
+		return nil, err
+	}
+	f.Name, f.Path = a.Name, target
+	log.Info("downloaded %s", a.Name)
+	return f, nil
+}
+
+// resume 从 offset 处继续下载到 part 文件，服务端不支持断点续传时从头下载
+func resume(d provider.AssetDownloader, a *provider.Asset, part string, offset int64) error {
+	body, resumed, err := d.DownloadAsset(a, offset)
+	var statusErr *provider.StatusError
+	if offset > 0 && errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+		// 未完成的文件已包含全部内容或已失效，从头下载
+		offset = 0
+		body, resumed, err = d.DownloadAsset(a, 0)
+	}
+	if err != nil {
+		return fmt.Errorf("download %s: %w", a.Name, err)
+	}
+	defer body.Close()
+	flag := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+	if resumed {
+		flag = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		log.Info("resume %s from %d bytes", a.Name, offset)
+	}
+	out, err := os.OpenFile(part, flag, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, body); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("download %s: %w", a.Name, err)
+	}
+	return out.Close()
+}
@@ -0,0 +1,71 @@
+package assets
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/provider"
+)
+
+type fakeRelease struct {
+	files   map[string]string
+	offsets map[string]int64 // 每个附件最后一次请求的起始位置
+}
+
+func (f *fakeRelease) ListAssets(tag string) ([]*provider.Asset, error) {
+	var list []*provider.Asset
+	for _, name := range []string{"app-linux.tar.gz", "app-darwin.tar.gz", "checksums.txt"} {
+		if data, ok := f.files[name]; ok {
+			list = append(list, &provider.Asset{Name: name, URL: "https://example.com/" + name, Size: int64(len(data))})
+		}
+	}
+	return list, nil
+}
+
+func (f *fakeRelease) DownloadAsset(a *provider.Asset, offset int64) (io.ReadCloser, bool, error) {
+	f.offsets[a.Name] = offset
+	return io.NopCloser(bytes.NewReader([]byte(f.files[a.Name][offset:]))), offset > 0, nil
+}
+
+func sum(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func TestDownload(t *testing.T) {
+	linux, darwin := "linux binary", "darwin binary"
+	f := &fakeRelease{offsets: map[string]int64{}, files: map[string]string{
+		"app-linux.tar.gz":  linux,
+		"app-darwin.tar.gz": darwin,
+		"checksums.txt":     fmt.Sprintf("%s  app-linux.tar.gz\n%s *dist/app-darwin.tar.gz\n", sum(linux), sum("tampered")),
+	}}
+	dir := t.TempDir()
+	// 上次下载中断，保留了前 5 个字节
+	_ = os.WriteFile(filepath.Join(dir, "app-linux.tar.gz"+partSuffix), []byte(linux[:5]), 0o644)
+
+	list, err := Download(f, "v1.0.0", Options{Dir: dir, Patterns: []string{"*linux*"}, RequireChecksum: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 1 || list[0].SHA256 != sum(linux) || f.offsets["app-linux.tar.gz"] != 5 {
+		t.Errorf("expected linux asset resumed from 5 bytes, but %+v %v got", list, f.offsets)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "app-linux.tar.gz")); string(data) != linux {
+		t.Errorf("unexpected content %q", data)
+	}
+	if _, err = Download(f, "v1.0.0", Options{Dir: dir, Patterns: []string{"*darwin*"}}); err == nil {
+		t.Error("expected checksum mismatch")
+	}
+	if _, err = os.Stat(filepath.Join(dir, "app-darwin.tar.gz")); !os.IsNotExist(err) {
+		t.Errorf("expected mismatched file removed, but %v got", err)
+	}
+	if _, err = Download(f, "v1.0.0", Options{Dir: dir, Patterns: []string{"*.zip"}}); err == nil {
+		t.Error("expected no asset matched")
+	}
+}
@@ -0,0 +1,332 @@
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/httpclient"
+	"github.com/coffee377/autoctl/internal/webhook"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+const (
+	BackendFile = "file"
+	BackendGit  = "git"
+	BackendHTTP = "http"
+
+	DefaultFile = ".autoctl/audit.jsonl"
+	DefaultRef  = "refs/autoctl/audit"
+
+	StatusSuccess = "success"
+	StatusFailed  = "failed"
+	StatusSkipped = "skipped"
+
+	maxAttempts = 5 // 并发推送审计引用冲突时的最大尝试次数
+)
+
+// ErrQueryUnsupported 后端只支持追加，不支持查询
+var ErrQueryUnsupported = errors.New("audit backend does not support queries")
+
+// Config 发布审计日志，对应配置文件 audit 节点
+type Config struct {
+	Enabled bool              `mapstructure:"enabled"` // 记录每次发布操作
+	Backend string            `mapstructure:"backend"` // file（默认，追加写入 JSON Lines 文件）| git（远程仓库中只追加的引用）| http（逐条发送到收集端点）
+	File    string            `mapstructure:"file"`    // file 后端的文件，相对路径基于工作目录，默认 .autoctl/audit.jsonl
+	Ref     string            `mapstructure:"ref"`     // git 后端的引用，每条记录为一个提交，默认 refs/autoctl/audit
+	URL     string            `mapstructure:"url"`     // http 后端的接收地址
+	Secret  string            `mapstructure:"secret"`  // http 后端的 HMAC-SHA256 签名密钥，签名放在 X-Autoctl-Signature-256 请求头
+	Headers map[string]string `mapstructure:"headers"` // http 后端的自定义请求头
+	HTTP    httpclient.Config `mapstructure:"http"`    // 代理及 TLS 配置，未设置的字段继承全局 http 节点
+}
+
+// Entry 一条审计记录
+type Entry struct {
+	Time    time.Time         `json:"time"`
+	Actor   string            `json:"actor"`            // 触发者，CI 中为触发流水线的用户
+	Runner  string            `json:"runner,omitempty"` // 主机、进程及 CI 任务
+	Action  string            `json:"action"`           // 操作，如 release、release prune、promote、chatops
+	Args    []string          `json:"args,omitempty"`   // 位置参数
+	Flags   map[string]string `json:"flags,omitempty"`  // 显式设置的命令行参数
+	Version string            `json:"version,omitempty"`
+	Tag     string            `json:"tag,omitempty"`
+	Commit  string            `json:"commit,omitempty"`
+	Steps   []string          `json:"steps,omitempty"` // 完成的发布步骤
+	DryRun  bool              `json:"dryRun,omitempty"`
+	Status  string            `json:"status"` // success | failed | skipped
+	Error   string            `json:"error,omitempty"`
+}
+
+// Log 审计日志后端
+type Log interface {
+	// Append 追加一条记录
+	Append(e *Entry) error
+	// Entries 按时间顺序返回全部记录
+	Entries() ([]*Entry, error)
+}
+
+// New 根据配置创建审计日志后端，remote 为 git 后端存放引用的远程仓库，为空时只保存在本地
+func New(cfg Config, plus *git.Plus, remote string) (Log, error) {
+	switch cfg.Backend {
+	case "", BackendFile:
+		name := cfg.File
+		if name == "" {
+			name = DefaultFile
+		}
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(plus.Cwd, name)
+		}
+		return &fileLog{name: name}, nil
+	case BackendGit:
+		ref := cfg.Ref
+		if ref == "" {
+			ref = DefaultRef
+		}
+		return &gitLog{ref: ref, plus: plus, remote: remote}, nil
+	case BackendHTTP:
+		if cfg.URL == "" {
+			return nil, errors.New("audit http backend requires a url")
+		}
+		client, err := httpclient.New(cfg.HTTP)
+		if err != nil {
+			return nil, err
+		}
+		return &httpLog{cfg: cfg, client: client}, nil
+	}
+	return nil, fmt.Errorf("unsupported audit backend %q, valid values are %s, %s, %s", cfg.Backend, BackendFile, BackendGit, BackendHTTP)
+}
+
+// Actor 当前触发者，依次读取 CI 平台的用户变量、git 用户及系统用户
+func Actor(plus *git.Plus) string {
+	for _, env := range []string{"GITHUB_ACTOR", "GITLAB_USER_LOGIN", "BUILD_USER_ID"} {
+		if v := os.Getenv(env); v != "" {
+			return v
+		}
+	}
+	if output, err := plus.Run("config", "user.email"); err == nil && len(bytes.TrimSpace(output)) > 0 {
+		return strings.TrimSpace(string(output))
+	}
+	if u, err := user.Current(); err == nil {
+		return u.Username
+	}
+	return "unknown"
+}
+
+// Filter 查询条件，为空的字段不参与过滤
+type Filter struct {
+	Action  string
+	Actor   string
+	Version string
+	Status  string
+	Since   time.Time
+	Limit   int // 只返回最近的 n 条
+}
+
+// Match 记录是否满足查询条件
+func (f Filter) Match(e *Entry) bool {
+	switch {
+	case f.Action != "" && e.Action != f.Action:
+	case f.Actor != "" && !strings.EqualFold(e.Actor, f.Actor):
+	case f.Version != "" && e.Version != f.Version && e.Tag != f.Version:
+	case f.Status != "" && e.Status != f.Status:
+	case !f.Since.IsZero() && e.Time.Before(f.Since):
+	default:
+		return true
+	}
+	return false
+}
+
+// Query 按条件查询记录，按时间顺序返回
+func Query(l Log, f Filter) ([]*Entry, error) {
+	entries, err := l.Entries()
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]*Entry, 0, len(entries))
+	for _, e := range entries {
+		if f.Match(e) {
+			matched = append(matched, e)
+		}
+	}
+	if f.Limit > 0 && len(matched) > f.Limit {
+		matched = matched[len(matched)-f.Limit:]
+	}
+	return matched, nil
+}
+
+// fileLog 追加写入的 JSON Lines 文件
+type fileLog struct {
+	name string
+}
+
+func (l *fileLog) Append(e *Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(l.name), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (l *fileLog) Entries() ([]*Entry, error) {
+	f, err := os.Open(l.name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return decode(f, l.name)
+}
+
+// decode 逐行解析 JSON Lines，忽略空行
+func decode(r io.Reader, name string) ([]*Entry, error) {
+	entries := make([]*Entry, 0)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1<<20)
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		e := &Entry{}
+		if err := json.Unmarshal(scanner.Bytes(), e); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, line, err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, scanner.Err()
+}
+
+// gitLog 远程仓库中只追加的引用，每条记录为一个以上一条记录为父提交的提交，
+// 通过 --force-with-lease 推送保证并发写入时不会丢失记录
+type gitLog struct {
+	ref    string
+	plus   *git.Plus
+	remote string
+}
+
+func (l *gitLog) tip() (string, error) {
+	if l.remote != "" {
+		return l.plus.SyncRef(l.remote, l.ref)
+	}
+	output, err := l.plus.Run("rev-parse", "--verify", "--quiet", l.ref)
+	if err != nil {
+		return "", nil
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+func (l *gitLog) Append(e *Entry) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		parent, err := l.tip()
+		if err != nil {
+			return err
+		}
+		args := []string{"commit-tree", "HEAD^{tree}", "-m", fmt.Sprintf("audit: %s %s", e.Action, e.Status), "-m", string(data)}
+		if parent != "" {
+			args = append(args, "-p", parent)
+		}
+		output, err := l.plus.Run(args...)
+		if err != nil {
+			return err
+		}
+		object := strings.TrimSpace(string(output))
+		if l.remote == "" {
+			_, err = l.plus.Run("update-ref", l.ref, object)
+			return err
+		}
+		if err = l.plus.CompareAndSwapRef(l.remote, l.ref, parent, object); err == nil {
+			_, err = l.plus.Run("update-ref", l.ref, object)
+			return err
+		}
+		// 其它任务同时追加了记录
+		if attempt >= maxAttempts {
+			return fmt.Errorf("append audit entry: %w", err)
+		}
+		log.Debug("append audit entry: %s, retry", err)
+	}
+}
+
+func (l *gitLog) Entries() ([]*Entry, error) {
+	tip, err := l.tip()
+	if err != nil || tip == "" {
+		return nil, err
+	}
+	// 提交说明的第一段为摘要，第二段为记录
+	output, err := l.plus.Run("log", "--reverse", "--format=%b%x00", tip)
+	if err != nil {
+		return nil, err
+	}
+	var lines bytes.Buffer
+	for _, body := range bytes.Split(output, []byte{0}) {
+		if body = bytes.TrimSpace(body); len(body) > 0 {
+			lines.Write(body)
+			lines.WriteByte('\n')
+		}
+	}
+	return decode(&lines, l.ref)
+}
+
+// httpLog 逐条发送到收集端点，如 SIEM 或日志平台，查询需在收集端进行
+type httpLog struct {
+	cfg    Config
+	client *http.Client
+}
+
+func (l *httpLog) Append(e *Entry) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, l.cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range l.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "autoctl")
+	if l.cfg.Secret != "" {
+		req.Header.Set(webhook.HeaderSignature, webhook.Sign(l.cfg.Secret, body))
+	}
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("audit %s: %d %s", l.cfg.URL, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+func (l *httpLog) Entries() ([]*Entry, error) {
+	return nil, ErrQueryUnsupported
+}
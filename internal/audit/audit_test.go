@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/testutil"
+	"github.com/coffee377/autoctl/internal/webhook"
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+func TestGitLog(t *testing.T) {
+	testutil.GitIdentity(t)
+	root := t.TempDir()
+	clone := func(name string) *git.Plus {
+		repo := filepath.Join(root, name)
+		testutil.GitRun(t, root, "clone", "-q", "origin.git", repo)
+		testutil.GitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: init")
+		return &git.Plus{Cwd: repo}
+	}
+	testutil.GitRun(t, root, "init", "-q", "--bare", "origin.git")
+	a, _ := New(Config{Backend: BackendGit}, clone("a"), "origin")
+	b, _ := New(Config{Backend: BackendGit}, clone("b"), "origin")
+	now := time.Now().UTC()
+	for i, entry := range []struct {
+		log Log
+		e   *Entry
+	}{
+		{a, &Entry{Time: now.Add(-48 * time.Hour), Actor: "alice", Action: "release", Version: "1.0.0", Status: StatusSuccess}},
+		{b, &Entry{Time: now.Add(-time.Hour), Actor: "bob", Action: "release", Version: "1.1.0", Status: StatusFailed}},
+		{a, &Entry{Time: now, Actor: "alice", Action: "promote", Version: "1.0.0", Status: StatusSuccess}},
+	} {
+		if err := entry.log.Append(entry.e); err != nil {
+			t.Fatalf("append entry %d: %v", i, err)
+		}
+	}
+	entries, err := b.Entries()
+	if err != nil || len(entries) != 3 || entries[0].Actor != "alice" || entries[2].Action != "promote" {
+		t.Fatalf("expected 3 entries in order, but %+v %v got", entries, err)
+	}
+	for _, tt := range []struct {
+		filter   Filter
+		expected int
+	}{
+		{Filter{Actor: "Alice"}, 2},
+		{Filter{Action: "release", Status: StatusFailed}, 1},
+		{Filter{Version: "1.0.0", Since: now.Add(-2 * time.Hour)}, 1},
+		{Filter{Limit: 2}, 2},
+	} {
+		if matched, err := Query(a, tt.filter); err != nil || len(matched) != tt.expected {
+			t.Errorf("Query(%+v) expected %d entries, but %d %v got", tt.filter, tt.expected, len(matched), err)
+		}
+	}
+}
+
+func TestFileLog(t *testing.T) {
+	l, _ := New(Config{}, &git.Plus{Cwd: t.TempDir()}, "")
+	if entries, err := l.Entries(); err != nil || len(entries) != 0 {
+		t.Fatalf("expected no entries, but %v %v got", entries, err)
+	}
+	_ = l.Append(&Entry{Action: "release", Status: StatusSuccess})
+	_ = l.Append(&Entry{Action: "release prune", Status: StatusSuccess, Flags: map[string]string{"dry-run": "true"}})
+	entries, err := l.Entries()
+	if err != nil || len(entries) != 2 || entries[1].Flags["dry-run"] != "true" {
+		t.Errorf("unexpected entries %+v %v", entries, err)
+	}
+}
+
+func TestHTTPLog(t *testing.T) {
+	var signature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(webhook.HeaderSignature)
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	l, err := New(Config{Backend: BackendHTTP, URL: server.URL, Secret: "s3cret"}, &git.Plus{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = l.Append(&Entry{Action: "release"}); err != nil || signature == "" {
+		t.Errorf("expected signed entry posted, but %q %v got", signature, err)
+	}
+	if _, err = l.Entries(); err != ErrQueryUnsupported {
+		t.Errorf("expected query unsupported, but %v got", err)
+	}
+}
@@ -0,0 +1,370 @@
+package binrepo
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/artifact"
+	"github.com/coffee377/autoctl/internal/httpclient"
+)
+
+const (
+	Nexus       = "nexus"
+	Artifactory = "artifactory"
+
+	LayoutGeneric = "generic"
+	LayoutMaven   = "maven"
+
+	DefaultPath = "{version}"
+	// MetadataFile generic 布局中记录版本信息及文件摘要的文件
+	MetadataFile = "release.json"
+)
+
+// Config Nexus 或 Artifactory 制品仓库，对应配置文件 repositories 节点中的一项
+type Config struct {
+	Name       string            `mapstructure:"name"`       // 名称，用于日志输出
+	Type       string            `mapstructure:"type"`       // nexus | artifactory
+	URL        string            `mapstructure:"url"`        // 服务地址，如 https://nexus.example.com、https://example.jfrog.io/artifactory
+	Repository string            `mapstructure:"repository"` // 仓库名称，Nexus 为 raw 或 maven2 类型的 hosted 仓库
+	Layout     string            `mapstructure:"layout"`     // generic | maven，默认 generic
+	Path       string            `mapstructure:"path"`       // generic 布局的上传目录，{version} 替换为版本号，默认 {version}
+	GroupID    string            `mapstructure:"groupId"`    // maven 布局的 groupId
+	ArtifactID string            `mapstructure:"artifactId"` // maven 布局的 artifactId
+	Username   string            `mapstructure:"username"`   // 用户名，默认读取 NEXUS_USERNAME 或 ARTIFACTORY_USERNAME 环境变量
+	Password   string            `mapstructure:"password"`   // 密码，默认读取 NEXUS_PASSWORD 或 ARTIFACTORY_PASSWORD 环境变量
+	Token      string            `mapstructure:"token"`      // Artifactory 访问令牌，默认读取 ARTIFACTORY_TOKEN 环境变量，设置后忽略用户名及密码
+	HTTP       httpclient.Config `mapstructure:"http"`       // 代理、TLS 及重试配置，未设置的字段继承全局 http 节点
+}
+
+func (c Config) String() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Type + ":" + c.Repository
+}
+
+// Validate 检查配置，不访问网络
+func (c Config) Validate() error {
+	switch c.Type {
+	case Nexus, Artifactory:
+	default:
+		return fmt.Errorf("unsupported repository type %q, one of %s|%s", c.Type, Nexus, Artifactory)
+	}
+	if c.URL == "" || c.Repository == "" {
+		return fmt.Errorf("repository %s: url and repository are required", c)
+	}
+	switch c.Layout {
+	case "", LayoutGeneric:
+	case LayoutMaven:
+		if c.GroupID == "" || c.ArtifactID == "" {
+			return fmt.Errorf("repository %s: groupId and artifactId are required by the maven layout", c)
+		}
+	default:
+		return fmt.Errorf("unsupported repository layout %q, one of %s|%s", c.Layout, LayoutGeneric, LayoutMaven)
+	}
+	return nil
+}
+
+// Release 发布到制品仓库的版本
+type Release struct {
+	Version string
+	Tag     string
+	Commit  string
+	Files   []artifact.Artifact
+}
+
+// Object 已上传的文件
+type Object struct {
+	Repository string `json:"repository"` // 制品仓库名称
+	Path       string `json:"path"`       // 仓库内的路径
+	URL        string `json:"url"`
+	SHA256     string `json:"sha256,omitempty"`
+}
+
+// checksums 文件的 MD5、SHA-1 及 SHA-256 摘要，Maven 仓库及 Artifactory 都需要
+type checksums struct {
+	MD5    string `json:"md5"`
+	SHA1   string `json:"sha1"`
+	SHA256 string `json:"sha256"`
+}
+
+func sum(r io.Reader) (checksums, error) {
+	hashes := []hash.Hash{md5.New(), sha1.New(), sha256.New()}
+	w := io.MultiWriter(hashes[0], hashes[1], hashes[2])
+	if _, err := io.Copy(w, r); err != nil {
+		return checksums{}, err
+	}
+	return checksums{
+		MD5:    hex.EncodeToString(hashes[0].Sum(nil)),
+		SHA1:   hex.EncodeToString(hashes[1].Sum(nil)),
+		SHA256: hex.EncodeToString(hashes[2].Sum(nil)),
+	}, nil
+}
+
+// Publisher 上传发布产物到制品仓库
+type Publisher struct {
+	cfg    Config
+	client *http.Client
+}
+
+func New(cfg Config) (*Publisher, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	env := strings.ToUpper(cfg.Type)
+	if cfg.Username == "" {
+		cfg.Username = os.Getenv(env + "_USERNAME")
+	}
+	if cfg.Password == "" {
+		cfg.Password = os.Getenv(env + "_PASSWORD")
+	}
+	if cfg.Token == "" && cfg.Type == Artifactory {
+		cfg.Token = os.Getenv("ARTIFACTORY_TOKEN")
+	}
+	client, err := httpclient.New(cfg.HTTP)
+	if err != nil {
+		return nil, err
+	}
+	return &Publisher{cfg: cfg, client: client}, nil
+}
+
+// Password 实际使用的密码或令牌，用于输出屏蔽
+func (p *Publisher) Password() string {
+	if p.cfg.Token != "" {
+		return p.cfg.Token
+	}
+	return p.cfg.Password
+}
+
+// base 仓库根地址，Nexus 为 <url>/repository/<repository>，Artifactory 为 <url>/<repository>
+func (p *Publisher) base() string {
+	u := strings.TrimSuffix(p.cfg.URL, "/")
+	if p.cfg.Type == Nexus {
+		return u + "/repository/" + url.PathEscape(p.cfg.Repository)
+	}
+	return u + "/" + url.PathEscape(p.cfg.Repository)
+}
+
+// Publish 上传发布产物及摘要，generic 布局写入 release.json，maven 布局生成 pom
+func (p *Publisher) Publish(rel Release) ([]Object, error) {
+	objects := make([]Object, 0, len(rel.Files)+1)
+	meta := metadata{Version: rel.Version, Tag: rel.Tag, Commit: rel.Commit}
+	for _, a := range rel.Files {
+		f, err := os.Open(a.Path)
+		if err != nil {
+			return objects, err
+		}
+		obj, c, err := p.upload(p.key(a.Name, rel.Version), f, rel)
+		f.Close()
+		if err != nil {
+			return objects, err
+		}
+		objects = append(objects, *obj)
+		meta.Files = append(meta.Files, file{Name: path.Base(obj.Path), Size: a.Size, checksums: c})
+	}
+	var (
+		name string
+		data []byte
+		err  error
+	)
+	if p.cfg.Layout == LayoutMaven {
+		name = fmt.Sprintf("%s-%s.pom", p.cfg.ArtifactID, rel.Version)
+		data, err = p.pom(rel)
+	} else {
+		name = MetadataFile
+		data, err = json.MarshalIndent(meta, "", "  ")
+	}
+	if err != nil {
+		return objects, err
+	}
+	obj, _, err := p.upload(path.Join(p.dir(rel.Version), name), bytes.NewReader(data), rel)
+	if err != nil {
+		return objects, err
+	}
+	return append(objects, *obj), nil
+}
+
+// upload 上传文件，Artifactory 通过请求头校验摘要并写入版本属性，Nexus 另外上传 .md5、.sha1、.sha256 摘要文件
+func (p *Publisher) upload(key string, r io.ReadSeeker, rel Release) (*Object, checksums, error) {
+	c, err := sum(r)
+	if err != nil {
+		return nil, c, err
+	}
+	target := p.base() + "/" + escapePath(key)
+	u := target
+	headers := map[string]string{}
+	if p.cfg.Type == Artifactory {
+		// https://jfrog.com/help/r/jfrog-rest-apis/deploy-artifact
+		headers["X-Checksum-Md5"] = c.MD5
+		headers["X-Checksum-Sha1"] = c.SHA1
+		headers["X-Checksum-Sha256"] = c.SHA256
+		u += matrix(rel)
+	}
+	if err = p.put(u, r, headers); err != nil {
+		return nil, c, fmt.Errorf("upload %s to %s: %w", key, p.cfg, err)
+	}
+	if p.cfg.Type == Nexus {
+		for _, s := range [][2]string{{"md5", c.MD5}, {"sha1", c.SHA1}, {"sha256", c.SHA256}} {
+			if err = p.put(target+"."+s[0], strings.NewReader(s[1]), nil); err != nil {
+				return nil, c, fmt.Errorf("upload %s.%s to %s: %w", key, s[0], p.cfg, err)
+			}
+		}
+	}
+	return &Object{Repository: p.cfg.String(), Path: key, URL: target, SHA256: c.SHA256}, c, nil
+}
+
+func (p *Publisher) put(u string, r io.ReadSeeker, headers map[string]string) error {
+	size, err := r.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, u, io.NopCloser(r))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	req.GetBody = func() (io.ReadCloser, error) {
+		if _, err := r.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(r), nil
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	if p.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.cfg.Token)
+	} else if p.cfg.Username != "" {
+		req.SetBasicAuth(p.cfg.Username, p.cfg.Password)
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("PUT %s: %s %s", req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// dir 版本目录，maven 布局为 <groupId 路径>/<artifactId>/<version>
+func (p *Publisher) dir(version string) string {
+	if p.cfg.Layout == LayoutMaven {
+		return path.Join(strings.ReplaceAll(p.cfg.GroupID, ".", "/"), p.cfg.ArtifactID, version)
+	}
+	dir := p.cfg.Path
+	if dir == "" {
+		dir = DefaultPath
+	}
+	return strings.Trim(strings.ReplaceAll(dir, "{version}", version), "/")
+}
+
+// key 文件在仓库中的路径，maven 布局为 <artifactId>-<version>[-<classifier>].<ext>，分类符取自去除 artifactId 及版本号后的文件名
+func (p *Publisher) key(name, version string) string {
+	name = filepath.Base(name)
+	if p.cfg.Layout != LayoutMaven {
+		return path.Join(p.dir(version), name)
+	}
+	ext := extension(name)
+	stem := strings.TrimPrefix(strings.TrimSuffix(name, ext), p.cfg.ArtifactID)
+	stem = strings.ReplaceAll(stem, version, "")
+	classifier := strings.Join(strings.FieldsFunc(stem, func(r rune) bool { return r == '-' || r == '_' || r == '.' }), "-")
+	file := p.cfg.ArtifactID + "-" + version
+	if classifier != "" {
+		file += "-" + classifier
+	}
+	return path.Join(p.dir(version), file+ext)
+}
+
+// extension 文件扩展名，支持 .tar.gz 等双重扩展名
+func extension(name string) string {
+	for _, ext := range []string{".tar.gz", ".tar.xz", ".tar.bz2", ".tar.zst"} {
+		if strings.HasSuffix(name, ext) {
+			return ext
+		}
+	}
+	return filepath.Ext(name)
+}
+
+// metadata generic 布局中的 release.json
+type metadata struct {
+	Version string `json:"version"`
+	Tag     string `json:"tag"`
+	Commit  string `json:"commit,omitempty"`
+	Files   []file `json:"files"`
+}
+
+type file struct {
+	Name string `json:"name"`
+	Size int64  `json:"size"`
+	checksums
+}
+
+// pom 以 pom 打包类型描述版本，发布产物作为带分类符的附件
+func (p *Publisher) pom(rel Release) ([]byte, error) {
+	type scm struct {
+		Tag string `xml:"tag,omitempty"`
+	}
+	project := struct {
+		XMLName      xml.Name `xml:"project"`
+		XMLNS        string   `xml:"xmlns,attr"`
+		ModelVersion string   `xml:"modelVersion"`
+		GroupID      string   `xml:"groupId"`
+		ArtifactID   string   `xml:"artifactId"`
+		Version      string   `xml:"version"`
+		Packaging    string   `xml:"packaging"`
+		SCM          scm      `xml:"scm"`
+	}{
+		XMLNS:        "http://maven.apache.org/POM/4.0.0",
+		ModelVersion: "4.0.0",
+		GroupID:      p.cfg.GroupID,
+		ArtifactID:   p.cfg.ArtifactID,
+		Version:      rel.Version,
+		Packaging:    "pom",
+		SCM:          scm{Tag: rel.Tag},
+	}
+	data, err := xml.MarshalIndent(project, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
+// matrix Artifactory 的矩阵参数，上传时设置文件属性
+func matrix(rel Release) string {
+	var sb strings.Builder
+	for _, prop := range [][2]string{{"version", rel.Version}, {"vcs.tag", rel.Tag}, {"vcs.revision", rel.Commit}} {
+		if prop[1] != "" {
+			sb.WriteString(";" + prop[0] + "=" + url.PathEscape(prop[1]))
+		}
+	}
+	return sb.String()
+}
+
+func escapePath(p string) string {
+	segments := strings.Split(p, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
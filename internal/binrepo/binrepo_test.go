@@ -0,0 +1,97 @@
+package binrepo
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/artifact"
+)
+
+type fakeRepo struct {
+	files   map[string]string
+	headers map[string]http.Header
+}
+
+func (f *fakeRepo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if user, pass, _ := r.BasicAuth(); r.Method != http.MethodPut || (user != "ci" && r.Header.Get("Authorization") != "Bearer t0ken") || (user == "ci" && pass != "secret") {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	data, _ := io.ReadAll(r.Body)
+	f.files[r.URL.EscapedPath()] = string(data)
+	f.headers[r.URL.EscapedPath()] = r.Header
+	w.WriteHeader(http.StatusCreated)
+}
+
+func newArtifact(t *testing.T, name, content string) artifact.Artifact {
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a, err := artifact.New(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return *a
+}
+
+func TestPublish_NexusGeneric(t *testing.T) {
+	repo := &fakeRepo{files: map[string]string{}, headers: map[string]http.Header{}}
+	srv := httptest.NewServer(repo)
+	defer srv.Close()
+	p, err := New(Config{Type: Nexus, URL: srv.URL, Repository: "releases", Path: "autoctl/{version}", Username: "ci", Password: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects, err := p.Publish(Release{Version: "1.2.0", Tag: "v1.2.0", Files: []artifact.Artifact{newArtifact(t, "app.zip", "zip")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(objects) != 2 || objects[0].URL != srv.URL+"/repository/releases/autoctl/1.2.0/app.zip" {
+		t.Errorf("unexpected objects %+v", objects)
+	}
+	if sha := repo.files["/repository/releases/autoctl/1.2.0/app.zip.sha256"]; sha != objects[0].SHA256 {
+		t.Errorf("expected sha256 %s, but %s got", objects[0].SHA256, sha)
+	}
+	meta := metadata{}
+	if err = json.Unmarshal([]byte(repo.files["/repository/releases/autoctl/1.2.0/release.json"]), &meta); err != nil || meta.Tag != "v1.2.0" || len(meta.Files) != 1 || meta.Files[0].SHA1 == "" {
+		t.Errorf("unexpected metadata %+v %v", meta, err)
+	}
+}
+
+func TestPublish_ArtifactoryMaven(t *testing.T) {
+	repo := &fakeRepo{files: map[string]string{}, headers: map[string]http.Header{}}
+	srv := httptest.NewServer(repo)
+	defer srv.Close()
+	p, err := New(Config{Type: Artifactory, URL: srv.URL + "/artifactory", Repository: "libs-release", Layout: LayoutMaven, GroupID: "com.example", ArtifactID: "autoctl", Token: "t0ken"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = p.Publish(Release{Version: "1.2.0", Tag: "v1.2.0", Commit: "abc", Files: []artifact.Artifact{newArtifact(t, "autoctl_1.2.0_linux_amd64.tar.gz", "tgz")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	key := "/artifactory/libs-release/com/example/autoctl/1.2.0/autoctl-1.2.0-linux-amd64.tar.gz;version=1.2.0;vcs.tag=v1.2.0;vcs.revision=abc"
+	if h := repo.headers[key]; h == nil || h.Get("X-Checksum-Sha256") == "" {
+		t.Errorf("expected %s uploaded with checksum headers, but %v got", key, repo.files)
+	}
+	pom := repo.files["/artifactory/libs-release/com/example/autoctl/1.2.0/autoctl-1.2.0.pom;version=1.2.0;vcs.tag=v1.2.0;vcs.revision=abc"]
+	if !strings.Contains(pom, "<groupId>com.example</groupId>") || !strings.Contains(pom, "<packaging>pom</packaging>") {
+		t.Errorf("unexpected pom %s", pom)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	if err := (Config{Type: Nexus, URL: "https://nexus", Repository: "r", Layout: LayoutMaven}).Validate(); err == nil {
+		t.Error("expected groupId and artifactId required")
+	}
+	if err := (Config{Type: "s3"}).Validate(); err == nil {
+		t.Error("expected unsupported type error")
+	}
+}
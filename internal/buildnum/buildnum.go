@@ -0,0 +1,250 @@
+package buildnum
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/fileutil"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+const (
+	BackendFile     = "file"
+	BackendGit      = "git"
+	BackendProvider = "provider"
+
+	DefaultFile     = ".autoctl/build-number"
+	DefaultRef      = "refs/notes/autoctl-build"
+	DefaultVariable = "AUTOCTL_BUILD_NUMBER"
+
+	maxAttempts = 5 // 并发递增冲突时的最大尝试次数
+)
+
+// Config 单调递增的构建号，对应配置文件 buildNumber 节点
+type Config struct {
+	Enabled  bool   `mapstructure:"enabled"`  // 发布时递增构建号，模板中通过 {{ .BuildNumber }} 引用
+	Backend  string `mapstructure:"backend"`  // file（默认）| git（远程仓库中的 git notes）| provider（代码托管平台的 CI/CD 变量）
+	File     string `mapstructure:"file"`     // file 后端的文件，相对路径基于工作目录，默认 .autoctl/build-number
+	Ref      string `mapstructure:"ref"`      // git 后端的 notes 引用，默认 refs/notes/autoctl-build
+	Variable string `mapstructure:"variable"` // provider 后端的变量名，默认 AUTOCTL_BUILD_NUMBER
+	Start    int64  `mapstructure:"start"`    // 第一个构建号，默认 1，迁移已有的构建号时设置
+}
+
+func (c Config) withDefaults() Config {
+	if c.Backend == "" {
+		c.Backend = BackendFile
+	}
+	if c.File == "" {
+		c.File = DefaultFile
+	}
+	if c.Ref == "" {
+		c.Ref = DefaultRef
+	}
+	if c.Variable == "" {
+		c.Variable = DefaultVariable
+	}
+	if c.Start <= 0 {
+		c.Start = 1
+	}
+	return c
+}
+
+// Counter 构建号后端
+type Counter interface {
+	// Current 最近一次分配的构建号，尚未分配时返回 0
+	Current() (int64, error)
+	// Next 分配并返回下一个构建号
+	Next() (int64, error)
+}
+
+// New 根据配置创建构建号后端，remote 为 git 后端存放 notes 的远程仓库，为空时只保存在本地；
+// p 为 provider 后端使用的代码托管平台
+func New(cfg Config, plus *git.Plus, remote string, p provider.Provider) (Counter, error) {
+	cfg = cfg.withDefaults()
+	switch cfg.Backend {
+	case BackendFile:
+		name := cfg.File
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(plus.Cwd, name)
+		}
+		return &fileCounter{name: name, start: cfg.Start}, nil
+	case BackendGit:
+		return &gitCounter{cfg: cfg, plus: plus, remote: remote}, nil
+	case BackendProvider:
+		vars, ok := p.(provider.Variables)
+		if !ok {
+			return nil, errors.New("build number provider backend requires a github or gitlab provider")
+		}
+		return &variableCounter{vars: vars, name: cfg.Variable, start: cfg.Start}, nil
+	}
+	return nil, fmt.Errorf("unsupported build number backend %q, valid values are %s, %s, %s", cfg.Backend, BackendFile, BackendGit, BackendProvider)
+}
+
+// parse 解析构建号，空字符串视为尚未分配
+func parse(s string) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid build number %q", s)
+	}
+	return n, nil
+}
+
+// next 下一个构建号，不小于起始值
+func next(current, start int64) int64 {
+	if current+1 < start {
+		return start
+	}
+	return current + 1
+}
+
+// fileCounter 保存在文件中的构建号，适用于单个构建机或将文件提交回仓库的场景
+type fileCounter struct {
+	name  string
+	start int64
+}
+
+func (c *fileCounter) Current() (int64, error) {
+	data, err := os.ReadFile(c.name)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	n, err := parse(string(data))
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", c.name, err)
+	}
+	return n, nil
+}
+
+func (c *fileCounter) Next() (int64, error) {
+	current, err := c.Current()
+	if err != nil {
+		return 0, err
+	}
+	n := next(current, c.start)
+	if err = os.MkdirAll(filepath.Dir(c.name), 0o755); err != nil {
+		return 0, err
+	}
+	return n, fileutil.WriteFile(c.name, []byte(strconv.FormatInt(n, 10)+"\n"), 0o644)
+}
+
+// variableCounter 保存在代码托管平台 CI/CD 变量中的构建号，平台接口不支持比较并交换，并发的流水线需配合发布锁使用
+type variableCounter struct {
+	vars  provider.Variables
+	name  string
+	start int64
+}
+
+func (c *variableCounter) Current() (int64, error) {
+	value, _, err := c.vars.Variable(c.name)
+	if err != nil {
+		return 0, err
+	}
+	n, err := parse(value)
+	if err != nil {
+		return 0, fmt.Errorf("variable %s: %w", c.name, err)
+	}
+	return n, nil
+}
+
+func (c *variableCounter) Next() (int64, error) {
+	current, err := c.Current()
+	if err != nil {
+		return 0, err
+	}
+	n := next(current, c.start)
+	return n, c.vars.SetVariable(c.name, strconv.FormatInt(n, 10))
+}
+
+// gitCounter 以 git notes 记录每次构建的构建号，附加在构建的提交上，
+// 通过 --force-with-lease 推送 notes 引用保证并发的流水线不会分配相同的构建号
+type gitCounter struct {
+	cfg    Config
+	plus   *git.Plus
+	remote string
+}
+
+// sync 将本地 notes 引用同步为远程仓库中的引用，返回其指向的对象，引用不存在时返回空字符串
+func (c *gitCounter) sync() (string, error) {
+	if c.remote == "" {
+		output, err := c.plus.Run("rev-parse", "--verify", "--quiet", c.cfg.Ref)
+		if err != nil {
+			return "", nil
+		}
+		return strings.TrimSpace(string(output)), nil
+	}
+	// 远程引用不存在时同时丢弃本地推送失败的 notes 提交
+	return c.plus.SyncRef(c.remote, c.cfg.Ref)
+}
+
+// read 读取 notes 引用最新一次提交写入的构建号，每次分配都会产生一个 notes 提交
+func (c *gitCounter) read(object string) (int64, error) {
+	if object == "" {
+		return 0, nil
+	}
+	output, err := c.plus.Run("show", "--format=", "--unified=0", "--no-color", object)
+	if err != nil {
+		return 0, err
+	}
+	var current int64
+	for _, line := range strings.Split(string(output), "\n") {
+		if !strings.HasPrefix(line, "+") || strings.HasPrefix(line, "+++") {
+			continue
+		}
+		n, err := parse(line[1:])
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", c.cfg.Ref, err)
+		}
+		if n > current {
+			current = n
+		}
+	}
+	return current, nil
+}
+
+func (c *gitCounter) Current() (int64, error) {
+	object, err := c.sync()
+	if err != nil {
+		return 0, err
+	}
+	return c.read(object)
+}
+
+func (c *gitCounter) Next() (int64, error) {
+	for attempt := 1; ; attempt++ {
+		expect, err := c.sync()
+		if err != nil {
+			return 0, err
+		}
+		current, err := c.read(expect)
+		if err != nil {
+			return 0, err
+		}
+		n := next(current, c.cfg.Start)
+		object, err := c.plus.AddNote(c.cfg.Ref, "HEAD", strconv.FormatInt(n, 10))
+		if err != nil || c.remote == "" {
+			return n, err
+		}
+		err = c.plus.CompareAndSwapRef(c.remote, c.cfg.Ref, expect, object)
+		if err == nil {
+			return n, nil
+		}
+		// 其它流水线抢先分配了构建号
+		if attempt >= maxAttempts {
+			return 0, fmt.Errorf("allocate build number: %w", err)
+		}
+		log.Debug("allocate build number %d: %s, retry", n, err)
+	}
+}
@@ -0,0 +1,91 @@
+package buildnum
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/testutil"
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+func newRepo(t *testing.T, root, name string) *git.Plus {
+	t.Helper()
+	repo := filepath.Join(root, name)
+	testutil.GitRun(t, root, "clone", "-q", "origin.git", repo)
+	testutil.GitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: "+name)
+	return &git.Plus{Cwd: repo}
+}
+
+func TestGitCounter(t *testing.T) {
+	testutil.GitIdentity(t)
+	root := t.TempDir()
+	testutil.GitRun(t, root, "init", "-q", "--bare", "origin.git")
+	a, _ := New(Config{Backend: BackendGit, Start: 100}, newRepo(t, root, "a"), "origin", nil)
+	b, _ := New(Config{Backend: BackendGit}, newRepo(t, root, "b"), "origin", nil)
+	if n, err := b.Current(); err != nil || n != 0 {
+		t.Fatalf("expected no build number, but %d %v got", n, err)
+	}
+	for _, tt := range []struct {
+		counter  Counter
+		expected int64
+	}{{a, 100}, {b, 101}, {a, 102}, {a, 103}} {
+		if n, err := tt.counter.Next(); err != nil || n != tt.expected {
+			t.Fatalf("expected build number %d, but %d %v got", tt.expected, n, err)
+		}
+	}
+	if n, err := b.Current(); err != nil || n != 103 {
+		t.Errorf("expected current build number 103, but %d %v got", n, err)
+	}
+	if output := testutil.GitRun(t, filepath.Join(root, "b"), "notes", "--ref", DefaultRef, "show", "HEAD"); strings.TrimSpace(output) != "101" {
+		t.Errorf("expected build number noted on the commit, but %q got", output)
+	}
+}
+
+func TestFileCounter(t *testing.T) {
+	plus := &git.Plus{Cwd: t.TempDir()}
+	c, _ := New(Config{}, plus, "", nil)
+	for _, expected := range []int64{1, 2} {
+		if n, err := c.Next(); err != nil || n != expected {
+			t.Fatalf("expected build number %d, but %d %v got", expected, n, err)
+		}
+	}
+	if n, err := c.Current(); err != nil || n != 2 {
+		t.Errorf("expected current build number 2, but %d %v got", n, err)
+	}
+	if _, err := New(Config{Backend: BackendProvider}, plus, "", nil); err == nil {
+		t.Errorf("expected provider backend requires a provider")
+	}
+}
+
+type variables map[string]string
+
+func (v variables) Name() string                                                  { return "fake" }
+func (v variables) Verify() error                                                 { return nil }
+func (v variables) CreateRelease(*provider.Release) (*provider.Release, error)    { return nil, nil }
+func (v variables) UploadAsset(*provider.Release, string, string) (string, error) { return "", nil }
+func (v variables) CreatePullRequest(*provider.PullRequest) (*provider.PullRequest, error) {
+	return nil, nil
+}
+
+func (v variables) Variable(name string) (string, bool, error) {
+	value, ok := v[name]
+	return value, ok, nil
+}
+
+func (v variables) SetVariable(name, value string) error {
+	v[name] = value
+	return nil
+}
+
+func TestVariableCounter(t *testing.T) {
+	vars := variables{DefaultVariable: "41"}
+	c, err := New(Config{Backend: BackendProvider}, &git.Plus{}, "", vars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, err := c.Next(); err != nil || n != 42 || vars[DefaultVariable] != "42" {
+		t.Errorf("expected build number 42, but %d %v %v got", n, err, vars)
+	}
+}
@@ -0,0 +1,119 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+)
+
+// DependenciesType 依赖更新提交在变更日志中的类型
+const DependenciesType = "deps"
+
+// 依赖更新对发布的影响
+const (
+	BotReleasePatch = "patch" // 仅有依赖更新时发布修订版本
+	BotReleaseNone  = "none"  // 仅有依赖更新时不视为可发布的变更
+)
+
+// DefaultBotAuthors Renovate 及 Dependabot 的提交作者
+var DefaultBotAuthors = []string{"renovate[bot]", "renovate-bot", "dependabot[bot]", "dependabot-preview[bot]"}
+
+// DefaultBotPatterns Renovate 及 Dependabot 默认的提交标题（合并请求标题），包初始化时编译
+var DefaultBotPatterns = []string{
+	`^\w+\(deps(-dev)?\)!?: `,                  // chore(deps): update dependency vue to v3.3.4
+	`^Bump \S+ from \S+ to \S+`,                // Bump lodash from 4.17.20 to 4.17.21
+	`^Bump the \S+ group`,                      // Bump the npm group with 3 updates
+	`^Update (dependency |module )?.+ to v?\d`, // Update dependency lodash to v4.17.21
+}
+
+var (
+	botRegs             = compileAll(DefaultBotPatterns)
+	conventionalDepsReg = botRegs[0]
+)
+
+func compileAll(patterns []string) []*regexp.Regexp {
+	regs := make([]*regexp.Regexp, len(patterns))
+	for i, p := range patterns {
+		regs[i] = regexp.MustCompile(p)
+	}
+	return regs
+}
+
+// Bots 依赖更新机器人提交的识别方式，对应配置文件 release.bots 节点
+type Bots struct {
+	Disabled bool     `mapstructure:"disabled"` // 不识别依赖更新提交
+	Authors  []string `mapstructure:"authors"`  // 机器人提交作者名称或邮箱，默认 DefaultBotAuthors
+	Patterns []string `mapstructure:"patterns"` // 额外的提交标题正则表达式，与 DefaultBotPatterns 同时生效
+	Release  string   `mapstructure:"release"`  // 仅有依赖更新时的发布影响 patch | none，默认 patch
+}
+
+// Validate 检查发布影响及标题正则表达式
+func (b Bots) Validate() error {
+	switch b.Release {
+	case "", BotReleasePatch, BotReleaseNone:
+	default:
+		return fmt.Errorf("invalid bots release %q, valid values are %s, %s", b.Release, BotReleasePatch, BotReleaseNone)
+	}
+	for _, p := range b.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid bots pattern %q: %w", p, err)
+		}
+	}
+	return nil
+}
+
+// Releasable 仅有依赖更新时是否可发布
+func (b Bots) Releasable() bool {
+	return b.Release != BotReleaseNone
+}
+
+// Match 提交是否为依赖更新，作者为机器人或标题符合依赖更新的格式
+func (b Bots) Match(record *commit.CommitRecord) bool {
+	_, ok := b.MatchRule(record)
+	return ok
+}
+
+// MatchRule 与 Match 相同，同时返回匹配的规则，如 bot author renovate[bot]
+func (b Bots) MatchRule(record *commit.CommitRecord) (string, bool) {
+	if b.Disabled {
+		return "", false
+	}
+	authors := b.Authors
+	if len(authors) == 0 {
+		authors = DefaultBotAuthors
+	}
+	for _, a := range authors {
+		if strings.EqualFold(record.Author, a) || strings.Contains(strings.ToLower(record.Email), strings.ToLower(a)) {
+			return "bot author " + a, true
+		}
+	}
+	title := firstLine(record.RawMessage)
+	for _, reg := range botRegs {
+		if reg.MatchString(title) {
+			return "bot pattern " + reg.String(), true
+		}
+	}
+	for _, p := range b.Patterns {
+		if reg, err := regexp.Compile(p); err == nil && reg.MatchString(title) {
+			return "bot pattern " + p, true
+		}
+	}
+	return "", false
+}
+
+// dependencyCommit 依赖更新提交转换为变更日志条目，非约定式提交的标题整体作为描述
+func dependencyCommit(record *commit.CommitRecord) Commit {
+	c := NewCommit(record)
+	c.Type, c.Scope = DependenciesType, ""
+	if title := firstLine(record.RawMessage); title != "" && !conventionalDepsReg.MatchString(title) {
+		c.Subject = title
+	}
+	return c
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(line)
+}
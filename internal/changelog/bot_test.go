@@ -0,0 +1,69 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/deps"
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+)
+
+func TestBots_Match(t *testing.T) {
+	bot := record("1", "chore: update lockfile")
+	bot.Author, bot.Email = "renovate[bot]", "29139614+renovate[bot]@users.noreply.github.com"
+	tests := []struct {
+		record   *commit.CommitRecord
+		expected bool
+	}{
+		{bot, true},
+		{record("2", "chore(deps): update dependency vue to v3.3.4"), true},
+		{record("3", "build(deps-dev): bump eslint from 8.0.0 to 8.1.0"), true},
+		{record("4", "Bump lodash from 4.17.20 to 4.17.21 (#12)"), true},
+		{record("5", "Bump the npm group with 3 updates"), true},
+		{record("6", "Update module github.com/spf13/cobra to v1.8.0"), true},
+		{record("7", "chore: bump version"), false},
+		{record("8", "feat: update readme"), false},
+	}
+	for _, test := range tests {
+		if actual := (Bots{}).Match(test.record); actual != test.expected {
+			t.Errorf("%s: expected %v, but %v got", test.record.RawMessage, test.expected, actual)
+		}
+	}
+	if (Bots{Disabled: true}).Match(bot) {
+		t.Error("expected disabled bots not matching")
+	}
+	if !(Bots{Patterns: []string{`^deps: `}}).Match(record("9", "deps: pin actions")) {
+		t.Error("expected custom pattern matching")
+	}
+}
+
+func TestBuild_Dependencies(t *testing.T) {
+	records := []*commit.CommitRecord{
+		record("1111111aaaa", "fix: handle empty tags"),
+		record("2222222bbbb", "Bump lodash from 4.17.20 to 4.17.21"),
+		record("3333333cccc", "fix(deps): update dependency vue to v3.3.4"),
+	}
+	log := Build("1.2.1", "1.2.0", "", records, Options{})
+	log.Dependencies = &deps.Report{Changes: []deps.Change{{Manifest: "go.mod", Name: "github.com/spf13/cobra", Kind: deps.Updated, From: "v1.6.0", To: "v1.7.0"}}}
+	expected := "## 1.2.1\n\n### Bug Fixes\n\n* handle empty tags (1111111)\n\n### Dependencies\n\n" +
+		"* Bump lodash from 4.17.20 to 4.17.21 (2222222)\n" +
+		"* update dependency vue to v3.3.4 (3333333)\n" +
+		"* **go.mod:** bump `github.com/spf13/cobra` from v1.6.0 to v1.7.0\n"
+	if actual := log.Markdown(); actual != expected {
+		t.Errorf("\nExpected: \n%s\nActual: \n%s\n", expected, actual)
+	}
+	if log.OnlyDependencies() {
+		t.Error("expected changelog with fixes not only dependencies")
+	}
+	if only := Build("1.2.1", "1.2.0", "", records[1:], Options{}); !only.OnlyDependencies() {
+		t.Error("expected changelog only with dependency updates")
+	}
+}
+
+func TestBots_Validate(t *testing.T) {
+	if err := (Bots{Release: "minor"}).Validate(); err == nil {
+		t.Error("expected invalid release error")
+	}
+	if err := (Bots{Patterns: []string{"("}}).Validate(); err == nil {
+		t.Error("expected invalid pattern error")
+	}
+}
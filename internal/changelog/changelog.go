@@ -0,0 +1,340 @@
+package changelog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/deps"
+	"github.com/coffee377/autoctl/pkg/git"
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+)
+
+// SectionType 变更日志中展示的提交类型及标题，顺序即展示顺序
+type SectionType struct {
+	Type  string `mapstructure:"type"`
+	Title string `mapstructure:"title"`
+}
+
+// DefaultSectionTypes 与 conventional-changelog 保持一致
+var DefaultSectionTypes = []SectionType{
+	{Type: "feat", Title: "Features"},
+	{Type: "fix", Title: "Bug Fixes"},
+	{Type: "perf", Title: "Performance Improvements"},
+	{Type: "revert", Title: "Reverts"},
+}
+
+const (
+	HighlightsTitle      = "Highlights"
+	BreakingChangesTitle = "⚠ BREAKING CHANGES"
+	DependenciesTitle    = "Dependencies"
+	LicensesTitle        = "License Changes"
+	EndOfLifeTitle       = "End of Life"
+	SourceCodeLabel      = "Source code"
+	MilestoneLabel       = "Milestone"
+)
+
+// Commit 变更日志中的一条提交
+type Commit struct {
+	Hash         string `json:"hash"`
+	Type         string `json:"type,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	Subject      string `json:"subject"`
+	Author       string `json:"author,omitempty"`
+	Breaking     bool   `json:"breaking,omitempty"`
+	BreakingNote string `json:"breakingNote,omitempty"`
+}
+
+// ShortHash 7 位提交哈希
+func (c Commit) ShortHash() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+	return c.Hash
+}
+
+// Section 同一类型的提交
+type Section struct {
+	Type    string   `json:"type"`
+	Title   string   `json:"title"`
+	Commits []Commit `json:"commits"`
+}
+
+// Changelog 一个版本的变更日志
+type Changelog struct {
+	Version  string        `json:"version"`
+	Previous string        `json:"previous,omitempty"`
+	Date     string        `json:"date"`
+	Breaking []Commit      `json:"breaking,omitempty"`
+	Sections []Section     `json:"sections,omitempty"`
+	Security []SecurityFix `json:"security,omitempty"` // 安全修复，不再出现在所属类型的分组中
+
+	Notice       string       `json:"notice,omitempty"`       // 版本说明，以引用块展示在版本标题之后，如代码冻结期间发布
+	Highlights   string       `json:"highlights,omitempty"`   // 变更摘要，展示在详细变更之前
+	Dependencies *deps.Report `json:"dependencies,omitempty"` // 依赖及许可证变更
+	EndOfLife    []string     `json:"endOfLife,omitempty"`    // 本次发布后停止支持的版本说明
+	Migrations   []Migration  `json:"migrations,omitempty"`   // 迁移指南，来自破坏性变更说明及新增的迁移文档
+	Components   []Component  `json:"components,omitempty"`   // 按 CODEOWNERS 归属到组件的变更
+	CompareURL   string       `json:"compareUrl,omitempty"`   // 与上一个版本的比较链接，版本标题链接到该地址
+	Archives     []Link       `json:"archives,omitempty"`     // 源码归档下载链接
+	Milestone    *Link        `json:"milestone,omitempty"`    // 版本对应的里程碑
+}
+
+// Collect 获取两个版本之间的提交记录，from 为空时获取 to 的全部提交
+func Collect(plus *git.Plus, from, to string) ([]*commit.CommitRecord, error) {
+	if to == "" {
+		to = "HEAD"
+	}
+	revRange := to
+	if from != "" {
+		revRange = fmt.Sprintf("%s..%s", from, to)
+	}
+	return plus.Logs(revRange)
+}
+
+// NormalizeType 去除 gitmoji 形式类型两侧的冒号并转为小写，如 :bug: => bug
+func NormalizeType(t string) string {
+	return strings.ToLower(strings.Trim(t, ":"))
+}
+
+// NewCommit 提交记录转换为变更日志条目
+func NewCommit(record *commit.CommitRecord) Commit {
+	c := Commit{Hash: record.Commit, Author: record.Author}
+	if record.Message == nil || record.Message.Header == nil {
+		return c
+	}
+	header := record.Message.Header
+	c.Type = NormalizeType(header.Type)
+	c.Scope = header.Scope
+	c.Subject = header.Description
+	c.Breaking = header.Broken
+	if footer := record.Message.Footer; footer != nil && footer.BreakingChange != "" {
+		c.Breaking = true
+		c.BreakingNote = footer.BreakingChange
+	}
+	return c
+}
+
+// Options 变更日志生成选项
+type Options struct {
+	Types []SectionType // 展示的提交类型，为空时使用 DefaultSectionTypes
+	Bots  Bots          // 依赖更新提交归入 Dependencies 分组
+}
+
+// Visible 提交转换为变更日志条目，并判断是否会出现在变更日志中（展示的类型、依赖更新或破坏性变更）
+func Visible(record *commit.CommitRecord, opts Options) (Commit, bool) {
+	if opts.Bots.Match(record) {
+		return dependencyCommit(record), true
+	}
+	c := NewCommit(record)
+	if c.Breaking {
+		return c, true
+	}
+	if _, ok := SecurityCommit(record, c); ok {
+		return c, true
+	}
+	types := opts.Types
+	if len(types) == 0 {
+		types = DefaultSectionTypes
+	}
+	for _, t := range types {
+		if t.Type == c.Type {
+			return c, true
+		}
+	}
+	return c, false
+}
+
+// Build 按类型分组生成变更日志，Renovate、Dependabot 等机器人的依赖更新提交归入 Dependencies 分组
+func Build(version, previous, date string, records []*commit.CommitRecord, opts Options) *Changelog {
+	types := opts.Types
+	if len(types) == 0 {
+		types = DefaultSectionTypes
+	}
+	log := &Changelog{Version: version, Previous: previous, Date: date}
+	sections := make(map[string]*Section, len(types)+1)
+	for _, t := range types {
+		sections[t.Type] = &Section{Type: t.Type, Title: t.Title}
+	}
+	dependencies := sections[DependenciesType]
+	if dependencies == nil {
+		dependencies = &Section{Type: DependenciesType, Title: DependenciesTitle}
+		types = append(types, SectionType{Type: DependenciesType, Title: DependenciesTitle})
+		sections[DependenciesType] = dependencies
+	}
+	for _, record := range records {
+		c := NewCommit(record)
+		if opts.Bots.Match(record) {
+			c = dependencyCommit(record)
+		}
+		if c.Breaking {
+			log.Breaking = append(log.Breaking, c)
+		}
+		if fix, ok := SecurityCommit(record, c); ok {
+			log.Security = append(log.Security, fix)
+			continue
+		}
+		if s, ok := sections[c.Type]; ok {
+			s.Commits = append(s.Commits, c)
+		}
+	}
+	for _, t := range types {
+		if s := sections[t.Type]; len(s.Commits) > 0 {
+			log.Sections = append(log.Sections, *s)
+		}
+	}
+	return log
+}
+
+// IsEmpty 是否没有任何需要展示的变更
+func (c *Changelog) IsEmpty() bool {
+	return len(c.Breaking) == 0 && len(c.Sections) == 0 && len(c.Security) == 0 && c.Dependencies.IsEmpty()
+}
+
+// OnlyDependencies 是否只有依赖更新提交，没有其它需要展示的变更
+func (c *Changelog) OnlyDependencies() bool {
+	if len(c.Breaking) > 0 || len(c.Security) > 0 || (c.Dependencies != nil && len(c.Dependencies.Licenses) > 0) {
+		return false
+	}
+	for _, s := range c.Sections {
+		if s.Type != DependenciesType {
+			return false
+		}
+	}
+	return !c.IsEmpty()
+}
+
+// Markdown 渲染为 Markdown 格式
+func (c *Changelog) Markdown() string {
+	return c.Localized(nil)
+}
+
+// Localized 渲染为 Markdown 格式，标题及固定文字使用 titles 中的译文，提交描述保持原文
+func (c *Changelog) Localized(titles Titles) string {
+	var sb strings.Builder
+	if c.CompareURL != "" {
+		sb.WriteString(fmt.Sprintf("## [%s](%s)", c.Version, c.CompareURL))
+	} else {
+		sb.WriteString(fmt.Sprintf("## %s", c.Version))
+	}
+	if c.Date != "" {
+		sb.WriteString(fmt.Sprintf(" (%s)", c.Date))
+	}
+	sb.WriteString("\n")
+	if len(c.Archives) > 0 {
+		links := make([]string, 0, len(c.Archives))
+		for _, a := range c.Archives {
+			links = append(links, fmt.Sprintf("[%s](%s)", a.Name, a.URL))
+		}
+		sb.WriteString(fmt.Sprintf("\n%s: %s\n", titles.get(SourceCodeLabel), strings.Join(links, " · ")))
+	}
+	if c.Milestone != nil {
+		sb.WriteString(fmt.Sprintf("\n%s: [%s](%s)\n", titles.get(MilestoneLabel), c.Milestone.Name, c.Milestone.URL))
+	}
+	if c.Notice != "" {
+		sb.WriteString(fmt.Sprintf("\n> %s\n", c.Notice))
+	}
+	writeSecurity(&sb, c.Security, titles)
+	if c.Highlights != "" {
+		sb.WriteString(fmt.Sprintf("\n### %s\n\n%s\n", titles.get(HighlightsTitle), c.Highlights))
+	}
+	if len(c.Breaking) > 0 {
+		sb.WriteString(fmt.Sprintf("\n### %s\n\n", titles.get(BreakingChangesTitle)))
+		for _, b := range c.Breaking {
+			note := b.BreakingNote
+			if note == "" {
+				note = b.Subject
+			}
+			if a := c.migrationAnchor(b.Hash); a != "" {
+				note = fmt.Sprintf("%s ([migration](#%s))", firstLine(note), a)
+			}
+			writeItem(&sb, b.Scope, note, "")
+		}
+	}
+	writeMigrations(&sb, c.Migrations, titles)
+	// 依赖更新提交与清单文件中的依赖变更合并展示
+	var dependencies *Section
+	for i, s := range c.Sections {
+		if s.Type == DependenciesType {
+			dependencies = &c.Sections[i]
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("\n### %s\n\n", titles.get(s.Title)))
+		for _, c := range s.Commits {
+			writeItem(&sb, c.Scope, c.Subject, c.ShortHash())
+		}
+	}
+	writeDependencies(&sb, dependencies, c.Dependencies, titles)
+	writeComponents(&sb, c.Components, titles)
+	if len(c.EndOfLife) > 0 {
+		sb.WriteString(fmt.Sprintf("\n### %s\n\n", titles.get(EndOfLifeTitle)))
+		for _, note := range c.EndOfLife {
+			writeItem(&sb, "", note, "")
+		}
+	}
+	return sb.String()
+}
+
+func writeDependencies(sb *strings.Builder, section *Section, report *deps.Report, titles Titles) {
+	if report == nil {
+		report = &deps.Report{}
+	}
+	if section != nil || len(report.Changes) > 0 {
+		title := DependenciesTitle
+		if section != nil {
+			title = section.Title
+		}
+		sb.WriteString(fmt.Sprintf("\n### %s\n\n", titles.get(title)))
+	}
+	if section != nil {
+		for _, c := range section.Commits {
+			writeItem(sb, c.Scope, c.Subject, c.ShortHash())
+		}
+	}
+	for _, c := range report.Changes {
+		var text string
+		switch c.Kind {
+		case deps.Added:
+			text = fmt.Sprintf("add `%s` %s", c.Name, c.To)
+		case deps.Removed:
+			text = fmt.Sprintf("remove `%s` %s", c.Name, c.From)
+		default:
+			text = fmt.Sprintf("bump `%s` from %s to %s", c.Name, c.From, c.To)
+		}
+		writeItem(sb, c.Manifest, text, "")
+	}
+	if len(report.Licenses) > 0 {
+		sb.WriteString(fmt.Sprintf("\n### %s\n\n", titles.get(LicensesTitle)))
+		for _, l := range report.Licenses {
+			name := "project license"
+			if l.Name != "" {
+				name = fmt.Sprintf("`%s`", l.Name)
+			}
+			writeItem(sb, "", fmt.Sprintf("%s changed from %s to %s", name, orUnknown(l.From), orUnknown(l.To)), "")
+		}
+	}
+}
+
+func orUnknown(license string) string {
+	if license == "" {
+		return "unknown"
+	}
+	return license
+}
+
+func writeItem(sb *strings.Builder, scope, text, hash string) {
+	sb.WriteString("* ")
+	if scope != "" {
+		sb.WriteString(fmt.Sprintf("**%s:** ", scope))
+	}
+	sb.WriteString(text)
+	if hash != "" {
+		sb.WriteString(fmt.Sprintf(" (%s)", hash))
+	}
+	sb.WriteString("\n")
+}
+
+// Link 变更日志中的链接
+type Link struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
@@ -0,0 +1,115 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/deps"
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+)
+
+func record(hash, raw string) *commit.CommitRecord {
+	r := &commit.CommitRecord{Commit: hash, RawMessage: raw}
+	r.Message = commit.NewCommitMessage(&r.RawMessage)
+	return r
+}
+
+func TestBuild(t *testing.T) {
+	records := []*commit.CommitRecord{
+		record("1111111aaaa", "feat(core): add plan"),
+		record("2222222bbbb", "fix: handle empty tags"),
+		record("3333333cccc", "chore: bump deps"),
+		record("4444444dddd", "feat!: drop v1 api\n\nBREAKING CHANGE: v1 endpoints removed"),
+	}
+	log := Build("1.3.0", "1.2.0", "2024-01-02", records, Options{})
+	expected := `## 1.3.0 (2024-01-02)
+
+### ⚠ BREAKING CHANGES
+
+* v1 endpoints removed
+
+### Features
+
+* **core:** add plan (1111111)
+* drop v1 api (4444444)
+
+### Bug Fixes
+
+* handle empty tags (2222222)
+`
+	if actual := log.Markdown(); actual != expected {
+		t.Errorf("\nExpected: \n%s\nActual: \n%s\n", expected, actual)
+	}
+}
+
+func TestBuild_Empty(t *testing.T) {
+	log := Build("1.0.1", "1.0.0", "", []*commit.CommitRecord{record("1", "docs: readme")}, Options{})
+	if !log.IsEmpty() {
+		t.Error("expected empty changelog")
+	}
+}
+
+func TestChangelog_MarkdownDependencies(t *testing.T) {
+	log := Build("1.3.0", "1.2.0", "", []*commit.CommitRecord{record("1", "docs: readme")}, Options{})
+	log.Dependencies = &deps.Report{
+		Changes: []deps.Change{
+			{Manifest: "go.mod", Name: "github.com/spf13/cobra", Kind: deps.Updated, From: "v1.6.0", To: "v1.7.0"},
+			{Manifest: "package.json", Name: "vue", Kind: deps.Added, To: "^3.3.0"},
+		},
+		Licenses: []deps.LicenseChange{{Name: "github.com/hashicorp/terraform", From: "MPL-2.0", To: "BUSL-1.1"}},
+	}
+	expected := "## 1.3.0\n\n### Dependencies\n\n" +
+		"* **go.mod:** bump `github.com/spf13/cobra` from v1.6.0 to v1.7.0\n" +
+		"* **package.json:** add `vue` ^3.3.0\n\n" +
+		"### License Changes\n\n" +
+		"* `github.com/hashicorp/terraform` changed from MPL-2.0 to BUSL-1.1\n"
+	if actual := log.Markdown(); actual != expected {
+		t.Errorf("\nExpected: \n%s\nActual: \n%s\n", expected, actual)
+	}
+	if log.IsEmpty() {
+		t.Error("expected changelog with dependency changes not empty")
+	}
+}
+
+func TestChangelog_MarkdownHighlights(t *testing.T) {
+	log := Build("1.3.0", "1.2.0", "", []*commit.CommitRecord{record("1111111aaaa", "feat: add plan")}, Options{})
+	log.Highlights = "Plan your releases."
+	expected := "## 1.3.0\n\n### Highlights\n\nPlan your releases.\n\n### Features\n\n* add plan (1111111)\n"
+	if actual := log.Markdown(); actual != expected {
+		t.Errorf("\nExpected: \n%s\nActual: \n%s\n", expected, actual)
+	}
+}
+
+func TestChangelog_MarkdownEndOfLife(t *testing.T) {
+	log := Build("1.3.0", "1.2.0", "", []*commit.CommitRecord{record("1111111aaaa", "feat: add plan")}, Options{})
+	log.EndOfLife = []string{"1.0.x is no longer supported, the last release is 1.0.2"}
+	expected := "## 1.3.0\n\n### Features\n\n* add plan (1111111)\n\n### End of Life\n\n* 1.0.x is no longer supported, the last release is 1.0.2\n"
+	if actual := log.Markdown(); actual != expected {
+		t.Errorf("\nExpected: \n%s\nActual: \n%s\n", expected, actual)
+	}
+}
+
+func TestBuild_Security(t *testing.T) {
+	records := []*commit.CommitRecord{
+		record("1111111aaaa", "fix(security): escape template output"),
+		record("2222222bbbb", "fix(auth): reject expired tokens\n\nSecurity: CVE-2024-12345\nSeverity: High"),
+		record("3333333cccc", "fix: handle empty tags"),
+	}
+	log := Build("1.2.1", "1.2.0", "", records, Options{})
+	expected := `## 1.2.1
+
+### Security
+
+* escape template output (1111111)
+* **auth:** reject expired tokens — [CVE-2024-12345](https://nvd.nist.gov/vuln/detail/CVE-2024-12345), high severity (2222222)
+
+### Bug Fixes
+
+* handle empty tags (3333333)
+`
+	if actual := log.Markdown(); actual != expected {
+		t.Errorf("\nExpected: \n%s\nActual: \n%s\n", expected, actual)
+	}
+	if _, visible := Visible(record("4", "chore(security): rotate keys"), Options{}); !visible {
+		t.Error("expected security commit visible")
+	}
+}
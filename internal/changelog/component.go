@@ -0,0 +1,70 @@
+package changelog
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	ComponentsTitle = "Components"
+	OwnersLabel     = "Owners"
+)
+
+// Component 按 CODEOWNERS 归属到同一组件的变更
+type Component struct {
+	Name     string   `json:"name"`
+	Owners   []string `json:"owners,omitempty"`   // CODEOWNERS 中的负责人
+	Mentions []string `json:"mentions,omitempty"` // 发布说明及通知中提及的负责团队
+	Commits  []Commit `json:"commits"`
+}
+
+// Attributed 参与组件归属的提交，包括各分组、破坏性变更及安全修复提交，按哈希去重
+func (c *Changelog) Attributed() []Commit {
+	list := make([]Commit, 0)
+	seen := map[string]bool{}
+	add := func(commit Commit) {
+		if commit.Hash != "" && !seen[commit.Hash] {
+			seen[commit.Hash] = true
+			list = append(list, commit)
+		}
+	}
+	for _, b := range c.Breaking {
+		add(b)
+	}
+	for _, s := range c.Sections {
+		for _, commit := range s.Commits {
+			add(commit)
+		}
+	}
+	for _, fix := range c.Security {
+		add(Commit{Hash: fix.Hash, Scope: fix.Scope, Subject: fix.Title})
+	}
+	return list
+}
+
+// writeComponents 负责人以代码格式展示，避免在发布说明中提及；需要通知的团队以 cc 单独列出
+func writeComponents(sb *strings.Builder, components []Component, titles Titles) {
+	if len(components) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("\n### %s\n", titles.get(ComponentsTitle)))
+	for _, component := range components {
+		sb.WriteString(fmt.Sprintf("\n#### %s\n\n", component.Name))
+		if len(component.Owners) > 0 {
+			owners := make([]string, 0, len(component.Owners))
+			for _, owner := range component.Owners {
+				owners = append(owners, "`"+owner+"`")
+			}
+			sb.WriteString(fmt.Sprintf("%s: %s", titles.get(OwnersLabel), strings.Join(owners, ", ")))
+			if len(component.Mentions) > 0 {
+				sb.WriteString(" · cc " + strings.Join(component.Mentions, " "))
+			}
+			sb.WriteString("\n\n")
+		} else if len(component.Mentions) > 0 {
+			sb.WriteString("cc " + strings.Join(component.Mentions, " ") + "\n\n")
+		}
+		for _, commit := range component.Commits {
+			writeItem(sb, commit.Scope, commit.Subject, commit.ShortHash())
+		}
+	}
+}
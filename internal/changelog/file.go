@@ -0,0 +1,18 @@
+package changelog
+
+import "strings"
+
+const (
+	FileName = "CHANGELOG.md"
+	Title    = "# " + DocumentTitle
+)
+
+// Document 渲染完整的变更日志文件，logs 按版本从新到旧排列
+func Document(logs []*Changelog) string {
+	var sb strings.Builder
+	sb.WriteString(Title + "\n")
+	for _, log := range logs {
+		sb.WriteString("\n" + log.Markdown())
+	}
+	return sb.String()
+}
@@ -0,0 +1,20 @@
+package changelog
+
+import (
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/golden"
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+)
+
+func TestDocument(t *testing.T) {
+	first := Build("1.0.0", "", "2024-01-01", []*commit.CommitRecord{
+		record("1111111aaaa", "feat: initial release"),
+	}, Options{})
+	second := Build("1.1.0", "1.0.0", "2024-02-01", []*commit.CommitRecord{
+		record("2222222bbbb", "feat(cli): add changelog check"),
+		record("3333333cccc", "fix: keep trailing newline"),
+	}, Options{})
+	second.CompareURL = "https://github.com/coffee377/autoctl/compare/v1.0.0...v1.1.0"
+	golden.Assert(t, "document", []byte(Document([]*Changelog{second, first})))
+}
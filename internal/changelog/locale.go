@@ -0,0 +1,158 @@
+package changelog
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/runctx"
+	"github.com/coffee377/autoctl/internal/shell"
+	"github.com/coffee377/autoctl/internal/tmpl"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// DocumentTitle 变更日志文件的一级标题
+const DocumentTitle = "Changelog"
+
+// Titles 标题及固定文字的译文，键为英文原文，如 Features => 新功能
+type Titles map[string]string
+
+func (t Titles) get(s string) string {
+	if v, ok := t[s]; ok && v != "" {
+		return v
+	}
+	return s
+}
+
+// BuiltinTitles 内置的标题译文，键为语言
+var BuiltinTitles = map[string]Titles{
+	"zh-CN": {
+		DocumentTitle:              "更新日志",
+		"Features":                 "新功能",
+		"Bug Fixes":                "问题修复",
+		"Performance Improvements": "性能优化",
+		"Reverts":                  "回退",
+		HighlightsTitle:            "重点更新",
+		BreakingChangesTitle:       "⚠ 破坏性变更",
+		DependenciesTitle:          "依赖更新",
+		LicensesTitle:              "许可证变更",
+		EndOfLifeTitle:             "停止支持",
+		SecurityTitle:              "安全修复",
+		MigrationTitle:             "迁移指南",
+		ComponentsTitle:            "组件",
+		OwnersLabel:                "负责人",
+		SourceCodeLabel:            "源代码",
+		MilestoneLabel:             "里程碑",
+	},
+}
+
+// Locale 变更日志的一种语言版本，对应配置文件 release.locales 节点中的一项
+type Locale struct {
+	Lang     string            `mapstructure:"lang"`     // 语言，如 zh-CN，变更日志文件为 CHANGELOG.zh-CN.md
+	Titles   map[string]string `mapstructure:"titles"`   // 标题译文，键为英文标题，覆盖内置译文
+	Template string            `mapstructure:"template"` // 单个版本的模板，可访问变更日志字段及 .Markdown、.Lang，{{ .T "Features" }} 翻译标题，为空时使用内置格式
+	Command  string            `mapstructure:"command"`  // 外部翻译命令，标准输入为渲染后的变更日志，标准输出为译文，环境变量 AUTOCTL_LANG 为目标语言
+}
+
+// Validate 检查语言及模板
+func (l Locale) Validate() error {
+	if l.Lang == "" {
+		return fmt.Errorf("changelog locale lang is required")
+	}
+	if l.Template != "" {
+		if _, err := tmpl.New(l.Lang).Parse(l.Template); err != nil {
+			return fmt.Errorf("changelog locale %s template: %w", l.Lang, err)
+		}
+	}
+	return nil
+}
+
+// titles 合并内置译文及配置的译文
+func (l Locale) titles() Titles {
+	titles := Titles{}
+	for k, v := range BuiltinTitles[l.Lang] {
+		titles[k] = v
+	}
+	for k, v := range l.Titles {
+		titles[k] = v
+	}
+	return titles
+}
+
+// localeData 语言模板的数据
+type localeData struct {
+	*Changelog
+	Lang     string
+	Markdown string // 以内置格式渲染并翻译标题的内容
+	titles   Titles
+}
+
+// T 标题译文，没有译文时返回原文
+func (d localeData) T(s string) string {
+	return d.titles.get(s)
+}
+
+// Render 以该语言渲染单个版本：按模板或内置格式渲染，再交给外部翻译命令
+func (l Locale) Render(c *Changelog) (string, error) {
+	titles := l.titles()
+	text := c.Localized(titles)
+	if l.Template != "" {
+		var err error
+		data := localeData{Changelog: c, Lang: l.Lang, Markdown: text, titles: titles}
+		if text, err = tmpl.Render(l.Lang, l.Template, data); err != nil {
+			return "", fmt.Errorf("changelog locale %s template: %w", l.Lang, err)
+		}
+	}
+	return l.translate(text)
+}
+
+// Document 以该语言渲染完整的变更日志文件，logs 按版本从新到旧排列
+func (l Locale) Document(logs []*Changelog) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("# " + l.titles().get(DocumentTitle) + "\n")
+	for _, log := range logs {
+		text, err := l.Render(log)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString("\n" + text)
+	}
+	return sb.String(), nil
+}
+
+// translate 通过外部翻译命令翻译内容，未配置命令时原样返回
+func (l Locale) translate(text string) (string, error) {
+	if l.Command == "" {
+		return text, nil
+	}
+	ctx, cancel := runctx.Command()
+	defer cancel()
+	cmd := shell.Command(ctx, l.Command)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Env = append(os.Environ(), "AUTOCTL_LANG="+l.Lang)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	start := time.Now()
+	err := cmd.Run()
+	log.TraceCommand(cmd, start, err)
+	if err = runctx.Wrap(ctx, err); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("changelog locale %s command: %w: %s", l.Lang, err, msg)
+		}
+		return "", fmt.Errorf("changelog locale %s command: %w", l.Lang, err)
+	}
+	out := stdout.String()
+	if !strings.HasSuffix(out, "\n") {
+		out += "\n"
+	}
+	return out, nil
+}
+
+// LocaleFile 语言版本的变更日志文件，如 CHANGELOG.md => CHANGELOG.zh-CN.md
+func LocaleFile(file, lang string) string {
+	ext := filepath.Ext(file)
+	return strings.TrimSuffix(file, ext) + "." + lang + ext
+}
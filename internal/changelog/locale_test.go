@@ -0,0 +1,46 @@
+package changelog
+
+import (
+	"strings"
+	"testing"
+
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+)
+
+func TestLocale_Document(t *testing.T) {
+	cl := Build("1.1.0", "1.0.0", "2024-02-01", []*commit.CommitRecord{
+		record("2222222bbbb", "feat(cli): add changelog check"),
+		record("3333333cccc", "fix: keep trailing newline"),
+	}, Options{})
+	l := Locale{Lang: "zh-CN", Titles: map[string]string{"Bug Fixes": "修复"}}
+	doc, err := l.Document([]*Changelog{cl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# 更新日志\n", "### 新功能\n", "### 修复\n", "add changelog check"} {
+		if !strings.Contains(doc, want) {
+			t.Errorf("expected %q in document, but got:\n%s", want, doc)
+		}
+	}
+	if strings.Contains(cl.Markdown(), "新功能") {
+		t.Error("expected default markdown untranslated")
+	}
+
+	l = Locale{Lang: "ja", Template: `## {{ .Version }} {{ .T "Features" }}`, Titles: map[string]string{"Features": "新機能"}, Command: "sed 's/^## /## v/'"}
+	text, err := l.Render(cl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "## v1.1.0 新機能\n" {
+		t.Errorf("expected template rendered and translated by command, but %q got", text)
+	}
+	if err = (Locale{}).Validate(); err == nil {
+		t.Error("expected missing lang error, got nil")
+	}
+}
+
+func TestLocaleFile(t *testing.T) {
+	if got := LocaleFile("docs/CHANGELOG.md", "zh-CN"); got != "docs/CHANGELOG.zh-CN.md" {
+		t.Errorf("LocaleFile() = %s", got)
+	}
+}
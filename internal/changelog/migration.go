@@ -0,0 +1,97 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// MigrationTitle 迁移指南分组标题
+const MigrationTitle = "Migration Guide"
+
+// Migration 迁移指南中的一项，来自破坏性变更说明或新增的迁移文档
+type Migration struct {
+	Anchor string `json:"anchor"`          // 页面内锚点，破坏性变更条目链接到该锚点
+	Title  string `json:"title"`           // 标题
+	Scope  string `json:"scope,omitempty"` // 破坏性变更提交的范围
+	Note   string `json:"note,omitempty"`  // 破坏性变更说明
+	Hash   string `json:"hash,omitempty"`  // 破坏性变更提交
+	Path   string `json:"path,omitempty"`  // 迁移文档路径
+	URL    string `json:"url,omitempty"`   // 迁移文档链接，为空时使用路径
+}
+
+var anchorReg = regexp.MustCompile(`[^a-z0-9]+`)
+
+// anchor 迁移指南锚点，如 migration-abc1234、migration-upgrade-to-v2
+func anchor(s string) string {
+	return "migration-" + strings.Trim(anchorReg.ReplaceAllString(strings.ToLower(s), "-"), "-")
+}
+
+// BreakingMigrations 破坏性变更提交对应的迁移指南条目，说明取 BREAKING CHANGE 脚注，没有脚注时取提交标题
+func BreakingMigrations(breaking []Commit) []Migration {
+	migrations := make([]Migration, 0, len(breaking))
+	for _, c := range breaking {
+		note := c.BreakingNote
+		if note == "" {
+			note = c.Subject
+		}
+		migrations = append(migrations, Migration{
+			Anchor: anchor(c.ShortHash()),
+			Title:  firstLine(note),
+			Scope:  c.Scope,
+			Note:   strings.TrimSpace(note),
+			Hash:   c.Hash,
+		})
+	}
+	return migrations
+}
+
+// DocumentMigration 迁移文档对应的迁移指南条目，标题取文档中的一级标题，没有时取文件名
+func DocumentMigration(path string, content []byte, url string) Migration {
+	name := path[strings.LastIndex(path, "/")+1:]
+	name = strings.TrimSuffix(name, ".md")
+	title := name
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "# ") {
+			title = strings.TrimSpace(line[2:])
+			break
+		}
+	}
+	if url == "" {
+		url = path
+	}
+	return Migration{Anchor: anchor(name), Title: title, Path: path, URL: url}
+}
+
+// migrationAnchor 破坏性变更提交在迁移指南中的锚点
+func (c *Changelog) migrationAnchor(hash string) string {
+	for _, m := range c.Migrations {
+		if m.Hash != "" && m.Hash == hash {
+			return m.Anchor
+		}
+	}
+	return ""
+}
+
+func writeMigrations(sb *strings.Builder, migrations []Migration, titles Titles) {
+	if len(migrations) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("\n### %s\n", titles.get(MigrationTitle)))
+	for _, m := range migrations {
+		sb.WriteString(fmt.Sprintf("\n<a id=\"%s\"></a>\n#### ", m.Anchor))
+		if m.Scope != "" {
+			sb.WriteString(fmt.Sprintf("**%s:** ", m.Scope))
+		}
+		sb.WriteString(m.Title)
+		if m.Hash != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", Commit{Hash: m.Hash}.ShortHash()))
+		}
+		sb.WriteString("\n\n")
+		if m.Path != "" {
+			sb.WriteString(fmt.Sprintf("See [%s](%s).\n", m.Path, m.URL))
+			continue
+		}
+		sb.WriteString(m.Note + "\n")
+	}
+}
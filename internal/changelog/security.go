@@ -0,0 +1,132 @@
+package changelog
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+)
+
+const (
+	SecurityTitle = "Security"
+	SecurityScope = "security" // 范围为 security 的提交视为安全修复，如 fix(security): ...
+)
+
+var (
+	cveReg      = regexp.MustCompile(`CVE-\d{4}-\d{4,}`)
+	ghsaReg     = regexp.MustCompile(`GHSA(?:-[23456789cfghjmpqrvwx]{4}){3}`)
+	severityReg = regexp.MustCompile(`(?im)^severity:\s*(\w+)`)
+)
+
+// SecurityFix 发布说明 Security 分组中的一项，来自安全修复提交或新增的安全变更文件
+type SecurityFix struct {
+	Title       string   `json:"title"`
+	Scope       string   `json:"scope,omitempty"`
+	IDs         []string `json:"ids,omitempty"`         // CVE 或 GHSA 编号
+	Severity    string   `json:"severity,omitempty"`    // 严重程度 low | medium | high | critical
+	Description string   `json:"description,omitempty"` // 详细说明，取提交正文或变更文件内容
+	Hash        string   `json:"hash,omitempty"`        // 安全修复提交
+	Path        string   `json:"path,omitempty"`        // 安全变更文件路径
+	URL         string   `json:"url,omitempty"`         // 安全变更文件链接，为空时使用路径
+}
+
+// advisoryIDs 文本中的 CVE 及 GHSA 编号，按出现顺序去重
+func advisoryIDs(texts ...string) []string {
+	var ids []string
+	seen := map[string]bool{}
+	for _, text := range texts {
+		for _, reg := range []*regexp.Regexp{cveReg, ghsaReg} {
+			for _, id := range reg.FindAllString(text, -1) {
+				if !seen[id] {
+					seen[id] = true
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+	return ids
+}
+
+// SecurityCommit 提交是否为安全修复：范围为 security，或带有 Security、CVE 脚注，如 Security: CVE-2024-1234；
+// Severity 脚注指定严重程度
+func SecurityCommit(record *commit.CommitRecord, c Commit) (SecurityFix, bool) {
+	security := strings.EqualFold(c.Scope, SecurityScope)
+	var notes []string
+	fix := SecurityFix{Title: c.Subject, Scope: c.Scope, Hash: c.Hash}
+	if security {
+		fix.Scope = ""
+	}
+	if record.Message != nil && record.Message.Footer != nil {
+		for _, item := range record.Message.Footer.Items {
+			switch strings.ToLower(item.Token) {
+			case "security", "cve":
+				security = true
+				notes = append(notes, item.Value)
+			case "severity":
+				fix.Severity = strings.ToLower(strings.TrimSpace(item.Value))
+			}
+		}
+	}
+	if !security {
+		return fix, false
+	}
+	if record.Message != nil && record.Message.Body != nil {
+		fix.Description = strings.TrimSpace(strings.Join(record.Message.Body.Description, "\n"))
+	}
+	fix.IDs = advisoryIDs(append(notes, c.Subject)...)
+	return fix, true
+}
+
+// SecurityDocument 安全变更文件对应的条目，标题取文档中的一级标题，没有时取文件名，
+// 编号取文中的 CVE 及 GHSA 编号，严重程度取 Severity: 开头的行
+func SecurityDocument(path string, content []byte, url string) SecurityFix {
+	text := string(content)
+	title := strings.TrimSuffix(path[strings.LastIndex(path, "/")+1:], ".md")
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(line, "# ") {
+			title = strings.TrimSpace(line[2:])
+			break
+		}
+	}
+	fix := SecurityFix{Title: title, IDs: advisoryIDs(text), Description: strings.TrimSpace(text), Path: path, URL: url}
+	if m := severityReg.FindStringSubmatch(text); m != nil {
+		fix.Severity = strings.ToLower(m[1])
+	}
+	if fix.URL == "" {
+		fix.URL = path
+	}
+	return fix
+}
+
+// advisoryURL CVE 及 GHSA 编号的公开地址
+func advisoryURL(id string) string {
+	if strings.HasPrefix(id, "GHSA-") {
+		return "https://github.com/advisories/" + id
+	}
+	return "https://nvd.nist.gov/vuln/detail/" + id
+}
+
+func writeSecurity(sb *strings.Builder, fixes []SecurityFix, titles Titles) {
+	if len(fixes) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("\n### %s\n\n", titles.get(SecurityTitle)))
+	for _, f := range fixes {
+		text := f.Title
+		var details []string
+		for _, id := range f.IDs {
+			details = append(details, fmt.Sprintf("[%s](%s)", id, advisoryURL(id)))
+		}
+		if f.Severity != "" {
+			details = append(details, f.Severity+" severity")
+		}
+		if len(details) > 0 {
+			text += " — " + strings.Join(details, ", ")
+		}
+		if f.Path != "" {
+			text += fmt.Sprintf(", see [%s](%s)", f.Path, f.URL)
+		}
+		writeItem(sb, f.Scope, text, Commit{Hash: f.Hash}.ShortHash())
+	}
+}
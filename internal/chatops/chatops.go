@@ -0,0 +1,301 @@
+package chatops
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/internal/webhook"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+const (
+	DefaultCommand = "/release"
+
+	HeaderGitHubEvent     = "X-GitHub-Event"
+	HeaderGitHubSignature = "X-Hub-Signature-256"
+	HeaderGitLabEvent     = "X-Gitlab-Event"
+	HeaderGitLabToken     = "X-Gitlab-Token"
+
+	maxBodySize = 1 << 20 // 网络钩子请求体的最大长度
+)
+
+// Config 评论命令触发发布，对应配置文件 chatops 节点
+type Config struct {
+	Enabled bool     `mapstructure:"enabled"` // 是否启用
+	Command string   `mapstructure:"command"` // 命令前缀，默认 /release
+	Users   []string `mapstructure:"users"`   // 允许触发发布的用户，为空时拒绝所有用户
+	Secret  string   `mapstructure:"secret"`  // GitHub 网络钩子签名密钥或 GitLab 网络钩子令牌
+}
+
+// Command 评论中的发布命令，如 /release minor、/release prerelease --preid beta、/release --dry-run
+type Command struct {
+	Release string `json:"release"`
+	PreId   string `json:"preid,omitempty"`
+	DryRun  bool   `json:"dryRun,omitempty"`
+	User    string `json:"user,omitempty"` // 发表评论的用户
+}
+
+// Event 议题或合并请求上的新评论
+type Event struct {
+	User        string `json:"user"`
+	Body        string `json:"body"`
+	Number      int    `json:"number"`
+	PullRequest bool   `json:"pullRequest"`
+}
+
+// ParseCommand 查找评论中以命令前缀开头的第一行并解析，没有命令时返回 false
+func ParseCommand(body, prefix string) (*Command, bool, error) {
+	if prefix == "" {
+		prefix = DefaultCommand
+	}
+	for _, line := range strings.Split(body, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != prefix {
+			continue
+		}
+		cmd := &Command{Release: semver.Patch.String()}
+		for i := 1; i < len(fields); i++ {
+			switch f := fields[i]; {
+			case f == "--dry-run" || f == "dry-run":
+				cmd.DryRun = true
+			case f == "--preid" && i+1 < len(fields):
+				i++
+				cmd.PreId = fields[i]
+			case strings.HasPrefix(f, "--preid="):
+				cmd.PreId = strings.TrimPrefix(f, "--preid=")
+			default:
+				changed, err := semver.ParseReleaseType(f)
+				if err != nil {
+					return nil, true, err
+				}
+				cmd.Release = changed.String()
+			}
+		}
+		if err := semver.PreReleaseIdentifier(cmd.PreId).Validate(); err != nil {
+			return nil, true, err
+		}
+		return cmd, true, nil
+	}
+	return nil, false, nil
+}
+
+// ParseGitHub 解析 GitHub issue_comment 事件，只处理新建的评论
+// https://docs.github.com/en/webhooks/webhook-events-and-payloads#issue_comment
+func ParseGitHub(data []byte) (*Event, bool, error) {
+	payload := struct {
+		Action string `json:"action"`
+		Issue  struct {
+			Number      int              `json:"number"`
+			PullRequest *json.RawMessage `json:"pull_request"`
+		} `json:"issue"`
+		Comment struct {
+			Body string `json:"body"`
+			User struct {
+				Login string `json:"login"`
+				Type  string `json:"type"`
+			} `json:"user"`
+		} `json:"comment"`
+	}{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, false, err
+	}
+	// 忽略机器人的评论，避免回复内容再次触发
+	if payload.Action != "created" || payload.Comment.User.Type == "Bot" {
+		return nil, false, nil
+	}
+	return &Event{
+		User:        payload.Comment.User.Login,
+		Body:        payload.Comment.Body,
+		Number:      payload.Issue.Number,
+		PullRequest: payload.Issue.PullRequest != nil,
+	}, true, nil
+}
+
+// ParseGitLab 解析 GitLab Note Hook 事件，只处理议题及合并请求上的评论
+// https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html#comment-events
+func ParseGitLab(data []byte) (*Event, bool, error) {
+	payload := struct {
+		User struct {
+			Username string `json:"username"`
+		} `json:"user"`
+		Attributes struct {
+			Note         string `json:"note"`
+			NoteableType string `json:"noteable_type"`
+		} `json:"object_attributes"`
+		MergeRequest struct {
+			IID int `json:"iid"`
+		} `json:"merge_request"`
+		Issue struct {
+			IID int `json:"iid"`
+		} `json:"issue"`
+	}{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, false, err
+	}
+	e := &Event{User: payload.User.Username, Body: payload.Attributes.Note}
+	switch payload.Attributes.NoteableType {
+	case "MergeRequest":
+		e.Number, e.PullRequest = payload.MergeRequest.IID, true
+	case "Issue":
+		e.Number = payload.Issue.IID
+	default:
+		return nil, false, nil
+	}
+	return e, true, nil
+}
+
+// Runner 执行发布命令
+type Runner func(cmd *Command) (*release.Result, error)
+
+// Bot 响应评论中的发布命令并回复发布结果
+type Bot struct {
+	cfg       Config
+	run       Runner
+	commenter provider.Commenter // 为空时不回复
+}
+
+func NewBot(cfg Config, run Runner, commenter provider.Commenter) *Bot {
+	if cfg.Command == "" {
+		cfg.Command = DefaultCommand
+	}
+	return &Bot{cfg: cfg, run: run, commenter: commenter}
+}
+
+// Allowed 用户是否允许触发发布
+func (b *Bot) Allowed(user string) bool {
+	for _, u := range b.cfg.Users {
+		if strings.EqualFold(u, user) {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrNotAllowed 评论者不在允许触发发布的用户中
+var ErrNotAllowed = errors.New("user is not allowed to release")
+
+// Handle 处理评论，不包含命令时返回 nil；执行结果回复在评论所在的议题或合并请求上
+func (b *Bot) Handle(e *Event) (*release.Result, error) {
+	cmd, ok, err := ParseCommand(e.Body, b.cfg.Command)
+	if !ok {
+		return nil, nil
+	}
+	if !b.Allowed(e.User) {
+		err = fmt.Errorf("%w: %s", ErrNotAllowed, e.User)
+	}
+	if cmd != nil {
+		cmd.User = e.User
+	}
+	var res *release.Result
+	if err == nil {
+		res, err = b.run(cmd)
+	}
+	if b.commenter != nil {
+		comment := &provider.Comment{Number: e.Number, PullRequest: e.PullRequest, Body: Reply(e, res, err)}
+		if replyErr := b.commenter.CreateComment(comment); replyErr != nil && err == nil {
+			err = fmt.Errorf("reply: %w", replyErr)
+		}
+	}
+	return res, err
+}
+
+// Reply 回复的评论内容
+func Reply(e *Event, res *release.Result, err error) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("@%s ", e.User))
+	switch {
+	case err != nil:
+		sb.WriteString(fmt.Sprintf("release failed: %s\n", log.Redact(err.Error())))
+	case res.Skipped:
+		sb.WriteString(fmt.Sprintf("no releasable commits, current version is `%s`\n", res.Version))
+	case res.DryRun:
+		sb.WriteString(fmt.Sprintf("dry run, next version would be `%s`\n", res.Tag))
+	default:
+		sb.WriteString(fmt.Sprintf("released `%s`", res.Tag))
+		if res.ReleaseURL != "" {
+			sb.WriteString(fmt.Sprintf(", see %s", res.ReleaseURL))
+		}
+		sb.WriteString("\n")
+	}
+	if err == nil && res.Changelog != nil && !res.Skipped {
+		sb.WriteString("\n" + res.Changelog.Markdown())
+	}
+	return sb.String()
+}
+
+// Handler 接收 GitHub issue_comment 及 GitLab Note Hook 网络钩子，校验签名或令牌后处理评论，未配置密钥时拒绝所有请求
+func (b *Bot) Handler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if b.cfg.Secret == "" {
+			http.Error(w, "chatops.secret is required to verify webhooks", http.StatusForbidden)
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize))
+		if err != nil {
+			http.Error(w, log.Redact(err.Error()), http.StatusBadRequest)
+			return
+		}
+		var (
+			e  *Event
+			ok bool
+		)
+		switch {
+		case r.Header.Get(HeaderGitHubEvent) != "":
+			if !webhook.Verify(b.cfg.Secret, data, r.Header.Get(HeaderGitHubSignature)) {
+				http.Error(w, "invalid signature", http.StatusUnauthorized)
+				return
+			}
+			if r.Header.Get(HeaderGitHubEvent) == "issue_comment" {
+				e, ok, err = ParseGitHub(data)
+			}
+		case r.Header.Get(HeaderGitLabEvent) != "":
+			if subtle.ConstantTimeCompare([]byte(r.Header.Get(HeaderGitLabToken)), []byte(b.cfg.Secret)) != 1 {
+				http.Error(w, "invalid token", http.StatusUnauthorized)
+				return
+			}
+			e, ok, err = ParseGitLab(data)
+		default:
+			http.Error(w, "unknown webhook", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			http.Error(w, log.Redact(err.Error()), http.StatusBadRequest)
+			return
+		}
+		if !ok {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		res, err := b.Handle(e)
+		status := http.StatusOK
+		switch {
+		case errors.Is(err, ErrNotAllowed):
+			status = http.StatusForbidden
+		case err != nil:
+			status = http.StatusUnprocessableEntity
+		case res == nil:
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		body := map[string]interface{}{"result": res}
+		if err != nil {
+			body = map[string]interface{}{"error": log.Redact(err.Error())}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+}
@@ -0,0 +1,46 @@
+package checkout
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/coffee377/autoctl/internal/progress"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// Config 未在工作区中执行时稀疏检出的内容，对应配置文件 checkout 节点
+type Config struct {
+	Paths []string `mapstructure:"paths"` // 除根目录文件外需要检出的目录，如 packages/app、charts，通常为版本清单文件所在的目录
+}
+
+// Checkout 临时检出的仓库
+type Checkout struct {
+	Dir  string // 工作区目录
+	temp string // 临时目录，清理时删除
+}
+
+// New 将远程仓库或裸仓库稀疏检出到临时目录，集中部署的发布服务无需预先准备工作区；
+// 克隆保留完整的提交历史及标签，推送标签时推送回 url
+func New(url, branch string, cfg Config, verbose bool) (*Checkout, error) {
+	temp, err := os.MkdirTemp("", "autoctl-checkout-*")
+	if err != nil {
+		return nil, err
+	}
+	c := &Checkout{Dir: filepath.Join(temp, "repo"), temp: temp}
+	log.Info("checkout %s into %s", url, c.Dir)
+	plus := &git.Plus{Cwd: temp, Verbose: verbose}
+	task := progress.Start("checkout %s", log.Redact(url))
+	err = plus.SparseClone(url, c.Dir, branch, cfg.Paths)
+	task.Stop()
+	if err != nil {
+		_ = c.Close()
+		return nil, err
+	}
+	return c, nil
+}
+
+// Close 删除临时检出的仓库
+func (c *Checkout) Close() error {
+	return os.RemoveAll(c.temp)
+}
@@ -0,0 +1,47 @@
+package checkout
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/testutil"
+)
+
+func TestNew(t *testing.T) {
+	root := t.TempDir()
+	src := testutil.NewRepo(t, filepath.Join(root, "src"))
+	for _, name := range []string{"package.json", "charts/app/Chart.yaml", "docs/guide.md"} {
+		if err := os.MkdirAll(filepath.Join(src, filepath.Dir(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(src, name), []byte("{}\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testutil.GitRun(t, src, "add", ".")
+	testutil.GitRun(t, src, "commit", "-q", "-m", "feat: init")
+	testutil.GitRun(t, src, "tag", "v1.0.0")
+	bare := filepath.Join(root, "bare.git")
+	testutil.GitRun(t, root, "clone", "-q", "--bare", src, bare)
+
+	c, err := New(bare, "", Config{Paths: []string{"charts"}}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range map[string]bool{"package.json": true, "charts/app/Chart.yaml": true, "docs/guide.md": false} {
+		if _, err := os.Stat(filepath.Join(c.Dir, name)); (err == nil) != want {
+			t.Errorf("expected %s checked out %v, but %v got", name, want, err)
+		}
+	}
+	if tags := testutil.GitRun(t, c.Dir, "tag", "-l"); strings.TrimSpace(tags) != "v1.0.0" {
+		t.Errorf("expected tags cloned, but %q got", tags)
+	}
+	if err = c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(c.Dir); !os.IsNotExist(err) {
+		t.Errorf("expected checkout removed, but %v got", err)
+	}
+}
@@ -0,0 +1,163 @@
+// Package codeowners 解析 GitHub、GitLab 及 Gitea 的 CODEOWNERS 文件，按路径查找负责人
+package codeowners
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// Locations CODEOWNERS 文件的查找位置，与代码托管平台的查找顺序一致
+var Locations = []string{"CODEOWNERS", ".github/CODEOWNERS", ".gitlab/CODEOWNERS", "docs/CODEOWNERS"}
+
+// Rule CODEOWNERS 中的一条规则
+type Rule struct {
+	Pattern string   // 路径模式，语法与 .gitignore 相近
+	Owners  []string // 负责人，如 @org/team、@user、user@example.com，为空时表示该路径没有负责人
+	Section string   // GitLab 的分节名称，没有分节时为空
+}
+
+// File 解析后的 CODEOWNERS 文件
+type File struct {
+	Rules []Rule
+}
+
+// GitLab 分节，如 [Docs]、^[Docs][2] @org/docs
+var sectionReg = regexp.MustCompile(`^\^?\[([^\]]+)\](?:\[\d+\])?\s*(.*)$`)
+
+// Parse 解析 CODEOWNERS 内容，忽略空行及注释，规则中以 \ 转义的空格及 # 视为路径的一部分；
+// GitLab 分节中没有负责人的规则使用分节的默认负责人
+func Parse(data []byte) *File {
+	f := &File{}
+	section, defaults := "", []string(nil)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if m := sectionReg.FindStringSubmatch(line); m != nil {
+			section, defaults = m[1], fields(m[2])
+			continue
+		}
+		list := fields(line)
+		rule := Rule{Pattern: list[0], Owners: list[1:], Section: section}
+		if len(rule.Owners) == 0 && section != "" {
+			rule.Owners = defaults
+		}
+		f.Rules = append(f.Rules, rule)
+	}
+	return f
+}
+
+// fields 按空白拆分，\ 转义的空格不拆分，# 开始的行尾注释被忽略
+func fields(s string) []string {
+	list := make([]string, 0)
+	var sb strings.Builder
+	escaped := false
+	for _, ch := range s {
+		switch {
+		case escaped:
+			sb.WriteRune(ch)
+			escaped = false
+		case ch == '\\':
+			escaped = true
+		case ch == '#':
+			if sb.Len() > 0 {
+				list = append(list, sb.String())
+			}
+			return list
+		case ch == ' ' || ch == '\t':
+			if sb.Len() > 0 {
+				list = append(list, sb.String())
+				sb.Reset()
+			}
+		default:
+			sb.WriteRune(ch)
+		}
+	}
+	if sb.Len() > 0 {
+		list = append(list, sb.String())
+	}
+	return list
+}
+
+// Owners 路径的负责人，同一分节内最后一条匹配的规则生效，GitLab 各分节的负责人合并
+func (f *File) Owners(file string) []string {
+	if f == nil {
+		return nil
+	}
+	matched := map[string]Rule{}
+	sections := make([]string, 0)
+	for _, rule := range f.Rules {
+		if !Match(rule.Pattern, file) {
+			continue
+		}
+		if _, ok := matched[rule.Section]; !ok {
+			sections = append(sections, rule.Section)
+		}
+		matched[rule.Section] = rule
+	}
+	owners := make([]string, 0)
+	seen := map[string]bool{}
+	for _, section := range sections {
+		for _, owner := range matched[section].Owners {
+			if !seen[owner] {
+				seen[owner] = true
+				owners = append(owners, owner)
+			}
+		}
+	}
+	return owners
+}
+
+// Match 路径是否匹配 CODEOWNERS 模式：以 / 开头或中间包含 / 的模式相对仓库根目录，否则匹配任意目录；
+// 以 / 结尾的模式只匹配目录，最后一级不含通配符的模式同时匹配目录下的全部文件，如 docs/* 只匹配 docs 下一级的文件
+func Match(pattern, file string) bool {
+	dir := strings.HasSuffix(pattern, "/")
+	trimmed := strings.Trim(pattern, "/")
+	if trimmed == "" {
+		return false
+	}
+	segments := strings.Split(trimmed, "/")
+	if !strings.HasPrefix(pattern, "/") && len(segments) == 1 {
+		segments = append([]string{"**"}, segments...)
+	}
+	parts := strings.Split(strings.TrimPrefix(file, "/"), "/")
+	recursive := !strings.Contains(segments[len(segments)-1], "*")
+	for n := len(parts); n >= 1; n-- {
+		if n < len(parts) && !recursive {
+			break
+		}
+		if n == len(parts) && dir {
+			continue
+		}
+		if matchSegments(segments, parts[:n]) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, parts []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(parts); i++ {
+				if matchSegments(pattern[1:], parts[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(parts) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+			return false
+		}
+		pattern, parts = pattern[1:], parts[1:]
+	}
+	return len(parts) == 0
+}
@@ -0,0 +1,52 @@
+package codeowners
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatch(t *testing.T) {
+	for _, c := range []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"*", "cmd/main.go", true},
+		{"*.js", "web/app/index.js", true},
+		{"/docs/", "docs/guide/intro.md", true},
+		{"/docs/", "api/docs/intro.md", false},
+		{"apps/", "web/apps/index.js", true},
+		{"docs/*", "docs/intro.md", true},
+		{"docs/*", "docs/guide/intro.md", false},
+		{"/build/logs/", "build/logs/out.log", true},
+		{"**/logs", "deep/a/logs/out.log", true},
+		{"src/app", "src/app/main.go", true},
+		{"src/app", "lib/src/app/main.go", false},
+	} {
+		if got := Match(c.pattern, c.file); got != c.want {
+			t.Errorf("expected %s matching %s %v, but %v got", c.pattern, c.file, c.want, got)
+		}
+	}
+}
+
+func TestFile_Owners(t *testing.T) {
+	f := Parse([]byte(`# default owners
+*       @org/core
+/payments/ @org/payments @alice # inline comment
+/payments/legacy/
+docs/My\ Guide.md docs@example.com
+
+[Database][2] @org/dba
+migrations/
+`))
+	for file, want := range map[string]string{
+		"cmd/main.go":              "@org/core",
+		"payments/api.go":          "@org/payments @alice",
+		"payments/legacy/old.go":   "",
+		"docs/My Guide.md":         "docs@example.com",
+		"migrations/0001_init.sql": "@org/core @org/dba",
+	} {
+		if got := strings.Join(f.Owners(file), " "); got != want {
+			t.Errorf("expected %s owned by %q, but %q got", file, want, got)
+		}
+	}
+}
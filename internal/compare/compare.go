@@ -0,0 +1,159 @@
+package compare
+
+import (
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/workspace"
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+// Contributor 贡献者及其提交数量
+type Contributor struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Commits int    `json:"commits"`
+}
+
+// Package 两个引用之间有变更的软件包
+type Package struct {
+	Name  string `json:"name"`
+	Path  string `json:"path"`
+	Files int    `json:"files"` // 变更的文件数量
+}
+
+// Manifest 清单文件中的版本号变化
+type Manifest struct {
+	File string `json:"file"`
+	Name string `json:"name,omitempty"`
+	From string `json:"from,omitempty"` // 为空表示 from 中不存在
+	To   string `json:"to,omitempty"`   // 为空表示 to 中不存在
+}
+
+// Report 两个引用之间的对比
+type Report struct {
+	From         string             `json:"from"`
+	To           string             `json:"to"`
+	Commits      []changelog.Commit `json:"commits"`
+	Contributors []Contributor      `json:"contributors"`
+	Packages     []Package          `json:"packages,omitempty"`
+	Breaking     []changelog.Commit `json:"breaking,omitempty"`
+	Manifests    []Manifest         `json:"manifests,omitempty"`
+	Files        int                `json:"files"` // 变更的文件总数
+}
+
+// Compare 对比两个引用，dir 为工作区根目录，软件包按当前工作区结构识别
+func Compare(plus *git.Plus, dir, from, to string) (*Report, error) {
+	records, err := changelog.Collect(plus, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{From: from, To: to, Commits: make([]changelog.Commit, 0, len(records))}
+	contributors := map[string]*Contributor{}
+	for _, r := range records {
+		c := changelog.NewCommit(r)
+		report.Commits = append(report.Commits, c)
+		if c.Breaking {
+			report.Breaking = append(report.Breaking, c)
+		}
+		key := strings.ToLower(r.Email)
+		if contributors[key] == nil {
+			contributors[key] = &Contributor{Name: r.Author, Email: r.Email}
+		}
+		contributors[key].Commits++
+	}
+	for _, c := range contributors {
+		report.Contributors = append(report.Contributors, *c)
+	}
+	sort.Slice(report.Contributors, func(i, j int) bool {
+		a, b := report.Contributors[i], report.Contributors[j]
+		if a.Commits != b.Commits {
+			return a.Commits > b.Commits
+		}
+		return a.Name < b.Name
+	})
+
+	files, err := plus.ChangedFiles(from, to)
+	if err != nil {
+		return nil, err
+	}
+	report.Files = len(files)
+	packages, err := workspace.Discover(dir)
+	if err != nil {
+		return nil, err
+	}
+	report.Packages = changedPackages(packages, files)
+	if report.Manifests, err = manifests(plus, packages, from, to); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// changedPackages 按最长路径前缀将变更文件归属到软件包
+func changedPackages(packages []workspace.Package, files []string) []Package {
+	counts := map[string]int{}
+	for _, file := range files {
+		owner := ""
+		for _, p := range packages {
+			if (p.Path == "." || file == p.Path || strings.HasPrefix(file, p.Path+"/")) && len(p.Path) >= len(owner) {
+				owner = p.Path
+			}
+		}
+		if owner != "" {
+			counts[owner]++
+		}
+	}
+	list := make([]Package, 0, len(counts))
+	for _, p := range packages {
+		if n := counts[p.Path]; n > 0 {
+			list = append(list, Package{Name: p.Name, Path: p.Path, Files: n})
+		}
+	}
+	return list
+}
+
+// manifests 对比根目录及各软件包 package.json 中的版本号
+func manifests(plus *git.Plus, packages []workspace.Package, from, to string) ([]Manifest, error) {
+	files := []string{"package.json"}
+	for _, p := range packages {
+		if p.Type == workspace.NPM && p.Path != "." {
+			files = append(files, path.Join(p.Path, "package.json"))
+		}
+	}
+	list := make([]Manifest, 0)
+	for _, file := range files {
+		name, a, err := manifestVersion(plus, from, file)
+		if err != nil {
+			return nil, err
+		}
+		toName, b, err := manifestVersion(plus, to, file)
+		if err != nil {
+			return nil, err
+		}
+		if toName != "" {
+			name = toName
+		}
+		if a != b {
+			list = append(list, Manifest{File: file, Name: name, From: a, To: b})
+		}
+	}
+	return list, nil
+}
+
+func manifestVersion(plus *git.Plus, ref, file string) (string, string, error) {
+	data, err := plus.Show(ref, file)
+	if err != nil || data == nil {
+		return "", "", err
+	}
+	v := struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}{}
+	if err = json.Unmarshal(data, &v); err != nil {
+		return "", "", err
+	}
+	return v.Name, v.Version, nil
+}
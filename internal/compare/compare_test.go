@@ -0,0 +1,66 @@
+package compare
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/testutil"
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+func TestCompare(t *testing.T) {
+	testutil.GitIdentity(t)
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		testutil.GitRun(t, dir, args...)
+	}
+	commit := func(author, message string, files map[string]string) {
+		t.Helper()
+		for name, content := range files {
+			file := filepath.Join(dir, name)
+			if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		run("add", "-A")
+		run("commit", "-q", "--author", author, "-m", message)
+	}
+	run("init", "-q", "-b", "main")
+	commit("Alice <alice@example.com>", "feat: init", map[string]string{
+		"package.json":             `{"name": "root", "version": "1.2.0", "workspaces": ["packages/*"]}`,
+		"packages/ui/package.json": `{"name": "@teamwork/ui", "version": "0.1.0"}`,
+		"packages/api/go.mod":      "module example.com/api\n",
+	})
+	run("tag", "v1.2.0")
+	commit("Bob <bob@example.com>", "feat(ui)!: new theme\n\nBREAKING CHANGE: theme tokens renamed", map[string]string{
+		"packages/ui/package.json": `{"name": "@teamwork/ui", "version": "1.0.0"}`,
+		"packages/ui/theme.css":    "body {}",
+	})
+	commit("Alice <alice@example.com>", "fix: readme", map[string]string{"README.md": "# root"})
+
+	report, err := Compare(&git.Plus{Cwd: dir}, dir, "v1.2.0", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Commits) != 2 || report.Files != 3 {
+		t.Errorf("expected 2 commits and 3 files, but %d and %d got", len(report.Commits), report.Files)
+	}
+	if expected := []Contributor{{"Alice", "alice@example.com", 1}, {"Bob", "bob@example.com", 1}}; !reflect.DeepEqual(report.Contributors, expected) {
+		t.Errorf("expected %v, but %v got", expected, report.Contributors)
+	}
+	if len(report.Breaking) != 1 || report.Breaking[0].BreakingNote != "theme tokens renamed" {
+		t.Errorf("expected breaking change, but %v got", report.Breaking)
+	}
+	if expected := []Package{{Name: "@teamwork/ui", Path: "packages/ui", Files: 2}}; !reflect.DeepEqual(report.Packages, expected) {
+		t.Errorf("expected %v, but %v got", expected, report.Packages)
+	}
+	if expected := []Manifest{{File: "packages/ui/package.json", Name: "@teamwork/ui", From: "0.1.0", To: "1.0.0"}}; !reflect.DeepEqual(report.Manifests, expected) {
+		t.Errorf("expected %v, but %v got", expected, report.Manifests)
+	}
+}
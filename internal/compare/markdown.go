@@ -0,0 +1,55 @@
+package compare
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Markdown 渲染为 Markdown 格式
+func (r *Report) Markdown() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("## %s...%s\n\n", r.From, r.To))
+	sb.WriteString(fmt.Sprintf("%d commits, %d contributors, %d files changed\n", len(r.Commits), len(r.Contributors), r.Files))
+	if len(r.Breaking) > 0 {
+		sb.WriteString("\n### ⚠ Breaking Changes\n\n")
+		for _, c := range r.Breaking {
+			note := c.BreakingNote
+			if note == "" {
+				note = c.Subject
+			}
+			sb.WriteString(fmt.Sprintf("* %s (%s)\n", note, c.ShortHash()))
+		}
+	}
+	if len(r.Packages) > 0 {
+		sb.WriteString("\n### Packages\n\n| Package | Path | Files |\n| --- | --- | --- |\n")
+		for _, p := range r.Packages {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %d |\n", p.Name, p.Path, p.Files))
+		}
+	}
+	if len(r.Manifests) > 0 {
+		sb.WriteString("\n### Versions\n\n| Manifest | Name | From | To |\n| --- | --- | --- | --- |\n")
+		for _, m := range r.Manifests {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", m.File, m.Name, orNone(m.From), orNone(m.To)))
+		}
+	}
+	if len(r.Contributors) > 0 {
+		sb.WriteString("\n### Contributors\n\n| Name | Email | Commits |\n| --- | --- | --- |\n")
+		for _, c := range r.Contributors {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %d |\n", c.Name, c.Email, c.Commits))
+		}
+	}
+	if len(r.Commits) > 0 {
+		sb.WriteString("\n### Commits\n\n| Commit | Type | Scope | Subject | Author |\n| --- | --- | --- | --- | --- |\n")
+		for _, c := range r.Commits {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s |\n", c.ShortHash(), c.Type, c.Scope, strings.ReplaceAll(c.Subject, "|", "\\|"), c.Author))
+		}
+	}
+	return sb.String()
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,186 @@
+package convention
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/i18n"
+)
+
+// Type 提交类型
+type Type struct {
+	Name        string `mapstructure:"name"`
+	Description string `mapstructure:"description"`
+}
+
+// DefaultTypes 约定式提交常用类型 https://www.conventionalcommits.org
+var DefaultTypes = []Type{
+	{"feat", "A new feature"},
+	{"fix", "A bug fix"},
+	{"docs", "Documentation only changes"},
+	{"style", "Changes that do not affect the meaning of the code"},
+	{"refactor", "A code change that neither fixes a bug nor adds a feature"},
+	{"perf", "A code change that improves performance"},
+	{"test", "Adding missing tests or correcting existing tests"},
+	{"build", "Changes that affect the build system or external dependencies"},
+	{"ci", "Changes to CI configuration files and scripts"},
+	{"chore", "Other changes that don't modify src or test files"},
+	{"revert", "Reverts a previous commit"},
+}
+
+const DefaultMaxHeaderLength = 100
+
+// Rules 提交信息规范，对应配置文件 commit 节点，提交向导与 lint 命令共用
+type Rules struct {
+	Types           []Type   `mapstructure:"types"`           // 允许的类型，默认 DefaultTypes
+	Scopes          []string `mapstructure:"scopes"`          // 允许的范围，与工作区软件包范围合并，均为空时不限制
+	SkipWorkspace   bool     `mapstructure:"skipWorkspace"`   // 不从工作区软件包推导范围
+	RequireScope    bool     `mapstructure:"requireScope"`    // 必须填写范围
+	MaxHeaderLength int      `mapstructure:"maxHeaderLength"` // 标题最大长度，默认 100
+	Gitmoji         string   `mapstructure:"gitmoji"`         // 提交向导在描述前插入类型对应的 gitmoji code | emoji，为空时不插入
+}
+
+// WithDefaults 补全默认值
+func (r Rules) WithDefaults() Rules {
+	if len(r.Types) == 0 {
+		r.Types = DefaultTypes
+	}
+	if r.MaxHeaderLength <= 0 {
+		r.MaxHeaderLength = DefaultMaxHeaderLength
+	}
+	return r
+}
+
+// TypeNames 允许的类型名称
+func (r Rules) TypeNames() []string {
+	names := make([]string, 0, len(r.Types))
+	for _, t := range r.WithDefaults().Types {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+var headerReg = regexp.MustCompile(`^(\w+)(?:\(([^()]*)\))?(!)?: (.*)$`)
+
+// ignoredReg 不做检查的提交：合并、git revert 生成的提交及 fixup/squash
+var ignoredReg = regexp.MustCompile(`^(Merge |Revert "|fixup! |squash! |amend! )`)
+
+// Validate 检查提交信息，返回所有不符合规范的问题
+func (r Rules) Validate(message string) []string {
+	r = r.WithDefaults()
+	message = stripComments(message)
+	header := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+	if ignoredReg.MatchString(header) {
+		return nil
+	}
+	if header == "" {
+		return []string{i18n.T("commit message is empty")}
+	}
+	problems := make([]string, 0)
+	if n := len([]rune(header)); n > r.MaxHeaderLength {
+		problems = append(problems, i18n.T("header is %d characters, must not be longer than %d", n, r.MaxHeaderLength))
+	}
+	m := headerReg.FindStringSubmatch(header)
+	if m == nil {
+		return append(problems, i18n.T("header must be in the format <type>[(scope)][!]: <subject>"))
+	}
+	if !contains(r.TypeNames(), m[1]) {
+		problems = append(problems, i18n.T("type %q is not allowed, allowed types: %s", m[1], strings.Join(r.TypeNames(), ", ")))
+	}
+	switch {
+	case m[2] == "" && r.RequireScope:
+		problems = append(problems, i18n.T("scope is required"))
+	case m[2] != "" && len(r.Scopes) > 0:
+		for _, scope := range strings.Split(m[2], ",") {
+			if !contains(r.Scopes, strings.TrimSpace(scope)) {
+				problems = append(problems, i18n.T("scope %q is not allowed, allowed scopes: %s", scope, strings.Join(r.Scopes, ", ")))
+			}
+		}
+	}
+	if strings.TrimSpace(m[4]) == "" {
+		problems = append(problems, i18n.T("subject must not be empty"))
+	}
+	if lines := strings.SplitN(message, "\n", 3); len(lines) > 1 && strings.TrimSpace(lines[1]) != "" {
+		problems = append(problems, i18n.T("header must be followed by a blank line"))
+	}
+	return problems
+}
+
+// stripComments 去除 git 编辑器模板中 # 开头的注释行
+func stripComments(message string) string {
+	lines := strings.Split(message, "\n")
+	kept := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		if !strings.HasPrefix(line, "#") {
+			kept = append(kept, line)
+		}
+	}
+	return strings.TrimSpace(strings.Join(kept, "\n"))
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// Draft 待提交的约定式提交信息
+type Draft struct {
+	Type         string
+	Scope        string
+	Subject      string
+	Gitmoji      string // 描述前的 gitmoji，如 ✨
+	Body         string
+	Breaking     bool
+	BreakingNote string   // BREAKING CHANGE 脚注内容
+	Closes       []string // 关闭的问题，如 #12
+	Refs         []string // 关联的问题
+}
+
+// String 按约定式提交格式输出
+func (d Draft) String() string {
+	var sb strings.Builder
+	sb.WriteString(d.Type)
+	if d.Scope != "" {
+		sb.WriteString("(" + d.Scope + ")")
+	}
+	if d.Breaking {
+		sb.WriteString("!")
+	}
+	sb.WriteString(": ")
+	if d.Gitmoji != "" {
+		sb.WriteString(d.Gitmoji + " ")
+	}
+	sb.WriteString(strings.TrimSpace(d.Subject))
+	if body := strings.TrimSpace(d.Body); body != "" {
+		sb.WriteString("\n\n" + body)
+	}
+	footers := make([]string, 0)
+	if d.Breaking && strings.TrimSpace(d.BreakingNote) != "" {
+		footers = append(footers, "BREAKING CHANGE: "+strings.TrimSpace(d.BreakingNote))
+	}
+	for _, issue := range d.Closes {
+		footers = append(footers, "Closes "+issueRef(issue))
+	}
+	for _, issue := range d.Refs {
+		footers = append(footers, "Refs "+issueRef(issue))
+	}
+	if len(footers) > 0 {
+		sb.WriteString("\n\n" + strings.Join(footers, "\n"))
+	}
+	return sb.String()
+}
+
+func issueRef(issue string) string {
+	issue = strings.TrimSpace(issue)
+	if issue != "" && issue[0] >= '0' && issue[0] <= '9' {
+		return "#" + issue
+	}
+	return issue
+}
@@ -0,0 +1,99 @@
+package convention
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/testutil"
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+func TestRules_Validate(t *testing.T) {
+	rules := Rules{Scopes: []string{"core", "cli"}}
+	tests := []struct {
+		message  string
+		problems int
+	}{
+		{"feat(core): add plan", 0},
+		{"feat(core,cli)!: drop v1 api\n\nBREAKING CHANGE: removed", 0},
+		{"fix: handle empty tags\n# Please enter the commit message", 0},
+		{"Merge branch 'main' into dev", 0},
+		{"fixup! feat: add plan", 0},
+		{"update readme", 1},
+		{"feature: add plan", 1},
+		{"feat(web): add plan", 1},
+		{"feat: ", 1},
+		{"feat: add plan\nbody", 1},
+		{"", 1},
+	}
+	for _, tt := range tests {
+		if problems := rules.Validate(tt.message); len(problems) != tt.problems {
+			t.Errorf("Validate(%q) expected %d problems, but %v got", tt.message, tt.problems, problems)
+		}
+	}
+	if problems := (Rules{RequireScope: true, MaxHeaderLength: 10}).Validate("feat: add plan"); len(problems) != 2 {
+		t.Errorf("expected scope required and header too long, but %v got", problems)
+	}
+}
+
+func TestDraft_String(t *testing.T) {
+	draft := Draft{
+		Type: "feat", Scope: "cli", Subject: "add commit command", Body: "compose commits interactively",
+		Breaking: true, BreakingNote: "lint exits non-zero on violations", Closes: []string{"12", "owner/repo#3"},
+	}
+	expected := "feat(cli)!: add commit command\n\ncompose commits interactively\n\n" +
+		"BREAKING CHANGE: lint exits non-zero on violations\nCloses #12\nCloses owner/repo#3"
+	if actual := draft.String(); actual != expected {
+		t.Errorf("\nExpected: \n%s\nActual: \n%s\n", expected, actual)
+	}
+	if problems := (Rules{}).Validate(draft.String()); len(problems) > 0 {
+		t.Errorf("expected draft valid, but %v got", problems)
+	}
+}
+
+func TestHistoryScopes(t *testing.T) {
+	dir := testutil.NewRepo(t, t.TempDir())
+	messages := []string{"feat(cli): a", "fix(core): b", "feat(cli,docs): c", "chore: d", "wip"}
+	for _, m := range messages {
+		testutil.GitRun(t, dir, "commit", "-q", "--allow-empty", "-m", m)
+	}
+	scopes, err := HistoryScopes(&git.Plus{Cwd: dir}, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"cli", "core", "docs"}; !reflect.DeepEqual(scopes, expected) {
+		t.Errorf("expected %v, but %v got", expected, scopes)
+	}
+}
+
+func TestRules_WithWorkspace(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"package.json":              `{"workspaces": ["packages/*"]}`,
+		"packages/ui/package.json":  `{"name": "@teamwork/ui"}`,
+		"packages/api/package.json": `{"name": "api"}`,
+	} {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	rules, err := Rules{Scopes: []string{"release", "ui"}}.WithWorkspace(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"release", "ui", "api"}; !reflect.DeepEqual(rules.Scopes, expected) {
+		t.Errorf("expected %v, but %v got", expected, rules.Scopes)
+	}
+	if problems := rules.Validate("feat(web): add page"); len(problems) != 1 {
+		t.Errorf("expected scope web not allowed, but %v got", problems)
+	}
+	if rules, _ = (Rules{SkipWorkspace: true}).WithWorkspace(dir); len(rules.Scopes) != 0 {
+		t.Errorf("expected no scopes derived, but %v got", rules.Scopes)
+	}
+}
@@ -0,0 +1,117 @@
+package convention
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Gitmoji 与提交类型对应的 gitmoji https://gitmoji.dev
+type Gitmoji struct {
+	Code     string // 短代码，如 :sparkles:
+	Emoji    string // emoji，如 ✨
+	Type     string // 对应的约定式提交类型
+	Breaking bool   // 是否表示破坏性变更
+}
+
+// Gitmojis 参与版本推断的 gitmoji，同一类型的第一项为提交向导插入的 gitmoji
+var Gitmojis = []Gitmoji{
+	{":sparkles:", "✨", "feat", false},
+	{":boom:", "💥", "feat", true},
+	{":bug:", "🐛", "fix", false},
+	{":ambulance:", "🚑", "fix", false},
+	{":lock:", "🔒", "fix", false},
+	{":zap:", "⚡", "perf", false},
+	{":rewind:", "⏪", "revert", false},
+	{":memo:", "📝", "docs", false},
+	{":recycle:", "♻", "refactor", false},
+	{":art:", "🎨", "style", false},
+	{":white_check_mark:", "✅", "test", false},
+	{":construction_worker:", "👷", "ci", false},
+	{":package:", "📦", "build", false},
+	{":arrow_up:", "⬆", "build", false},
+	{":wrench:", "🔧", "chore", false},
+}
+
+// 提交向导插入 gitmoji 的写法
+const (
+	GitmojiCode  = "code"  // 短代码，如 feat: :sparkles: add export
+	GitmojiEmoji = "emoji" // emoji，如 feat: ✨ add export
+)
+
+// 变更日志中 gitmoji 的处理方式
+const (
+	EmojiStrip    = "strip"    // 去除描述开头的 gitmoji
+	EmojiPreserve = "preserve" // 保留 gitmoji，只有 gitmoji 没有类型的提交以 emoji 开头
+)
+
+// gitmojiTypes gitmoji 内置规范的类型映射，短代码及 emoji 均可匹配
+func gitmojiTypes() map[string]string {
+	types := make(map[string]string, len(Gitmojis)*2)
+	for _, g := range Gitmojis {
+		t := g.Type
+		if g.Breaking {
+			t += "!"
+		}
+		types[g.Code], types[g.Emoji] = t, t
+	}
+	return types
+}
+
+// LookupGitmoji 按短代码或 emoji 查找 gitmoji，忽略 emoji 的变体选择符
+func LookupGitmoji(s string) (Gitmoji, bool) {
+	key := normalizeKey(s)
+	for _, g := range Gitmojis {
+		if key == g.Code || key == g.Emoji {
+			return g, true
+		}
+	}
+	return Gitmoji{}, false
+}
+
+// GitmojiFor 提交类型对应的 gitmoji，破坏性变更为 💥
+func GitmojiFor(typ string, breaking bool) (Gitmoji, bool) {
+	for _, g := range Gitmojis {
+		if g.Breaking == breaking && (breaking || g.Type == typ) {
+			return g, true
+		}
+	}
+	return Gitmoji{}, false
+}
+
+// StripGitmoji 去除开头的 gitmoji 短代码或 emoji，没有时原样返回
+func StripGitmoji(s string) (string, bool) {
+	s = strings.TrimSpace(s)
+	head := s
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		head = s[:i]
+	}
+	if _, ok := LookupGitmoji(head); !ok {
+		return s, false
+	}
+	return strings.TrimSpace(s[len(head):]), true
+}
+
+// ValidateGitmoji 检查提交向导插入 gitmoji 的写法
+func ValidateGitmoji(style string) error {
+	switch style {
+	case "", GitmojiCode, GitmojiEmoji:
+		return nil
+	default:
+		return fmt.Errorf("invalid commit gitmoji %q, valid values are %s|%s", style, GitmojiCode, GitmojiEmoji)
+	}
+}
+
+// GitmojiPrefix 提交向导在描述前插入的 gitmoji，未开启或类型没有对应的 gitmoji 时为空
+func (r Rules) GitmojiPrefix(typ string, breaking bool) string {
+	g, ok := GitmojiFor(typ, breaking)
+	switch {
+	case !ok:
+		return ""
+	case r.Gitmoji == GitmojiCode:
+		return g.Code
+	case r.Gitmoji == GitmojiEmoji:
+		return g.Emoji
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,170 @@
+package convention
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+)
+
+// 内置的提交规范
+const (
+	PresetGitmoji = "gitmoji" // 以 gitmoji 开头，如 :sparkles: add export、✨ add export、:bug: fix crash
+	PresetBracket = "bracket" // 以方括号中的类型开头，如 [FEATURE] add export、[BUGFIX] fix crash
+)
+
+// PresetNames 所有内置规范的名称
+func PresetNames() []string {
+	return []string{PresetGitmoji, PresetBracket}
+}
+
+// presets 内置规范的标题规则
+var presets = map[string]Pattern{
+	PresetGitmoji: {
+		Regex: `^(?P<type>:\w+:|\S+?)\s*(?:\((?P<scope>[^()]*)\))?(?P<breaking>!)?:?\s+(?P<subject>.+)$`,
+		Types: gitmojiTypes(),
+	},
+	PresetBracket: {
+		Regex: `^\[(?P<type>[\w-]+)\]\s*(?:\((?P<scope>[^()]*)\))?(?P<breaking>!)?:?\s*(?P<subject>.+)$`,
+		Types: map[string]string{
+			"feature": "feat", "feat": "feat",
+			"bugfix": "fix", "bug": "fix", "fix": "fix", "hotfix": "fix",
+			"breaking": "feat!",
+			"perf":     "perf", "performance": "perf",
+			"revert": "revert",
+			"docs":   "docs", "doc": "docs",
+			"refactor": "refactor",
+			"test":     "test", "tests": "test",
+			"build": "build", "ci": "ci",
+			"chore": "chore", "task": "chore",
+		},
+	},
+}
+
+// Pattern 自定义的提交标题规则，正则表达式的命名分组 type、scope、breaking、subject
+// 分别对应类型、范围、破坏性变更标记及描述，缺少 type 分组时类型取 Types 中键为空字符串的值
+type Pattern struct {
+	Regex string            `mapstructure:"regex"` // 标题匹配规则
+	Types map[string]string `mapstructure:"types"` // 类型映射（键不区分大小写），如 FEATURE => feat，值以 ! 结尾时视为破坏性变更；不为空时只接受映射中的类型
+}
+
+// Grammar 非约定式提交的解析规则，对应配置文件 release.convention 节点，
+// 匹配的提交转换为约定式提交后参与版本推断及变更日志生成，都不匹配时按约定式提交解析
+type Grammar struct {
+	Presets  []string  `mapstructure:"presets"`  // 内置规范 gitmoji | bracket，在自定义规则之后匹配
+	Patterns []Pattern `mapstructure:"patterns"` // 自定义规则，按顺序匹配
+	Emoji    string    `mapstructure:"emoji"`    // 变更日志中 gitmoji 的处理方式 strip | preserve，为空时保持提交原文
+}
+
+// Validate 检查内置规范名称及正则表达式
+func (g Grammar) Validate() error {
+	_, err := g.Compile()
+	return err
+}
+
+// Compile 编译全部规则，没有任何规则且不处理 gitmoji 时返回 nil
+func (g Grammar) Compile() (*Parser, error) {
+	switch g.Emoji {
+	case "", EmojiStrip, EmojiPreserve:
+	default:
+		return nil, fmt.Errorf("invalid convention emoji %q, valid values are %s|%s", g.Emoji, EmojiStrip, EmojiPreserve)
+	}
+	patterns := append([]Pattern{}, g.Patterns...)
+	for _, name := range g.Presets {
+		p, ok := presets[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid convention preset %q, valid values are %s", name, strings.Join(PresetNames(), "|"))
+		}
+		patterns = append(patterns, p)
+	}
+	if len(patterns) == 0 && g.Emoji == "" {
+		return nil, nil
+	}
+	parser := &Parser{rules: make([]rule, 0, len(patterns)), emoji: g.Emoji}
+	for _, p := range patterns {
+		reg, err := regexp.Compile(p.Regex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid convention pattern %q: %w", p.Regex, err)
+		}
+		if reg.SubexpIndex("subject") < 0 {
+			return nil, fmt.Errorf("convention pattern %q must capture the subject", p.Regex)
+		}
+		types := make(map[string]string, len(p.Types))
+		for k, v := range p.Types {
+			types[normalizeKey(k)] = v
+		}
+		parser.rules = append(parser.rules, rule{reg: reg, types: types})
+	}
+	return parser, nil
+}
+
+type rule struct {
+	reg   *regexp.Regexp
+	types map[string]string
+}
+
+// Parser 编译后的解析规则
+type Parser struct {
+	rules []rule
+	emoji string
+}
+
+// normalizeKey 类型映射的键不区分大小写，并去除 emoji 的变体选择符
+func normalizeKey(s string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(s), "\ufe0f", ""))
+}
+
+// Parse 按规则解析提交标题，都不匹配时 ok 为 false
+func (p *Parser) Parse(title string) (header *commit.MessageHeader, ok bool) {
+	if p == nil {
+		return nil, false
+	}
+	title = strings.TrimSpace(title)
+	for _, r := range p.rules {
+		match := r.reg.FindStringSubmatch(title)
+		if match == nil {
+			continue
+		}
+		group := func(name string) string {
+			if i := r.reg.SubexpIndex(name); i >= 0 {
+				return strings.TrimSpace(match[i])
+			}
+			return ""
+		}
+		t := group("type")
+		if len(r.types) > 0 {
+			mapped, known := r.types[normalizeKey(t)]
+			if !known {
+				continue
+			}
+			t = mapped
+		}
+		subject := group("subject")
+		if g, ok := LookupGitmoji(group("type")); ok && p.emoji == EmojiPreserve {
+			subject = g.Emoji + " " + subject
+		}
+		header = commit.NewCommitMessageHeader(strings.TrimSuffix(t, "!"), subject)
+		header.Scope = group("scope")
+		header.Broken = strings.HasSuffix(t, "!") || group("breaking") != ""
+		return header, true
+	}
+	return nil, false
+}
+
+// Normalize 按规则将提交标题转换为约定式提交的头部，匹配时返回 true；
+// emoji 为 strip 时同时去除约定式提交描述开头的 gitmoji，如 feat: ✨ add export
+func (p *Parser) Normalize(record *commit.CommitRecord) bool {
+	if p == nil || record.Message == nil {
+		return false
+	}
+	title := strings.SplitN(strings.TrimSpace(record.RawMessage), "\n", 2)[0]
+	header, ok := p.Parse(title)
+	if ok {
+		record.Message.Header = header
+	}
+	if h := record.Message.Header; h != nil && p.emoji == EmojiStrip {
+		h.Description, _ = StripGitmoji(h.Description)
+	}
+	return ok
+}
@@ -0,0 +1,91 @@
+package convention
+
+import (
+	"testing"
+
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+)
+
+func TestGrammar_Parse(t *testing.T) {
+	parser, err := Grammar{
+		Presets:  []string{PresetGitmoji, PresetBracket},
+		Patterns: []Pattern{{Regex: `^(?P<type>ADD|FIX)-\d+ (?P<subject>.+)$`, Types: map[string]string{"add": "feat", "fix": "fix"}}},
+	}.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		title    string
+		typ      string
+		scope    string
+		breaking bool
+		subject  string
+		ok       bool
+	}{
+		{":sparkles: add export", "feat", "", false, "add export", true},
+		{"🚑️ (api) fix crash", "fix", "api", false, "fix crash", true},
+		{"💥 drop csv", "feat", "", true, "drop csv", true},
+		{"[FEATURE] add export", "feat", "", false, "add export", true},
+		{"[BugFix](cli) keep newline", "fix", "cli", false, "keep newline", true},
+		{"ADD-42 support yaml", "feat", "", false, "support yaml", true},
+		{"feat: conventional", "", "", false, "", false},
+		{"[WIP] unknown type", "", "", false, "", false},
+	}
+	for _, tt := range tests {
+		h, ok := parser.Parse(tt.title)
+		if ok != tt.ok {
+			t.Errorf("Parse(%q) ok = %v, want %v", tt.title, ok, tt.ok)
+			continue
+		}
+		if ok && (h.Type != tt.typ || h.Scope != tt.scope || h.Broken != tt.breaking || h.Description != tt.subject) {
+			t.Errorf("Parse(%q) = %+v", tt.title, h)
+		}
+	}
+	for _, g := range []Grammar{{Presets: []string{"svn"}}, {Patterns: []Pattern{{Regex: `^(?P<type>\w+)`}}}} {
+		if err = g.Validate(); err == nil {
+			t.Errorf("expected %+v invalid, got nil", g)
+		}
+	}
+	if parser, _ = (Grammar{}).Compile(); parser != nil {
+		t.Error("expected nil parser without rules")
+	}
+}
+
+func TestGrammar_Emoji(t *testing.T) {
+	preserve, err := Grammar{Presets: []string{PresetGitmoji}, Emoji: EmojiPreserve}.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if h, _ := preserve.Parse(":sparkles: add export"); h.Description != "✨ add export" {
+		t.Errorf("expected gitmoji preserved as emoji, but %q got", h.Description)
+	}
+	strip, err := Grammar{Emoji: EmojiStrip}.Compile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw := "feat(cli): 🐛 keep newline"
+	record := &commit.CommitRecord{RawMessage: raw, Message: commit.NewCommitMessage(&raw)}
+	if strip.Normalize(record) || record.Message.Header.Description != "keep newline" || record.Message.Header.Type != "feat" {
+		t.Errorf("expected gitmoji stripped from conventional commit, but %+v got", record.Message.Header)
+	}
+	if err = (Grammar{Emoji: "keep"}).Validate(); err == nil {
+		t.Error("expected invalid emoji error, got nil")
+	}
+}
+
+func TestRules_GitmojiPrefix(t *testing.T) {
+	draft := Draft{Type: "feat", Subject: "add export"}
+	draft.Gitmoji = Rules{Gitmoji: GitmojiEmoji}.GitmojiPrefix(draft.Type, false)
+	if got := draft.String(); got != "feat: ✨ add export" {
+		t.Errorf("unexpected message %q", got)
+	}
+	if got := (Rules{Gitmoji: GitmojiCode}).GitmojiPrefix("fix", true); got != ":boom:" {
+		t.Errorf("expected :boom: for breaking changes, but %q got", got)
+	}
+	if got := (Rules{}).GitmojiPrefix("feat", false); got != "" {
+		t.Errorf("expected no gitmoji by default, but %q got", got)
+	}
+	if problems := (Rules{}).Validate("feat: ✨ add export"); len(problems) > 0 {
+		t.Errorf("expected gitmoji message valid, but %v got", problems)
+	}
+}
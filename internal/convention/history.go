@@ -0,0 +1,40 @@
+package convention
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+// HistoryScopes 统计最近 limit 条提交中使用过的范围，按使用次数降序排列
+func HistoryScopes(plus *git.Plus, limit int) ([]string, error) {
+	output, err := plus.Run("log", "--no-merges", "--format=%s", "-n", strconv.Itoa(limit))
+	if err != nil {
+		return nil, err
+	}
+	counts := map[string]int{}
+	for _, line := range strings.Split(string(output), "\n") {
+		m := headerReg.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil || m[2] == "" {
+			continue
+		}
+		for _, scope := range strings.Split(m[2], ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				counts[scope]++
+			}
+		}
+	}
+	scopes := make([]string, 0, len(counts))
+	for scope := range counts {
+		scopes = append(scopes, scope)
+	}
+	sort.Slice(scopes, func(i, j int) bool {
+		if counts[scopes[i]] != counts[scopes[j]] {
+			return counts[scopes[i]] > counts[scopes[j]]
+		}
+		return scopes[i] < scopes[j]
+	})
+	return scopes, nil
+}
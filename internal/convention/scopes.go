@@ -0,0 +1,39 @@
+package convention
+
+import (
+	"sort"
+
+	"github.com/coffee377/autoctl/internal/workspace"
+)
+
+// WithWorkspace 将工作区软件包对应的范围加入允许的范围，单包仓库不推导
+func (r Rules) WithWorkspace(dir string) (Rules, error) {
+	if r.SkipWorkspace {
+		return r, nil
+	}
+	packages, err := workspace.Discover(dir)
+	if err != nil {
+		return r, err
+	}
+	if len(packages) == 0 {
+		return r, nil
+	}
+	seen := map[string]bool{}
+	scopes := make([]string, 0, len(r.Scopes)+len(packages))
+	for _, s := range r.Scopes {
+		if !seen[s] {
+			seen[s] = true
+			scopes = append(scopes, s)
+		}
+	}
+	derived := make([]string, 0, len(packages))
+	for _, p := range packages {
+		if s := p.Scope(); s != "" && !seen[s] {
+			seen[s] = true
+			derived = append(derived, s)
+		}
+	}
+	sort.Strings(derived)
+	r.Scopes = append(scopes, derived...)
+	return r, nil
+}
@@ -0,0 +1,176 @@
+package dashboard
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/workspace"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// Status 软件包的发布状态
+type Status struct {
+	Package    workspace.Package `json:"package"`
+	TagPrefix  string            `json:"tagPrefix"`         // 软件包版本标签前缀
+	Tag        string            `json:"tag,omitempty"`     // 最近一次发布的标签，未发布时为空
+	Version    string            `json:"version,omitempty"` // 当前版本
+	Released   time.Time         `json:"released"`          // 最近一次发布的时间
+	Unreleased int               `json:"unreleased"`        // 最近一次发布之后修改了软件包的提交数
+	Pending    []string          `json:"pending,omitempty"` // 未提交的变更文件
+	Release    string            `json:"release,omitempty"` // 下一次发布的版本变动类型
+	Next       string            `json:"next,omitempty"`    // 下一次发布的版本
+}
+
+// TagPrefix 软件包的版本标签前缀：根目录为 prefix，Go 子模块为 <目录>/v，npm 子包为 <包名>@
+func TagPrefix(p workspace.Package, prefix string) string {
+	switch {
+	case p.Path == ".":
+		return prefix
+	case p.Type == workspace.Go || p.Name == "":
+		return p.Path + "/v"
+	default:
+		return p.Name + "@"
+	}
+}
+
+// Load 读取工作区中所有软件包的发布状态，工作区没有声明软件包时将根目录视为一个软件包，
+// 只修改 rules 中忽略路径的提交及变更文件不计入未发布的变更
+func Load(plus *git.Plus, dir, prefix string, rules workspace.PathRules) ([]*Status, error) {
+	packages, err := workspace.Discover(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(packages) == 0 {
+		packages = []workspace.Package{{Name: filepath.Base(absDir(dir)), Path: "."}}
+	}
+	list := make([]*Status, 0, len(packages))
+	for _, p := range packages {
+		s, err := load(plus, p, prefix, rules)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p.Path, err)
+		}
+		list = append(list, s)
+	}
+	return list, nil
+}
+
+func load(plus *git.Plus, p workspace.Package, prefix string, rules workspace.PathRules) (*Status, error) {
+	s := &Status{Package: p, TagPrefix: TagPrefix(p, prefix), Release: semver.Patch.String()}
+	revRange := "HEAD"
+	if tag, err := plus.LatestTag(s.TagPrefix + "*"); err == nil {
+		s.Tag = tag
+		s.Version = strings.TrimPrefix(tag, s.TagPrefix)
+		revRange = tag + "..HEAD"
+		refs, err := plus.TagRefs(tag)
+		if err != nil {
+			return nil, err
+		}
+		if len(refs) > 0 {
+			s.Released = refs[0].Date
+		}
+	}
+	pending, err := plus.PendingFiles(p.Path)
+	if err != nil {
+		return nil, err
+	}
+	if rules.Empty() {
+		s.Pending = pending
+		s.Unreleased, err = plus.CountCommits(revRange, p.Path)
+		if err != nil {
+			return nil, err
+		}
+		return s, s.SetRelease(s.Release)
+	}
+	s.Pending = rules.Filter(pending)
+	files, err := plus.CommitFiles(revRange)
+	if err != nil {
+		return nil, err
+	}
+	for _, changed := range files {
+		if len(rules.Filter(within(changed, p.Path))) > 0 {
+			s.Unreleased++
+		}
+	}
+	return s, s.SetRelease(s.Release)
+}
+
+// within 位于软件包目录中的文件
+func within(files []string, dir string) []string {
+	if dir == "." {
+		return files
+	}
+	list := make([]string, 0, len(files))
+	for _, file := range files {
+		if strings.HasPrefix(file, dir+"/") {
+			list = append(list, file)
+		}
+	}
+	return list
+}
+
+// SetRelease 设置下一次发布的版本变动类型并计算下一个版本
+func (s *Status) SetRelease(name string) error {
+	changed, err := semver.ParseReleaseType(name)
+	if err != nil {
+		return err
+	}
+	current := s.Version
+	if current == "" {
+		current = "0.0.0"
+	}
+	v, err := semver.Version(current)
+	if err != nil {
+		return err
+	}
+	s.Release = changed.String()
+	s.Next = v.Increment(semver.WithReleaseType(changed)).String()
+	return nil
+}
+
+// Render 以表格形式输出发布状态，selected 为选中的行，从 0 开始，小于 0 时不选中
+func Render(w io.Writer, list []*Status, selected int) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "#\tPACKAGE\tVERSION\tRELEASED\tUNRELEASED\tPENDING\tNEXT")
+	for i, s := range list {
+		released := "-"
+		if !s.Released.IsZero() {
+			released = s.Released.Format("2006-01-02")
+		}
+		next := "-"
+		if s.Unreleased > 0 || s.Tag == "" {
+			next = fmt.Sprintf("%s (%s)", s.Next, s.Release)
+		}
+		index := fmt.Sprintf("%d", i+1)
+		if i == selected {
+			index = "> " + index
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%s\n", index, name(s.Package), orDash(s.Version), released, s.Unreleased, len(s.Pending), next)
+	}
+	return tw.Flush()
+}
+
+func name(p workspace.Package) string {
+	if p.Name != "" {
+		return p.Name
+	}
+	return p.Path
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func absDir(dir string) string {
+	if abs, err := filepath.Abs(dir); err == nil {
+		return abs
+	}
+	return dir
+}
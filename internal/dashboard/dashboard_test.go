@@ -0,0 +1,69 @@
+package dashboard
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/testutil"
+	"github.com/coffee377/autoctl/internal/workspace"
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+func write(t *testing.T, dir, name, content string) {
+	t.Helper()
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	_ = os.MkdirAll(filepath.Dir(p), 0o755)
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := testutil.NewRepo(t, t.TempDir())
+	write(t, dir, "package.json", `{"name":"root","private":true,"workspaces":["packages/*"]}`)
+	write(t, dir, "packages/a/package.json", `{"name":"@org/a"}`)
+	write(t, dir, "packages/b/go.mod", "module example.com/b\n")
+	testutil.GitRun(t, dir, "add", "-A")
+	testutil.GitRun(t, dir, "commit", "-q", "-m", "feat: init")
+	testutil.GitRun(t, dir, "tag", "-a", "-m", "release", "@org/a@1.2.0")
+	write(t, dir, "packages/b/b.go", "package b\n")
+	testutil.GitRun(t, dir, "add", "-A")
+	testutil.GitRun(t, dir, "commit", "-q", "-m", "feat(b): add b")
+	write(t, dir, "packages/a/index.js", "")
+
+	list, err := Load(&git.Plus{Cwd: dir}, dir, "v", workspace.PathRules{})
+	if err != nil || len(list) != 2 {
+		t.Fatalf("expected 2 packages, but %v %v got", list, err)
+	}
+	a, b := list[0], list[1]
+	if a.TagPrefix != "@org/a@" || a.Version != "1.2.0" || a.Released.IsZero() || a.Unreleased != 0 || len(a.Pending) != 1 {
+		t.Errorf("unexpected status of a %+v", a)
+	}
+	if b.TagPrefix != "packages/b/v" || b.Tag != "" || b.Unreleased != 2 || b.Next != "0.0.1" {
+		t.Errorf("unexpected status of b %+v", b)
+	}
+	if err = b.SetRelease("minor"); err != nil || b.Next != "0.1.0" {
+		t.Errorf("expected next 0.1.0, but %s %v got", b.Next, err)
+	}
+	var out bytes.Buffer
+	if err = Render(&out, list, 1); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "> 2") || !strings.Contains(out.String(), "0.1.0 (minor)") {
+		t.Errorf("unexpected table\n%s", out.String())
+	}
+
+	write(t, dir, "packages/b/README.md", "# b\n")
+	testutil.GitRun(t, dir, "add", "packages/b/README.md")
+	testutil.GitRun(t, dir, "commit", "-q", "-m", "docs(b): readme")
+	rules := workspace.PathRules{Ignore: []string{"*.md", "packages/*/index.js"}}
+	if list, err = Load(&git.Plus{Cwd: dir}, dir, "v", rules); err != nil {
+		t.Fatal(err)
+	}
+	if a, b = list[0], list[1]; len(a.Pending) != 0 || b.Unreleased != 2 {
+		t.Errorf("expected ignored paths not counted, but a %+v b %+v got", a, b)
+	}
+}
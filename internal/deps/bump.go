@@ -0,0 +1,212 @@
+package deps
+
+import (
+	"encoding/json"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/fileutil"
+	"github.com/coffee377/autoctl/internal/workspace"
+)
+
+var (
+	pomDependencyReg = regexp.MustCompile(`(?s)<dependency>.*?</dependency>`)
+	pomVersionReg    = regexp.MustCompile(`(<version>\s*)([^<]*?)(\s*</version>)`)
+)
+
+// rangeReg package.json 中可以更新的版本范围，保留 ^、~ 等前缀
+var rangeReg = regexp.MustCompile(`^([\^~]|[<>]=?|=)?\s*v?\d+(\.\d+){0,2}([-+][0-9A-Za-z.+-]*)?$`)
+
+// Bump 将目录及其工作区软件包清单文件中的依赖版本更新为 versions 中的版本
+// （键为 Go 模块路径、npm 包名或 Maven 的 groupId:artifactId），返回变更的清单文件（相对路径），dryRun 时只计算不写入
+func Bump(dir string, versions map[string]string, dryRun bool) ([]string, error) {
+	manifests := []string{"go.mod", "package.json", "pom.xml"}
+	packages, err := workspace.Discover(dir)
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range packages {
+		name := "package.json"
+		if p.Type == workspace.Go {
+			name = "go.mod"
+		}
+		manifests = append(manifests, path.Join(p.Path, name))
+	}
+	changed := make([]string, 0)
+	seen := map[string]bool{}
+	for _, manifest := range manifests {
+		if seen[manifest] {
+			continue
+		}
+		seen[manifest] = true
+		file := filepath.Join(dir, filepath.FromSlash(manifest))
+		if _, err = os.Stat(file); err != nil {
+			continue
+		}
+		ok, err := bumpFile(file, versions, dryRun)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			changed = append(changed, manifest)
+		}
+	}
+	return changed, nil
+}
+
+func bumpFile(file string, versions map[string]string, dryRun bool) (bool, error) {
+	fn := func(data []byte) ([]byte, bool, error) {
+		changed := false
+		for name, version := range versions {
+			var ok bool
+			var err error
+			switch path.Base(filepath.ToSlash(file)) {
+			case "go.mod":
+				data, ok = SetGoMod(data, name, version)
+			case "pom.xml":
+				data, ok = SetPom(data, name, version)
+			default:
+				if data, ok, err = SetPackageJSON(data, name, version); err != nil {
+					return nil, false, err
+				}
+			}
+			changed = changed || ok
+		}
+		return data, changed, nil
+	}
+	if !dryRun {
+		return fileutil.Update(file, fn)
+	}
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return false, err
+	}
+	data, _, err := fileutil.Decode(raw)
+	if err != nil {
+		return false, err
+	}
+	_, changed, err := fn(data)
+	return changed, err
+}
+
+// SetGoMod 更新 go.mod 中 require 的模块版本，version 不含 v 前缀时自动补全，返回内容是否变更
+func SetGoMod(data []byte, module, version string) ([]byte, bool) {
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	reg := regexp.MustCompile(`^(\s*(?:require\s+)?)` + regexp.QuoteMeta(module) + `(\s+)(\S+)(.*)$`)
+	lines := strings.Split(string(data), "\n")
+	changed, block := false, ""
+	for i, line := range lines {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 2 && fields[1] == "(":
+			block = fields[0]
+			continue
+		case len(fields) > 0 && fields[0] == ")":
+			block = ""
+			continue
+		}
+		inRequire := block == "require" || (block == "" && len(fields) > 0 && fields[0] == "require")
+		if !inRequire {
+			continue
+		}
+		m := reg.FindStringSubmatch(line)
+		if m == nil || m[3] == version {
+			continue
+		}
+		lines[i] = m[1] + module + m[2] + version + m[4]
+		changed = true
+	}
+	return []byte(strings.Join(lines, "\n")), changed
+}
+
+// SetPackageJSON 更新 package.json 中 dependencies、devDependencies、optionalDependencies 及 peerDependencies 的版本，
+// 保留原有的范围前缀，workspace:、file: 等协议及非版本号的值不修改，返回内容是否变更
+func SetPackageJSON(data []byte, name, version string) ([]byte, bool, error) {
+	pkg := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, false, err
+	}
+	declared := false
+	for _, section := range []string{"dependencies", "devDependencies", "optionalDependencies", "peerDependencies"} {
+		deps := map[string]string{}
+		if raw, ok := pkg[section]; ok && json.Unmarshal(raw, &deps) == nil {
+			if _, ok = deps[name]; ok {
+				declared = true
+			}
+		}
+	}
+	if !declared {
+		return data, false, nil
+	}
+	version = strings.TrimPrefix(version, "v")
+	reg := regexp.MustCompile(`("` + regexp.QuoteMeta(name) + `"\s*:\s*")([^"]*)(")`)
+	changed := false
+	data = reg.ReplaceAllFunc(data, func(b []byte) []byte {
+		m := reg.FindSubmatch(b)
+		value := string(m[2])
+		if !rangeReg.MatchString(value) {
+			return b
+		}
+		prefix := strings.TrimSpace(value[:len(value)-len(strings.TrimLeft(value, "^~<>= "))])
+		if updated := prefix + version; updated != value {
+			changed = true
+			return []byte(string(m[1]) + updated + string(m[3]))
+		}
+		return b
+	})
+	return data, changed, nil
+}
+
+// SetPom 更新 pom.xml 中 dependencies 及 dependencyManagement 声明的依赖版本，name 为 groupId:artifactId，
+// 版本引用属性（如 ${lib.version}）时更新 properties 中的属性值，返回内容是否变更
+func SetPom(data []byte, name, version string) ([]byte, bool) {
+	group, artifact, ok := strings.Cut(name, ":")
+	if !ok {
+		return data, false
+	}
+	version = strings.TrimPrefix(version, "v")
+	changed := false
+	props := map[string]bool{}
+	data = pomDependencyReg.ReplaceAllFunc(data, func(b []byte) []byte {
+		text := string(b)
+		if xmlValue(text, "groupId") != group || xmlValue(text, "artifactId") != artifact {
+			return b
+		}
+		m := pomVersionReg.FindStringSubmatchIndex(text)
+		if m == nil {
+			return b
+		}
+		current := text[m[4]:m[5]]
+		if strings.HasPrefix(current, "${") && strings.HasSuffix(current, "}") {
+			props[current[2:len(current)-1]] = true
+			return b
+		}
+		if current == version {
+			return b
+		}
+		changed = true
+		return []byte(text[:m[4]] + version + text[m[5]:])
+	})
+	for prop := range props {
+		reg := regexp.MustCompile(`(<` + regexp.QuoteMeta(prop) + `>\s*)([^<]*?)(\s*</` + regexp.QuoteMeta(prop) + `>)`)
+		if m := reg.FindSubmatchIndex(data); m != nil && string(data[m[4]:m[5]]) != version {
+			data = append(append(append([]byte{}, data[:m[4]]...), version...), data[m[5]:]...)
+			changed = true
+		}
+	}
+	return data, changed
+}
+
+func xmlValue(text, tag string) string {
+	start := strings.Index(text, "<"+tag+">")
+	end := strings.Index(text, "</"+tag+">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return strings.TrimSpace(text[start+len(tag)+2 : end])
+}
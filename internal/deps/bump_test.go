@@ -0,0 +1,160 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSetGoMod(t *testing.T) {
+	data := []byte(`module example.com/app
+
+go 1.18
+
+require example.com/lib v1.0.0
+
+require (
+	example.com/lib/v2 v2.1.0 // indirect
+	github.com/spf13/cobra v1.8.0
+)
+
+replace example.com/lib => ../lib
+`)
+	expected := `module example.com/app
+
+go 1.18
+
+require example.com/lib v1.1.0
+
+require (
+	example.com/lib/v2 v2.1.0 // indirect
+	github.com/spf13/cobra v1.8.0
+)
+
+replace example.com/lib => ../lib
+`
+	actual, changed := SetGoMod(data, "example.com/lib", "1.1.0")
+	if !changed || string(actual) != expected {
+		t.Errorf("\nExpected: \n%s\nActual: \n%s\n", expected, actual)
+	}
+	actual, changed = SetGoMod(data, "example.com/lib/v2", "v2.2.0")
+	if list, _ := ParseGoMod(actual); !changed || list[1] != (Dependency{Name: "example.com/lib/v2", Version: "v2.2.0", Indirect: true}) {
+		t.Errorf("expected block require updated, but %s got", actual)
+	}
+	if _, changed = SetGoMod(data, "example.com/none", "1.0.0"); changed {
+		t.Error("expected unknown module unchanged")
+	}
+}
+
+func TestSetPackageJSON(t *testing.T) {
+	data := []byte(`{
+  "name": "app",
+  "dependencies": {
+    "@org/lib": "^1.0.0",
+    "@org/ui": "workspace:*"
+  },
+  "devDependencies": {
+    "@org/tool": "1.0.0"
+  }
+}
+`)
+	expected := `{
+  "name": "app",
+  "dependencies": {
+    "@org/lib": "^1.2.0",
+    "@org/ui": "workspace:*"
+  },
+  "devDependencies": {
+    "@org/tool": "1.0.0"
+  }
+}
+`
+	actual, changed, err := SetPackageJSON(data, "@org/lib", "1.2.0")
+	if err != nil || !changed || string(actual) != expected {
+		t.Errorf("\nExpected: \n%s\nActual: \n%s\n", expected, actual)
+	}
+	if _, changed, _ = SetPackageJSON(data, "@org/ui", "2.0.0"); changed {
+		t.Error("expected workspace protocol unchanged")
+	}
+}
+
+func TestBump(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"package.json":            `{"name":"root","private":true,"workspaces":["packages/*"]}`,
+		"packages/a/package.json": `{"name":"a","dependencies":{"lib":"~1.0.0"}}`,
+		"packages/b/package.json": `{"name":"b"}`,
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	changed, err := Bump(dir, map[string]string{"lib": "1.0.3"}, true)
+	if err != nil || !reflect.DeepEqual(changed, []string{"packages/a/package.json"}) {
+		t.Fatalf("expected packages/a/package.json, but %v %v got", changed, err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "packages/a/package.json")); string(data) != files["packages/a/package.json"] {
+		t.Errorf("expected dry run not writing, but %s got", data)
+	}
+	if _, err = Bump(dir, map[string]string{"lib": "1.0.3"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "packages/a/package.json")); string(data) != `{"name":"a","dependencies":{"lib":"~1.0.3"}}` {
+		t.Errorf("unexpected package.json %s", data)
+	}
+}
+
+func TestSetPom(t *testing.T) {
+	data := []byte(`<project>
+  <properties>
+    <core.version>1.0.0</core.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>com.example</groupId>
+        <artifactId>lib</artifactId>
+        <version>1.0.0</version>
+      </dependency>
+      <dependency>
+        <groupId>com.example</groupId>
+        <artifactId>core</artifactId>
+        <version>${core.version}</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+</project>
+`)
+	expected := `<project>
+  <properties>
+    <core.version>2.0.0</core.version>
+  </properties>
+  <dependencyManagement>
+    <dependencies>
+      <dependency>
+        <groupId>com.example</groupId>
+        <artifactId>lib</artifactId>
+        <version>1.1.0</version>
+      </dependency>
+      <dependency>
+        <groupId>com.example</groupId>
+        <artifactId>core</artifactId>
+        <version>${core.version}</version>
+      </dependency>
+    </dependencies>
+  </dependencyManagement>
+</project>
+`
+	actual, changed := SetPom(data, "com.example:lib", "1.1.0")
+	actual, ok := SetPom(actual, "com.example:core", "2.0.0")
+	if !changed || !ok || string(actual) != expected {
+		t.Errorf("\nExpected: \n%s\nActual: \n%s\n", expected, actual)
+	}
+	if _, changed = SetPom(data, "com.example:none", "1.0.0"); changed {
+		t.Error("expected unknown artifact unchanged")
+	}
+}
@@ -0,0 +1,169 @@
+package deps
+
+import (
+	"fmt"
+	"path"
+	"sort"
+
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+// DefaultManifests 默认比较的清单文件
+var DefaultManifests = []string{"go.mod", "package.json"}
+
+const (
+	Added   = "added"
+	Removed = "removed"
+	Updated = "updated"
+)
+
+// Config 依赖变更报告配置，对应配置文件 release.dependencies 节点
+type Config struct {
+	Enabled   bool     `mapstructure:"enabled"`   // 在变更日志中展示依赖变更
+	Manifests []string `mapstructure:"manifests"` // 清单文件，相对于仓库根目录，默认 go.mod、package.json
+	Indirect  bool     `mapstructure:"indirect"`  // 包含 go.mod 中的间接依赖
+	Dev       bool     `mapstructure:"dev"`       // 包含 package.json 中的开发依赖
+	Licenses  bool     `mapstructure:"licenses"`  // 检查许可证变更，Go 模块需已下载到模块缓存
+}
+
+// Change 依赖变更
+type Change struct {
+	Manifest string `json:"manifest"`
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	From     string `json:"from,omitempty"`
+	To       string `json:"to,omitempty"`
+}
+
+// LicenseChange 许可证变更，Name 为空时表示项目自身的许可证
+type LicenseChange struct {
+	Name string `json:"name,omitempty"`
+	From string `json:"from,omitempty"`
+	To   string `json:"to,omitempty"`
+}
+
+// Report 两个版本之间的依赖变更
+type Report struct {
+	Changes  []Change        `json:"changes,omitempty"`
+	Licenses []LicenseChange `json:"licenses,omitempty"`
+}
+
+// IsEmpty 没有任何变更
+func (r *Report) IsEmpty() bool {
+	return r == nil || len(r.Changes) == 0 && len(r.Licenses) == 0
+}
+
+// Diff 比较 from 与 to 两个引用中的清单文件
+func Diff(plus *git.Plus, cfg Config, from, to string) (*Report, error) {
+	manifests := cfg.Manifests
+	if len(manifests) == 0 {
+		manifests = DefaultManifests
+	}
+	report := &Report{}
+	cache := ""
+	if cfg.Licenses {
+		cache = goModCache()
+	}
+	for _, manifest := range manifests {
+		before, err := load(plus, from, manifest, cfg)
+		if err != nil {
+			return nil, err
+		}
+		after, err := load(plus, to, manifest, cfg)
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range Compare(manifest, before, after) {
+			report.Changes = append(report.Changes, c)
+			if cfg.Licenses && path.Base(manifest) == "go.mod" {
+				fromLicense := goModuleLicense(cache, c.Name, c.From)
+				toLicense := goModuleLicense(cache, c.Name, c.To)
+				if c.Kind == Updated && fromLicense != "" && toLicense != "" && fromLicense != toLicense {
+					report.Licenses = append(report.Licenses, LicenseChange{Name: c.Name, From: fromLicense, To: toLicense})
+				}
+			}
+		}
+	}
+	if cfg.Licenses {
+		changed, err := projectLicense(plus, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if changed != nil {
+			report.Licenses = append(report.Licenses, *changed)
+		}
+	}
+	return report, nil
+}
+
+func load(plus *git.Plus, ref, manifest string, cfg Config) (map[string]Dependency, error) {
+	data, err := plus.Show(ref, manifest)
+	if err != nil || data == nil {
+		return map[string]Dependency{}, err
+	}
+	list, err := Parse(manifest, data)
+	if err != nil {
+		return nil, fmt.Errorf("%s at %s: %w", manifest, ref, err)
+	}
+	deps := make(map[string]Dependency, len(list))
+	for _, d := range list {
+		if (d.Indirect && !cfg.Indirect) || (d.Dev && !cfg.Dev) {
+			continue
+		}
+		deps[d.Name] = d
+	}
+	return deps, nil
+}
+
+// Compare 比较依赖列表，结果按名称排序
+func Compare(manifest string, before, after map[string]Dependency) []Change {
+	changes := make([]Change, 0)
+	for _, name := range sortedKeys(before, after) {
+		b, inBefore := before[name]
+		a, inAfter := after[name]
+		switch {
+		case !inBefore:
+			changes = append(changes, Change{Manifest: manifest, Name: name, Kind: Added, To: a.Version})
+		case !inAfter:
+			changes = append(changes, Change{Manifest: manifest, Name: name, Kind: Removed, From: b.Version})
+		case a.Version != b.Version:
+			changes = append(changes, Change{Manifest: manifest, Name: name, Kind: Updated, From: b.Version, To: a.Version})
+		}
+	}
+	return changes
+}
+
+func projectLicense(plus *git.Plus, from, to string) (*LicenseChange, error) {
+	files, err := plus.ChangedFiles(from, to, licenseFiles...)
+	if err != nil || len(files) == 0 {
+		return nil, err
+	}
+	change := &LicenseChange{}
+	for _, name := range licenseFiles {
+		if data, _ := plus.Show(from, name); data != nil && change.From == "" {
+			change.From = DetectLicense(data)
+		}
+		if data, _ := plus.Show(to, name); data != nil && change.To == "" {
+			change.To = DetectLicense(data)
+		}
+	}
+	if change.From == change.To {
+		return nil, nil
+	}
+	return change, nil
+}
+
+func sortedKeys(maps ...map[string]Dependency) []string {
+	seen := map[string]bool{}
+	keys := make([]string, 0)
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,100 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/testutil"
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+const goModV1 = `module github.com/coffee377/autoctl
+
+go 1.18
+
+require (
+	github.com/spf13/cobra v1.6.0
+	github.com/spf13/viper v1.16.0 // indirect
+	gorm.io/gorm v1.25.0
+)
+
+require github.com/mitchellh/go-homedir v1.1.0
+`
+
+const goModV2 = `module github.com/coffee377/autoctl
+
+go 1.18
+
+require (
+	github.com/spf13/cobra v1.7.0
+	github.com/spf13/viper v1.17.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require github.com/mitchellh/go-homedir v1.1.0
+
+replace github.com/spf13/cobra => github.com/spf13/cobra v1.7.1
+`
+
+func TestParseGoMod(t *testing.T) {
+	deps, err := ParseGoMod([]byte(goModV2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 4 || deps[1].Name != "github.com/spf13/cobra" || deps[1].Version != "v1.7.1" || !deps[2].Indirect {
+		t.Errorf("unexpected dependencies %+v", deps)
+	}
+}
+
+func TestParsePackageJSON(t *testing.T) {
+	deps, err := ParsePackageJSON([]byte(`{"dependencies":{"vue":"^3.3.0"},"devDependencies":{"vite":"^4.0.0"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 2 || deps[0].Name != "vite" || !deps[0].Dev || deps[1].Version != "^3.3.0" {
+		t.Errorf("unexpected dependencies %+v", deps)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	testutil.GitIdentity(t)
+	dir := t.TempDir()
+	run := func(args ...string) {
+		testutil.GitRun(t, dir, args...)
+	}
+	write := func(name, content string) {
+		_ = os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)
+	}
+	run("init", "--quiet")
+	write("go.mod", goModV1)
+	write("LICENSE", "Permission is hereby granted, free of charge, to any person obtaining a copy")
+	run("add", ".")
+	run("commit", "--quiet", "-m", "v1")
+	run("tag", "v1.0.0")
+	write("go.mod", goModV2)
+	write("LICENSE", "Apache License\nVersion 2.0, January 2004")
+	run("add", ".")
+	run("commit", "--quiet", "-m", "v2")
+
+	report, err := Diff(&git.Plus{Cwd: dir}, Config{Licenses: true}, "v1.0.0", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Change{
+		{Manifest: "go.mod", Name: "github.com/spf13/cobra", Kind: Updated, From: "v1.6.0", To: "v1.7.1"},
+		{Manifest: "go.mod", Name: "gopkg.in/yaml.v3", Kind: Added, To: "v3.0.1"},
+		{Manifest: "go.mod", Name: "gorm.io/gorm", Kind: Removed, From: "v1.25.0"},
+	}
+	if len(report.Changes) != len(expected) {
+		t.Fatalf("expected %d changes, but %+v got", len(expected), report.Changes)
+	}
+	for i, e := range expected {
+		if report.Changes[i] != e {
+			t.Errorf("change %d expected %+v, but %+v got", i, e, report.Changes[i])
+		}
+	}
+	if len(report.Licenses) != 1 || report.Licenses[0] != (LicenseChange{From: "MIT", To: "Apache-2.0"}) {
+		t.Errorf("unexpected license changes %+v", report.Licenses)
+	}
+}
@@ -0,0 +1,88 @@
+package deps
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/runctx"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// licenseFiles 常见的许可证文件名
+var licenseFiles = []string{"LICENSE", "LICENSE.md", "LICENSE.txt", "LICENCE", "COPYING", "COPYING.md"}
+
+var licenseRules = []struct {
+	id  string
+	reg *regexp.Regexp
+}{
+	{"AGPL-3.0", regexp.MustCompile(`GNU AFFERO GENERAL PUBLIC LICENSE`)},
+	{"LGPL-3.0", regexp.MustCompile(`GNU LESSER GENERAL PUBLIC LICENSE\s+Version 3`)},
+	{"LGPL-2.1", regexp.MustCompile(`GNU LESSER GENERAL PUBLIC LICENSE\s+Version 2\.1`)},
+	{"GPL-3.0", regexp.MustCompile(`GNU GENERAL PUBLIC LICENSE\s+Version 3`)},
+	{"GPL-2.0", regexp.MustCompile(`GNU GENERAL PUBLIC LICENSE\s+Version 2`)},
+	{"MPL-2.0", regexp.MustCompile(`Mozilla Public License,? [Vv]ersion 2\.0`)},
+	{"Apache-2.0", regexp.MustCompile(`Apache License,?\s+Version 2\.0`)},
+	{"BSD-3-Clause", regexp.MustCompile(`(?s)Redistribution and use in source and binary forms.*Neither the name`)},
+	{"BSD-2-Clause", regexp.MustCompile(`Redistribution and use in source and binary forms`)},
+	{"ISC", regexp.MustCompile(`Permission to use, copy, modify, and(/or)? distribute this software for any`)},
+	{"MIT", regexp.MustCompile(`Permission is hereby granted, free of charge, to any person obtaining`)},
+	{"Unlicense", regexp.MustCompile(`This is free and unencumbered software released into the public domain`)},
+}
+
+// DetectLicense 根据许可证文本识别 SPDX 标识，无法识别时返回空字符串
+func DetectLicense(text []byte) string {
+	for _, rule := range licenseRules {
+		if rule.reg.Match(text) {
+			return rule.id
+		}
+	}
+	return ""
+}
+
+// goModCache 本机的 Go 模块缓存目录
+func goModCache() string {
+	if dir := os.Getenv("GOMODCACHE"); dir != "" {
+		return dir
+	}
+	ctx, cancel := runctx.Command()
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "env", "GOMODCACHE")
+	start := time.Now()
+	output, err := cmd.Output()
+	log.TraceCommand(cmd, start, err)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// escapeModulePath 模块缓存路径中大写字母转义为 !小写
+func escapeModulePath(p string) string {
+	var sb strings.Builder
+	for _, r := range p {
+		if 'A' <= r && r <= 'Z' {
+			sb.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// goModuleLicense 从模块缓存中读取模块的许可证，模块未下载时返回空字符串
+func goModuleLicense(cache, module, version string) string {
+	if cache == "" || version == "" {
+		return ""
+	}
+	dir := filepath.Join(cache, escapeModulePath(module)+"@"+escapeModulePath(version))
+	for _, name := range licenseFiles {
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			return DetectLicense(data)
+		}
+	}
+	return ""
+}
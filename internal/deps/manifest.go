@@ -0,0 +1,107 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// Dependency 清单文件中声明的依赖
+type Dependency struct {
+	Name     string
+	Version  string
+	Indirect bool // go.mod 中的间接依赖
+	Dev      bool // package.json 中的开发依赖
+}
+
+// Parse 按文件名选择解析器
+func Parse(manifest string, data []byte) ([]Dependency, error) {
+	switch path.Base(manifest) {
+	case "go.mod":
+		return ParseGoMod(data)
+	case "package.json":
+		return ParsePackageJSON(data)
+	}
+	return nil, fmt.Errorf("unsupported manifest %s", manifest)
+}
+
+// ParseGoMod 解析 go.mod 中的 require，replace 指向其它版本时以 replace 为准
+func ParseGoMod(data []byte) ([]Dependency, error) {
+	deps := map[string]*Dependency{}
+	replaces := map[string]string{}
+	block := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		comment := ""
+		if i := strings.Index(line, "//"); i >= 0 {
+			line, comment = line[:i], strings.TrimSpace(line[i+2:])
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if block != "" {
+			if fields[0] == ")" {
+				block = ""
+				continue
+			}
+			fields = append([]string{block}, fields...)
+		} else if len(fields) == 2 && fields[1] == "(" {
+			block = fields[0]
+			continue
+		}
+		switch fields[0] {
+		case "require":
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("invalid require %q", line)
+			}
+			deps[fields[1]] = &Dependency{Name: fields[1], Version: fields[2], Indirect: comment == "indirect"}
+		case "replace":
+			// replace old [v] => new v，本地路径替换没有版本
+			if i := indexOf(fields, "=>"); i > 0 && len(fields) == i+3 {
+				replaces[fields[1]] = fields[i+2]
+			}
+		}
+	}
+	list := make([]Dependency, 0, len(deps))
+	for name, d := range deps {
+		if v, ok := replaces[name]; ok {
+			d.Version = v
+		}
+		list = append(list, *d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+func indexOf(fields []string, s string) int {
+	for i, f := range fields {
+		if f == s {
+			return i
+		}
+	}
+	return -1
+}
+
+// ParsePackageJSON 解析 package.json 中的 dependencies 及 devDependencies
+func ParsePackageJSON(data []byte) ([]Dependency, error) {
+	pkg := struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}{}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	list := make([]Dependency, 0, len(pkg.Dependencies)+len(pkg.DevDependencies))
+	for name, v := range pkg.Dependencies {
+		list = append(list, Dependency{Name: name, Version: v})
+	}
+	for name, v := range pkg.DevDependencies {
+		if _, ok := pkg.Dependencies[name]; !ok {
+			list = append(list, Dependency{Name: name, Version: v, Dev: true})
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
@@ -0,0 +1,114 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/workspace"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+const (
+	DefaultBumpBranch  = "autoctl/deps-bump"
+	DefaultBumpMessage = "chore(deps): bump internal dependencies"
+)
+
+// WorkspaceVersions 工作区软件包的当前版本，npm 包读取 package.json 中的 version，
+// Go 模块使用 <模块目录>/v* 形式的最新标签（Go 多模块仓库的标签约定）
+func WorkspaceVersions(plus *git.Plus, dir string) (map[string]string, error) {
+	packages, err := workspace.Discover(dir)
+	if err != nil {
+		return nil, err
+	}
+	versions := map[string]string{}
+	for _, p := range packages {
+		if p.Name == "" {
+			continue
+		}
+		switch p.Type {
+		case workspace.NPM:
+			data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(p.Path), "package.json"))
+			if err != nil {
+				return nil, err
+			}
+			pkg := struct {
+				Version string `json:"version"`
+			}{}
+			if err = json.Unmarshal(data, &pkg); err != nil {
+				return nil, fmt.Errorf("%s: %w", p.Path, err)
+			}
+			if pkg.Version != "" {
+				versions[p.Name] = pkg.Version
+			}
+		case workspace.Go:
+			if tag, err := plus.LatestTag(p.Path + "/v*"); err == nil {
+				versions[p.Name] = strings.TrimPrefix(tag, p.Path+"/")
+			}
+		}
+	}
+	return versions, nil
+}
+
+// Proposal 以合并请求提交依赖版本更新
+type Proposal struct {
+	Branch  string // 源分支，默认 autoctl/deps-bump
+	Base    string // 目标分支，默认当前分支
+	Remote  string // 推送的远程仓库，默认 origin
+	Message string // 提交信息及合并请求标题
+}
+
+// Propose 在源分支上提交变更的清单文件并推送，创建或更新合并请求，完成后切换回目标分支
+func Propose(plus *git.Plus, p provider.Provider, files []string, versions map[string]string, proposal Proposal) (*provider.PullRequest, error) {
+	if proposal.Branch == "" {
+		proposal.Branch = DefaultBumpBranch
+	}
+	if proposal.Remote == "" {
+		proposal.Remote = "origin"
+	}
+	if proposal.Message == "" {
+		proposal.Message = DefaultBumpMessage
+	}
+	if proposal.Base == "" {
+		base, err := plus.CurrentBranch()
+		if err != nil {
+			return nil, err
+		}
+		proposal.Base = base
+	}
+	if err := plus.CreateBranch(proposal.Branch); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if _, err := plus.Run("checkout", "--quiet", proposal.Base); err != nil {
+			log.Warn("checkout %s: %s", proposal.Base, err)
+		}
+	}()
+	if err := plus.Commit(proposal.Message, files...); err != nil {
+		return nil, err
+	}
+	// 源分支由 autoctl 独占，重复执行时强制覆盖
+	if err := plus.Push(proposal.Remote, "HEAD:refs/heads/"+proposal.Branch, true); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(versions))
+	for name := range versions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var body strings.Builder
+	body.WriteString("Bump internal dependencies to the released versions:\n\n")
+	for _, name := range names {
+		body.WriteString(fmt.Sprintf("* `%s` %s\n", name, versions[name]))
+	}
+	body.WriteString("\nUpdated manifests:\n\n")
+	for _, f := range files {
+		body.WriteString(fmt.Sprintf("* %s\n", f))
+	}
+	return p.CreatePullRequest(&provider.PullRequest{Title: proposal.Message, Body: body.String(), Head: proposal.Branch, Base: proposal.Base})
+}
@@ -0,0 +1,82 @@
+package deps
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/testutil"
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+func TestPropose(t *testing.T) {
+	remote := t.TempDir()
+	dir := testutil.NewRepo(t, t.TempDir())
+	testutil.GitRun(t, remote, "init", "--quiet", "--bare")
+	testutil.GitRun(t, dir, "remote", "add", "origin", remote)
+	_ = os.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"name":"app","dependencies":{"lib":"^1.0.0"}}`), 0o644)
+	testutil.GitRun(t, dir, "add", ".")
+	testutil.GitRun(t, dir, "commit", "--quiet", "-m", "chore: init")
+
+	versions := map[string]string{"lib": "1.1.0"}
+	changed, err := Bump(dir, versions, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var created provider.PullRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/repos/a/b/pulls" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&created)
+		_, _ = w.Write([]byte(`{"number":3,"html_url":"https://github.com/a/b/pull/3"}`))
+	}))
+	defer server.Close()
+	p, _ := provider.New(provider.Config{Type: provider.GitHub, URL: server.URL, Repo: "a/b"})
+
+	pr, err := Propose(&git.Plus{Cwd: dir}, p, changed, versions, Proposal{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.URL != "https://github.com/a/b/pull/3" || created.Head != DefaultBumpBranch || created.Base != "main" {
+		t.Errorf("unexpected pull request %+v %+v", pr, created)
+	}
+	if !strings.Contains(created.Body, "`lib` 1.1.0") {
+		t.Errorf("expected body listing lib 1.1.0, but %s got", created.Body)
+	}
+	if branch := strings.TrimSpace(testutil.GitRun(t, dir, "rev-parse", "--abbrev-ref", "HEAD")); branch != "main" {
+		t.Errorf("expected back on main, but %s got", branch)
+	}
+	if content := testutil.GitRun(t, remote, "show", DefaultBumpBranch+":package.json"); !strings.Contains(content, `"lib":"^1.1.0"`) {
+		t.Errorf("unexpected pushed package.json %s", content)
+	}
+}
+
+func TestWorkspaceVersions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"package.json":            `{"name":"root","private":true,"workspaces":["packages/*"]}`,
+		"packages/a/package.json": `{"name":"a","version":"1.2.0"}`,
+		"packages/b/package.json": `{"name":"b"}`,
+	}
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		_ = os.MkdirAll(filepath.Dir(p), 0o755)
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	versions, err := WorkspaceVersions(&git.Plus{Cwd: dir}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 1 || versions["a"] != "1.2.0" {
+		t.Errorf("expected a 1.2.0, but %v got", versions)
+	}
+}
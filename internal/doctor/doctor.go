@@ -0,0 +1,322 @@
+// Package doctor 诊断 autoctl 的运行环境：git 版本及配置、访问令牌及权限范围、配置文件、CI 环境、工作区软件包及代码托管平台的连通性，
+// 每个问题附带修复建议
+package doctor
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/workspace"
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+// 诊断状态
+const (
+	StatusOK   = "ok"
+	StatusWarn = "warn" // 不影响当前命令，但部分功能不可用
+	StatusFail = "fail" // 需要修复才能发布
+	StatusSkip = "skip" // 不适用于当前配置
+)
+
+// MinGitVersion 支持的最低 git 版本，稀疏检出的 cone 模式需要 2.25
+const MinGitVersion = "2.25.0"
+
+// Finding 一项诊断结果
+type Finding struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message,omitempty"`
+	Fix     string `json:"fix,omitempty"` // 修复建议
+}
+
+// Check 诊断项
+type Check struct {
+	Name string
+	Run  func() Finding
+}
+
+// OK 通过
+func OK(format string, a ...interface{}) Finding {
+	return Finding{Status: StatusOK, Message: fmt.Sprintf(format, a...)}
+}
+
+// Warn 警告及修复建议
+func Warn(message, fix string) Finding {
+	return Finding{Status: StatusWarn, Message: message, Fix: fix}
+}
+
+// Fail 失败及修复建议
+func Fail(message, fix string) Finding {
+	return Finding{Status: StatusFail, Message: message, Fix: fix}
+}
+
+// Skip 不适用
+func Skip(message string) Finding {
+	return Finding{Status: StatusSkip, Message: message}
+}
+
+// Run 依次执行诊断项
+func Run(checks []Check) []Finding {
+	findings := make([]Finding, 0, len(checks))
+	for _, c := range checks {
+		f := c.Run()
+		f.Name = c.Name
+		findings = append(findings, f)
+	}
+	return findings
+}
+
+// Failed 失败的诊断项数量
+func Failed(findings []Finding) int {
+	n := 0
+	for _, f := range findings {
+		if f.Status == StatusFail {
+			n++
+		}
+	}
+	return n
+}
+
+var versionReg = regexp.MustCompile(`(\d+)\.(\d+)(?:\.(\d+))?`)
+
+// parseVersion 解析 git --version 输出中的版本号，如 git version 2.39.2.windows.1
+func parseVersion(s string) ([3]int, bool) {
+	var v [3]int
+	m := versionReg.FindStringSubmatch(s)
+	if m == nil {
+		return v, false
+	}
+	for i := range v {
+		v[i], _ = strconv.Atoi(m[i+1])
+	}
+	return v, true
+}
+
+func less(a, b [3]int) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+	return false
+}
+
+// GitVersion 检查 git 已安装且不低于 MinGitVersion
+func GitVersion(plus *git.Plus) Check {
+	return Check{Name: "git version", Run: func() Finding {
+		output, err := plus.Run("--version")
+		if err != nil {
+			return Fail(err.Error(), "install git "+MinGitVersion+" or later and make sure it is on PATH")
+		}
+		text := strings.TrimSpace(string(output))
+		v, ok := parseVersion(text)
+		if !ok {
+			return Warn("unrecognized version "+text, "")
+		}
+		min, _ := parseVersion(MinGitVersion)
+		if less(v, min) {
+			return Fail(text+" is older than "+MinGitVersion, "upgrade git to "+MinGitVersion+" or later")
+		}
+		return OK("%d.%d.%d", v[0], v[1], v[2])
+	}}
+}
+
+// Repository 检查工作目录位于完整克隆的 git 仓库中且存在发布使用的远程仓库
+func Repository(plus *git.Plus, remote string) Check {
+	return Check{Name: "git repository", Run: func() Finding {
+		if _, err := plus.Run("rev-parse", "--is-inside-work-tree"); err != nil {
+			if strings.Contains(err.Error(), "dubious ownership") {
+				return Fail(err.Error(), "git config --global --add safe.directory <repository>, or run as the owner of the repository")
+			}
+			return Fail(err.Error(), "run autoctl inside a git repository or pass --directory")
+		}
+		if output, err := plus.Run("rev-parse", "--is-shallow-repository"); err == nil && strings.TrimSpace(string(output)) == "true" {
+			return Warn("shallow clone, tags and history before the clone depth are missing",
+				"git fetch --unshallow --tags, or set fetch-depth: 0 on actions/checkout (GIT_DEPTH: 0 on GitLab CI)")
+		}
+		if remote == "" {
+			remote = "origin"
+		}
+		url, err := plus.RemoteURL(remote)
+		if err != nil || url == "" {
+			return Warn("remote "+remote+" is not configured, tags cannot be pushed", "git remote add "+remote+" <url>, or set release.remote")
+		}
+		return OK("remote %s %s", remote, url)
+	}}
+}
+
+// GitConfig 检查创建附注标签所需的提交者身份，CI 中也可以通过 GIT_COMMITTER_NAME、GIT_COMMITTER_EMAIL 设置
+func GitConfig(plus *git.Plus) Check {
+	return Check{Name: "git identity", Run: func() Finding {
+		identity := map[string]string{}
+		for _, pair := range [][2]string{{"user.name", "GIT_COMMITTER_NAME"}, {"user.email", "GIT_COMMITTER_EMAIL"}} {
+			key, env := pair[0], pair[1]
+			value := os.Getenv(env)
+			if value == "" {
+				output, _ := plus.Run("config", "--get", key)
+				value = strings.TrimSpace(string(output))
+			}
+			if value == "" {
+				return Fail(key+" is not set, annotated tags cannot be created",
+					fmt.Sprintf("git config %s <value>, or set %s in CI", key, env))
+			}
+			identity[key] = value
+		}
+		return OK("%s <%s>", identity["user.name"], identity["user.email"])
+	}}
+}
+
+// ci CI 平台及识别所用的环境变量
+var ci = []struct{ name, env string }{
+	{"GitHub Actions", "GITHUB_ACTIONS"},
+	{"GitLab CI", "GITLAB_CI"},
+	{"Jenkins", "JENKINS_URL"},
+	{"Azure Pipelines", "TF_BUILD"},
+	{"CircleCI", "CIRCLECI"},
+	{"Buildkite", "BUILDKITE"},
+	{"Bitbucket Pipelines", "BITBUCKET_BUILD_NUMBER"},
+	{"Drone", "DRONE"},
+	{"TeamCity", "TEAMCITY_VERSION"},
+}
+
+// DetectCI 当前所在的 CI 平台，不在 CI 中时返回空字符串，无法识别的平台返回 CI
+func DetectCI() string {
+	for _, c := range ci {
+		if os.Getenv(c.env) != "" {
+			return c.name
+		}
+	}
+	if os.Getenv("CI") != "" {
+		return "CI"
+	}
+	return ""
+}
+
+// CI 输出检测到的 CI 平台，CI 中没有访问令牌时给出提示
+func CI() Check {
+	return Check{Name: "ci", Run: func() Finding {
+		name := DetectCI()
+		switch {
+		case name == "":
+			return OK("not running in CI")
+		case name == "GitHub Actions" && os.Getenv("GITHUB_TOKEN") == "":
+			return Warn(name+", GITHUB_TOKEN is not exported", "add env: GITHUB_TOKEN: ${{ secrets.GITHUB_TOKEN }} to the release step")
+		}
+		return OK("%s", name)
+	}}
+}
+
+// Workspace 输出工作区中识别到的软件包
+func Workspace(dir string) Check {
+	return Check{Name: "workspace", Run: func() Finding {
+		packages, err := workspace.Discover(dir)
+		if err != nil {
+			return Fail(err.Error(), "fix the workspace manifest, or declare a detector in workspace.detectors")
+		}
+		if len(packages) == 0 {
+			return OK("single package")
+		}
+		list := make([]string, 0, len(packages))
+		for _, p := range packages {
+			name := p.Name
+			if name == "" {
+				name = p.Path
+			}
+			list = append(list, fmt.Sprintf("%s %s", p.Type, name))
+		}
+		return OK("%d packages: %s", len(packages), strings.Join(list, ", "))
+	}}
+}
+
+// token 访问令牌及其来源
+func token(cfg provider.Config, key string) (string, string) {
+	if cfg.Token != "" {
+		return cfg.Token, key + ".token"
+	}
+	env := "GITHUB_TOKEN"
+	if strings.EqualFold(cfg.Type, provider.GitLab) {
+		env = "GITLAB_TOKEN"
+	}
+	return os.Getenv(env), env
+}
+
+// requiredScopes 创建标签、版本发布及合并请求所需的权限范围，满足其一即可
+var requiredScopes = map[string][]string{
+	provider.GitHub: {"repo", "public_repo"},
+	provider.GitLab: {"api"},
+}
+
+// Credentials 检查代码托管平台的访问令牌已设置，并列出其权限范围，key 为配置节点，如 provider、gitops[0].provider
+func Credentials(key string, cfg provider.Config) Check {
+	return Check{Name: key + " credentials", Run: func() Finding {
+		if cfg.Type == "" && cfg.Repo == "" {
+			return Skip(key + " is not configured")
+		}
+		value, source := token(cfg, key)
+		if value == "" {
+			return Fail("token is not set", fmt.Sprintf("set %s.token or the %s environment variable", key, source))
+		}
+		cfg.Token = value
+		p, err := provider.New(cfg)
+		if err != nil {
+			return Fail(err.Error(), fmt.Sprintf("set %s.type and %s.repo", key, key))
+		}
+		s, ok := p.(provider.TokenScopes)
+		if !ok {
+			return OK("token from %s", source)
+		}
+		scopes, err := s.Scopes()
+		if err != nil {
+			return Warn(fmt.Sprintf("token from %s, scopes unknown: %s", source, err), "")
+		}
+		if len(scopes) == 0 {
+			return OK("token from %s, fine-grained or installation token", source)
+		}
+		for _, want := range requiredScopes[p.Name()] {
+			for _, scope := range scopes {
+				if scope == want {
+					return OK("token from %s, scopes %s", source, strings.Join(scopes, ", "))
+				}
+			}
+		}
+		return Warn(fmt.Sprintf("token from %s has scopes %s", source, strings.Join(scopes, ", ")),
+			fmt.Sprintf("grant the %s scope to create releases and pull requests", strings.Join(requiredScopes[p.Name()], " or ")))
+	}}
+}
+
+// Connectivity 检查可以访问代码托管平台的接口且令牌对仓库有写权限
+func Connectivity(key string, cfg provider.Config) Check {
+	return Check{Name: key + " connectivity", Run: func() Finding {
+		if cfg.Type == "" && cfg.Repo == "" {
+			return Skip(key + " is not configured")
+		}
+		if value, _ := token(cfg, key); value == "" {
+			return Skip("token is not set")
+		}
+		p, err := provider.New(cfg)
+		if err != nil {
+			return Fail(err.Error(), fmt.Sprintf("set %s.type and %s.repo", key, key))
+		}
+		err = p.Verify()
+		if err == nil {
+			return OK("%s %s", p.Name(), cfg.Repo)
+		}
+		var status *provider.StatusError
+		switch {
+		case errors.As(err, &status) && status.StatusCode == http.StatusUnauthorized:
+			return Fail(err.Error(), "the token is invalid or expired, create a new one")
+		case errors.As(err, &status) && status.StatusCode == http.StatusNotFound:
+			return Fail(err.Error(), fmt.Sprintf("check %s.repo and that the token can access the repository", key))
+		case errors.As(err, &status) && status.StatusCode == http.StatusForbidden, strings.Contains(err.Error(), "token"):
+			return Fail(err.Error(), "grant the token write access to the repository")
+		default:
+			return Fail(err.Error(), fmt.Sprintf("check %s.url, the network and the proxy settings in http", key))
+		}
+	}}
+}
@@ -0,0 +1,79 @@
+package doctor
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/provider"
+)
+
+func TestParseVersion(t *testing.T) {
+	min, _ := parseVersion(MinGitVersion)
+	for text, old := range map[string]bool{
+		"git version 2.39.2.windows.1":         false,
+		"git version 2.37.1 (Apple Git-137.1)": false,
+		"git version 2.17.1":                   true,
+	} {
+		v, ok := parseVersion(text)
+		if !ok || less(v, min) != old {
+			t.Errorf("expected %q older than %s %v, but %v got", text, MinGitVersion, old, v)
+		}
+	}
+}
+
+func TestDetectCI(t *testing.T) {
+	for _, c := range ci {
+		t.Setenv(c.env, "")
+	}
+	t.Setenv("CI", "")
+	if name := DetectCI(); name != "" {
+		t.Errorf("expected no CI, but %s got", name)
+	}
+	t.Setenv("CI", "true")
+	t.Setenv("GITLAB_CI", "true")
+	if name := DetectCI(); name != "GitLab CI" {
+		t.Errorf("expected GitLab CI, but %s got", name)
+	}
+}
+
+func TestProvider(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/rate_limit":
+			w.Header().Set("X-OAuth-Scopes", "read:org")
+			_, _ = w.Write([]byte(`{}`))
+		case "/repos/a/b":
+			_, _ = w.Write([]byte(`{"permissions":{"push":true}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	cfg := provider.Config{Type: provider.GitHub, URL: server.URL, Repo: "a/b", Token: "token"}
+	findings := Run([]Check{Credentials("provider", cfg), Connectivity("provider", cfg)})
+	if f := findings[0]; f.Status != StatusWarn || !strings.Contains(f.Fix, "repo") {
+		t.Errorf("expected missing repo scope warned, but %+v got", f)
+	}
+	if f := findings[1]; f.Status != StatusOK || f.Name != "provider connectivity" {
+		t.Errorf("expected provider reachable, but %+v got", f)
+	}
+
+	cfg.Token = "expired"
+	if f := Run([]Check{Connectivity("provider", cfg)})[0]; f.Status != StatusFail || !strings.Contains(f.Fix, "expired") {
+		t.Errorf("expected expired token reported, but %+v got", f)
+	}
+	cfg.Repo, cfg.Token = "a/missing", "token"
+	if f := Run([]Check{Connectivity("provider", cfg)})[0]; f.Status != StatusFail || !strings.Contains(f.Fix, "provider.repo") {
+		t.Errorf("expected missing repository reported, but %+v got", f)
+	}
+	if findings = Run([]Check{Credentials("provider", provider.Config{})}); findings[0].Status != StatusSkip {
+		t.Errorf("expected unconfigured provider skipped, but %+v got", findings[0])
+	}
+}
@@ -0,0 +1,231 @@
+package environments
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/fileutil"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/tags"
+)
+
+// DefaultFile 部署记录文件，提交到仓库后所有流水线共享同一部署记录
+const DefaultFile = ".autoctl/environments.json"
+
+// 部署记录来源
+const (
+	SourceFile     = "file"     // 仓库中的部署记录文件
+	SourceProvider = "provider" // 代码托管平台的部署接口（GitHub deployments、GitLab deployments）
+)
+
+// Config 各环境部署的版本，对应配置文件 environments 节点
+type Config struct {
+	Names  []string `mapstructure:"names"`  // 环境名称，按晋级顺序排列，如 dev、staging、prod，为空时不限制
+	Source string   `mapstructure:"source"` // 部署记录来源 file | provider，默认 file
+	File   string   `mapstructure:"file"`   // 部署记录文件，相对路径基于工作目录，默认 .autoctl/environments.json
+}
+
+// Path 部署记录文件的路径
+func (c Config) Path(cwd string) string {
+	name := c.File
+	if name == "" {
+		name = DefaultFile
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(cwd, name)
+	}
+	return name
+}
+
+// Validate 检查环境名称，配置了 names 时只允许其中的环境
+func (c Config) Validate(env string) error {
+	if env == "" {
+		return errors.New("environment is required")
+	}
+	if len(c.Names) == 0 {
+		return nil
+	}
+	for _, name := range c.Names {
+		if name == env {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown environment %q, configured environments: %s", env, strings.Join(c.Names, ", "))
+}
+
+// Deployment 环境中部署的版本
+type Deployment struct {
+	Environment string    `json:"environment"`
+	Tag         string    `json:"tag"`              // 部署的版本标签
+	Commit      string    `json:"commit,omitempty"` // 标签指向的提交
+	By          string    `json:"by,omitempty"`     // 部署人
+	Time        time.Time `json:"time"`             // 部署时间
+	URL         string    `json:"url,omitempty"`    // 环境地址或平台上的部署地址
+}
+
+// Store 部署记录
+type Store interface {
+	// Get 查询环境中部署的版本，未部署时返回 nil
+	Get(env string) (*Deployment, error)
+	// Set 记录环境中部署的版本
+	Set(d *Deployment) error
+}
+
+// New 按配置的来源创建部署记录
+func New(cfg Config, cwd string, p provider.Config) (Store, error) {
+	switch cfg.Source {
+	case "", SourceFile:
+		return &fileStore{name: cfg.Path(cwd)}, nil
+	case SourceProvider:
+		client, err := provider.New(p)
+		if err != nil {
+			return nil, err
+		}
+		tracker, ok := client.(provider.DeploymentTracker)
+		if !ok {
+			return nil, fmt.Errorf("provider %s does not support deployments", client.Name())
+		}
+		return &providerStore{tracker: tracker}, nil
+	default:
+		return nil, fmt.Errorf("invalid environments source %q, valid values are %s|%s", cfg.Source, SourceFile, SourceProvider)
+	}
+}
+
+// fileStore 以环境名称为键的 JSON 文件
+type fileStore struct {
+	name string
+}
+
+func (s *fileStore) load() (map[string]*Deployment, error) {
+	all := map[string]*Deployment{}
+	data, err := os.ReadFile(s.name)
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("%s: %w", s.name, err)
+	}
+	for env, d := range all {
+		d.Environment = env
+	}
+	return all, nil
+}
+
+func (s *fileStore) Get(env string) (*Deployment, error) {
+	all, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+	return all[env], nil
+}
+
+func (s *fileStore) Set(d *Deployment) error {
+	all, err := s.load()
+	if err != nil {
+		return err
+	}
+	all[d.Environment] = d
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(s.name), 0o755); err != nil {
+		return err
+	}
+	return fileutil.WriteFile(s.name, append(data, '\n'), 0o644)
+}
+
+// providerStore 代码托管平台上的部署
+type providerStore struct {
+	tracker provider.DeploymentTracker
+}
+
+func (s *providerStore) Get(env string) (*Deployment, error) {
+	d, err := s.tracker.LatestDeployment(env)
+	if err != nil || d == nil {
+		return nil, err
+	}
+	return &Deployment{Environment: env, Tag: d.Ref, Commit: d.SHA, By: d.Creator, Time: d.CreatedAt, URL: d.URL}, nil
+}
+
+func (s *providerStore) Set(d *Deployment) error {
+	_, err := s.tracker.CreateDeployment(&provider.Deployment{Environment: d.Environment, Ref: d.Tag, SHA: d.Commit})
+	return err
+}
+
+// Drift 环境部署的版本与之后发布的版本
+type Drift struct {
+	Environment string      `json:"environment"`
+	Deployed    *Deployment `json:"deployed,omitempty"` // 未部署时为空
+	Behind      []string    `json:"behind,omitempty"`   // 部署版本之后发布的正式版本标签，从新到旧
+}
+
+// Current 环境是否已部署最新的正式版本
+func (d Drift) Current() bool {
+	return d.Deployed != nil && len(d.Behind) == 0
+}
+
+// Compare 对比环境部署的版本与 list 中的版本，list 按版本号从新到旧排列；
+// 部署的标签不在 list 中时视为落后于全部正式版本
+func Compare(env string, deployed *Deployment, list []tags.Tag) Drift {
+	drift := Drift{Environment: env, Deployed: deployed}
+	for _, t := range list {
+		if deployed != nil && t.Name == deployed.Tag {
+			break
+		}
+		if !t.Prerelease() {
+			drift.Behind = append(drift.Behind, t.Name)
+		}
+	}
+	return drift
+}
+
+// Between 版本标签 from 之后到 to（含）之间发布的正式版本，list 按版本号从新到旧排列，to 不晚于 from 时为空
+func Between(from, to string, list []tags.Tag) []string {
+	if from == to {
+		return nil
+	}
+	var res []string
+	in := false
+	for _, t := range list {
+		switch t.Name {
+		case to:
+			in = true
+		case from:
+			return res
+		}
+		if in && (!t.Prerelease() || t.Name == to) {
+			res = append(res, t.Name)
+		}
+	}
+	if !in {
+		return nil
+	}
+	return res
+}
+
+// Names 配置的环境，未配置时为部署记录文件中的全部环境，按名称排序
+func Names(cfg Config, cwd string) ([]string, error) {
+	if len(cfg.Names) > 0 || cfg.Source == SourceProvider {
+		return cfg.Names, nil
+	}
+	all, err := (&fileStore{name: cfg.Path(cwd)}).load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(all))
+	for name := range all {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
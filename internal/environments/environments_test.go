@@ -0,0 +1,68 @@
+package environments
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/tags"
+)
+
+func TestFileStore(t *testing.T) {
+	cwd := t.TempDir()
+	store, err := New(Config{}, cwd, provider.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d, err := store.Get("prod"); err != nil || d != nil {
+		t.Fatalf("expected no deployment, but %+v %v got", d, err)
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	for _, d := range []*Deployment{
+		{Environment: "staging", Tag: "v1.2.0", Time: now},
+		{Environment: "prod", Tag: "v1.1.0", Time: now},
+		{Environment: "staging", Tag: "v1.3.0", Time: now},
+	} {
+		if err = store.Set(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	d, err := store.Get("staging")
+	if err != nil || d.Tag != "v1.3.0" || d.Environment != "staging" {
+		t.Errorf("expected staging at v1.3.0, but %+v %v got", d, err)
+	}
+	names, err := Names(Config{}, cwd)
+	if err != nil || !reflect.DeepEqual(names, []string{"prod", "staging"}) {
+		t.Errorf("unexpected environments %v %v", names, err)
+	}
+	if err = (Config{Names: []string{"dev", "prod"}}).Validate("staging"); err == nil {
+		t.Error("expected unknown environment rejected")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	list := []tags.Tag{
+		{Name: "v1.3.0", Channel: tags.Stable},
+		{Name: "v1.3.0-rc.1", Channel: "rc"},
+		{Name: "v1.2.0", Channel: tags.Stable},
+		{Name: "v1.1.0", Channel: tags.Stable},
+	}
+	drift := Compare("prod", &Deployment{Tag: "v1.1.0"}, list)
+	if !reflect.DeepEqual(drift.Behind, []string{"v1.3.0", "v1.2.0"}) || drift.Current() {
+		t.Errorf("unexpected drift %+v", drift)
+	}
+	if drift = Compare("staging", &Deployment{Tag: "v1.3.0"}, list); !drift.Current() {
+		t.Errorf("expected staging up to date, but %+v got", drift)
+	}
+	if drift = Compare("dev", nil, list); drift.Current() || len(drift.Behind) != 3 {
+		t.Errorf("expected undeployed environment behind all releases, but %+v got", drift)
+	}
+
+	if got := Between("v1.1.0", "v1.3.0-rc.1", list); !reflect.DeepEqual(got, []string{"v1.3.0-rc.1", "v1.2.0"}) {
+		t.Errorf("unexpected releases between %v", got)
+	}
+	if got := Between("v1.3.0", "v1.2.0", list); got != nil {
+		t.Errorf("expected no releases when the target is older, but %v got", got)
+	}
+}
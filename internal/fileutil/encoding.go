@@ -0,0 +1,50 @@
+package fileutil
+
+import (
+	"bytes"
+	"errors"
+)
+
+var (
+	utf8BOM    = []byte{0xEF, 0xBB, 0xBF}
+	utf16LEBOM = []byte{0xFF, 0xFE}
+	utf16BEBOM = []byte{0xFE, 0xFF}
+)
+
+// ErrUnsupportedEncoding 无法安全编辑的文件编码
+var ErrUnsupportedEncoding = errors.New("unsupported encoding utf-16, convert the file to utf-8")
+
+// Encoding 文件的 BOM 及换行符，编辑时统一为无 BOM 及 LF，写回时还原
+type Encoding struct {
+	BOM  bool // 是否以 UTF-8 BOM 开头
+	CRLF bool // 是否使用 CRLF 换行
+}
+
+// Decode 去除 UTF-8 BOM 并将 CRLF 转换为 LF，换行符混用时以数量多的为准
+func Decode(data []byte) ([]byte, Encoding, error) {
+	var enc Encoding
+	if bytes.HasPrefix(data, utf16LEBOM) || bytes.HasPrefix(data, utf16BEBOM) {
+		return nil, enc, ErrUnsupportedEncoding
+	}
+	if bytes.HasPrefix(data, utf8BOM) {
+		enc.BOM = true
+		data = data[len(utf8BOM):]
+	}
+	crlf := bytes.Count(data, []byte("\r\n"))
+	if crlf > 0 && crlf*2 >= bytes.Count(data, []byte("\n")) {
+		enc.CRLF = true
+		data = bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	}
+	return data, enc, nil
+}
+
+// Encode 还原 BOM 及换行符
+func (e Encoding) Encode(data []byte) []byte {
+	if e.CRLF {
+		data = bytes.ReplaceAll(bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n")), []byte("\n"), []byte("\r\n"))
+	}
+	if e.BOM {
+		data = append(append([]byte{}, utf8BOM...), data...)
+	}
+	return data
+}
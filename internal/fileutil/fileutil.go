@@ -0,0 +1,72 @@
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WriteFile 先写入同目录下的临时文件再重命名，中断时不会留下写了一半的文件；文件已存在时保留其权限
+func WriteFile(name string, data []byte, perm os.FileMode) (err error) {
+	if info, err := os.Stat(name); err == nil {
+		perm = info.Mode().Perm()
+	}
+	dir, base := filepath.Split(name)
+	if dir == "" {
+		dir = "."
+	}
+	tmp, err := os.CreateTemp(dir, "."+base+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Chmod(tmp.Name(), perm); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// Update 读取文件并以 LF 换行、无 BOM 的内容调用 fn，变更时还原原有编码后原子写回，返回文件是否变更
+func Update(name string, fn func(data []byte) ([]byte, bool, error)) (bool, error) {
+	raw, err := os.ReadFile(name)
+	if err != nil {
+		return false, err
+	}
+	data, enc, err := Decode(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", name, err)
+	}
+	updated, changed, err := fn(data)
+	if err != nil || !changed {
+		return false, err
+	}
+	return true, WriteFile(name, enc.Encode(updated), 0o644)
+}
+
+// Join 将 / 分隔的相对路径拼接到 root 下，路径为绝对路径或超出 root 时返回错误
+func Join(root, rel string) (string, error) {
+	p := filepath.FromSlash(rel)
+	if filepath.IsAbs(p) || filepath.VolumeName(p) != "" || strings.HasPrefix(rel, "/") {
+		return "", fmt.Errorf("path %s must be relative", rel)
+	}
+	p = filepath.Clean(p)
+	if p == ".." || strings.HasPrefix(p, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("path %s is outside of %s", rel, root)
+	}
+	return filepath.Join(root, p), nil
+}
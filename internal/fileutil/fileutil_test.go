@@ -0,0 +1,63 @@
+package fileutil
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDecode(t *testing.T) {
+	raw := []byte("\xEF\xBB\xBFa: 1\r\nb: 2\r\n")
+	data, enc, err := Decode(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "a: 1\nb: 2\n" || !enc.BOM || !enc.CRLF {
+		t.Fatalf("unexpected %q %+v", data, enc)
+	}
+	if actual := enc.Encode(append(data, "c: 3\n"...)); !bytes.Equal(actual, append(raw, "c: 3\r\n"...)) {
+		t.Errorf("expected BOM and CRLF restored, but %q got", actual)
+	}
+	if _, enc, _ = Decode([]byte("a\nb\nc\r\n")); enc.CRLF {
+		t.Error("expected LF for mostly LF file")
+	}
+	if _, _, err = Decode([]byte("\xFF\xFEa\x00")); err != ErrUnsupportedEncoding {
+		t.Errorf("expected unsupported encoding, but %v got", err)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "values.yaml")
+	if err := os.WriteFile(file, []byte("tag: 1.0.0\r\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	changed, err := Update(file, func(data []byte) ([]byte, bool, error) {
+		return bytes.Replace(data, []byte("1.0.0"), []byte("1.1.0"), 1), true, nil
+	})
+	if err != nil || !changed {
+		t.Fatalf("expected changed, but %v %v got", changed, err)
+	}
+	if data, _ := os.ReadFile(file); string(data) != "tag: 1.1.0\r\n" {
+		t.Errorf("expected CRLF preserved, but %q got", data)
+	}
+	if info, _ := os.Stat(file); info.Mode().Perm() != 0o600 {
+		t.Errorf("expected mode preserved, but %s got", info.Mode())
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected no temporary file left, but %d entries got", len(entries))
+	}
+}
+
+func TestJoin(t *testing.T) {
+	root := filepath.FromSlash("/repo")
+	if p, err := Join(root, "deploy/values.yaml"); err != nil || p != filepath.Join(root, "deploy", "values.yaml") {
+		t.Errorf("unexpected %s %v", p, err)
+	}
+	for _, rel := range []string{"/etc/passwd", "../secret", "deploy/../../secret"} {
+		if _, err := Join(root, rel); err == nil {
+			t.Errorf("Join(%q) expected error, got nil", rel)
+		}
+	}
+}
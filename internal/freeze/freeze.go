@@ -0,0 +1,116 @@
+package freeze
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/fileutil"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// DefaultFile 冻结状态文件，提交到仓库后所有流水线共享同一冻结状态
+const DefaultFile = ".autoctl/freeze.json"
+
+// Config 代码冻结，对应配置文件 release.freeze 节点
+type Config struct {
+	File string `mapstructure:"file"` // 冻结状态文件，相对路径基于工作目录，默认 .autoctl/freeze.json
+}
+
+// Path 冻结状态文件的路径
+func (c Config) Path(cwd string) string {
+	name := c.File
+	if name == "" {
+		name = DefaultFile
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(cwd, name)
+	}
+	return name
+}
+
+// State 冻结窗口，冻结期间只允许发布 patch 版本
+type State struct {
+	Since  time.Time  `json:"since"`           // 开始时间
+	Until  *time.Time `json:"until,omitempty"` // 结束时间，为空时一直冻结到解冻
+	Reason string     `json:"reason,omitempty"`
+	By     string     `json:"by,omitempty"` // 冻结人
+}
+
+// Active 指定时间是否处于冻结窗口内
+func (s *State) Active(now time.Time) bool {
+	if s == nil || now.Before(s.Since) {
+		return false
+	}
+	return s.Until == nil || now.Before(*s.Until)
+}
+
+// String 冻结窗口说明，如 code freeze until 2024-12-31 15:00 UTC: year-end
+func (s *State) String() string {
+	msg := "code freeze"
+	if s.Until != nil {
+		msg += " until " + s.Until.UTC().Format("2006-01-02 15:04 UTC")
+	}
+	if s.Reason != "" {
+		msg += ": " + s.Reason
+	}
+	return msg
+}
+
+// Allows 冻结期间是否允许从 current 发布 next，只允许主版本号及次版本号不变的 patch 版本（含预发布版本），
+// 首次发布不允许
+func Allows(current, next semver.Semver) bool {
+	if current == nil {
+		return false
+	}
+	d := semver.Distance(current, next)
+	return d.Major == 0 && d.Minor == 0
+}
+
+// Check 冻结期间发布 patch 以外的版本时返回错误
+func (s *State) Check(now time.Time, current, next semver.Semver) error {
+	if !s.Active(now) || Allows(current, next) {
+		return nil
+	}
+	return fmt.Errorf("release %s is blocked by the %s, only patch releases are allowed, run 'autoctl thaw' to lift it", next, s)
+}
+
+// Load 读取冻结状态，文件不存在时返回 nil
+func Load(name string) (*State, error) {
+	data, err := os.ReadFile(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	s := &State{}
+	if err = json.Unmarshal(data, s); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return s, nil
+}
+
+// Save 写入冻结状态
+func Save(name string, s *State) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+	return fileutil.WriteFile(name, append(data, '\n'), 0o644)
+}
+
+// Remove 删除冻结状态，未冻结时返回 false
+func Remove(name string) (bool, error) {
+	err := os.Remove(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
@@ -0,0 +1,69 @@
+package freeze
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+func version(t *testing.T, s string) semver.Semver {
+	v, err := semver.Version(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestState_Check(t *testing.T) {
+	now := time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC)
+	until := now.Add(24 * time.Hour)
+	s := &State{Since: now.Add(-time.Hour), Until: &until, Reason: "year-end"}
+	current := version(t, "1.2.3")
+	if err := s.Check(now, current, version(t, "1.2.4")); err != nil {
+		t.Errorf("expected patch release allowed, but %v got", err)
+	}
+	if err := s.Check(now, current, version(t, "1.2.4-rc.0")); err != nil {
+		t.Errorf("expected patch prerelease allowed, but %v got", err)
+	}
+	err := s.Check(now, current, version(t, "1.3.0"))
+	if err == nil || !strings.Contains(err.Error(), "code freeze until 2024-12-21 00:00 UTC: year-end") {
+		t.Errorf("expected minor release blocked, but %v got", err)
+	}
+	if err = s.Check(now.Add(48*time.Hour), current, version(t, "2.0.0")); err != nil {
+		t.Errorf("expected release allowed after the window, but %v got", err)
+	}
+	if err = s.Check(now, nil, version(t, "0.0.1")); err == nil {
+		t.Error("expected first release blocked")
+	}
+	var none *State
+	if err = none.Check(now, current, version(t, "2.0.0")); err != nil {
+		t.Errorf("expected release allowed without freeze, but %v got", err)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	name := Config{}.Path(t.TempDir())
+	if s, err := Load(name); err != nil || s != nil {
+		t.Fatalf("expected no freeze, but %v %v got", s, err)
+	}
+	since := time.Date(2024, 12, 20, 0, 0, 0, 0, time.UTC)
+	if err := Save(name, &State{Since: since, Reason: "release candidate"}); err != nil {
+		t.Fatal(err)
+	}
+	s, err := Load(name)
+	if err != nil || !s.Since.Equal(since) || s.Reason != "release candidate" || s.Until != nil {
+		t.Fatalf("unexpected state %+v %v", s, err)
+	}
+	if ok, err := Remove(name); !ok || err != nil {
+		t.Errorf("expected freeze removed, but %v %v got", ok, err)
+	}
+	if ok, err := Remove(name); ok || err != nil {
+		t.Errorf("expected nothing to remove, but %v %v got", ok, err)
+	}
+	if filepath.Base(name) != "freeze.json" {
+		t.Errorf("unexpected default file %s", name)
+	}
+}
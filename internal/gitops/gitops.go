@@ -0,0 +1,255 @@
+package gitops
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/coffee377/autoctl/internal/fileutil"
+	"github.com/coffee377/autoctl/internal/progress"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/registry"
+	"github.com/coffee377/autoctl/internal/tmpl"
+	"github.com/coffee377/autoctl/internal/yamledit"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+const (
+	DefaultBase    = "main"
+	DefaultBranch  = "autoctl/deploy-{{ .Name }}-{{ .Tag }}"
+	DefaultValue   = "{{ .Version }}"
+	PinnedValue    = "{{ .Version }}@{{ .Digest }}"
+	DefaultMessage = "chore(deploy): bump {{ .Name }} to {{ .Version }}"
+)
+
+// File 需要更新的 YAML 文件，如 Helm values.yaml 或 Argo CD / Flux 资源清单
+type File struct {
+	Path  string `mapstructure:"path"`  // 相对于部署仓库根目录的路径
+	Key   string `mapstructure:"key"`   // YAML 路径，如 image.tag、images[name=app].newTag
+	Value string `mapstructure:"value"` // 新值模板，默认 {{ .Version }}
+}
+
+// Target 部署仓库，对应配置文件 gitops 节点中的一项
+type Target struct {
+	Name     string          `mapstructure:"name"`     // 应用名称，默认为部署仓库地址
+	Repo     string          `mapstructure:"repo"`     // 部署仓库克隆地址
+	Base     string          `mapstructure:"base"`     // 目标分支，默认 main
+	Branch   string          `mapstructure:"branch"`   // 合并请求的源分支模板
+	Direct   bool            `mapstructure:"direct"`   // 直接推送到目标分支，不创建合并请求
+	Message  string          `mapstructure:"message"`  // 提交信息及合并请求标题模板
+	Files    []File          `mapstructure:"files"`    // 需要更新的文件
+	Provider provider.Config `mapstructure:"provider"` // 部署仓库所在平台，用于创建合并请求
+	Image    string          `mapstructure:"image"`    // 部署的镜像模板，如 ghcr.io/coffee377/app:{{ .Version }}，设置后提交前检查镜像仓库中存在该镜像，模板中可通过 {{ .Digest }} 使用其摘要
+	Pin      bool            `mapstructure:"pin"`      // 固定镜像摘要，文件新值模板默认为 {{ .Version }}@{{ .Digest }}
+	Registry registry.Config `mapstructure:"registry"` // 镜像仓库访问配置
+}
+
+// Data 模板数据
+type Data struct {
+	Name        string
+	Version     string
+	Previous    string
+	Tag         string
+	Commit      string
+	BuildNumber int64  // 单调递增的构建号，未启用 release.buildNumber 时为 0
+	Image       string // 部署的镜像，未配置 image 时为空
+	Digest      string // 部署的镜像摘要，如 sha256:...
+}
+
+// Result 部署仓库的更新结果
+type Result struct {
+	Target      string                `json:"target"`
+	Branch      string                `json:"branch"`
+	Changed     bool                  `json:"changed"` // 文件中的值已是目标值时为 false
+	PullRequest *provider.PullRequest `json:"pullRequest,omitempty"`
+}
+
+func (t Target) withDefaults() Target {
+	if t.Name == "" {
+		t.Name = t.Repo
+	}
+	if t.Base == "" {
+		t.Base = DefaultBase
+	}
+	if t.Branch == "" {
+		t.Branch = DefaultBranch
+	}
+	if t.Message == "" {
+		t.Message = DefaultMessage
+	}
+	return t
+}
+
+// Apply 克隆部署仓库，更新文件中的版本并提交，按配置直接推送或创建合并请求
+func Apply(target Target, data Data) (*Result, error) {
+	if len(target.Files) == 0 {
+		return nil, fmt.Errorf("gitops %s: repo and files are required", target.withDefaults().Name)
+	}
+	if target.Image != "" {
+		if err := resolveImage(target.withDefaults(), &data); err != nil {
+			return nil, err
+		}
+	}
+	return Commit(target, data, func(dir string) ([]string, error) {
+		paths := make([]string, 0, len(target.Files))
+		for _, file := range target.Files {
+			if file.Value == "" && target.Pin {
+				file.Value = PinnedValue
+			}
+			changed, err := update(dir, file, data)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", file.Path, err)
+			}
+			if changed {
+				paths = append(paths, file.Path)
+			}
+		}
+		return paths, nil
+	})
+}
+
+// WriteFiles 将文件内容写入仓库并提交，内容未变化的文件不提交，用于 Homebrew 公式等整体生成的文件
+func WriteFiles(target Target, data Data, files map[string][]byte) (*Result, error) {
+	return Commit(target, data, func(dir string) ([]string, error) {
+		paths := make([]string, 0, len(files))
+		for name, content := range files {
+			path, err := fileutil.Join(dir, name)
+			if err != nil {
+				return nil, err
+			}
+			if old, err := os.ReadFile(path); err == nil && bytes.Equal(old, content) {
+				continue
+			}
+			if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				return nil, err
+			}
+			if err = os.WriteFile(path, content, 0o644); err != nil {
+				return nil, err
+			}
+			paths = append(paths, name)
+		}
+		sort.Strings(paths)
+		return paths, nil
+	})
+}
+
+// Commit 克隆仓库，由 edit 修改文件并返回变更的路径，提交后按配置直接推送或创建合并请求
+func Commit(target Target, data Data, edit func(dir string) ([]string, error)) (*Result, error) {
+	target = target.withDefaults()
+	if target.Repo == "" {
+		return nil, fmt.Errorf("gitops %s: repo is required", target.Name)
+	}
+	data.Name = target.Name
+	message, err := render(target.Message, data)
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Target: target.Name, Branch: target.Base}
+	if !target.Direct {
+		if res.Branch, err = render(target.Branch, data); err != nil {
+			return nil, err
+		}
+	}
+
+	dir, err := os.MkdirTemp("", "autoctl-gitops-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	plus := &git.Plus{Cwd: dir}
+	task := progress.Start("clone %s", log.Redact(target.Repo))
+	err = plus.Clone(target.Repo, dir, target.Base, 1)
+	task.Stop()
+	if err != nil {
+		return nil, err
+	}
+	if !target.Direct {
+		if err = plus.CreateBranch(res.Branch); err != nil {
+			return nil, err
+		}
+	}
+
+	paths, err := edit(dir)
+	if err != nil {
+		return nil, fmt.Errorf("gitops %s: %w", target.Name, err)
+	}
+	if len(paths) == 0 {
+		log.Info("gitops %s: already at %s", target.Name, data.Version)
+		return res, nil
+	}
+	res.Changed = true
+	if err = plus.Commit(message, paths...); err != nil {
+		return nil, err
+	}
+	// 源分支由 autoctl 独占，重复执行时强制覆盖
+	if err = plus.Push("origin", "HEAD:refs/heads/"+res.Branch, !target.Direct); err != nil {
+		return nil, err
+	}
+	log.Info("gitops %s: pushed %s", target.Name, res.Branch)
+	if target.Direct {
+		return res, nil
+	}
+
+	p, err := provider.New(target.Provider)
+	if err != nil {
+		return nil, err
+	}
+	body := fmt.Sprintf("Bump %s from %s to %s.\n\nCommit: %s", target.Name, data.Previous, data.Version, data.Commit)
+	if data.Previous == "" {
+		body = fmt.Sprintf("Deploy %s %s.\n\nCommit: %s", target.Name, data.Version, data.Commit)
+	}
+	res.PullRequest, err = p.CreatePullRequest(&provider.PullRequest{Title: message, Body: body, Head: res.Branch, Base: target.Base})
+	if err != nil {
+		return nil, fmt.Errorf("gitops %s: create pull request: %w", target.Name, err)
+	}
+	log.Info("gitops %s: opened %s", target.Name, res.PullRequest.URL)
+	return res, nil
+}
+
+// resolveImage 检查镜像仓库中存在部署的镜像并查询其摘要，不存在时不修改部署仓库
+func resolveImage(target Target, data *Data) error {
+	data.Name = target.Name
+	image, err := render(target.Image, *data)
+	if err != nil {
+		return err
+	}
+	ref, err := registry.ParseReference(image)
+	if err != nil {
+		return fmt.Errorf("gitops %s: %w", target.Name, err)
+	}
+	client, err := registry.New(target.Registry)
+	if err != nil {
+		return err
+	}
+	if data.Digest, err = client.Digest(ref); err != nil {
+		return fmt.Errorf("gitops %s: verify image %s: %w", target.Name, ref, err)
+	}
+	data.Image = ref.String()
+	log.Info("gitops %s: image %s@%s", target.Name, ref, data.Digest)
+	return nil
+}
+
+func update(dir string, file File, data Data) (bool, error) {
+	tpl := file.Value
+	if tpl == "" {
+		tpl = DefaultValue
+	}
+	value, err := render(tpl, data)
+	if err != nil {
+		return false, err
+	}
+	path, err := fileutil.Join(dir, file.Path)
+	if err != nil {
+		return false, err
+	}
+	return fileutil.Update(path, func(content []byte) ([]byte, bool, error) {
+		return yamledit.Set(content, file.Key, value)
+	})
+}
+
+func render(text string, data Data) (string, error) {
+	return tmpl.Render("gitops", text, data)
+}
@@ -0,0 +1,138 @@
+package gitops
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/registry"
+	"github.com/coffee377/autoctl/internal/testutil"
+)
+
+// gitRun 执行 git 命令并返回去除首尾空白的输出
+func gitRun(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	return strings.TrimSpace(testutil.GitRun(t, dir, args...))
+}
+
+// newDeployRepo 创建包含 values.yaml 的裸仓库作为部署仓库
+func newDeployRepo(t *testing.T) string {
+	testutil.GitIdentity(t)
+	root := t.TempDir()
+	remote := filepath.Join(root, "deploy.git")
+	work := filepath.Join(root, "work")
+	gitRun(t, root, "init", "--quiet", "--bare", "--initial-branch=main", remote)
+	gitRun(t, root, "clone", "--quiet", remote, work)
+	_ = os.WriteFile(filepath.Join(work, "values.yaml"), []byte("image:\n  tag: 1.0.0\n"), 0o644)
+	gitRun(t, work, "add", ".")
+	gitRun(t, work, "commit", "--quiet", "-m", "init")
+	gitRun(t, work, "push", "--quiet", "origin", "HEAD:main")
+	return remote
+}
+
+func TestApply_Direct(t *testing.T) {
+	remote := newDeployRepo(t)
+	target := Target{Name: "app", Repo: remote, Direct: true, Files: []File{{Path: "values.yaml", Key: "image.tag"}}}
+	res, err := Apply(target, Data{Version: "1.1.0", Previous: "1.0.0", Tag: "v1.1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Changed || res.Branch != "main" {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if content := gitRun(t, remote, "show", "main:values.yaml"); content != "image:\n  tag: 1.1.0" {
+		t.Errorf("unexpected values.yaml %q", content)
+	}
+	if subject := gitRun(t, remote, "log", "-1", "--format=%s", "main"); subject != "chore(deploy): bump app to 1.1.0" {
+		t.Errorf("unexpected commit subject %q", subject)
+	}
+
+	res, err = Apply(target, Data{Version: "1.1.0", Tag: "v1.1.0"})
+	if err != nil || res.Changed {
+		t.Errorf("expected unchanged when already deployed, but %+v with err %v", res, err)
+	}
+}
+
+func TestApply_PullRequest(t *testing.T) {
+	remote := newDeployRepo(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"number":3,"html_url":"https://github.com/org/deploy/pull/3"}`))
+	}))
+	defer server.Close()
+
+	target := Target{
+		Name:     "app",
+		Repo:     remote,
+		Files:    []File{{Path: "values.yaml", Key: "image.tag", Value: "{{ .Tag }}"}},
+		Provider: provider.Config{Type: provider.GitHub, URL: server.URL, Repo: "org/deploy"},
+	}
+	res, err := Apply(target, Data{Version: "1.1.0", Tag: "v1.1.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Branch != "autoctl/deploy-app-v1.1.0" || res.PullRequest == nil || res.PullRequest.Number != 3 {
+		t.Errorf("unexpected result %+v", res)
+	}
+	if content := gitRun(t, remote, "show", res.Branch+":values.yaml"); content != "image:\n  tag: v1.1.0" {
+		t.Errorf("unexpected values.yaml %q", content)
+	}
+	if content := gitRun(t, remote, "show", "main:values.yaml"); content != "image:\n  tag: 1.0.0" {
+		t.Errorf("expected main untouched, but %q got", content)
+	}
+}
+
+func TestWriteFiles(t *testing.T) {
+	remote := newDeployRepo(t)
+	target := Target{Name: "autoctl", Repo: remote, Direct: true, Message: "chore(homebrew): update {{ .Name }} to {{ .Version }}"}
+	files := map[string][]byte{"Formula/autoctl.rb": []byte("class Autoctl < Formula\nend\n")}
+	res, err := WriteFiles(target, Data{Version: "1.1.0", Tag: "v1.1.0"}, files)
+	if err != nil || !res.Changed {
+		t.Fatalf("expected formula committed, but %+v %v got", res, err)
+	}
+	if content := gitRun(t, remote, "show", "main:Formula/autoctl.rb"); content != "class Autoctl < Formula\nend" {
+		t.Errorf("unexpected formula %q", content)
+	}
+	if res, err = WriteFiles(target, Data{Version: "1.1.0", Tag: "v1.1.0"}, files); err != nil || res.Changed {
+		t.Errorf("expected unchanged formula not committed, but %+v %v got", res, err)
+	}
+}
+
+func TestApply_PinImage(t *testing.T) {
+	remote := newDeployRepo(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v2/org/app/manifests/1.1.0" {
+			w.Header().Set("Docker-Content-Digest", "sha256:abc")
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	t.Setenv("DOCKER_CONFIG", t.TempDir())
+
+	host := strings.TrimPrefix(server.URL, "http://")
+	target := Target{
+		Name:     "app",
+		Repo:     remote,
+		Direct:   true,
+		Files:    []File{{Path: "values.yaml", Key: "image.tag"}},
+		Image:    host + "/org/app:{{ .Version }}",
+		Pin:      true,
+		Registry: registry.Config{PlainHTTP: true},
+	}
+	if _, err := Apply(target, Data{Version: "1.2.0", Tag: "v1.2.0"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected missing image error, but %v got", err)
+	}
+	if content := gitRun(t, remote, "show", "main:values.yaml"); content != "image:\n  tag: 1.0.0" {
+		t.Fatalf("expected deploy repo untouched, but %q got", content)
+	}
+	if _, err := Apply(target, Data{Version: "1.1.0", Tag: "v1.1.0"}); err != nil {
+		t.Fatal(err)
+	}
+	if content := gitRun(t, remote, "show", "main:values.yaml"); content != "image:\n  tag: 1.1.0@sha256:abc" {
+		t.Errorf("expected pinned digest, but %q got", content)
+	}
+}
@@ -0,0 +1,191 @@
+// Package golden 比较渲染结果与已提交的快照文件，用于渲染输出的快照测试及 autoctl changelog --check
+package golden
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/fileutil"
+)
+
+// EnvUpdate 设置为 1 时 Assert 以实际结果覆盖快照文件，如 GOLDEN_UPDATE=1 go test ./...
+const EnvUpdate = "GOLDEN_UPDATE"
+
+// contextLines 差异中每处变更前后展示的相同行数
+const contextLines = 3
+
+// Path 快照文件路径 testdata/<name>.golden
+func Path(name string) string {
+	return filepath.Join("testdata", name+".golden")
+}
+
+// Assert 比较 got 与快照文件，不一致时以统一差异格式报告
+func Assert(t testing.TB, name string, got []byte) {
+	t.Helper()
+	path := Path(name)
+	if os.Getenv(EnvUpdate) == "1" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := fileutil.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read golden file: %v, run with %s=1 to create it", err, EnvUpdate)
+	}
+	if diff := Diff(path, "actual", string(want), string(got)); diff != "" {
+		t.Errorf("output differs from %s, run with %s=1 to update it\n%s", path, EnvUpdate, diff)
+	}
+}
+
+// Diff 按行比较两段文本，相同时返回空字符串，否则返回统一差异格式
+func Diff(wantName, gotName, want, got string) string {
+	if want == got {
+		return ""
+	}
+	a, b := splitLines(want), splitLines(got)
+	edits := myers(a, b)
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", wantName, gotName))
+	for _, h := range hunks(edits) {
+		sb.WriteString(h)
+	}
+	return sb.String()
+}
+
+func splitLines(s string) []string {
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// edit 编辑脚本中的一行，kind 为 ' '、'-' 或 '+'
+type edit struct {
+	kind byte
+	line string
+}
+
+// myers Myers 差异算法，返回从 a 到 b 的最短编辑脚本
+func myers(a, b []string) []edit {
+	n, m := len(a), len(b)
+	max := n + m
+	v := make([]int, 2*max+2)
+	var trace [][]int
+search:
+	for d := 0; d <= max; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+				x = v[max+k+1]
+			} else {
+				x = v[max+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			v[max+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+	var edits []edit
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[max+k-1] < v[max+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := v[max+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			edits = append(edits, edit{' ', a[x-1]})
+			x, y = x-1, y-1
+		}
+		if d > 0 {
+			if x == prevX {
+				edits = append(edits, edit{'+', b[y-1]})
+			} else {
+				edits = append(edits, edit{'-', a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+	for i, j := 0, len(edits)-1; i < j; i, j = i+1, j-1 {
+		edits[i], edits[j] = edits[j], edits[i]
+	}
+	return edits
+}
+
+// hunks 将编辑脚本按变更分组，每组保留前后 contextLines 行相同内容
+func hunks(edits []edit) []string {
+	var res []string
+	for i := 0; i < len(edits); {
+		if edits[i].kind == ' ' {
+			i++
+			continue
+		}
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		// 向后扩展直到连续的相同行超过两倍上下文
+		end, same := i, 0
+		for ; end < len(edits) && same <= 2*contextLines; end++ {
+			if edits[end].kind == ' ' {
+				same++
+			} else {
+				same = 0
+			}
+		}
+		end -= same - contextLines
+		if end > len(edits) {
+			end = len(edits)
+		}
+		res = append(res, hunk(edits, start, end))
+		i = end
+	}
+	return res
+}
+
+func hunk(edits []edit, start, end int) string {
+	aStart, bStart := 1, 1
+	for _, e := range edits[:start] {
+		if e.kind != '+' {
+			aStart++
+		}
+		if e.kind != '-' {
+			bStart++
+		}
+	}
+	var body strings.Builder
+	aLen, bLen := 0, 0
+	for _, e := range edits[start:end] {
+		if e.kind != '+' {
+			aLen++
+		}
+		if e.kind != '-' {
+			bLen++
+		}
+		body.WriteByte(e.kind)
+		body.WriteString(e.line)
+		if !strings.HasSuffix(e.line, "\n") {
+			body.WriteString("\n\\ No newline at end of file\n")
+		}
+	}
+	return fmt.Sprintf("@@ -%d,%d +%d,%d @@\n%s", aStart, aLen, bStart, bLen, body.String())
+}
@@ -0,0 +1,36 @@
+package golden
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	if d := Diff("a", "b", "same\n", "same\n"); d != "" {
+		t.Errorf("expected no diff, but %q got", d)
+	}
+	want := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n"
+	got := "1\n2\nthree\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n"
+	expected := `--- want
++++ got
+@@ -1,6 +1,6 @@
+ 1
+ 2
+-3
++three
+ 4
+ 5
+ 6
+@@ -10,3 +10,4 @@
+ 10
+ 11
+ 12
++13
+`
+	if d := Diff("want", "got", want, got); d != expected {
+		t.Errorf("unexpected diff\n%s", d)
+	}
+	if d := Diff("want", "got", "a", "b"); !strings.Contains(d, "-a\n\\ No newline at end of file\n+b\n") {
+		t.Errorf("unexpected diff without trailing newline\n%s", d)
+	}
+}
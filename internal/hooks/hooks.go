@@ -0,0 +1,86 @@
+// Package hooks 在发布的各阶段执行配置的脚本，脚本通过当前系统的 shell 执行（见 shell 包），
+// 可以通过 hooks.windows、hooks.unix 为不同系统配置不同的脚本
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/runctx"
+	"github.com/coffee377/autoctl/internal/shell"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// 发布阶段
+const (
+	BeforeRelease = "beforeRelease" // 创建标签前，失败时中止发布
+	AfterRelease  = "afterRelease"  // 发布完成后
+)
+
+// Scripts 各阶段依次执行的脚本
+type Scripts struct {
+	BeforeRelease []string `mapstructure:"beforeRelease"`
+	AfterRelease  []string `mapstructure:"afterRelease"`
+}
+
+func (s Scripts) get(stage string) []string {
+	switch stage {
+	case BeforeRelease:
+		return s.BeforeRelease
+	case AfterRelease:
+		return s.AfterRelease
+	}
+	return nil
+}
+
+// Config 发布钩子，对应配置文件 hooks 节点，当前系统的变体中配置了某一阶段时替换该阶段的通用脚本
+type Config struct {
+	Scripts `mapstructure:",squash"`
+	Windows Scripts `mapstructure:"windows"` // 仅在 Windows 上执行
+	Unix    Scripts `mapstructure:"unix"`    // 仅在 Windows 以外的系统上执行
+}
+
+// For 当前系统在指定阶段执行的脚本
+func (c Config) For(stage string) []string {
+	variant := c.Unix
+	if shell.Windows() {
+		variant = c.Windows
+	}
+	if scripts := variant.get(stage); len(scripts) > 0 {
+		return scripts
+	}
+	return c.Scripts.get(stage)
+}
+
+// Run 在 dir 中依次执行指定阶段的脚本，任一脚本失败时停止，env 追加到当前进程的环境变量，
+// 脚本的输出写入标准错误，不影响 --json 等输出
+func (c Config) Run(stage, dir string, env map[string]string) error {
+	scripts := c.For(stage)
+	if len(scripts) == 0 {
+		return nil
+	}
+	environ := os.Environ()
+	for k, v := range env {
+		environ = append(environ, k+"="+v)
+	}
+	for i, script := range scripts {
+		if err := run(script, dir, environ); err != nil {
+			return fmt.Errorf("hooks.%s[%d]: %w", stage, i, err)
+		}
+	}
+	return nil
+}
+
+func run(script, dir string, environ []string) error {
+	ctx, cancel := runctx.Command()
+	defer cancel()
+	cmd := shell.Command(ctx, script)
+	cmd.Dir = dir
+	cmd.Env = environ
+	cmd.Stdout, cmd.Stderr = os.Stderr, os.Stderr
+	start := time.Now()
+	err := cmd.Run()
+	log.TraceCommand(cmd, start, err)
+	return runctx.Wrap(ctx, err)
+}
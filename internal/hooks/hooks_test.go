@@ -0,0 +1,51 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/shell"
+)
+
+func TestConfig_For(t *testing.T) {
+	cfg := Config{
+		Scripts: Scripts{BeforeRelease: []string{"make build"}, AfterRelease: []string{"make announce"}},
+		Windows: Scripts{BeforeRelease: []string{"./build.ps1"}},
+		Unix:    Scripts{AfterRelease: []string{"./announce.sh"}},
+	}
+	before, after := []string{"make build"}, []string{"./announce.sh"}
+	if shell.Windows() {
+		before, after = []string{"./build.ps1"}, []string{"make announce"}
+	}
+	if got := cfg.For(BeforeRelease); strings.Join(got, ";") != strings.Join(before, ";") {
+		t.Errorf("For(beforeRelease) = %q, want %q", got, before)
+	}
+	if got := cfg.For(AfterRelease); strings.Join(got, ";") != strings.Join(after, ";") {
+		t.Errorf("For(afterRelease) = %q, want %q", got, after)
+	}
+}
+
+func TestConfig_Run(t *testing.T) {
+	dir := t.TempDir()
+	write := `printf '%s' "$AUTOCTL_TAG" > tag.txt`
+	if shell.Windows() {
+		write = `Set-Content -NoNewline -Path tag.txt -Value $env:AUTOCTL_TAG`
+	}
+	cfg := Config{Scripts: Scripts{BeforeRelease: []string{write}, AfterRelease: []string{"exit 1", write}}}
+	if err := cfg.Run(BeforeRelease, dir, map[string]string{"AUTOCTL_TAG": "v1.2.0"}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "tag.txt"))
+	if err != nil || string(data) != "v1.2.0" {
+		t.Errorf("expected tag written by hook, but got %q, %v", data, err)
+	}
+	err = cfg.Run(AfterRelease, dir, map[string]string{"AUTOCTL_TAG": "v1.3.0"})
+	if err == nil || !strings.Contains(err.Error(), "hooks.afterRelease[0]") {
+		t.Errorf("expected failing hook reported, got %v", err)
+	}
+	if data, _ = os.ReadFile(filepath.Join(dir, "tag.txt")); string(data) != "v1.2.0" {
+		t.Errorf("expected hooks after the failing one skipped, but got %q", data)
+	}
+}
@@ -0,0 +1,200 @@
+package httpclient
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+const DefaultCacheTTL = 10 * time.Minute
+
+// cacheKeyHeaders 参与缓存键计算的请求头，不同令牌或媒体类型的响应分别缓存
+var cacheKeyHeaders = []string{"Authorization", "Private-Token", "Accept"}
+
+// CacheConfig GET、HEAD 请求响应的磁盘缓存，减少重复执行及多包发布时的接口调用，避免触发限流
+type CacheConfig struct {
+	Enabled bool          `mapstructure:"enabled"` // 开启响应缓存，--no-cache 时禁用
+	Dir     string        `mapstructure:"dir"`     // 缓存目录，默认 <用户缓存目录>/autoctl/http
+	TTL     time.Duration `mapstructure:"ttl"`     // 缓存有效期，默认 10m，过期后携带 ETag 重新验证
+}
+
+// Merge 以 c 为准，未设置的字段使用 global 中的值
+func (c CacheConfig) Merge(global CacheConfig) CacheConfig {
+	c.Enabled = c.Enabled || global.Enabled
+	if c.Dir == "" {
+		c.Dir = global.Dir
+	}
+	if c.TTL == 0 {
+		c.TTL = global.TTL
+	}
+	return c
+}
+
+var cacheDisabled int32
+
+// DisableCache 禁用所有客户端的响应缓存，对应 --no-cache
+func DisableCache() {
+	atomic.StoreInt32(&cacheDisabled, 1)
+}
+
+// cacheEntry 缓存的响应
+type cacheEntry struct {
+	URL     string      `json:"url"`
+	Status  int         `json:"status"`
+	Header  http.Header `json:"header"`
+	Body    []byte      `json:"body"`
+	Created time.Time   `json:"created"`
+}
+
+// cacheTransport 缓存成功的 GET、HEAD 响应；请求头 Cache-Control: no-cache 时跳过读取缓存（如轮询审批状态），
+// 其它方法的请求成功后清除同一主机的缓存，避免读到变更前的数据
+type cacheTransport struct {
+	next http.RoundTripper
+	dir  string
+	ttl  time.Duration
+}
+
+func newCacheTransport(next http.RoundTripper, cfg CacheConfig) http.RoundTripper {
+	if !cfg.Enabled || atomic.LoadInt32(&cacheDisabled) == 1 {
+		return next
+	}
+	dir := cfg.Dir
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			log.Debug("http cache disabled: %s", err)
+			return next
+		}
+		dir = filepath.Join(base, "autoctl", "http")
+	}
+	ttl := cfg.TTL
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &cacheTransport{next: next, dir: dir, ttl: ttl}
+}
+
+func (t *cacheTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		resp, err := t.next.RoundTrip(req)
+		if err == nil && resp.StatusCode < 400 {
+			t.invalidate(req)
+		}
+		return resp, err
+	}
+	// no-store 及断点续传的请求不读写缓存，如下载版本发布附件
+	if strings.Contains(req.Header.Get("Cache-Control"), "no-store") || req.Header.Get("Range") != "" {
+		return t.next.RoundTrip(req)
+	}
+	file := t.file(req)
+	entry := t.load(file)
+	fresh := strings.Contains(req.Header.Get("Cache-Control"), "no-cache")
+	if entry != nil && !fresh && time.Since(entry.Created) < t.ttl {
+		log.Debug("http cache hit %s %s", req.Method, req.URL.Redacted())
+		return entry.response(req), nil
+	}
+	attempt := req
+	if etag := entry.etag(); etag != "" && !fresh {
+		attempt = req.Clone(req.Context())
+		attempt.Header.Set("If-None-Match", etag)
+	}
+	resp, err := t.next.RoundTrip(attempt)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && attempt != req {
+		// 重新验证通过，GitHub 的 304 响应不计入限流
+		_ = resp.Body.Close()
+		entry.Created = time.Now()
+		t.store(file, entry)
+		return entry.response(req), nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	t.store(file, &cacheEntry{URL: req.URL.Redacted(), Status: resp.StatusCode, Header: resp.Header, Body: body, Created: time.Now()})
+	return resp, nil
+}
+
+// file 缓存文件按主机分目录存放，便于按主机清除
+func (t *cacheTransport) file(req *http.Request) string {
+	h := sha256.New()
+	_, _ = io.WriteString(h, req.Method+" "+req.URL.String()+"\n")
+	for _, name := range cacheKeyHeaders {
+		_, _ = io.WriteString(h, name+": "+req.Header.Get(name)+"\n")
+	}
+	return filepath.Join(t.dir, req.URL.Host, hex.EncodeToString(h.Sum(nil))+".json")
+}
+
+func (t *cacheTransport) load(file string) *cacheEntry {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil
+	}
+	entry := &cacheEntry{}
+	if err = json.Unmarshal(data, entry); err != nil {
+		return nil
+	}
+	return entry
+}
+
+func (t *cacheTransport) store(file string, entry *cacheEntry) {
+	data, err := json.Marshal(entry)
+	if err == nil {
+		err = os.MkdirAll(filepath.Dir(file), 0o700)
+	}
+	if err == nil {
+		// 先写入临时文件再重命名，避免并发执行时读到不完整的缓存
+		tmp := file + ".tmp"
+		if err = os.WriteFile(tmp, data, 0o600); err == nil {
+			err = os.Rename(tmp, file)
+		}
+	}
+	if err != nil {
+		log.Debug("write http cache: %s", err)
+	}
+}
+
+func (t *cacheTransport) invalidate(req *http.Request) {
+	if err := os.RemoveAll(filepath.Join(t.dir, req.URL.Host)); err != nil {
+		log.Debug("clear http cache: %s", err)
+	}
+}
+
+func (e *cacheEntry) etag() string {
+	if e == nil {
+		return ""
+	}
+	return e.Header.Get("ETag")
+}
+
+func (e *cacheEntry) response(req *http.Request) *http.Response {
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status)),
+		StatusCode:    e.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        e.Header.Clone(),
+		Body:          io.NopCloser(bytes.NewReader(e.Body)),
+		ContentLength: int64(len(e.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,105 @@
+package httpclient
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacheTransport(t *testing.T) {
+	hits := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.Method == http.MethodPost {
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`[{"tag_name":"v1.0.0"}]`))
+	}))
+	defer server.Close()
+
+	client, err := New(Config{Cache: CacheConfig{Enabled: true, Dir: t.TempDir(), TTL: time.Hour}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	get := func(headers ...string) string {
+		t.Helper()
+		req, _ := http.NewRequest(http.MethodGet, server.URL+"/releases", nil)
+		for i := 0; i+1 < len(headers); i += 2 {
+			req.Header.Set(headers[i], headers[i+1])
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("expected 200, but %d got", resp.StatusCode)
+		}
+		return string(data)
+	}
+
+	if body := get(); body != `[{"tag_name":"v1.0.0"}]` || hits != 1 {
+		t.Fatalf("unexpected first response %s (%d hits)", body, hits)
+	}
+	if body := get(); body != `[{"tag_name":"v1.0.0"}]` || hits != 1 {
+		t.Errorf("expected response served from cache, but %s (%d hits) got", body, hits)
+	}
+	if get("Authorization", "token other"); hits != 2 {
+		t.Errorf("expected another token not sharing the cache, but %d hits got", hits)
+	}
+	if get("Cache-Control", "no-cache"); hits != 3 {
+		t.Errorf("expected no-cache request sent, but %d hits got", hits)
+	}
+	if get("Cache-Control", "no-store"); hits != 4 {
+		t.Errorf("expected no-store request sent, but %d hits got", hits)
+	}
+	resp, err := client.Post(server.URL+"/releases", "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if get(); hits != 6 {
+		t.Errorf("expected cache cleared after post, but %d hits got", hits)
+	}
+}
+
+func TestCacheTransport_Revalidate(t *testing.T) {
+	hits, revalidated := 0, 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			revalidated++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		_, _ = w.Write([]byte(`ok`))
+	}))
+	defer server.Close()
+
+	client, _ := New(Config{Cache: CacheConfig{Enabled: true, Dir: t.TempDir(), TTL: time.Nanosecond}})
+	for i := 0; i < 2; i++ {
+		resp, err := client.Get(server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(data) != "ok" {
+			t.Fatalf("unexpected response %d %s", resp.StatusCode, data)
+		}
+	}
+	if hits != 2 || revalidated != 1 {
+		t.Errorf("expected expired entry revalidated with etag, but %d hits %d revalidated got", hits, revalidated)
+	}
+}
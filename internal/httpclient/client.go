@@ -0,0 +1,230 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/progress"
+	"github.com/coffee377/autoctl/internal/retry"
+	"github.com/coffee377/autoctl/internal/runctx"
+)
+
+const DefaultTimeout = 30 * time.Second
+
+// TLSConfig TLS 配置，私有化部署的服务通常使用企业自签名证书
+type TLSConfig struct {
+	CAFile             string `mapstructure:"caFile"`             // PEM 格式的 CA 证书，追加到系统证书池
+	CertFile           string `mapstructure:"certFile"`           // 客户端证书
+	KeyFile            string `mapstructure:"keyFile"`            // 客户端证书私钥
+	InsecureSkipVerify bool   `mapstructure:"insecureSkipVerify"` // 跳过服务端证书校验，仅用于调试
+}
+
+// Config HTTP 客户端配置，全局配置对应配置文件 http 节点，各服务可通过自身的 http 节点覆盖
+type Config struct {
+	Proxy   string        `mapstructure:"proxy"`   // 代理地址，为空时读取 HTTPS_PROXY / HTTP_PROXY 环境变量
+	NoProxy string        `mapstructure:"noProxy"` // 不使用代理的主机，逗号分隔，为空时读取 NO_PROXY 环境变量
+	Timeout time.Duration `mapstructure:"timeout"` // 请求超时时间，包含重试等待时间，默认 30s
+	TLS     TLSConfig     `mapstructure:"tls"`
+	Retry   retry.Policy  `mapstructure:"retry"` // 网络错误及限流、网关错误的重试策略，默认 retry.DefaultPolicy
+	Cache   CacheConfig   `mapstructure:"cache"` // GET、HEAD 响应的磁盘缓存
+}
+
+// Merge 以 c 为准，未设置的字段使用 global 中的值
+func (c Config) Merge(global Config) Config {
+	if c.Proxy == "" {
+		c.Proxy = global.Proxy
+	}
+	if c.NoProxy == "" {
+		c.NoProxy = global.NoProxy
+	}
+	if c.Timeout == 0 {
+		c.Timeout = global.Timeout
+	}
+	if c.TLS.CAFile == "" {
+		c.TLS.CAFile = global.TLS.CAFile
+	}
+	if c.TLS.CertFile == "" && c.TLS.KeyFile == "" {
+		c.TLS.CertFile, c.TLS.KeyFile = global.TLS.CertFile, global.TLS.KeyFile
+	}
+	c.TLS.InsecureSkipVerify = c.TLS.InsecureSkipVerify || global.TLS.InsecureSkipVerify
+	c.Retry = c.Retry.Merge(global.Retry)
+	c.Cache = c.Cache.Merge(global.Cache)
+	return c
+}
+
+// New 根据配置创建 HTTP 客户端
+func New(cfg Config) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	tlsConfig, err := cfg.TLS.build()
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+	if transport.Proxy, err = cfg.proxy(); err != nil {
+		return nil, err
+	}
+	timeout := cfg.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout
+	}
+	return &http.Client{
+		Transport: &cancelTransport{next: newCacheTransport(&retryTransport{next: &progressTransport{next: transport}, policy: cfg.Retry}, cfg.Cache)},
+		Timeout:   timeout,
+	}, nil
+}
+
+// cancelTransport 单次执行取消（中断信号或 timeouts.total）时终止进行中的请求及重试等待
+type cancelTransport struct {
+	next http.RoundTripper
+}
+
+func (t *cancelTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := runctx.Merge(req.Context())
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, runctx.Wrap(ctx, err)
+	}
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// progressThreshold 请求体不小于该大小或大小未知时显示上传进度
+const progressThreshold = 1 << 20
+
+// progressTransport 上传文件时显示进度，如发布附件、制品库文件及镜像层
+type progressTransport struct {
+	next http.RoundTripper
+}
+
+func (t *progressTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.Body == http.NoBody || (req.ContentLength > 0 && req.ContentLength < progressThreshold) ||
+		req.Method == http.MethodGet || req.Method == http.MethodHead || !progress.Enabled() {
+		return t.next.RoundTrip(req)
+	}
+	task := progress.Bar("upload "+path.Base(req.URL.Path), req.ContentLength)
+	defer task.Stop()
+	upload := req.WithContext(req.Context())
+	upload.Body = &progressBody{Reader: task.Reader(req.Body), Closer: req.Body}
+	return t.next.RoundTrip(upload)
+}
+
+type progressBody struct {
+	io.Reader
+	io.Closer
+}
+
+// cancelBody 读取完响应后释放上下文
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+func (t TLSConfig) build() (*tls.Config, error) {
+	config := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: t.InsecureSkipVerify}
+	if t.CAFile != "" {
+		pem, err := os.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read ca file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil || pool == nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in ca file %s", t.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	if t.CertFile != "" || t.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+func (c Config) proxy() (func(*http.Request) (*url.URL, error), error) {
+	if c.Proxy == "" && c.NoProxy == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+	proxy := c.Proxy
+	if proxy == "" {
+		proxy = firstEnv("HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy")
+	}
+	if proxy == "" {
+		return nil, nil
+	}
+	proxyURL, err := url.Parse(proxy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy %q: %w", proxy, err)
+	}
+	noProxy := c.NoProxy
+	if noProxy == "" {
+		noProxy = firstEnv("NO_PROXY", "no_proxy")
+	}
+	return func(req *http.Request) (*url.URL, error) {
+		if bypassProxy(req.URL.Hostname(), noProxy) {
+			return nil, nil
+		}
+		return proxyURL, nil
+	}, nil
+}
+
+// bypassProxy 按 NO_PROXY 规则判断主机是否直连：* 匹配所有主机，.example.com 及 example.com 匹配自身及子域名
+func bypassProxy(host, noProxy string) bool {
+	host = strings.ToLower(host)
+	if host == "localhost" || net.ParseIP(host).IsLoopback() {
+		return true
+	}
+	for _, rule := range strings.Split(noProxy, ",") {
+		rule = strings.ToLower(strings.TrimSpace(rule))
+		if rule == "" {
+			continue
+		}
+		if rule == "*" {
+			return true
+		}
+		if h, _, err := net.SplitHostPort(rule); err == nil {
+			rule = h
+		}
+		if _, cidr, err := net.ParseCIDR(rule); err == nil {
+			if ip := net.ParseIP(host); ip != nil && cidr.Contains(ip) {
+				return true
+			}
+			continue
+		}
+		rule = strings.TrimPrefix(rule, ".")
+		if host == rule || strings.HasSuffix(host, "."+rule) {
+			return true
+		}
+	}
+	return false
+}
+
+func firstEnv(keys ...string) string {
+	for _, k := range keys {
+		if v := os.Getenv(k); v != "" {
+			return v
+		}
+	}
+	return ""
+}
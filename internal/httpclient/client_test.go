@@ -0,0 +1,118 @@
+package httpclient
+
+import (
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/retry"
+)
+
+func TestNew_CAFile(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		cfg  Config
+		ok   bool
+	}{
+		{"system pool", Config{}, false},
+		{"custom ca", Config{TLS: TLSConfig{CAFile: caFile}}, true},
+		{"insecure", Config{TLS: TLSConfig{InsecureSkipVerify: true}}, true},
+	}
+	for _, test := range tests {
+		client, err := New(test.cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Get(server.URL)
+		if resp != nil {
+			_ = resp.Body.Close()
+		}
+		if (err == nil) != test.ok {
+			t.Errorf("%s: expected ok %v, but err %v got", test.name, test.ok, err)
+		}
+	}
+}
+
+func TestBypassProxy(t *testing.T) {
+	tests := []struct {
+		host    string
+		noProxy string
+		bypass  bool
+	}{
+		{"gitlab.corp.net", "corp.net", true},
+		{"gitlab.corp.net", ".corp.net", true},
+		{"corp.net.evil.com", "corp.net", false},
+		{"api.github.com", "corp.net,localhost", false},
+		{"10.1.2.3", "10.0.0.0/8", true},
+		{"api.github.com", "*", true},
+		{"127.0.0.1", "", true},
+	}
+	for _, test := range tests {
+		if actual := bypassProxy(test.host, test.noProxy); actual != test.bypass {
+			t.Errorf("host %s with NO_PROXY %q expected bypass %v, but %v got", test.host, test.noProxy, test.bypass, actual)
+		}
+	}
+}
+
+func TestConfig_Merge(t *testing.T) {
+	global := Config{Proxy: "http://proxy:3128", TLS: TLSConfig{CAFile: "/etc/ca.pem"}}
+	merged := Config{TLS: TLSConfig{InsecureSkipVerify: true}}.Merge(global)
+	if merged.Proxy != global.Proxy || merged.TLS.CAFile != global.TLS.CAFile || !merged.TLS.InsecureSkipVerify {
+		t.Errorf("unexpected merged config %+v", merged)
+	}
+}
+
+func TestNew_Retry(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		if r.Method == http.MethodPost && string(body) != "payload" {
+			t.Errorf("expected replayed body, but %q got", body)
+		}
+		if calls < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	client, err := New(Config{Retry: retry.Policy{Attempts: 3, Delay: time.Millisecond}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated || calls != 3 {
+		t.Errorf("expected 201 after 3 calls, but %d after %d calls got", resp.StatusCode, calls)
+	}
+
+	calls = -10
+	resp, err = client.Get(server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("expected last response 503 when retries exhausted, but %d got", resp.StatusCode)
+	}
+}
@@ -0,0 +1,95 @@
+package httpclient
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/retry"
+	"github.com/coffee377/autoctl/internal/runctx"
+)
+
+// retryTransport 对网络错误及 429、502、503、504 响应按策略重试
+// 请求体无法重放（未设置 GetBody）时不重试，非幂等请求的重复提交由调用方检查
+type retryTransport struct {
+	next   http.RoundTripper
+	policy retry.Policy
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	name := fmt.Sprintf("%s %s", req.Method, req.URL.Redacted())
+	err := retry.Do(t.policy, name, func() error {
+		if resp != nil {
+			// 上一次为可重试的响应，重试前释放连接
+			_ = resp.Body.Close()
+			resp = nil
+		}
+		attempt := req
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				r, err := t.next.RoundTrip(req)
+				resp = r
+				return retry.Permanent(err)
+			}
+			body, err := req.GetBody()
+			if err != nil {
+				return retry.Permanent(err)
+			}
+			attempt = req.Clone(req.Context())
+			attempt.Body = body
+		}
+		r, err := t.next.RoundTrip(attempt)
+		if err != nil {
+			if req.Context().Err() != nil || certificateError(err) {
+				return retry.Permanent(err)
+			}
+			return err
+		}
+		resp = r
+		if retryable(r.StatusCode) {
+			if wait := retryAfter(r); wait > 0 && wait <= t.policy.Merge(retry.DefaultPolicy).MaxDelay {
+				if err := runctx.Sleep(wait); err != nil {
+					_ = r.Body.Close()
+					resp = nil
+					return retry.Permanent(err)
+				}
+			}
+			return fmt.Errorf("status %d", r.StatusCode)
+		}
+		return nil
+	})
+	if resp != nil {
+		// 重试耗尽时返回最后一次响应，由调用方处理状态码
+		return resp, nil
+	}
+	return nil, err
+}
+
+// certificateError 证书校验失败时重试无意义
+func certificateError(err error) bool {
+	var unknownAuthority x509.UnknownAuthorityError
+	var hostname x509.HostnameError
+	var invalid x509.CertificateInvalidError
+	return errors.As(err, &unknownAuthority) || errors.As(err, &hostname) || errors.As(err, &invalid)
+}
+
+func retryable(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// retryAfter 解析以秒为单位的 Retry-After 响应头
+func retryAfter(resp *http.Response) time.Duration {
+	seconds, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
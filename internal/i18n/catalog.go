@@ -0,0 +1,94 @@
+package i18n
+
+// catalogs 各语言的消息译文，键为英文原文，新增用户可见的消息时需同步补充译文
+var catalogs = map[Lang]map[string]string{
+	ZhCN: zhCN,
+}
+
+var zhCN = map[string]string{
+	// prompt
+	"y/N":                 "是(y)/否(N)",
+	"Y/n":                 "是(Y)/否(n)",
+	"invalid choice %q\n": "无效的选项 %q\n",
+
+	// commit
+	"Select the type of change:":                   "选择变更类型：",
+	"Scope of the change (optional):":              "变更范围（可选）：",
+	"Scope of the change:":                         "变更范围：",
+	"Short description":                            "简短描述",
+	"Longer description (optional)":                "详细描述（可选）",
+	"Is this a breaking change?":                   "是否为破坏性变更？",
+	"Describe the breaking change":                 "描述破坏性变更",
+	"Issues closed, separated by comma (optional)": "关闭的议题，以逗号分隔（可选）",
+	"Commit?":                       "确认提交？",
+	"invalid scope %q\n":            "无效的范围 %q\n",
+	"invalid commit message:\n  %s": "提交信息不符合规范：\n  %s",
+	"no changes added to commit, stage changes with git add or use --all": "没有待提交的变更，请使用 git add 暂存变更或使用 --all",
+
+	// lint
+	"%d commit message(s) do not follow the conventional commits specification": "%d 条提交信息不符合约定式提交规范",
+	"commit message is empty":                                    "提交信息为空",
+	"header is %d characters, must not be longer than %d":        "标题共 %d 个字符，不能超过 %d 个字符",
+	"header must be in the format <type>[(scope)][!]: <subject>": "标题格式必须为 <type>[(scope)][!]: <subject>",
+	"type %q is not allowed, allowed types: %s":                  "不允许的类型 %q，允许的类型：%s",
+	"scope is required":                                          "必须填写范围",
+	"scope %q is not allowed, allowed scopes: %s":                "不允许的范围 %q，允许的范围：%s",
+	"subject must not be empty":                                  "描述不能为空",
+	"header must be followed by a blank line":                    "标题后必须空一行",
+
+	// release
+	"no release, current version is %s\n":                                                                      "未发布新版本，当前版本为 %s\n",
+	"load release plan: %w, run 'autoctl plan --write' first":                                                  "读取发布计划失败：%w，请先执行 'autoctl plan --write'",
+	"all planned releases have been released, run 'autoctl plan --write' to plan more":                         "发布计划中的版本均已发布，请执行 'autoctl plan --write' 制定新的计划",
+	"next planned release %s is scheduled on %s, use --force to release now":                                   "下一次计划发布 %s 安排在 %s，使用 --force 立即发布",
+	"planned version %s is not greater than current version %s, the plan is stale, run 'autoctl plan --write'": "计划版本 %s 不大于当前版本 %s，发布计划已过期，请执行 'autoctl plan --write'",
+
+	// changelog
+	"wrote %d releases to %s": "已将 %d 个版本写入 %s",
+	"%s is up to date":        "%s 已是最新",
+
+	// release prune
+	"dry run: %d releases would be deleted": "试运行：将删除 %d 个版本发布",
+
+	// tag
+	"dry run: %d tags would be deleted": "试运行：将删除 %d 个标签",
+	"deleted tag %s":                    "已删除标签 %s",
+
+	// sync
+	"updated %s to %s": "已将 %s 更新为 %s",
+
+	// nightly
+	"created tag %s":      "已创建标签 %s",
+	"pushed tag %s to %s": "已推送标签 %s 到 %s",
+
+	// deps
+	"dependencies are up to date": "依赖均为最新版本",
+	"updated %s\n":                "已更新 %s\n",
+	"pull request %s\n":           "合并请求 %s\n",
+
+	// semver
+	"ok, %d checks passed\n": "通过，共 %d 项检查\n",
+
+	// ui
+	"command": "命令",
+	"[n] select  [b <type>] release type  [r] release  [g] reload  [q] quit": "[n] 选择  [b <类型>] 版本变动类型  [r] 发布  [g] 刷新  [q] 退出",
+	"release type required, one of %s":                                       "请指定版本变动类型，可选 %s",
+	"unknown command %q":                                                     "未知的命令 %q",
+	"Release %s %s?":                                                         "发布 %s %s？",
+	"released %s":                                                            "已发布 %s",
+	"release timings:":                                                       "发布耗时：",
+
+	// root
+	"Using config file: %s":      "使用配置文件：%s",
+	"load redact config: %v":     "读取 redact 配置失败：%v",
+	"load presets: %v":           "读取继承的预设配置失败：%v",
+	"invalid redact pattern: %v": "无效的屏蔽规则：%v",
+	"load templates config: %v":  "读取 templates 配置失败：%v",
+	"load checkout config: %v":   "读取 checkout 配置失败：%v",
+	"load timeouts config: %v":   "读取 timeouts 配置失败：%v",
+	"load telemetry config: %v":  "读取 telemetry 配置失败：%v",
+	"load workspace config: %v":  "读取 workspace 配置失败：%v",
+	"load shells config: %v":     "读取 shells 配置失败：%v",
+	"open trace file: %v":        "打开命令追踪文件失败：%v",
+	"initialize default config":  "初始化默认配置",
+}
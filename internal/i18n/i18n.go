@@ -0,0 +1,117 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// Lang 界面语言
+type Lang string
+
+const (
+	En   Lang = "en"
+	ZhCN Lang = "zh-CN"
+
+	EnvLang = "AUTOCTL_LANG" // 指定界面语言的环境变量，优先于 LC_ALL、LC_MESSAGES、LANG
+)
+
+// Langs 支持的界面语言
+var Langs = []Lang{En, ZhCN}
+
+var current atomic.Value
+
+func init() {
+	current.Store(En)
+}
+
+// Current 当前界面语言，默认 en
+func Current() Lang {
+	return current.Load().(Lang)
+}
+
+// SetLang 设置界面语言，不支持的语言返回错误
+func SetLang(lang string) error {
+	l, ok := Parse(lang)
+	if !ok {
+		return fmt.Errorf("unsupported language %q, one of %s", lang, strings.Join(LangNames(), "|"))
+	}
+	current.Store(l)
+	return nil
+}
+
+// LangNames 支持的界面语言名称
+func LangNames() []string {
+	names := make([]string, 0, len(Langs))
+	for _, l := range Langs {
+		names = append(names, string(l))
+	}
+	return names
+}
+
+// Parse 解析语言标识，兼容 locale 格式，如 zh_CN.UTF-8、zh-Hans、en_US
+func Parse(lang string) (Lang, bool) {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if i := strings.IndexAny(lang, ".@"); i >= 0 {
+		lang = lang[:i]
+	}
+	lang = strings.ReplaceAll(lang, "_", "-")
+	switch {
+	case lang == "zh" || strings.HasPrefix(lang, "zh-"):
+		return ZhCN, true
+	case lang == "en" || strings.HasPrefix(lang, "en-"), lang == "c", lang == "posix":
+		return En, true
+	}
+	return En, false
+}
+
+// Detect 依次读取 AUTOCTL_LANG、LC_ALL、LC_MESSAGES、LANG 环境变量确定界面语言，均未设置或不支持时使用 en
+func Detect() Lang {
+	for _, key := range []string{EnvLang, "LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(key); v != "" {
+			// 与 gettext 一致，第一个非空的变量决定语言
+			l, _ := Parse(v)
+			return l
+		}
+	}
+	return En
+}
+
+// T 翻译消息并格式化，以英文原文作为键，当前语言没有对应译文时使用原文
+func T(format string, a ...interface{}) string {
+	if msg, ok := catalogs[Current()][format]; ok {
+		format = msg
+	}
+	if len(a) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, a...)
+}
+
+// Errorf 翻译消息并创建错误，支持 %w
+func Errorf(format string, a ...interface{}) error {
+	if msg, ok := catalogs[Current()][format]; ok {
+		format = msg
+	}
+	return fmt.Errorf(format, a...)
+}
+
+// Yes 当前语言中表示确认的输入
+func Yes(answer string) (yes, ok bool) {
+	switch strings.ToLower(answer) {
+	case "y", "yes":
+		return true, true
+	case "n", "no":
+		return false, true
+	}
+	if Current() == ZhCN {
+		switch answer {
+		case "是", "确认":
+			return true, true
+		case "否", "取消":
+			return false, true
+		}
+	}
+	return false, false
+}
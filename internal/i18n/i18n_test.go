@@ -0,0 +1,79 @@
+package i18n
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]Lang{
+		"zh_CN.UTF-8": ZhCN,
+		"zh-Hans":     ZhCN,
+		"zh":          ZhCN,
+		"en_US.UTF-8": En,
+		"C":           En,
+		"POSIX":       En,
+	}
+	for in, expected := range tests {
+		if actual, ok := Parse(in); !ok || actual != expected {
+			t.Errorf("Parse(%q) expected %s, but %s got", in, expected, actual)
+		}
+	}
+	if _, ok := Parse("fr_FR"); ok {
+		t.Error("expected fr_FR unsupported")
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Setenv(EnvLang, "")
+	t.Setenv("LC_ALL", "")
+	t.Setenv("LC_MESSAGES", "zh_CN.UTF-8")
+	t.Setenv("LANG", "en_US.UTF-8")
+	if l := Detect(); l != ZhCN {
+		t.Errorf("expected LC_MESSAGES before LANG, but %s got", l)
+	}
+	t.Setenv(EnvLang, "en")
+	if l := Detect(); l != En {
+		t.Errorf("expected %s first, but %s got", EnvLang, l)
+	}
+}
+
+func TestT(t *testing.T) {
+	defer func() { _ = SetLang(string(En)) }()
+	if msg := T("deleted tag %s", "v1.0.0"); msg != "deleted tag v1.0.0" {
+		t.Errorf("expected english message, but %q got", msg)
+	}
+	if err := SetLang("zh_CN.UTF-8"); err != nil {
+		t.Fatal(err)
+	}
+	if msg := T("deleted tag %s", "v1.0.0"); msg != "已删除标签 v1.0.0" {
+		t.Errorf("expected chinese message, but %q got", msg)
+	}
+	if msg := T("untranslated %d", 1); msg != "untranslated 1" {
+		t.Errorf("expected fallback to source, but %q got", msg)
+	}
+	cause := errors.New("not found")
+	if err := Errorf("load release plan: %w, run 'autoctl plan --write' first", cause); !errors.Is(err, cause) {
+		t.Errorf("expected wrapped error, but %v got", err)
+	}
+	if yes, ok := Yes("是"); !ok || !yes {
+		t.Errorf("expected 是 confirmed, but %v %v got", yes, ok)
+	}
+	if err := SetLang("fr"); err == nil {
+		t.Error("expected unsupported language error")
+	}
+}
+
+var verbReg = regexp.MustCompile(`%(\[\d+])?[-+# 0]*\d*(\.\d+)?[a-zA-Z%]`)
+
+// TestCatalogs 译文与原文的格式化动词数量一致
+func TestCatalogs(t *testing.T) {
+	for lang, catalog := range catalogs {
+		for src, msg := range catalog {
+			if a, b := len(verbReg.FindAllString(src, -1)), len(verbReg.FindAllString(msg, -1)); a != b {
+				t.Errorf("%s: %q has %d verbs, but translation %q has %d", lang, src, a, msg, b)
+			}
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package impact
+
+import (
+	"bytes"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// goAPI 比较变更的 Go 包在两个引用中的导出符号，internal、testdata、vendor 目录及 main 包不属于公开 API
+func goAPI(plus *git.Plus, from, to string, changed []string) ([]Finding, error) {
+	dirs := map[string]bool{}
+	for _, f := range changed {
+		if isAPIFile(f) {
+			dirs[path.Dir(f)] = true
+		}
+	}
+	names := make([]string, 0, len(dirs))
+	for dir := range dirs {
+		names = append(names, dir)
+	}
+	sort.Strings(names)
+	var findings []Finding
+	for _, dir := range names {
+		before, err := packageAPI(plus, from, dir)
+		if err != nil {
+			return nil, err
+		}
+		after, err := packageAPI(plus, to, dir)
+		if err != nil {
+			return nil, err
+		}
+		findings = append(findings, compareAPI(dir, before, after)...)
+	}
+	return findings, nil
+}
+
+func isAPIFile(file string) bool {
+	if !strings.HasSuffix(file, ".go") || strings.HasSuffix(file, "_test.go") {
+		return false
+	}
+	for _, segment := range strings.Split(path.Dir(file), "/") {
+		if segment == "internal" || segment == "testdata" || segment == "vendor" {
+			return false
+		}
+	}
+	return true
+}
+
+// compareAPI 删除或修改导出符号为 major，新增导出符号为 minor
+func compareAPI(dir string, before, after map[string]string) []Finding {
+	var findings []Finding
+	for _, name := range sortedNames(before) {
+		sig, ok := after[name]
+		switch {
+		case !ok:
+			findings = append(findings, finding(KindRemoved, dir, name, before[name]))
+		case sig != before[name]:
+			findings = append(findings, finding(KindChanged, dir, name, before[name]+" => "+sig))
+		}
+	}
+	for _, name := range sortedNames(after) {
+		if _, ok := before[name]; !ok {
+			findings = append(findings, finding(KindAdded, dir, name, after[name]))
+		}
+	}
+	return findings
+}
+
+func sortedNames(m map[string]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// packageAPI 引用中目录下 Go 包的导出符号，键为符号名称（方法及字段为 Type.Name），值为去除参数名称的签名；
+// 包不存在或为 main 包时返回空
+func packageAPI(plus *git.Plus, ref, dir string) (map[string]string, error) {
+	api := map[string]string{}
+	files, err := plus.ListFiles(ref, dir)
+	if err != nil {
+		return nil, err
+	}
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if path.Dir(file) != dir || !isAPIFile(file) {
+			continue
+		}
+		src, err := plus.Show(ref, file)
+		if err != nil {
+			return nil, err
+		}
+		f, err := parser.ParseFile(fset, file, src, 0)
+		if err != nil {
+			log.Debug("skip %s:%s: %s", ref, file, err)
+			continue
+		}
+		if f.Name.Name == "main" {
+			return map[string]string{}, nil
+		}
+		collect(fset, f, api)
+	}
+	return api, nil
+}
+
+// collect 收集文件中的导出符号
+func collect(fset *token.FileSet, f *ast.File, api map[string]string) {
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			name := d.Name.Name
+			if d.Recv != nil && len(d.Recv.List) > 0 {
+				recv := receiverName(d.Recv.List[0].Type)
+				if !ast.IsExported(recv) {
+					continue
+				}
+				name = recv + "." + name
+			}
+			api[name] = "func" + signature(fset, d.Type)
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.IsExported() {
+						collectType(fset, s, api)
+					}
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						if n.IsExported() {
+							api[n.Name] = strings.TrimSpace(d.Tok.String() + " " + exprString(fset, s.Type))
+						}
+					}
+				}
+			}
+		}
+	}
+}
+
+// collectType 结构体的导出字段及接口的方法单独作为符号，其它类型记录其定义
+func collectType(fset *token.FileSet, s *ast.TypeSpec, api map[string]string) {
+	name := s.Name.Name
+	switch t := s.Type.(type) {
+	case *ast.StructType:
+		api[name] = "struct"
+		for _, field := range t.Fields.List {
+			for _, n := range field.Names {
+				if n.IsExported() {
+					api[name+"."+n.Name] = exprString(fset, field.Type)
+				}
+			}
+			if len(field.Names) == 0 {
+				// 嵌入字段
+				api[name+"."+receiverName(field.Type)] = exprString(fset, field.Type)
+			}
+		}
+	case *ast.InterfaceType:
+		api[name] = "interface"
+		for _, method := range t.Methods.List {
+			if ft, ok := method.Type.(*ast.FuncType); ok && len(method.Names) > 0 {
+				api[name+"."+method.Names[0].Name] = "func" + signature(fset, ft)
+				continue
+			}
+			api[name+"."+exprString(fset, method.Type)] = "embedded"
+		}
+	default:
+		def := "type " + exprString(fset, s.Type)
+		if s.Assign.IsValid() {
+			def = "alias " + exprString(fset, s.Type)
+		}
+		api[name] = def
+	}
+}
+
+// signature 只保留参数及返回值类型的函数签名，参数改名不视为变化
+func signature(fset *token.FileSet, ft *ast.FuncType) string {
+	sig := "(" + strings.Join(fieldTypes(fset, ft.Params), ", ") + ")"
+	if results := fieldTypes(fset, ft.Results); len(results) > 0 {
+		sig += " (" + strings.Join(results, ", ") + ")"
+	}
+	return sig
+}
+
+func fieldTypes(fset *token.FileSet, fields *ast.FieldList) []string {
+	if fields == nil {
+		return nil
+	}
+	var types []string
+	for _, field := range fields.List {
+		t := exprString(fset, field.Type)
+		n := len(field.Names)
+		if n == 0 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// receiverName 方法接收者或嵌入字段的类型名称，去除指针、包名及泛型参数
+func receiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.IndexExpr:
+		return receiverName(t.X)
+	case *ast.IndexListExpr:
+		return receiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	default:
+		return ""
+	}
+}
+
+func exprString(fset *token.FileSet, expr ast.Expr) string {
+	if expr == nil {
+		return ""
+	}
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, expr); err != nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(buf.String()), " ")
+}
+
+// Incompatible from 与 to 之间不兼容的 Go API 变更，即删除或修改了导出符号
+func Incompatible(plus *git.Plus, from, to string) ([]Finding, error) {
+	changed, err := plus.ChangedFiles(from, to)
+	if err != nil {
+		return nil, err
+	}
+	findings, err := goAPI(plus, from, to, changed)
+	if err != nil {
+		return nil, err
+	}
+	incompatible := make([]Finding, 0, len(findings))
+	for _, f := range findings {
+		if f.Kind == KindRemoved || f.Kind == KindChanged {
+			incompatible = append(incompatible, f)
+		}
+	}
+	return incompatible, nil
+}
@@ -0,0 +1,190 @@
+// Package impact 在提交信息无法体现变更类型时（如 squash 合并后的历史），按代码差异推断版本变动类型
+package impact
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/deps"
+	"github.com/coffee377/autoctl/internal/schema"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// DefaultMinConfidence 采用推断结果的最低置信度
+const DefaultMinConfidence = 0.6
+
+// 差异的种类
+const (
+	KindRemoved    = "removed"    // 删除了导出的 Go 符号
+	KindChanged    = "changed"    // 导出的 Go 符号签名发生变化
+	KindAdded      = "added"      // 新增了导出的 Go 符号
+	KindDependency = "dependency" // package.json 中的依赖升级了主版本
+	KindOther      = "other"      // 其它文件变更
+
+	KindSchemaBreaking = "schema-breaking" // OpenAPI、protobuf 等接口定义的不兼容变更
+	KindSchemaAdded    = "schema-added"    // 接口定义新增了接口、模型或字段
+)
+
+// 各种差异的置信度，多个同级别的差异按独立证据合并
+var confidences = map[string]float64{
+	KindRemoved:    0.9,
+	KindChanged:    0.8,
+	KindAdded:      0.7,
+	KindDependency: 0.5,
+	KindOther:      0.4,
+
+	KindSchemaBreaking: 0.9,
+	KindSchemaAdded:    0.7,
+}
+
+// Config 按代码差异推断版本变动类型，对应配置文件 release.impact 节点
+type Config struct {
+	Enabled       bool     `mapstructure:"enabled"`       // 待发布的提交都不影响版本时按代码差异推断版本变动类型
+	MinConfidence float64  `mapstructure:"minConfidence"` // 采用推断结果的最低置信度，默认 0.6
+	Manifests     []string `mapstructure:"manifests"`     // 检查依赖主版本升级的 package.json，默认 package.json
+}
+
+func (c Config) minConfidence() float64 {
+	if c.MinConfidence <= 0 {
+		return DefaultMinConfidence
+	}
+	return c.MinConfidence
+}
+
+// Accept 推断结果的置信度是否达到要求
+func (c Config) Accept(r *Report) bool {
+	return r != nil && r.Release != "" && r.Confidence >= c.minConfidence()
+}
+
+// Finding 一项影响版本的差异
+type Finding struct {
+	Kind       string  `json:"kind"`             // 种类 removed | changed | added | dependency | other | schema-breaking | schema-added
+	Package    string  `json:"package"`          // Go 包目录、清单文件或接口定义文件
+	Symbol     string  `json:"symbol,omitempty"` // 符号或依赖名称，方法为 Type.Method
+	Detail     string  `json:"detail,omitempty"` // 变化前后的签名或版本
+	Impact     string  `json:"impact"`           // 发布影响 major | minor | patch
+	Confidence float64 `json:"confidence"`       // 置信度 0~1
+}
+
+// Report 代码差异推断的版本变动类型
+type Report struct {
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	Release    string    `json:"release,omitempty"` // 推断的版本变动类型，没有任何差异时为空
+	Confidence float64   `json:"confidence"`        // 推断结果的置信度，同级别差异的置信度按 1-∏(1-c) 合并
+	Findings   []Finding `json:"findings,omitempty"`
+}
+
+// Analyze 比较 from 与 to 两个引用之间的导出 Go 符号及 package.json 依赖，推断版本变动类型
+func Analyze(plus *git.Plus, cfg Config, from, to string) (*Report, error) {
+	report := &Report{From: from, To: to}
+	changed, err := plus.ChangedFiles(from, to)
+	if err != nil {
+		return nil, err
+	}
+	if len(changed) == 0 {
+		return report, nil
+	}
+	api, err := goAPI(plus, from, to, changed)
+	if err != nil {
+		return nil, err
+	}
+	report.Findings = append(report.Findings, api...)
+	dependencies, err := majorBumps(plus, cfg, from, to)
+	if err != nil {
+		return nil, err
+	}
+	report.Findings = append(report.Findings, dependencies...)
+	if len(report.Findings) == 0 {
+		report.Findings = append(report.Findings, finding(KindOther, "", "", fmt.Sprintf("%d files changed", len(changed))))
+	}
+	report.summarize()
+	return report, nil
+}
+
+func finding(kind, pkg, symbol, detail string) Finding {
+	impact := semver.Patch
+	switch kind {
+	case KindRemoved, KindChanged, KindDependency, KindSchemaBreaking:
+		impact = semver.Major
+	case KindAdded, KindSchemaAdded:
+		impact = semver.Minor
+	}
+	return Finding{Kind: kind, Package: pkg, Symbol: symbol, Detail: detail, Impact: impact.String(), Confidence: confidences[kind]}
+}
+
+// summarize 取影响最大的级别作为推断结果，并合并该级别全部差异的置信度
+func (r *Report) summarize() {
+	for _, level := range []semver.VersionChanged{semver.Major, semver.Minor, semver.Patch} {
+		miss := 1.0
+		for _, f := range r.Findings {
+			if f.Impact == level.String() {
+				miss *= 1 - f.Confidence
+			}
+		}
+		if miss < 1 {
+			r.Release = level.String()
+			r.Confidence = float64(int((1-miss)*100+0.5)) / 100
+			return
+		}
+	}
+}
+
+// Merge 合并接口定义的变更，不影响兼容性的变更（如修改描述）不作为差异
+func (r *Report) Merge(schemas *schema.Report) {
+	if schemas == nil {
+		return
+	}
+	for _, c := range schemas.Changes {
+		switch {
+		case c.Breaking:
+			r.Findings = append(r.Findings, finding(KindSchemaBreaking, c.File, c.Path, strings.TrimSpace(c.Kind+" "+c.Detail)))
+		case c.Kind == schema.KindAdded:
+			r.Findings = append(r.Findings, finding(KindSchemaAdded, c.File, c.Path, c.Detail))
+		}
+	}
+	r.summarize()
+}
+
+// ReleaseType 推断的版本变动类型
+func (r *Report) ReleaseType() (semver.VersionChanged, error) {
+	return semver.ParseReleaseType(r.Release)
+}
+
+// majorBumps package.json 中运行时依赖的主版本升级
+func majorBumps(plus *git.Plus, cfg Config, from, to string) ([]Finding, error) {
+	manifests := cfg.Manifests
+	if len(manifests) == 0 {
+		manifests = []string{"package.json"}
+	}
+	report, err := deps.Diff(plus, deps.Config{Manifests: manifests}, from, to)
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	for _, c := range report.Changes {
+		if c.Kind != deps.Updated {
+			continue
+		}
+		before, ok1 := majorOf(c.From)
+		after, ok2 := majorOf(c.To)
+		if ok1 && ok2 && after > before {
+			findings = append(findings, finding(KindDependency, c.Manifest, c.Name, c.From+" => "+c.To))
+		}
+	}
+	sort.SliceStable(findings, func(i, j int) bool { return findings[i].Symbol < findings[j].Symbol })
+	return findings, nil
+}
+
+// majorOf 版本范围中的主版本号，如 ^4.17.21 => 4
+func majorOf(v string) (int, bool) {
+	v = strings.TrimLeft(strings.TrimSpace(v), "^~>=<v ")
+	if i := strings.IndexAny(v, ".-+ "); i >= 0 {
+		v = v[:i]
+	}
+	n, err := strconv.Atoi(v)
+	return n, err == nil
+}
@@ -0,0 +1,88 @@
+package impact
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/testutil"
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+func TestAnalyze(t *testing.T) {
+	testutil.GitIdentity(t)
+	dir := t.TempDir()
+	run := func(args ...string) {
+		testutil.GitRun(t, dir, args...)
+	}
+	write := func(name, content string) {
+		_ = os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o755)
+		_ = os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644)
+	}
+	commit := func(message string) {
+		run("add", ".")
+		run("commit", "--quiet", "-m", message)
+	}
+	plus := &git.Plus{Cwd: dir}
+	run("init", "--quiet")
+	write("api.go", "package api\n\nfunc Parse(s string) error { return nil }\n\ntype Options struct {\n\tName string\n\tsize int\n}\n")
+	write("internal/x/x.go", "package x\n\nfunc Helper() {}\n")
+	write("package.json", `{"dependencies": {"lodash": "^3.10.0"}, "devDependencies": {"vite": "^4.0.0"}}`)
+	commit("init")
+	run("tag", "v1.0.0")
+
+	// 参数改名、未导出字段及 internal 包的变化不影响版本
+	write("api.go", "package api\n\nfunc Parse(text string) error { return nil }\n\ntype Options struct {\n\tName string\n\tcount int\n}\n")
+	write("internal/x/x.go", "package x\n\nfunc Helper2() {}\n")
+	commit("tidy")
+	report, err := Analyze(plus, Config{}, "v1.0.0", "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Release != "patch" || (Config{}).Accept(report) {
+		t.Fatalf("expected patch below the minimum confidence, but %+v got", report)
+	}
+
+	write("api.go", "package api\n\nfunc Parse(text string) error { return nil }\n\nfunc Format() string { return \"\" }\n\ntype Options struct {\n\tName string\n}\n")
+	commit("add format")
+	if report, err = Analyze(plus, Config{}, "v1.0.0", "HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	if report.Release != "minor" || report.Confidence != 0.7 || !(Config{}).Accept(report) {
+		t.Fatalf("expected minor with confidence 0.7, but %+v got", report)
+	}
+
+	write("api.go", "package api\n\nfunc Parse(text string, strict bool) error { return nil }\n\nfunc Format() string { return \"\" }\n")
+	write("package.json", `{"dependencies": {"lodash": "^4.17.21"}, "devDependencies": {"vite": "^5.0.0"}}`)
+	commit("rework")
+	if report, err = Analyze(plus, Config{}, "v1.0.0", "HEAD"); err != nil {
+		t.Fatal(err)
+	}
+	kinds := map[string]string{}
+	for _, f := range report.Findings {
+		kinds[f.Symbol] = f.Kind
+	}
+	want := map[string]string{"Parse": KindChanged, "Options": KindRemoved, "Options.Name": KindRemoved, "Format": KindAdded, "lodash": KindDependency}
+	for symbol, kind := range want {
+		if kinds[symbol] != kind {
+			t.Errorf("expected %s %s, but findings %+v got", symbol, kind, report.Findings)
+		}
+	}
+	if _, ok := kinds["vite"]; ok {
+		t.Error("expected dev dependencies ignored")
+	}
+	if report.Release != "major" || report.Confidence < 0.99 {
+		t.Errorf("expected major with high confidence, but %s %.2f got", report.Release, report.Confidence)
+	}
+}
+
+func TestMajorOf(t *testing.T) {
+	for v, want := range map[string]int{"^4.17.21": 4, "~1.2": 1, ">=2.0.0": 2, "v3.0.1": 3, "5": 5} {
+		if got, ok := majorOf(v); !ok || got != want {
+			t.Errorf("majorOf(%s) = %d, want %d", v, got, want)
+		}
+	}
+	if _, ok := majorOf("latest"); ok {
+		t.Error("expected latest not parsed")
+	}
+}
@@ -0,0 +1,108 @@
+package kustomize
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/coffee377/autoctl/internal/registry"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+const DefaultMessage = "chore(deploy): set image tag to %s"
+
+// Config 镜像标签更新配置，对应配置文件 kustomize 节点
+type Config struct {
+	Images    []string        `mapstructure:"images"`    // kustomization 中 images 项的 name
+	Paths     []string        `mapstructure:"paths"`     // kustomization 文件或所在目录，相对路径基于工作目录
+	TagPrefix string          `mapstructure:"tagPrefix"` // 镜像标签前缀，默认与版本号相同不带前缀
+	Verify    bool            `mapstructure:"verify"`    // 更新前检查镜像仓库中存在该标签的镜像
+	Pin       bool            `mapstructure:"pin"`       // 查询镜像摘要并写入 images 项的 digest，固定部署的镜像内容，同时检查镜像存在
+	Commit    bool            `mapstructure:"commit"`    // 更新后提交变更
+	Message   string          `mapstructure:"message"`   // 提交信息，%s 为镜像标签
+	Registry  registry.Config `mapstructure:"registry"`  // 镜像仓库访问配置
+}
+
+// Update 单个文件中单个镜像的更新结果
+type Update struct {
+	File    string `json:"file"`
+	Image   string `json:"image"`
+	Ref     string `json:"ref"`              // 更新后部署的镜像
+	Digest  string `json:"digest,omitempty"` // 固定的镜像摘要
+	Changed bool   `json:"changed"`
+}
+
+// Apply 将所有 kustomization 文件中配置的镜像标签更新为 tag，校验失败时不修改任何文件
+func Apply(cfg Config, dir, tag string) ([]Update, error) {
+	if len(cfg.Images) == 0 || len(cfg.Paths) == 0 {
+		return nil, fmt.Errorf("kustomize images and paths are required")
+	}
+	updates := make([]Update, 0)
+	for _, path := range cfg.Paths {
+		if !filepath.IsAbs(path) && dir != "" {
+			path = filepath.Join(dir, path)
+		}
+		file, err := Find(path)
+		if err != nil {
+			return nil, err
+		}
+		images, err := Images(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range cfg.Images {
+			ref := name
+			for _, image := range images {
+				if image.Name == name {
+					ref = image.Ref()
+				}
+			}
+			updates = append(updates, Update{File: file, Image: name, Ref: ref + ":" + tag})
+		}
+	}
+	if cfg.Verify || cfg.Pin {
+		digests, err := resolve(cfg.Registry, updates)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Pin {
+			for i := range updates {
+				updates[i].Digest = digests[updates[i].Ref]
+			}
+		}
+	}
+	for i, u := range updates {
+		changed, err := SetImageDigest(u.File, u.Image, tag, u.Digest)
+		if err != nil {
+			return nil, err
+		}
+		updates[i].Changed = changed
+		if changed && u.Digest != "" {
+			log.Info("%s: set image %s to %s@%s", u.File, u.Image, u.Ref, u.Digest)
+		} else if changed {
+			log.Info("%s: set image %s to %s", u.File, u.Image, u.Ref)
+		}
+	}
+	return updates, nil
+}
+
+// resolve 查询所有镜像的摘要，任一镜像不存在时失败
+func resolve(cfg registry.Config, updates []Update) (map[string]string, error) {
+	client, err := registry.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	digests := map[string]string{}
+	for _, u := range updates {
+		if _, ok := digests[u.Ref]; ok {
+			continue
+		}
+		ref, err := registry.ParseReference(u.Ref)
+		if err != nil {
+			return nil, err
+		}
+		if digests[u.Ref], err = client.Digest(ref); err != nil {
+			return nil, fmt.Errorf("verify image %s: %w", ref, err)
+		}
+	}
+	return digests, nil
+}
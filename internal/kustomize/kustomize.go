@@ -0,0 +1,196 @@
+package kustomize
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/coffee377/autoctl/internal/fileutil"
+	"github.com/coffee377/autoctl/internal/yamledit"
+	"gopkg.in/yaml.v3"
+)
+
+// FileNames kustomize 识别的配置文件名，按优先级排列
+var FileNames = []string{"kustomization.yaml", "kustomization.yml", "Kustomization"}
+
+// Image kustomization 中 images 的一项
+type Image struct {
+	Name    string `yaml:"name"`
+	NewName string `yaml:"newName,omitempty"`
+	NewTag  string `yaml:"newTag,omitempty"`
+	Digest  string `yaml:"digest,omitempty"`
+}
+
+// Ref 实际部署的镜像名称，设置 newName 时为 newName
+func (i Image) Ref() string {
+	if i.NewName != "" {
+		return i.NewName
+	}
+	return i.Name
+}
+
+// Find 返回 path 对应的 kustomization 文件，path 为目录时按 FileNames 查找
+func Find(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+	for _, name := range FileNames {
+		file := filepath.Join(path, name)
+		if _, err = os.Stat(file); err == nil {
+			return file, nil
+		}
+	}
+	return "", fmt.Errorf("no kustomization file found in %s", path)
+}
+
+// Images 读取 kustomization 文件中的 images
+func Images(file string) ([]Image, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	if data, _, err = fileutil.Decode(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	images, err := parseImages(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	return images, nil
+}
+
+func parseImages(data []byte) ([]Image, error) {
+	k := struct {
+		Images []Image `yaml:"images"`
+	}{}
+	if err := yaml.Unmarshal(data, &k); err != nil {
+		return nil, err
+	}
+	return k.Images, nil
+}
+
+// SetImage 与 kustomize edit set image name=name:tag 语义相同：设置 name 项的 newTag 并移除 digest，不存在时追加该项
+// 已有 newTag 字段时仅替换其值，保留文件原有格式，返回文件是否变更
+func SetImage(file, name, tag string) (bool, error) {
+	return SetImageDigest(file, name, tag, "")
+}
+
+// SetImageDigest 设置 name 项的 newTag 并固定摘要 digest，digest 为空时与 SetImage 相同
+func SetImageDigest(file, name, tag, digest string) (bool, error) {
+	changed, err := fileutil.Update(file, func(data []byte) ([]byte, bool, error) {
+		return setImage(data, name, tag, digest)
+	})
+	if err != nil {
+		return false, fmt.Errorf("%s: %w", file, err)
+	}
+	return changed, nil
+}
+
+func setImage(data []byte, name, tag, digest string) ([]byte, bool, error) {
+	images, err := parseImages(data)
+	if err != nil {
+		return nil, false, err
+	}
+	for _, image := range images {
+		if image.Name != name {
+			continue
+		}
+		if image.NewTag == tag && image.Digest == digest {
+			return nil, false, nil
+		}
+		// 已有的字段与目标字段相同时仅替换值
+		if image.NewTag != "" && (image.Digest == "") == (digest == "") {
+			if updated, changed, err := setFields(data, name, tag, digest); err == nil {
+				return updated, changed, nil
+			}
+		}
+		break
+	}
+	updated, err := setImageNode(data, name, tag, digest)
+	if err != nil {
+		return nil, false, err
+	}
+	return updated, true, nil
+}
+
+func setFields(data []byte, name, tag, digest string) ([]byte, bool, error) {
+	updated, tagChanged, err := yamledit.Set(data, fmt.Sprintf("images[name=%s].newTag", name), tag)
+	if err != nil || digest == "" {
+		return updated, tagChanged, err
+	}
+	updated, digestChanged, err := yamledit.Set(updated, fmt.Sprintf("images[name=%s].digest", name), digest)
+	if err != nil {
+		return nil, false, err
+	}
+	return updated, tagChanged || digestChanged, nil
+}
+
+// setImageNode 通过修改语法树设置镜像标签及摘要，会按两个空格缩进重新格式化文件
+func setImageNode(data []byte, name, tag, digest string) ([]byte, error) {
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	if len(doc.Content) == 0 {
+		doc = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("kustomization must be a mapping")
+	}
+	images := value(root, "images")
+	if images == nil {
+		images = &yaml.Node{Kind: yaml.SequenceNode}
+		root.Content = append(root.Content, scalar("images"), images)
+	}
+	var image *yaml.Node
+	for _, item := range images.Content {
+		if n := value(item, "name"); n != nil && n.Value == name {
+			image = item
+			break
+		}
+	}
+	if image == nil {
+		image = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{scalar("name"), scalar(name)}}
+		images.Content = append(images.Content, image)
+	}
+	content := make([]*yaml.Node, 0, len(image.Content))
+	for i := 0; i+1 < len(image.Content); i += 2 {
+		if k := image.Content[i].Value; k != "digest" && k != "newTag" {
+			content = append(content, image.Content[i], image.Content[i+1])
+		}
+	}
+	image.Content = append(content, scalar("newTag"), &yaml.Node{Kind: yaml.ScalarNode, Value: tag, Style: yaml.DoubleQuotedStyle})
+	if digest != "" {
+		image.Content = append(image.Content, scalar("digest"), scalar(digest))
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), encoder.Close()
+}
+
+func value(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func scalar(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: s}
+}
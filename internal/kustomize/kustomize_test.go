@@ -0,0 +1,98 @@
+package kustomize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetImage(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			"replace newTag",
+			"resources:\n  - deploy.yaml\nimages:\n  - name: app # app image\n    newName: ghcr.io/org/app\n    newTag: 1.0.0\n",
+			"resources:\n  - deploy.yaml\nimages:\n  - name: app # app image\n    newName: ghcr.io/org/app\n    newTag: 1.1.0\n",
+		},
+		{
+			"replace digest",
+			"images:\n  - name: app\n    digest: sha256:abc\n",
+			"images:\n  - name: app\n    newTag: \"1.1.0\"\n",
+		},
+		{
+			"keep bom and crlf",
+			"\xEF\xBB\xBFimages:\r\n  - name: app\r\n    newTag: 1.0.0 # release\r\n",
+			"\xEF\xBB\xBFimages:\r\n  - name: app\r\n    newTag: 1.1.0 # release\r\n",
+		},
+		{
+			"append image",
+			"resources:\n  - deploy.yaml\n",
+			"resources:\n  - deploy.yaml\nimages:\n  - name: app\n    newTag: \"1.1.0\"\n",
+		},
+	}
+	for _, test := range tests {
+		dir := t.TempDir()
+		_ = os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(test.content), 0o644)
+		file, err := Find(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		changed, err := SetImage(file, "app", "1.1.0")
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		data, _ := os.ReadFile(file)
+		if !changed || string(data) != test.expected {
+			t.Errorf("%s: expected\n%s\nbut got\n%s", test.name, test.expected, data)
+		}
+		if changed, _ = SetImage(file, "app", "1.1.0"); changed {
+			t.Errorf("%s: expected unchanged on second run", test.name)
+		}
+	}
+}
+
+func TestSetImageDigest(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "kustomization.yaml")
+	_ = os.WriteFile(file, []byte("images:\n  - name: app # app image\n    newTag: 1.0.0\n"), 0o644)
+	if changed, err := SetImageDigest(file, "app", "1.1.0", "sha256:abc"); err != nil || !changed {
+		t.Fatalf("expected digest pinned, but %v %v got", changed, err)
+	}
+	data, _ := os.ReadFile(file)
+	if string(data) != "images:\n  - name: app # app image\n    newTag: \"1.1.0\"\n    digest: sha256:abc\n" {
+		t.Errorf("unexpected kustomization\n%s", data)
+	}
+	// 已固定摘要时仅替换值，保留原有格式
+	_ = os.WriteFile(file, []byte("images:\n  - name: app # app image\n    newTag: 1.1.0\n    digest: sha256:abc\n"), 0o644)
+	if changed, err := SetImageDigest(file, "app", "1.2.0", "sha256:def"); err != nil || !changed {
+		t.Fatalf("expected digest updated, but %v %v got", changed, err)
+	}
+	data, _ = os.ReadFile(file)
+	if string(data) != "images:\n  - name: app # app image\n    newTag: 1.2.0\n    digest: sha256:def\n" {
+		t.Errorf("unexpected kustomization\n%s", data)
+	}
+	if changed, _ := SetImageDigest(file, "app", "1.2.0", "sha256:def"); changed {
+		t.Error("expected unchanged on second run")
+	}
+}
+
+func TestApply(t *testing.T) {
+	dir := t.TempDir()
+	for _, env := range []string{"staging", "prod"} {
+		_ = os.MkdirAll(filepath.Join(dir, env), 0o755)
+		_ = os.WriteFile(filepath.Join(dir, env, "kustomization.yaml"), []byte("images:\n  - name: app\n    newName: ghcr.io/org/app\n    newTag: 1.0.0\n"), 0o644)
+	}
+	updates, err := Apply(Config{Images: []string{"app"}, Paths: []string{"staging", "prod"}}, dir, "1.1.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 2 || !updates[1].Changed || updates[1].Ref != "ghcr.io/org/app:1.1.0" {
+		t.Errorf("unexpected updates %+v", updates)
+	}
+	if _, err = Apply(Config{Images: []string{"app"}, Paths: []string{"dev"}}, dir, "1.1.0"); err == nil {
+		t.Error("expected missing kustomization error, got nil")
+	}
+}
@@ -0,0 +1,208 @@
+package lock
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/runctx"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+const (
+	BackendGit  = "git"
+	BackendNone = "none"
+
+	DefaultRef      = "refs/autoctl/release-lock"
+	DefaultTTL      = 30 * time.Minute
+	DefaultWait     = 5 * time.Minute
+	DefaultInterval = 10 * time.Second
+)
+
+// ErrLocked 锁被其它发布任务持有
+var ErrLocked = errors.New("release is locked")
+
+// Config 发布锁配置，对应配置文件 release.lock 节点
+type Config struct {
+	Enabled  bool          `mapstructure:"enabled"`  // 推送标签前获取发布锁，避免并发的流水线计算出相同的版本
+	Backend  string        `mapstructure:"backend"`  // git（默认，在远程仓库中创建引用）| none
+	Ref      string        `mapstructure:"ref"`      // 锁引用，默认 refs/autoctl/release-lock
+	TTL      time.Duration `mapstructure:"ttl"`      // 锁超过该时长视为失效，可被其它任务接管，默认 30m
+	Wait     time.Duration `mapstructure:"wait"`     // 等待锁释放的最长时间，默认 5m，为负数时不等待
+	Interval time.Duration `mapstructure:"interval"` // 等待时检查锁的间隔，默认 10s
+}
+
+func (c Config) withDefaults() Config {
+	if c.Backend == "" {
+		c.Backend = BackendGit
+	}
+	if c.Ref == "" {
+		c.Ref = DefaultRef
+	}
+	if c.TTL <= 0 {
+		c.TTL = DefaultTTL
+	}
+	if c.Wait == 0 {
+		c.Wait = DefaultWait
+	}
+	if c.Interval <= 0 {
+		c.Interval = DefaultInterval
+	}
+	return c
+}
+
+// Lock 已获取的锁
+type Lock interface {
+	// Release 释放锁，锁已被其它任务接管时不做任何处理
+	Release() error
+}
+
+// Locker 锁后端
+type Locker interface {
+	// Acquire 获取锁，owner 为持有者描述，锁被持有时等待直到超时
+	Acquire(owner string) (Lock, error)
+}
+
+// New 根据配置创建锁后端，remote 为存放锁的远程仓库
+func New(cfg Config, plus *git.Plus, remote string) (Locker, error) {
+	cfg = cfg.withDefaults()
+	switch cfg.Backend {
+	case BackendGit:
+		return &gitLocker{cfg: cfg, plus: plus, remote: remote}, nil
+	case BackendNone:
+		return noop{}, nil
+	}
+	return nil, fmt.Errorf("unsupported lock backend %q, valid values are %s, %s", cfg.Backend, BackendGit, BackendNone)
+}
+
+// Owner 当前任务的描述，包含主机、进程及 CI 任务信息
+func Owner() string {
+	host, _ := os.Hostname()
+	parts := []string{fmt.Sprintf("%s:%d", host, os.Getpid())}
+	for _, env := range []string{"GITHUB_RUN_ID", "CI_JOB_ID", "BUILD_ID"} {
+		if v := os.Getenv(env); v != "" {
+			parts = append(parts, env+"="+v)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+type noop struct{}
+
+func (noop) Acquire(string) (Lock, error) { return noop{}, nil }
+
+func (noop) Release() error { return nil }
+
+// gitLocker 在远程仓库中以引用实现的锁，通过 --force-with-lease 保证创建与删除的原子性
+type gitLocker struct {
+	cfg    Config
+	plus   *git.Plus
+	remote string
+}
+
+type gitLock struct {
+	locker *gitLocker
+	object string
+}
+
+func (l *gitLocker) Acquire(owner string) (Lock, error) {
+	deadline := time.Now().Add(l.cfg.Wait)
+	for {
+		object, err := l.plus.CommitTree(fmt.Sprintf("autoctl release lock\n\nowner: %s\ntime: %d", owner, time.Now().Unix()))
+		if err != nil {
+			return nil, err
+		}
+		current, err := l.plus.RemoteRef(l.remote, l.cfg.Ref)
+		if err != nil {
+			return nil, err
+		}
+		expect := ""
+		if current != "" {
+			holder, created, err := l.holder()
+			if err != nil {
+				return nil, err
+			}
+			if time.Since(created) <= l.cfg.TTL {
+				if l.cfg.Wait < 0 || time.Now().After(deadline) {
+					return nil, fmt.Errorf("%w by %s since %s", ErrLocked, holder, created.Format(time.RFC3339))
+				}
+				log.Info("release is locked by %s, retry in %s", holder, l.cfg.Interval)
+				if err = runctx.Sleep(l.cfg.Interval); err != nil {
+					return nil, fmt.Errorf("wait for release lock: %w", err)
+				}
+				continue
+			}
+			log.Warn("release lock held by %s since %s expired, take it over", holder, created.Format(time.RFC3339))
+			expect = current
+		}
+		if err = l.plus.CompareAndSwapRef(l.remote, l.cfg.Ref, expect, object); err != nil {
+			if !leaseRejected(err) {
+				return nil, fmt.Errorf("acquire release lock: %w", err)
+			}
+			// 其它任务抢先获取了锁
+			log.Debug("acquire release lock: %s", err)
+			if l.cfg.Wait < 0 || time.Now().After(deadline) {
+				return nil, fmt.Errorf("%w: %s", ErrLocked, err)
+			}
+			log.Info("release lock was taken by another job, retry in %s", l.cfg.Interval)
+			if err = runctx.Sleep(l.cfg.Interval); err != nil {
+				return nil, fmt.Errorf("wait for release lock: %w", err)
+			}
+			continue
+		}
+		log.Debug("acquired release lock %s on %s", l.cfg.Ref, l.remote)
+		return &gitLock{locker: l, object: object}, nil
+	}
+}
+
+// leaseRejected 推送是否因远程引用已被其它任务修改而被拒绝，其它错误（如无权限、网络故障）重试无意义
+func leaseRejected(err error) bool {
+	msg := err.Error()
+	for _, s := range []string{"stale info", "cannot lock ref", "failed to lock", "failed to update ref"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// holder 读取远程锁的持有者及创建时间
+func (l *gitLocker) holder() (owner string, created time.Time, err error) {
+	object, err := l.plus.FetchRef(l.remote, l.cfg.Ref)
+	if err != nil {
+		return "", created, err
+	}
+	output, err := l.plus.Run("log", "-1", "--format=%B", object)
+	if err != nil {
+		return "", created, err
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if k, v, ok := strings.Cut(line, ": "); ok {
+			switch k {
+			case "owner":
+				owner = v
+			case "time":
+				ts, _ := strconv.ParseInt(v, 10, 64)
+				created = time.Unix(ts, 0)
+			}
+		}
+	}
+	return owner, created, nil
+}
+
+func (l *gitLock) Release() error {
+	err := l.locker.plus.CompareAndSwapRef(l.locker.remote, l.locker.cfg.Ref, l.object, "")
+	if err != nil && leaseRejected(err) {
+		log.Warn("release lock was taken over by another job")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	log.Debug("released release lock %s on %s", l.locker.cfg.Ref, l.locker.remote)
+	return nil
+}
@@ -0,0 +1,96 @@
+package lock
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/testutil"
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+func newRepo(t *testing.T) *git.Plus {
+	t.Helper()
+	root := t.TempDir()
+	repo := testutil.NewRepo(t, filepath.Join(root, "repo"))
+	testutil.GitRun(t, root, "init", "-q", "--bare", "origin.git")
+	testutil.GitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: init")
+	testutil.GitRun(t, repo, "remote", "add", "origin", filepath.Join(root, "origin.git"))
+	return &git.Plus{Cwd: repo}
+}
+
+func TestGitLocker(t *testing.T) {
+	plus := newRepo(t)
+	a, _ := New(Config{}, plus, "origin")
+	b, _ := New(Config{Wait: -1}, plus, "origin")
+	lockA, err := a.Acquire("job-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = b.Acquire("job-b"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected locked error, but %v got", err)
+	}
+	if err = lockA.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if object, _ := plus.RemoteRef("origin", DefaultRef); object != "" {
+		t.Fatalf("expected lock ref deleted, but %s got", object)
+	}
+	if _, err = b.Acquire("job-b"); err != nil {
+		t.Fatalf("expected lock acquired after release, but %v got", err)
+	}
+}
+
+func TestGitLocker_Wait(t *testing.T) {
+	plus := newRepo(t)
+	a, _ := New(Config{}, plus, "origin")
+	lockA, err := a.Acquire("job-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.AfterFunc(50*time.Millisecond, func() { _ = lockA.Release() })
+	b, _ := New(Config{Wait: 5 * time.Second, Interval: 20 * time.Millisecond}, plus, "origin")
+	if _, err = b.Acquire("job-b"); err != nil {
+		t.Fatalf("expected lock acquired after waiting, but %v got", err)
+	}
+}
+
+func TestGitLocker_Expired(t *testing.T) {
+	plus := newRepo(t)
+	a, _ := New(Config{}, plus, "origin")
+	lockA, err := a.Acquire("job-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := New(Config{Wait: -1, TTL: time.Nanosecond}, plus, "origin")
+	lockB, err := b.Acquire("job-b")
+	if err != nil {
+		t.Fatalf("expected expired lock taken over, but %v got", err)
+	}
+	// 锁已被接管，释放时不删除其它任务的锁
+	if err = lockA.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if object, _ := plus.RemoteRef("origin", DefaultRef); object != lockB.(*gitLock).object {
+		t.Errorf("expected lock held by job-b, but %s got", object)
+	}
+}
+
+func TestGitLocker_FailFast(t *testing.T) {
+	plus := newRepo(t)
+	hook := filepath.Join(filepath.Dir(plus.Cwd), "origin.git", "hooks", "pre-receive")
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\necho denied >&2\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	l, _ := New(Config{Wait: time.Minute, Interval: time.Minute}, plus, "origin")
+	start := time.Now()
+	_, err := l.Acquire("job-a")
+	if err == nil || errors.Is(err, ErrLocked) {
+		t.Fatalf("expected push error returned, but %v got", err)
+	}
+	if elapsed := time.Since(start); elapsed > 30*time.Second {
+		t.Errorf("expected no retry on rejected push, but waited %s", elapsed)
+	}
+}
@@ -0,0 +1,225 @@
+// Package metrics 以 Prometheus 文本格式提供发布指标：发布后推送到 Pushgateway，autoctl serve 通过 /metrics 暴露，
+// 便于对失败或长时间未完成发布的流水线告警
+package metrics
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/httpclient"
+)
+
+// DefaultJob 默认的 Pushgateway 任务名称
+const DefaultJob = "autoctl"
+
+// Config 发布指标，对应配置文件 metrics 节点
+type Config struct {
+	Pushgateway string            `mapstructure:"pushgateway"` // Pushgateway 地址，如 http://pushgateway:9091，为空时不推送
+	Job         string            `mapstructure:"job"`         // 分组的 job 标签，默认 autoctl
+	Grouping    map[string]string `mapstructure:"grouping"`    // 附加的分组标签，如 team、environment，仓库始终作为 repository 分组标签
+	Username    string            `mapstructure:"username"`    // Basic 认证用户名
+	Password    string            `mapstructure:"password"`    // Basic 认证密码
+	HTTP        httpclient.Config `mapstructure:"http"`        // 代理及 TLS 配置，未设置的字段继承全局 http 节点
+}
+
+// Release 一次发布的结果
+type Release struct {
+	Repository string        // 仓库 owner/name，未配置代码托管平台时为空
+	Version    string        // 发布的版本
+	Previous   string        // 上一个版本，首次发布时为空
+	Tag        string        // 发布的标签
+	Success    bool          // 是否成功
+	Duration   time.Duration // 耗时
+	Time       time.Time     // 结束时间
+
+	lastSuccess time.Time // 失败时为同一仓库上一次成功发布的结束时间
+}
+
+var escaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// labels 标签按名称排序后输出
+type labels map[string]string
+
+func (l labels) String() string {
+	if len(l) == 0 {
+		return ""
+	}
+	names := make([]string, 0, len(l))
+	for name := range l {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	pairs := make([]string, 0, len(names))
+	for _, name := range names {
+		pairs = append(pairs, name+`="`+escaper.Replace(l[name])+`"`)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// Write 以 Prometheus 文本格式写入发布指标，totals 为各仓库按状态累计的发布次数，推送时为空
+func Write(w io.Writer, releases []Release, totals map[string]map[string]int) error {
+	b := &bytes.Buffer{}
+	gauge := func(name, help string, value func(Release) (float64, bool), extra func(Release) labels) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n", name, help, name)
+		for _, rel := range releases {
+			v, ok := value(rel)
+			if !ok {
+				continue
+			}
+			l := labels{"repository": rel.Repository}
+			if extra != nil {
+				for k, v := range extra(rel) {
+					l[k] = v
+				}
+			}
+			fmt.Fprintf(b, "%s%s %s\n", name, l, strconv.FormatFloat(v, 'g', -1, 64))
+		}
+	}
+	gauge("autoctl_release_info", "Version of the last release.", func(Release) (float64, bool) { return 1, true }, func(rel Release) labels {
+		return labels{"version": rel.Version, "previous": rel.Previous, "tag": rel.Tag}
+	})
+	gauge("autoctl_release_success", "Whether the last release succeeded.", func(rel Release) (float64, bool) {
+		if rel.Success {
+			return 1, true
+		}
+		return 0, true
+	}, nil)
+	gauge("autoctl_release_duration_seconds", "Duration of the last release.", func(rel Release) (float64, bool) {
+		return rel.Duration.Seconds(), true
+	}, nil)
+	gauge("autoctl_release_last_timestamp_seconds", "Time the last release finished.", func(rel Release) (float64, bool) {
+		return float64(rel.Time.Unix()), true
+	}, nil)
+	gauge("autoctl_release_last_success_timestamp_seconds", "Time the last successful release finished.", func(rel Release) (float64, bool) {
+		if rel.Success {
+			return float64(rel.Time.Unix()), true
+		}
+		return float64(rel.lastSuccess.Unix()), !rel.lastSuccess.IsZero()
+	}, nil)
+	if totals != nil {
+		b.WriteString("# HELP autoctl_releases_total Number of releases by status.\n# TYPE autoctl_releases_total counter\n")
+		repositories := make([]string, 0, len(totals))
+		for repository := range totals {
+			repositories = append(repositories, repository)
+		}
+		sort.Strings(repositories)
+		for _, repository := range repositories {
+			for _, status := range []string{"success", "failed"} {
+				fmt.Fprintf(b, "autoctl_releases_total%s %d\n", labels{"repository": repository, "status": status}, totals[repository][status])
+			}
+		}
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// Push 将发布指标推送到 Pushgateway，以 job 及 repository 分组；
+// 使用 POST 只替换同名指标，失败的发布保留上一次成功发布的时间
+func Push(cfg Config, rel Release) error {
+	if cfg.Pushgateway == "" {
+		return nil
+	}
+	job := cfg.Job
+	if job == "" {
+		job = DefaultJob
+	}
+	grouping := labels{"repository": rel.Repository}
+	for k, v := range cfg.Grouping {
+		grouping[k] = v
+	}
+	target := strings.TrimSuffix(cfg.Pushgateway, "/") + "/metrics/job/" + url.PathEscape(job)
+	names := make([]string, 0, len(grouping))
+	for name := range grouping {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		switch value := grouping[name]; {
+		case value == "":
+			// 空值及包含 / 的值按 Pushgateway 的约定以 base64 编码
+			target += "/" + name + "@base64/="
+		case strings.Contains(value, "/"):
+			target += "/" + name + "@base64/" + base64.RawURLEncoding.EncodeToString([]byte(value))
+		default:
+			target += "/" + name + "/" + url.PathEscape(value)
+		}
+	}
+	body := &bytes.Buffer{}
+	if err := Write(body, []Release{rel}, nil); err != nil {
+		return err
+	}
+	client, err := httpclient.New(cfg.HTTP)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, target, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	req.Header.Set("User-Agent", "autoctl")
+	if cfg.Username != "" {
+		req.SetBasicAuth(cfg.Username, cfg.Password)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("push release metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("push release metrics: %d %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
+
+var (
+	mu     sync.Mutex
+	last   = map[string]Release{}        // 仓库 => 最近一次发布
+	totals = map[string]map[string]int{} // 仓库 => 状态 => 次数
+)
+
+// Observe 记录本进程内的发布，autoctl serve 通过 Handler 暴露
+func Observe(rel Release) {
+	mu.Lock()
+	defer mu.Unlock()
+	if prev, ok := last[rel.Repository]; ok && !rel.Success {
+		rel.lastSuccess = prev.lastSuccess
+		if prev.Success {
+			rel.lastSuccess = prev.Time
+		}
+	}
+	last[rel.Repository] = rel
+	if totals[rel.Repository] == nil {
+		totals[rel.Repository] = map[string]int{}
+	}
+	status := "failed"
+	if rel.Success {
+		status = "success"
+	}
+	totals[rel.Repository][status]++
+}
+
+// Handler 以 Prometheus 文本格式输出本进程内记录的发布指标
+func Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		releases := make([]Release, 0, len(last))
+		for _, rel := range last {
+			releases = append(releases, rel)
+		}
+		sort.Slice(releases, func(i, j int) bool { return releases[i].Repository < releases[j].Repository })
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		_ = Write(w, releases, totals)
+	})
+}
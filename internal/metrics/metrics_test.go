@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPush(t *testing.T) {
+	var path, body, user string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		path, body = r.URL.EscapedPath(), string(data)
+		user, _, _ = r.BasicAuth()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	finished := time.Unix(1700000000, 0)
+	rel := Release{Repository: "org/app", Version: "1.2.0", Previous: "1.1.0", Tag: "v1.2.0", Duration: 90 * time.Second, Time: finished}
+	cfg := Config{Pushgateway: server.URL + "/", Grouping: map[string]string{"team": "platform"}, Username: "ci"}
+	if err := Push(cfg, rel); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/metrics/job/autoctl/repository@base64/b3JnL2FwcA/team/platform" || user != "ci" {
+		t.Errorf("unexpected grouping path %s or user %s", path, user)
+	}
+	for _, line := range []string{
+		`autoctl_release_info{previous="1.1.0",repository="org/app",tag="v1.2.0",version="1.2.0"} 1`,
+		`autoctl_release_success{repository="org/app"} 0`,
+		`autoctl_release_duration_seconds{repository="org/app"} 90`,
+		`autoctl_release_last_timestamp_seconds{repository="org/app"} 1.7e+09`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected %s, but got\n%s", line, body)
+		}
+	}
+	if strings.Contains(body, "autoctl_release_last_success_timestamp_seconds{") {
+		t.Errorf("expected no last success time pushed for a failed release, but got\n%s", body)
+	}
+
+	if err := Push(Config{}, rel); err != nil {
+		t.Errorf("expected nothing pushed without pushgateway, but %v got", err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	defer func() { last, totals = map[string]Release{}, map[string]map[string]int{} }()
+	succeeded := time.Unix(1700000000, 0)
+	Observe(Release{Repository: "org/app", Version: "1.1.0", Success: true, Time: succeeded})
+	Observe(Release{Repository: "org/app", Version: "1.2.0", Time: succeeded.Add(time.Hour)})
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rec.Body.String()
+	for _, line := range []string{
+		`autoctl_release_success{repository="org/app"} 0`,
+		`autoctl_release_last_success_timestamp_seconds{repository="org/app"} 1.7e+09`,
+		`autoctl_releases_total{repository="org/app",status="success"} 1`,
+		`autoctl_releases_total{repository="org/app",status="failed"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("expected %s, but got\n%s", line, body)
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package multirepo
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/deps"
+	"github.com/coffee377/autoctl/internal/progress"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/internal/tmpl"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	DefaultFile    = "releases.yaml"
+	DefaultDir     = ".autoctl/repos"
+	DefaultBranch  = "main"
+	DefaultRemote  = "origin"
+	DefaultMessage = "chore(deps): bump {{ .Names }}"
+)
+
+// Repository 参与发布的仓库
+type Repository struct {
+	Name      string   `yaml:"name" json:"name"`                               // 仓库名称，dependsOn 中引用
+	URL       string   `yaml:"url,omitempty" json:"url,omitempty"`             // 克隆地址，为空时直接使用 path 目录
+	Path      string   `yaml:"path,omitempty" json:"path,omitempty"`           // 本地目录，相对于编排文件所在目录，默认 <dir>/<name>
+	Branch    string   `yaml:"branch,omitempty" json:"branch,omitempty"`       // 发布分支，默认 main
+	Release   string   `yaml:"release,omitempty" json:"release,omitempty"`     // 版本变动类型，默认 patch
+	PreId     string   `yaml:"preid,omitempty" json:"preid,omitempty"`         // 预发布版本标识符
+	TagPrefix string   `yaml:"tagPrefix,omitempty" json:"tagPrefix,omitempty"` // 标签前缀，默认 v
+	Module    string   `yaml:"module,omitempty" json:"module,omitempty"`       // 被依赖时的 Go 模块路径或 npm 包名，默认读取 go.mod 或 package.json
+	DependsOn []string `yaml:"dependsOn,omitempty" json:"dependsOn,omitempty"` // 依赖的仓库，先于本仓库发布
+	Sparse    []string `yaml:"sparse,omitempty" json:"sparse,omitempty"`       // 部分克隆时除根目录文件外需要检出的目录，如清单文件所在的 packages/app
+}
+
+// File 多仓库发布编排文件，默认 releases.yaml
+type File struct {
+	Dir          string       `yaml:"dir,omitempty"`     // 克隆仓库的目录，默认 .autoctl/repos
+	Message      string       `yaml:"message,omitempty"` // 更新依赖版本的提交信息模板
+	Partial      bool         `yaml:"partial,omitempty"` // 部分克隆（blob:none）并稀疏检出，只下载提交信息及检出的清单文件，适用于大型仓库
+	Repositories []Repository `yaml:"repositories"`
+
+	base string // 编排文件所在目录
+}
+
+// Options 发布选项
+type Options struct {
+	Push       bool   // 推送依赖更新提交及标签
+	DryRun     bool   // 只计算版本，不修改任何仓库
+	OnNoChange string // 没有可发布的提交时的处理方式，见 release.NoChangeNames
+	Verbose    bool
+}
+
+// Result 单个仓库的发布结果
+type Result struct {
+	Name     string   `json:"name"`
+	Previous string   `json:"previous,omitempty"`
+	Version  string   `json:"version"`
+	Tag      string   `json:"tag"`
+	Bumped   []string `json:"bumped,omitempty"` // 更新了依赖版本的清单文件
+	Skipped  bool     `json:"skipped,omitempty"`
+	DryRun   bool     `json:"dryRun,omitempty"`
+}
+
+// Load 读取编排文件
+func Load(name string) (*File, error) {
+	data, err := os.ReadFile(name)
+	if err != nil {
+		return nil, err
+	}
+	f := &File{}
+	if err = yaml.Unmarshal(data, f); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return nil, err
+	}
+	f.base = filepath.Dir(abs)
+	if f.Dir == "" {
+		f.Dir = DefaultDir
+	}
+	if f.Message == "" {
+		f.Message = DefaultMessage
+	}
+	for i := range f.Repositories {
+		r := &f.Repositories[i]
+		if r.Name == "" {
+			return nil, fmt.Errorf("%s: repository %d has no name", name, i+1)
+		}
+		if r.URL == "" && r.Path == "" {
+			return nil, fmt.Errorf("%s: repository %s requires url or path", name, r.Name)
+		}
+		if r.Branch == "" {
+			r.Branch = DefaultBranch
+		}
+		if r.Release == "" {
+			r.Release = "patch"
+		}
+	}
+	return f, nil
+}
+
+// RepoDir 仓库的本地目录
+func (f *File) RepoDir(r Repository) string {
+	p := r.Path
+	if p == "" {
+		p = filepath.Join(f.Dir, r.Name)
+	}
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(f.base, p)
+}
+
+// Order 按依赖关系排序，被依赖的仓库在前，无依赖关系时保持文件中的顺序
+func (f *File) Order() ([]Repository, error) {
+	index := make(map[string]int, len(f.Repositories))
+	for i, r := range f.Repositories {
+		if _, ok := index[r.Name]; ok {
+			return nil, fmt.Errorf("duplicate repository %s", r.Name)
+		}
+		index[r.Name] = i
+	}
+	for _, r := range f.Repositories {
+		for _, d := range r.DependsOn {
+			if _, ok := index[d]; !ok {
+				return nil, fmt.Errorf("repository %s depends on unknown repository %s", r.Name, d)
+			}
+		}
+	}
+	const (
+		visiting = iota + 1
+		done
+	)
+	state := make([]int, len(f.Repositories))
+	ordered := make([]Repository, 0, len(f.Repositories))
+	var visit func(i int, path []string) error
+	visit = func(i int, path []string) error {
+		r := f.Repositories[i]
+		switch state[i] {
+		case done:
+			return nil
+		case visiting:
+			return fmt.Errorf("dependency cycle: %s", strings.Join(append(path, r.Name), " -> "))
+		}
+		state[i] = visiting
+		path = append(append([]string{}, path...), r.Name)
+		for _, d := range r.DependsOn {
+			if err := visit(index[d], path); err != nil {
+				return err
+			}
+		}
+		state[i] = done
+		ordered = append(ordered, r)
+		return nil
+	}
+	for i := range f.Repositories {
+		if err := visit(i, nil); err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// Release 按依赖顺序同步并发布所有仓库，发布前将依赖仓库的版本更新为本次发布的版本
+func Release(f *File, opts Options) ([]*Result, error) {
+	ordered, err := f.Order()
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*Result, 0, len(ordered))
+	released := map[string]*Result{}
+	modules := map[string]string{}
+	for i, r := range ordered {
+		task := progress.Start("[%d/%d] %s", i+1, len(ordered), r.Name)
+		res, err := f.releaseRepo(r, released, modules, opts)
+		task.Stop()
+		if err != nil {
+			return results, fmt.Errorf("%s: %w", r.Name, err)
+		}
+		results = append(results, res)
+		released[r.Name] = res
+	}
+	return results, nil
+}
+
+// releaseRepo 同步并发布单个仓库
+func (f *File) releaseRepo(r Repository, released map[string]*Result, modules map[string]string, opts Options) (*Result, error) {
+	dir := f.RepoDir(r)
+	plus := &git.Plus{Cwd: dir, Verbose: opts.Verbose}
+	if err := sync(plus, r, dir, f.Partial); err != nil {
+		return nil, err
+	}
+	name, err := moduleName(r, dir)
+	if err != nil {
+		return nil, err
+	}
+	modules[r.Name] = name
+	return f.release(plus, r, dir, released, modules, opts)
+}
+
+func (f *File) release(plus *git.Plus, r Repository, dir string, released map[string]*Result, modules map[string]string, opts Options) (*Result, error) {
+	versions := map[string]string{}
+	names := make([]string, 0, len(r.DependsOn))
+	for _, d := range r.DependsOn {
+		if dep := released[d]; dep != nil && !dep.Skipped && modules[d] != "" {
+			versions[modules[d]] = dep.Version
+			names = append(names, fmt.Sprintf("%s to %s", modules[d], dep.Version))
+		}
+	}
+	sort.Strings(names)
+	bumped, err := deps.Bump(dir, versions, opts.DryRun)
+	if err != nil {
+		return nil, err
+	}
+	if len(bumped) > 0 && !opts.DryRun {
+		message, err := render(f.Message, strings.Join(names, ", "))
+		if err != nil {
+			return nil, err
+		}
+		if err = plus.Commit(message, bumped...); err != nil {
+			return nil, err
+		}
+		log.Info("%s: bumped %s", r.Name, strings.Join(names, ", "))
+		if opts.Push {
+			if err = plus.Push(DefaultRemote, "HEAD:refs/heads/"+r.Branch, false); err != nil {
+				return nil, err
+			}
+		}
+	}
+	changed, err := semver.ParseReleaseType(r.Release)
+	if err != nil {
+		return nil, err
+	}
+	res, err := release.New(release.Options{
+		Cwd:        dir,
+		Release:    changed,
+		PreId:      r.PreId,
+		TagPrefix:  r.TagPrefix,
+		Push:       opts.Push,
+		OnNoChange: opts.OnNoChange,
+		DryRun:     opts.DryRun,
+		Verbose:    opts.Verbose,
+	}).Release()
+	if err != nil {
+		return nil, err
+	}
+	result := &Result{Name: r.Name, Version: res.Version.String(), Tag: res.Tag, Bumped: bumped, Skipped: res.Skipped, DryRun: res.DryRun}
+	if res.Previous != nil {
+		result.Previous = res.Previous.String()
+	}
+	return result, nil
+}
+
+// sync 克隆仓库或快进到远程分支的最新提交，未设置克隆地址时直接使用本地目录
+func sync(plus *git.Plus, r Repository, dir string, partial bool) error {
+	if r.URL == "" {
+		return nil
+	}
+	if !git.IsRepository(dir) {
+		log.Info("%s: clone %s", r.Name, r.URL)
+		if err := os.MkdirAll(filepath.Dir(dir), 0o755); err != nil {
+			return err
+		}
+		parent := &git.Plus{Cwd: filepath.Dir(dir), Verbose: plus.Verbose}
+		task := progress.Start("%s: clone %s", r.Name, log.Redact(r.URL))
+		defer task.Stop()
+		return parent.CloneWith(r.URL, dir, cloneOptions(r, partial))
+	}
+	return plus.Pull(DefaultRemote, r.Branch)
+}
+
+// cloneOptions 部分克隆时不下载历史中的文件内容，并只检出根目录的清单文件及 sparse 中的目录
+func cloneOptions(r Repository, partial bool) git.CloneOptions {
+	opts := git.CloneOptions{Branch: r.Branch}
+	if partial || len(r.Sparse) > 0 {
+		opts.Filter = "blob:none"
+		opts.Sparse = true
+		opts.Paths = r.Sparse
+	}
+	return opts
+}
+
+// moduleName 仓库被依赖时的名称，优先使用 go.mod 中的模块路径
+func moduleName(r Repository, dir string) (string, error) {
+	if r.Module != "" {
+		return r.Module, nil
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil {
+		for _, line := range strings.Split(string(data), "\n") {
+			if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "module" {
+				return strings.Trim(fields[1], `"`), nil
+			}
+		}
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if os.IsNotExist(err) {
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	pkg := struct {
+		Name string `json:"name"`
+	}{}
+	if err = json.Unmarshal(data, &pkg); err != nil {
+		return "", fmt.Errorf("package.json: %w", err)
+	}
+	return pkg.Name, nil
+}
+
+func render(text, names string) (string, error) {
+	return tmpl.Render("message", text, struct{ Names string }{names})
+}
+
+// Versions 已同步到本地的仓库被依赖时的名称及最新版本，用于更新其它仓库中的依赖版本
+func Versions(f *File) (map[string]string, error) {
+	versions := map[string]string{}
+	for _, r := range f.Repositories {
+		dir := f.RepoDir(r)
+		if _, err := os.Stat(dir); err != nil {
+			continue
+		}
+		name, err := moduleName(r, dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Name, err)
+		}
+		if name == "" {
+			continue
+		}
+		releaser := release.New(release.Options{Cwd: dir, TagPrefix: r.TagPrefix})
+		current, err := releaser.Current()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Name, err)
+		}
+		if current != nil {
+			versions[name] = current.String()
+		}
+	}
+	return versions, nil
+}
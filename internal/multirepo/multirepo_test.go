@@ -0,0 +1,130 @@
+package multirepo
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/testutil"
+)
+
+// newRemote 创建包含 files 的裸仓库，返回仓库地址
+func newRemote(t *testing.T, root, name string, files map[string]string) string {
+	t.Helper()
+	work := filepath.Join(root, "work-"+name)
+	testutil.GitRun(t, root, "init", "-q", "-b", "main", work)
+	for file, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(work, file)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(work, file), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	testutil.GitRun(t, work, "add", ".")
+	testutil.GitRun(t, work, "commit", "-q", "-m", "feat: init")
+	testutil.GitRun(t, work, "tag", "v1.0.0")
+	testutil.GitRun(t, work, "commit", "-q", "--allow-empty", "-m", "fix: change")
+	bare := filepath.Join(root, name+".git")
+	testutil.GitRun(t, root, "clone", "-q", "--bare", work, bare)
+	return bare
+}
+
+func TestRelease(t *testing.T) {
+	testutil.GitIdentity(t)
+	root := t.TempDir()
+	lib := newRemote(t, root, "lib", map[string]string{"go.mod": "module example.com/lib\n\ngo 1.18\n"})
+	app := newRemote(t, root, "app", map[string]string{"go.mod": "module example.com/app\n\ngo 1.18\n\nrequire example.com/lib v1.0.0\n"})
+	plan := filepath.Join(root, DefaultFile)
+	content := "repositories:\n" +
+		"  - name: app\n    url: " + app + "\n    dependsOn: [lib]\n" +
+		"  - name: lib\n    url: " + lib + "\n    release: minor\n"
+	if err := os.WriteFile(plan, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Load(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Release(f, Options{Push: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 || results[0].Name != "lib" || results[0].Tag != "v1.1.0" || results[1].Tag != "v1.0.1" {
+		t.Fatalf("unexpected results %+v %+v", results[0], results[1])
+	}
+	if out := testutil.GitRun(t, app, "show", "main:go.mod"); !strings.Contains(out, "require example.com/lib v1.1.0") {
+		t.Errorf("expected pin pushed, but %s got", out)
+	}
+	if out := testutil.GitRun(t, app, "log", "-1", "--format=%s", "v1.0.1"); strings.TrimSpace(out) != "chore(deps): bump example.com/lib to 1.1.0" {
+		t.Errorf("expected release on bump commit, but %s got", out)
+	}
+
+	// 再次执行时拉取已克隆的仓库
+	if results, err = Release(f, Options{DryRun: true, OnNoChange: "skip"}); err != nil {
+		t.Fatal(err)
+	}
+	if !results[0].Skipped || !results[1].Skipped {
+		t.Errorf("expected no release without new commits, but %+v %+v got", results[0], results[1])
+	}
+}
+
+func TestRelease_Partial(t *testing.T) {
+	testutil.GitIdentity(t)
+	root := t.TempDir()
+	lib := newRemote(t, root, "lib", map[string]string{
+		"go.mod":            "module example.com/lib\n\ngo 1.18\n",
+		"docs/guide.md":     "# guide\n",
+		"charts/Chart.yaml": "version: 1.0.0\n",
+	})
+	plan := filepath.Join(root, DefaultFile)
+	content := "partial: true\nrepositories:\n  - name: lib\n    url: file://" + filepath.ToSlash(lib) + "\n    sparse: [charts]\n"
+	if err := os.WriteFile(plan, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	f, err := Load(plan)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, err := Release(f, Options{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Version != "1.0.1" {
+		t.Errorf("expected 1.0.1, but %s got", results[0].Version)
+	}
+	dir := f.RepoDir(f.Repositories[0])
+	for name, want := range map[string]bool{"go.mod": true, "charts/Chart.yaml": true, "docs/guide.md": false} {
+		if _, err := os.Stat(filepath.Join(dir, name)); (err == nil) != want {
+			t.Errorf("expected %s checked out %v, but %v got", name, want, err)
+		}
+	}
+	if out := testutil.GitRun(t, dir, "config", "remote.origin.promisor"); strings.TrimSpace(out) != "true" {
+		t.Errorf("expected partial clone, but promisor %q got", out)
+	}
+}
+
+func TestFile_Order(t *testing.T) {
+	f := &File{Repositories: []Repository{
+		{Name: "a", DependsOn: []string{"b"}},
+		{Name: "b", DependsOn: []string{"c"}},
+		{Name: "c"},
+		{Name: "d"},
+	}}
+	ordered, err := f.Order()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, 0, len(ordered))
+	for _, r := range ordered {
+		names = append(names, r.Name)
+	}
+	if actual := strings.Join(names, ","); actual != "c,b,a,d" {
+		t.Errorf("expected c,b,a,d, but %s got", actual)
+	}
+	f.Repositories[2].DependsOn = []string{"a"}
+	if _, err = f.Order(); err == nil || !strings.Contains(err.Error(), "a -> b -> c -> a") {
+		t.Errorf("expected dependency cycle, but %v got", err)
+	}
+}
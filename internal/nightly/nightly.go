@@ -0,0 +1,121 @@
+package nightly
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/tags"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+const (
+	DefaultChannel = "nightly"
+	dateLayout     = "20060102"
+	shortSHA       = 7
+)
+
+// Config 快照版本配置，对应配置文件 nightly 节点
+type Config struct {
+	Channel string `mapstructure:"channel"` // 预发布标识符，默认 nightly
+	Release string `mapstructure:"release"` // 预计的下一次发布的版本变动类型，默认 patch
+	Keep    int    `mapstructure:"keep"`    // 清理时保留最新的 n 个快照标签
+	MaxAge  string `mapstructure:"maxAge"`  // 清理时删除早于该时长的快照标签，如 30d
+}
+
+// Snapshot 快照版本
+type Snapshot struct {
+	Base    semver.Semver `json:"base,omitempty"` // 最近的正式版本，首次发布前为空
+	Version semver.Semver `json:"version"`
+	Tag     string        `json:"tag"`
+	Commit  string        `json:"commit"`
+	Date    time.Time     `json:"date"` // 提交时间
+}
+
+// Version 快照版本号 <next>-<channel>.<YYYYMMDD>+sha.<commit>，日期取提交时间的 UTC 日期，同一提交始终得到相同的版本号
+func Version(next semver.Semver, channel string, date time.Time, commit string) (semver.Semver, error) {
+	if channel == "" {
+		channel = DefaultChannel
+	}
+	if len(commit) > shortSHA {
+		commit = commit[:shortSHA]
+	}
+	return semver.Version(fmt.Sprintf("%d.%d.%d-%s.%s+sha.%s",
+		next.Major(), next.Minor(), next.Patch(), channel, date.UTC().Format(dateLayout), commit))
+}
+
+// Next 预计的下一个正式版本，以最近的正式版本标签为基础按 major、minor 或 patch 递增，没有正式版本时以 0.0.0 为基础
+func Next(list []tags.Tag, changed semver.VersionChanged) (base, next semver.Semver) {
+	for _, t := range list {
+		if !t.Prerelease() {
+			base = t.Version
+			break
+		}
+	}
+	current := base
+	if current == nil {
+		current, _ = semver.Version("0.0.0")
+	}
+	if changed != semver.Major && changed != semver.Minor {
+		changed = semver.Patch
+	}
+	return base, current.Increment(semver.WithReleaseType(changed))
+}
+
+// New 基于 HEAD 生成快照版本
+func New(plus *git.Plus, prefix string, cfg Config) (*Snapshot, error) {
+	changed := semver.Patch
+	if cfg.Release != "" {
+		var err error
+		if changed, err = semver.ParseReleaseType(cfg.Release); err != nil {
+			return nil, err
+		}
+		if changed != semver.Major && changed != semver.Minor && changed != semver.Patch {
+			return nil, fmt.Errorf("nightly release type must be major, minor or patch, but %s got", changed)
+		}
+	}
+	list, _, err := tags.List(plus, prefix)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := plus.Head()
+	if err != nil {
+		return nil, err
+	}
+	date, err := plus.CommitTime(commit)
+	if err != nil {
+		return nil, err
+	}
+	base, next := Next(list, changed)
+	v, err := Version(next, cfg.Channel, date, commit)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{Base: base, Version: v, Tag: prefix + v.String(), Commit: commit, Date: date.UTC()}, nil
+}
+
+// Expired 需要清理的快照标签：按版本从新到旧保留最新的 keep 个，其余及早于 maxAge 的标签均过期
+func Expired(list []tags.Tag, cfg Config, now time.Time) ([]tags.Tag, error) {
+	channel := cfg.Channel
+	if channel == "" {
+		channel = DefaultChannel
+	}
+	var maxAge time.Duration
+	if cfg.MaxAge != "" {
+		var err error
+		if maxAge, err = tags.ParseAge(cfg.MaxAge); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.Keep <= 0 && maxAge <= 0 {
+		return nil, fmt.Errorf("keep or maxAge is required to clean up %s tags", channel)
+	}
+	snapshots := tags.Filter{Channels: []string{channel}, Prerelease: true}.Select(list, now)
+	expired := make([]tags.Tag, 0, len(snapshots))
+	for i, t := range snapshots {
+		if cfg.Keep > 0 && i >= cfg.Keep || maxAge > 0 && now.Sub(t.Date) > maxAge {
+			expired = append(expired, t)
+		}
+	}
+	return expired, nil
+}
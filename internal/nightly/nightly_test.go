@@ -0,0 +1,62 @@
+package nightly
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/tags"
+	"github.com/coffee377/autoctl/internal/testutil"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+func TestNew(t *testing.T) {
+	dir := testutil.NewRepo(t, t.TempDir())
+	t.Setenv("GIT_COMMITTER_DATE", "2024-05-21T23:30:00+08:00")
+	testutil.GitRun(t, dir, "commit", "-q", "--allow-empty", "-m", "feat: init")
+	testutil.GitRun(t, dir, "tag", "v1.4.2")
+	testutil.GitRun(t, dir, "tag", "v1.5.0-rc.1")
+
+	plus := &git.Plus{Cwd: dir}
+	head, _ := plus.Head()
+	s, err := New(plus, "v", Config{Release: "minor"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "v1.5.0-nightly.20240521+sha." + head[:7]
+	if s.Tag != expected || s.Base.String() != "1.4.2" {
+		t.Errorf("expected %s based on 1.4.2, but %s based on %v got", expected, s.Tag, s.Base)
+	}
+	if again, _ := New(plus, "v", Config{Release: "minor"}); again.Tag != s.Tag {
+		t.Errorf("expected deterministic version, but %s got", again.Tag)
+	}
+	if _, err = New(plus, "v", Config{Release: "prerelease"}); err == nil {
+		t.Error("expected prerelease type rejected")
+	}
+}
+
+func TestExpired(t *testing.T) {
+	now := time.Date(2024, 5, 21, 0, 0, 0, 0, time.UTC)
+	tag := func(name string, days int) tags.Tag {
+		v, _ := semver.Version(name)
+		return tags.Tag{Name: "v" + name, Version: v, Channel: tags.Channel(v), Date: now.AddDate(0, 0, -days)}
+	}
+	list := []tags.Tag{
+		tag("1.5.0-nightly.20240520", 1),
+		tag("1.5.0-nightly.20240519", 2),
+		tag("1.5.0-nightly.20240510", 11),
+		tag("1.5.0-rc.1", 30),
+		tag("1.4.2", 40),
+	}
+	expired, err := Expired(list, Config{Keep: 2}, now)
+	if err != nil || len(expired) != 1 || expired[0].Name != "v1.5.0-nightly.20240510" {
+		t.Errorf("expected the oldest nightly expired, but %v %v got", expired, err)
+	}
+	expired, _ = Expired(list, Config{MaxAge: "36h"}, now)
+	if len(expired) != 2 {
+		t.Errorf("expected 2 nightly tags older than 36h, but %v got", expired)
+	}
+	if _, err = Expired(list, Config{}, now); err == nil {
+		t.Error("expected keep or maxAge required")
+	}
+}
@@ -0,0 +1,363 @@
+package policy
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/artifact"
+	"github.com/coffee377/autoctl/pkg/semver"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	LevelDeny = "deny"
+	LevelWarn = "warn"
+
+	BumpMajor      = "major"
+	BumpMinor      = "minor"
+	BumpPatch      = "patch"
+	BumpPrerelease = "prerelease"
+)
+
+// SignatureSuffixes 产物签名文件的后缀，签名文件与产物位于同一目录
+var SignatureSuffixes = []string{".sig", ".asc", ".sigstore", ".bundle"}
+
+// Config 发布合规策略，对应配置文件 release.policy 节点
+type Config struct {
+	Files    []string `mapstructure:"files"`    // 组织统一下发的策略文件，相对路径基于工作目录，规则追加在 rules 之后
+	Timezone string   `mapstructure:"timezone"` // 时间规则使用的时区，如 Asia/Shanghai，默认本地时区
+	Rules    []Rule   `mapstructure:"rules"`    // 发布前检查的规则
+}
+
+// File 策略文件，timezone 只作用于文件中的规则
+type File struct {
+	Timezone string `yaml:"timezone"`
+	Rules    []Rule `yaml:"rules"`
+}
+
+// Rule 一条发布规则，适用范围均为空时适用于所有发布，设置的检查项任一不满足即违反规则
+type Rule struct {
+	Name    string `mapstructure:"name" yaml:"name"`
+	Message string `mapstructure:"message" yaml:"message"` // 违反规则时的说明，默认根据检查项生成
+	Level   string `mapstructure:"level" yaml:"level"`     // deny（默认，阻止发布）| warn（只输出警告）
+
+	Branches []string `mapstructure:"branches" yaml:"branches"` // 适用的分支匹配规则
+	Bumps    []string `mapstructure:"bumps" yaml:"bumps"`       // 适用的版本变动 major | minor | patch | prerelease，根据前后两个版本计算
+
+	Weekdays  []string `mapstructure:"weekdays" yaml:"weekdays"`   // 禁止发布的星期，如 Fri、Friday
+	Hours     string   `mapstructure:"hours" yaml:"hours"`         // 禁止发布的时段，如 18-9 表示 18:00 至次日 09:00
+	Changelog string   `mapstructure:"changelog" yaml:"changelog"` // 变更日志必须匹配的正则，如 RFC 链接
+	Signed    bool     `mapstructure:"signed" yaml:"signed"`       // 每个发布产物都必须有签名文件，见 SignatureSuffixes
+	Actors    []string `mapstructure:"actors" yaml:"actors"`       // 允许发布的触发者
+
+	location *time.Location
+}
+
+// Input 规则求值的输入
+type Input struct {
+	Time      time.Time
+	Branch    string
+	Previous  semver.Semver // 首次发布时为 nil
+	Version   semver.Semver
+	Changelog string
+	Artifacts []artifact.Artifact
+	Actor     string
+}
+
+// Violation 违反的规则
+type Violation struct {
+	Rule    string `json:"rule"`
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Rule, v.Message)
+}
+
+// Policy 合并配置及策略文件后的规则
+type Policy struct {
+	Rules []Rule
+}
+
+// Load 读取配置中的规则及策略文件，dir 为策略文件相对路径的基准目录
+func Load(cfg Config, dir string) (*Policy, error) {
+	location, err := loadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, err
+	}
+	p := &Policy{}
+	if err = p.add("policy", cfg.Rules, location); err != nil {
+		return nil, err
+	}
+	for _, name := range cfg.Files {
+		if !filepath.IsAbs(name) {
+			name = filepath.Join(dir, name)
+		}
+		data, err := os.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+		f := &File{}
+		if err = yaml.Unmarshal(data, f); err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		fileLocation := location
+		if f.Timezone != "" {
+			if fileLocation, err = loadLocation(f.Timezone); err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		if err = p.add(name, f.Rules, fileLocation); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("invalid policy timezone %q: %w", name, err)
+	}
+	return location, nil
+}
+
+func (p *Policy) add(source string, rules []Rule, location *time.Location) error {
+	for i, rule := range rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("%s#%d", filepath.Base(source), i+1)
+		}
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("%s: rule %s: %w", source, rule.Name, err)
+		}
+		rule.location = location
+		p.Rules = append(p.Rules, rule)
+	}
+	return nil
+}
+
+func (rule Rule) validate() error {
+	switch strings.ToLower(rule.Level) {
+	case "", LevelDeny, LevelWarn:
+	default:
+		return fmt.Errorf("invalid level %q, valid values are %s, %s", rule.Level, LevelDeny, LevelWarn)
+	}
+	for _, p := range rule.Branches {
+		if _, err := regexp.Compile(p); err != nil {
+			return fmt.Errorf("invalid branch pattern %q: %w", p, err)
+		}
+	}
+	for _, b := range rule.Bumps {
+		switch strings.ToLower(b) {
+		case BumpMajor, BumpMinor, BumpPatch, BumpPrerelease:
+		default:
+			return fmt.Errorf("invalid bump %q, valid values are %s, %s, %s, %s", b, BumpMajor, BumpMinor, BumpPatch, BumpPrerelease)
+		}
+	}
+	for _, d := range rule.Weekdays {
+		if _, err := parseWeekday(d); err != nil {
+			return err
+		}
+	}
+	if rule.Hours != "" {
+		if _, _, err := parseHours(rule.Hours); err != nil {
+			return err
+		}
+	}
+	if rule.Changelog != "" {
+		if _, err := regexp.Compile(rule.Changelog); err != nil {
+			return fmt.Errorf("invalid changelog pattern %q: %w", rule.Changelog, err)
+		}
+	}
+	return nil
+}
+
+// Evaluate 依次检查所有规则，返回违反的规则
+func (p *Policy) Evaluate(in Input) []Violation {
+	violations := make([]Violation, 0)
+	bump := Bump(in.Previous, in.Version)
+	for _, rule := range p.Rules {
+		if !rule.applies(in.Branch, bump) {
+			continue
+		}
+		if problem := rule.check(in); problem != "" {
+			level := strings.ToLower(rule.Level)
+			if level == "" {
+				level = LevelDeny
+			}
+			message := rule.Message
+			if message == "" {
+				message = problem
+			}
+			violations = append(violations, Violation{Rule: rule.Name, Level: level, Message: message})
+		}
+	}
+	return violations
+}
+
+// Deny 返回阻止发布的违规，没有时返回 nil
+func Deny(violations []Violation) error {
+	problems := make([]string, 0)
+	for _, v := range violations {
+		if v.Level == LevelDeny {
+			problems = append(problems, v.String())
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("release policy violated: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func (rule Rule) applies(branch, bump string) bool {
+	if len(rule.Branches) > 0 {
+		matched := false
+		for _, p := range rule.Branches {
+			if regexp.MustCompile(p).MatchString(branch) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if len(rule.Bumps) > 0 {
+		for _, b := range rule.Bumps {
+			if strings.EqualFold(b, bump) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// check 返回第一个不满足的检查项的说明
+func (rule Rule) check(in Input) string {
+	now := in.Time
+	if rule.location != nil {
+		now = now.In(rule.location)
+	}
+	for _, d := range rule.Weekdays {
+		if weekday, _ := parseWeekday(d); weekday == now.Weekday() {
+			return fmt.Sprintf("releases are not allowed on %s", weekday)
+		}
+	}
+	if rule.Hours != "" {
+		from, to, _ := parseHours(rule.Hours)
+		hour := now.Hour()
+		if (from <= to && hour >= from && hour < to) || (from > to && (hour >= from || hour < to)) {
+			return fmt.Sprintf("releases are not allowed between %02d:00 and %02d:00", from, to)
+		}
+	}
+	if rule.Changelog != "" && !regexp.MustCompile(rule.Changelog).MatchString(in.Changelog) {
+		return fmt.Sprintf("changelog does not match %s", rule.Changelog)
+	}
+	if rule.Signed {
+		if unsigned := Unsigned(in.Artifacts); len(unsigned) > 0 {
+			return fmt.Sprintf("artifacts are not signed: %s", strings.Join(unsigned, ", "))
+		}
+	}
+	if len(rule.Actors) > 0 {
+		allowed := false
+		for _, actor := range rule.Actors {
+			if strings.EqualFold(actor, in.Actor) {
+				allowed = true
+				break
+			}
+		}
+		if !allowed {
+			return fmt.Sprintf("%s is not allowed to release", in.Actor)
+		}
+	}
+	return ""
+}
+
+// Unsigned 返回没有签名文件的产物，签名文件本身不需要签名
+func Unsigned(artifacts []artifact.Artifact) []string {
+	unsigned := make([]string, 0)
+	for _, a := range artifacts {
+		if isSignature(a.Name) {
+			continue
+		}
+		signed := false
+		for _, suffix := range SignatureSuffixes {
+			if _, err := os.Stat(a.Path + suffix); err == nil {
+				signed = true
+				break
+			}
+		}
+		if !signed {
+			unsigned = append(unsigned, a.Name)
+		}
+	}
+	return unsigned
+}
+
+func isSignature(name string) bool {
+	for _, suffix := range SignatureSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Bump 根据前后两个版本计算版本变动，首次发布视为 major
+func Bump(previous, next semver.Semver) string {
+	if next == nil {
+		return ""
+	}
+	switch {
+	case len(next.PreRelease()) > 0:
+		return BumpPrerelease
+	case previous == nil || next.Major() != previous.Major():
+		return BumpMajor
+	case next.Minor() != previous.Minor():
+		return BumpMinor
+	default:
+		return BumpPatch
+	}
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	input := strings.ToLower(strings.TrimSpace(s))
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		name := strings.ToLower(d.String())
+		if input == name || input == name[:3] {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("invalid weekday %q", s)
+}
+
+// parseHours 解析 from-to 形式的时段，to 小于 from 时跨越午夜
+func parseHours(s string) (int, int, error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid hours %q, expected from-to such as 18-9", s)
+	}
+	hours := make([]int, 2)
+	for i, part := range parts {
+		h, err := strconv.Atoi(strings.TrimSuffix(strings.TrimSpace(part), ":00"))
+		if err != nil || h < 0 || h > 24 {
+			return 0, 0, fmt.Errorf("invalid hours %q, expected from-to such as 18-9", s)
+		}
+		hours[i] = h % 24
+	}
+	if hours[0] == hours[1] {
+		return 0, 0, errors.New("invalid hours, from and to must differ")
+	}
+	return hours[0], hours[1], nil
+}
@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/artifact"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+func TestPolicy_Evaluate(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"app.tar.gz", "app.tar.gz.sig", "app.zip"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 组织下发的策略文件
+	file := filepath.Join(dir, "policy.yaml")
+	content := "timezone: UTC\nrules:\n  - name: no-fridays\n    message: no releases on Fridays\n    weekdays: [Fri]\n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := Load(Config{Files: []string{"policy.yaml"}, Timezone: "UTC", Rules: []Rule{
+		{Name: "rfc", Bumps: []string{BumpMajor}, Changelog: `RFC-\d+`},
+		{Name: "signed", Branches: []string{"^main$"}, Signed: true},
+		{Name: "night", Level: LevelWarn, Hours: "22-6"},
+	}}, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := []artifact.Artifact{{Name: "app.tar.gz", Path: filepath.Join(dir, "app.tar.gz")}, {Name: "app.tar.gz.sig", Path: filepath.Join(dir, "app.tar.gz.sig")}}
+	unsigned := append(signed, artifact.Artifact{Name: "app.zip", Path: filepath.Join(dir, "app.zip")})
+	thursday := time.Date(2023, 8, 17, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name      string
+		in        Input
+		violation string
+	}{
+		{"minor", Input{Time: thursday, Branch: "main", Previous: version("1.2.0"), Version: version("1.3.0"), Artifacts: signed}, ""},
+		{"major without rfc", Input{Time: thursday, Branch: "main", Previous: version("1.2.0"), Version: version("2.0.0")}, "rfc: changelog does not match"},
+		{"major with rfc", Input{Time: thursday, Branch: "main", Previous: version("1.2.0"), Version: version("2.0.0"), Changelog: "see RFC-12"}, ""},
+		{"unsigned", Input{Time: thursday, Branch: "main", Previous: version("1.2.0"), Version: version("1.2.1"), Artifacts: unsigned}, "signed: artifacts are not signed: app.zip"},
+		{"unsigned on other branch", Input{Time: thursday, Branch: "next", Previous: version("1.2.0"), Version: version("1.2.1"), Artifacts: unsigned}, ""},
+		{"friday", Input{Time: thursday.Add(24 * time.Hour), Branch: "next", Previous: version("1.2.0"), Version: version("1.2.1")}, "no-fridays: no releases on Fridays"},
+		{"night", Input{Time: thursday.Add(11 * time.Hour), Branch: "next", Previous: version("1.2.0"), Version: version("1.2.1")}, "night: releases are not allowed between 22:00 and 06:00"},
+	}
+	for _, test := range tests {
+		violations := p.Evaluate(test.in)
+		got := make([]string, 0, len(violations))
+		for _, v := range violations {
+			got = append(got, v.String())
+		}
+		if test.violation == "" && len(got) > 0 || test.violation != "" && (len(got) != 1 || !strings.HasPrefix(got[0], test.violation)) {
+			t.Errorf("%s: expected %q, but %q got", test.name, test.violation, got)
+		}
+		if err = Deny(violations); (err != nil) != (test.violation != "" && test.name != "night") {
+			t.Errorf("%s: unexpected deny result %v", test.name, err)
+		}
+	}
+}
+
+func TestLoad_Invalid(t *testing.T) {
+	for _, rule := range []Rule{{Level: "block"}, {Weekdays: []string{"Funday"}}, {Hours: "9"}, {Bumps: []string{"huge"}}} {
+		if _, err := Load(Config{Rules: []Rule{rule}}, ""); err == nil {
+			t.Errorf("expected error for %+v, but nil got", rule)
+		}
+	}
+}
+
+func version(s string) semver.Semver {
+	v, _ := semver.Version(s)
+	return v
+}
@@ -0,0 +1,378 @@
+// Package preset 解析配置文件的 extends 节点，继承组织共享的预设配置（提交类型、模板、发布策略等），
+// 仓库的配置文件只需覆盖差异部分
+package preset
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/httpclient"
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Key 配置文件中声明继承的节点
+const Key = "extends"
+
+// DefaultTTL 未固定哈希的远程预设的缓存有效期
+const DefaultTTL = time.Hour
+
+const gitPrefix = "git::"
+
+// Preset 继承的预设，extends 可以是单个来源、来源列表或包含 source、sha256 的对象列表，
+// 排在后面的预设覆盖前面的预设，配置文件中的值覆盖所有预设
+type Preset struct {
+	Source string `mapstructure:"source"` // 本地路径（相对于引用它的文件）、HTTP(S) 地址或 git::<仓库地址>//<文件路径>?ref=<分支或标签>
+	SHA256 string `mapstructure:"sha256"` // 预设内容的 SHA-256，内容不一致时拒绝使用；固定后优先使用缓存，无需访问网络
+}
+
+// Options 读取远程预设的选项
+type Options struct {
+	HTTP    httpclient.Config // 下载 HTTP(S) 预设使用的客户端配置
+	Dir     string            // 缓存目录，默认 <用户缓存目录>/autoctl/presets
+	TTL     time.Duration     // 未固定哈希的远程预设的缓存有效期，默认 1h，过期后下载失败时仍使用缓存
+	NoCache bool              // 不读取也不写入缓存，对应 --no-cache
+}
+
+// Parse 解析 extends 节点的值
+func Parse(value interface{}) ([]Preset, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []Preset{{Source: v}}, nil
+	case map[string]interface{}:
+		p := Preset{}
+		p.Source, _ = lookup(v, "source").(string)
+		p.SHA256, _ = lookup(v, "sha256").(string)
+		if p.Source == "" {
+			return nil, fmt.Errorf("%s: source is required", Key)
+		}
+		p.SHA256 = strings.ToLower(strings.TrimPrefix(p.SHA256, "sha256:"))
+		return []Preset{p}, nil
+	case []interface{}:
+		presets := make([]Preset, 0, len(v))
+		for _, item := range v {
+			list, err := Parse(item)
+			if err != nil {
+				return nil, err
+			}
+			presets = append(presets, list...)
+		}
+		return presets, nil
+	case []string:
+		presets := make([]Preset, 0, len(v))
+		for _, s := range v {
+			presets = append(presets, Preset{Source: s})
+		}
+		return presets, nil
+	}
+	return nil, fmt.Errorf("%s: unsupported value %v", Key, value)
+}
+
+func lookup(m map[string]interface{}, key string) interface{} {
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v
+		}
+	}
+	return nil
+}
+
+// Merge 深度合并配置，override 中的值覆盖 base，列表整体替换；键统一为小写，与 viper 一致
+func Merge(base, override map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(base)+len(override))
+	for k, v := range base {
+		merged[strings.ToLower(k)] = normalize(v)
+	}
+	for k, v := range override {
+		k = strings.ToLower(k)
+		if b, ok := merged[k].(map[string]interface{}); ok {
+			if o, ok := v.(map[string]interface{}); ok {
+				merged[k] = Merge(b, o)
+				continue
+			}
+		}
+		merged[k] = normalize(v)
+	}
+	return merged
+}
+
+// normalize 将嵌套的键转为小写
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		return Merge(nil, v)
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = normalize(item)
+		}
+		return list
+	}
+	return v
+}
+
+// Resolve 按配置文件 file 中 settings 的 extends 节点加载预设，返回合并后的配置，不包含 extends 节点；
+// 预设本身也可以通过 extends 继承其它预设，循环引用时返回错误
+func Resolve(file string, settings map[string]interface{}, opts Options) (map[string]interface{}, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, err
+	}
+	l := &loader{opts: opts}
+	base, err := l.extends(location{kind: kindFile, path: abs}, settings)
+	if err != nil {
+		return nil, err
+	}
+	merged := Merge(base, settings)
+	delete(merged, Key)
+	return merged, nil
+}
+
+const (
+	kindFile = "file"
+	kindHTTP = "http"
+	kindGit  = "git"
+)
+
+// location 预设所在位置
+type location struct {
+	kind string
+	path string // 本地文件的绝对路径、HTTP(S) 地址或仓库中的文件路径
+	repo string // git 仓库地址
+	ref  string // git 分支或标签
+}
+
+func (l location) String() string {
+	if l.kind == kindGit {
+		s := gitPrefix + l.repo + "//" + l.path
+		if l.ref != "" {
+			s += "?ref=" + l.ref
+		}
+		return s
+	}
+	return l.path
+}
+
+// locate 解析来源，相对路径相对于引用它的预设：本地文件所在目录、HTTP(S) 地址或同一仓库同一版本中的目录
+func locate(parent location, source string) (location, error) {
+	switch {
+	case strings.HasPrefix(source, gitPrefix):
+		rest := strings.TrimPrefix(source, gitPrefix)
+		loc := location{kind: kindGit}
+		if i := strings.LastIndex(rest, "?ref="); i >= 0 {
+			rest, loc.ref = rest[:i], rest[i+len("?ref="):]
+		}
+		offset := 0
+		if i := strings.Index(rest, "://"); i >= 0 {
+			offset = i + len("://")
+		}
+		i := strings.Index(rest[offset:], "//")
+		if i < 0 {
+			return loc, fmt.Errorf("%s: expected %s<repository>//<file>, got %s", Key, gitPrefix, source)
+		}
+		loc.repo, loc.path = rest[:offset+i], path.Clean(rest[offset+i+2:])
+		return loc, checkPath(loc.path, source)
+	case strings.HasPrefix(source, "https://"), strings.HasPrefix(source, "http://"):
+		return location{kind: kindHTTP, path: source}, nil
+	}
+	switch parent.kind {
+	case kindHTTP:
+		base, err := url.Parse(parent.path)
+		if err != nil {
+			return parent, err
+		}
+		ref, err := url.Parse(filepath.ToSlash(source))
+		if err != nil {
+			return parent, err
+		}
+		return location{kind: kindHTTP, path: base.ResolveReference(ref).String()}, nil
+	case kindGit:
+		p := path.Join(path.Dir(parent.path), filepath.ToSlash(source))
+		return location{kind: kindGit, repo: parent.repo, ref: parent.ref, path: p}, checkPath(p, source)
+	}
+	if !filepath.IsAbs(source) {
+		source = filepath.Join(filepath.Dir(parent.path), source)
+	}
+	return location{kind: kindFile, path: filepath.Clean(source)}, nil
+}
+
+// checkPath 仓库中的文件不能位于仓库之外
+func checkPath(p, source string) error {
+	if p == "." || p == ".." || strings.HasPrefix(p, "../") || path.IsAbs(p) {
+		return fmt.Errorf("%s: invalid file in repository %s", Key, source)
+	}
+	return nil
+}
+
+type loader struct {
+	opts   Options
+	client *http.Client
+	stack  []string // 正在加载的预设，用于检测循环引用
+}
+
+// extends 依次加载 settings 中声明的预设并合并
+func (l *loader) extends(parent location, settings map[string]interface{}) (map[string]interface{}, error) {
+	presets, err := Parse(lookup(settings, Key))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", parent, err)
+	}
+	base := map[string]interface{}{}
+	for _, p := range presets {
+		loc, err := locate(parent, p.Source)
+		if err != nil {
+			return nil, err
+		}
+		m, err := l.load(loc, p.SHA256)
+		if err != nil {
+			return nil, err
+		}
+		base = Merge(base, m)
+	}
+	return base, nil
+}
+
+func (l *loader) load(loc location, pin string) (map[string]interface{}, error) {
+	key := loc.String()
+	for _, s := range l.stack {
+		if s == key {
+			return nil, fmt.Errorf("%s: circular reference %s -> %s", Key, strings.Join(l.stack, " -> "), key)
+		}
+	}
+	l.stack = append(l.stack, key)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+
+	data, err := l.read(loc, pin)
+	if err != nil {
+		return nil, fmt.Errorf("preset %s: %w", key, err)
+	}
+	if sum := checksum(data); pin != "" && sum != pin {
+		return nil, fmt.Errorf("preset %s: sha256 mismatch, expected %s, got %s", key, pin, sum)
+	}
+	settings := map[string]interface{}{}
+	if err = yaml.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("preset %s: %w", key, err)
+	}
+	base, err := l.extends(loc, settings)
+	if err != nil {
+		return nil, err
+	}
+	merged := Merge(base, settings)
+	delete(merged, Key)
+	return merged, nil
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// read 读取预设内容；远程预设固定哈希且缓存内容一致时直接使用缓存，未固定时使用有效期内的缓存，
+// 下载失败时退回到过期的缓存
+func (l *loader) read(loc location, pin string) ([]byte, error) {
+	if loc.kind == kindFile {
+		return os.ReadFile(loc.path)
+	}
+	file := l.cacheFile(loc)
+	var cached []byte
+	if file != "" {
+		if info, err := os.Stat(file); err == nil {
+			if cached, err = os.ReadFile(file); err == nil {
+				if pin != "" && checksum(cached) == pin {
+					return cached, nil
+				}
+				if pin == "" && time.Since(info.ModTime()) < l.ttl() {
+					return cached, nil
+				}
+			}
+		}
+	}
+	data, err := l.fetch(loc)
+	if err != nil {
+		if cached != nil && (pin == "" || checksum(cached) == pin) {
+			log.Warn("preset %s: %s, using the cached copy", loc, err)
+			return cached, nil
+		}
+		return nil, err
+	}
+	if file != "" {
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err == nil {
+			_ = os.WriteFile(file, data, 0o644)
+		}
+	}
+	return data, nil
+}
+
+func (l *loader) ttl() time.Duration {
+	if l.opts.TTL > 0 {
+		return l.opts.TTL
+	}
+	return DefaultTTL
+}
+
+// cacheFile 远程预设的缓存文件，不使用缓存时返回空字符串
+func (l *loader) cacheFile(loc location) string {
+	if l.opts.NoCache {
+		return ""
+	}
+	dir := l.opts.Dir
+	if dir == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(base, "autoctl", "presets")
+	}
+	return filepath.Join(dir, checksum([]byte(loc.String()))+".yaml")
+}
+
+func (l *loader) fetch(loc location) ([]byte, error) {
+	if loc.kind == kindGit {
+		return fetchGit(loc)
+	}
+	if l.client == nil {
+		client, err := httpclient.New(l.opts.HTTP)
+		if err != nil {
+			return nil, err
+		}
+		l.client = client
+	}
+	resp, err := l.client.Get(loc.path)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("GET %s: %s", log.Redact(loc.path), resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// fetchGit 浅克隆仓库到临时目录并读取其中的文件，私有仓库使用 git 的凭据配置
+func fetchGit(loc location) ([]byte, error) {
+	temp, err := os.MkdirTemp("", "autoctl-preset-*")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(temp)
+	args := []string{"clone", "--quiet", "--depth", "1"}
+	if loc.ref != "" {
+		args = append(args, "--branch", loc.ref)
+	}
+	plus := &git.Plus{Cwd: temp}
+	if _, err = plus.Run(append(args, loc.repo, "repo")...); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Join(temp, "repo", filepath.FromSlash(loc.path)))
+}
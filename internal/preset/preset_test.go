@@ -0,0 +1,157 @@
+package preset
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/httpclient"
+	"github.com/coffee377/autoctl/internal/retry"
+	"github.com/coffee377/autoctl/internal/testutil"
+)
+
+func write(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	file := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func get(m map[string]interface{}, keys ...string) interface{} {
+	var v interface{} = m
+	for _, k := range keys {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[k]
+	}
+	return v
+}
+
+func TestParse(t *testing.T) {
+	presets, err := Parse([]interface{}{"./base.yaml", map[string]interface{}{"source": "https://example.com/a.yaml", "sha256": "sha256:ABC"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(presets) != 2 || presets[0].Source != "./base.yaml" || presets[1].SHA256 != "abc" {
+		t.Errorf("Parse() = %+v", presets)
+	}
+	if _, err = Parse(map[string]interface{}{"sha256": "abc"}); err == nil {
+		t.Error("expected missing source error, got nil")
+	}
+}
+
+func TestLocate(t *testing.T) {
+	loc, err := locate(location{}, "git::https://github.com/acme/presets.git//autoctl/base.yaml?ref=v1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loc.repo != "https://github.com/acme/presets.git" || loc.path != "autoctl/base.yaml" || loc.ref != "v1" {
+		t.Errorf("locate() = %+v", loc)
+	}
+	if loc, _ = locate(loc, "../common.yaml"); loc.String() != "git::https://github.com/acme/presets.git//common.yaml?ref=v1" {
+		t.Errorf("expected relative preset in the same repository, got %s", loc)
+	}
+	if _, err = locate(loc, "../../escape.yaml"); err == nil {
+		t.Error("expected file outside the repository rejected, got nil")
+	}
+	if loc, _ = locate(location{kind: kindHTTP, path: "https://example.com/presets/go.yaml"}, "base.yaml"); loc.path != "https://example.com/presets/base.yaml" {
+		t.Errorf("expected relative url resolved, got %s", loc)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	dir := t.TempDir()
+	write(t, dir, "presets/common.yaml", "lang: zh-CN\nrelease:\n  onNoChange: skip\n  tagPrefix: rel-\n")
+	write(t, dir, "presets/base.yaml", "extends: common.yaml\nrelease:\n  tagPrefix: v\n  sections: [feat, fix]\n")
+	file := write(t, dir, "repo/auto.yaml", "")
+	settings := map[string]interface{}{
+		"extends": "../presets/base.yaml",
+		"release": map[string]interface{}{"sections": []interface{}{"feat"}},
+	}
+	merged, err := Resolve(file, settings, Options{NoCache: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if get(merged, "lang") != "zh-CN" || get(merged, "release", "onnochange") != "skip" || get(merged, "release", "tagprefix") != "v" {
+		t.Errorf("expected presets merged in order, got %v", merged)
+	}
+	if sections, _ := get(merged, "release", "sections").([]interface{}); len(sections) != 1 {
+		t.Errorf("expected lists replaced by the repository config, got %v", sections)
+	}
+	if _, ok := merged["extends"]; ok {
+		t.Error("expected extends removed")
+	}
+
+	write(t, dir, "presets/common.yaml", "extends: base.yaml\n")
+	if _, err = Resolve(file, settings, Options{NoCache: true}); err == nil || !strings.Contains(err.Error(), "circular") {
+		t.Errorf("expected circular reference error, got %v", err)
+	}
+}
+
+func TestResolve_HTTP(t *testing.T) {
+	content, up := "lang: en\n", true
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !up {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(content))
+	}))
+	defer srv.Close()
+	file := write(t, t.TempDir(), "auto.yaml", "")
+	opts := Options{Dir: t.TempDir(), HTTP: httpclient.Config{Retry: retry.Policy{Attempts: 1}}}
+	pinned := map[string]interface{}{"extends": []interface{}{map[string]interface{}{"source": srv.URL + "/base.yaml", "sha256": checksum([]byte(content))}}}
+	merged, err := Resolve(file, pinned, opts)
+	if err != nil || merged["lang"] != "en" {
+		t.Fatalf("Resolve() = %v, %v", merged, err)
+	}
+
+	// 固定哈希且缓存一致时无需访问网络
+	up = false
+	if merged, err = Resolve(file, pinned, opts); err != nil || merged["lang"] != "en" {
+		t.Errorf("expected pinned preset served from cache, got %v, %v", merged, err)
+	}
+	// 未固定哈希时缓存过期后下载失败也使用缓存
+	opts.TTL = time.Nanosecond
+	if merged, err = Resolve(file, map[string]interface{}{"extends": srv.URL + "/base.yaml"}, opts); err != nil || merged["lang"] != "en" {
+		t.Errorf("expected stale cache used when download fails, got %v, %v", merged, err)
+	}
+
+	up, content = true, "lang: ja\n"
+	if _, err = Resolve(file, pinned, Options{NoCache: true}); err == nil || !strings.Contains(err.Error(), "sha256 mismatch") {
+		t.Errorf("expected sha256 mismatch error, got %v", err)
+	}
+}
+
+func TestResolve_Git(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	root := t.TempDir()
+	repo := testutil.NewRepo(t, filepath.Join(root, "presets"))
+	write(t, repo, "autoctl/base.yaml", "extends: ../common.yaml\nrelease:\n  tagPrefix: v\n")
+	write(t, repo, "common.yaml", "lang: zh-CN\n")
+	for _, args := range [][]string{{"add", "."}, {"commit", "-q", "-m", "feat: presets"}, {"tag", "v1"}} {
+		testutil.GitRun(t, repo, args...)
+	}
+	file := write(t, root, "app/auto.yaml", "")
+	merged, err := Resolve(file, map[string]interface{}{"extends": "git::" + repo + "//autoctl/base.yaml?ref=v1"}, Options{NoCache: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged["lang"] != "zh-CN" || get(merged, "release", "tagprefix") != "v" {
+		t.Errorf("expected presets read from the repository, got %v", merged)
+	}
+}
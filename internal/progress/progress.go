@@ -0,0 +1,239 @@
+// Package progress 在终端中显示耗时操作的进度，如克隆仓库、上传文件及多仓库发布；
+// 标准错误输出不是终端、设置了 CI 环境变量或指定 --no-progress 时不显示
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	interval = 100 * time.Millisecond
+	// delay 操作超过该时间仍未完成时才显示，避免快速完成的操作闪烁
+	delay = 300 * time.Millisecond
+	width = 24 // 进度条宽度
+)
+
+var frames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+var (
+	mu       sync.Mutex
+	disabled bool
+	forced   bool      // 测试时不检查终端
+	out      io.Writer = os.Stderr
+	tasks    []*Task   // 进行中的任务，只显示最近开始的一个
+	drawn    bool      // 当前行是否有进度输出
+	ticker   *time.Ticker
+	frame    int
+)
+
+// Disable 不显示进度，对应 --no-progress
+func Disable() {
+	mu.Lock()
+	defer mu.Unlock()
+	disabled = true
+}
+
+// Enabled 是否显示进度
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return enabled()
+}
+
+func enabled() bool {
+	if disabled {
+		return false
+	}
+	if forced {
+		return true
+	}
+	return os.Getenv("CI") == "" && isTerminal(os.Stderr)
+}
+
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
+
+// Task 一项耗时操作，不显示进度时为 nil，其方法均可安全调用
+type Task struct {
+	label string
+	total int64 // 总量，小于等于 0 时只显示旋转指示器及已完成的数量
+	done  int64
+	start time.Time
+}
+
+// Start 开始显示旋转指示器，直到调用 Stop
+func Start(format string, args ...interface{}) *Task {
+	return Bar(fmt.Sprintf(format, args...), 0)
+}
+
+// Bar 开始显示进度条，total 为字节数
+func Bar(label string, total int64) *Task {
+	mu.Lock()
+	defer mu.Unlock()
+	if !enabled() {
+		return nil
+	}
+	t := &Task{label: label, total: total, start: time.Now()}
+	tasks = append(tasks, t)
+	if ticker == nil {
+		ticker = time.NewTicker(interval)
+		go loop(ticker)
+	}
+	return t
+}
+
+// Add 增加已完成的数量
+func (t *Task) Add(n int64) {
+	if t == nil {
+		return
+	}
+	mu.Lock()
+	t.done += n
+	mu.Unlock()
+}
+
+// Reset 重新开始计数，如上传失败后重试
+func (t *Task) Reset() {
+	if t == nil {
+		return
+	}
+	mu.Lock()
+	t.done = 0
+	mu.Unlock()
+}
+
+// Stop 结束显示并清除进度行
+func (t *Task) Stop() {
+	if t == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for i, task := range tasks {
+		if task == t {
+			tasks = append(tasks[:i], tasks[i+1:]...)
+			break
+		}
+	}
+	erase()
+	if len(tasks) == 0 && ticker != nil {
+		ticker.Stop()
+		ticker = nil
+	} else {
+		draw()
+	}
+}
+
+// Reader 读取时累计进度
+func (t *Task) Reader(r io.Reader) io.Reader {
+	if t == nil {
+		return r
+	}
+	return &reader{r: r, task: t}
+}
+
+type reader struct {
+	r    io.Reader
+	task *Task
+}
+
+func (r *reader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.task.Add(int64(n))
+	return n, err
+}
+
+func loop(t *time.Ticker) {
+	for range t.C {
+		mu.Lock()
+		if ticker != t {
+			mu.Unlock()
+			return
+		}
+		frame++
+		draw()
+		mu.Unlock()
+	}
+}
+
+// draw 重绘当前任务，调用方持有 mu
+func draw() {
+	if len(tasks) == 0 {
+		return
+	}
+	t := tasks[len(tasks)-1]
+	elapsed := time.Since(t.start)
+	if elapsed < delay {
+		return
+	}
+	_, _ = fmt.Fprintf(out, "\r\033[K%s", t.line(frames[frame%len(frames)], elapsed))
+	drawn = true
+}
+
+// erase 清除进度行，调用方持有 mu
+func erase() {
+	if drawn {
+		_, _ = io.WriteString(out, "\r\033[K")
+		drawn = false
+	}
+}
+
+func (t *Task) line(spinner string, elapsed time.Duration) string {
+	elapsed = elapsed.Round(time.Second)
+	switch {
+	case t.total > 0:
+		done := t.done
+		if done > t.total {
+			done = t.total
+		}
+		filled := int(done * width / t.total)
+		return fmt.Sprintf("%s [%s%s] %3d%% %s/%s (%s)", t.label, strings.Repeat("=", filled), strings.Repeat(" ", width-filled),
+			done*100/t.total, Bytes(done), Bytes(t.total), elapsed)
+	case t.done > 0:
+		return fmt.Sprintf("%s %s %s (%s)", spinner, t.label, Bytes(t.done), elapsed)
+	default:
+		return fmt.Sprintf("%s %s (%s)", spinner, t.label, elapsed)
+	}
+}
+
+// Bytes 以 KiB、MiB 等单位显示字节数
+func Bytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// Writer 写入前清除进度行，写入后重绘，避免日志与进度输出在同一行
+func Writer(w io.Writer) io.Writer {
+	return &writer{w: w}
+}
+
+type writer struct {
+	w io.Writer
+}
+
+func (w *writer) Write(p []byte) (int, error) {
+	mu.Lock()
+	defer mu.Unlock()
+	if !drawn {
+		return w.w.Write(p)
+	}
+	erase()
+	n, err := w.w.Write(p)
+	draw()
+	return n, err
+}
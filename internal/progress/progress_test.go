@@ -0,0 +1,66 @@
+package progress
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBar(t *testing.T) {
+	var buf bytes.Buffer
+	mu.Lock()
+	forced, out = true, &buf
+	mu.Unlock()
+	defer func() {
+		mu.Lock()
+		forced, out = false, os.Stderr
+		mu.Unlock()
+	}()
+
+	task := Bar("upload app.tar.gz", 4<<20)
+	if _, err := io.Copy(io.Discard, task.Reader(bytes.NewReader(make([]byte, 1<<20)))); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(delay + 2*interval)
+	var log bytes.Buffer
+	_, _ = Writer(&log).Write([]byte("uploaded\n"))
+	task.Stop()
+
+	output := buf.String()
+	if !strings.Contains(output, "upload app.tar.gz [======                  ]  25% 1.0 MiB/4.0 MiB") {
+		t.Errorf("expected progress bar drawn, but %q got", output)
+	}
+	if !strings.HasSuffix(output, "\r\033[K") || log.String() != "uploaded\n" {
+		t.Errorf("expected progress line cleared, but %q got", output)
+	}
+}
+
+func TestDisable(t *testing.T) {
+	Disable()
+	defer func() {
+		mu.Lock()
+		disabled = false
+		mu.Unlock()
+	}()
+	task := Start("clone %s", "repo")
+	if task != nil {
+		t.Fatal("expected no task when disabled")
+	}
+	// nil 任务的方法均可调用
+	task.Add(1)
+	task.Stop()
+	if r := strings.NewReader("x"); task.Reader(r) != r {
+		t.Error("expected reader unchanged")
+	}
+}
+
+func TestBytes(t *testing.T) {
+	for n, expected := range map[int64]string{512: "512 B", 1536: "1.5 KiB", 5 << 30: "5.0 GiB"} {
+		if actual := Bytes(n); actual != expected {
+			t.Errorf("expected %s, but %s got", expected, actual)
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package promote
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/artifact"
+	"github.com/coffee377/autoctl/internal/registry"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// DefaultJournal 晋级记录文件，每行一条 JSON 记录
+const DefaultJournal = ".autoctl/promotions.jsonl"
+
+// Environment 发布环境
+type Environment struct {
+	Images    []string `mapstructure:"images"`    // 镜像名称，不含标签，镜像标签为版本号，如 registry.example.com/org/app
+	Artifacts string   `mapstructure:"artifacts"` // 产物目录，{version} 替换为版本号，如 /mnt/releases/staging/{version}
+}
+
+// Config 版本晋级配置，对应配置文件 promote 节点
+type Config struct {
+	Environments map[string]Environment `mapstructure:"environments"`
+	Registry     registry.Config        `mapstructure:"registry"` // 镜像仓库访问配置
+	Journal      string                 `mapstructure:"journal"`  // 晋级记录文件，默认 DefaultJournal
+}
+
+// Image 复制的镜像
+type Image struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+	Digest string `json:"digest,omitempty"`
+}
+
+// Record 一次版本晋级的记录
+type Record struct {
+	Version   string              `json:"version"`
+	From      string              `json:"from"`
+	To        string              `json:"to"`
+	Images    []Image             `json:"images,omitempty"`
+	Artifacts []artifact.Artifact `json:"artifacts,omitempty"`
+	Actor     string              `json:"actor,omitempty"`
+	Time      time.Time           `json:"time"`
+	DryRun    bool                `json:"dryRun,omitempty"`
+}
+
+// Environment 查找环境配置
+func (c Config) Environment(name string) (Environment, error) {
+	env, ok := c.Environments[name]
+	if !ok {
+		names := make([]string, 0, len(c.Environments))
+		for n := range c.Environments {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return env, fmt.Errorf("unknown environment %q, configured environments: %s", name, strings.Join(names, ", "))
+	}
+	return env, nil
+}
+
+// Pair 按镜像名称的最后一段配对源环境及目标环境的镜像，如 staging 的 ghcr.io/org/app 对应 production 的 registry.example.com/prod/app
+func Pair(from, to []string) ([][2]registry.Reference, error) {
+	targets := map[string]registry.Reference{}
+	for _, name := range to {
+		ref, err := registry.ParseReference(name)
+		if err != nil {
+			return nil, err
+		}
+		targets[path.Base(ref.Repository)] = ref
+	}
+	pairs := make([][2]registry.Reference, 0, len(from))
+	for _, name := range from {
+		src, err := registry.ParseReference(name)
+		if err != nil {
+			return nil, err
+		}
+		dst, ok := targets[path.Base(src.Repository)]
+		if !ok {
+			return nil, fmt.Errorf("no target image for %s", src.Name())
+		}
+		pairs = append(pairs, [2]registry.Reference{src, dst})
+	}
+	return pairs, nil
+}
+
+// Promote 将版本的镜像及产物从 from 环境复制到 to 环境，不重新构建；演练时只计算需要复制的内容
+func Promote(cfg Config, version, from, to string, dryRun bool) (*Record, error) {
+	if from == to {
+		return nil, fmt.Errorf("source and target environment are both %s", from)
+	}
+	src, err := cfg.Environment(from)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := cfg.Environment(to)
+	if err != nil {
+		return nil, err
+	}
+	rec := &Record{Version: version, From: from, To: to, Actor: actor(), Time: time.Now().UTC(), DryRun: dryRun}
+	pairs, err := Pair(src.Images, dst.Images)
+	if err != nil {
+		return nil, err
+	}
+	if len(pairs) > 0 {
+		client, err := registry.New(cfg.Registry)
+		if err != nil {
+			return nil, err
+		}
+		for _, p := range pairs {
+			p[0].Tag, p[1].Tag = version, version
+			image := Image{Source: p[0].String(), Target: p[1].String()}
+			if !dryRun {
+				if image.Digest, err = client.Copy(p[0], p[1]); err != nil {
+					return rec, fmt.Errorf("copy %s to %s: %w", image.Source, image.Target, err)
+				}
+				log.Info("copied %s to %s", image.Source, image.Target)
+			}
+			rec.Images = append(rec.Images, image)
+		}
+	}
+	if src.Artifacts != "" && dst.Artifacts != "" {
+		if rec.Artifacts, err = copyArtifacts(expand(src.Artifacts, version), expand(dst.Artifacts, version), dryRun); err != nil {
+			return rec, err
+		}
+	}
+	return rec, nil
+}
+
+func expand(location, version string) string {
+	return strings.ReplaceAll(location, "{version}", version)
+}
+
+// actor 执行晋级的用户
+func actor() string {
+	for _, key := range []string{"GITHUB_ACTOR", "GITLAB_USER_LOGIN", "USER", "USERNAME"} {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// copyArtifacts 复制目录中的产物文件并校验摘要，目标中摘要一致的文件跳过
+func copyArtifacts(src, dst string, dryRun bool) ([]artifact.Artifact, error) {
+	list, err := artifact.Collect(src, []string{"*"})
+	if err != nil {
+		return nil, err
+	}
+	if !dryRun {
+		if err = os.MkdirAll(dst, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	copied := make([]artifact.Artifact, 0, len(list))
+	for _, a := range list {
+		target := filepath.Join(dst, a.Name)
+		if existing, err := artifact.New(target); err == nil && existing != nil && existing.SHA256 == a.SHA256 {
+			copied = append(copied, *existing)
+			continue
+		}
+		if !dryRun {
+			if err = copyFile(a.Path, target); err != nil {
+				return copied, err
+			}
+			c, err := artifact.New(target)
+			if err != nil {
+				return copied, err
+			}
+			if c.SHA256 != a.SHA256 {
+				return copied, fmt.Errorf("checksum mismatch after copying %s to %s", a.Path, target)
+			}
+			log.Info("copied %s to %s", a.Path, target)
+		}
+		a.Path = target
+		copied = append(copied, a)
+	}
+	return copied, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// Append 在晋级记录文件末尾追加记录
+func Append(journal string, rec *Record) error {
+	if journal == "" {
+		journal = DefaultJournal
+	}
+	if err := os.MkdirAll(filepath.Dir(journal), 0o755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(journal, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Note 追加到版本发布说明中的晋级记录
+func Note(rec *Record) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n\n> Promoted from `%s` to `%s` on %s", rec.From, rec.To, rec.Time.Format(time.RFC3339)))
+	if rec.Actor != "" {
+		sb.WriteString(" by @" + rec.Actor)
+	}
+	for _, img := range rec.Images {
+		sb.WriteString(fmt.Sprintf("\n> - `%s`", img.Target))
+		if img.Digest != "" {
+			sb.WriteString(fmt.Sprintf(" (%s)", img.Digest))
+		}
+	}
+	return sb.String()
+}
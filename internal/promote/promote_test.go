@@ -0,0 +1,66 @@
+package promote
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPair(t *testing.T) {
+	pairs, err := Pair([]string{"ghcr.io/org/app", "ghcr.io/org/worker"}, []string{"registry.example.com/prod/worker", "registry.example.com/prod/app"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pairs) != 2 || pairs[0][1].Name() != "registry.example.com/prod/app" || pairs[1][1].Name() != "registry.example.com/prod/worker" {
+		t.Errorf("unexpected pairs %v", pairs)
+	}
+	if _, err = Pair([]string{"ghcr.io/org/api"}, []string{"registry.example.com/prod/app"}); err == nil {
+		t.Error("expected missing target image error")
+	}
+}
+
+func TestPromote_Artifacts(t *testing.T) {
+	dir := t.TempDir()
+	staging := filepath.Join(dir, "staging", "1.2.0")
+	_ = os.MkdirAll(staging, 0o755)
+	_ = os.WriteFile(filepath.Join(staging, "app.tar.gz"), []byte("app"), 0o644)
+	cfg := Config{Environments: map[string]Environment{
+		"staging":    {Artifacts: filepath.Join(dir, "staging", "{version}")},
+		"production": {Artifacts: filepath.Join(dir, "production", "{version}")},
+	}}
+
+	rec, err := Promote(cfg, "1.2.0", "staging", "production", true)
+	if err != nil || len(rec.Artifacts) != 1 {
+		t.Fatalf("expected 1 artifact to copy, but %+v %v got", rec, err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "production")); !os.IsNotExist(err) {
+		t.Errorf("expected nothing copied on dry run, but %v got", err)
+	}
+	if rec, err = Promote(cfg, "1.2.0", "staging", "production", false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "production", "1.2.0", "app.tar.gz")); string(data) != "app" {
+		t.Errorf("expected artifact copied, but %q got", data)
+	}
+	if _, err = Promote(cfg, "1.2.0", "staging", "qa", false); err == nil {
+		t.Error("expected unknown environment error")
+	}
+
+	journal := filepath.Join(dir, "promotions.jsonl")
+	_ = Append(journal, rec)
+	_ = Append(journal, rec)
+	f, _ := os.Open(journal)
+	defer f.Close()
+	lines := 0
+	for scanner := bufio.NewScanner(f); scanner.Scan(); lines++ {
+		got := Record{}
+		if err = json.Unmarshal(scanner.Bytes(), &got); err != nil || got.To != "production" {
+			t.Errorf("unexpected record %s %v", scanner.Text(), err)
+		}
+	}
+	if lines != 2 {
+		t.Errorf("expected 2 records, but %d got", lines)
+	}
+}
@@ -0,0 +1,117 @@
+package prompt
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/i18n"
+)
+
+// Option 选择项
+type Option struct {
+	Value       string
+	Description string
+}
+
+// Prompter 基于行输入的交互式问答
+type Prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+}
+
+func New(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{in: bufio.NewReader(in), out: out}
+}
+
+// Printf 输出提示信息
+func (p *Prompter) Printf(format string, a ...interface{}) {
+	_, _ = fmt.Fprintf(p.out, format, a...)
+}
+
+// readLine 读取一行输入，输入结束且无内容时返回 io.EOF
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.in.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// Input 读取文本，直接回车时返回默认值
+func (p *Prompter) Input(label, def string) (string, error) {
+	if def != "" {
+		p.Printf("%s (%s): ", label, def)
+	} else {
+		p.Printf("%s: ", label)
+	}
+	line, err := p.readLine()
+	if err != nil {
+		return "", err
+	}
+	if line == "" {
+		return def, nil
+	}
+	return line, nil
+}
+
+// Confirm 读取是否确认，直接回车时返回默认值
+func (p *Prompter) Confirm(label string, def bool) (bool, error) {
+	hint := i18n.T("y/N")
+	if def {
+		hint = i18n.T("Y/n")
+	}
+	for {
+		p.Printf("%s [%s]: ", label, hint)
+		line, err := p.readLine()
+		if err != nil {
+			return false, err
+		}
+		if line == "" {
+			return def, nil
+		}
+		if yes, ok := i18n.Yes(line); ok {
+			return yes, nil
+		}
+	}
+}
+
+// Select 从选项中选择一项，可输入序号或选项值；allowOther 为 true 时允许输入不在选项中的值
+func (p *Prompter) Select(label string, options []Option, def string, allowOther bool) (string, error) {
+	p.Printf("%s\n", label)
+	width := 0
+	for _, o := range options {
+		if len(o.Value) > width {
+			width = len(o.Value)
+		}
+	}
+	for i, o := range options {
+		line := fmt.Sprintf("  %2d) %-*s  %s", i+1, width, o.Value, o.Description)
+		p.Printf("%s\n", strings.TrimRight(line, " "))
+	}
+	for {
+		p.Printf("> ")
+		value, err := p.readLine()
+		if err != nil {
+			return "", err
+		}
+		if value == "" {
+			value = def
+		}
+		if n, err := strconv.Atoi(value); err == nil && n >= 1 && n <= len(options) {
+			return options[n-1].Value, nil
+		}
+		for _, o := range options {
+			if o.Value == value {
+				return value, nil
+			}
+		}
+		if allowOther {
+			return value, nil
+		}
+		p.Printf("%s", i18n.T("invalid choice %q\n", value))
+	}
+}
@@ -0,0 +1,31 @@
+package prompt
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestPrompter(t *testing.T) {
+	var out bytes.Buffer
+	p := New(strings.NewReader("x\n2\n\nyes\ncore\n"), &out)
+	options := []Option{{Value: "feat"}, {Value: "fix"}}
+	if v, err := p.Select("type", options, "", false); err != nil || v != "fix" {
+		t.Errorf("expected fix after invalid choice, but %q %v got", v, err)
+	}
+	if v, err := p.Input("subject", "default"); err != nil || v != "default" {
+		t.Errorf("expected default value, but %q %v got", v, err)
+	}
+	if v, err := p.Confirm("breaking", false); err != nil || !v {
+		t.Errorf("expected confirmed, but %v %v got", v, err)
+	}
+	if v, err := p.Select("scope", options, "", true); err != nil || v != "core" {
+		t.Errorf("expected other value core, but %q %v got", v, err)
+	}
+	if _, err := p.Input("body", ""); err == nil {
+		t.Error("expected EOF error, got nil")
+	}
+	if !strings.Contains(out.String(), `invalid choice "x"`) {
+		t.Errorf("expected invalid choice hint, but %q got", out.String())
+	}
+}
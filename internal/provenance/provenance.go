@@ -0,0 +1,195 @@
+package provenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/artifact"
+	"github.com/coffee377/autoctl/internal/runctx"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+const (
+	StatementType  = "https://in-toto.io/Statement/v1"
+	PredicateType  = "https://slsa.dev/provenance/v1"
+	BuildType      = "https://github.com/coffee377/autoctl/release/v1"
+	DefaultOutput  = "provenance.intoto.jsonl"
+	DefaultBuilder = "https://github.com/coffee377/autoctl"
+)
+
+// Config SLSA 来源证明配置，对应配置文件 release.provenance 节点
+type Config struct {
+	Enabled   bool   `mapstructure:"enabled"`   // 发布时生成来源证明并作为产物上传
+	Output    string `mapstructure:"output"`    // 输出文件，默认 provenance.intoto.jsonl，相对路径基于工作目录
+	BuilderID string `mapstructure:"builderId"` // 构建者标识，默认根据 CI 环境推断
+	Cosign    bool   `mapstructure:"cosign"`    // 存在 cosign 时使用 cosign attest-blob 对每个产物签署证明
+	Key       string `mapstructure:"key"`       // cosign 私钥，为空时使用无密钥签名
+}
+
+// Source 构建来源
+type Source struct {
+	URI    string // 仓库地址
+	Ref    string // 引用，如 refs/tags/v1.0.0
+	Commit string
+}
+
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+type Predicate struct {
+	BuildDefinition BuildDefinition `json:"buildDefinition"`
+	RunDetails      RunDetails      `json:"runDetails"`
+}
+
+type BuildDefinition struct {
+	BuildType            string                 `json:"buildType"`
+	ExternalParameters   map[string]interface{} `json:"externalParameters"`
+	InternalParameters   map[string]interface{} `json:"internalParameters,omitempty"`
+	ResolvedDependencies []ResourceDescriptor   `json:"resolvedDependencies"`
+}
+
+type ResourceDescriptor struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+type RunDetails struct {
+	Builder  Builder  `json:"builder"`
+	Metadata Metadata `json:"metadata"`
+}
+
+type Builder struct {
+	ID string `json:"id"`
+}
+
+type Metadata struct {
+	InvocationID string    `json:"invocationId,omitempty"`
+	StartedOn    time.Time `json:"startedOn"`
+	FinishedOn   time.Time `json:"finishedOn"`
+}
+
+// NewStatement 为产物生成 SLSA v1 来源证明
+func NewStatement(cfg Config, src Source, artifacts []artifact.Artifact, params map[string]interface{}, started time.Time) (*Statement, error) {
+	if len(artifacts) == 0 {
+		return nil, fmt.Errorf("no artifact to attest")
+	}
+	subjects := make([]Subject, 0, len(artifacts))
+	for _, a := range artifacts {
+		subjects = append(subjects, Subject{Name: a.Name, Digest: map[string]string{"sha256": a.SHA256}})
+	}
+	builder, invocation := detectBuilder()
+	if cfg.BuilderID != "" {
+		builder = cfg.BuilderID
+	}
+	return &Statement{
+		Type:          StatementType,
+		Subject:       subjects,
+		PredicateType: PredicateType,
+		Predicate: Predicate{
+			BuildDefinition: BuildDefinition{
+				BuildType:          BuildType,
+				ExternalParameters: params,
+				ResolvedDependencies: []ResourceDescriptor{{
+					URI:    fmt.Sprintf("git+%s@%s", redact(src.URI), src.Ref),
+					Digest: map[string]string{"gitCommit": src.Commit},
+				}},
+			},
+			RunDetails: RunDetails{
+				Builder:  Builder{ID: builder},
+				Metadata: Metadata{InvocationID: invocation, StartedOn: started.UTC(), FinishedOn: time.Now().UTC()},
+			},
+		},
+	}, nil
+}
+
+// redact 去除仓库地址中的认证信息
+func redact(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.User != nil {
+		u.User = nil
+		return u.String()
+	}
+	return uri
+}
+
+// detectBuilder 根据 CI 环境变量推断构建者标识及本次执行地址
+func detectBuilder() (string, string) {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		server := os.Getenv("GITHUB_SERVER_URL")
+		run := fmt.Sprintf("%s/%s/actions/runs/%s/attempts/%s", server, os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"), os.Getenv("GITHUB_RUN_ATTEMPT"))
+		return server + "/actions/runner/" + os.Getenv("RUNNER_ENVIRONMENT"), run
+	case os.Getenv("GITLAB_CI") == "true":
+		return os.Getenv("CI_SERVER_URL") + "/-/runners/" + os.Getenv("CI_RUNNER_ID"), os.Getenv("CI_JOB_URL")
+	}
+	return DefaultBuilder, ""
+}
+
+// Write 以 JSON Lines 格式写入来源证明，启用 cosign 时同时为每个产物签署证明，返回生成的文件
+func Write(cfg Config, dir string, statement *Statement, artifacts []artifact.Artifact) ([]string, error) {
+	output := cfg.Output
+	if output == "" {
+		output = DefaultOutput
+	}
+	if !filepath.IsAbs(output) && dir != "" {
+		output = filepath.Join(dir, output)
+	}
+	data, err := json.Marshal(statement)
+	if err != nil {
+		return nil, err
+	}
+	if err = os.WriteFile(output, append(data, '\n'), 0o644); err != nil {
+		return nil, err
+	}
+	log.Info("generated provenance %s", output)
+	files := []string{output}
+	if !cfg.Cosign {
+		return files, nil
+	}
+	if _, err = exec.LookPath("cosign"); err != nil {
+		log.Warn("cosign not found, skip provenance attestation")
+		return files, nil
+	}
+	predicate, err := json.Marshal(statement.Predicate)
+	if err != nil {
+		return nil, err
+	}
+	predicateFile := output + ".predicate.json"
+	if err = os.WriteFile(predicateFile, predicate, 0o644); err != nil {
+		return nil, err
+	}
+	defer os.Remove(predicateFile)
+	for _, a := range artifacts {
+		bundle := filepath.Join(filepath.Dir(output), a.Name+".intoto.sigstore.json")
+		args := []string{"attest-blob", "--yes", "--type", "slsaprovenance1", "--predicate", predicateFile, "--bundle", bundle}
+		if cfg.Key != "" {
+			args = append(args, "--key", cfg.Key)
+		}
+		ctx, cancel := runctx.Command()
+		cmd := exec.CommandContext(ctx, "cosign", append(args, a.Path)...)
+		start := time.Now()
+		out, err := cmd.CombinedOutput()
+		log.TraceCommand(cmd, start, err)
+		err = runctx.Wrap(ctx, err)
+		cancel()
+		if err != nil {
+			return nil, fmt.Errorf("cosign attest-blob %s: %w: %s", a.Name, err, strings.TrimSpace(string(out)))
+		}
+		files = append(files, bundle)
+	}
+	return files, nil
+}
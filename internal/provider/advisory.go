@@ -0,0 +1,55 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Advisory 仓库安全公告
+type Advisory struct {
+	ID          string // 平台分配的编号，GitHub 为 GHSA 编号
+	Summary     string
+	Description string
+	Severity    string // 严重程度 low | medium | high | critical
+	CVE         string // 已分配的 CVE 编号
+	Ecosystem   string // 受影响软件包的生态，如 go、npm、maven
+	Package     string // 受影响的软件包名称
+	Vulnerable  string // 受影响的版本范围，如 < 1.2.3
+	Patched     string // 修复版本
+	URL         string
+}
+
+// AdvisoryPublisher 支持创建安全公告的平台
+type AdvisoryPublisher interface {
+	// CreateAdvisory 创建安全公告草稿，由维护者在平台上审核后发布
+	CreateAdvisory(advisory *Advisory) (*Advisory, error)
+}
+
+// CreateAdvisory https://docs.github.com/en/rest/security-advisories/repository-advisories#create-a-repository-security-advisory
+func (g *github) CreateAdvisory(advisory *Advisory) (*Advisory, error) {
+	req := map[string]interface{}{
+		"summary":     advisory.Summary,
+		"description": advisory.Description,
+		"severity":    advisory.Severity,
+		"vulnerabilities": []map[string]interface{}{{
+			"package":                  map[string]string{"ecosystem": advisory.Ecosystem, "name": advisory.Package},
+			"vulnerable_version_range": advisory.Vulnerable,
+			"patched_versions":         advisory.Patched,
+		}},
+	}
+	if advisory.CVE != "" {
+		req["cve_id"] = advisory.CVE
+	}
+	res := struct {
+		GHSAID  string `json:"ghsa_id"`
+		HTMLURL string `json:"html_url"`
+	}{}
+	u := fmt.Sprintf("%s/repos/%s/security-advisories", g.cfg.URL, g.cfg.Repo)
+	if err := doJSON(g.client, http.MethodPost, u, g.headers(), req, &res); err != nil {
+		return nil, err
+	}
+	created := *advisory
+	created.ID = res.GHSAID
+	created.URL = res.HTMLURL
+	return &created, nil
+}
@@ -0,0 +1,241 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// 发布审批状态
+const (
+	ApprovalPending  = "pending"
+	ApprovalApproved = "approved"
+	ApprovalRejected = "rejected"
+)
+
+// Approval 平台上等待审批的部署（GitHub）或手动作业（GitLab）
+type Approval struct {
+	ID          string `json:"id"`
+	Ref         string `json:"ref"`         // 待发布的提交
+	Environment string `json:"environment"` // GitHub 部署环境或 GitLab 手动作业名称
+	Description string `json:"description,omitempty"`
+	URL         string `json:"url,omitempty"`
+}
+
+// PullRequestApproval 合并请求上的审批条件，设置标签或评论任一即可
+type PullRequestApproval struct {
+	Number    int      // 合并请求编号
+	Label     string   // 出现该标签时视为审批通过
+	Comment   string   // 出现内容一致的评论时视为审批通过，如 /approve
+	Reviewers []string // 允许审批的用户，为空时不限制
+}
+
+// Approver 支持发布审批的平台
+type Approver interface {
+	// RequestApproval 创建等待审批的部署，GitLab 中查找提交所在流水线的手动作业
+	RequestApproval(approval *Approval) (*Approval, error)
+	// ApprovalStatus 查询审批状态
+	ApprovalStatus(approval *Approval) (string, error)
+	// PullRequestStatus 查询合并请求上的审批状态，只返回 pending 或 approved
+	PullRequestStatus(cond PullRequestApproval) (string, error)
+}
+
+// allowed 评论者是否允许审批
+func (cond PullRequestApproval) allowed(user string) bool {
+	if len(cond.Reviewers) == 0 {
+		return true
+	}
+	for _, r := range cond.Reviewers {
+		if strings.EqualFold(r, user) {
+			return true
+		}
+	}
+	return false
+}
+
+func (cond PullRequestApproval) status(labels []string, comments map[string][]string) string {
+	if cond.Label != "" {
+		for _, l := range labels {
+			if strings.EqualFold(l, cond.Label) {
+				return ApprovalApproved
+			}
+		}
+	}
+	if cond.Comment != "" {
+		for user, bodies := range comments {
+			for _, body := range bodies {
+				if strings.TrimSpace(body) == cond.Comment && cond.allowed(user) {
+					return ApprovalApproved
+				}
+			}
+		}
+	}
+	return ApprovalPending
+}
+
+// RequestApproval https://docs.github.com/en/rest/deployments/deployments#create-a-deployment
+// 部署环境配置了保护规则时，需要审批者在平台上批准
+func (g *github) RequestApproval(approval *Approval) (*Approval, error) {
+	req := map[string]interface{}{
+		"ref":               approval.Ref,
+		"environment":       approval.Environment,
+		"description":       approval.Description,
+		"auto_merge":        false,
+		"required_contexts": []string{},
+	}
+	res := struct {
+		ID  int64  `json:"id"`
+		URL string `json:"url"`
+	}{}
+	if err := doJSON(g.client, http.MethodPost, fmt.Sprintf("%s/repos/%s/deployments", g.cfg.URL, g.cfg.Repo), g.headers(), req, &res); err != nil {
+		return nil, err
+	}
+	created := *approval
+	created.ID = strconv.FormatInt(res.ID, 10)
+	created.URL = res.URL
+	return &created, nil
+}
+
+// ApprovalStatus https://docs.github.com/en/rest/deployments/statuses#list-deployment-statuses
+func (g *github) ApprovalStatus(approval *Approval) (string, error) {
+	var statuses []struct {
+		State string `json:"state"`
+	}
+	url := fmt.Sprintf("%s/repos/%s/deployments/%s/statuses", g.cfg.URL, g.cfg.Repo, approval.ID)
+	if err := doJSON(g.client, http.MethodGet, url, noCache(g.headers()), nil, &statuses); err != nil {
+		return "", err
+	}
+	if len(statuses) == 0 {
+		return ApprovalPending, nil
+	}
+	// 状态按创建时间倒序
+	switch statuses[0].State {
+	case "success", "in_progress", "inactive":
+		return ApprovalApproved, nil
+	case "failure", "error":
+		return ApprovalRejected, nil
+	default:
+		return ApprovalPending, nil
+	}
+}
+
+// PullRequestStatus https://docs.github.com/en/rest/issues/labels#list-labels-for-an-issue
+// https://docs.github.com/en/rest/issues/comments#list-issue-comments
+func (g *github) PullRequestStatus(cond PullRequestApproval) (string, error) {
+	issue := fmt.Sprintf("%s/repos/%s/issues/%d", g.cfg.URL, g.cfg.Repo, cond.Number)
+	labels := make([]string, 0)
+	if cond.Label != "" {
+		var res []struct {
+			Name string `json:"name"`
+		}
+		if err := doJSON(g.client, http.MethodGet, issue+"/labels", noCache(g.headers()), nil, &res); err != nil {
+			return "", err
+		}
+		for _, l := range res {
+			labels = append(labels, l.Name)
+		}
+	}
+	comments := map[string][]string{}
+	if cond.Comment != "" {
+		var res []struct {
+			Body string `json:"body"`
+			User struct {
+				Login string `json:"login"`
+			} `json:"user"`
+		}
+		if err := doJSON(g.client, http.MethodGet, issue+"/comments?per_page=100", noCache(g.headers()), nil, &res); err != nil {
+			return "", err
+		}
+		for _, c := range res {
+			comments[c.User.Login] = append(comments[c.User.Login], c.Body)
+		}
+	}
+	return cond.status(labels, comments), nil
+}
+
+type gitlabJob struct {
+	ID     int64  `json:"id"`
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	WebURL string `json:"web_url"`
+}
+
+// RequestApproval 查找提交最近一次流水线中的手动作业，审批者在平台上执行该作业即为批准
+// https://docs.gitlab.com/ee/api/pipelines.html#list-project-pipelines
+// https://docs.gitlab.com/ee/api/jobs.html#list-pipeline-jobs
+func (g *gitlab) RequestApproval(approval *Approval) (*Approval, error) {
+	var pipelines []struct {
+		ID int64 `json:"id"`
+	}
+	query := url.Values{"sha": {approval.Ref}, "order_by": {"id"}, "sort": {"desc"}}
+	if err := doJSON(g.client, http.MethodGet, g.projectURL()+"/pipelines?"+query.Encode(), noCache(g.headers()), nil, &pipelines); err != nil {
+		return nil, err
+	}
+	if len(pipelines) == 0 {
+		return nil, fmt.Errorf("no pipeline found for commit %s", approval.Ref)
+	}
+	var jobs []gitlabJob
+	if err := doJSON(g.client, http.MethodGet, fmt.Sprintf("%s/pipelines/%d/jobs?per_page=100", g.projectURL(), pipelines[0].ID), noCache(g.headers()), nil, &jobs); err != nil {
+		return nil, err
+	}
+	for _, job := range jobs {
+		if job.Name == approval.Environment {
+			created := *approval
+			created.ID = strconv.FormatInt(job.ID, 10)
+			created.URL = job.WebURL
+			return &created, nil
+		}
+	}
+	return nil, fmt.Errorf("manual job %s not found in pipeline %d", approval.Environment, pipelines[0].ID)
+}
+
+// ApprovalStatus https://docs.gitlab.com/ee/api/jobs.html#get-a-single-job
+func (g *gitlab) ApprovalStatus(approval *Approval) (string, error) {
+	job := gitlabJob{}
+	if err := doJSON(g.client, http.MethodGet, fmt.Sprintf("%s/jobs/%s", g.projectURL(), approval.ID), noCache(g.headers()), nil, &job); err != nil {
+		return "", err
+	}
+	switch job.Status {
+	case "success", "running":
+		return ApprovalApproved, nil
+	case "failed", "canceled", "skipped":
+		return ApprovalRejected, nil
+	default:
+		return ApprovalPending, nil
+	}
+}
+
+// PullRequestStatus https://docs.gitlab.com/ee/api/merge_requests.html#get-single-mr
+// https://docs.gitlab.com/ee/api/notes.html#list-all-merge-request-notes
+func (g *gitlab) PullRequestStatus(cond PullRequestApproval) (string, error) {
+	mr := fmt.Sprintf("%s/merge_requests/%d", g.projectURL(), cond.Number)
+	res := struct {
+		Labels []string `json:"labels"`
+	}{}
+	if cond.Label != "" {
+		if err := doJSON(g.client, http.MethodGet, mr, noCache(g.headers()), nil, &res); err != nil {
+			return "", err
+		}
+	}
+	comments := map[string][]string{}
+	if cond.Comment != "" {
+		var notes []struct {
+			Body   string `json:"body"`
+			System bool   `json:"system"`
+			Author struct {
+				Username string `json:"username"`
+			} `json:"author"`
+		}
+		if err := doJSON(g.client, http.MethodGet, mr+"/notes?per_page=100", noCache(g.headers()), nil, &notes); err != nil {
+			return "", err
+		}
+		for _, n := range notes {
+			if !n.System {
+				comments[n.Author.Username] = append(comments[n.Author.Username], n.Body)
+			}
+		}
+	}
+	return cond.status(res.Labels, comments), nil
+}
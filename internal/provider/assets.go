@@ -0,0 +1,119 @@
+package provider
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Asset 版本发布的附件
+type Asset struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`              // 下载地址
+	Size   int64  `json:"size,omitempty"`   // 文件大小（字节），平台未提供时为 0
+	SHA256 string `json:"sha256,omitempty"` // 平台提供的摘要，未提供时为空
+}
+
+// AssetDownloader 支持下载版本发布附件的平台
+type AssetDownloader interface {
+	// ListAssets 列出标签对应版本发布的附件
+	ListAssets(tag string) ([]*Asset, error)
+	// DownloadAsset 从 offset 处开始下载附件，服务端不支持断点续传时从头下载并返回 resumed 为 false
+	DownloadAsset(asset *Asset, offset int64) (body io.ReadCloser, resumed bool, err error)
+}
+
+// ListAssets https://docs.github.com/en/rest/releases/releases#get-a-release-by-tag-name
+func (g *github) ListAssets(tag string) ([]*Asset, error) {
+	res := struct {
+		Assets []struct {
+			Name   string `json:"name"`
+			URL    string `json:"url"`
+			Size   int64  `json:"size"`
+			Digest string `json:"digest"` // sha256:<hex>
+		} `json:"assets"`
+	}{}
+	u := fmt.Sprintf("%s/repos/%s/releases/tags/%s", g.cfg.URL, g.cfg.Repo, url.PathEscape(tag))
+	if err := doJSON(g.client, http.MethodGet, u, g.headers(), nil, &res); err != nil {
+		return nil, err
+	}
+	assets := make([]*Asset, 0, len(res.Assets))
+	for _, a := range res.Assets {
+		assets = append(assets, &Asset{Name: a.Name, URL: a.URL, Size: a.Size, SHA256: strings.TrimPrefix(a.Digest, "sha256:")})
+	}
+	return assets, nil
+}
+
+// DownloadAsset https://docs.github.com/en/rest/releases/assets#get-a-release-asset
+// 通过 API 地址下载以支持私有仓库，重定向到存储服务时不携带令牌
+func (g *github) DownloadAsset(asset *Asset, offset int64) (io.ReadCloser, bool, error) {
+	headers := g.headers()
+	headers["Accept"] = "application/octet-stream"
+	return download(g.client, asset.URL, headers, offset)
+}
+
+// ListAssets https://docs.gitlab.com/ee/api/releases/#get-a-release-by-a-tag-name
+// GitLab 附件为版本发布的链接，不提供文件大小及摘要
+func (g *gitlab) ListAssets(tag string) ([]*Asset, error) {
+	res := struct {
+		Assets struct {
+			Links []struct {
+				Name           string `json:"name"`
+				URL            string `json:"url"`
+				DirectAssetURL string `json:"direct_asset_url"`
+			} `json:"links"`
+		} `json:"assets"`
+	}{}
+	if err := doJSON(g.client, http.MethodGet, g.projectURL()+"/releases/"+url.PathEscape(tag), g.headers(), nil, &res); err != nil {
+		return nil, err
+	}
+	assets := make([]*Asset, 0, len(res.Assets.Links))
+	for _, l := range res.Assets.Links {
+		u := l.DirectAssetURL
+		if u == "" {
+			u = l.URL
+		}
+		assets = append(assets, &Asset{Name: l.Name, URL: u})
+	}
+	return assets, nil
+}
+
+// DownloadAsset 链接与 API 同一主机时携带令牌，以支持私有项目的上传文件
+func (g *gitlab) DownloadAsset(asset *Asset, offset int64) (io.ReadCloser, bool, error) {
+	headers := map[string]string{}
+	if api, err := url.Parse(g.cfg.URL); err == nil {
+		if u, err := url.Parse(asset.URL); err == nil && u.Host == api.Host {
+			headers = g.headers()
+		}
+	}
+	return download(g.client, asset.URL, headers, offset)
+}
+
+// download 发送下载请求，offset 大于 0 时请求剩余部分，响应不经过缓存
+func download(client *http.Client, u string, headers map[string]string, offset int64) (io.ReadCloser, bool, error) {
+	req, err := http.NewRequest(http.MethodGet, u, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Cache-Control", "no-store")
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		return resp.Body, true, nil
+	case resp.StatusCode == http.StatusOK:
+		return resp.Body, false, nil
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return nil, false, &StatusError{Method: http.MethodGet, URL: u, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+}
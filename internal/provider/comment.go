@@ -0,0 +1,36 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Comment 议题或合并请求上的评论
+type Comment struct {
+	Number      int    // 议题或合并请求编号
+	PullRequest bool   // 是否为合并请求，GitHub 中两者共用议题评论接口
+	Body        string // Markdown 格式的评论内容
+}
+
+// Commenter 支持在议题及合并请求上发表评论的平台
+type Commenter interface {
+	// CreateComment 发表评论
+	CreateComment(comment *Comment) error
+}
+
+// CreateComment https://docs.github.com/en/rest/issues/comments#create-an-issue-comment
+func (g *github) CreateComment(comment *Comment) error {
+	url := fmt.Sprintf("%s/repos/%s/issues/%d/comments", g.cfg.URL, g.cfg.Repo, comment.Number)
+	return doJSON(g.client, http.MethodPost, url, g.headers(), map[string]string{"body": comment.Body}, nil)
+}
+
+// CreateComment https://docs.gitlab.com/ee/api/notes.html#create-new-merge-request-note
+// https://docs.gitlab.com/ee/api/notes.html#create-new-issue-note
+func (g *gitlab) CreateComment(comment *Comment) error {
+	kind := "issues"
+	if comment.PullRequest {
+		kind = "merge_requests"
+	}
+	url := fmt.Sprintf("%s/%s/%d/notes", g.projectURL(), kind, comment.Number)
+	return doJSON(g.client, http.MethodPost, url, g.headers(), map[string]string{"body": comment.Body}, nil)
+}
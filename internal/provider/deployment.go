@@ -0,0 +1,157 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Deployment 平台上记录的一次部署，Ref 为部署的版本标签
+type Deployment struct {
+	ID          string    `json:"id,omitempty"`
+	Environment string    `json:"environment"`
+	Ref         string    `json:"ref"`
+	SHA         string    `json:"sha,omitempty"`
+	Creator     string    `json:"creator,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	URL         string    `json:"url,omitempty"`
+}
+
+// DeploymentTracker 支持记录及查询部署的平台
+type DeploymentTracker interface {
+	// CreateDeployment 记录一次已成功的部署
+	CreateDeployment(deployment *Deployment) (*Deployment, error)
+	// LatestDeployment 查询环境中最近一次成功的部署，没有部署时返回 nil
+	LatestDeployment(environment string) (*Deployment, error)
+}
+
+type githubDeployment struct {
+	ID          int64     `json:"id"`
+	Ref         string    `json:"ref"`
+	SHA         string    `json:"sha"`
+	Environment string    `json:"environment"`
+	CreatedAt   time.Time `json:"created_at"`
+	URL         string    `json:"url"`
+	Creator     struct {
+		Login string `json:"login"`
+	} `json:"creator"`
+}
+
+func (d githubDeployment) deployment() *Deployment {
+	return &Deployment{
+		ID:          strconv.FormatInt(d.ID, 10),
+		Environment: d.Environment,
+		Ref:         d.Ref,
+		SHA:         d.SHA,
+		Creator:     d.Creator.Login,
+		CreatedAt:   d.CreatedAt,
+		URL:         d.URL,
+	}
+}
+
+// CreateDeployment https://docs.github.com/en/rest/deployments/deployments#create-a-deployment
+// 部署创建后随即设置 success 状态，环境页面展示当前部署的版本
+func (g *github) CreateDeployment(deployment *Deployment) (*Deployment, error) {
+	req := map[string]interface{}{
+		"ref":               deployment.Ref,
+		"environment":       deployment.Environment,
+		"description":       fmt.Sprintf("deploy %s to %s", deployment.Ref, deployment.Environment),
+		"auto_merge":        false,
+		"required_contexts": []string{},
+	}
+	res := githubDeployment{}
+	if err := doJSON(g.client, http.MethodPost, fmt.Sprintf("%s/repos/%s/deployments", g.cfg.URL, g.cfg.Repo), g.headers(), req, &res); err != nil {
+		return nil, err
+	}
+	status := map[string]string{"state": "success"}
+	u := fmt.Sprintf("%s/repos/%s/deployments/%d/statuses", g.cfg.URL, g.cfg.Repo, res.ID)
+	if err := doJSON(g.client, http.MethodPost, u, g.headers(), status, nil); err != nil {
+		return nil, err
+	}
+	return res.deployment(), nil
+}
+
+// LatestDeployment https://docs.github.com/en/rest/deployments/deployments#list-deployments
+// 部署按创建时间倒序，跳过最近状态不是 success 的部署
+func (g *github) LatestDeployment(environment string) (*Deployment, error) {
+	for page := 1; ; page++ {
+		var list []githubDeployment
+		query := url.Values{"environment": {environment}, "per_page": {"30"}, "page": {strconv.Itoa(page)}}
+		u := fmt.Sprintf("%s/repos/%s/deployments?%s", g.cfg.URL, g.cfg.Repo, query.Encode())
+		if err := doJSON(g.client, http.MethodGet, u, noCache(g.headers()), nil, &list); err != nil {
+			return nil, err
+		}
+		for _, d := range list {
+			var statuses []struct {
+				State string `json:"state"`
+			}
+			u = fmt.Sprintf("%s/repos/%s/deployments/%d/statuses?per_page=1", g.cfg.URL, g.cfg.Repo, d.ID)
+			if err := doJSON(g.client, http.MethodGet, u, noCache(g.headers()), nil, &statuses); err != nil {
+				return nil, err
+			}
+			if len(statuses) > 0 && statuses[0].State == "success" {
+				return d.deployment(), nil
+			}
+		}
+		if len(list) < 30 {
+			return nil, nil
+		}
+	}
+}
+
+type gitlabDeployment struct {
+	ID        int64     `json:"id"`
+	Ref       string    `json:"ref"`
+	SHA       string    `json:"sha"`
+	CreatedAt time.Time `json:"created_at"`
+	User      struct {
+		Username string `json:"username"`
+	} `json:"user"`
+	Environment struct {
+		Name        string `json:"name"`
+		ExternalURL string `json:"external_url"`
+	} `json:"environment"`
+}
+
+func (d gitlabDeployment) deployment() *Deployment {
+	return &Deployment{
+		ID:          strconv.FormatInt(d.ID, 10),
+		Environment: d.Environment.Name,
+		Ref:         d.Ref,
+		SHA:         d.SHA,
+		Creator:     d.User.Username,
+		CreatedAt:   d.CreatedAt,
+		URL:         d.Environment.ExternalURL,
+	}
+}
+
+// CreateDeployment https://docs.gitlab.com/ee/api/deployments.html#create-a-deployment
+func (g *gitlab) CreateDeployment(deployment *Deployment) (*Deployment, error) {
+	req := map[string]interface{}{
+		"environment": deployment.Environment,
+		"ref":         deployment.Ref,
+		"sha":         deployment.SHA,
+		"tag":         true,
+		"status":      "success",
+	}
+	res := gitlabDeployment{}
+	if err := doJSON(g.client, http.MethodPost, g.projectURL()+"/deployments", g.headers(), req, &res); err != nil {
+		return nil, err
+	}
+	return res.deployment(), nil
+}
+
+// LatestDeployment https://docs.gitlab.com/ee/api/deployments.html#list-project-deployments
+func (g *gitlab) LatestDeployment(environment string) (*Deployment, error) {
+	var list []gitlabDeployment
+	query := url.Values{"environment": {environment}, "status": {"success"}, "order_by": {"id"}, "sort": {"desc"}, "per_page": {"1"}}
+	if err := doJSON(g.client, http.MethodGet, g.projectURL()+"/deployments?"+query.Encode(), noCache(g.headers()), nil, &list); err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return nil, nil
+	}
+	return list[0].deployment(), nil
+}
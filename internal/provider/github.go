@@ -0,0 +1,176 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+type github struct {
+	cfg    Config
+	client *http.Client
+}
+
+func (g *github) Name() string {
+	return GitHub
+}
+
+func (g *github) headers() map[string]string {
+	headers := map[string]string{"Accept": "application/vnd.github+json", "X-GitHub-Api-Version": "2022-11-28"}
+	if g.cfg.Token != "" {
+		headers["Authorization"] = "Bearer " + g.cfg.Token
+	}
+	return headers
+}
+
+type githubRelease struct {
+	ID         int64  `json:"id,omitempty"`
+	TagName    string `json:"tag_name"`
+	Name       string `json:"name"`
+	Body       string `json:"body"`
+	Draft      bool   `json:"draft"`
+	Prerelease bool   `json:"prerelease"`
+	MakeLatest string `json:"make_latest,omitempty"`
+	HTMLURL    string `json:"html_url,omitempty"`
+	UploadURL  string `json:"upload_url,omitempty"`
+}
+
+func (r githubRelease) release() *Release {
+	return &Release{
+		ID:         strconv.FormatInt(r.ID, 10),
+		Tag:        r.TagName,
+		Name:       r.Name,
+		Body:       r.Body,
+		Draft:      r.Draft,
+		Prerelease: r.Prerelease,
+		URL:        r.HTMLURL,
+		UploadURL:  strings.SplitN(r.UploadURL, "{", 2)[0],
+	}
+}
+
+// Verify https://docs.github.com/en/rest/repos/repos#get-a-repository
+func (g *github) Verify() error {
+	if g.cfg.Token == "" {
+		return fmt.Errorf("github token is not set, set provider.token or GITHUB_TOKEN")
+	}
+	res := struct {
+		Permissions struct {
+			Push bool `json:"push"`
+		} `json:"permissions"`
+	}{}
+	if err := doJSON(g.client, http.MethodGet, fmt.Sprintf("%s/repos/%s", g.cfg.URL, g.cfg.Repo), g.headers(), nil, &res); err != nil {
+		return err
+	}
+	if !res.Permissions.Push {
+		return fmt.Errorf("github token has no push permission on %s", g.cfg.Repo)
+	}
+	return nil
+}
+
+// CreateRelease https://docs.github.com/en/rest/releases/releases#create-a-release
+// 标签已存在版本发布时（如上次发布中途失败后重新执行）更新该版本发布
+func (g *github) CreateRelease(release *Release) (*Release, error) {
+	req := githubRelease{
+		TagName:    release.Tag,
+		Name:       release.Name,
+		Body:       release.Body,
+		Draft:      release.Draft,
+		Prerelease: release.Prerelease,
+		MakeLatest: strconv.FormatBool(release.Latest),
+	}
+	res := githubRelease{}
+	url := fmt.Sprintf("%s/repos/%s/releases", g.cfg.URL, g.cfg.Repo)
+	err := doJSON(g.client, http.MethodPost, url, g.headers(), req, &res)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnprocessableEntity &&
+		strings.Contains(statusErr.Body, "already_exists") {
+		err = g.updateRelease(req, &res)
+	}
+	if err != nil {
+		return nil, err
+	}
+	created := res.release()
+	created.Latest = release.Latest
+	return created, nil
+}
+
+// updateRelease https://docs.github.com/en/rest/releases/releases#update-a-release
+func (g *github) updateRelease(req githubRelease, res *githubRelease) error {
+	existing := githubRelease{}
+	url := fmt.Sprintf("%s/repos/%s/releases/tags/%s", g.cfg.URL, g.cfg.Repo, req.TagName)
+	if err := doJSON(g.client, http.MethodGet, url, g.headers(), nil, &existing); err != nil {
+		return err
+	}
+	log.Warn("release %s already exists, update it", req.TagName)
+	url = fmt.Sprintf("%s/repos/%s/releases/%d", g.cfg.URL, g.cfg.Repo, existing.ID)
+	return doJSON(g.client, http.MethodPatch, url, g.headers(), req, res)
+}
+
+type githubPull struct {
+	Number  int    `json:"number,omitempty"`
+	Title   string `json:"title"`
+	Body    string `json:"body"`
+	Head    string `json:"head,omitempty"`
+	Base    string `json:"base,omitempty"`
+	HTMLURL string `json:"html_url,omitempty"`
+}
+
+// CreatePullRequest https://docs.github.com/en/rest/pulls/pulls#create-a-pull-request
+func (g *github) CreatePullRequest(pr *PullRequest) (*PullRequest, error) {
+	req := githubPull{Title: pr.Title, Body: pr.Body, Head: pr.Head, Base: pr.Base}
+	res := githubPull{}
+	url := fmt.Sprintf("%s/repos/%s/pulls", g.cfg.URL, g.cfg.Repo)
+	err := doJSON(g.client, http.MethodPost, url, g.headers(), req, &res)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusUnprocessableEntity &&
+		strings.Contains(statusErr.Body, "already exists") {
+		err = g.updatePullRequest(req, &res)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &PullRequest{Number: res.Number, Title: res.Title, Body: res.Body, Head: pr.Head, Base: pr.Base, URL: res.HTMLURL}, nil
+}
+
+// updatePullRequest https://docs.github.com/en/rest/pulls/pulls#update-a-pull-request
+func (g *github) updatePullRequest(req githubPull, res *githubPull) error {
+	owner, _, _ := strings.Cut(g.cfg.Repo, "/")
+	var pulls []githubPull
+	url := fmt.Sprintf("%s/repos/%s/pulls?state=open&head=%s:%s&base=%s", g.cfg.URL, g.cfg.Repo, owner, req.Head, req.Base)
+	if err := doJSON(g.client, http.MethodGet, url, g.headers(), nil, &pulls); err != nil {
+		return err
+	}
+	if len(pulls) == 0 {
+		return fmt.Errorf("pull request from %s already exists but not found", req.Head)
+	}
+	log.Warn("pull request #%d from %s already exists, update it", pulls[0].Number, req.Head)
+	url = fmt.Sprintf("%s/repos/%s/pulls/%d", g.cfg.URL, g.cfg.Repo, pulls[0].Number)
+	return doJSON(g.client, http.MethodPatch, url, g.headers(), githubPull{Title: req.Title, Body: req.Body}, res)
+}
+
+// UploadAsset https://docs.github.com/en/rest/releases/assets#upload-a-release-asset
+func (g *github) UploadAsset(release *Release, name, path string) (string, error) {
+	if release.UploadURL == "" {
+		return "", fmt.Errorf("release %s has no upload url", release.Tag)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	headers := g.headers()
+	headers["Content-Type"] = "application/octet-stream"
+	res := struct {
+		URL string `json:"browser_download_url"`
+	}{}
+	u := release.UploadURL + "?name=" + neturl.QueryEscape(name)
+	if err = doRaw(g.client, http.MethodPost, u, headers, data, &res); err != nil {
+		return "", err
+	}
+	return res.URL, nil
+}
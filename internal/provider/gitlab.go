@@ -0,0 +1,186 @@
+package provider
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+type gitlab struct {
+	cfg    Config
+	client *http.Client
+}
+
+func (g *gitlab) Name() string {
+	return GitLab
+}
+
+func (g *gitlab) headers() map[string]string {
+	headers := map[string]string{}
+	if g.cfg.Token != "" {
+		headers["PRIVATE-TOKEN"] = g.cfg.Token
+	}
+	return headers
+}
+
+func (g *gitlab) projectURL() string {
+	return fmt.Sprintf("%s/projects/%s", g.cfg.URL, url.PathEscape(g.cfg.Repo))
+}
+
+// developerAccess GitLab Developer 角色的访问级别，可以创建标签及版本发布
+const developerAccess = 30
+
+// Verify https://docs.gitlab.com/ee/api/projects.html#get-single-project
+func (g *gitlab) Verify() error {
+	if g.cfg.Token == "" {
+		return fmt.Errorf("gitlab token is not set, set provider.token or GITLAB_TOKEN")
+	}
+	type access struct {
+		AccessLevel int `json:"access_level"`
+	}
+	res := struct {
+		Permissions struct {
+			Project *access `json:"project_access"`
+			Group   *access `json:"group_access"`
+		} `json:"permissions"`
+	}{}
+	if err := doJSON(g.client, http.MethodGet, g.projectURL(), g.headers(), nil, &res); err != nil {
+		return err
+	}
+	level := 0
+	for _, a := range []*access{res.Permissions.Project, res.Permissions.Group} {
+		if a != nil && a.AccessLevel > level {
+			level = a.AccessLevel
+		}
+	}
+	if level < developerAccess {
+		return fmt.Errorf("gitlab token has no developer access on %s", g.cfg.Repo)
+	}
+	return nil
+}
+
+type gitlabRelease struct {
+	TagName     string `json:"tag_name"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Links       struct {
+		Self string `json:"self"`
+	} `json:"_links"`
+}
+
+// CreateRelease https://docs.gitlab.com/ee/api/releases/#create-a-release
+// GitLab 按发布时间自动确定最新版本，不支持 draft、prerelease 及 latest 标记
+// 标签已存在版本发布时更新该版本发布 https://docs.gitlab.com/ee/api/releases/#update-a-release
+func (g *gitlab) CreateRelease(release *Release) (*Release, error) {
+	req := map[string]string{
+		"tag_name":    release.Tag,
+		"name":        release.Name,
+		"description": release.Body,
+	}
+	res := gitlabRelease{}
+	err := doJSON(g.client, http.MethodPost, g.projectURL()+"/releases", g.headers(), req, &res)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusConflict {
+		log.Warn("release %s already exists, update it", release.Tag)
+		delete(req, "tag_name")
+		err = doJSON(g.client, http.MethodPut, g.projectURL()+"/releases/"+url.PathEscape(release.Tag), g.headers(), req, &res)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Release{
+		ID:         res.TagName,
+		Tag:        res.TagName,
+		Name:       res.Name,
+		Body:       res.Description,
+		Prerelease: release.Prerelease,
+		Latest:     release.Latest,
+		URL:        res.Links.Self,
+	}, nil
+}
+
+type gitlabMergeRequest struct {
+	IID          int    `json:"iid,omitempty"`
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	SourceBranch string `json:"source_branch,omitempty"`
+	TargetBranch string `json:"target_branch,omitempty"`
+	RemoveSource bool   `json:"remove_source_branch,omitempty"`
+	WebURL       string `json:"web_url,omitempty"`
+}
+
+// CreatePullRequest https://docs.gitlab.com/ee/api/merge_requests.html#create-mr
+func (g *gitlab) CreatePullRequest(pr *PullRequest) (*PullRequest, error) {
+	req := gitlabMergeRequest{Title: pr.Title, Description: pr.Body, SourceBranch: pr.Head, TargetBranch: pr.Base, RemoveSource: true}
+	res := gitlabMergeRequest{}
+	err := doJSON(g.client, http.MethodPost, g.projectURL()+"/merge_requests", g.headers(), req, &res)
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusConflict {
+		err = g.updateMergeRequest(req, &res)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &PullRequest{Number: res.IID, Title: res.Title, Body: res.Description, Head: pr.Head, Base: pr.Base, URL: res.WebURL}, nil
+}
+
+// updateMergeRequest https://docs.gitlab.com/ee/api/merge_requests.html#update-mr
+func (g *gitlab) updateMergeRequest(req gitlabMergeRequest, res *gitlabMergeRequest) error {
+	var mrs []gitlabMergeRequest
+	query := url.Values{"state": {"opened"}, "source_branch": {req.SourceBranch}, "target_branch": {req.TargetBranch}}
+	if err := doJSON(g.client, http.MethodGet, g.projectURL()+"/merge_requests?"+query.Encode(), g.headers(), nil, &mrs); err != nil {
+		return err
+	}
+	if len(mrs) == 0 {
+		return fmt.Errorf("merge request from %s already exists but not found", req.SourceBranch)
+	}
+	log.Warn("merge request !%d from %s already exists, update it", mrs[0].IID, req.SourceBranch)
+	update := gitlabMergeRequest{Title: req.Title, Description: req.Description}
+	return doJSON(g.client, http.MethodPut, fmt.Sprintf("%s/merge_requests/%d", g.projectURL(), mrs[0].IID), g.headers(), update, res)
+}
+
+// UploadAsset 上传项目文件并添加为版本发布的链接
+// https://docs.gitlab.com/ee/api/projects.html#upload-a-file
+// https://docs.gitlab.com/ee/api/releases/links.html#create-a-release-link
+func (g *gitlab) UploadAsset(release *Release, name, path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	part, err := w.CreateFormFile("file", name)
+	if err != nil {
+		return "", err
+	}
+	if _, err = io.Copy(part, f); err != nil {
+		return "", err
+	}
+	if err = w.Close(); err != nil {
+		return "", err
+	}
+	headers := g.headers()
+	headers["Content-Type"] = w.FormDataContentType()
+	uploaded := struct {
+		FullPath string `json:"full_path"`
+	}{}
+	if err = doRaw(g.client, http.MethodPost, g.projectURL()+"/uploads", headers, body.Bytes(), &uploaded); err != nil {
+		return "", err
+	}
+	link := strings.TrimSuffix(strings.TrimSuffix(g.cfg.URL, "/"), "/api/v4") + uploaded.FullPath
+	req := map[string]string{"name": name, "url": link, "link_type": "other"}
+	u := fmt.Sprintf("%s/releases/%s/assets/links", g.projectURL(), url.PathEscape(release.Tag))
+	if err = doJSON(g.client, http.MethodPost, u, g.headers(), req, nil); err != nil {
+		return "", err
+	}
+	return link, nil
+}
@@ -0,0 +1,70 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// CommitPullRequests 支持查询提交所属合并请求的平台
+type CommitPullRequests interface {
+	// CommitPullRequests 查询引入提交的合并请求，squash 合并产生的提交同样可以查到
+	CommitPullRequests(sha string) ([]*PullRequest, error)
+}
+
+// CommitPullRequests https://docs.github.com/en/rest/commits/commits#list-pull-requests-associated-with-a-commit
+func (g *github) CommitPullRequests(sha string) ([]*PullRequest, error) {
+	var pulls []struct {
+		Number  int    `json:"number"`
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+		HTMLURL string `json:"html_url"`
+		Head    struct {
+			Ref string `json:"ref"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+	}
+	u := fmt.Sprintf("%s/repos/%s/commits/%s/pulls", g.cfg.URL, g.cfg.Repo, sha)
+	if err := doJSON(g.client, http.MethodGet, u, noCache(g.headers()), nil, &pulls); err != nil {
+		return nil, err
+	}
+	res := make([]*PullRequest, 0, len(pulls))
+	for _, p := range pulls {
+		pr := &PullRequest{Number: p.Number, Title: p.Title, Body: p.Body, Head: p.Head.Ref, Base: p.Base.Ref, URL: p.HTMLURL}
+		for _, l := range p.Labels {
+			pr.Labels = append(pr.Labels, l.Name)
+		}
+		res = append(res, pr)
+	}
+	return res, nil
+}
+
+// CommitPullRequests https://docs.gitlab.com/ee/api/commits.html#list-merge-requests-associated-with-a-commit
+func (g *gitlab) CommitPullRequests(sha string) ([]*PullRequest, error) {
+	var mrs []struct {
+		gitlabMergeRequest
+		Labels []string `json:"labels"`
+	}
+	u := fmt.Sprintf("%s/repository/commits/%s/merge_requests", g.projectURL(), url.PathEscape(sha))
+	if err := doJSON(g.client, http.MethodGet, u, noCache(g.headers()), nil, &mrs); err != nil {
+		return nil, err
+	}
+	res := make([]*PullRequest, 0, len(mrs))
+	for _, mr := range mrs {
+		res = append(res, &PullRequest{
+			Number: mr.IID,
+			Title:  mr.Title,
+			Body:   mr.Description,
+			Head:   mr.SourceBranch,
+			Base:   mr.TargetBranch,
+			URL:    mr.WebURL,
+			Labels: mr.Labels,
+		})
+	}
+	return res, nil
+}
@@ -0,0 +1,59 @@
+package provider
+
+import (
+	"strings"
+)
+
+// LinkTemplates 版本比较及源码归档链接模板，模板数据为 .Web（仓库网页地址）、.Repo、.Name（仓库名称）、
+// .Tag、.Previous（上一个版本的标签）及 .Version
+type LinkTemplates struct {
+	Compare  string            // 两个版本的比较链接
+	Archives []ArchiveTemplate // 源码归档链接，按展示顺序排列
+}
+
+// ArchiveTemplate 源码归档链接模板
+type ArchiveTemplate struct {
+	Name string // 展示名称，如 zip、tar.gz
+	URL  string
+}
+
+// DefaultLinkTemplates 平台默认的链接模板，私有化部署的平台同样适用
+func DefaultLinkTemplates(typ string) LinkTemplates {
+	switch strings.ToLower(typ) {
+	case GitLab:
+		return LinkTemplates{
+			Compare: "{{ .Web }}/-/compare/{{ .Previous }}...{{ .Tag }}",
+			Archives: []ArchiveTemplate{
+				{"zip", "{{ .Web }}/-/archive/{{ .Tag }}/{{ .Name }}-{{ .Tag }}.zip"},
+				{"tar.gz", "{{ .Web }}/-/archive/{{ .Tag }}/{{ .Name }}-{{ .Tag }}.tar.gz"},
+			},
+		}
+	default:
+		return LinkTemplates{
+			Compare: "{{ .Web }}/compare/{{ .Previous }}...{{ .Tag }}",
+			Archives: []ArchiveTemplate{
+				{"zip", "{{ .Web }}/archive/refs/tags/{{ .Tag }}.zip"},
+				{"tar.gz", "{{ .Web }}/archive/refs/tags/{{ .Tag }}.tar.gz"},
+			},
+		}
+	}
+}
+
+// WebURL 仓库的网页地址，由 API 地址推导，如 https://api.github.com => https://github.com/org/repo，
+// GitHub Enterprise 的 https://host/api/v3 及 GitLab 的 https://host/api/v4 => https://host/org/repo
+func WebURL(cfg Config) string {
+	base := strings.TrimSuffix(cfg.URL, "/")
+	switch strings.ToLower(cfg.Type) {
+	case GitLab:
+		if base == "" {
+			base = "https://gitlab.com"
+		}
+		base = strings.TrimSuffix(base, "/api/v4")
+	default:
+		if base == "" || base == "https://api.github.com" {
+			base = "https://github.com"
+		}
+		base = strings.TrimSuffix(base, "/api/v3")
+	}
+	return base + "/" + cfg.Repo
+}
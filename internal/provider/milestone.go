@@ -0,0 +1,97 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// Milestone 平台上的里程碑
+type Milestone struct {
+	Number int    `json:"number"`
+	Title  string `json:"title"`
+	State  string `json:"state"` // open | closed
+	URL    string `json:"html_url"`
+}
+
+// MilestoneManager 支持管理里程碑的平台
+type MilestoneManager interface {
+	// FindMilestone 按标题查找里程碑，包括已关闭的里程碑，不存在时返回 nil
+	FindMilestone(title string) (*Milestone, error)
+	// CreateMilestone 创建里程碑
+	CreateMilestone(title string) (*Milestone, error)
+	// CloseMilestone 关闭里程碑
+	CloseMilestone(milestone *Milestone) error
+	// MoveOpenIssues 将里程碑中未关闭的议题及合并请求移到另一个里程碑，返回移动的数量
+	MoveOpenIssues(from, to *Milestone) (int, error)
+}
+
+// githubPageSize GitHub 列表接口的最大分页大小
+const githubPageSize = 100
+
+// FindMilestone https://docs.github.com/en/rest/issues/milestones#list-milestones
+func (g *github) FindMilestone(title string) (*Milestone, error) {
+	for page := 1; ; page++ {
+		var list []*Milestone
+		u := fmt.Sprintf("%s/repos/%s/milestones?state=all&per_page=%d&page=%d", g.cfg.URL, g.cfg.Repo, githubPageSize, page)
+		if err := doJSON(g.client, http.MethodGet, u, noCache(g.headers()), nil, &list); err != nil {
+			return nil, err
+		}
+		for _, m := range list {
+			if m.Title == title {
+				return m, nil
+			}
+		}
+		if len(list) < githubPageSize {
+			return nil, nil
+		}
+	}
+}
+
+// CreateMilestone https://docs.github.com/en/rest/issues/milestones#create-a-milestone
+func (g *github) CreateMilestone(title string) (*Milestone, error) {
+	m := &Milestone{}
+	u := fmt.Sprintf("%s/repos/%s/milestones", g.cfg.URL, g.cfg.Repo)
+	if err := doJSON(g.client, http.MethodPost, u, g.headers(), map[string]string{"title": title}, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// CloseMilestone https://docs.github.com/en/rest/issues/milestones#update-a-milestone
+func (g *github) CloseMilestone(milestone *Milestone) error {
+	u := fmt.Sprintf("%s/repos/%s/milestones/%d", g.cfg.URL, g.cfg.Repo, milestone.Number)
+	if err := doJSON(g.client, http.MethodPatch, u, g.headers(), map[string]string{"state": "closed"}, nil); err != nil {
+		return err
+	}
+	milestone.State = "closed"
+	return nil
+}
+
+// MoveOpenIssues https://docs.github.com/en/rest/issues/issues#list-repository-issues
+// https://docs.github.com/en/rest/issues/issues#update-an-issue
+// 先列出全部议题再逐个更新，避免更新过程中分页偏移
+func (g *github) MoveOpenIssues(from, to *Milestone) (int, error) {
+	var numbers []int
+	for page := 1; ; page++ {
+		var issues []struct {
+			Number int `json:"number"`
+		}
+		u := fmt.Sprintf("%s/repos/%s/issues?milestone=%d&state=open&per_page=%d&page=%d", g.cfg.URL, g.cfg.Repo, from.Number, githubPageSize, page)
+		if err := doJSON(g.client, http.MethodGet, u, noCache(g.headers()), nil, &issues); err != nil {
+			return 0, err
+		}
+		for _, issue := range issues {
+			numbers = append(numbers, issue.Number)
+		}
+		if len(issues) < githubPageSize {
+			break
+		}
+	}
+	for i, n := range numbers {
+		u := fmt.Sprintf("%s/repos/%s/issues/%d", g.cfg.URL, g.cfg.Repo, n)
+		if err := doJSON(g.client, http.MethodPatch, u, g.headers(), map[string]int{"milestone": to.Number}, nil); err != nil {
+			return i, fmt.Errorf("move #%d to milestone %s: %w", n, to.Title, err)
+		}
+	}
+	return len(numbers), nil
+}
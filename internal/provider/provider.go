@@ -0,0 +1,169 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/httpclient"
+)
+
+const (
+	GitHub = "github"
+	GitLab = "gitlab"
+)
+
+// Config 代码托管平台配置，对应配置文件 provider 节点
+type Config struct {
+	Type  string `mapstructure:"type"`  // 平台类型 github | gitlab
+	URL   string `mapstructure:"url"`   // API 地址，私有化部署时需要设置
+	Repo  string `mapstructure:"repo"`  // 仓库 owner/name，GitLab 为项目完整路径
+	Token string `mapstructure:"token"` // 访问令牌，默认读取 GITHUB_TOKEN / GITLAB_TOKEN 环境变量
+
+	HTTP httpclient.Config `mapstructure:"http"` // 代理及 TLS 配置，未设置的字段继承全局 http 节点
+}
+
+// Release 平台上的版本发布
+type Release struct {
+	ID         string    `json:"id,omitempty"`
+	Tag        string    `json:"tag"`
+	Name       string    `json:"name"`
+	Body       string    `json:"body,omitempty"`
+	Draft      bool      `json:"draft,omitempty"`
+	Prerelease bool      `json:"prerelease,omitempty"`
+	Latest     bool      `json:"latest,omitempty"` // 是否标记为最新版本，维护分支的补丁版本不应标记
+	URL        string    `json:"url,omitempty"`
+	UploadURL  string    `json:"-"`                   // 上传附件的地址，仅 GitHub 使用
+	CreatedAt  time.Time `json:"createdAt,omitempty"` // 创建时间，仅查询版本发布时返回
+}
+
+// PullRequest 合并请求，GitLab 中为 Merge Request
+type PullRequest struct {
+	Number int      `json:"number,omitempty"`
+	Title  string   `json:"title"`
+	Body   string   `json:"body,omitempty"`
+	Head   string   `json:"head"` // 源分支
+	Base   string   `json:"base"` // 目标分支
+	URL    string   `json:"url,omitempty"`
+	Labels []string `json:"labels,omitempty"` // 标签，仅查询合并请求时返回
+}
+
+// Provider 代码托管平台
+type Provider interface {
+	// Name 平台名称
+	Name() string
+	// Verify 检查访问令牌有效且有仓库写权限
+	Verify() error
+	// CreateRelease 基于已推送的标签创建版本发布
+	CreateRelease(release *Release) (*Release, error)
+	// UploadAsset 上传文件作为版本发布的附件，返回下载地址
+	UploadAsset(release *Release, name, path string) (string, error)
+	// CreatePullRequest 创建合并请求，源分支已存在打开的合并请求时更新其标题及描述
+	CreatePullRequest(pr *PullRequest) (*PullRequest, error)
+}
+
+// New 根据配置创建平台客户端
+func New(cfg Config) (Provider, error) {
+	if cfg.Repo == "" {
+		return nil, fmt.Errorf("provider repo is required")
+	}
+	client, err := httpclient.New(cfg.HTTP)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(cfg.Type) {
+	case GitHub:
+		if cfg.Token == "" {
+			cfg.Token = os.Getenv("GITHUB_TOKEN")
+		}
+		if cfg.URL == "" {
+			cfg.URL = "https://api.github.com"
+		}
+		return &github{cfg: cfg, client: client}, nil
+	case GitLab:
+		if cfg.Token == "" {
+			cfg.Token = os.Getenv("GITLAB_TOKEN")
+		}
+		if cfg.URL == "" {
+			cfg.URL = "https://gitlab.com/api/v4"
+		}
+		return &gitlab{cfg: cfg, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported provider type %q, valid values are %s, %s", cfg.Type, GitHub, GitLab)
+	}
+}
+
+// StatusError 平台接口返回的非 2xx 响应
+type StatusError struct {
+	Method     string
+	URL        string
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s %s: %d %s", e.Method, e.URL, e.StatusCode, e.Body)
+}
+
+// doJSON 发送 JSON 请求并解析响应
+func doJSON(client *http.Client, method, url string, headers map[string]string, in, out interface{}) error {
+	var data []byte
+	if in != nil {
+		var err error
+		if data, err = json.Marshal(in); err != nil {
+			return err
+		}
+		h := map[string]string{"Content-Type": "application/json"}
+		for k, v := range headers {
+			h[k] = v
+		}
+		headers = h
+	}
+	return doRaw(client, method, url, headers, data, out)
+}
+
+// noCache 追加 Cache-Control: no-cache 请求头，轮询状态等需要最新数据的请求跳过响应缓存
+func noCache(headers map[string]string) map[string]string {
+	h := map[string]string{"Cache-Control": "no-cache"}
+	for k, v := range headers {
+		h[k] = v
+	}
+	return h
+}
+
+// doRaw 发送请求体为 body 的请求并解析 JSON 响应
+func doRaw(client *http.Client, method, url string, headers map[string]string, body []byte, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &StatusError{Method: method, URL: url, StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(data))}
+	}
+	if out != nil && len(data) > 0 {
+		return json.Unmarshal(data, out)
+	}
+	return nil
+}
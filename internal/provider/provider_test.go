@@ -0,0 +1,357 @@
+package provider
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGitHub_CreateRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/coffee377/autoctl/releases" || r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		req := githubRelease{}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.MakeLatest != "false" {
+			t.Errorf("expected make_latest false, but %q got", req.MakeLatest)
+		}
+		req.ID = 1
+		req.HTMLURL = "https://github.com/coffee377/autoctl/releases/tag/" + req.TagName
+		_ = json.NewEncoder(w).Encode(req)
+	}))
+	defer server.Close()
+
+	p, err := New(Config{Type: GitHub, URL: server.URL, Repo: "coffee377/autoctl", Token: "token"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	release, err := p.CreateRelease(&Release{Tag: "v1.2.1", Name: "v1.2.1", Latest: false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.ID != "1" || release.URL != "https://github.com/coffee377/autoctl/releases/tag/v1.2.1" {
+		t.Errorf("unexpected release %+v", release)
+	}
+}
+
+func TestGitLab_CreateRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawPath != "/projects/group%2Fproject/releases" || r.Header.Get("PRIVATE-TOKEN") != "token" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"tag_name":"v1.0.0","name":"v1.0.0","description":"notes","_links":{"self":"https://gitlab.com/group/project/-/releases/v1.0.0"}}`))
+	}))
+	defer server.Close()
+
+	p, _ := New(Config{Type: GitLab, URL: server.URL, Repo: "group/project", Token: "token"})
+	release, err := p.CreateRelease(&Release{Tag: "v1.0.0", Name: "v1.0.0", Body: "notes"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.URL != "https://gitlab.com/group/project/-/releases/v1.0.0" {
+		t.Errorf("unexpected release %+v", release)
+	}
+}
+
+func TestNew_Unsupported(t *testing.T) {
+	if _, err := New(Config{Type: "svn", Repo: "a/b"}); err == nil {
+		t.Error("expected unsupported provider error, got nil")
+	}
+}
+
+func TestGitHub_CreateRelease_AlreadyExists(t *testing.T) {
+	var methods []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message":"Validation Failed","errors":[{"resource":"Release","code":"already_exists","field":"tag_name"}]}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/a/b/releases/tags/v1.0.0":
+			_, _ = w.Write([]byte(`{"id":7,"tag_name":"v1.0.0"}`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/a/b/releases/7":
+			_, _ = w.Write([]byte(`{"id":7,"tag_name":"v1.0.0","body":"updated"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, _ := New(Config{Type: GitHub, URL: server.URL, Repo: "a/b"})
+	release, err := p.CreateRelease(&Release{Tag: "v1.0.0", Body: "updated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.ID != "7" || len(methods) != 3 {
+		t.Errorf("expected release 7 updated, but %+v with requests %v got", release, methods)
+	}
+}
+
+func TestGitLab_CreateRelease_AlreadyExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			w.WriteHeader(http.StatusConflict)
+		case http.MethodPut:
+			_, _ = w.Write([]byte(`{"tag_name":"v1.0.0","description":"updated"}`))
+		}
+	}))
+	defer server.Close()
+
+	p, _ := New(Config{Type: GitLab, URL: server.URL, Repo: "group/project"})
+	release, err := p.CreateRelease(&Release{Tag: "v1.0.0", Body: "updated"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if release.Body != "updated" {
+		t.Errorf("unexpected release %+v", release)
+	}
+}
+
+func TestGitHub_CreatePullRequest_AlreadyExists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/org/deploy/pulls":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			_, _ = w.Write([]byte(`{"message":"Validation Failed","errors":[{"message":"A pull request already exists for org:autoctl/release-v1.2.0."}]}`))
+		case r.Method == http.MethodGet && r.URL.Query().Get("head") == "org:autoctl/release-v1.2.0":
+			_, _ = w.Write([]byte(`[{"number":12}]`))
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/org/deploy/pulls/12":
+			_, _ = w.Write([]byte(`{"number":12,"title":"deploy v1.2.0","html_url":"https://github.com/org/deploy/pull/12"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, _ := New(Config{Type: GitHub, URL: server.URL, Repo: "org/deploy"})
+	pr, err := p.CreatePullRequest(&PullRequest{Title: "deploy v1.2.0", Head: "autoctl/release-v1.2.0", Base: "main"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.Number != 12 || pr.URL != "https://github.com/org/deploy/pull/12" {
+		t.Errorf("unexpected pull request %+v", pr)
+	}
+}
+
+func TestGitHub_UploadAsset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.URL.Path != "/repos/a/b/releases/1/assets" || r.URL.Query().Get("name") != "sbom.cdx.json" || string(body) != "{}" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(`{"browser_download_url":"https://github.com/a/b/releases/download/v1.0.0/sbom.cdx.json"}`))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "sbom.cdx.json")
+	_ = os.WriteFile(path, []byte("{}"), 0o644)
+	p, _ := New(Config{Type: GitHub, URL: server.URL, Repo: "a/b"})
+	u, err := p.UploadAsset(&Release{Tag: "v1.0.0", UploadURL: server.URL + "/repos/a/b/releases/1/assets"}, "sbom.cdx.json", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if u != "https://github.com/a/b/releases/download/v1.0.0/sbom.cdx.json" {
+		t.Errorf("unexpected download url %s", u)
+	}
+}
+
+func TestGitHub_Verify(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"permissions":{"admin":false,"push":true,"pull":true}}`))
+	}))
+	defer server.Close()
+
+	p, _ := New(Config{Type: GitHub, URL: server.URL, Repo: "a/b", Token: "token"})
+	if err := p.Verify(); err != nil {
+		t.Errorf("expected valid token, but %v got", err)
+	}
+	p, _ = New(Config{Type: GitHub, URL: server.URL, Repo: "a/b", Token: "expired"})
+	if err := p.Verify(); err == nil {
+		t.Error("expected invalid token error, got nil")
+	}
+}
+
+func TestGitHub_DeleteRelease(t *testing.T) {
+	var deleted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/coffee377/autoctl/releases/tags/v1.0.1-nightly.20240521":
+			_, _ = w.Write([]byte(`{"id":42,"tag_name":"v1.0.1-nightly.20240521"}`))
+		case r.Method == http.MethodDelete:
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, _ := New(Config{Type: GitHub, URL: server.URL, Repo: "coffee377/autoctl", Token: "token"})
+	m := p.(ReleaseManager)
+	if err := m.DeleteRelease(&Release{Tag: "v1.0.1-nightly.20240521"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.DeleteRelease(&Release{Tag: "v1.0.1-nightly.20240520"}); err != nil {
+		t.Errorf("expected missing release ignored, but %v got", err)
+	}
+	if len(deleted) != 1 || deleted[0] != "/repos/coffee377/autoctl/releases/42" {
+		t.Errorf("unexpected deleted releases %v", deleted)
+	}
+}
+
+func TestGitLab_SetCommitStatus(t *testing.T) {
+	var got map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.RawPath != "/projects/group%2Fproject/statuses/abc123" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&got)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	p, _ := New(Config{Type: GitLab, URL: server.URL, Repo: "group/project"})
+	status := &CommitStatus{SHA: "abc123", State: StateFailure, Context: "autoctl/version", Description: strings.Repeat("x", 200)}
+	if err := p.(StatusReporter).SetCommitStatus(status); err != nil {
+		t.Fatal(err)
+	}
+	if got["state"] != "failed" || got["name"] != "autoctl/version" || len([]rune(got["description"])) != maxStatusDescription {
+		t.Errorf("unexpected status %v", got)
+	}
+}
+
+func TestGitHub_CommitPullRequests(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/a/b/commits/abc123/pulls" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"number":5,"title":"feat: export","head":{"ref":"export"},"base":{"ref":"main"},"labels":[{"name":"release:major"}]}]`))
+	}))
+	defer server.Close()
+
+	p, _ := New(Config{Type: GitHub, URL: server.URL, Repo: "a/b"})
+	pulls, err := p.(CommitPullRequests).CommitPullRequests("abc123")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pulls) != 1 || pulls[0].Number != 5 || pulls[0].Head != "export" || len(pulls[0].Labels) != 1 || pulls[0].Labels[0] != "release:major" {
+		t.Errorf("unexpected pull requests %+v", pulls)
+	}
+}
+
+func TestGitHub_UpsertComment(t *testing.T) {
+	body := "old <!-- marker -->"
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/a/b/issues/7/comments":
+			data, _ := json.Marshal([]map[string]interface{}{{"id": 1, "body": "lgtm"}, {"id": 2, "body": body}})
+			_, _ = w.Write(data)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/a/b/issues/comments/2":
+			in := map[string]string{}
+			_ = json.NewDecoder(r.Body).Decode(&in)
+			body = in["body"]
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, _ := New(Config{Type: GitHub, URL: server.URL, Repo: "a/b"})
+	comment := &Comment{Number: 7, PullRequest: true, Body: "new <!-- marker -->"}
+	changed, err := p.(StickyCommenter).UpsertComment(comment, "<!-- marker -->")
+	if err != nil || !changed || body != comment.Body {
+		t.Fatalf("expected comment 2 updated, but %v %v %q got", changed, err, body)
+	}
+	requests = nil
+	if changed, err = p.(StickyCommenter).UpsertComment(comment, "<!-- marker -->"); err != nil || changed {
+		t.Errorf("expected unchanged comment not updated, but %v %v got", changed, err)
+	}
+	if len(requests) != 1 {
+		t.Errorf("expected only the list request, but %v got", requests)
+	}
+}
+
+func TestGitHub_LatestDeployment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/a/b/deployments":
+			if r.URL.Query().Get("environment") != "prod" {
+				t.Errorf("unexpected environment %s", r.URL.Query().Get("environment"))
+			}
+			_, _ = w.Write([]byte(`[{"id":2,"ref":"v1.3.0","environment":"prod"},{"id":1,"ref":"v1.2.0","environment":"prod","creator":{"login":"alice"}}]`))
+		case "/repos/a/b/deployments/2/statuses":
+			_, _ = w.Write([]byte(`[{"state":"failure"}]`))
+		case "/repos/a/b/deployments/1/statuses":
+			_, _ = w.Write([]byte(`[{"state":"success"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, _ := New(Config{Type: GitHub, URL: server.URL, Repo: "a/b"})
+	d, err := p.(DeploymentTracker).LatestDeployment("prod")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d == nil || d.Ref != "v1.2.0" || d.Creator != "alice" {
+		t.Errorf("expected the latest successful deployment v1.2.0, but %+v got", d)
+	}
+}
+
+func TestWebURL(t *testing.T) {
+	tests := map[string]Config{
+		"https://github.com/a/b":             {Type: GitHub, URL: "https://api.github.com", Repo: "a/b"},
+		"https://ghe.example.com/a/b":        {Type: GitHub, URL: "https://ghe.example.com/api/v3", Repo: "a/b"},
+		"https://gitlab.com/group/sub/proj":  {Type: GitLab, Repo: "group/sub/proj"},
+		"https://git.example.com/group/proj": {Type: GitLab, URL: "https://git.example.com/api/v4/", Repo: "group/proj"},
+	}
+	for want, cfg := range tests {
+		if got := WebURL(cfg); got != want {
+			t.Errorf("WebURL(%+v) = %s, want %s", cfg, got, want)
+		}
+	}
+}
+
+func TestTokenScopes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/rate_limit":
+			w.Header().Set("X-OAuth-Scopes", "repo, workflow")
+			_, _ = w.Write([]byte(`{}`))
+		case "/personal_access_tokens/self":
+			_, _ = w.Write([]byte(`{"scopes":["api","write_repository"]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	p, _ := New(Config{Type: GitHub, URL: server.URL, Repo: "a/b", Token: "token"})
+	if scopes, err := p.(TokenScopes).Scopes(); err != nil || strings.Join(scopes, ",") != "repo,workflow" {
+		t.Errorf("expected github scopes repo,workflow, but %v %v got", scopes, err)
+	}
+	p, _ = New(Config{Type: GitLab, URL: server.URL, Repo: "group/project", Token: "token"})
+	if scopes, err := p.(TokenScopes).Scopes(); err != nil || strings.Join(scopes, ",") != "api,write_repository" {
+		t.Errorf("expected gitlab scopes api,write_repository, but %v %v got", scopes, err)
+	}
+}
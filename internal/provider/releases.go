@@ -0,0 +1,159 @@
+package provider
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// pageSize 分页查询时每页的数量
+const pageSize = 100
+
+// ReleaseManager 支持查询及删除版本发布的平台
+type ReleaseManager interface {
+	// ListReleases 列出所有版本发布，包括草稿
+	ListReleases() ([]*Release, error)
+	// GetRelease 查询标签对应的版本发布
+	GetRelease(tag string) (*Release, error)
+	// UpdateReleaseBody 更新版本发布的说明
+	UpdateReleaseBody(release *Release, body string) error
+	// UpdateReleaseName 更新版本发布的标题
+	UpdateReleaseName(release *Release, name string) error
+	// DeleteRelease 删除版本发布，不删除标签本身；未设置 ID 时按标签查找，版本发布不存在时返回 nil
+	DeleteRelease(release *Release) error
+}
+
+// notFound 是否为 404 响应
+func notFound(err error) bool {
+	var statusErr *StatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode == http.StatusNotFound
+}
+
+// ListReleases https://docs.github.com/en/rest/releases/releases#list-releases
+func (g *github) ListReleases() ([]*Release, error) {
+	var list []*Release
+	for page := 1; ; page++ {
+		var res []struct {
+			githubRelease
+			CreatedAt time.Time `json:"created_at"`
+		}
+		u := fmt.Sprintf("%s/repos/%s/releases?per_page=%d&page=%d", g.cfg.URL, g.cfg.Repo, pageSize, page)
+		if err := doJSON(g.client, http.MethodGet, u, noCache(g.headers()), nil, &res); err != nil {
+			return nil, err
+		}
+		for _, r := range res {
+			release := r.release()
+			release.CreatedAt = r.CreatedAt
+			list = append(list, release)
+		}
+		if len(res) < pageSize {
+			return list, nil
+		}
+	}
+}
+
+// GetRelease https://docs.github.com/en/rest/releases/releases#get-a-release-by-tag-name
+func (g *github) GetRelease(tag string) (*Release, error) {
+	res := githubRelease{}
+	u := fmt.Sprintf("%s/repos/%s/releases/tags/%s", g.cfg.URL, g.cfg.Repo, url.PathEscape(tag))
+	if err := doJSON(g.client, http.MethodGet, u, noCache(g.headers()), nil, &res); err != nil {
+		return nil, err
+	}
+	return res.release(), nil
+}
+
+// UpdateReleaseBody https://docs.github.com/en/rest/releases/releases#update-a-release
+func (g *github) UpdateReleaseBody(release *Release, body string) error {
+	u := fmt.Sprintf("%s/repos/%s/releases/%s", g.cfg.URL, g.cfg.Repo, release.ID)
+	return doJSON(g.client, http.MethodPatch, u, g.headers(), map[string]string{"body": body}, nil)
+}
+
+// UpdateReleaseName https://docs.github.com/en/rest/releases/releases#update-a-release
+func (g *github) UpdateReleaseName(release *Release, name string) error {
+	u := fmt.Sprintf("%s/repos/%s/releases/%s", g.cfg.URL, g.cfg.Repo, release.ID)
+	return doJSON(g.client, http.MethodPatch, u, g.headers(), map[string]string{"name": name}, nil)
+}
+
+// DeleteRelease https://docs.github.com/en/rest/releases/releases#delete-a-release
+func (g *github) DeleteRelease(release *Release) error {
+	id := release.ID
+	if id == "" {
+		existing := githubRelease{}
+		u := fmt.Sprintf("%s/repos/%s/releases/tags/%s", g.cfg.URL, g.cfg.Repo, url.PathEscape(release.Tag))
+		if err := doJSON(g.client, http.MethodGet, u, g.headers(), nil, &existing); err != nil {
+			if notFound(err) {
+				return nil
+			}
+			return err
+		}
+		id = fmt.Sprint(existing.ID)
+	}
+	err := doJSON(g.client, http.MethodDelete, fmt.Sprintf("%s/repos/%s/releases/%s", g.cfg.URL, g.cfg.Repo, id), g.headers(), nil, nil)
+	if notFound(err) {
+		return nil
+	}
+	return err
+}
+
+// ListReleases https://docs.gitlab.com/ee/api/releases/#list-releases
+// GitLab 没有草稿，发布时间晚于当前时间的即将发布版本视为草稿
+func (g *gitlab) ListReleases() ([]*Release, error) {
+	var list []*Release
+	for page := 1; ; page++ {
+		var res []struct {
+			gitlabRelease
+			CreatedAt       time.Time `json:"created_at"`
+			UpcomingRelease bool      `json:"upcoming_release"`
+		}
+		u := fmt.Sprintf("%s/releases?per_page=%d&page=%d", g.projectURL(), pageSize, page)
+		if err := doJSON(g.client, http.MethodGet, u, noCache(g.headers()), nil, &res); err != nil {
+			return nil, err
+		}
+		for _, r := range res {
+			list = append(list, &Release{
+				ID:        r.TagName,
+				Tag:       r.TagName,
+				Name:      r.Name,
+				Body:      r.Description,
+				Draft:     r.UpcomingRelease,
+				URL:       r.Links.Self,
+				CreatedAt: r.CreatedAt,
+			})
+		}
+		if len(res) < pageSize {
+			return list, nil
+		}
+	}
+}
+
+// GetRelease https://docs.gitlab.com/ee/api/releases/#get-a-release-by-a-tag-name
+func (g *gitlab) GetRelease(tag string) (*Release, error) {
+	res := gitlabRelease{}
+	if err := doJSON(g.client, http.MethodGet, g.projectURL()+"/releases/"+url.PathEscape(tag), noCache(g.headers()), nil, &res); err != nil {
+		return nil, err
+	}
+	return &Release{ID: res.TagName, Tag: res.TagName, Name: res.Name, Body: res.Description, URL: res.Links.Self}, nil
+}
+
+// UpdateReleaseBody https://docs.gitlab.com/ee/api/releases/#update-a-release
+func (g *gitlab) UpdateReleaseBody(release *Release, body string) error {
+	u := g.projectURL() + "/releases/" + url.PathEscape(release.Tag)
+	return doJSON(g.client, http.MethodPut, u, g.headers(), map[string]string{"description": body}, nil)
+}
+
+// UpdateReleaseName https://docs.gitlab.com/ee/api/releases/#update-a-release
+func (g *gitlab) UpdateReleaseName(release *Release, name string) error {
+	u := g.projectURL() + "/releases/" + url.PathEscape(release.Tag)
+	return doJSON(g.client, http.MethodPut, u, g.headers(), map[string]string{"name": name}, nil)
+}
+
+// DeleteRelease https://docs.gitlab.com/ee/api/releases/#delete-a-release
+func (g *gitlab) DeleteRelease(release *Release) error {
+	err := doJSON(g.client, http.MethodDelete, g.projectURL()+"/releases/"+url.PathEscape(release.Tag), g.headers(), nil, nil)
+	if notFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"net/http"
+	"strings"
+)
+
+// TokenScopes 支持查询访问令牌权限范围的平台
+type TokenScopes interface {
+	// Scopes 访问令牌的权限范围，GitHub fine-grained 令牌及 GITHUB_TOKEN 没有权限范围，返回空列表
+	Scopes() ([]string, error)
+}
+
+// Scopes 读取任意接口响应的 X-OAuth-Scopes 头，/rate_limit 不消耗请求配额
+func (g *github) Scopes() ([]string, error) {
+	url := g.cfg.URL + "/rate_limit"
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range g.headers() {
+		req.Header.Set(k, v)
+	}
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, &StatusError{Method: http.MethodGet, URL: url, StatusCode: resp.StatusCode, Body: resp.Status}
+	}
+	scopes := make([]string, 0)
+	for _, scope := range strings.Split(resp.Header.Get("X-OAuth-Scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes, nil
+}
+
+// Scopes https://docs.gitlab.com/ee/api/personal_access_tokens.html#using-a-request-header
+func (g *gitlab) Scopes() ([]string, error) {
+	res := struct {
+		Scopes []string `json:"scopes"`
+	}{}
+	if err := doJSON(g.client, http.MethodGet, g.cfg.URL+"/personal_access_tokens/self", g.headers(), nil, &res); err != nil {
+		return nil, err
+	}
+	return res.Scopes, nil
+}
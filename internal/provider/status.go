@@ -0,0 +1,72 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+)
+
+// 提交状态
+const (
+	StatePending = "pending"
+	StateSuccess = "success"
+	StateFailure = "failure"
+	StateError   = "error"
+)
+
+// maxStatusDescription GitHub 提交状态描述的最大长度
+const maxStatusDescription = 140
+
+// CommitStatus 提交上的状态，同一提交上 Context 相同的状态后写入的覆盖先写入的
+type CommitStatus struct {
+	SHA         string // 提交哈希
+	State       string // 状态 pending | success | failure | error
+	Context     string // 状态名称，如 autoctl/version
+	Description string // 简短描述，超过 140 个字符时截断
+	TargetURL   string // 点击状态时打开的地址，如 CI 任务地址
+}
+
+// StatusReporter 支持在提交上设置状态的平台，合并请求页面会展示源分支最新提交的状态
+type StatusReporter interface {
+	// SetCommitStatus 设置提交状态
+	SetCommitStatus(status *CommitStatus) error
+}
+
+func (s *CommitStatus) description() string {
+	runes := []rune(s.Description)
+	if len(runes) <= maxStatusDescription {
+		return s.Description
+	}
+	return string(runes[:maxStatusDescription-1]) + "…"
+}
+
+// SetCommitStatus https://docs.github.com/en/rest/commits/statuses#create-a-commit-status
+func (g *github) SetCommitStatus(status *CommitStatus) error {
+	url := fmt.Sprintf("%s/repos/%s/statuses/%s", g.cfg.URL, g.cfg.Repo, status.SHA)
+	body := map[string]string{
+		"state":       status.State,
+		"context":     status.Context,
+		"description": status.description(),
+	}
+	if status.TargetURL != "" {
+		body["target_url"] = status.TargetURL
+	}
+	return doJSON(g.client, http.MethodPost, url, g.headers(), body, nil)
+}
+
+// SetCommitStatus https://docs.gitlab.com/ee/api/commits.html#set-the-pipeline-status-of-a-commit
+// GitLab 的失败状态为 failed，error 同样映射为 failed
+func (g *gitlab) SetCommitStatus(status *CommitStatus) error {
+	state := status.State
+	if state == StateFailure || state == StateError {
+		state = "failed"
+	}
+	body := map[string]string{
+		"state":       state,
+		"name":        status.Context,
+		"description": status.description(),
+	}
+	if status.TargetURL != "" {
+		body["target_url"] = status.TargetURL
+	}
+	return doJSON(g.client, http.MethodPost, fmt.Sprintf("%s/statuses/%s", g.projectURL(), status.SHA), g.headers(), body, nil)
+}
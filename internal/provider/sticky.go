@@ -0,0 +1,77 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// StickyCommenter 支持更新已有评论的平台，用于在合并请求上维护一条持续更新的评论，而不是每次推送都新增一条
+type StickyCommenter interface {
+	// UpsertComment 查找内容包含 marker 的评论并更新为 comment.Body，不存在时发表新评论；
+	// 内容未变化时不发送更新请求，changed 为 false
+	UpsertComment(comment *Comment, marker string) (changed bool, err error)
+}
+
+// UpsertComment https://docs.github.com/en/rest/issues/comments#update-an-issue-comment
+func (g *github) UpsertComment(comment *Comment, marker string) (bool, error) {
+	const pageSize = 100
+	for page := 1; ; page++ {
+		var comments []struct {
+			ID   int64  `json:"id"`
+			Body string `json:"body"`
+		}
+		u := fmt.Sprintf("%s/repos/%s/issues/%d/comments?per_page=%d&page=%d", g.cfg.URL, g.cfg.Repo, comment.Number, pageSize, page)
+		if err := doJSON(g.client, http.MethodGet, u, noCache(g.headers()), nil, &comments); err != nil {
+			return false, err
+		}
+		for _, c := range comments {
+			if !strings.Contains(c.Body, marker) {
+				continue
+			}
+			if c.Body == comment.Body {
+				return false, nil
+			}
+			u = fmt.Sprintf("%s/repos/%s/issues/comments/%d", g.cfg.URL, g.cfg.Repo, c.ID)
+			return true, doJSON(g.client, http.MethodPatch, u, g.headers(), map[string]string{"body": comment.Body}, nil)
+		}
+		if len(comments) < pageSize {
+			break
+		}
+	}
+	return true, g.CreateComment(comment)
+}
+
+// UpsertComment https://docs.gitlab.com/ee/api/notes.html#modify-existing-merge-request-note
+func (g *gitlab) UpsertComment(comment *Comment, marker string) (bool, error) {
+	const pageSize = 100
+	kind := "issues"
+	if comment.PullRequest {
+		kind = "merge_requests"
+	}
+	notes := fmt.Sprintf("%s/%s/%d/notes", g.projectURL(), kind, comment.Number)
+	for page := 1; ; page++ {
+		var res []struct {
+			ID     int64  `json:"id"`
+			Body   string `json:"body"`
+			System bool   `json:"system"`
+		}
+		u := fmt.Sprintf("%s?per_page=%d&page=%d", notes, pageSize, page)
+		if err := doJSON(g.client, http.MethodGet, u, noCache(g.headers()), nil, &res); err != nil {
+			return false, err
+		}
+		for _, n := range res {
+			if n.System || !strings.Contains(n.Body, marker) {
+				continue
+			}
+			if n.Body == comment.Body {
+				return false, nil
+			}
+			return true, doJSON(g.client, http.MethodPut, fmt.Sprintf("%s/%d", notes, n.ID), g.headers(), map[string]string{"body": comment.Body}, nil)
+		}
+		if len(res) < pageSize {
+			break
+		}
+	}
+	return true, g.CreateComment(comment)
+}
@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Variables 支持读写 CI/CD 变量的平台
+type Variables interface {
+	// Variable 读取变量，变量不存在时返回 false
+	Variable(name string) (string, bool, error)
+	// SetVariable 更新变量，变量不存在时创建
+	SetVariable(name, value string) error
+}
+
+// Variable https://docs.github.com/en/rest/actions/variables#get-a-repository-variable
+func (g *github) Variable(name string) (string, bool, error) {
+	out := struct {
+		Value string `json:"value"`
+	}{}
+	u := fmt.Sprintf("%s/repos/%s/actions/variables/%s", g.cfg.URL, g.cfg.Repo, url.PathEscape(name))
+	if err := doJSON(g.client, http.MethodGet, u, noCache(g.headers()), nil, &out); err != nil {
+		if notFound(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return out.Value, true, nil
+}
+
+// SetVariable https://docs.github.com/en/rest/actions/variables#update-a-repository-variable
+func (g *github) SetVariable(name, value string) error {
+	u := fmt.Sprintf("%s/repos/%s/actions/variables", g.cfg.URL, g.cfg.Repo)
+	body := map[string]string{"name": name, "value": value}
+	err := doJSON(g.client, http.MethodPatch, u+"/"+url.PathEscape(name), g.headers(), body, nil)
+	if notFound(err) {
+		return doJSON(g.client, http.MethodPost, u, g.headers(), body, nil)
+	}
+	return err
+}
+
+// Variable https://docs.gitlab.com/ee/api/project_level_variables.html#get-a-single-variable
+func (g *gitlab) Variable(name string) (string, bool, error) {
+	out := struct {
+		Value string `json:"value"`
+	}{}
+	u := fmt.Sprintf("%s/variables/%s", g.projectURL(), url.PathEscape(name))
+	if err := doJSON(g.client, http.MethodGet, u, noCache(g.headers()), nil, &out); err != nil {
+		if notFound(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	return out.Value, true, nil
+}
+
+// SetVariable https://docs.gitlab.com/ee/api/project_level_variables.html#update-a-variable
+func (g *gitlab) SetVariable(name, value string) error {
+	u := fmt.Sprintf("%s/variables", g.projectURL())
+	err := doJSON(g.client, http.MethodPut, u+"/"+url.PathEscape(name), g.headers(), map[string]string{"value": value}, nil)
+	if notFound(err) {
+		return doJSON(g.client, http.MethodPost, u, g.headers(), map[string]string{"key": name, "value": value}, nil)
+	}
+	return err
+}
@@ -0,0 +1,286 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// 镜像仓库类型，标签列表、存在性检查及复制均使用 Registry V2 接口，认证及删除标签的方式因类型而异
+const (
+	TypeGeneric   = "generic"   // Docker Registry HTTP API V2，删除标签时删除其指向的清单
+	TypeDockerHub = "dockerhub" // Docker Hub
+	TypeHarbor    = "harbor"    // Harbor，通常使用机器人账号（robot$project+name）认证
+	TypeECR       = "ecr"       // Amazon ECR，通过 IAM 身份执行 aws ecr get-login-password 认证
+	TypeGCR       = "gcr"       // Google Container Registry 及 Artifact Registry，使用服务账号密钥或 gcloud 访问令牌认证
+	TypeACR       = "acr"       // Azure Container Registry，使用服务主体或 az acr login 访问令牌认证
+)
+
+// DockerHubAPI Docker Hub 管理接口地址
+var DockerHubAPI = "https://hub.docker.com"
+
+// adapter 镜像仓库特有的认证及删除标签方式
+type adapter interface {
+	// login 通过平台身份获取认证信息，ok 为 false 时匿名访问
+	login(host string) (a auth, ok bool, err error)
+	// deleteTag 删除标签
+	deleteTag(c *Client, ref Reference) error
+}
+
+var adapters = map[string]adapter{
+	TypeGeneric:   generic{},
+	TypeDockerHub: dockerHub{},
+	TypeHarbor:    harbor{},
+	TypeECR:       ecr{},
+	TypeGCR:       gcr{},
+	TypeACR:       acr{},
+}
+
+// Type 镜像仓库类型，优先使用 registry.credentials 中配置的类型，否则按仓库地址识别
+func (c *Client) Type(host string) string {
+	for _, cred := range c.cfg.Credentials {
+		if cred.Type != "" && normalizeHost(cred.Registry) == host {
+			return cred.Type
+		}
+	}
+	switch {
+	case host == DockerHub:
+		return TypeDockerHub
+	case ecrReg.MatchString(host):
+		return TypeECR
+	case host == "gcr.io" || strings.HasSuffix(host, ".gcr.io") || strings.HasSuffix(host, "-docker.pkg.dev"):
+		return TypeGCR
+	case strings.HasSuffix(host, ".azurecr.io") || strings.HasSuffix(host, ".azurecr.cn"):
+		return TypeACR
+	default:
+		return TypeGeneric
+	}
+}
+
+func (c *Client) adapter(host string) adapter {
+	return adapters[c.Type(host)]
+}
+
+// DeleteTag 删除镜像标签：Docker Hub、Harbor、ECR、GCR 及 ACR 只删除标签，
+// 通用仓库删除标签指向的清单，同一清单的其它标签一并删除
+func (c *Client) DeleteTag(ref Reference) error {
+	if ref.Tag == "" {
+		return fmt.Errorf("image %s has no tag", ref)
+	}
+	if err := c.adapter(ref.Registry).deleteTag(c, ref); err != nil {
+		return fmt.Errorf("delete %s: %w", ref, err)
+	}
+	return nil
+}
+
+// untags 删除标签时是否只删除标签本身
+func (c *Client) untags(host string) bool {
+	return c.Type(host) != TypeGeneric
+}
+
+func deleteScope(ref Reference) string {
+	return fmt.Sprintf("repository:%s:delete", ref.Repository)
+}
+
+// generic Registry V2 只能按摘要删除清单
+type generic struct{}
+
+func (generic) login(string) (auth, bool, error) {
+	return auth{}, false, nil
+}
+
+func (generic) deleteTag(c *Client, ref Reference) error {
+	digest, err := c.Digest(ref)
+	if err != nil {
+		return err
+	}
+	return c.deleteManifest(ref, digest, http.StatusAccepted)
+}
+
+// deleteManifest 删除清单，reference 为摘要或支持按标签删除的仓库中的标签
+func (c *Client) deleteManifest(ref Reference, reference string, expected int) error {
+	u := fmt.Sprintf("%s/manifests/%s", c.baseURL(ref), reference)
+	resp, err := c.do(ref, deleteScope(ref), func() (*http.Request, error) {
+		return http.NewRequest(http.MethodDelete, u, nil)
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkStatus(resp, expected)
+}
+
+// dockerHub 通过 Docker Hub 管理接口删除标签，需要用户名及密码或个人访问令牌
+type dockerHub struct{}
+
+func (dockerHub) login(string) (auth, bool, error) {
+	return auth{}, false, nil
+}
+
+func (dockerHub) deleteTag(c *Client, ref Reference) error {
+	a, err := c.credential(ref.Registry)
+	if err != nil {
+		return err
+	}
+	if a.username == "" {
+		return fmt.Errorf("docker hub requires username and password to delete tags")
+	}
+	body, _ := json.Marshal(map[string]string{"username": a.username, "password": a.password})
+	resp, err := c.client.Post(DockerHubAPI+"/v2/users/login", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err = checkStatus(resp, http.StatusOK); err != nil {
+		return err
+	}
+	res := struct {
+		Token string `json:"token"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/v2/repositories/%s/tags/%s/", DockerHubAPI, ref.Repository, ref.Tag), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+res.Token)
+	del, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer del.Body.Close()
+	return checkStatus(del, http.StatusNoContent)
+}
+
+// harbor 通过 Harbor API v2.0 删除标签 https://goharbor.io/docs/main/build-customize-contribute/configure-swagger/
+type harbor struct{}
+
+func (harbor) login(string) (auth, bool, error) {
+	return auth{}, false, nil
+}
+
+func (harbor) deleteTag(c *Client, ref Reference) error {
+	project, repo, ok := strings.Cut(ref.Repository, "/")
+	if !ok {
+		return fmt.Errorf("harbor repository %s has no project", ref.Repository)
+	}
+	scheme := "https"
+	if c.cfg.PlainHTTP {
+		scheme = "http"
+	}
+	// 仓库名称中的 / 需要编码两次
+	u := fmt.Sprintf("%s://%s/api/v2.0/projects/%s/repositories/%s/artifacts/%s/tags/%s", scheme, ref.endpoint(),
+		url.PathEscape(project), url.PathEscape(url.PathEscape(repo)), url.PathEscape(ref.Tag), url.PathEscape(ref.Tag))
+	req, err := http.NewRequest(http.MethodDelete, u, nil)
+	if err != nil {
+		return err
+	}
+	if err = c.authorize(req, ref.Registry); err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkStatus(resp, http.StatusOK)
+}
+
+// ecr 使用 IAM 身份（环境变量、配置文件或实例角色）访问 ECR
+type ecr struct{}
+
+func (ecr) login(host string) (auth, bool, error) {
+	m := ecrReg.FindStringSubmatch(host)
+	if m == nil {
+		return auth{}, false, nil
+	}
+	if _, err := exec.LookPath("aws"); err != nil {
+		return auth{}, false, nil
+	}
+	password, err := runCommand("aws", []string{"ecr", "get-login-password", "--region", m[1]}, "")
+	return auth{"AWS", password}, err == nil, err
+}
+
+func (ecr) deleteTag(_ *Client, ref Reference) error {
+	args := []string{"ecr", "batch-delete-image", "--repository-name", ref.Repository, "--image-ids", "imageTag=" + ref.Tag}
+	if m := ecrReg.FindStringSubmatch(ref.Registry); m != nil {
+		args = append(args, "--registry-id", strings.SplitN(ref.Registry, ".", 2)[0], "--region", m[1])
+	}
+	output, err := runCommand("aws", args, "")
+	if err != nil {
+		return err
+	}
+	res := struct {
+		Failures []struct {
+			FailureReason string `json:"failureReason"`
+		} `json:"failures"`
+	}{}
+	if err = json.Unmarshal([]byte(output), &res); err == nil && len(res.Failures) > 0 {
+		return fmt.Errorf("%s", res.Failures[0].FailureReason)
+	}
+	return nil
+}
+
+// gcr 优先使用 GOOGLE_APPLICATION_CREDENTIALS 指定的服务账号密钥，否则使用 gcloud 当前账号的访问令牌
+type gcr struct{}
+
+func (gcr) login(string) (auth, bool, error) {
+	if file := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); file != "" {
+		key, err := os.ReadFile(file)
+		if err != nil {
+			return auth{}, false, err
+		}
+		return auth{"_json_key", string(key)}, true, nil
+	}
+	if _, err := exec.LookPath("gcloud"); err != nil {
+		return auth{}, false, nil
+	}
+	token, err := runCommand("gcloud", []string{"auth", "print-access-token"}, "")
+	return auth{"oauth2accesstoken", token}, err == nil, err
+}
+
+// deleteTag GCR 及 Artifact Registry 支持按标签删除，只删除标签本身
+func (gcr) deleteTag(c *Client, ref Reference) error {
+	return c.deleteManifest(ref, ref.Tag, http.StatusAccepted)
+}
+
+// acr 优先使用 AZURE_CLIENT_ID 及 AZURE_CLIENT_SECRET 指定的服务主体，否则使用 az acr login 的访问令牌
+type acr struct{}
+
+// acrTokenUser az acr login --expose-token 返回的令牌对应的用户名
+const acrTokenUser = "00000000-0000-0000-0000-000000000000"
+
+func (acr) login(host string) (auth, bool, error) {
+	if id, secret := os.Getenv("AZURE_CLIENT_ID"), os.Getenv("AZURE_CLIENT_SECRET"); id != "" && secret != "" {
+		return auth{id, secret}, true, nil
+	}
+	if _, err := exec.LookPath("az"); err != nil {
+		return auth{}, false, nil
+	}
+	name := strings.SplitN(host, ".", 2)[0]
+	token, err := runCommand("az", []string{"acr", "login", "--name", name, "--expose-token", "--output", "tsv", "--query", "accessToken"}, "")
+	return auth{acrTokenUser, token}, err == nil, err
+}
+
+// deleteTag 通过 ACR 的 /acr/v1 接口只删除标签
+func (acr) deleteTag(c *Client, ref Reference) error {
+	scheme := "https"
+	if c.cfg.PlainHTTP {
+		scheme = "http"
+	}
+	u := fmt.Sprintf("%s://%s/acr/v1/%s/_tags/%s", scheme, ref.endpoint(), ref.Repository, ref.Tag)
+	resp, err := c.do(ref, deleteScope(ref), func() (*http.Request, error) {
+		return http.NewRequest(http.MethodDelete, u, nil)
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return checkStatus(resp, http.StatusAccepted)
+}
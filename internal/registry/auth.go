@@ -0,0 +1,212 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/runctx"
+	"github.com/coffee377/autoctl/internal/shell"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// dockerHubKeys Docker 配置文件中 Docker Hub 可能使用的键
+var dockerHubKeys = []string{"https://index.docker.io/v1/", "index.docker.io", "docker.io", "registry-1.docker.io"}
+
+// ecrReg Amazon ECR 私有仓库地址，分组为区域
+var ecrReg = regexp.MustCompile(`^\d{12}\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?$`)
+
+// Credential 单个镜像仓库的认证信息，对应配置文件 registry.credentials 中的一项
+type Credential struct {
+	Registry        string `mapstructure:"registry"`        // 镜像仓库地址，如 ghcr.io、harbor.example.com，Docker Hub 为 docker.io
+	Type            string `mapstructure:"type"`            // 仓库类型 generic | dockerhub | harbor | ecr | gcr | acr，默认按地址识别，Harbor 需显式指定
+	Username        string `mapstructure:"username"`        // 用户名，Amazon ECR 为 AWS
+	Password        string `mapstructure:"password"`        // 密码或访问令牌
+	PasswordCommand string `mapstructure:"passwordCommand"` // 输出密码的命令，如 aws ecr get-login-password --region us-east-1
+}
+
+// auth 认证信息，均为空时匿名访问
+type auth struct {
+	username, password string
+}
+
+// credential 镜像仓库的认证信息，依次使用 registry.credentials 中匹配的项、registry.username、
+// Docker 配置文件（auths、credHelpers、credsStore）中的登录信息，最后按仓库类型使用平台身份，如 ECR 的 IAM、ACR 的服务主体
+func (c *Client) credential(host string) (auth, error) {
+	if a, ok := c.auths[host]; ok {
+		return a, nil
+	}
+	a, err := c.lookup(host)
+	if err != nil {
+		return a, fmt.Errorf("registry %s: %w", host, err)
+	}
+	c.auths[host] = a
+	return a, nil
+}
+
+func (c *Client) lookup(host string) (auth, error) {
+	for _, cred := range c.cfg.Credentials {
+		if normalizeHost(cred.Registry) != host {
+			continue
+		}
+		if cred.PasswordCommand == "" {
+			return auth{cred.Username, cred.Password}, nil
+		}
+		password, err := runScript("passwordCommand", cred.PasswordCommand)
+		return auth{cred.Username, password}, err
+	}
+	if c.cfg.Username != "" {
+		return auth{c.cfg.Username, c.cfg.Password}, nil
+	}
+	if a, ok := dockerConfigAuth(host); ok {
+		return a, nil
+	}
+	a, _, err := c.adapter(host).login(host)
+	return a, err
+}
+
+// authorize 为请求设置基本认证
+func (c *Client) authorize(req *http.Request, host string) error {
+	a, err := c.credential(host)
+	if err != nil {
+		return err
+	}
+	if a.username != "" || a.password != "" {
+		req.SetBasicAuth(a.username, a.password)
+	}
+	return nil
+}
+
+// normalizeHost 去除协议及路径，Docker Hub 的各种地址统一为 docker.io
+func normalizeHost(s string) string {
+	s = strings.TrimPrefix(strings.TrimPrefix(s, "https://"), "http://")
+	if i := strings.Index(s, "/"); i >= 0 {
+		s = s[:i]
+	}
+	if s == "index.docker.io" || s == dockerHubEndpoint {
+		return DockerHub
+	}
+	return s
+}
+
+// dockerConfigAuth 读取 docker login 保存的登录信息，配置文件为 $DOCKER_CONFIG/config.json 或 ~/.docker/config.json
+func dockerConfigAuth(host string) (auth, bool) {
+	dir := os.Getenv("DOCKER_CONFIG")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return auth{}, false
+		}
+		dir = filepath.Join(home, ".docker")
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "config.json"))
+	if err != nil {
+		return auth{}, false
+	}
+	cfg := struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+		CredHelpers map[string]string `json:"credHelpers"`
+		CredsStore  string            `json:"credsStore"`
+	}{}
+	if err = json.Unmarshal(data, &cfg); err != nil {
+		log.Debug("parse docker config: %s", err)
+		return auth{}, false
+	}
+	keys := []string{host}
+	if host == DockerHub {
+		keys = dockerHubKeys
+	}
+	for key, helper := range cfg.CredHelpers {
+		if normalizeHost(key) == host {
+			return credentialHelper(helper, key)
+		}
+	}
+	for key, entry := range cfg.Auths {
+		if normalizeHost(key) != host {
+			continue
+		}
+		if entry.Auth == "" {
+			if entry.Username != "" {
+				return auth{entry.Username, entry.Password}, true
+			}
+			break
+		}
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			return auth{}, false
+		}
+		username, password, _ := strings.Cut(string(decoded), ":")
+		return auth{username, password}, true
+	}
+	if cfg.CredsStore != "" {
+		for _, key := range keys {
+			if a, ok := credentialHelper(cfg.CredsStore, key); ok {
+				return a, true
+			}
+		}
+	}
+	return auth{}, false
+}
+
+// credentialHelper 通过 docker-credential-<helper> get 获取登录信息，如 ecr-login、desktop、osxkeychain
+func credentialHelper(helper, server string) (auth, bool) {
+	output, err := runCommand("docker-credential-"+helper, []string{"get"}, server)
+	if err != nil {
+		log.Debug("docker credential helper %s: %s", helper, err)
+		return auth{}, false
+	}
+	res := struct {
+		Username string `json:"Username"`
+		Secret   string `json:"Secret"`
+	}{}
+	if err = json.Unmarshal([]byte(output), &res); err != nil {
+		return auth{}, false
+	}
+	return auth{res.Username, res.Secret}, true
+}
+
+// runCommand 执行命令并返回去除首尾空白的标准输出
+func runCommand(name string, args []string, stdin string) (string, error) {
+	return run(name, stdin, func(ctx context.Context) *exec.Cmd {
+		return exec.CommandContext(ctx, name, args...)
+	})
+}
+
+// runScript 在当前系统的 shell 中执行脚本并返回去除首尾空白的标准输出
+func runScript(name, script string) (string, error) {
+	return run(name, "", func(ctx context.Context) *exec.Cmd {
+		return shell.Command(ctx, script)
+	})
+}
+
+func run(name, stdin string, command func(ctx context.Context) *exec.Cmd) (string, error) {
+	ctx, cancel := runctx.Command()
+	defer cancel()
+	cmd := command(ctx)
+	cmd.Stdin = strings.NewReader(stdin)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	start := time.Now()
+	err := cmd.Run()
+	log.TraceCommand(cmd, start, err)
+	if err = runctx.Wrap(ctx, err); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%s: %w: %s", name, err, msg)
+		}
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
@@ -0,0 +1,253 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// manifest 镜像清单或镜像索引中复制时需要的字段
+type manifest struct {
+	MediaType string `json:"mediaType"`
+	Config    struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Layers []struct {
+		Digest string `json:"digest"`
+	} `json:"layers"`
+	Manifests []struct {
+		Digest string `json:"digest"`
+	} `json:"manifests"`
+}
+
+func pullScope(ref Reference) string {
+	return fmt.Sprintf("repository:%s:pull", ref.Repository)
+}
+
+func pushScope(ref Reference) string {
+	return fmt.Sprintf("repository:%s:pull,push", ref.Repository)
+}
+
+// Copy 将镜像复制为另一个镜像标签，不重新构建；多架构镜像复制全部平台
+// 同一仓库地址时挂载已有的层，不同仓库地址时逐层传输，目标中已存在的层跳过
+// 返回目标镜像的清单摘要
+func (c *Client) Copy(src, dst Reference) (string, error) {
+	if dst.Tag == "" {
+		return "", fmt.Errorf("destination image %s has no tag", dst)
+	}
+	ref := src.Tag
+	if src.Digest != "" {
+		ref = src.Digest
+	}
+	if ref == "" {
+		ref = "latest"
+	}
+	return c.copyManifest(src, dst, ref, dst.Tag)
+}
+
+// copyManifest 复制 src 中的清单 ref 及其引用的内容，以 target（标签或摘要）写入 dst
+func (c *Client) copyManifest(src, dst Reference, ref, target string) (string, error) {
+	data, mediaType, err := c.getManifest(src, ref)
+	if err != nil {
+		return "", err
+	}
+	m := manifest{}
+	if err = json.Unmarshal(data, &m); err != nil {
+		return "", fmt.Errorf("parse manifest of %s: %w", src, err)
+	}
+	for _, child := range m.Manifests {
+		if _, err = c.copyManifest(src, dst, child.Digest, child.Digest); err != nil {
+			return "", err
+		}
+	}
+	blobs := make([]string, 0, len(m.Layers)+1)
+	if m.Config.Digest != "" {
+		blobs = append(blobs, m.Config.Digest)
+	}
+	for _, l := range m.Layers {
+		blobs = append(blobs, l.Digest)
+	}
+	for _, digest := range blobs {
+		if err = c.copyBlob(src, dst, digest); err != nil {
+			return "", err
+		}
+	}
+	return c.putManifest(dst, target, mediaType, data)
+}
+
+func (c *Client) getManifest(ref Reference, tag string) ([]byte, string, error) {
+	u := fmt.Sprintf("%s/manifests/%s", c.baseURL(ref), tag)
+	resp, err := c.do(ref, pullScope(ref), func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err == nil {
+			req.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+			req.Header.Set("Cache-Control", "no-cache")
+		}
+		return req, err
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, "", &NotFoundError{Reference: ref}
+	}
+	if err = checkStatus(resp, http.StatusOK); err != nil {
+		return nil, "", err
+	}
+	data, err := io.ReadAll(resp.Body)
+	return data, resp.Header.Get("Content-Type"), err
+}
+
+func (c *Client) putManifest(ref Reference, tag, mediaType string, data []byte) (string, error) {
+	u := fmt.Sprintf("%s/manifests/%s", c.baseURL(ref), tag)
+	resp, err := c.do(ref, pushScope(ref), func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodPut, u, bytes.NewReader(data))
+		if err == nil {
+			req.Header.Set("Content-Type", mediaType)
+		}
+		return req, err
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if err = checkStatus(resp, http.StatusCreated); err != nil {
+		return "", err
+	}
+	return resp.Header.Get("Docker-Content-Digest"), nil
+}
+
+// copyBlob 目标中不存在该层时挂载或传输
+func (c *Client) copyBlob(src, dst Reference, digest string) error {
+	exists, err := c.blobExists(dst, digest)
+	if err != nil || exists {
+		return err
+	}
+	uploads := c.baseURL(dst) + "/blobs/uploads/"
+	scope := pushScope(dst)
+	if src.Registry == dst.Registry {
+		// 同一仓库地址跨镜像挂载 https://distribution.github.io/distribution/spec/api/#cross-repository-blob-mount
+		scope += " " + pullScope(src)
+		query := url.Values{"mount": {digest}, "from": {src.Repository}}
+		resp, err := c.do(dst, scope, func() (*http.Request, error) {
+			return http.NewRequest(http.MethodPost, uploads+"?"+query.Encode(), nil)
+		})
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode == http.StatusCreated {
+			return nil
+		}
+		// 不支持挂载时返回 202 及上传地址，改为传输
+		if err = checkStatus(resp, http.StatusAccepted); err != nil {
+			return err
+		}
+		return c.uploadBlob(src, dst, digest, scope, resp.Header.Get("Location"))
+	}
+	resp, err := c.do(dst, scope, func() (*http.Request, error) {
+		return http.NewRequest(http.MethodPost, uploads, nil)
+	})
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if err = checkStatus(resp, http.StatusAccepted); err != nil {
+		return err
+	}
+	return c.uploadBlob(src, dst, digest, scope, resp.Header.Get("Location"))
+}
+
+func (c *Client) blobExists(ref Reference, digest string) (bool, error) {
+	u := fmt.Sprintf("%s/blobs/%s", c.baseURL(ref), digest)
+	resp, err := c.do(ref, pushScope(ref), func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodHead, u, nil)
+		if err == nil {
+			req.Header.Set("Cache-Control", "no-store")
+		}
+		return req, err
+	})
+	if err != nil {
+		return false, err
+	}
+	_ = resp.Body.Close()
+	return resp.StatusCode == http.StatusOK, nil
+}
+
+// uploadBlob 从 src 读取层并一次性上传到 location
+func (c *Client) uploadBlob(src, dst Reference, digest, scope, location string) error {
+	target, err := c.resolve(dst, location)
+	if err != nil {
+		return err
+	}
+	q := target.Query()
+	q.Set("digest", digest)
+	target.RawQuery = q.Encode()
+	resp, err := c.do(dst, scope, func() (*http.Request, error) {
+		body, size, err := c.openBlob(src, digest)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest(http.MethodPut, target.String(), body)
+		if err != nil {
+			_ = body.Close()
+			return nil, err
+		}
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "application/octet-stream")
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	return checkStatus(resp, http.StatusCreated)
+}
+
+func (c *Client) openBlob(ref Reference, digest string) (io.ReadCloser, int64, error) {
+	u := fmt.Sprintf("%s/blobs/%s", c.baseURL(ref), digest)
+	resp, err := c.do(ref, pullScope(ref), func() (*http.Request, error) {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err == nil {
+			req.Header.Set("Cache-Control", "no-store")
+		}
+		return req, err
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	if err = checkStatus(resp, http.StatusOK); err != nil {
+		_ = resp.Body.Close()
+		return nil, 0, err
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+// resolve 上传地址可能为相对路径
+func (c *Client) resolve(ref Reference, location string) (*url.URL, error) {
+	if location == "" {
+		return nil, fmt.Errorf("registry %s: upload location is empty", ref.Registry)
+	}
+	base, err := url.Parse(c.baseURL(ref))
+	if err != nil {
+		return nil, err
+	}
+	loc, err := url.Parse(location)
+	if err != nil {
+		return nil, err
+	}
+	return base.ResolveReference(loc), nil
+}
+
+func checkStatus(resp *http.Response, expected int) error {
+	if resp.StatusCode == expected {
+		return nil
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return fmt.Errorf("%s %s: %d %s", resp.Request.Method, resp.Request.URL.Redacted(), resp.StatusCode, strings.TrimSpace(string(data)))
+}
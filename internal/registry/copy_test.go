@@ -0,0 +1,111 @@
+package registry
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRegistry 内存中的镜像仓库，只实现复制镜像用到的接口
+type fakeRegistry struct {
+	mu        sync.Mutex
+	manifests map[string]string // <repo>/<tag or digest> => 清单
+	blobs     map[string]string // <repo>/<digest> => 内容
+	mounts    int
+	uploads   int
+}
+
+func newFakeRegistry() *fakeRegistry {
+	return &fakeRegistry{manifests: map[string]string{}, blobs: map[string]string{}}
+}
+
+func (f *fakeRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	switch {
+	case strings.Contains(path, "/manifests/"):
+		key := strings.Replace(path, "/manifests/", "/", 1)
+		if r.Method == http.MethodPut {
+			data, _ := io.ReadAll(r.Body)
+			f.manifests[key] = string(data)
+			w.Header().Set("Docker-Content-Digest", "sha256:put")
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		m, ok := f.manifests[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/vnd.oci.image.manifest.v1+json")
+		_, _ = io.WriteString(w, m)
+	case strings.HasSuffix(path, "/blobs/uploads/"):
+		repo := strings.TrimSuffix(path, "/blobs/uploads/")
+		if from, digest := r.URL.Query().Get("from"), r.URL.Query().Get("mount"); from != "" {
+			if b, ok := f.blobs[from+"/"+digest]; ok {
+				f.blobs[repo+"/"+digest] = b
+				f.mounts++
+				w.WriteHeader(http.StatusCreated)
+				return
+			}
+		}
+		w.Header().Set("Location", "/upload/"+repo)
+		w.WriteHeader(http.StatusAccepted)
+	case strings.HasPrefix(r.URL.Path, "/upload/"):
+		data, _ := io.ReadAll(r.Body)
+		f.blobs[strings.TrimPrefix(r.URL.Path, "/upload/")+"/"+r.URL.Query().Get("digest")] = string(data)
+		f.uploads++
+		w.WriteHeader(http.StatusCreated)
+	case strings.Contains(path, "/blobs/"):
+		b, ok := f.blobs[strings.Replace(path, "/blobs/", "/", 1)]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = io.WriteString(w, b)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+const testManifest = `{"mediaType":"application/vnd.oci.image.manifest.v1+json","config":{"digest":"sha256:c"},"layers":[{"digest":"sha256:l1"},{"digest":"sha256:l2"}]}`
+
+func TestClient_Copy(t *testing.T) {
+	staging := newFakeRegistry()
+	staging.manifests["org/app/1.0.0"] = testManifest
+	for _, d := range []string{"sha256:c", "sha256:l1", "sha256:l2"} {
+		staging.blobs["org/app/"+d] = "blob " + d
+	}
+	stagingServer := httptest.NewServer(staging)
+	defer stagingServer.Close()
+	production := newFakeRegistry()
+	production.blobs["prod/app/sha256:l1"] = "blob sha256:l1"
+	productionServer := httptest.NewServer(production)
+	defer productionServer.Close()
+
+	client, _ := New(Config{PlainHTTP: true})
+	stagingHost := strings.TrimPrefix(stagingServer.URL, "http://")
+	src, _ := ParseReference(stagingHost + "/org/app:1.0.0")
+
+	// 同一仓库地址挂载已有的层
+	dst, _ := ParseReference(stagingHost + "/org/app-release:1.0.0")
+	if _, err := client.Copy(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if staging.mounts != 3 || staging.manifests["org/app-release/1.0.0"] != testManifest {
+		t.Errorf("expected 3 blobs mounted and manifest copied, but %d mounts got", staging.mounts)
+	}
+
+	// 不同仓库地址传输目标中不存在的层
+	dst, _ = ParseReference(strings.TrimPrefix(productionServer.URL, "http://") + "/prod/app:1.0.0")
+	if _, err := client.Copy(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	if production.uploads != 2 || production.blobs["prod/app/sha256:l2"] != "blob sha256:l2" || production.manifests["prod/app/1.0.0"] != testManifest {
+		t.Errorf("expected 2 blobs uploaded and manifest copied, but %d uploads got", production.uploads)
+	}
+}
@@ -0,0 +1,201 @@
+package registry
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/artifact"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+const (
+	// DefaultArtifactType 发布产物的 OCI 制品类型
+	DefaultArtifactType = "application/vnd.autoctl.release.v1"
+	// 与 ORAS 一致的空配置及文件层媒体类型
+	emptyMediaType = "application/vnd.oci.empty.v1+json"
+	layerMediaType = "application/vnd.oci.image.layer.v1.tar"
+	ociManifest    = "application/vnd.oci.image.manifest.v1+json"
+
+	AnnotationTitle    = "org.opencontainers.image.title"
+	AnnotationVersion  = "org.opencontainers.image.version"
+	AnnotationRevision = "org.opencontainers.image.revision"
+	AnnotationCreated  = "org.opencontainers.image.created"
+)
+
+// ArtifactTarget 以 OCI 制品推送发布产物的目标，对应配置文件 oci 节点中的一项
+type ArtifactTarget struct {
+	Repository   string   `mapstructure:"repository"`   // 制品仓库，如 ghcr.io/coffee377/autoctl-artifacts
+	ArtifactType string   `mapstructure:"artifactType"` // 制品类型，默认 application/vnd.autoctl.release.v1
+	Files        []string `mapstructure:"files"`        // 推送的产物文件名匹配规则，为空时推送全部产物
+	Floating     bool     `mapstructure:"floating"`     // 正式版本同时更新 X.Y、X 及 latest 标签
+	Registry     Config   `mapstructure:"registry"`     // 镜像仓库访问配置
+}
+
+// Validate 检查制品仓库地址
+func (t ArtifactTarget) Validate() error {
+	ref, err := ParseReference(t.Repository)
+	if err == nil && (ref.Tag != "" || ref.Digest != "") {
+		err = fmt.Errorf("oci repository %s must not contain a tag or digest", t.Repository)
+	}
+	return err
+}
+
+// Tags 版本对应的制品标签，OCI 标签不允许 +，替换为 _
+func (t ArtifactTarget) Tags(version semver.Semver) []string {
+	tags := []string{version.Format(semver.LayoutDocker)}
+	if t.Floating && len(version.PreRelease()) == 0 {
+		tags = append(tags, version.Format(semver.LayoutMajorMinor), version.Format(semver.LayoutMajor), "latest")
+	}
+	return tags
+}
+
+// Select 按文件名匹配规则筛选产物
+func (t ArtifactTarget) Select(artifacts []artifact.Artifact) ([]artifact.Artifact, error) {
+	if len(t.Files) == 0 {
+		return artifacts, nil
+	}
+	selected := make([]artifact.Artifact, 0, len(artifacts))
+	for _, a := range artifacts {
+		for _, pattern := range t.Files {
+			ok, err := filepath.Match(pattern, filepath.Base(a.Name))
+			if err != nil {
+				return nil, fmt.Errorf("invalid oci file pattern %q: %w", pattern, err)
+			}
+			if ok {
+				selected = append(selected, a)
+				break
+			}
+		}
+	}
+	return selected, nil
+}
+
+// descriptor OCI 内容描述符
+type descriptor struct {
+	MediaType   string            `json:"mediaType"`
+	Digest      string            `json:"digest"`
+	Size        int64             `json:"size"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// Push 以 ORAS 兼容的方式推送文件：空配置，每个文件为一层并以文件名标注，返回清单摘要
+// https://github.com/opencontainers/image-spec/blob/main/manifest.md#guidelines-for-artifact-usage
+func (c *Client) Push(ref Reference, artifactType string, files []artifact.Artifact, annotations map[string]string, tags ...string) (string, error) {
+	if len(files) == 0 {
+		return "", fmt.Errorf("push %s: no files", ref.Name())
+	}
+	if artifactType == "" {
+		artifactType = DefaultArtifactType
+	}
+	empty := []byte("{}")
+	config := descriptor{MediaType: emptyMediaType, Digest: digestOf(empty), Size: int64(len(empty))}
+	if err := c.pushBlob(ref, config.Digest, config.Size, func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(empty)), nil
+	}); err != nil {
+		return "", err
+	}
+	layers := make([]descriptor, 0, len(files))
+	for _, f := range files {
+		path := f.Path
+		digest := "sha256:" + f.SHA256
+		if err := c.pushBlob(ref, digest, f.Size, func() (io.ReadCloser, error) {
+			return os.Open(path)
+		}); err != nil {
+			return "", fmt.Errorf("push %s: %w", f.Name, err)
+		}
+		layers = append(layers, descriptor{
+			MediaType:   layerMediaType,
+			Digest:      digest,
+			Size:        f.Size,
+			Annotations: map[string]string{AnnotationTitle: filepath.Base(f.Name)},
+		})
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	if _, ok := annotations[AnnotationCreated]; !ok {
+		annotations[AnnotationCreated] = time.Now().UTC().Format(time.RFC3339)
+	}
+	data, err := json.Marshal(struct {
+		SchemaVersion int               `json:"schemaVersion"`
+		MediaType     string            `json:"mediaType"`
+		ArtifactType  string            `json:"artifactType"`
+		Config        descriptor        `json:"config"`
+		Layers        []descriptor      `json:"layers"`
+		Annotations   map[string]string `json:"annotations,omitempty"`
+	}{2, ociManifest, artifactType, config, layers, annotations})
+	if err != nil {
+		return "", err
+	}
+	if len(tags) == 0 {
+		tags = []string{ref.Tag}
+	}
+	digest := digestOf(data)
+	for _, tag := range tags {
+		if tag == "" {
+			tag = digest
+		}
+		if _, err = c.putManifest(ref, tag, ociManifest, data); err != nil {
+			return "", err
+		}
+	}
+	return digest, nil
+}
+
+// pushBlob 目标中不存在该内容时一次性上传
+func (c *Client) pushBlob(ref Reference, digest string, size int64, open func() (io.ReadCloser, error)) error {
+	exists, err := c.blobExists(ref, digest)
+	if err != nil || exists {
+		return err
+	}
+	scope := pushScope(ref)
+	resp, err := c.do(ref, scope, func() (*http.Request, error) {
+		return http.NewRequest(http.MethodPost, c.baseURL(ref)+"/blobs/uploads/", nil)
+	})
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if err = checkStatus(resp, http.StatusAccepted); err != nil {
+		return err
+	}
+	target, err := c.resolve(ref, resp.Header.Get("Location"))
+	if err != nil {
+		return err
+	}
+	q := target.Query()
+	q.Set("digest", digest)
+	target.RawQuery = q.Encode()
+	resp, err = c.do(ref, scope, func() (*http.Request, error) {
+		body, err := open()
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequest(http.MethodPut, target.String(), body)
+		if err != nil {
+			_ = body.Close()
+			return nil, err
+		}
+		req.ContentLength = size
+		req.Header.Set("Content-Type", "application/octet-stream")
+		return req, nil
+	})
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	return checkStatus(resp, http.StatusCreated)
+}
+
+func digestOf(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/artifact"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+func TestClient_Push(t *testing.T) {
+	fake := newFakeRegistry()
+	srv := httptest.NewServer(fake)
+	defer srv.Close()
+	file := filepath.Join(t.TempDir(), "app_linux_amd64.tar.gz")
+	if err := os.WriteFile(file, []byte("binary"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	a, err := artifact.New(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client, _ := New(Config{PlainHTTP: true})
+	ref, _ := ParseReference(strings.TrimPrefix(srv.URL, "http://") + "/org/app-artifacts")
+	target := ArtifactTarget{Floating: true}
+	v, _ := semver.Version("1.2.0")
+	digest, err := client.Push(ref, "", []artifact.Artifact{*a}, map[string]string{AnnotationVersion: "1.2.0"}, target.Tags(v)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tag := range []string{"1.2.0", "1.2", "1", "latest"} {
+		if _, ok := fake.manifests["org/app-artifacts/"+tag]; !ok {
+			t.Errorf("expected tag %s pushed, but %v got", tag, fake.manifests)
+		}
+	}
+	m := struct {
+		ArtifactType string `json:"artifactType"`
+		Layers       []descriptor
+	}{}
+	data := fake.manifests["org/app-artifacts/1.2.0"]
+	if err = json.Unmarshal([]byte(data), &m); err != nil || digestOf([]byte(data)) != digest {
+		t.Fatalf("unexpected manifest %s %v", data, err)
+	}
+	if m.ArtifactType != DefaultArtifactType || len(m.Layers) != 1 || m.Layers[0].Annotations[AnnotationTitle] != "app_linux_amd64.tar.gz" {
+		t.Errorf("unexpected manifest %+v", m)
+	}
+	if fake.blobs["org/app-artifacts/sha256:"+a.SHA256] != "binary" || fake.uploads != 2 {
+		t.Errorf("expected config and file uploaded, but %d uploads got", fake.uploads)
+	}
+
+	pre, _ := semver.Version("1.3.0-beta.1+build.5")
+	if tags := target.Tags(pre); len(tags) != 1 || tags[0] != "1.3.0-beta.1_build.5" {
+		t.Errorf("expected prerelease tag only, but %v got", tags)
+	}
+}
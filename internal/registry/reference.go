@@ -0,0 +1,70 @@
+package registry
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	DockerHub         = "docker.io"
+	dockerHubEndpoint = "registry-1.docker.io"
+)
+
+// Reference 镜像引用，如 ghcr.io/coffee377/autoctl:1.0.0
+type Reference struct {
+	Registry   string // 镜像仓库地址，默认 docker.io
+	Repository string // 镜像名称，Docker Hub 官方镜像补全 library/ 前缀
+	Tag        string
+	Digest     string
+}
+
+// ParseReference 按 Docker 规则解析镜像引用，第一段包含 . 或 : 或为 localhost 时视为仓库地址
+func ParseReference(s string) (Reference, error) {
+	ref := Reference{}
+	if s == "" {
+		return ref, fmt.Errorf("empty image reference")
+	}
+	name := s
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, ref.Digest = name[:i], name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 && !strings.Contains(name[i:], "/") {
+		name, ref.Tag = name[:i], name[i+1:]
+	}
+	if i := strings.Index(name, "/"); i >= 0 && (strings.ContainsAny(name[:i], ".:") || name[:i] == "localhost") {
+		ref.Registry, ref.Repository = name[:i], name[i+1:]
+	} else {
+		ref.Registry, ref.Repository = DockerHub, name
+	}
+	if ref.Registry == DockerHub && !strings.Contains(ref.Repository, "/") {
+		ref.Repository = "library/" + ref.Repository
+	}
+	if ref.Repository == "" || strings.ToLower(ref.Repository) != ref.Repository {
+		return ref, fmt.Errorf("invalid image reference %q", s)
+	}
+	return ref, nil
+}
+
+// Name 不含标签及摘要的镜像名称
+func (r Reference) Name() string {
+	return r.Registry + "/" + r.Repository
+}
+
+func (r Reference) String() string {
+	s := r.Name()
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	if r.Digest != "" {
+		s += "@" + r.Digest
+	}
+	return s
+}
+
+// endpoint 仓库 API 地址
+func (r Reference) endpoint() string {
+	if r.Registry == DockerHub {
+		return dockerHubEndpoint
+	}
+	return r.Registry
+}
@@ -0,0 +1,231 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/httpclient"
+)
+
+// manifestTypes 查询清单时接受的媒体类型，包含多架构镜像索引
+var manifestTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// Config 镜像仓库访问配置，对应配置文件 registry 节点
+type Config struct {
+	Username    string            `mapstructure:"username"`    // 用户名，默认读取 REGISTRY_USERNAME 环境变量，适用于所有未在 credentials 中配置的仓库
+	Password    string            `mapstructure:"password"`    // 密码或访问令牌，默认读取 REGISTRY_PASSWORD 环境变量
+	Credentials []Credential      `mapstructure:"credentials"` // 按仓库地址配置的认证信息，未配置时依次使用 username、docker login 的登录信息，Amazon ECR 使用 aws ecr get-login-password
+	PlainHTTP   bool              `mapstructure:"plainHttp"`   // 使用 HTTP 访问私有仓库
+	HTTP        httpclient.Config `mapstructure:"http"`
+}
+
+// Client 基于 Docker Registry HTTP API V2 的镜像仓库客户端
+type Client struct {
+	cfg    Config
+	client *http.Client
+	tokens map[string]string // 按仓库地址及权限范围缓存的访问令牌
+	auths  map[string]auth   // 按仓库地址缓存的认证信息
+}
+
+func New(cfg Config) (*Client, error) {
+	if cfg.Username == "" {
+		cfg.Username = os.Getenv("REGISTRY_USERNAME")
+	}
+	if cfg.Password == "" {
+		cfg.Password = os.Getenv("REGISTRY_PASSWORD")
+	}
+	client, err := httpclient.New(cfg.HTTP)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{cfg: cfg, client: client, tokens: map[string]string{}, auths: map[string]auth{}}, nil
+}
+
+// Digest 查询镜像清单摘要，镜像不存在时返回 ErrNotFound
+func (c *Client) Digest(ref Reference) (string, error) {
+	tag := ref.Tag
+	if ref.Digest != "" {
+		tag = ref.Digest
+	}
+	if tag == "" {
+		tag = "latest"
+	}
+	u := fmt.Sprintf("%s/manifests/%s", c.baseURL(ref), tag)
+	resp, err := c.head(ref, u, "")
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		token, err := c.token(resp.Header.Get("WWW-Authenticate"), ref, "")
+		if err != nil {
+			return "", err
+		}
+		if resp, err = c.head(ref, u, token); err != nil {
+			return "", err
+		}
+	}
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return "", &NotFoundError{Reference: ref}
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return "", fmt.Errorf("HEAD %s: %d", u, resp.StatusCode)
+	}
+	return resp.Header.Get("Docker-Content-Digest"), nil
+}
+
+// Ping 检查镜像仓库可访问，未认证的 401 响应同样视为可访问
+func (c *Client) Ping(ref Reference) error {
+	scheme := "https"
+	if c.cfg.PlainHTTP {
+		scheme = "http"
+	}
+	u := fmt.Sprintf("%s://%s/v2/", scheme, ref.endpoint())
+	resp, err := c.client.Get(u)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusUnauthorized {
+		return fmt.Errorf("GET %s: %d", u, resp.StatusCode)
+	}
+	return nil
+}
+
+// Exists 判断镜像是否存在
+func (c *Client) Exists(ref Reference) (bool, error) {
+	_, err := c.Digest(ref)
+	if _, ok := err.(*NotFoundError); ok {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// NotFoundError 镜像仓库中不存在该镜像
+type NotFoundError struct {
+	Reference Reference
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("image %s not found", e.Reference)
+}
+
+func (c *Client) baseURL(ref Reference) string {
+	scheme := "https"
+	if c.cfg.PlainHTTP {
+		scheme = "http"
+	}
+	return fmt.Sprintf("%s://%s/v2/%s", scheme, ref.endpoint(), ref.Repository)
+}
+
+// do 发送请求，收到 401 时按质询获取 scope 范围的令牌后重新发送，newReq 每次返回新的请求
+func (c *Client) do(ref Reference, scope string, newReq func() (*http.Request, error)) (*http.Response, error) {
+	key := ref.Registry + " " + scope
+	send := func(token string) (*http.Response, error) {
+		req, err := newReq()
+		if err != nil {
+			return nil, err
+		}
+		if token != "" {
+			req.Header.Set("Authorization", token)
+		} else if err = c.authorize(req, ref.Registry); err != nil {
+			return nil, err
+		}
+		return c.client.Do(req)
+	}
+	resp, err := send(c.tokens[key])
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	_ = resp.Body.Close()
+	token, err := c.token(resp.Header.Get("WWW-Authenticate"), ref, scope)
+	if err != nil {
+		return nil, err
+	}
+	c.tokens[key] = token
+	return send(token)
+}
+
+func (c *Client) head(ref Reference, u, token string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodHead, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestTypes, ", "))
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	} else if err = c.authorize(req, ref.Registry); err != nil {
+		return nil, err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_ = resp.Body.Close()
+	return resp, nil
+}
+
+var challengeReg = regexp.MustCompile(`(\w+)="([^"]*)"`)
+
+// token 按 WWW-Authenticate 质询获取访问令牌 https://distribution.github.io/distribution/spec/auth/token/
+// scope 为空时使用质询中的权限范围，多个权限范围以空格分隔
+func (c *Client) token(challenge string, ref Reference, scope string) (string, error) {
+	if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+		return "", fmt.Errorf("registry %s: unauthorized, check registry credentials", ref.Registry)
+	}
+	params := map[string]string{}
+	for _, m := range challengeReg.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+	if params["realm"] == "" {
+		return "", fmt.Errorf("registry %s: invalid auth challenge %q", ref.Registry, challenge)
+	}
+	query := url.Values{}
+	if params["service"] != "" {
+		query.Set("service", params["service"])
+	}
+	if scope == "" {
+		scope = params["scope"]
+	}
+	if scope == "" {
+		scope = fmt.Sprintf("repository:%s:pull", ref.Repository)
+	}
+	for _, s := range strings.Fields(scope) {
+		query.Add("scope", s)
+	}
+	req, err := http.NewRequest(http.MethodGet, params["realm"]+"?"+query.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	if err = c.authorize(req, ref.Registry); err != nil {
+		return "", err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("registry %s: get token: %d", ref.Registry, resp.StatusCode)
+	}
+	res := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err = json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		return "", err
+	}
+	if res.Token == "" {
+		res.Token = res.AccessToken
+	}
+	return "Bearer " + res.Token, nil
+}
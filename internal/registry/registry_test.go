@@ -0,0 +1,102 @@
+package registry
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"nginx", "docker.io/library/nginx"},
+		{"nginx:1.25", "docker.io/library/nginx:1.25"},
+		{"coffee377/autoctl:1.0.0", "docker.io/coffee377/autoctl:1.0.0"},
+		{"ghcr.io/coffee377/autoctl:1.0.0", "ghcr.io/coffee377/autoctl:1.0.0"},
+		{"localhost:5000/app", "localhost:5000/app"},
+		{"registry.cn-hangzhou.aliyuncs.com/ns/app@sha256:abc", "registry.cn-hangzhou.aliyuncs.com/ns/app@sha256:abc"},
+	}
+	for _, test := range tests {
+		ref, err := ParseReference(test.input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ref.String() != test.expected {
+			t.Errorf("%s expected %s, but %s got", test.input, test.expected, ref)
+		}
+	}
+	if _, err := ParseReference("Ghcr.io/Org/App"); err == nil {
+		t.Error("expected invalid reference error, got nil")
+	}
+}
+
+func TestClient_Exists(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/token":
+			if r.URL.Query().Get("scope") != "repository:org/app:pull" {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			_, _ = w.Write([]byte(`{"token":"t0k3n"}`))
+		case r.Header.Get("Authorization") != "Bearer t0k3n":
+			w.Header().Set("WWW-Authenticate", `Bearer realm="`+server.URL+`/token",service="test",scope="repository:org/app:pull"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		case r.URL.Path == "/v2/org/app/manifests/1.0.0":
+			w.Header().Set("Docker-Content-Digest", "sha256:abc")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client, _ := New(Config{PlainHTTP: true})
+	host := strings.TrimPrefix(server.URL, "http://")
+	ref, _ := ParseReference(host + "/org/app:1.0.0")
+	digest, err := client.Digest(ref)
+	if err != nil || digest != "sha256:abc" {
+		t.Errorf("expected digest sha256:abc, but %q with err %v got", digest, err)
+	}
+	ref.Tag = "2.0.0"
+	if exists, err := client.Exists(ref); err != nil || exists {
+		t.Errorf("expected not exists, but %v with err %v got", exists, err)
+	}
+}
+
+func TestClient_Credential(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("DOCKER_CONFIG", dir)
+	t.Setenv("REGISTRY_USERNAME", "")
+	t.Setenv("REGISTRY_PASSWORD", "")
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+	config := `{
+  "auths": {"https://index.docker.io/v1/": {"auth": "` + base64.StdEncoding.EncodeToString([]byte("hub:secret")) + `"}},
+  "credHelpers": {"harbor.example.com": "test"}
+}`
+	_ = os.WriteFile(filepath.Join(dir, "config.json"), []byte(config), 0o644)
+	helper := "#!/bin/sh\nread server\necho '{\"ServerURL\":\"'$server'\",\"Username\":\"robot\",\"Secret\":\"t0k3n\"}'\n"
+	_ = os.WriteFile(filepath.Join(dir, "docker-credential-test"), []byte(helper), 0o755)
+
+	client, _ := New(Config{Credentials: []Credential{
+		{Registry: "ghcr.io", Username: "octocat", Password: "ghp"},
+		{Registry: "https://123456789012.dkr.ecr.us-east-1.amazonaws.com", Username: "AWS", PasswordCommand: "echo ecr-token"},
+	}})
+	for host, want := range map[string]auth{
+		"ghcr.io": {"octocat", "ghp"},
+		"123456789012.dkr.ecr.us-east-1.amazonaws.com": {"AWS", "ecr-token"},
+		DockerHub:            {"hub", "secret"},
+		"harbor.example.com": {"robot", "t0k3n"},
+		"quay.io":            {},
+	} {
+		if got, err := client.credential(host); err != nil || got != want {
+			t.Errorf("%s expected %+v, but %+v with err %v got", host, want, got, err)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// linkReg 分页的 Link 响应头，如 </v2/org/app/tags/list?last=1.0.0&n=100>; rel="next"
+var linkReg = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+// Tags 列出镜像的全部标签
+func (c *Client) Tags(ref Reference) ([]string, error) {
+	tags := make([]string, 0)
+	next := fmt.Sprintf("%s/tags/list?n=1000", c.baseURL(ref))
+	for next != "" {
+		u := next
+		resp, err := c.do(ref, pullScope(ref), func() (*http.Request, error) {
+			return http.NewRequest(http.MethodGet, u, nil)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if err = checkStatus(resp, http.StatusOK); err != nil {
+			_ = resp.Body.Close()
+			return nil, err
+		}
+		page := struct {
+			Tags []string `json:"tags"`
+		}{}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, page.Tags...)
+		next = ""
+		if m := linkReg.FindStringSubmatch(resp.Header.Get("Link")); m != nil {
+			loc, err := c.resolve(ref, m[1])
+			if err != nil {
+				return nil, err
+			}
+			next = loc.String()
+		}
+	}
+	return tags, nil
+}
+
+// PruneOptions 清理预发布标签的选项
+type PruneOptions struct {
+	TagPrefix string // 标签前缀，如 v，不带该前缀或不是语义化版本的标签不处理
+	Keep      int    // 保留最近的预发布标签数量
+	DryRun    bool   // 只返回将删除的标签
+}
+
+// PrunePrereleases 删除低于最新正式版本的预发布标签，如 1.2.0 发布后的 1.2.0-rc.1，最近的 Keep 个除外，返回删除的标签。
+// 通用仓库删除标签时会删除其指向的清单，因此与保留的标签摘要相同的预发布标签不删除
+func (c *Client) PrunePrereleases(ref Reference, opts PruneOptions) ([]string, error) {
+	tags, err := c.Tags(ref)
+	if err != nil {
+		return nil, err
+	}
+	var latest semver.Semver
+	var prereleases semver.Versions
+	names := map[semver.Semver]string{}
+	for _, tag := range tags {
+		if !strings.HasPrefix(tag, opts.TagPrefix) {
+			continue
+		}
+		v, err := semver.Version(strings.TrimPrefix(tag, opts.TagPrefix))
+		if err != nil {
+			continue
+		}
+		names[v] = tag
+		if len(v.PreRelease()) > 0 {
+			prereleases = append(prereleases, v)
+		} else if latest == nil || v.Compare(latest) > 0 {
+			latest = v
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+	sort.Sort(sort.Reverse(prereleases))
+	var stale []string
+	kept := 0
+	for _, v := range prereleases {
+		if v.Compare(latest) > 0 {
+			continue
+		}
+		if kept < opts.Keep {
+			kept++
+			continue
+		}
+		stale = append(stale, names[v])
+	}
+	if len(stale) > 0 && !c.untags(ref.Registry) {
+		if stale, err = c.unshared(ref, tags, stale); err != nil {
+			return nil, err
+		}
+	}
+	if opts.DryRun {
+		return stale, nil
+	}
+	for i, tag := range stale {
+		target := ref
+		target.Tag, target.Digest = tag, ""
+		var notFound *NotFoundError
+		if err = c.DeleteTag(target); errors.As(err, &notFound) {
+			// 通用仓库中与前一个标签指向同一清单，已一并删除
+			continue
+		} else if err != nil {
+			return stale[:i], err
+		}
+		log.Info("deleted %s", target)
+	}
+	return stale, nil
+}
+
+// unshared 排除与其它保留的标签指向同一清单的标签
+func (c *Client) unshared(ref Reference, tags, stale []string) ([]string, error) {
+	removing := map[string]bool{}
+	for _, tag := range stale {
+		removing[tag] = true
+	}
+	digestOf := func(tag string) (string, error) {
+		target := ref
+		target.Tag, target.Digest = tag, ""
+		return c.Digest(target)
+	}
+	kept := map[string]string{}
+	for _, tag := range tags {
+		if removing[tag] {
+			continue
+		}
+		digest, err := digestOf(tag)
+		if err != nil {
+			return nil, err
+		}
+		kept[digest] = tag
+	}
+	result := make([]string, 0, len(stale))
+	for _, tag := range stale {
+		digest, err := digestOf(tag)
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := kept[digest]; ok {
+			log.Warn("keep %s, it shares the manifest with %s", tag, other)
+			continue
+		}
+		result = append(result, tag)
+	}
+	return result, nil
+}
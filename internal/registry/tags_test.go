@@ -0,0 +1,141 @@
+package registry
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// tagRegistry 内存中的镜像仓库，只实现标签列表、清单摘要查询及删除接口
+type tagRegistry struct {
+	mu      sync.Mutex
+	tags    map[string]string // 标签 => 摘要
+	deleted []string          // 删除请求的路径
+}
+
+func (f *tagRegistry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/tags/list"):
+		tags := make([]string, 0)
+		for tag := range f.tags {
+			tags = append(tags, tag)
+		}
+		// 按 last 参数分两页返回
+		var page []string
+		for _, tag := range tags {
+			if (r.URL.Query().Get("last") == "") == (tag < "1.1") {
+				page = append(page, tag)
+			}
+		}
+		if r.URL.Query().Get("last") == "" {
+			w.Header().Set("Link", `</v2/org/app/tags/list?last=1.0.0&n=1000>; rel="next"`)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"tags": page})
+	case r.Method == http.MethodDelete:
+		f.deleted = append(f.deleted, r.URL.Path)
+		if strings.HasPrefix(r.URL.Path, "/api/v2.0/") {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	case strings.Contains(r.URL.Path, "/manifests/"):
+		digest, ok := f.tags[r.URL.Path[strings.LastIndex(r.URL.Path, "/")+1:]]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Docker-Content-Digest", digest)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func newTagRegistry(t *testing.T, tags map[string]string) (*tagRegistry, Reference) {
+	f := &tagRegistry{tags: tags}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	ref, _ := ParseReference(strings.TrimPrefix(server.URL, "http://") + "/org/app")
+	return f, ref
+}
+
+func TestClient_Tags(t *testing.T) {
+	_, ref := newTagRegistry(t, map[string]string{"1.0.0": "sha256:a", "1.1.0": "sha256:b"})
+	client, _ := New(Config{PlainHTTP: true})
+	tags, err := client.Tags(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(tags, []string{"1.0.0", "1.1.0"}) {
+		t.Errorf("expected tags of both pages, but %v got", tags)
+	}
+}
+
+func TestClient_PrunePrereleases(t *testing.T) {
+	tags := map[string]string{
+		"1.0.0":       "sha256:a",
+		"1.0.0-rc.1":  "sha256:r1",
+		"1.0.0-rc.2":  "sha256:a", // 与 1.0.0 为同一清单
+		"1.0.0-rc.3":  "sha256:r3",
+		"1.1.0-rc.1":  "sha256:n1", // 高于最新正式版本
+		"1.0.0-beta":  "sha256:b",
+		"latest":      "sha256:a",
+		"v1.0.0-rc.9": "sha256:x", // 前缀不匹配
+	}
+	f, ref := newTagRegistry(t, tags)
+	client, _ := New(Config{PlainHTTP: true})
+
+	stale, err := client.PrunePrereleases(ref, PruneOptions{Keep: 1, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(stale, []string{"1.0.0-rc.1", "1.0.0-beta"}) || len(f.deleted) != 0 {
+		t.Errorf("expected rc.3 kept, rc.2 sharing the release manifest skipped and nothing deleted, but %v and %v got", stale, f.deleted)
+	}
+
+	if _, err = client.PrunePrereleases(ref, PruneOptions{Keep: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(f.deleted, []string{"/v2/org/app/manifests/sha256:r1", "/v2/org/app/manifests/sha256:b"}) {
+		t.Errorf("expected manifests deleted by digest, but %v got", f.deleted)
+	}
+}
+
+func TestClient_DeleteTag(t *testing.T) {
+	f, ref := newTagRegistry(t, map[string]string{"1.0.0-rc.1": "sha256:r1"})
+	ref.Repository, ref.Tag = "library/team/app", "1.0.0-rc.1"
+	client, _ := New(Config{PlainHTTP: true, Credentials: []Credential{{Registry: ref.Registry, Type: TypeHarbor, Username: "robot$library+ci"}}})
+	if client.Type(ref.Registry) != TypeHarbor || !client.untags(ref.Registry) {
+		t.Fatalf("expected harbor registry, but %s got", client.Type(ref.Registry))
+	}
+	if err := client.DeleteTag(ref); err != nil {
+		t.Fatal(err)
+	}
+	expected := "/api/v2.0/projects/library/repositories/team%2Fapp/artifacts/1.0.0-rc.1/tags/1.0.0-rc.1"
+	if len(f.deleted) != 1 || f.deleted[0] != expected {
+		t.Errorf("expected %s deleted, but %v got", expected, f.deleted)
+	}
+}
+
+func TestClient_Type(t *testing.T) {
+	client, _ := New(Config{})
+	for host, expected := range map[string]string{
+		"docker.io": TypeDockerHub,
+		"123456789012.dkr.ecr.us-east-1.amazonaws.com": TypeECR,
+		"gcr.io":                     TypeGCR,
+		"eu.gcr.io":                  TypeGCR,
+		"us-central1-docker.pkg.dev": TypeGCR,
+		"example.azurecr.io":         TypeACR,
+		"ghcr.io":                    TypeGeneric,
+		"harbor.example.com":         TypeGeneric,
+	} {
+		if actual := client.Type(host); actual != expected {
+			t.Errorf("expected %s for %s, but %s got", expected, host, actual)
+		}
+	}
+}
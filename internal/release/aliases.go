@@ -0,0 +1,152 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/retry"
+	"github.com/coffee377/autoctl/internal/tags"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// 浮动标签的种类
+const (
+	AliasMajor  = "major"  // 主版本内最新的正式版本，如 v1
+	AliasMinor  = "minor"  // 次版本内最新的正式版本，如 v1.2
+	AliasStable = "stable" // 最新的正式版本
+)
+
+// DefaultStableAlias stable 浮动标签的默认名称
+const DefaultStableAlias = "stable"
+
+// AliasNames 所有浮动标签种类的名称
+func AliasNames() []string {
+	return []string{AliasMajor, AliasMinor, AliasStable}
+}
+
+// AliasesConfig 发布时移动的浮动标签，对应配置文件 release.aliases 节点，
+// 只有发布的版本为浮动标签范围内的最新正式版本时才移动，维护分支上发布旧版本线的补丁不会移动 stable
+type AliasesConfig struct {
+	Names  []string `mapstructure:"names"`  // 维护的浮动标签 major | minor | stable，为空时不维护
+	Stable string   `mapstructure:"stable"` // stable 浮动标签的名称，默认 stable
+}
+
+// Enabled 是否维护浮动标签
+func (c AliasesConfig) Enabled() bool {
+	return len(c.Names) > 0
+}
+
+// Validate 检查浮动标签种类
+func (c AliasesConfig) Validate() error {
+	for _, name := range c.Names {
+		switch name {
+		case AliasMajor, AliasMinor, AliasStable:
+		default:
+			return fmt.Errorf("invalid alias %q, valid values are %s", name, strings.Join(AliasNames(), "|"))
+		}
+	}
+	return nil
+}
+
+func (c AliasesConfig) has(kind string) bool {
+	for _, name := range c.Names {
+		if name == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Alias 发布时移动的浮动标签
+type Alias struct {
+	Kind   string `json:"kind"`   // 种类 major | minor | stable
+	Tag    string `json:"tag"`    // git 标签名称，如 v1、v1.2、stable
+	Docker string `json:"docker"` // 对应的镜像标签，如 1、1.2、latest
+}
+
+// Aliases 计算发布 next 后需要移动的浮动标签，versions 为已有的全部版本；
+// 预发布版本不移动任何浮动标签，已有更大的正式版本时不移动对应范围的浮动标签
+func Aliases(c AliasesConfig, prefix string, next semver.Semver, versions []semver.Semver) []Alias {
+	if len(next.PreRelease()) > 0 {
+		return nil
+	}
+	latestMajor, latestMinor, latestStable := true, true, true
+	for _, v := range versions {
+		if len(v.PreRelease()) > 0 || v.Compare(next) <= 0 {
+			continue
+		}
+		latestStable = false
+		if v.Major() == next.Major() {
+			latestMajor = false
+			if v.Minor() == next.Minor() {
+				latestMinor = false
+			}
+		}
+	}
+	var aliases []Alias
+	if c.has(AliasMajor) && latestMajor {
+		major := next.Format(semver.LayoutMajor)
+		aliases = append(aliases, Alias{Kind: AliasMajor, Tag: prefix + major, Docker: major})
+	}
+	if c.has(AliasMinor) && latestMinor {
+		minor := next.Format(semver.LayoutMajorMinor)
+		aliases = append(aliases, Alias{Kind: AliasMinor, Tag: prefix + minor, Docker: minor})
+	}
+	if c.has(AliasStable) && latestStable {
+		stable := c.Stable
+		if stable == "" {
+			stable = DefaultStableAlias
+		}
+		aliases = append(aliases, Alias{Kind: AliasStable, Tag: stable, Docker: "latest"})
+	}
+	return aliases
+}
+
+// IsStable 浮动标签中是否包含 stable，即发布的版本为最新的正式版本
+func IsStable(aliases []Alias) bool {
+	for _, a := range aliases {
+		if a.Kind == AliasStable {
+			return true
+		}
+	}
+	return false
+}
+
+// aliases 按已有的版本标签计算本次发布需要移动的浮动标签
+func (r *Releaser) aliases(next semver.Semver) ([]Alias, error) {
+	list, _, err := tags.List(r.git, r.opts.TagPrefix)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]semver.Semver, 0, len(list))
+	for _, t := range list {
+		versions = append(versions, t.Version)
+	}
+	return Aliases(r.opts.Aliases, r.opts.TagPrefix, next, versions), nil
+}
+
+// moveAliases 将浮动标签移动到本次发布的提交，并以原子方式强制推送到所有远程仓库
+func (r *Releaser) moveAliases(res *Result, push bool) error {
+	names := make([]string, 0, len(res.Aliases))
+	for _, a := range res.Aliases {
+		if err := r.git.MoveTag(a.Tag, res.Tag); err != nil {
+			return fmt.Errorf("release %s succeeded but move alias %s: %w", res.Tag, a.Tag, err)
+		}
+		names = append(names, a.Tag)
+	}
+	log.Info("moved aliases %s to %s", strings.Join(names, ", "), res.Tag)
+	if !push {
+		return nil
+	}
+	for _, remote := range r.Remotes() {
+		err := retry.Do(r.opts.Retry, "push aliases to "+remote, func() error {
+			return r.git.ForcePushTags(remote, names...)
+		})
+		if err != nil {
+			return fmt.Errorf("release %s succeeded but push aliases to %s: %w", res.Tag, remote, err)
+		}
+		log.Info("pushed aliases %s to %s", strings.Join(names, ", "), remote)
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package release
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+func TestAliases(t *testing.T) {
+	var versions []semver.Semver
+	for _, s := range []string{"1.2.0", "1.3.0", "2.0.0", "2.1.0-rc.1"} {
+		v, _ := semver.Version(s)
+		versions = append(versions, v)
+	}
+	cfg := AliasesConfig{Names: []string{AliasMajor, AliasMinor, AliasStable}}
+	tests := []struct {
+		next string
+		want []string
+	}{
+		{"2.0.1", []string{"v2", "v2.0", "stable"}},
+		{"1.3.1", []string{"v1", "v1.3"}},
+		{"1.2.1", []string{"v1.2"}},
+		{"2.1.0-rc.2", nil},
+	}
+	for _, tt := range tests {
+		next, _ := semver.Version(tt.next)
+		var got []string
+		for _, a := range Aliases(cfg, "v", next, versions) {
+			got = append(got, a.Tag)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Aliases(%s) = %v, want %v", tt.next, got, tt.want)
+		}
+	}
+	if err := (AliasesConfig{Names: []string{"latest"}}).Validate(); err == nil {
+		t.Error("expected invalid alias error, got nil")
+	}
+}
+
+func TestReleaser_Aliases(t *testing.T) {
+	repo := newRepo(t, "origin")
+	opts := Options{Cwd: repo, Push: true, Aliases: AliasesConfig{Names: []string{AliasMajor, AliasStable}}}
+	if _, err := New(opts).Release(); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "fix: bug")
+	res, err := New(opts).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tag != "v0.1.1" {
+		t.Fatalf("expected floating tags ignored when computing the version, but %s got", res.Tag)
+	}
+	head := strings.TrimSpace(gitRun(t, repo, "rev-parse", "HEAD"))
+	origin := filepath.Join(filepath.Dir(repo), "origin.git")
+	for _, alias := range []string{"v0", "stable"} {
+		if got := strings.TrimSpace(gitRun(t, origin, "rev-parse", alias)); got != head {
+			t.Errorf("expected %s moved to %s, but %s got", alias, head, got)
+		}
+	}
+	if res.Steps[StepAliases].IsZero() {
+		t.Error("expected aliases step recorded")
+	}
+}
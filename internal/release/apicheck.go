@@ -0,0 +1,136 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/impact"
+	"github.com/coffee377/autoctl/internal/schema"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// 存在不兼容的 API 变更但版本变动不足时的处理方式
+const (
+	OnBreakingFail     = "fail"     // 发布失败
+	OnBreakingEscalate = "escalate" // 提升为 major，0.x 版本提升为 minor
+)
+
+// maxListedFindings 错误信息中列出的不兼容变更数量
+const maxListedFindings = 5
+
+// APICheckConfig 发布前检查导出 Go API 的兼容性，对应配置文件 release.apiCheck 节点
+type APICheckConfig struct {
+	Enabled    bool           `mapstructure:"enabled"`    // 比较上一个版本与 HEAD 的导出 Go 符号，删除或修改导出符号视为不兼容变更
+	OnBreaking string         `mapstructure:"onBreaking"` // 不兼容变更只递增 minor 或 patch 时的处理方式 fail | escalate，默认 fail
+	Schemas    []schema.Check `mapstructure:"schemas"`    // OpenAPI、protobuf 等接口定义的兼容性检查，配置后与 enabled 无关，结果同时用于按代码差异推断版本
+}
+
+// Validate 检查处理方式及接口定义检查
+func (c APICheckConfig) Validate() error {
+	switch c.OnBreaking {
+	case "", OnBreakingFail, OnBreakingEscalate:
+	default:
+		return fmt.Errorf("invalid apiCheck onBreaking %q, valid values are %s|%s", c.OnBreaking, OnBreakingFail, OnBreakingEscalate)
+	}
+	for _, s := range c.Schemas {
+		if err := s.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c APICheckConfig) active() bool {
+	return c.Enabled || len(c.Schemas) > 0
+}
+
+// allowsBreaking 从 current 到 next 是否允许不兼容的变更：主版本递增，0.x 版本次版本递增，
+// 或仍在开发中的主版本（0.x 为次版本）的预发布版本，如 2.0.0-rc.1 => 2.0.0-rc.2
+func allowsBreaking(current, next semver.Semver) bool {
+	if next.Major() > current.Major() {
+		return true
+	}
+	if current.Major() == 0 && next.Minor() > current.Minor() {
+		return true
+	}
+	return len(current.PreRelease()) > 0 && current.Patch() == 0 && (current.Major() == 0 || current.Minor() == 0)
+}
+
+// escalated 允许不兼容变更的版本变动类型，保留预发布
+func escalated(changed semver.VersionChanged, current semver.Semver) semver.VersionChanged {
+	prerelease := changed == semver.PreMajor || changed == semver.PreMinor || changed == semver.PrePatch || changed == semver.PreRelease
+	switch {
+	case current.Major() == 0 && prerelease:
+		return semver.PreMinor
+	case current.Major() == 0:
+		return semver.Minor
+	case prerelease:
+		return semver.PreMajor
+	default:
+		return semver.Major
+	}
+}
+
+// incompatible from 与 HEAD 之间不兼容的导出 Go API 及接口定义变更
+func (r *Releaser) incompatible(from string) ([]string, error) {
+	var changes []string
+	if r.opts.APICheck.Enabled {
+		findings, err := impact.Incompatible(r.git, from, "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range findings {
+			symbol := f.Symbol
+			if f.Package != "." {
+				symbol = f.Package + "." + symbol
+			}
+			changes = append(changes, f.Kind+" "+symbol)
+		}
+	}
+	if len(r.opts.APICheck.Schemas) > 0 {
+		report, err := schema.Compare(r.git, r.opts.APICheck.Schemas, from, "HEAD")
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range report.Breaking() {
+			changes = append(changes, c.String())
+		}
+	}
+	return changes, nil
+}
+
+// listed 错误信息中列出的不兼容变更
+func listed(changes []string) string {
+	if len(changes) > maxListedFindings {
+		return strings.Join(changes[:maxListedFindings], ", ") + fmt.Sprintf(", and %d more", len(changes)-maxListedFindings)
+	}
+	return strings.Join(changes, ", ")
+}
+
+// checkAPI 版本变动不允许不兼容变更时检查导出 Go API 及接口定义，存在不兼容变更时按配置失败或提升版本变动类型
+func (r *Releaser) checkAPI(current semver.Semver) error {
+	if !r.opts.APICheck.active() || current == nil {
+		return nil
+	}
+	next, err := r.Next(current)
+	if err != nil {
+		return err
+	}
+	if allowsBreaking(current, next) {
+		return nil
+	}
+	from := r.TagName(current)
+	changes, err := r.incompatible(from)
+	if err != nil || len(changes) == 0 {
+		return err
+	}
+	if r.opts.APICheck.OnBreaking != OnBreakingEscalate {
+		return fmt.Errorf("%d incompatible api changes since %s require a major release, but got %s: %s",
+			len(changes), from, r.opts.Release, listed(changes))
+	}
+	changed := escalated(r.opts.Release, current)
+	log.Warn("%d incompatible api changes since %s, escalate release %s to %s: %s", len(changes), from, r.opts.Release, changed, listed(changes))
+	r.opts.Release = changed
+	return nil
+}
@@ -0,0 +1,106 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/impact"
+	"github.com/coffee377/autoctl/internal/schema"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+func TestAllowsBreaking(t *testing.T) {
+	for _, c := range []struct {
+		current, next string
+		want          bool
+	}{
+		{"1.2.3", "2.0.0", true},
+		{"1.2.3", "1.3.0", false},
+		{"1.2.3", "1.2.4", false},
+		{"0.2.3", "0.3.0", true},
+		{"0.2.3", "0.2.4", false},
+		{"2.0.0-rc.1", "2.0.0-rc.2", true},
+		{"1.3.0-rc.1", "1.3.0", false},
+		{"0.3.0-rc.1", "0.3.0", true},
+	} {
+		current, _ := semver.Version(c.current)
+		next, _ := semver.Version(c.next)
+		if got := allowsBreaking(current, next); got != c.want {
+			t.Errorf("allowsBreaking(%s, %s) = %v, want %v", c.current, c.next, got, c.want)
+		}
+	}
+}
+
+func TestReleaser_APICheck(t *testing.T) {
+	repo := newRepo(t)
+	write := func(content string) {
+		if err := os.WriteFile(filepath.Join(repo, "api.go"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		gitRun(t, repo, "add", ".")
+	}
+	write("package api\n\nfunc Parse() {}\n\nfunc Format() {}\n")
+	gitRun(t, repo, "commit", "-q", "-m", "feat: api")
+	gitRun(t, repo, "tag", "v1.0.0")
+	write("package api\n\nfunc Parse(strict bool) {}\n")
+	gitRun(t, repo, "commit", "-q", "-m", "fix: strict parse")
+
+	_, err := New(Options{Cwd: repo, APICheck: APICheckConfig{Enabled: true}}).Release()
+	if err == nil || !strings.Contains(err.Error(), "removed Format") || !strings.Contains(err.Error(), "changed Parse") {
+		t.Fatalf("expected incompatible api changes failed the patch release, but %v got", err)
+	}
+
+	res, err := New(Options{Cwd: repo, DryRun: true, APICheck: APICheckConfig{Enabled: true, OnBreaking: OnBreakingEscalate}}).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tag != "v2.0.0" {
+		t.Fatalf("expected escalated to v2.0.0, but %s got", res.Tag)
+	}
+
+	res, err = New(Options{Cwd: repo, DryRun: true, Release: semver.Major, APICheck: APICheckConfig{Enabled: true}}).Release()
+	if err != nil || res.Tag != "v2.0.0" {
+		t.Fatalf("expected major release allowed, but %v %v got", res, err)
+	}
+}
+
+func TestReleaser_SchemaCheck(t *testing.T) {
+	repo := newRepo(t)
+	write := func(content string) {
+		if err := os.WriteFile(filepath.Join(repo, "openapi.yaml"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		gitRun(t, repo, "add", ".")
+	}
+	write("openapi: 3.0.0\npaths:\n  /users:\n    get: {}\n")
+	gitRun(t, repo, "commit", "-q", "-m", "feat: users")
+	gitRun(t, repo, "tag", "v1.0.0")
+	write("openapi: 3.0.0\npaths:\n  /users:\n    get: {}\n    post: {}\n")
+	gitRun(t, repo, "commit", "-q", "-m", "Add user creation (#7)")
+	checks := APICheckConfig{Schemas: []schema.Check{{Type: schema.TypeOpenAPI, Files: []string{"*.yaml"}}}}
+
+	// 接口定义新增接口时按代码差异推断为 minor
+	res, err := New(Options{Cwd: repo, DryRun: true, APICheck: checks, Impact: impact.Config{Enabled: true}}).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tag != "v1.1.0" {
+		t.Fatalf("expected minor release inferred from the schema, but %s got", res.Tag)
+	}
+
+	write("openapi: 3.0.0\npaths:\n  /users:\n    post: {}\n")
+	gitRun(t, repo, "commit", "-q", "-m", "fix: drop listing")
+	_, err = New(Options{Cwd: repo, DryRun: true, Release: semver.Minor, APICheck: checks}).Release()
+	if err == nil || !strings.Contains(err.Error(), "removed openapi.yaml:GET /users") {
+		t.Fatalf("expected removed operation failed the minor release, but %v got", err)
+	}
+	failed := false
+	for _, r := range New(Options{Cwd: repo, Release: semver.Minor, APICheck: checks}).Readiness() {
+		failed = failed || r.Name == "api compatibility" && !r.Passed
+	}
+	if !failed {
+		t.Error("expected api compatibility check failed")
+	}
+}
@@ -0,0 +1,53 @@
+package release
+
+import (
+	"sort"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/audit"
+	"github.com/coffee377/autoctl/internal/lock"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// AuditLog 审计日志后端，git 后端的引用保存在发布的远程仓库中，远程仓库不存在时只保存在本地
+func (r *Releaser) AuditLog() (audit.Log, error) {
+	remote := r.opts.Remote
+	if _, err := r.git.RemoteURL(remote); err != nil {
+		remote = ""
+	}
+	return audit.New(r.opts.Audit, r.git, remote)
+}
+
+// Audit 启用审计日志时记录一次发布操作，e 中需设置操作及参数，未设置触发者时自动识别，其余字段根据发布结果填充；记录失败只输出警告
+func (r *Releaser) Audit(e *audit.Entry, res *Result, err error) {
+	if !r.opts.Audit.Enabled {
+		return
+	}
+	e.Time = time.Now().UTC()
+	if e.Actor == "" {
+		e.Actor = audit.Actor(r.git)
+	}
+	e.Runner = lock.Owner()
+	e.DryRun = e.DryRun || r.opts.DryRun
+	e.Status = audit.StatusSuccess
+	if res != nil {
+		e.Version, e.Tag, e.Commit = res.Version.String(), res.Tag, res.Commit
+		for step := range res.Steps {
+			e.Steps = append(e.Steps, step)
+		}
+		sort.Slice(e.Steps, func(i, j int) bool { return res.Steps[e.Steps[i]].Before(res.Steps[e.Steps[j]]) })
+		if res.Skipped {
+			e.Status = audit.StatusSkipped
+		}
+	}
+	if err != nil {
+		e.Status, e.Error = audit.StatusFailed, log.Redact(err.Error())
+	}
+	l, err := r.AuditLog()
+	if err == nil {
+		err = l.Append(e)
+	}
+	if err != nil {
+		log.Warn("write audit log: %s", err)
+	}
+}
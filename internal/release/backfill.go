@@ -0,0 +1,105 @@
+package release
+
+import (
+	"time"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// Backfill 为所有历史版本标签生成变更日志，按版本从新到旧排列；
+// skipPrerelease 为 true 时忽略预发布版本，其变更归入之后的正式版本
+func (r *Releaser) Backfill(skipPrerelease bool) ([]*changelog.Changelog, error) {
+	tags, err := r.git.Tags(r.opts.TagPrefix + "*")
+	if err != nil {
+		return nil, err
+	}
+	dates, err := r.git.TagDates(r.opts.TagPrefix + "*")
+	if err != nil {
+		return nil, err
+	}
+	versions := make(semver.Versions, 0, len(tags))
+	for _, tag := range tags {
+		v, err := r.ParseTag(tag)
+		if err != nil {
+			log.Debug("skip tag %s: %s", tag, err)
+			continue
+		}
+		if skipPrerelease && len(v.PreRelease()) > 0 {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	versions.SortAsc()
+	logs := make([]*changelog.Changelog, len(versions))
+	for i, v := range versions {
+		from, previous := "", ""
+		if i > 0 {
+			from, previous = r.TagName(versions[i-1]), versions[i-1].String()
+		}
+		tag := r.TagName(v)
+		records, err := r.logs(from, tag)
+		if err != nil {
+			return nil, err
+		}
+		cl := changelog.Build(v.String(), previous, dates[tag], records, changelog.Options{Bots: r.opts.Bots})
+		if err = r.addLinks(cl, from, tag); err != nil {
+			return nil, err
+		}
+		logs[len(versions)-1-i] = cl
+	}
+	return logs, nil
+}
+
+// VersionChangelog 生成 version 相对于上一个版本标签的变更日志，version 的标签不存在时统计到 HEAD
+func (r *Releaser) VersionChangelog(version semver.Semver) (*changelog.Changelog, error) {
+	tags, err := r.git.Tags(r.opts.TagPrefix + "*")
+	if err != nil {
+		return nil, err
+	}
+	var previous semver.Semver
+	for _, tag := range tags {
+		v, err := r.ParseTag(tag)
+		if err != nil || v.Compare(version) >= 0 {
+			continue
+		}
+		if previous == nil || v.Compare(previous) > 0 {
+			previous = v
+		}
+	}
+	from, prev := "", ""
+	if previous != nil {
+		from, prev = r.TagName(previous), previous.String()
+	}
+	to := r.TagName(version)
+	if !r.git.TagExists(to) {
+		to = "HEAD"
+	}
+	records, err := r.logs(from, to)
+	if err != nil {
+		return nil, err
+	}
+	cl := changelog.Build(version.String(), prev, time.Now().Format("2006-01-02"), records, changelog.Options{Bots: r.opts.Bots})
+	if err = r.addLinks(cl, from, r.TagName(version)); err != nil {
+		return nil, err
+	}
+	return cl, nil
+}
+
+// logs 获取两个版本之间的提交，并按 convention 配置转换为约定式提交
+func (r *Releaser) logs(from, to string) ([]*commit.CommitRecord, error) {
+	parser, err := r.opts.Convention.Compile()
+	if err != nil {
+		return nil, err
+	}
+	records, err := changelog.Collect(r.git, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, record := range records {
+		parser.Normalize(record)
+	}
+	return records, nil
+}
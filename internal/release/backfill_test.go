@@ -0,0 +1,62 @@
+package release
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+)
+
+func TestReleaser_Backfill(t *testing.T) {
+	repo := newRepo(t)
+	commit := func(message, date string) {
+		t.Setenv("GIT_COMMITTER_DATE", date+"T12:00:00")
+		gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", message)
+	}
+	commit("feat: add plan", "2023-01-10")
+	gitRun(t, repo, "tag", "v1.0.0")
+	commit("fix: handle empty tags", "2023-02-01")
+	gitRun(t, repo, "tag", "-a", "v1.0.1-rc.0", "-m", "rc")
+	commit("fix: retry push", "2023-02-03")
+	t.Setenv("GIT_COMMITTER_DATE", "2023-02-05T12:00:00")
+	gitRun(t, repo, "tag", "-a", "v1.0.1", "-m", "release v1.0.1")
+	gitRun(t, repo, "tag", "not-a-version")
+
+	logs, err := New(Options{Cwd: repo}).Backfill(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(logs) != 3 || logs[0].Version != "1.0.1" || logs[1].Version != "1.0.1-rc.0" || logs[2].Version != "1.0.0" {
+		t.Fatalf("expected 3 releases from newest to oldest, but %v got", logs)
+	}
+	if logs[0].Date != "2023-02-05" || logs[2].Date != "2023-01-10" {
+		t.Errorf("expected dates from tag metadata, but %s and %s got", logs[0].Date, logs[2].Date)
+	}
+
+	logs, err = New(Options{Cwd: repo}).Backfill(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	short := func(ref string) string {
+		return strings.TrimSpace(gitRun(t, repo, "rev-parse", "--short=7", ref+"^{commit}"))
+	}
+	expected := `# Changelog
+
+## 1.0.1 (2023-02-05)
+
+### Bug Fixes
+
+* retry push (` + short("v1.0.1") + `)
+* handle empty tags (` + short("v1.0.1~1") + `)
+
+## 1.0.0 (2023-01-10)
+
+### Features
+
+* add plan (` + short("v1.0.0") + `)
+* init (` + short("v1.0.0~1") + `)
+`
+	if actual := changelog.Document(logs); actual != expected {
+		t.Errorf("\nExpected: \n%s\nActual: \n%s\n", expected, actual)
+	}
+}
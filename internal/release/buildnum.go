@@ -0,0 +1,32 @@
+package release
+
+import (
+	"github.com/coffee377/autoctl/internal/buildnum"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// BuildCounter 构建号后端，git 后端的 notes 保存在发布的远程仓库中
+func (r *Releaser) BuildCounter() (buildnum.Counter, error) {
+	var p provider.Provider
+	if r.opts.BuildNumber.Backend == buildnum.BackendProvider {
+		var err error
+		if p, err = provider.New(r.opts.Provider); err != nil {
+			return nil, err
+		}
+	}
+	return buildnum.New(r.opts.BuildNumber, r.git, r.opts.Remote, p)
+}
+
+// allocateBuildNumber 为本次发布分配构建号
+func (r *Releaser) allocateBuildNumber(res *Result) error {
+	counter, err := r.BuildCounter()
+	if err != nil {
+		return err
+	}
+	if res.BuildNumber, err = counter.Next(); err != nil {
+		return err
+	}
+	log.Info("allocated build number %d", res.BuildNumber)
+	return nil
+}
@@ -0,0 +1,181 @@
+package release
+
+import (
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/impact"
+	"github.com/coffee377/autoctl/internal/workspace"
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// ImpactNone 不影响版本的提交
+const ImpactNone = "none"
+
+// Classification 待发布提交的分类报告，用于排查版本变动类型的推断结果
+type Classification struct {
+	From    string         `json:"from,omitempty"` // 上一个版本的标签，首次发布时为空
+	Release string         `json:"release"`        // 按提交推断的版本变动类型
+	Commits []Decision     `json:"commits"`
+	Impact  *impact.Report `json:"impact,omitempty"` // 提交都不影响版本时按代码差异推断的版本变动类型
+}
+
+// Classify 按变更日志规则对提交分类，并给出每个提交推断的发布影响及匹配的规则
+func Classify(records []*commit.CommitRecord, bots changelog.Bots) []Decision {
+	opts := changelog.Options{Bots: bots}
+	decisions := make([]Decision, 0, len(records))
+	for _, record := range records {
+		c, ok := changelog.Visible(record, opts)
+		d := Decision{Hash: c.Hash, Subject: c.Subject, Type: c.Type, Scope: c.Scope, Breaking: c.Breaking}
+		if d.Subject == "" {
+			d.Subject = strings.SplitN(strings.TrimSpace(record.RawMessage), "\n", 2)[0]
+		}
+		if ok {
+			d.Section = c.Type
+		}
+		d.Impact, d.Rule = commitImpact(record, c, ok, bots)
+		decisions = append(decisions, d)
+	}
+	return decisions
+}
+
+// ApplyPaths 按提交修改的文件调整发布影响：修改 force 路径且不影响版本的提交至少为 patch，
+// 只修改 ignore 路径的提交不影响版本，files 为提交 => 修改的文件，不在其中的提交（如合并提交）保持不变
+func ApplyPaths(decisions []Decision, files map[string][]string, rules workspace.PathRules) []Decision {
+	for i, d := range decisions {
+		changed, ok := files[d.Hash]
+		if !ok {
+			continue
+		}
+		if pattern, forced := rules.Forced(changed); forced {
+			if d.Impact == ImpactNone {
+				decisions[i].Impact, decisions[i].Rule = semver.Patch.String(), "path "+pattern+" forces patch"
+			}
+			continue
+		}
+		if d.Impact != ImpactNone && rules.Ignored(changed) {
+			decisions[i].Impact, decisions[i].Rule = ImpactNone, d.Rule+", only ignored paths changed"
+		}
+	}
+	return decisions
+}
+
+// commitImpact 单个提交的发布影响及决定影响的规则，规则按破坏性变更、依赖更新、提交类型的顺序匹配
+func commitImpact(record *commit.CommitRecord, c changelog.Commit, visible bool, bots changelog.Bots) (string, string) {
+	if c.Breaking {
+		if c.BreakingNote != "" {
+			return semver.Major.String(), "BREAKING CHANGE footer"
+		}
+		return semver.Major.String(), "breaking marker !"
+	}
+	if rule, ok := bots.MatchRule(record); ok {
+		if !bots.Releasable() {
+			return ImpactNone, rule + ", bots release none"
+		}
+		return semver.Patch.String(), rule
+	}
+	switch {
+	case c.Type == "feat":
+		return semver.Minor.String(), "type feat"
+	case visible:
+		return semver.Patch.String(), "type " + c.Type
+	case c.Type != "":
+		return ImpactNone, "type " + c.Type + " not in changelog"
+	default:
+		return ImpactNone, "not a conventional commit"
+	}
+}
+
+// infer 取分类结果中最大的发布影响，没有影响版本的提交时为 patch
+func infer(decisions []Decision) semver.VersionChanged {
+	changed := semver.Patch
+	for _, d := range decisions {
+		switch d.Impact {
+		case semver.Major.String():
+			return semver.Major
+		case semver.Minor.String():
+			changed = semver.Minor
+		}
+	}
+	return changed
+}
+
+// classified 自 current 以来的提交分类及按提交推断的版本变动类型，结果只计算一次，
+// classify、合并请求标签及代码差异推断读取同一个推断结果
+func (r *Releaser) classified(current semver.Semver) (*Classification, error) {
+	if r.pending != nil {
+		return r.pending, nil
+	}
+	from := ""
+	if current != nil {
+		from = r.TagName(current)
+	}
+	records, err := r.collect(from)
+	if err != nil {
+		return nil, err
+	}
+	decisions, err := r.classify(from, records)
+	if err != nil {
+		return nil, err
+	}
+	r.records, r.inferred = records, infer(decisions)
+	r.pending = &Classification{From: from, Release: r.inferred.String(), Commits: decisions}
+	return r.pending, nil
+}
+
+// Pending 自上一个版本以来的提交分类报告
+func (r *Releaser) Pending() (*Classification, error) {
+	current, err := r.Current()
+	if err != nil {
+		return nil, err
+	}
+	pending, err := r.classified(current)
+	if err != nil {
+		return nil, err
+	}
+	c := *pending
+	if c.Impact, err = r.analyze(c.From, c.Commits); err != nil {
+		return nil, err
+	}
+	if r.opts.Impact.Accept(c.Impact) {
+		c.Release = c.Impact.Release
+	}
+	return &c, nil
+}
+
+// PendingChangelog 按自上一个版本以来分类的提交生成下一个版本的变更日志，版本变动类型与 classify 相同，
+// 不读取合并请求标签，也不执行摘要钩子、推送校验及发布策略，用于只读的变更日志预览
+func (r *Releaser) PendingChangelog() (*changelog.Changelog, error) {
+	if !r.explicit {
+		c, err := r.Pending()
+		if err != nil {
+			return nil, err
+		}
+		if r.opts.Release, err = semver.ParseReleaseType(c.Release); err != nil {
+			return nil, err
+		}
+	}
+	current, err := r.Current()
+	if err != nil {
+		return nil, err
+	}
+	pending, err := r.classified(current)
+	if err != nil {
+		return nil, err
+	}
+	next, err := r.Next(current)
+	if err != nil {
+		return nil, err
+	}
+	previous := ""
+	if current != nil {
+		previous = current.String()
+	}
+	cl := changelog.Build(next.String(), previous, time.Now().Format("2006-01-02"), r.records, changelog.Options{Bots: r.opts.Bots})
+	if err = r.addLinks(cl, pending.From, r.TagName(next)); err != nil {
+		return nil, err
+	}
+	return cl, nil
+}
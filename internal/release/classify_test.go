@@ -0,0 +1,125 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/convention"
+	"github.com/coffee377/autoctl/internal/impact"
+	"github.com/coffee377/autoctl/internal/workspace"
+)
+
+func TestReleaser_Pending(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.0.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "fix(api): crash")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "chore: tidy")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: export\n\nBREAKING CHANGE: drop csv")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "Bump lodash from 4.17.20 to 4.17.21")
+
+	c, err := New(Options{Cwd: repo}).Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.From != "v1.0.0" || c.Release != "major" || len(c.Commits) != 4 {
+		t.Fatalf("unexpected classification %+v", c)
+	}
+	want := map[string][2]string{
+		"crash":                               {"patch", "type fix"},
+		"tidy":                                {"none", "type chore not in changelog"},
+		"export":                              {"major", "BREAKING CHANGE footer"},
+		"Bump lodash from 4.17.20 to 4.17.21": {"patch", "bot pattern ^Bump \\S+ from \\S+ to \\S+"},
+	}
+	for _, d := range c.Commits {
+		if w := want[d.Subject]; d.Impact != w[0] || d.Rule != w[1] {
+			t.Errorf("expected %s classified %v, but %s %q got", d.Subject, w, d.Impact, d.Rule)
+		}
+	}
+}
+
+func TestReleaser_PendingConvention(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.0.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "[BUGFIX] crash")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", ":sparkles: export")
+
+	opts := Options{Cwd: repo, DryRun: true, Convention: convention.Grammar{Presets: []string{convention.PresetGitmoji, convention.PresetBracket}}}
+	c, err := New(opts).Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Release != "minor" {
+		t.Fatalf("expected minor inferred from gitmoji, but %s got", c.Release)
+	}
+	for _, d := range c.Commits {
+		if d.Subject == "crash" && d.Type != "fix" {
+			t.Errorf("expected [BUGFIX] mapped to fix, but %s got", d.Type)
+		}
+	}
+	// 未指定版本变动类型时发布与 classify 采用同一个推断结果
+	if res, err := New(opts).Release(); err != nil || res.Tag != "v1.1.0" {
+		t.Fatalf("expected v1.1.0 inferred like classify, but %v %v got", res, err)
+	}
+}
+
+func TestReleaser_PendingPaths(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.0.0")
+	commitFile := func(name, message string) {
+		t.Helper()
+		if err := os.MkdirAll(filepath.Join(repo, filepath.Dir(name)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(repo, name), []byte(message), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		gitRun(t, repo, "add", name)
+		gitRun(t, repo, "commit", "-q", "-m", message)
+	}
+	commitFile("docs/guide/intro.md", "feat: document plans")
+	commitFile(".github/workflows/ci.yml", "fix: ci cache")
+
+	rules := Options{Cwd: repo, OnNoChange: NoChangeSkip, Paths: workspace.PathRules{Ignore: []string{"docs/**", "*.md", ".github/**"}, Force: []string{"migrations/**"}}}
+	c, err := New(rules).Pending()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range c.Commits {
+		if d.Impact != ImpactNone {
+			t.Errorf("expected %s ignored, but %s %q got", d.Subject, d.Impact, d.Rule)
+		}
+	}
+	if res, err := New(rules).Release(); err != nil || !res.Skipped {
+		t.Fatalf("expected release skipped with only ignored paths changed, but %v %v got", res, err)
+	}
+
+	commitFile("migrations/0002_plans.sql", "chore: add plans table")
+	if c, err = New(rules).Pending(); err != nil {
+		t.Fatal(err)
+	}
+	if c.Release != "patch" || c.Commits[0].Impact != "patch" || c.Commits[0].Rule != "path migrations/** forces patch" {
+		t.Fatalf("expected migration forcing patch, but %+v got", c.Commits[0])
+	}
+	if res, err := New(rules).Release(); err != nil || res.Tag != "v1.0.1" {
+		t.Fatalf("expected v1.0.1 released, but %v %v got", res, err)
+	}
+}
+
+func TestReleaser_Impact(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.0.0")
+	if err := os.WriteFile(filepath.Join(repo, "api.go"), []byte("package api\n\nfunc Parse() {}\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "commit", "-q", "-m", "Add parser (#12)")
+
+	res, err := New(Options{Cwd: repo, Impact: impact.Config{Enabled: true}}).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tag != "v1.1.0" || res.Impact == nil || res.Impact.Release != "minor" {
+		t.Fatalf("expected minor release inferred from the diff, but %s %+v got", res.Tag, res.Impact)
+	}
+}
@@ -0,0 +1,117 @@
+package release
+
+import (
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/codeowners"
+)
+
+// ComponentsConfig 按 CODEOWNERS 将变更归属到组件，对应配置文件 release.components 节点
+type ComponentsConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // 在发布说明中按组件列出变更
+	File    string `mapstructure:"file"`    // CODEOWNERS 路径，默认依次查找 CODEOWNERS、.github/CODEOWNERS、.gitlab/CODEOWNERS、docs/CODEOWNERS
+	Notify  bool   `mapstructure:"notify"`  // 在发布说明及发布事件中提及各组件的负责团队
+	Teams   []Team `mapstructure:"teams"`   // 负责人与组件的对应关系，未配置的负责人以自身作为组件名称
+}
+
+// Team 负责人对应的组件及通知方式
+type Team struct {
+	Owner     string   `mapstructure:"owner"`     // CODEOWNERS 中的负责人，如 @org/payments
+	Component string   `mapstructure:"component"` // 组件名称，多个负责人可以属于同一组件
+	Mentions  []string `mapstructure:"mentions"`  // 通知时提及的账号，如聊天工具中的 @payments-oncall，默认为负责人
+}
+
+// team 负责人对应的组件配置，未配置时以负责人作为组件名称
+func (c ComponentsConfig) team(owner string) Team {
+	for _, t := range c.Teams {
+		if strings.EqualFold(t.Owner, owner) {
+			if t.Component == "" {
+				t.Component = owner
+			}
+			return t
+		}
+	}
+	return Team{Owner: owner, Component: owner}
+}
+
+// codeowners 读取 HEAD 中的 CODEOWNERS，不存在时返回 nil
+func (r *Releaser) codeowners() (*codeowners.File, error) {
+	locations := codeowners.Locations
+	if r.opts.Components.File != "" {
+		locations = []string{r.opts.Components.File}
+	}
+	for _, location := range locations {
+		data, err := r.git.Show("HEAD", location)
+		if err != nil {
+			return nil, err
+		}
+		if data != nil {
+			return codeowners.Parse(data), nil
+		}
+	}
+	return nil, nil
+}
+
+// components 按各提交修改文件的负责人将变更日志中的提交归属到组件，修改多个组件的提交出现在每个组件中，
+// 没有负责人的文件不归属任何组件
+func (r *Releaser) components(cl *changelog.Changelog, from string) error {
+	owners, err := r.codeowners()
+	if err != nil || owners == nil {
+		return err
+	}
+	revRange := "HEAD"
+	if from != "" {
+		revRange = from + "..HEAD"
+	}
+	files, err := r.git.CommitFiles(revRange)
+	if err != nil {
+		return err
+	}
+	cfg := r.opts.Components
+	index := map[string]int{}
+	for _, c := range cl.Attributed() {
+		attributed := map[string]bool{}
+		for _, file := range files[c.Hash] {
+			for _, owner := range owners.Owners(file) {
+				t := cfg.team(owner)
+				i, ok := index[t.Component]
+				if !ok {
+					i = len(cl.Components)
+					index[t.Component] = i
+					cl.Components = append(cl.Components, changelog.Component{Name: t.Component})
+				}
+				component := &cl.Components[i]
+				component.Owners = appendUnique(component.Owners, owner)
+				if cfg.Notify {
+					mentions := t.Mentions
+					if len(mentions) == 0 {
+						mentions = []string{owner}
+					}
+					component.Mentions = appendUnique(component.Mentions, mentions...)
+				}
+				if !attributed[t.Component] {
+					attributed[t.Component] = true
+					component.Commits = append(component.Commits, c)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func appendUnique(list []string, values ...string) []string {
+	for _, v := range values {
+		found := false
+		for _, item := range list {
+			if item == v {
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, v)
+		}
+	}
+	return list
+}
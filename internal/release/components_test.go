@@ -0,0 +1,62 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReleaser_Components(t *testing.T) {
+	repo := newRepo(t)
+	commitFiles := func(message string, files ...string) {
+		t.Helper()
+		for _, name := range files {
+			_ = os.MkdirAll(filepath.Join(repo, filepath.Dir(name)), 0o755)
+			if err := os.WriteFile(filepath.Join(repo, name), []byte(message), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		gitRun(t, repo, "add", ".")
+		gitRun(t, repo, "commit", "-q", "-m", message)
+	}
+	_ = os.MkdirAll(filepath.Join(repo, ".github"), 0o755)
+	_ = os.WriteFile(filepath.Join(repo, ".github", "CODEOWNERS"), []byte("* @org/core\n/payments/ @org/payments @alice\ndocs/ @org/docs\n"), 0o644)
+	commitFiles("chore: owners")
+	gitRun(t, repo, "tag", "v1.0.0")
+	commitFiles("feat(payments): add refunds", "payments/refund.go")
+	commitFiles("fix: shared crash", "payments/api.go", "cmd/main.go")
+	commitFiles("chore: tidy", "payments/tidy.go")
+
+	opts := Options{Cwd: repo, DryRun: true, Components: ComponentsConfig{
+		Enabled: true,
+		Notify:  true,
+		Teams: []Team{
+			{Owner: "@org/payments", Component: "payments", Mentions: []string{"@payments-oncall"}},
+			{Owner: "@alice", Component: "payments"},
+		},
+	}}
+	res, err := New(opts).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	components := res.Changelog.Components
+	if len(components) != 2 || components[0].Name != "payments" || components[1].Name != "@org/core" {
+		t.Fatalf("unexpected components %+v", components)
+	}
+	if p := components[0]; len(p.Commits) != 2 || strings.Join(p.Owners, " ") != "@org/payments @alice" || strings.Join(p.Mentions, " ") != "@payments-oncall @alice" {
+		t.Errorf("unexpected payments component %+v", p)
+	}
+	if core := components[1]; len(core.Commits) != 1 || core.Commits[0].Subject != "shared crash" {
+		t.Errorf("unexpected core component %+v", core)
+	}
+	md := res.Changelog.Markdown()
+	for _, want := range []string{"### Components", "#### payments", "Owners: `@org/payments`, `@alice` · cc @payments-oncall @alice", "* **payments:** add refunds"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected %q in changelog, but\n%s", want, md)
+		}
+	}
+	if event := New(opts).Event(res); len(event.Components) != 2 || event.Components[0].Commits != 2 {
+		t.Errorf("unexpected event components %+v", event.Components)
+	}
+}
@@ -0,0 +1,74 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// 预发布版本规则
+const (
+	PrereleaseAllow   = ""        // 允许预发布及正式版本
+	PrereleaseDeny    = "deny"    // 只允许正式版本
+	PrereleaseRequire = "require" // 只允许预发布版本
+)
+
+// Constraint 发布版本约束，对应配置文件 release.constraints 节点，匹配分支的所有约束均需满足
+type Constraint struct {
+	Branch     string `mapstructure:"branch"`     // 分支匹配规则（正则），为空时匹配所有分支
+	Version    string `mapstructure:"version"`    // 版本约束，如 <2.0.0、^1.2.0、>=1.0.0 <2.0.0
+	Prerelease string `mapstructure:"prerelease"` // 预发布版本规则 deny | require，为空时不限制
+}
+
+// Check 检查分支上发布的版本是否满足约束，不匹配该分支时直接通过，版本比较方式由 opts 指定
+func (c Constraint) Check(branch string, version semver.Semver, opts semver.CompareOptions) error {
+	if c.Branch != "" {
+		reg, err := regexp.Compile(c.Branch)
+		if err != nil {
+			return fmt.Errorf("invalid release constraint branch pattern %q: %w", c.Branch, err)
+		}
+		if !reg.MatchString(branch) {
+			return nil
+		}
+	}
+	if c.Version != "" {
+		constraint, err := semver.ParseConstraint(c.Version)
+		if err != nil {
+			return err
+		}
+		if !constraint.CheckWith(version, opts) {
+			return fmt.Errorf("version %s does not satisfy %s required on branch %s", version, constraint, branch)
+		}
+	}
+	prerelease := len(version.PreRelease()) > 0
+	switch strings.ToLower(c.Prerelease) {
+	case PrereleaseAllow:
+	case PrereleaseDeny:
+		if prerelease {
+			return fmt.Errorf("prerelease version %s is not allowed on branch %s", version, branch)
+		}
+	case PrereleaseRequire:
+		if !prerelease {
+			return fmt.Errorf("only prerelease versions are allowed on branch %s, but got %s", branch, version)
+		}
+	default:
+		return fmt.Errorf("invalid release constraint prerelease %q, valid values are %s, %s", c.Prerelease, PrereleaseDeny, PrereleaseRequire)
+	}
+	return nil
+}
+
+// checkConstraints 打标签前检查版本约束，返回所有不满足的约束
+func (r *Releaser) checkConstraints(branch string, version semver.Semver) error {
+	problems := make([]string, 0)
+	for _, c := range r.opts.Constraints {
+		if err := c.Check(branch, version, r.opts.Compare); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("release constraint violated: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package release
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+func TestConstraint_Check(t *testing.T) {
+	constraints := []Constraint{
+		{Branch: `^release-1\.x$`, Version: "<2.0.0"},
+		{Branch: `^main$`, Prerelease: PrereleaseDeny},
+		{Branch: `^next$`, Prerelease: PrereleaseRequire},
+	}
+	tests := []struct {
+		branch, version string
+		problem         string
+	}{
+		{"release-1.x", "1.9.0", ""},
+		{"release-1.x", "2.0.0", "does not satisfy <2.0.0"},
+		{"main", "2.0.0", ""},
+		{"main", "2.0.0-rc.0", "is not allowed on branch main"},
+		{"next", "2.0.0", "only prerelease versions"},
+		{"feature/x", "2.0.0-alpha", ""},
+	}
+	for _, test := range tests {
+		v, _ := semver.Version(test.version)
+		r := New(Options{Constraints: constraints})
+		err := r.checkConstraints(test.branch, v)
+		switch {
+		case test.problem == "" && err != nil:
+			t.Errorf("%s %s: expected no error, but %s got", test.branch, test.version, err)
+		case test.problem != "" && (err == nil || !strings.Contains(err.Error(), test.problem)):
+			t.Errorf("%s %s: expected %q, but %v got", test.branch, test.version, test.problem, err)
+		}
+	}
+}
+
+func TestReleaser_ConstraintsCompare(t *testing.T) {
+	v, _ := semver.Version("1.0.0-alpha9")
+	constraints := []Constraint{{Version: "<1.0.0-alpha10"}}
+	if err := New(Options{Constraints: constraints}).checkConstraints("main", v); err == nil {
+		t.Error("expected alpha9 compared lexically and rejected, but nil error got")
+	}
+	opts := Options{Constraints: constraints, Compare: semver.CompareOptions{NumericAware: true}}
+	if err := New(opts).checkConstraints("main", v); err != nil {
+		t.Errorf("expected alpha9 < alpha10 with numericAware, but %s got", err)
+	}
+}
+
+func TestReleaser_Constraints(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.9.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: break")
+	opts := Options{Cwd: repo, Release: semver.Major, Constraints: []Constraint{{Version: "<2.0.0"}}}
+	if _, err := New(opts).Release(); err == nil || !strings.Contains(err.Error(), "release constraint violated") {
+		t.Fatalf("expected constraint violation, but %v got", err)
+	}
+	if out := gitRun(t, repo, "tag", "-l", "v2.0.0"); out != "" {
+		t.Errorf("expected no tag created, but %q got", out)
+	}
+}
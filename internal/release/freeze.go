@@ -0,0 +1,29 @@
+package release
+
+import (
+	"time"
+
+	"github.com/coffee377/autoctl/internal/freeze"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// FreezeFile 冻结状态文件的路径
+func (r *Releaser) FreezeFile() string {
+	return r.opts.Freeze.Path(r.opts.Cwd)
+}
+
+// checkFreeze 代码冻结期间只允许发布 patch 版本，处于冻结窗口内时返回冻结状态，用于在变更日志中注明
+func (r *Releaser) checkFreeze(current, next semver.Semver) (*freeze.State, error) {
+	state, err := freeze.Load(r.FreezeFile())
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if err = state.Check(now, current, next); err != nil {
+		return nil, err
+	}
+	if !state.Active(now) {
+		return nil, nil
+	}
+	return state, nil
+}
@@ -0,0 +1,32 @@
+package release
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/freeze"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+func TestReleaser_Freeze(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.2.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: add plan")
+	r := New(Options{Cwd: repo})
+	if err := freeze.Save(r.FreezeFile(), &freeze.State{Since: time.Now().Add(-time.Minute), Reason: "year-end"}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := New(Options{Cwd: repo, Release: semver.Minor}).Release()
+	if err == nil || !strings.Contains(err.Error(), "code freeze: year-end") {
+		t.Fatalf("expected minor release blocked, but %v got", err)
+	}
+	res, err := New(Options{Cwd: repo, Release: semver.Patch}).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tag != "v1.2.1" || !strings.Contains(res.Changelog.Markdown(), "> Released during the code freeze: year-end.") {
+		t.Errorf("expected v1.2.1 noted as released during the freeze, but %s\n%s got", res.Tag, res.Changelog.Markdown())
+	}
+}
@@ -0,0 +1,119 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// DefaultHotfixPrefix 热修复分支前缀，分支名称为前缀加热修复版本，如 hotfix/1.2.1
+const DefaultHotfixPrefix = "hotfix/"
+
+// HotfixConfig 热修复，对应配置文件 release.hotfix 节点
+type HotfixConfig struct {
+	Prefix string `mapstructure:"prefix"` // 热修复分支前缀，默认 hotfix/
+	Base   string `mapstructure:"base"`   // 回合并请求的目标分支，默认执行命令时所在的分支
+}
+
+// Hotfix 热修复的输入
+type Hotfix struct {
+	Tag       string   // 线上版本的标签
+	Commits   []string // 依次挑选到热修复分支的提交
+	Base      string   // 回合并请求的目标分支，优先于配置
+	BackMerge bool     // 发布后创建将热修复分支合并回目标分支的合并请求，需要推送
+}
+
+// HotfixResult 热修复结果
+type HotfixResult struct {
+	Branch    string                `json:"branch"`              // 热修复分支
+	Picked    []string              `json:"picked"`              // 挑选的提交
+	Release   *Result               `json:"release"`             // 热修复版本的发布结果
+	BackMerge *provider.PullRequest `json:"backMerge,omitempty"` // 回合并请求
+}
+
+// Hotfix 从线上版本的标签创建热修复分支，挑选提交后发布下一个 patch 版本，推送时同时推送热修复分支并按需创建回合并请求；
+// 挑选冲突时中止挑选并停留在热修复分支上，成功后切换回执行命令时所在的分支
+func (r *Releaser) Hotfix(h Hotfix) (*HotfixResult, error) {
+	if len(h.Commits) == 0 {
+		return nil, errors.New("hotfix requires at least one commit to cherry-pick")
+	}
+	if !r.git.TagExists(h.Tag) {
+		return nil, fmt.Errorf("tag %s not found", h.Tag)
+	}
+	current, err := r.ParseTag(h.Tag)
+	if err != nil {
+		return nil, fmt.Errorf("invalid production tag %s: %w", h.Tag, err)
+	}
+	next := current.IncrementPatch()
+	if tag := r.TagName(next); r.git.TagExists(tag) {
+		return nil, fmt.Errorf("hotfix version %s has already been released as %s", next, tag)
+	}
+	base := h.Base
+	if base == "" {
+		base = r.opts.Hotfix.Base
+	}
+	origin, err := r.git.CurrentBranch()
+	if err != nil {
+		return nil, err
+	}
+	if base == "" {
+		base = origin
+	}
+	push := r.opts.Push || r.opts.Publish
+	if h.BackMerge && !push && !r.opts.DryRun {
+		return nil, errors.New("back-merge pull request requires --push")
+	}
+	prefix := r.opts.Hotfix.Prefix
+	if prefix == "" {
+		prefix = DefaultHotfixPrefix
+	}
+	res := &HotfixResult{Branch: prefix + next.String(), Picked: h.Commits}
+	if err = r.git.CreateBranchFrom(res.Branch, h.Tag+"^{commit}"); err != nil {
+		return nil, err
+	}
+	log.Info("created branch %s from %s", res.Branch, h.Tag)
+	if err = r.git.CherryPick(h.Commits...); err != nil {
+		return res, fmt.Errorf("cherry-pick onto %s: %w", res.Branch, err)
+	}
+	r.hotfix = true
+	if res.Release, err = r.ReleaseVersion(current, next); err != nil {
+		return res, err
+	}
+	if r.opts.DryRun {
+		return res, r.git.Checkout(origin)
+	}
+	if push {
+		if err = r.git.Push(r.opts.Remote, "HEAD:refs/heads/"+res.Branch, false); err != nil {
+			return res, err
+		}
+	}
+	if h.BackMerge {
+		if res.BackMerge, err = r.backMerge(res, base); err != nil {
+			return res, err
+		}
+		log.Info("opened back-merge pull request %s", res.BackMerge.URL)
+	}
+	return res, r.git.Checkout(origin)
+}
+
+// backMerge 创建将热修复分支合并回目标分支的合并请求
+func (r *Releaser) backMerge(res *HotfixResult, base string) (*provider.PullRequest, error) {
+	p, err := provider.New(r.opts.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("back-merge: %w", err)
+	}
+	var body strings.Builder
+	body.WriteString(fmt.Sprintf("Merge hotfix %s back into %s.\n\nCherry-picked commits:\n\n", res.Release.Tag, base))
+	for _, c := range res.Picked {
+		body.WriteString(fmt.Sprintf("* %s\n", c))
+	}
+	return p.CreatePullRequest(&provider.PullRequest{
+		Title: fmt.Sprintf("chore(release): merge hotfix %s into %s", res.Release.Tag, base),
+		Body:  body.String(),
+		Head:  res.Branch,
+		Base:  base,
+	})
+}
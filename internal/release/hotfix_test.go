@@ -0,0 +1,44 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReleaser_Hotfix(t *testing.T) {
+	repo := newRepo(t, "origin")
+	gitRun(t, repo, "tag", "-a", "v1.2.0", "-m", "release v1.2.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: next feature")
+	if err := os.WriteFile(filepath.Join(repo, "fix.txt"), []byte("fix\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitRun(t, repo, "add", "fix.txt")
+	gitRun(t, repo, "commit", "-q", "-m", "fix: crash on start")
+	fix := strings.TrimSpace(gitRun(t, repo, "rev-parse", "HEAD"))
+
+	res, err := New(Options{Cwd: repo, Push: true}).Hotfix(Hotfix{Tag: "v1.2.0", Commits: []string{fix}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Branch != "hotfix/1.2.1" || res.Release.Tag != "v1.2.1" {
+		t.Fatalf("expected v1.2.1 released from hotfix/1.2.1, but %s %s got", res.Release.Tag, res.Branch)
+	}
+	if branch := strings.TrimSpace(gitRun(t, repo, "branch", "--show-current")); branch != "main" {
+		t.Errorf("expected back on main, but %s got", branch)
+	}
+	if log := gitRun(t, repo, "log", "--format=%s", "v1.2.0..v1.2.1"); strings.TrimSpace(log) != "fix: crash on start" {
+		t.Errorf("expected only the picked commit in the hotfix, but %q got", log)
+	}
+	if out := gitRun(t, repo, "ls-remote", "origin", "refs/heads/hotfix/1.2.1", "refs/tags/v1.2.1"); strings.Count(out, "\n") != 2 {
+		t.Errorf("expected hotfix branch and tag pushed, but %q got", out)
+	}
+
+	if _, err = New(Options{Cwd: repo}).Hotfix(Hotfix{Tag: "v1.2.0", Commits: []string{fix}}); err == nil {
+		t.Error("expected error releasing the same hotfix twice")
+	}
+	if _, err = New(Options{Cwd: repo}).Hotfix(Hotfix{Tag: "v9.9.9", Commits: []string{fix}}); err == nil {
+		t.Error("expected error for unknown tag")
+	}
+}
@@ -0,0 +1,62 @@
+package release
+
+import (
+	"github.com/coffee377/autoctl/internal/impact"
+	"github.com/coffee377/autoctl/internal/schema"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// noSignal 提交都不影响版本，如 squash 合并后不符合约定式提交的历史
+func noSignal(decisions []Decision) bool {
+	for _, d := range decisions {
+		if d.Impact != ImpactNone {
+			return false
+		}
+	}
+	return true
+}
+
+// analyze 提交都不影响版本时按代码差异及接口定义的变更推断版本变动类型，首次发布或提交带有版本信息时返回 nil
+func (r *Releaser) analyze(from string, decisions []Decision) (*impact.Report, error) {
+	if !r.opts.Impact.Enabled || from == "" || len(decisions) == 0 || !noSignal(decisions) {
+		return nil, nil
+	}
+	report, err := impact.Analyze(r.git, r.opts.Impact, from, "HEAD")
+	if err != nil || len(r.opts.APICheck.Schemas) == 0 {
+		return report, err
+	}
+	schemas, err := schema.Compare(r.git, r.opts.APICheck.Schemas, from, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	report.Merge(schemas)
+	return report, nil
+}
+
+// applyImpact 未显式指定版本变动类型且合并请求没有版本标签时，采用置信度达到要求的代码差异推断结果，
+// 采用时返回 true，此时即使没有可发布的提交也会发布
+func (r *Releaser) applyImpact(current semver.Semver) (bool, error) {
+	if !r.opts.Impact.Enabled || r.explicit || r.labeled || current == nil {
+		return false, nil
+	}
+	pending, err := r.classified(current)
+	if err != nil {
+		return false, err
+	}
+	from := pending.From
+	report, err := r.analyze(from, pending.Commits)
+	if err != nil || report == nil {
+		return false, err
+	}
+	r.impact = report
+	if !r.opts.Impact.Accept(report) {
+		log.Info("commits since %s carry no release type, diff suggests %s with confidence %.2f, below the minimum", from, report.Release, report.Confidence)
+		return false, nil
+	}
+	if r.opts.Release, err = report.ReleaseType(); err != nil {
+		return false, err
+	}
+	log.Info("commits since %s carry no release type, release %s inferred from the diff with confidence %.2f", from, r.opts.Release, report.Confidence)
+	return true, nil
+}
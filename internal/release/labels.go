@@ -0,0 +1,180 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/provider"
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+const (
+	DefaultLabelPrefix = "release:"
+	LabelSkip          = "skip" // 合并请求带有 release:skip 标签时不发布
+)
+
+// 合并请求标签与提交推断的版本变动类型不一致时的处理方式
+const (
+	ConflictLabel   = "label"   // 以标签为准
+	ConflictHighest = "highest" // 取两者中较大的变动，预发布类型以标签为准
+	ConflictFail    = "fail"    // 发布失败
+)
+
+// ConflictNames 所有处理方式的名称
+func ConflictNames() []string {
+	return []string{ConflictLabel, ConflictHighest, ConflictFail}
+}
+
+// LabelsConfig 合并请求标签指定版本变动类型，对应配置文件 release.labels 节点，
+// 适用于 squash 合并后提交信息无法体现变更类型的情况，命令行显式指定的版本变动类型优先
+type LabelsConfig struct {
+	Enabled  bool   `mapstructure:"enabled"`  // 从 HEAD 所属合并请求的标签读取版本变动类型，如 release:major、release:skip
+	Prefix   string `mapstructure:"prefix"`   // 标签前缀，默认 release:
+	Conflict string `mapstructure:"conflict"` // 标签与提交推断的类型不一致时的处理方式 label | highest | fail，默认 label
+}
+
+func (c LabelsConfig) prefix() string {
+	if c.Prefix == "" {
+		return DefaultLabelPrefix
+	}
+	return c.Prefix
+}
+
+// Validate 检查冲突处理方式
+func (c LabelsConfig) Validate() error {
+	switch c.Conflict {
+	case "", ConflictLabel, ConflictHighest, ConflictFail:
+		return nil
+	default:
+		return fmt.Errorf("invalid labels conflict %q, valid values are %s", c.Conflict, strings.Join(ConflictNames(), "|"))
+	}
+}
+
+// Infer 按约定式提交推断版本变动类型，存在破坏性变更时为 major，存在 feat 提交时为 minor，否则为 patch
+func Infer(records []*commit.CommitRecord, bots changelog.Bots) semver.VersionChanged {
+	return infer(Classify(records, bots))
+}
+
+// LabelReleaseType 从合并请求标签中解析版本变动类型，没有相关标签时 ok 为 false，
+// 带有 skip 标签时 skip 为 true，多个标签指定不同类型时返回错误
+func LabelReleaseType(labels []string, prefix string) (changed semver.VersionChanged, skip, ok bool, err error) {
+	for _, label := range labels {
+		if !strings.HasPrefix(strings.ToLower(label), strings.ToLower(prefix)) {
+			continue
+		}
+		name := strings.TrimSpace(label[len(prefix):])
+		if strings.EqualFold(name, LabelSkip) {
+			skip = true
+			continue
+		}
+		c, parseErr := semver.ParseReleaseType(name)
+		if parseErr != nil {
+			return 0, false, false, fmt.Errorf("invalid release label %q: %w", label, parseErr)
+		}
+		if ok && c != changed {
+			return 0, false, false, fmt.Errorf("conflicting release labels %s%s and %s", prefix, changed, label)
+		}
+		changed, ok = c, true
+	}
+	return changed, skip, ok, nil
+}
+
+// rank 正式版本变动的大小，预发布类型为 0
+func rank(c semver.VersionChanged) int {
+	switch c {
+	case semver.Major:
+		return 3
+	case semver.Minor:
+		return 2
+	case semver.Patch:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// ResolveConflict 按处理方式合并标签与提交推断的版本变动类型
+func ResolveConflict(label, inferred semver.VersionChanged, conflict string) (semver.VersionChanged, error) {
+	if label == inferred {
+		return label, nil
+	}
+	switch conflict {
+	case ConflictHighest:
+		if rank(label) > 0 && rank(inferred) > rank(label) {
+			return inferred, nil
+		}
+		return label, nil
+	case ConflictFail:
+		return 0, fmt.Errorf("release label %s conflicts with %s inferred from commits", label, inferred)
+	default:
+		return label, nil
+	}
+}
+
+// applyLabels 启用 release.labels 且未显式指定版本变动类型时，按 HEAD 所属合并请求的标签调整按提交推断的版本变动类型，
+// 带有 skip 标签时返回跳过发布的结果
+func (r *Releaser) applyLabels(current semver.Semver) (*Result, error) {
+	if !r.opts.Labels.Enabled || r.explicit {
+		return nil, nil
+	}
+	head, err := r.git.Head()
+	if err != nil {
+		return nil, err
+	}
+	labels, err := r.pullRequestLabels(head)
+	if err != nil {
+		return nil, err
+	}
+	label, skip, ok, err := LabelReleaseType(labels, r.opts.Labels.prefix())
+	if err != nil {
+		return nil, err
+	}
+	from := ""
+	if current != nil {
+		from = r.TagName(current)
+	}
+	switch {
+	case skip && current == nil:
+		log.Warn("ignore %s%s label on the first release", r.opts.Labels.prefix(), LabelSkip)
+	case skip:
+		log.Info("pull request of %s is labeled %s%s, skip release", head, r.opts.Labels.prefix(), LabelSkip)
+		return &Result{Previous: current, Version: current, Tag: from, Commit: head, Skipped: true, DryRun: r.opts.DryRun}, nil
+	}
+	if !ok {
+		// 没有版本标签时与未启用 release.labels 一致，沿用按提交推断的版本变动类型
+		return nil, nil
+	}
+	inferred := r.inferred
+	if r.opts.Release, err = ResolveConflict(label, inferred, r.opts.Labels.Conflict); err != nil {
+		return nil, err
+	}
+	r.labeled = true
+	if r.opts.Release != inferred {
+		log.Info("release type %s from pull request label overrides %s inferred from commits", r.opts.Release, inferred)
+	}
+	return nil, nil
+}
+
+// pullRequestLabels 引入提交的合并请求上的全部标签
+func (r *Releaser) pullRequestLabels(sha string) ([]string, error) {
+	p, err := provider.New(r.opts.Provider)
+	if err != nil {
+		return nil, fmt.Errorf("release labels: %w", err)
+	}
+	finder, ok := p.(provider.CommitPullRequests)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support pull request labels", p.Name())
+	}
+	pulls, err := finder.CommitPullRequests(sha)
+	if err != nil {
+		return nil, err
+	}
+	var labels []string
+	for _, pr := range pulls {
+		labels = append(labels, pr.Labels...)
+	}
+	return labels, nil
+}
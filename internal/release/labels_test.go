@@ -0,0 +1,64 @@
+package release
+
+import (
+	"testing"
+
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+func TestLabelReleaseType(t *testing.T) {
+	changed, skip, ok, err := LabelReleaseType([]string{"bug", "Release:Minor"}, DefaultLabelPrefix)
+	if err != nil || !ok || skip || changed != semver.Minor {
+		t.Errorf("expected minor, but %s %v %v %v got", changed, skip, ok, err)
+	}
+	if _, skip, ok, _ = LabelReleaseType([]string{"release:skip"}, DefaultLabelPrefix); !skip || ok {
+		t.Errorf("expected skip only, but skip %v ok %v got", skip, ok)
+	}
+	if _, _, _, err = LabelReleaseType([]string{"release:major", "release:patch"}, DefaultLabelPrefix); err == nil {
+		t.Error("expected conflicting labels error, got nil")
+	}
+	if _, _, _, err = LabelReleaseType([]string{"release:huge"}, DefaultLabelPrefix); err == nil {
+		t.Error("expected invalid label error, got nil")
+	}
+}
+
+func TestResolveConflict(t *testing.T) {
+	tests := []struct {
+		label, inferred semver.VersionChanged
+		conflict        string
+		want            semver.VersionChanged
+		wantErr         bool
+	}{
+		{semver.Patch, semver.Minor, ConflictLabel, semver.Patch, false},
+		{semver.Patch, semver.Minor, ConflictHighest, semver.Minor, false},
+		{semver.Major, semver.Minor, ConflictHighest, semver.Major, false},
+		{semver.PreMinor, semver.Major, ConflictHighest, semver.PreMinor, false},
+		{semver.Patch, semver.Minor, ConflictFail, 0, true},
+		{semver.Minor, semver.Minor, ConflictFail, semver.Minor, false},
+	}
+	for _, tt := range tests {
+		got, err := ResolveConflict(tt.label, tt.inferred, tt.conflict)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ResolveConflict(%s, %s, %s) = %s, %v, want %s", tt.label, tt.inferred, tt.conflict, got, err, tt.want)
+		}
+	}
+}
+
+func TestInfer(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.0.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "fix: crash")
+	r := New(Options{Cwd: repo})
+	records, err := r.collect("v1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Infer(records, r.opts.Bots); got != semver.Patch {
+		t.Errorf("expected patch, but %s got", got)
+	}
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat!: drop v1 api")
+	records, _ = r.collect("v1.0.0")
+	if got := Infer(records, r.opts.Bots); got != semver.Major {
+		t.Errorf("expected major, but %s got", got)
+	}
+}
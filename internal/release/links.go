@@ -0,0 +1,74 @@
+package release
+
+import (
+	"path"
+	"sort"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/tmpl"
+)
+
+// LinksConfig 变更日志中的版本比较及源码归档链接，对应配置文件 release.links 节点，
+// 模板数据为 .Web、.Repo、.Name、.Tag、.Previous 及 .Version，未配置的模板按 provider.type 使用平台默认模板
+type LinksConfig struct {
+	Enabled  bool              `mapstructure:"enabled"`  // 在每个版本的变更日志中添加比较及源码归档链接
+	Web      string            `mapstructure:"web"`      // 仓库网页地址，默认由 provider.url 及 provider.repo 推导
+	Compare  string            `mapstructure:"compare"`  // 比较链接模板，如 {{ .Web }}/compare/{{ .Previous }}...{{ .Tag }}
+	Archives map[string]string `mapstructure:"archives"` // 源码归档链接模板，键为展示名称，如 zip、tar.gz
+}
+
+// templates 配置的模板与平台默认模板合并
+func (c LinksConfig) templates(typ string) provider.LinkTemplates {
+	t := provider.DefaultLinkTemplates(typ)
+	if c.Compare != "" {
+		t.Compare = c.Compare
+	}
+	if len(c.Archives) > 0 {
+		t.Archives = make([]provider.ArchiveTemplate, 0, len(c.Archives))
+		for name, u := range c.Archives {
+			t.Archives = append(t.Archives, provider.ArchiveTemplate{Name: name, URL: u})
+		}
+		sort.Slice(t.Archives, func(i, j int) bool { return t.Archives[i].Name < t.Archives[j].Name })
+	}
+	return t
+}
+
+// addLinks 启用 release.links 时为变更日志添加比较及源码归档链接，previous 为空（首次发布）时没有比较链接
+func (r *Releaser) addLinks(cl *changelog.Changelog, previous, tag string) error {
+	cfg := r.opts.Links
+	if !cfg.Enabled {
+		return nil
+	}
+	web := cfg.Web
+	if web == "" {
+		web = provider.WebURL(r.opts.Provider)
+	}
+	data := map[string]string{
+		"Web":      web,
+		"Repo":     r.opts.Provider.Repo,
+		"Name":     path.Base(r.opts.Provider.Repo),
+		"Tag":      tag,
+		"Previous": previous,
+		"Version":  cl.Version,
+	}
+	t := cfg.templates(r.opts.Provider.Type)
+	var err error
+	if previous != "" {
+		if cl.CompareURL, err = renderLink(t.Compare, data); err != nil {
+			return err
+		}
+	}
+	for _, a := range t.Archives {
+		u, err := renderLink(a.URL, data)
+		if err != nil {
+			return err
+		}
+		cl.Archives = append(cl.Archives, changelog.Link{Name: a.Name, URL: u})
+	}
+	return nil
+}
+
+func renderLink(text string, data map[string]string) (string, error) {
+	return tmpl.Render("link", text, data)
+}
@@ -0,0 +1,45 @@
+package release
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/provider"
+)
+
+func TestReleaser_Links(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.2.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: export")
+
+	opts := Options{
+		Cwd:      repo,
+		DryRun:   true,
+		Provider: provider.Config{Type: provider.GitLab, URL: "https://git.example.com/api/v4", Repo: "group/app"},
+		Links:    LinksConfig{Enabled: true},
+	}
+	res, err := New(opts).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cl := res.Changelog
+	if cl.CompareURL != "https://git.example.com/group/app/-/compare/v1.2.0...v1.3.0" {
+		t.Errorf("unexpected compare url %s", cl.CompareURL)
+	}
+	if len(cl.Archives) != 2 || cl.Archives[0].URL != "https://git.example.com/group/app/-/archive/v1.3.0/app-v1.3.0.zip" {
+		t.Errorf("unexpected archives %+v", cl.Archives)
+	}
+	if md := cl.Markdown(); !strings.HasPrefix(md, "## [1.3.0](https://git.example.com/group/app/-/compare/v1.2.0...v1.3.0)") {
+		t.Errorf("expected version heading linked to compare url, but\n%s", md)
+	}
+
+	opts.Provider = provider.Config{Type: provider.GitHub, Repo: "org/app"}
+	opts.Links.Archives = map[string]string{"source": "https://mirror.example.com/{{ .Name }}/{{ .Version }}.tgz"}
+	logs, err := New(opts).Backfill(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if logs[0].CompareURL != "" || len(logs[0].Archives) != 1 || logs[0].Archives[0].URL != "https://mirror.example.com/app/1.2.0.tgz" {
+		t.Errorf("unexpected first release links %+v", logs[0])
+	}
+}
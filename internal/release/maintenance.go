@@ -0,0 +1,68 @@
+package release
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// DefaultMaintenanceBranches 维护分支匹配规则，第一个分组为维护的主版本号，如 release-1.x
+var DefaultMaintenanceBranches = []string{`^release-(\d+)\.x$`, `^(\d+)\.x$`}
+
+// Line 维护分支对应的版本线
+type Line struct {
+	Branch string // 分支名称
+	Major  uint64 // 维护的主版本号
+}
+
+// Contains 版本是否属于该版本线
+func (l *Line) Contains(v semver.Semver) bool {
+	return v.Major() == l.Major
+}
+
+// MaintenanceLine 判断分支是否为维护分支，patterns 为空时使用 DefaultMaintenanceBranches
+func MaintenanceLine(branch string, patterns []string) (*Line, error) {
+	if len(patterns) == 0 {
+		patterns = DefaultMaintenanceBranches
+	}
+	for _, p := range patterns {
+		reg, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maintenance branch pattern %q: %w", p, err)
+		}
+		match := reg.FindStringSubmatch(branch)
+		if match == nil {
+			continue
+		}
+		if len(match) < 2 {
+			return nil, fmt.Errorf("maintenance branch pattern %q must capture the major version", p)
+		}
+		major, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("maintenance branch %s: invalid major version %q", branch, match[1])
+		}
+		return &Line{Branch: branch, Major: major}, nil
+	}
+	return nil, nil
+}
+
+// latestInLine 在标签中查找版本线内最大的版本
+func (r *Releaser) latestInLine(line *Line) (semver.Semver, error) {
+	tags, err := r.git.Tags(fmt.Sprintf("%s%d.*", r.opts.TagPrefix, line.Major))
+	if err != nil {
+		return nil, err
+	}
+	var latest semver.Semver
+	for _, tag := range tags {
+		v, err := r.ParseTag(tag)
+		if err != nil || !line.Contains(v) {
+			continue
+		}
+		if latest == nil || v.Compare(latest) > 0 {
+			latest = v
+		}
+	}
+	return latest, nil
+}
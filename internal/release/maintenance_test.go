@@ -0,0 +1,38 @@
+package release
+
+import (
+	"testing"
+)
+
+func TestMaintenanceLine(t *testing.T) {
+	tests := []struct {
+		branch   string
+		patterns []string
+		major    int // -1 表示不是维护分支
+	}{
+		{"release-1.x", nil, 1},
+		{"3.x", nil, 3},
+		{"main", nil, -1},
+		{"release-1.2", nil, -1},
+		{"support/v2", []string{`^support/v(\d+)$`}, 2},
+	}
+	for _, test := range tests {
+		line, err := MaintenanceLine(test.branch, test.patterns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.major == -1 {
+			if line != nil {
+				t.Errorf("branch %s expected not a maintenance branch, but %+v got", test.branch, line)
+			}
+			continue
+		}
+		if line == nil || line.Major != uint64(test.major) {
+			t.Errorf("branch %s expected major %d, but %+v got", test.branch, test.major, line)
+		}
+	}
+
+	if _, err := MaintenanceLine("release-1.x", []string{`^release-\d+\.x$`}); err == nil {
+		t.Error("expected error for pattern without capture group, got nil")
+	}
+}
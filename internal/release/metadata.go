@@ -0,0 +1,212 @@
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+const (
+	DefaultMetadataRef = "refs/notes/autoctl-release"
+
+	// 打标签前的步骤只记录耗时
+	StepPrepared    = "prepared"
+	StepApproved    = "approved"
+	StepSBOM        = "sbom"
+	StepProvenance  = "provenance"
+	StepBuildNumber = "buildNumber"
+
+	StepTagged    = "tagged"
+	StepPushed    = "pushed"
+	StepAliases   = "aliases"
+	StepPublished = "published"
+	StepStored    = "stored"
+	StepUploaded  = "uploaded"
+	StepOCI       = "oci"
+	StepTaps      = "taps"
+	StepDeployed  = "deployed"
+	StepNotified  = "notified"
+
+	maxNoteAttempts = 5 // 并发推送 notes 冲突时的最大尝试次数
+)
+
+// MetadataConfig 发布元数据，对应配置文件 release.metadata 节点
+type MetadataConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // 发布后将元数据以 git notes 附加到标签指向的提交，推送标签时同时推送 notes
+	Ref     string `mapstructure:"ref"`     // notes 引用，默认 refs/notes/autoctl-release
+}
+
+func (c MetadataConfig) ref() string {
+	if c.Ref == "" {
+		return DefaultMetadataRef
+	}
+	return c.Ref
+}
+
+// Decision 提交的分类结果
+type Decision struct {
+	Hash     string `json:"hash"`
+	Subject  string `json:"subject"`
+	Type     string `json:"type,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+	Section  string `json:"section,omitempty"` // 归入的变更日志分组，为空表示不出现在变更日志中
+	Breaking bool   `json:"breaking,omitempty"`
+	Impact   string `json:"impact,omitempty"` // 推断的发布影响 major | minor | patch | none
+	Rule     string `json:"rule,omitempty"`   // 决定分类的规则，如 BREAKING CHANGE footer、bot author renovate[bot]
+}
+
+// Metadata 以 git notes 保存的发布元数据，不依赖平台接口即可审计历史发布
+type Metadata struct {
+	Version     string               `json:"version"`
+	Previous    string               `json:"previous,omitempty"`
+	Tag         string               `json:"tag"`
+	Commit      string               `json:"commit"`
+	Branch      string               `json:"branch,omitempty"`
+	Maintenance bool                 `json:"maintenance,omitempty"`
+	ReleaseType string               `json:"releaseType"` // 版本变动类型
+	BuildNumber int64                `json:"buildNumber,omitempty"`
+	ReleaseURL  string               `json:"releaseUrl,omitempty"`
+	Started     time.Time            `json:"started"`
+	Steps       map[string]time.Time `json:"steps"`             // 各发布步骤完成的时间，如 tagged、pushed、published
+	Commits     []Decision           `json:"commits,omitempty"` // 本次发布包含的提交及其分类
+}
+
+// Done 记录发布步骤完成的时间，ok 为 false 时表示步骤未执行
+func (m *Metadata) Done(step string, ok bool) {
+	if ok {
+		m.Steps[step] = time.Now().UTC()
+	}
+}
+
+// classify 按变更日志规则对提交分类，配置 release.paths 时再按 from 之后各提交修改的文件调整发布影响
+func (r *Releaser) classify(from string, records []*commit.CommitRecord) ([]Decision, error) {
+	decisions := Classify(records, r.opts.Bots)
+	if r.opts.Paths.Empty() || len(records) == 0 {
+		return decisions, nil
+	}
+	revRange := "HEAD"
+	if from != "" {
+		revRange = from + "..HEAD"
+	}
+	files, err := r.git.CommitFiles(revRange)
+	if err != nil {
+		return nil, err
+	}
+	return ApplyPaths(decisions, files, r.opts.Paths), nil
+}
+
+// newMetadata 本次发布的元数据
+func (r *Releaser) newMetadata(res *Result, records []*commit.CommitRecord, started time.Time) (*Metadata, error) {
+	from := ""
+	if res.Previous != nil {
+		from = r.TagName(res.Previous)
+	}
+	decisions, err := r.classify(from, records)
+	if err != nil {
+		return nil, err
+	}
+	m := &Metadata{
+		Version:     res.Version.String(),
+		Tag:         res.Tag,
+		Commit:      res.Commit,
+		Branch:      res.Branch,
+		Maintenance: res.Maintenance,
+		ReleaseType: r.opts.Release.String(),
+		Started:     started.UTC(),
+		Steps:       map[string]time.Time{},
+		Commits:     decisions,
+	}
+	if res.Previous != nil {
+		m.Previous = res.Previous.String()
+	}
+	return m, nil
+}
+
+// notes 读取提交上的元数据，同一提交可能有多个版本标签（如预发布版本晋升为正式版本），按标签保存
+func (r *Releaser) notes(object string) (map[string]*Metadata, error) {
+	all := map[string]*Metadata{}
+	note, err := r.git.Note(r.opts.Metadata.ref(), object)
+	if err != nil || strings.TrimSpace(note) == "" {
+		return all, err
+	}
+	if err = json.Unmarshal([]byte(note), &all); err != nil {
+		return nil, fmt.Errorf("parse release metadata of %s: %w", object, err)
+	}
+	return all, nil
+}
+
+// syncNotes 远程仓库存在 notes 引用时将本地引用更新为远程引用，返回远程引用指向的对象，不存在时保留本地引用
+func (r *Releaser) syncNotes() (string, error) {
+	object, err := r.git.RemoteRef(r.opts.Remote, r.opts.Metadata.ref())
+	if err != nil || object == "" {
+		return "", err
+	}
+	return r.git.SyncRef(r.opts.Remote, r.opts.Metadata.ref())
+}
+
+// writeMetadata 将元数据写入标签指向的提交的 notes，推送标签时通过 --force-with-lease 推送 notes 引用
+func (r *Releaser) writeMetadata(m *Metadata, res *Result) error {
+	m.BuildNumber, m.ReleaseURL = res.BuildNumber, res.ReleaseURL
+	ref := r.opts.Metadata.ref()
+	push := r.opts.Push || r.opts.Publish
+	for attempt := 1; ; attempt++ {
+		expect := ""
+		if push {
+			var err error
+			if expect, err = r.syncNotes(); err != nil {
+				return err
+			}
+		}
+		all, err := r.notes(m.Commit)
+		if err != nil {
+			return err
+		}
+		all[m.Tag] = m
+		data, err := json.MarshalIndent(all, "", "  ")
+		if err != nil {
+			return err
+		}
+		object, err := r.git.AddNote(ref, m.Commit, string(data))
+		if err != nil || !push {
+			return err
+		}
+		if err = r.git.CompareAndSwapRef(r.opts.Remote, ref, expect, object); err == nil {
+			log.Debug("pushed release metadata of %s to %s", m.Tag, ref)
+			return nil
+		}
+		// 其它发布任务同时推送了 notes
+		if attempt >= maxNoteAttempts {
+			return fmt.Errorf("push %s: %w", ref, err)
+		}
+		log.Debug("push release metadata: %s, retry", err)
+	}
+}
+
+// Metadata 读取版本的发布元数据，存在远程仓库时先拉取 notes 引用
+func (r *Releaser) Metadata(version semver.Semver) (*Metadata, error) {
+	tag := r.TagName(version)
+	if !r.git.TagExists(tag) {
+		return nil, fmt.Errorf("tag %s not found", tag)
+	}
+	if _, err := r.syncNotes(); err != nil {
+		log.Debug("fetch release metadata: %s", err)
+	}
+	output, err := r.git.Run("rev-parse", tag+"^{commit}")
+	if err != nil {
+		return nil, err
+	}
+	all, err := r.notes(strings.TrimSpace(string(output)))
+	if err != nil {
+		return nil, err
+	}
+	m, ok := all[tag]
+	if !ok {
+		return nil, fmt.Errorf("no release metadata found for %s in %s", tag, r.opts.Metadata.ref())
+	}
+	return m, nil
+}
@@ -0,0 +1,52 @@
+package release
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+func TestReleaser_Metadata(t *testing.T) {
+	repo := newRepo(t, "origin")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "chore: tidy")
+	opts := Options{Cwd: repo, Push: true, Release: semver.Minor, Metadata: MetadataConfig{Enabled: true}}
+	res, err := New(opts).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	steps := make([]string, 0)
+	for _, timing := range res.Timings {
+		steps = append(steps, timing.Step)
+	}
+	if strings.Join(steps, ",") != "prepared,tagged,pushed" {
+		t.Errorf("expected timings of the executed steps, but %v got", res.Timings)
+	}
+	// 同一提交上的第二个版本标签不覆盖已有的元数据
+	opts.Release = semver.Major
+	if _, err = New(opts).Release(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 在新的克隆中只通过 notes 读取元数据
+	clone := filepath.Join(t.TempDir(), "clone")
+	gitRun(t, "", "clone", "-q", filepath.Join(filepath.Dir(repo), "origin.git"), clone)
+	m, err := New(Options{Cwd: clone}).Metadata(res.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Tag != "v0.1.0" || m.ReleaseType != "minor" || m.Commit != res.Commit {
+		t.Errorf("unexpected metadata %+v", m)
+	}
+	if _, ok := m.Steps[StepPushed]; !ok || len(m.Steps) != 2 {
+		t.Errorf("expected tagged and pushed steps, but %v got", m.Steps)
+	}
+	if len(m.Commits) != 2 || m.Commits[0].Section != "" || m.Commits[1].Section != "feat" || !strings.HasPrefix(m.Commits[0].Subject, "tidy") {
+		t.Errorf("unexpected commit classification %+v", m.Commits)
+	}
+	major, _ := semver.Version("1.0.0")
+	if m, err = New(Options{Cwd: clone}).Metadata(major); err != nil || m.ReleaseType != "major" {
+		t.Errorf("expected major release metadata, but %+v %v got", m, err)
+	}
+}
@@ -0,0 +1,61 @@
+package release
+
+import (
+	"path"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/tmpl"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+const (
+	DefaultMigrationsDir = "docs/migrations"                          // 迁移文档目录
+	emptyTree            = "4b825dc642cb6eb9a060e54bf8d69288fbee4904" // git 空树对象，首次发布时与其比较
+)
+
+// MigrationsConfig 迁移指南，对应配置文件 release.migrations 节点
+type MigrationsConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // 将破坏性变更说明及新增的迁移文档汇总到发布说明的 Migration Guide 分组
+	Dir     string `mapstructure:"dir"`     // 迁移文档目录，默认 docs/migrations，收集自上个版本以来新增的 *.md 文件
+	Link    string `mapstructure:"link"`    // 迁移文档链接模板，如 https://github.com/org/repo/blob/{{ .Tag }}/{{ .Path }}，默认使用相对路径
+}
+
+func (c MigrationsConfig) dir() string {
+	if c.Dir == "" {
+		return DefaultMigrationsDir
+	}
+	return c.Dir
+}
+
+// migrations 收集破坏性变更说明及自 from 以来新增的迁移文档
+func (r *Releaser) migrations(cl *changelog.Changelog, from string, next semver.Semver) error {
+	cl.Migrations = changelog.BreakingMigrations(cl.Breaking)
+	if from == "" {
+		from = emptyTree
+	}
+	files, err := r.git.AddedFiles(from, "HEAD", path.Join(r.opts.Migrations.dir(), "*.md"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		content, err := r.git.Show("HEAD", file)
+		if err != nil {
+			return err
+		}
+		link, err := r.migrationLink(file, next)
+		if err != nil {
+			return err
+		}
+		cl.Migrations = append(cl.Migrations, changelog.DocumentMigration(file, content, link))
+	}
+	return nil
+}
+
+// migrationLink 按链接模板生成迁移文档链接，未配置模板时返回空字符串
+func (r *Releaser) migrationLink(file string, next semver.Semver) (string, error) {
+	if r.opts.Migrations.Link == "" {
+		return "", nil
+	}
+	data := map[string]string{"Tag": r.TagName(next), "Version": next.String(), "Path": file}
+	return tmpl.Render("migration", r.opts.Migrations.Link, data)
+}
@@ -0,0 +1,50 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReleaser_Migrations(t *testing.T) {
+	repo := newRepo(t)
+	dir := filepath.Join(repo, "docs", "migrations")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	_ = os.WriteFile(filepath.Join(dir, "v1.md"), []byte("# Upgrade to v1\n"), 0o644)
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "commit", "-q", "-m", "docs: v1 migration")
+	gitRun(t, repo, "tag", "v1.0.0")
+	_ = os.WriteFile(filepath.Join(dir, "v2.md"), []byte("intro\n# Upgrade to v2\n"), 0o644)
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "commit", "-q", "-m", "feat(api)!: drop csv export\n\nBREAKING CHANGE: use json export instead")
+
+	opts := Options{Cwd: repo, DryRun: true, Migrations: MigrationsConfig{
+		Enabled: true,
+		Link:    "https://github.com/org/repo/blob/{{ .Tag }}/{{ .Path }}",
+	}}
+	res, err := New(opts).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrations := res.Changelog.Migrations
+	if len(migrations) != 2 || migrations[0].Note != "use json export instead" || migrations[1].Title != "Upgrade to v2" {
+		t.Fatalf("unexpected migrations %+v", migrations)
+	}
+	if migrations[1].URL != "https://github.com/org/repo/blob/v2.0.0/docs/migrations/v2.md" || migrations[1].Anchor != "migration-v2" {
+		t.Errorf("unexpected migration document %+v", migrations[1])
+	}
+	md := res.Changelog.Markdown()
+	for _, want := range []string{
+		"* **api:** use json export instead ([migration](#" + migrations[0].Anchor + "))",
+		"### Migration Guide",
+		`<a id="migration-v2"></a>`,
+		"See [docs/migrations/v2.md](https://github.com/org/repo/blob/v2.0.0/docs/migrations/v2.md).",
+	} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected %q in changelog, but\n%s", want, md)
+		}
+	}
+}
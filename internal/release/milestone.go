@@ -0,0 +1,144 @@
+package release
+
+import (
+	"fmt"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/tmpl"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// DefaultMilestoneTitle 里程碑标题模板，如 1.2.0
+const DefaultMilestoneTitle = "{{ .Version }}"
+
+// MilestonesConfig 与版本对应的平台里程碑，对应配置文件 release.milestones 节点，预发布版本不处理里程碑
+type MilestonesConfig struct {
+	Enabled bool   `mapstructure:"enabled"` // 发布后关闭版本对应的里程碑，未关闭的议题移到下一个版本的里程碑，并在发布说明中链接里程碑
+	Title   string `mapstructure:"title"`   // 里程碑标题模板，数据为 .Version、.Tag、.Major、.Minor 及 .Patch，默认 {{ .Version }}
+	Next    string `mapstructure:"next"`    // 下一个版本的变动类型 major | minor | patch，默认 patch
+}
+
+// Validate 检查下一个版本的变动类型
+func (c MilestonesConfig) Validate() error {
+	_, err := c.next()
+	return err
+}
+
+func (c MilestonesConfig) next() (semver.VersionChanged, error) {
+	if c.Next == "" {
+		return semver.Patch, nil
+	}
+	changed, err := semver.ParseReleaseType(c.Next)
+	if err != nil {
+		return 0, fmt.Errorf("invalid milestones next: %w", err)
+	}
+	if changed != semver.Major && changed != semver.Minor && changed != semver.Patch {
+		return 0, fmt.Errorf("invalid milestones next %q, valid values are major|minor|patch", c.Next)
+	}
+	return changed, nil
+}
+
+// title 版本对应的里程碑标题
+func (c MilestonesConfig) title(version semver.Semver, tag string) (string, error) {
+	text := c.Title
+	if text == "" {
+		text = DefaultMilestoneTitle
+	}
+	return tmpl.Render("milestone", text, map[string]interface{}{
+		"Version": version.String(),
+		"Tag":     tag,
+		"Major":   version.Major(),
+		"Minor":   version.Minor(),
+		"Patch":   version.Patch(),
+	})
+}
+
+func (r *Releaser) milestones() (provider.MilestoneManager, error) {
+	p, err := provider.New(r.opts.Provider)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := p.(provider.MilestoneManager)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support milestones", p.Name())
+	}
+	return m, nil
+}
+
+// milestoneLink 在变更日志中链接版本对应的里程碑，查询失败时只输出警告
+func (r *Releaser) milestoneLink(cl *changelog.Changelog, next semver.Semver) {
+	title, err := r.opts.Milestones.title(next, r.TagName(next))
+	if err != nil {
+		log.Warn("skip milestone link: %s", err)
+		return
+	}
+	manager, err := r.milestones()
+	if err != nil {
+		log.Warn("skip milestone link: %s", err)
+		return
+	}
+	m, err := manager.FindMilestone(title)
+	if err != nil {
+		log.Warn("skip milestone link: find milestone %s: %s", title, err)
+		return
+	}
+	if m != nil {
+		cl.Milestone = &changelog.Link{Name: m.Title, URL: m.URL}
+	}
+}
+
+// closeMilestone 关闭已发布版本的里程碑，未关闭的议题移到下一个版本的里程碑，下一个里程碑不存在时创建
+func (r *Releaser) closeMilestone(res *Result) error {
+	cfg := r.opts.Milestones
+	title, err := cfg.title(res.Version, res.Tag)
+	if err != nil {
+		return err
+	}
+	manager, err := r.milestones()
+	if err != nil {
+		return err
+	}
+	current, err := manager.FindMilestone(title)
+	if err != nil {
+		return fmt.Errorf("find milestone %s: %w", title, err)
+	}
+	if current == nil {
+		log.Info("no milestone %s to close", title)
+		return nil
+	}
+	changed, err := cfg.next()
+	if err != nil {
+		return err
+	}
+	next := res.Version.Increment(semver.WithReleaseType(changed))
+	nextTitle, err := cfg.title(next, r.TagName(next))
+	if err != nil {
+		return err
+	}
+	target, err := manager.FindMilestone(nextTitle)
+	if err != nil {
+		return fmt.Errorf("find milestone %s: %w", nextTitle, err)
+	}
+	if target == nil {
+		if target, err = manager.CreateMilestone(nextTitle); err != nil {
+			return fmt.Errorf("create milestone %s: %w", nextTitle, err)
+		}
+		log.Info("created milestone %s", nextTitle)
+	}
+	moved, err := manager.MoveOpenIssues(current, target)
+	if err != nil {
+		return err
+	}
+	if moved > 0 {
+		log.Info("moved %d open issues from milestone %s to %s", moved, title, nextTitle)
+	}
+	if current.State != "closed" {
+		if err = manager.CloseMilestone(current); err != nil {
+			return fmt.Errorf("close milestone %s: %w", title, err)
+		}
+		log.Info("closed milestone %s", title)
+	}
+	return nil
+}
@@ -0,0 +1,69 @@
+package release
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/provider"
+)
+
+func TestRelease_Milestones(t *testing.T) {
+	milestones := []map[string]interface{}{
+		{"number": 1, "title": "1.0.0", "state": "closed", "html_url": "https://github.com/a/b/milestone/1"},
+		{"number": 2, "title": "1.0.1", "state": "open", "html_url": "https://github.com/a/b/milestone/2"},
+	}
+	moved := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		in := map[string]interface{}{}
+		_ = json.NewDecoder(r.Body).Decode(&in)
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/a/b/milestones":
+			_ = json.NewEncoder(w).Encode(milestones)
+		case r.Method == http.MethodPost && r.URL.Path == "/repos/a/b/milestones":
+			m := map[string]interface{}{"number": 3, "title": in["title"], "state": "open"}
+			milestones = append(milestones, m)
+			_ = json.NewEncoder(w).Encode(m)
+		case r.Method == http.MethodPatch && r.URL.Path == "/repos/a/b/milestones/2":
+			milestones[1]["state"] = in["state"]
+			_, _ = w.Write([]byte(`{}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/repos/a/b/issues":
+			if r.URL.Query().Get("milestone") != "2" {
+				t.Errorf("unexpected milestone %s", r.URL.Query().Get("milestone"))
+			}
+			_, _ = w.Write([]byte(`[{"number":5},{"number":8}]`))
+		case r.Method == http.MethodPatch && strings.HasPrefix(r.URL.Path, "/repos/a/b/issues/"):
+			moved[strings.TrimPrefix(r.URL.Path, "/repos/a/b/issues/")] = int(in["milestone"].(float64))
+			_, _ = w.Write([]byte(`{}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.0.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "fix: crash")
+	res, err := New(Options{
+		Cwd:        repo,
+		Provider:   provider.Config{Type: provider.GitHub, URL: server.URL, Repo: "a/b"},
+		Milestones: MilestonesConfig{Enabled: true},
+	}).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l := res.Changelog.Milestone; l == nil || l.URL != "https://github.com/a/b/milestone/2" {
+		t.Errorf("expected milestone 1.0.1 linked, but %+v got", l)
+	}
+	if !strings.Contains(res.Changelog.Markdown(), "Milestone: [1.0.1](https://github.com/a/b/milestone/2)") {
+		t.Errorf("expected milestone link in release notes\n%s", res.Changelog.Markdown())
+	}
+	if milestones[1]["state"] != "closed" || len(milestones) != 3 || milestones[2]["title"] != "1.0.2" {
+		t.Errorf("expected milestone 1.0.1 closed and 1.0.2 created, but %v got", milestones)
+	}
+	if moved["5"] != 3 || moved["8"] != 3 {
+		t.Errorf("expected open issues moved to milestone 1.0.2, but %v got", moved)
+	}
+}
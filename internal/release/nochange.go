@@ -0,0 +1,112 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// 没有可发布的提交时的处理方式
+const (
+	NoChangeSkip  = "skip"  // 不发布，正常退出
+	NoChangeFail  = "fail"  // 不发布，以 ExitNoChange 退出码结束
+	NoChangePatch = "patch" // 仍然发布，默认递增补丁版本
+)
+
+// ExitNoChange 没有可发布的提交且处理方式为 fail 时的退出码，便于流水线区分
+const ExitNoChange = 3
+
+// NoChangeNames 所有处理方式的名称
+func NoChangeNames() []string {
+	return []string{NoChangeSkip, NoChangeFail, NoChangePatch}
+}
+
+// ParseNoChange 解析没有可发布的提交时的处理方式，为空时使用 patch 保持原有行为
+func ParseNoChange(s string) (string, error) {
+	switch v := strings.ToLower(strings.TrimSpace(s)); v {
+	case "":
+		return NoChangePatch, nil
+	case NoChangeSkip, NoChangeFail, NoChangePatch:
+		return v, nil
+	default:
+		return "", fmt.Errorf("invalid no change behavior %q, valid values are %s", s, strings.Join(NoChangeNames(), "|"))
+	}
+}
+
+// NoChangeError 自上次发布以来没有可发布的提交
+type NoChangeError struct {
+	Tag string
+}
+
+func (e *NoChangeError) Error() string {
+	return fmt.Sprintf("no releasable commits since %s", e.Tag)
+}
+
+// ExitStatus 进程退出码
+func (e *NoChangeError) ExitStatus() int {
+	return ExitNoChange
+}
+
+// Releasable 自 current 以来是否存在会出现在变更日志中的提交（feat、fix、perf、revert、依赖更新及破坏性变更），
+// 只有依赖更新且 bots.release 为 none 时不可发布，首次发布始终可发布；
+// 配置 release.paths 时按提交分类判断，只修改忽略路径的提交不可发布，修改强制路径的提交可发布
+func (r *Releaser) Releasable(current semver.Semver) (bool, error) {
+	if current == nil {
+		return true, nil
+	}
+	if !r.opts.Paths.Empty() {
+		from := r.TagName(current)
+		records, err := r.collect(from)
+		if err != nil {
+			return false, err
+		}
+		decisions, err := r.classify(from, records)
+		if err != nil {
+			return false, err
+		}
+		for _, d := range decisions {
+			if d.Impact != ImpactNone {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	releasable := false
+	opts := changelog.Options{Bots: r.opts.Bots}
+	// 找到第一个可发布的提交即停止读取
+	err := r.walk(r.TagName(current), func(record *commit.CommitRecord) error {
+		c, ok := changelog.Visible(record, opts)
+		if ok && (c.Type != changelog.DependenciesType || c.Breaking || r.opts.Bots.Releasable()) {
+			releasable = true
+			return commit.ErrStop
+		}
+		return nil
+	})
+	return releasable, err
+}
+
+// checkChange 没有可发布的提交时按配置处理，返回不为空的结果时表示跳过本次发布
+func (r *Releaser) checkChange(current semver.Semver) (*Result, error) {
+	mode, err := ParseNoChange(r.opts.OnNoChange)
+	if err != nil || mode == NoChangePatch {
+		return nil, err
+	}
+	ok, err := r.Releasable(current)
+	if err != nil || ok {
+		return nil, err
+	}
+	tag := r.TagName(current)
+	if mode == NoChangeFail {
+		return nil, &NoChangeError{Tag: tag}
+	}
+	log.Info("no releasable commits since %s, skip release", tag)
+	commit, err := r.git.Head()
+	if err != nil {
+		return nil, err
+	}
+	return &Result{Previous: current, Version: current, Tag: tag, Commit: commit, Skipped: true, DryRun: r.opts.DryRun}, nil
+}
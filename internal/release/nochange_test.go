@@ -0,0 +1,83 @@
+package release
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+)
+
+func TestReleaser_NoChange(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.0.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "chore: tidy")
+
+	res, err := New(Options{Cwd: repo, OnNoChange: NoChangeSkip}).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Skipped || res.Version.String() != "1.0.0" {
+		t.Errorf("expected release skipped at 1.0.0, but %s (skipped %v) got", res.Version, res.Skipped)
+	}
+	if out := gitRun(t, repo, "tag", "-l"); strings.TrimSpace(out) != "v1.0.0" {
+		t.Errorf("expected no tag created, but %q got", out)
+	}
+
+	_, err = New(Options{Cwd: repo, OnNoChange: NoChangeFail}).Release()
+	var noChange *NoChangeError
+	if !errors.As(err, &noChange) || noChange.ExitStatus() != ExitNoChange {
+		t.Fatalf("expected no change error, but %v got", err)
+	}
+
+	if res, err = New(Options{Cwd: repo, OnNoChange: NoChangePatch}).Release(); err != nil || res.Tag != "v1.0.1" {
+		t.Fatalf("expected v1.0.1 released, but %v %v got", res, err)
+	}
+
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "fix: crash")
+	if res, err = New(Options{Cwd: repo, OnNoChange: NoChangeFail}).Release(); err != nil || res.Tag != "v1.0.2" {
+		t.Fatalf("expected v1.0.2 released, but %v %v got", res, err)
+	}
+}
+
+func TestReleaser_NoChangeDependencies(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.0.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "chore(deps): update dependency vue to v3.3.4")
+
+	res, err := New(Options{Cwd: repo, OnNoChange: NoChangeSkip, Bots: changelog.Bots{Release: changelog.BotReleaseNone}}).Release()
+	if err != nil || !res.Skipped {
+		t.Fatalf("expected release skipped with only dependency updates, but %v %v got", res, err)
+	}
+	if res, err = New(Options{Cwd: repo, OnNoChange: NoChangeSkip}).Release(); err != nil || res.Tag != "v1.0.1" {
+		t.Fatalf("expected v1.0.1 released, but %v %v got", res, err)
+	}
+}
+
+func TestReleaser_SinceTag(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: add plan")
+	gitRun(t, repo, "tag", "-a", "v1.0.0", "-m", "release v1.0.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "chore: tidy")
+
+	if res, err := New(Options{Cwd: repo, SinceTag: true, OnNoChange: NoChangeSkip}).Release(); err != nil || !res.Skipped {
+		t.Fatalf("expected release skipped, but %v %v got", res, err)
+	}
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "fix: crash")
+	res, err := New(Options{Cwd: repo, SinceTag: true, OnNoChange: NoChangeSkip}).Release()
+	if err != nil || res.Tag != "v1.0.1" {
+		t.Fatalf("expected v1.0.1 released, but %v %v got", res, err)
+	}
+	if len(res.Changelog.Sections) != 1 || len(res.Changelog.Sections[0].Commits) != 1 {
+		t.Errorf("expected only the fix since v1.0.0, but %+v got", res.Changelog.Sections)
+	}
+}
+
+func TestParseNoChange(t *testing.T) {
+	if v, err := ParseNoChange(""); err != nil || v != NoChangePatch {
+		t.Errorf("expected default %s, but %s %v got", NoChangePatch, v, err)
+	}
+	if _, err := ParseNoChange("ignore"); err == nil {
+		t.Error("expected invalid value error, but nil got")
+	}
+}
@@ -0,0 +1,60 @@
+package release
+
+import (
+	"fmt"
+
+	"github.com/coffee377/autoctl/internal/webhook"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// Event 根据发布结果生成发布事件
+func (r *Releaser) Event(res *Result) *webhook.Event {
+	event := webhook.NewEvent(webhook.EventRelease)
+	event.Version = res.Version.String()
+	if res.Previous != nil {
+		event.Previous = res.Previous.String()
+	}
+	event.Tag = res.Tag
+	event.Commit = res.Commit
+	event.Branch = res.Branch
+	event.Prerelease = len(res.Version.PreRelease()) > 0
+	event.Maintenance = res.Maintenance
+	if res.Changelog != nil {
+		event.Changelog = res.Changelog.Markdown()
+		event.EndOfLife = res.Changelog.EndOfLife
+		for _, c := range res.Changelog.Components {
+			event.Components = append(event.Components, webhook.Component{Name: c.Name, Owners: c.Owners, Mentions: c.Mentions, Commits: len(c.Commits)})
+		}
+	}
+	event.Artifacts = res.Artifacts
+	event.URLs = map[string]string{}
+	if res.ReleaseURL != "" {
+		event.URLs["release"] = res.ReleaseURL
+	}
+	for _, d := range res.Deployments {
+		if d.PullRequest != nil {
+			event.URLs["gitops."+d.Target] = d.PullRequest.URL
+		}
+	}
+	return event
+}
+
+// notify 向所有 webhook 端点发送发布事件，单个端点失败不影响其余端点，返回第一个错误
+func (r *Releaser) notify(res *Result) error {
+	if len(r.opts.Webhooks) == 0 {
+		return nil
+	}
+	event := r.Event(res)
+	var first error
+	for _, hook := range r.opts.Webhooks {
+		if err := webhook.Send(hook, event); err != nil {
+			log.Error("%s", err)
+			if first == nil {
+				first = fmt.Errorf("release %s succeeded but %w", res.Tag, err)
+			}
+			continue
+		}
+		log.Info("sent release event %s to %s", event.ID, hook.URL)
+	}
+	return first
+}
@@ -0,0 +1,43 @@
+package release
+
+import (
+	"time"
+
+	"github.com/coffee377/autoctl/internal/audit"
+	"github.com/coffee377/autoctl/internal/policy"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// checkPolicy 打标签前检查合规策略，warn 级别的违规只输出警告，deny 级别的违规阻止发布
+func (r *Releaser) checkPolicy(res *Result) error {
+	violations, err := r.Policy(res)
+	if err != nil {
+		return err
+	}
+	for _, v := range violations {
+		if v.Level == policy.LevelWarn {
+			log.Warn("release policy %s", v)
+		}
+	}
+	return policy.Deny(violations)
+}
+
+// Policy 以发布结果为输入检查合规策略，返回违反的规则
+func (r *Releaser) Policy(res *Result) ([]policy.Violation, error) {
+	p, err := policy.Load(r.opts.Policy, r.opts.Cwd)
+	if err != nil {
+		return nil, err
+	}
+	in := policy.Input{
+		Time:      time.Now(),
+		Branch:    res.Branch,
+		Previous:  res.Previous,
+		Version:   res.Version,
+		Artifacts: res.Artifacts,
+		Actor:     audit.Actor(r.git),
+	}
+	if res.Changelog != nil {
+		in.Changelog = res.Changelog.Markdown()
+	}
+	return p.Evaluate(in), nil
+}
@@ -0,0 +1,98 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+const (
+	DefaultPreviewMarker   = "<!-- autoctl:release-preview -->"
+	DefaultPreviewMaxLines = 30
+)
+
+// PreviewConfig 合并请求上的发布预览评论，对应配置文件 release.preview 节点
+type PreviewConfig struct {
+	Title    string `mapstructure:"title"`    // 评论标题，默认 Release preview
+	Marker   string `mapstructure:"marker"`   // 识别预览评论的标记，同一合并请求上只维护一条带有该标记的评论
+	MaxLines int    `mapstructure:"maxLines"` // 变更日志片段的最大行数，默认 30，超出部分省略
+}
+
+func (c PreviewConfig) marker() string {
+	if c.Marker == "" {
+		return DefaultPreviewMarker
+	}
+	return c.Marker
+}
+
+// Preview 将演练结果渲染为合并请求评论，包含本次合并触发的版本变动类型、预计的下一个版本及变更日志片段
+func (c PreviewConfig) Preview(res *Result, err error) string {
+	title := c.Title
+	if title == "" {
+		title = "Release preview"
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("### %s\n\n", title))
+	var noChange *NoChangeError
+	switch {
+	case errors.As(err, &noChange):
+		sb.WriteString(fmt.Sprintf("No releasable commits since `%s`, merging this pull request does not trigger a release.\n", noChange.Tag))
+	case err != nil:
+		sb.WriteString(fmt.Sprintf("The release would fail:\n\n```\n%s\n```\n", err))
+	case res.Skipped:
+		sb.WriteString(fmt.Sprintf("Merging this pull request does not trigger a release, the version stays `%s`.\n", res.Version))
+	default:
+		if res.Previous == nil {
+			sb.WriteString(fmt.Sprintf("Merging this pull request triggers the **initial** release `%s`.\n", res.Version))
+		} else {
+			sb.WriteString(fmt.Sprintf("Merging this pull request triggers a **%s** release: `%s` → `%s`.\n", releaseType(res.Previous, res.Version), res.Previous, res.Version))
+		}
+		if res.Changelog != nil && !res.Changelog.IsEmpty() {
+			sb.WriteString("\n<details>\n<summary>Changelog</summary>\n\n")
+			sb.WriteString(truncateLines(res.Changelog.Markdown(), c.maxLines()))
+			sb.WriteString("\n</details>\n")
+		}
+	}
+	sb.WriteString("\n" + c.marker() + "\n")
+	return sb.String()
+}
+
+func (c PreviewConfig) maxLines() int {
+	if c.MaxLines <= 0 {
+		return DefaultPreviewMaxLines
+	}
+	return c.MaxLines
+}
+
+// releaseType 两个版本之间最高位的变动类型，预发布版本为 prerelease
+func releaseType(previous, next semver.Semver) string {
+	if len(next.PreRelease()) > 0 {
+		return semver.PreRelease.String()
+	}
+	return semver.Distance(previous, next).Type
+}
+
+// truncateLines 保留前 n 行，超出时追加省略说明
+func truncateLines(s string, n int) string {
+	lines := strings.Split(strings.TrimRight(s, "\n"), "\n")
+	if len(lines) <= n {
+		return strings.Join(lines, "\n") + "\n"
+	}
+	return strings.Join(lines[:n], "\n") + fmt.Sprintf("\n\n_… %d more lines_\n", len(lines)-n)
+}
+
+// PostPreview 在合并请求上发表或更新发布预览评论，内容未变化时不发送更新请求
+func (r *Releaser) PostPreview(number int, body string) (bool, error) {
+	p, err := provider.New(r.opts.Provider)
+	if err != nil {
+		return false, err
+	}
+	commenter, ok := p.(provider.StickyCommenter)
+	if !ok {
+		return false, fmt.Errorf("provider %s does not support updating comments", p.Name())
+	}
+	return commenter.UpsertComment(&provider.Comment{Number: number, PullRequest: true, Body: body}, r.opts.Preview.marker())
+}
@@ -0,0 +1,32 @@
+package release
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPreviewConfig_Preview(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.3.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: export")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "fix: crash")
+
+	res, err := New(Options{Cwd: repo, DryRun: true}).Release()
+	body := PreviewConfig{}.Preview(res, err)
+	if !strings.Contains(body, "a **minor** release: `1.3.0` → `1.4.0`") || !strings.HasSuffix(body, DefaultPreviewMarker+"\n") {
+		t.Errorf("unexpected preview\n%s", body)
+	}
+	if !strings.Contains(body, "export") || !strings.Contains(body, "<details>") {
+		t.Errorf("expected changelog snippet in preview\n%s", body)
+	}
+
+	body = PreviewConfig{Marker: "<!-- preview -->", MaxLines: 1}.Preview(res, err)
+	if !strings.Contains(body, "more lines_") || !strings.HasSuffix(body, "<!-- preview -->\n") {
+		t.Errorf("expected truncated changelog\n%s", body)
+	}
+
+	body = PreviewConfig{}.Preview(nil, &NoChangeError{Tag: "v1.3.0"})
+	if !strings.Contains(body, "does not trigger a release") {
+		t.Errorf("unexpected no change preview\n%s", body)
+	}
+}
@@ -0,0 +1,106 @@
+package release
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/tags"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// Retention 平台上版本发布的保留策略，对应配置文件 release.retention 节点，正式版本始终保留
+type Retention struct {
+	Prereleases int    `mapstructure:"prereleases"` // 每个预发布通道保留最新的 n 个预发布版本，0 表示不清理
+	Drafts      string `mapstructure:"drafts"`      // 删除早于该时长的草稿，如 30d，为空表示不清理
+}
+
+// Pruned 按保留策略需要删除的版本发布
+type Pruned struct {
+	Release *provider.Release `json:"release"`
+	Channel string            `json:"channel,omitempty"`
+	Reason  string            `json:"reason"`
+}
+
+type prerelease struct {
+	release *provider.Release
+	version semver.Semver
+}
+
+// Select 选出需要删除的版本发布；无法解析为版本号的标签只按草稿规则处理
+func (rt Retention) Select(list []*provider.Release, prefix string, now time.Time) ([]Pruned, error) {
+	var maxAge time.Duration
+	if rt.Drafts != "" {
+		var err error
+		if maxAge, err = tags.ParseAge(rt.Drafts); err != nil {
+			return nil, err
+		}
+	}
+	if rt.Prereleases <= 0 && maxAge <= 0 {
+		return nil, fmt.Errorf("release.retention.prereleases or release.retention.drafts is required")
+	}
+	var pruned []Pruned
+	channels := map[string][]prerelease{}
+	for _, r := range list {
+		v, err := semver.Version(strings.TrimPrefix(r.Tag, prefix))
+		if r.Draft {
+			if maxAge > 0 && !r.CreatedAt.IsZero() && now.Sub(r.CreatedAt) > maxAge {
+				pruned = append(pruned, Pruned{Release: r, Reason: fmt.Sprintf("draft older than %s", rt.Drafts)})
+			}
+			continue
+		}
+		// 正式版本及无法识别的标签不清理
+		if err != nil || len(v.PreRelease()) == 0 || rt.Prereleases <= 0 {
+			continue
+		}
+		c := tags.Channel(v)
+		channels[c] = append(channels[c], prerelease{release: r, version: v})
+	}
+	names := make([]string, 0, len(channels))
+	for c := range channels {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+	for _, c := range names {
+		list := channels[c]
+		sort.SliceStable(list, func(i, j int) bool { return list[i].version.Compare(list[j].version) > 0 })
+		for i := rt.Prereleases; i < len(list); i++ {
+			pruned = append(pruned, Pruned{
+				Release: list[i].release,
+				Channel: c,
+				Reason:  fmt.Sprintf("beyond the newest %d %s prereleases", rt.Prereleases, c),
+			})
+		}
+	}
+	return pruned, nil
+}
+
+// Prune 按保留策略删除代码托管平台上的版本发布及其附件，不删除标签；演练时只返回需要删除的版本发布
+func (r *Releaser) Prune(rt Retention) ([]Pruned, error) {
+	p, err := provider.New(r.opts.Provider)
+	if err != nil {
+		return nil, err
+	}
+	m, ok := p.(provider.ReleaseManager)
+	if !ok {
+		return nil, fmt.Errorf("%s does not support listing releases", p.Name())
+	}
+	list, err := m.ListReleases()
+	if err != nil {
+		return nil, err
+	}
+	pruned, err := rt.Select(list, r.opts.TagPrefix, time.Now())
+	if err != nil || r.opts.DryRun {
+		return pruned, err
+	}
+	for i, pr := range pruned {
+		if err = m.DeleteRelease(pr.Release); err != nil {
+			return pruned[:i], fmt.Errorf("delete %s release %s: %w", p.Name(), pr.Release.Tag, err)
+		}
+		log.Info("deleted %s release %s", p.Name(), pr.Release.Tag)
+	}
+	return pruned, nil
+}
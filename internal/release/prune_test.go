@@ -0,0 +1,43 @@
+package release
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/provider"
+)
+
+func TestRetention_Select(t *testing.T) {
+	now := time.Date(2024, 5, 21, 0, 0, 0, 0, time.UTC)
+	list := []*provider.Release{
+		{Tag: "v1.2.0", CreatedAt: now.AddDate(-1, 0, 0)},
+		{Tag: "v1.3.0-rc.3"},
+		{Tag: "v1.3.0-rc.1"},
+		{Tag: "v1.3.0-rc.2"},
+		{Tag: "v1.3.0-beta.1"},
+		{Tag: "v1.4.0", Draft: true, CreatedAt: now.AddDate(0, 0, -40)},
+		{Tag: "v1.4.0-rc.1", Draft: true, CreatedAt: now.AddDate(0, 0, -1)},
+		{Tag: "nightly"},
+	}
+	pruned, err := Retention{Prereleases: 1, Drafts: "30d"}.Select(list, "v", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, p := range pruned {
+		got = append(got, p.Release.Tag)
+	}
+	expected := []string{"v1.4.0", "v1.3.0-rc.2", "v1.3.0-rc.1"}
+	if len(got) != len(expected) {
+		t.Fatalf("expected %v, but %v got", expected, got)
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("expected %v, but %v got", expected, got)
+			break
+		}
+	}
+	if _, err = (Retention{}).Select(list, "v", now); err == nil {
+		t.Error("expected retention rules required")
+	}
+}
@@ -0,0 +1,85 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/retry"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// Remotes 接收标签的远程仓库，依次为 remote 及 mirrors（去重）
+func (r *Releaser) Remotes() []string {
+	remotes := []string{r.opts.Remote}
+	for _, m := range r.opts.Mirrors {
+		exists := false
+		for _, remote := range remotes {
+			if remote == m {
+				exists = true
+				break
+			}
+		}
+		if !exists && m != "" {
+			remotes = append(remotes, m)
+		}
+	}
+	return remotes
+}
+
+// VerifyPush 检查所有远程仓库存在且有推送权限，在创建标签前执行
+func (r *Releaser) VerifyPush(tag string) error {
+	configured, err := r.git.Remotes()
+	if err != nil {
+		return err
+	}
+	for _, remote := range r.Remotes() {
+		found := false
+		for _, c := range configured {
+			if c == remote {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("remote %s does not exist, configured remotes: %s", remote, strings.Join(configured, ", "))
+		}
+		// 以 HEAD 模拟推送即将创建的标签
+		if err = r.git.VerifyPush(remote, "HEAD:refs/tags/"+tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pushTag 将标签推送到所有远程仓库，任一失败（重试后）时删除已推送的远程标签及本地标签
+// 重复推送指向相同提交的标签不会报错，因此重试是安全的
+func (r *Releaser) pushTag(tag string) error {
+	pushed := make([]string, 0, len(r.Remotes()))
+	for _, remote := range r.Remotes() {
+		err := retry.Do(r.opts.Retry, "push tag "+tag+" to "+remote, func() error {
+			return r.git.PushTag(remote, tag)
+		})
+		if err != nil {
+			r.rollbackTag(tag, pushed)
+			return fmt.Errorf("push tag %s to %s: %w", tag, remote, err)
+		}
+		pushed = append(pushed, remote)
+		log.Info("pushed tag %s to %s", tag, remote)
+	}
+	return nil
+}
+
+func (r *Releaser) rollbackTag(tag string, remotes []string) {
+	for _, remote := range remotes {
+		if err := r.git.DeleteRemoteTag(remote, tag); err != nil {
+			log.Error("rollback: failed to delete tag %s from %s: %s", tag, remote, err)
+		} else {
+			log.Warn("rollback: deleted tag %s from %s", tag, remote)
+		}
+	}
+	if err := r.git.DeleteTag(tag); err != nil {
+		log.Error("rollback: failed to delete local tag %s: %s", tag, err)
+	} else {
+		log.Warn("rollback: deleted local tag %s", tag)
+	}
+}
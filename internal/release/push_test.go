@@ -0,0 +1,105 @@
+package release
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/lock"
+	"github.com/coffee377/autoctl/internal/retry"
+	"github.com/coffee377/autoctl/internal/testutil"
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+// gitRun 包内测试共用的 git 命令执行函数
+var gitRun = testutil.GitRun
+
+// newRepo 创建包含一个提交的仓库，并添加指定名称的裸仓库作为远程仓库
+func newRepo(t *testing.T, remotes ...string) string {
+	t.Helper()
+	root := t.TempDir()
+	repo := testutil.NewRepo(t, filepath.Join(root, "repo"))
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: init")
+	for _, remote := range remotes {
+		bare := filepath.Join(root, remote+".git")
+		gitRun(t, root, "init", "-q", "--bare", bare)
+		gitRun(t, repo, "remote", "add", remote, bare)
+	}
+	return repo
+}
+
+func TestReleaser_PushMirrors(t *testing.T) {
+	repo := newRepo(t, "origin", "mirror")
+	r := New(Options{Cwd: repo, Push: true, Mirrors: []string{"mirror", "origin"}})
+	if remotes := r.Remotes(); len(remotes) != 2 {
+		t.Fatalf("expected remotes deduplicated, but %v got", remotes)
+	}
+	res, err := r.Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, remote := range []string{"origin", "mirror"} {
+		bare := filepath.Join(filepath.Dir(repo), remote+".git")
+		if out := gitRun(t, bare, "tag", "-l", res.Tag); out == "" {
+			t.Errorf("expected tag %s pushed to %s", res.Tag, remote)
+		}
+	}
+}
+
+func TestReleaser_PushRollback(t *testing.T) {
+	repo := newRepo(t, "origin", "mirror")
+	// 镜像仓库拒绝所有推送
+	hook := filepath.Join(filepath.Dir(repo), "mirror.git", "hooks", "pre-receive")
+	if err := os.WriteFile(hook, []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	r := New(Options{Cwd: repo, Push: true, Mirrors: []string{"mirror"}, Retry: retry.Policy{Attempts: 2, Delay: time.Millisecond}})
+	if _, err := r.Release(); err == nil {
+		t.Fatal("expected push error, got nil")
+	}
+	origin := filepath.Join(filepath.Dir(repo), "origin.git")
+	if out := gitRun(t, origin, "tag", "-l"); out != "" {
+		t.Errorf("expected tag deleted from origin, but %q got", out)
+	}
+	if out := gitRun(t, repo, "tag", "-l"); out != "" {
+		t.Errorf("expected local tag deleted, but %q got", out)
+	}
+}
+
+func TestReleaser_VerifyPushUnknownRemote(t *testing.T) {
+	repo := newRepo(t, "origin")
+	r := New(Options{Cwd: repo, Push: true, Remote: "upstream"})
+	if _, err := r.Release(); err == nil {
+		t.Fatal("expected unknown remote error, got nil")
+	}
+	if out := gitRun(t, repo, "tag", "-l"); out != "" {
+		t.Errorf("expected no tag created, but %q got", out)
+	}
+}
+
+func TestReleaser_Lock(t *testing.T) {
+	repo := newRepo(t, "origin")
+	held, err := lock.New(lock.Config{}, &git.Plus{Cwd: repo}, "origin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	l, err := held.Acquire("another job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := New(Options{Cwd: repo, Push: true, Lock: lock.Config{Enabled: true, Wait: -1}})
+	if _, err = r.Release(); !errors.Is(err, lock.ErrLocked) {
+		t.Fatalf("expected locked error, but %v got", err)
+	}
+	if out := gitRun(t, repo, "tag", "-l"); out != "" {
+		t.Errorf("expected no tag created, but %q got", out)
+	}
+	if err = l.Release(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = r.Release(); err != nil {
+		t.Fatal(err)
+	}
+}
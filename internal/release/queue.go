@@ -0,0 +1,196 @@
+package release
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// 合并队列中的发布方式
+const (
+	QueueMerge = "merge" // 每次合入基础分支的流水线各自发布一个版本
+	QueueBatch = "batch" // 同一批次合入的合并请求只发布一次，由批次中最后一次合入的流水线发布
+)
+
+// QueueBranchPrefix GitHub 合并队列的临时分支前缀，分支形如 gh-readonly-queue/main/pr-123-<base sha>
+const QueueBranchPrefix = "gh-readonly-queue/"
+
+// MergeQueueConfig 合并队列（GitHub merge queue、GitLab merge train）下的发布方式，对应配置文件 release.mergeQueue 节点
+type MergeQueueConfig struct {
+	Mode string `mapstructure:"mode"` // merge | batch，默认 merge
+}
+
+// Validate 检查发布方式
+func (c MergeQueueConfig) Validate() error {
+	switch c.Mode {
+	case "", QueueMerge, QueueBatch:
+		return nil
+	default:
+		return fmt.Errorf("invalid merge queue mode %q, valid values are %s|%s", c.Mode, QueueMerge, QueueBatch)
+	}
+}
+
+// Queue 合并队列中的一次运行，队列在临时引用上合并批次内的合并请求并运行流水线，通过后再合入基础分支
+type Queue struct {
+	Branch  string   `json:"branch"`            // 队列合入的基础分支
+	Base    string   `json:"base,omitempty"`    // 批次所基于的基础分支提交
+	Head    string   `json:"head,omitempty"`    // 批次合并后的提交
+	Number  int      `json:"number,omitempty"`  // 触发本次运行的合并请求
+	Commits []string `json:"commits,omitempty"` // 批次合入基础分支的提交，按第一父提交从新到旧
+}
+
+// ParseQueueBranch 解析 GitHub 合并队列的临时分支，返回队列合入的基础分支及合并请求编号
+func ParseQueueBranch(name string) (base string, number int, ok bool) {
+	name = strings.TrimPrefix(name, "refs/heads/")
+	if !strings.HasPrefix(name, QueueBranchPrefix) {
+		return "", 0, false
+	}
+	name = strings.TrimPrefix(name, QueueBranchPrefix)
+	i := strings.LastIndex(name, "/")
+	if i <= 0 || !strings.HasPrefix(name[i+1:], "pr-") {
+		return "", 0, false
+	}
+	fields := strings.SplitN(strings.TrimPrefix(name[i+1:], "pr-"), "-", 2)
+	number, err := strconv.Atoi(fields[0])
+	if err != nil {
+		return "", 0, false
+	}
+	return name[:i], number, true
+}
+
+// DetectQueue 从 CI 环境变量识别合并队列中的运行，不在合并队列中时返回 nil
+func DetectQueue() *Queue {
+	if os.Getenv("CI_MERGE_REQUEST_EVENT_TYPE") == "merge_train" {
+		number, _ := strconv.Atoi(os.Getenv("CI_MERGE_REQUEST_IID"))
+		return &Queue{
+			Branch: os.Getenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME"),
+			Base:   os.Getenv("CI_MERGE_REQUEST_TARGET_BRANCH_SHA"),
+			Head:   os.Getenv("CI_COMMIT_SHA"),
+			Number: number,
+		}
+	}
+	if os.Getenv("GITHUB_EVENT_NAME") == "merge_group" {
+		if q := mergeGroup(os.Getenv("GITHUB_EVENT_PATH")); q != nil {
+			return q
+		}
+	}
+	if base, number, ok := ParseQueueBranch(os.Getenv("GITHUB_REF_NAME")); ok {
+		return &Queue{Branch: base, Head: os.Getenv("GITHUB_SHA"), Number: number}
+	}
+	return nil
+}
+
+// mergeGroup 读取 GitHub merge_group 事件
+func mergeGroup(path string) *Queue {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	event := struct {
+		MergeGroup struct {
+			HeadSHA string `json:"head_sha"`
+			HeadRef string `json:"head_ref"`
+			BaseSHA string `json:"base_sha"`
+			BaseRef string `json:"base_ref"`
+		} `json:"merge_group"`
+	}{}
+	if json.Unmarshal(data, &event) != nil || event.MergeGroup.BaseRef == "" {
+		return nil
+	}
+	q := &Queue{
+		Branch: strings.TrimPrefix(event.MergeGroup.BaseRef, "refs/heads/"),
+		Base:   event.MergeGroup.BaseSHA,
+		Head:   event.MergeGroup.HeadSHA,
+	}
+	_, q.Number, _ = ParseQueueBranch(event.MergeGroup.HeadRef)
+	return q
+}
+
+// Branch 发布分支，在合并队列中运行时为队列合入的基础分支而不是临时分支或分离头指针
+func (r *Releaser) Branch() (string, error) {
+	if r.queue != nil && r.queue.Branch != "" {
+		return r.queue.Branch, nil
+	}
+	branch, err := r.git.CurrentBranch()
+	if err != nil {
+		return "", err
+	}
+	if base, _, ok := ParseQueueBranch(branch); ok {
+		return base, nil
+	}
+	return branch, nil
+}
+
+// detectQueue 按 CI 环境变量或当前所在的 GitHub 合并队列临时分支识别合并队列，不在合并队列中时返回 nil
+func (r *Releaser) detectQueue() *Queue {
+	if r.queue != nil {
+		return r.queue
+	}
+	if r.queue = DetectQueue(); r.queue != nil {
+		return r.queue
+	}
+	branch, err := r.git.CurrentBranch()
+	if err != nil {
+		return nil
+	}
+	if base, number, ok := ParseQueueBranch(branch); ok {
+		r.queue = &Queue{Branch: base, Number: number}
+	}
+	return r.queue
+}
+
+// resolveQueue 补全批次合并后的提交并列出批次内容；临时引用在批次失败时会被丢弃，
+// 因此队列中只计算版本，不创建标签，批次合入基础分支后再发布
+func (r *Releaser) resolveQueue() error {
+	q := r.queue
+	if q.Head == "" {
+		head, err := r.git.Head()
+		if err != nil {
+			return err
+		}
+		q.Head = head
+	}
+	if q.Base != "" {
+		commits, err := r.git.FirstParents(q.Base + ".." + q.Head)
+		if err != nil {
+			return err
+		}
+		q.Commits = commits
+		log.Info("merge queue batch of %d commits onto %s", len(commits), q.Branch)
+	}
+	if !r.opts.DryRun {
+		log.Info("merge queue run: skip creating tag, %s is released after the batch lands", q.Branch)
+		r.opts.DryRun = true
+	}
+	return nil
+}
+
+// superseded 按批次发布时，基础分支已合入 HEAD 之后的提交说明本次合入不是批次中的最后一个，
+// 返回跳过发布的结果，由最后一次合入的流水线发布整个批次
+func (r *Releaser) superseded(current semver.Semver) (*Result, error) {
+	if r.opts.MergeQueue.Mode != QueueBatch || r.queue != nil || current == nil {
+		return nil, nil
+	}
+	branch, err := r.Branch()
+	if err != nil || branch == "HEAD" {
+		return nil, err
+	}
+	tip, err := r.git.FetchRef(r.opts.Remote, "refs/heads/"+branch)
+	if err != nil {
+		return nil, err
+	}
+	head, err := r.git.Head()
+	if err != nil {
+		return nil, err
+	}
+	if tip == head || !r.git.IsAncestor(head, tip) {
+		return nil, nil
+	}
+	log.Info("%s has moved on to %s, skip release and leave the batch to the latest merge", branch, tip)
+	return &Result{Previous: current, Version: current, Tag: r.TagName(current), Commit: head, Branch: branch, Skipped: true, DryRun: r.opts.DryRun}, nil
+}
@@ -0,0 +1,85 @@
+package release
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseQueueBranch(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		number int
+		ok     bool
+	}{
+		{"gh-readonly-queue/main/pr-12-4c1e5d7", "main", 12, true},
+		{"refs/heads/gh-readonly-queue/release/1.x/pr-3-abc", "release/1.x", 3, true},
+		{"gh-readonly-queue/main/feature", "", 0, false},
+		{"main", "", 0, false},
+	}
+	for _, tt := range tests {
+		base, number, ok := ParseQueueBranch(tt.name)
+		if base != tt.base || number != tt.number || ok != tt.ok {
+			t.Errorf("ParseQueueBranch(%q) = %q, %d, %v, want %q, %d, %v", tt.name, base, number, ok, tt.base, tt.number, tt.ok)
+		}
+	}
+}
+
+func TestRelease_MergeQueue(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.0.0")
+	base := strings.TrimSpace(gitRun(t, repo, "rev-parse", "HEAD"))
+	gitRun(t, repo, "checkout", "-q", "-b", "gh-readonly-queue/main/pr-7-"+base)
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: export (#6)")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "fix: crash (#7)")
+
+	res, err := New(Options{Cwd: repo, Branches: []string{"^main$"}}).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Branch != "main" || !res.DryRun || res.Version.String() != "1.1.0" {
+		t.Errorf("expected dry run against main, but %+v got", res)
+	}
+	if out := gitRun(t, repo, "tag", "-l", "v1.1.0"); out != "" {
+		t.Errorf("expected no tag in merge queue, but %s got", out)
+	}
+
+	t.Setenv("CI_MERGE_REQUEST_EVENT_TYPE", "merge_train")
+	t.Setenv("CI_MERGE_REQUEST_TARGET_BRANCH_NAME", "main")
+	t.Setenv("CI_MERGE_REQUEST_TARGET_BRANCH_SHA", base)
+	t.Setenv("CI_MERGE_REQUEST_IID", "7")
+	t.Setenv("CI_COMMIT_SHA", "")
+	gitRun(t, repo, "checkout", "-q", "--detach")
+	if res, err = New(Options{Cwd: repo, Branches: []string{"^main$"}}).Release(); err != nil {
+		t.Fatal(err)
+	}
+	if res.Queue == nil || res.Queue.Number != 7 || len(res.Queue.Commits) != 2 || res.Branch != "main" {
+		t.Errorf("unexpected merge train %+v", res.Queue)
+	}
+}
+
+func TestRelease_MergeQueueBatch(t *testing.T) {
+	repo := newRepo(t, "origin")
+	gitRun(t, repo, "tag", "v1.0.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: export (#6)")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "fix: crash (#7)")
+	gitRun(t, repo, "push", "-q", "origin", "main")
+	gitRun(t, repo, "reset", "-q", "--hard", "HEAD~1")
+
+	opts := Options{Cwd: repo, Push: true, MergeQueue: MergeQueueConfig{Mode: QueueBatch}}
+	res, err := New(opts).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Skipped || res.Version.String() != "1.0.0" {
+		t.Errorf("expected the first merge of the batch skipped, but %+v got", res)
+	}
+
+	gitRun(t, repo, "reset", "-q", "--hard", "origin/main")
+	if res, err = New(opts).Release(); err != nil {
+		t.Fatal(err)
+	}
+	if res.Skipped || res.Version.String() != "1.1.0" {
+		t.Errorf("expected the last merge of the batch released, but %+v got", res)
+	}
+}
@@ -0,0 +1,223 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/artifact"
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/policy"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// ErrSkipped 检查项不适用于当前配置
+var ErrSkipped = errors.New("skipped")
+
+// Check 发布前的检查项，Run 返回通过时的说明
+type Check struct {
+	Name string
+	Run  func() (string, error)
+}
+
+// CheckResult 检查结果
+type CheckResult struct {
+	Name    string `json:"name"`
+	Passed  bool   `json:"passed"`
+	Skipped bool   `json:"skipped,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// checkBranch 当前分支必须匹配 Branches 中的任一规则，未配置时允许所有分支，维护分支始终允许
+func (r *Releaser) checkBranch(branch string) error {
+	if len(r.opts.Branches) == 0 {
+		return nil
+	}
+	for _, p := range r.opts.Branches {
+		reg, err := regexp.Compile(p)
+		if err != nil {
+			return fmt.Errorf("invalid release branch pattern %q: %w", p, err)
+		}
+		if reg.MatchString(branch) {
+			return nil
+		}
+	}
+	return fmt.Errorf("branch %s is not a release branch, allowed branches: %s", branch, strings.Join(r.opts.Branches, ", "))
+}
+
+// Readiness 依次执行所有发布前检查，不做任何变更，extra 为调用方追加的检查项
+func (r *Releaser) Readiness(extra ...Check) []CheckResult {
+	var current, next semver.Semver
+	var cl *changelog.Changelog
+	push := r.opts.Push || r.opts.Publish
+	checks := []Check{
+		{"clean working tree", func() (string, error) {
+			dirty, err := r.git.HasChanges()
+			if err != nil {
+				return "", err
+			}
+			if dirty {
+				return "", errors.New("there are uncommitted changes")
+			}
+			return "", nil
+		}},
+		{"release branch", func() (string, error) {
+			line, err := r.Line()
+			if err != nil {
+				return "", err
+			}
+			if line != nil {
+				return fmt.Sprintf("maintenance branch of %d.x", line.Major), nil
+			}
+			branch, err := r.Branch()
+			if err != nil {
+				return "", err
+			}
+			return branch, r.checkBranch(branch)
+		}},
+		{"next version", func() (string, error) {
+			var err error
+			if current, err = r.Current(); err != nil {
+				return "", err
+			}
+			if next, err = r.Next(current); err != nil {
+				return "", err
+			}
+			if line, _ := r.Line(); line != nil && !line.Contains(next) {
+				return "", fmt.Errorf("%s is out of the maintenance line %d.x", next, line.Major)
+			}
+			if current == nil {
+				return fmt.Sprintf("%s (first release)", next), nil
+			}
+			return fmt.Sprintf("%s, current %s", next, current), nil
+		}},
+		{"version constraints", func() (string, error) {
+			if len(r.opts.Constraints) == 0 || next == nil {
+				return "", ErrSkipped
+			}
+			branch, err := r.Branch()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%d constraints", len(r.opts.Constraints)), r.checkConstraints(branch, next)
+		}},
+		{"tag available", func() (string, error) {
+			if next == nil {
+				return "", ErrSkipped
+			}
+			tag := r.TagName(next)
+			if r.git.TagExists(tag) {
+				return "", fmt.Errorf("tag %s already exists", tag)
+			}
+			if push {
+				for _, remote := range r.Remotes() {
+					exists, err := r.git.RemoteTagExists(remote, tag)
+					if err != nil {
+						return "", err
+					}
+					if exists {
+						return "", fmt.Errorf("tag %s already exists on %s", tag, remote)
+					}
+				}
+			}
+			return tag, nil
+		}},
+		{"push access", func() (string, error) {
+			if !push || next == nil {
+				return "", ErrSkipped
+			}
+			return strings.Join(r.Remotes(), ", "), r.VerifyPush(r.TagName(next))
+		}},
+		{"provider token", func() (string, error) {
+			if !r.opts.Publish {
+				return "", ErrSkipped
+			}
+			p, err := provider.New(r.opts.Provider)
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("%s %s", p.Name(), r.opts.Provider.Repo), p.Verify()
+		}},
+		{"api compatibility", func() (string, error) {
+			if !r.opts.APICheck.active() || current == nil || next == nil {
+				return "", ErrSkipped
+			}
+			changes, err := r.incompatible(r.TagName(current))
+			if err != nil {
+				return "", err
+			}
+			switch {
+			case len(changes) == 0:
+				return "no incompatible changes", nil
+			case allowsBreaking(current, next):
+				return fmt.Sprintf("%d incompatible changes allowed by %s", len(changes), next), nil
+			case r.opts.APICheck.OnBreaking == OnBreakingEscalate:
+				return fmt.Sprintf("%d incompatible changes, release escalates to %s", len(changes), escalated(r.opts.Release, current)), nil
+			default:
+				return "", fmt.Errorf("%d incompatible changes require a major release: %s", len(changes), listed(changes))
+			}
+		}},
+		{"changelog", func() (string, error) {
+			if next == nil {
+				return "", ErrSkipped
+			}
+			var err error
+			if cl, _, err = r.changelog(current, next); err != nil {
+				return "", err
+			}
+			commits := 0
+			for _, s := range cl.Sections {
+				commits += len(s.Commits)
+			}
+			return fmt.Sprintf("%d sections, %d commits, %d breaking changes", len(cl.Sections), commits, len(cl.Breaking)), nil
+		}},
+		{"release policy", func() (string, error) {
+			if len(r.opts.Policy.Rules) == 0 && len(r.opts.Policy.Files) == 0 || cl == nil {
+				return "", ErrSkipped
+			}
+			res := &Result{Previous: current, Version: next, Changelog: cl}
+			if line, _ := r.Line(); line != nil {
+				res.Branch = line.Branch
+			} else {
+				res.Branch, _ = r.Branch()
+			}
+			if len(r.opts.Artifacts) > 0 {
+				var err error
+				if res.Artifacts, err = artifact.Collect(r.opts.Cwd, r.opts.Artifacts); err != nil {
+					return "", err
+				}
+			}
+			violations, err := r.Policy(res)
+			if err != nil {
+				return "", err
+			}
+			warnings := make([]string, 0)
+			for _, v := range violations {
+				if v.Level == policy.LevelWarn {
+					warnings = append(warnings, v.String())
+				}
+			}
+			if err = policy.Deny(violations); err != nil {
+				return "", err
+			}
+			if len(warnings) > 0 {
+				return "warning: " + strings.Join(warnings, "; "), nil
+			}
+			return "", nil
+		}},
+	}
+	results := make([]CheckResult, 0, len(checks)+len(extra))
+	for _, c := range append(checks, extra...) {
+		msg, err := c.Run()
+		res := CheckResult{Name: c.Name, Passed: err == nil, Message: msg}
+		if errors.Is(err, ErrSkipped) {
+			res.Passed, res.Skipped, res.Message = true, true, ""
+		} else if err != nil {
+			res.Message = err.Error()
+		}
+		results = append(results, res)
+	}
+	return results
+}
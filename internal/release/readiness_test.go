@@ -0,0 +1,37 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestReleaser_Readiness(t *testing.T) {
+	repo := newRepo(t, "origin")
+	gitRun(t, repo, "tag", "v0.0.1")
+	// 远程仓库已存在下一个版本的标签
+	gitRun(t, repo, "tag", "v0.0.2")
+	gitRun(t, repo, "push", "-q", "origin", "v0.0.2")
+	gitRun(t, repo, "tag", "-d", "v0.0.2")
+	_ = os.WriteFile(filepath.Join(repo, "dirty.txt"), []byte("x"), 0o644)
+
+	r := New(Options{Cwd: repo, Push: true, Branches: []string{"^release$"}})
+	failed := map[string]bool{}
+	for _, res := range r.Readiness() {
+		if !res.Passed {
+			failed[res.Name] = true
+		}
+	}
+	for _, name := range []string{"clean working tree", "release branch", "tag available"} {
+		if !failed[name] {
+			t.Errorf("expected check %q failed", name)
+		}
+	}
+	if failed["next version"] || failed["push access"] || failed["changelog"] {
+		t.Errorf("unexpected failed checks %v", failed)
+	}
+
+	if _, err := r.Release(); err == nil {
+		t.Error("expected release on branch main refused, got nil")
+	}
+}
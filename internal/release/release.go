@@ -0,0 +1,911 @@
+package release
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/approval"
+	"github.com/coffee377/autoctl/internal/artifact"
+	"github.com/coffee377/autoctl/internal/audit"
+	"github.com/coffee377/autoctl/internal/binrepo"
+	"github.com/coffee377/autoctl/internal/buildnum"
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/convention"
+	"github.com/coffee377/autoctl/internal/deps"
+	"github.com/coffee377/autoctl/internal/freeze"
+	"github.com/coffee377/autoctl/internal/gitops"
+	"github.com/coffee377/autoctl/internal/hooks"
+	"github.com/coffee377/autoctl/internal/impact"
+	"github.com/coffee377/autoctl/internal/lock"
+	"github.com/coffee377/autoctl/internal/metrics"
+	"github.com/coffee377/autoctl/internal/policy"
+	"github.com/coffee377/autoctl/internal/provenance"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/registry"
+	"github.com/coffee377/autoctl/internal/retry"
+	"github.com/coffee377/autoctl/internal/sbom"
+	"github.com/coffee377/autoctl/internal/storage"
+	"github.com/coffee377/autoctl/internal/summary"
+	"github.com/coffee377/autoctl/internal/support"
+	"github.com/coffee377/autoctl/internal/tags"
+	"github.com/coffee377/autoctl/internal/tap"
+	"github.com/coffee377/autoctl/internal/telemetry"
+	"github.com/coffee377/autoctl/internal/webhook"
+	"github.com/coffee377/autoctl/internal/workspace"
+	"github.com/coffee377/autoctl/pkg/git"
+	commit "github.com/coffee377/autoctl/pkg/git/commit"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// Options 发布选项，可通过配置文件 release 节点设置
+type Options struct {
+	Cwd          string                    `mapstructure:"-"`            // 工作目录
+	Release      semver.VersionChanged     `mapstructure:"-"`            // 版本变动类型
+	PreId        string                    `mapstructure:"preid"`        // 预发布版本标识符
+	PreIdMode    semver.PreIdMode          `mapstructure:"-"`            // 预发布版本切换标识符时的计数方式
+	Target       string                    `mapstructure:"-"`            // 人工指定的目标版本，指定时不再按版本变动类型计算
+	Reason       string                    `mapstructure:"-"`            // 人工指定目标版本的原因，写入标签说明
+	Downgrade    bool                      `mapstructure:"-"`            // 允许目标版本不大于当前版本
+	TagPrefix    string                    `mapstructure:"tagPrefix"`    // 标签前缀，默认 v
+	Remote       string                    `mapstructure:"remote"`       // 推送标签的远程仓库，默认 origin，fork 仓库通常为 upstream
+	Mirrors      []string                  `mapstructure:"mirrors"`      // 同时推送标签的镜像仓库，任一推送失败时全部回滚
+	SkipVerify   bool                      `mapstructure:"skipVerify"`   // 跳过推送权限检查
+	Retry        retry.Policy              `mapstructure:"retry"`        // 推送标签失败时的重试策略
+	Lock         lock.Config               `mapstructure:"lock"`         // 推送标签前获取发布锁，避免并发发布
+	Approval     approval.Config           `mapstructure:"approval"`     // 打标签前等待平台上的人工审批
+	BuildNumber  buildnum.Config           `mapstructure:"buildNumber"`  // 打标签前分配单调递增的构建号，模板中通过 {{ .BuildNumber }} 引用
+	Metadata     MetadataConfig            `mapstructure:"metadata"`     // 发布后以 git notes 记录发布类型、提交分类及各步骤完成时间
+	Push         bool                      `mapstructure:"push"`         // 是否推送标签
+	Publish      bool                      `mapstructure:"publish"`      // 是否在代码托管平台创建版本发布，需要推送标签
+	Branches     []string                  `mapstructure:"branches"`     // 允许发布的分支匹配规则，为空时允许所有分支，维护分支始终允许
+	Maintenance  []string                  `mapstructure:"maintenance"`  // 维护分支匹配规则，默认 DefaultMaintenanceBranches
+	Constraints  []Constraint              `mapstructure:"constraints"`  // 打标签前检查的版本约束，如 release-1.x 分支只允许 <2.0.0
+	Compare      semver.CompareOptions     `mapstructure:"compare"`      // 检查版本约束时的比较方式，如 caseInsensitive、numericAware
+	Labels       LabelsConfig              `mapstructure:"labels"`       // 按合并请求标签（如 release:major、release:skip）确定版本变动类型
+	Freeze       freeze.Config             `mapstructure:"freeze"`       // 代码冻结期间只允许发布 patch 版本，通过 autoctl freeze、autoctl thaw 切换
+	Hotfix       HotfixConfig              `mapstructure:"hotfix"`       // autoctl hotfix 创建的热修复分支及回合并请求的目标分支
+	Submodules   SubmodulesConfig          `mapstructure:"submodules"`   // 打标签前更新子模块记录，打标签后为子模块创建同名标签
+	Preview      PreviewConfig             `mapstructure:"preview"`      // autoctl release preview 在合并请求上维护的发布预览评论
+	MergeQueue   MergeQueueConfig          `mapstructure:"mergeQueue"`   // 合并队列下每次合入发布一次或每个批次发布一次
+	Milestones   MilestonesConfig          `mapstructure:"milestones"`   // 发布后关闭版本对应的平台里程碑，未关闭的议题移到下一个版本的里程碑
+	Security     SecurityConfig            `mapstructure:"security"`     // 安全变更文件目录及是否在平台上创建安全公告
+	Aliases      AliasesConfig             `mapstructure:"aliases"`      // 发布时移动的浮动标签，如 v1 指向 1.x.y 中的最新版本、stable 指向最新的正式版本
+	Policy       policy.Config             `mapstructure:"policy"`       // 打标签前检查的合规策略，如禁止周五发布、产物必须签名
+	Migrations   MigrationsConfig          `mapstructure:"migrations"`   // 在发布说明中汇总破坏性变更说明及新增的迁移文档
+	Links        LinksConfig               `mapstructure:"links"`        // 在变更日志中添加版本比较及源码归档链接
+	Components   ComponentsConfig          `mapstructure:"components"`   // 按 CODEOWNERS 将变更归属到组件，并在发布说明及通知中提及负责团队
+	Artifacts    []string                  `mapstructure:"artifacts"`    // 发布产物文件，支持通配符，相对路径基于工作目录
+	Dependencies deps.Config               `mapstructure:"dependencies"` // 在变更日志中展示依赖及许可证变更
+	Bots         changelog.Bots            `mapstructure:"bots"`         // Renovate、Dependabot 等依赖更新提交的识别方式及发布影响
+	Convention   convention.Grammar        `mapstructure:"convention"`   // gitmoji、[FEATURE] 等非约定式提交的解析规则
+	Impact       impact.Config             `mapstructure:"impact"`       // 提交都不影响版本时按导出 Go 符号及依赖主版本的差异推断版本变动类型
+	Paths        workspace.PathRules       `mapstructure:"paths"`        // 不触发发布（如 docs/**、*.md）及至少发布 patch 版本（如 migrations/**）的路径
+	APICheck     APICheckConfig            `mapstructure:"apiCheck"`     // 发布前检查导出 Go API 的兼容性，不兼容变更只递增 minor 或 patch 时失败或提升为 major
+	Locales      []changelog.Locale        `mapstructure:"locales"`      // 变更日志的其它语言版本，autoctl changelog backfill 同时生成 CHANGELOG.<lang>.md
+	SBOM         sbom.Config               `mapstructure:"sbom"`         // 发布时生成 SBOM 并作为产物上传
+	Provenance   provenance.Config         `mapstructure:"provenance"`   // 发布时生成 SLSA 来源证明并作为产物上传
+	Summary      summary.Config            `mapstructure:"summary"`      // 通过外部命令或 HTTP 端点生成变更摘要
+	Retention    Retention                 `mapstructure:"retention"`    // 清理平台上版本发布的保留策略
+	Provider     provider.Config           `mapstructure:"-"`            // 代码托管平台，对应配置文件 provider 节点
+	GitOps       []gitops.Target           `mapstructure:"-"`            // 发布后更新版本的部署仓库，对应配置文件 gitops 节点
+	Webhooks     []webhook.Config          `mapstructure:"-"`            // 发布完成后接收事件的端点，对应配置文件 webhooks 节点
+	Support      support.Policy            `mapstructure:"-"`            // 版本支持策略，发布后停止支持的版本写入变更日志，对应配置文件 support 节点
+	Storage      []storage.Config          `mapstructure:"-"`            // 同时上传发布产物及变更日志的对象存储，对应配置文件 storage 节点
+	Repositories []binrepo.Config          `mapstructure:"-"`            // 同时上传发布产物的 Nexus、Artifactory 制品仓库，对应配置文件 repositories 节点
+	OCI          []registry.ArtifactTarget `mapstructure:"-"`            // 同时以 OCI 制品推送发布产物的镜像仓库，对应配置文件 oci 节点
+	Homebrew     tap.Homebrew              `mapstructure:"-"`            // 正式版本发布后更新的 Homebrew 公式，对应配置文件 homebrew 节点
+	Scoop        tap.Scoop                 `mapstructure:"-"`            // 正式版本发布后更新的 Scoop 清单，对应配置文件 scoop 节点
+	Audit        audit.Config              `mapstructure:"-"`            // 记录发布操作的审计日志，对应配置文件 audit 节点
+	Metrics      metrics.Config            `mapstructure:"-"`            // 发布后推送指标的 Pushgateway，对应配置文件 metrics 节点
+	Hooks        hooks.Config              `mapstructure:"-"`            // 创建标签前及发布完成后执行的脚本，对应配置文件 hooks 节点
+	OnNoChange   string                    `mapstructure:"onNoChange"`   // 没有可发布的提交时的处理方式 skip | fail | patch，默认 patch
+	SinceTag     bool                      `mapstructure:"sinceTag"`     // 从 HEAD 流式读取提交直到最近的版本标签，不计算标签范围，适用于提交数量巨大的线性历史
+	DryRun       bool                      `mapstructure:"-"`            // 仅计算版本，不执行任何变更
+	Verbose      bool                      `mapstructure:"-"`            // 输出详细信息
+}
+
+// Result 发布结果
+type Result struct {
+	Previous    semver.Semver        `json:"previous,omitempty"`    // 上一个版本，首次发布时为空
+	Version     semver.Semver        `json:"version"`               // 本次发布的版本
+	Tag         string               `json:"tag"`                   // 本次发布的标签
+	Commit      string               `json:"commit"`                // 标签指向的提交
+	Branch      string               `json:"branch,omitempty"`      // 发布分支
+	Maintenance bool                 `json:"maintenance,omitempty"` // 是否从维护分支发布
+	Changelog   *changelog.Changelog `json:"changelog,omitempty"`   // 本次发布的变更日志
+	Artifacts   []artifact.Artifact  `json:"artifacts,omitempty"`   // 发布产物
+	BuildNumber int64                `json:"buildNumber,omitempty"` // 本次发布分配的构建号
+	ReleaseURL  string               `json:"releaseUrl,omitempty"`  // 代码托管平台上的发布地址
+	Storage     []storage.Object     `json:"storage,omitempty"`     // 上传到对象存储的文件
+	Packages    []binrepo.Object     `json:"packages,omitempty"`    // 上传到制品仓库的文件
+	OCI         []string             `json:"oci,omitempty"`         // 推送的 OCI 制品，形如 <仓库>:<版本>@<摘要>
+	Deployments []*gitops.Result     `json:"deployments,omitempty"` // 部署仓库的更新结果
+	Taps        []*gitops.Result     `json:"taps,omitempty"`        // Homebrew tap 及 Scoop bucket 仓库的更新结果
+	Steps       map[string]time.Time `json:"steps,omitempty"`       // 打标签后完成的发布步骤及完成时间
+	Timings     []Timing             `json:"timings,omitempty"`     // 各发布步骤的耗时
+	Override    *Override            `json:"override,omitempty"`    // 人工指定目标版本时的记录
+	Aliases     []Alias              `json:"aliases,omitempty"`     // 移动到本次发布的浮动标签
+	Queue       *Queue               `json:"queue,omitempty"`       // 在合并队列中运行时的队列及批次内容
+	Impact      *impact.Report       `json:"impact,omitempty"`      // 按代码差异推断的版本变动类型
+	Skipped     bool                 `json:"skipped,omitempty"`     // 没有可发布的提交而跳过发布，此时版本为当前版本
+	DryRun      bool                 `json:"dryRun,omitempty"`      // 是否为演练
+
+	lap time.Time // 上一步骤结束的时间
+}
+
+// Timing 发布步骤的耗时
+type Timing struct {
+	Step     string        `json:"step"`
+	Duration time.Duration `json:"duration"`
+}
+
+// timed 记录自上一步骤结束以来的耗时，ok 为 false 时表示步骤未执行，只重新计时
+func (res *Result) timed(step string, ok bool) {
+	now := time.Now()
+	if ok {
+		res.Timings = append(res.Timings, Timing{Step: step, Duration: now.Sub(res.lap)})
+		telemetry.Record(step, res.lap, now)
+	}
+	res.lap = now
+}
+
+// Releaser 根据最近一次的标签计算下一个版本并打标签
+type Releaser struct {
+	opts     Options
+	git      *git.Plus
+	line     *Line // 当前分支为维护分支时对应的版本线
+	explicit bool  // 是否显式指定了版本变动类型，显式指定时不读取合并请求标签
+	hotfix   bool  // 是否为热修复发布，热修复分支不受 branches 限制
+	labeled  bool  // 是否按合并请求标签确定了版本变动类型
+	queue    *Queue
+	impact   *impact.Report
+	pending  *Classification        // 自上一个版本以来的提交分类，只计算一次
+	inferred semver.VersionChanged  // 按提交推断的版本变动类型
+	records  []*commit.CommitRecord // 分类的提交
+}
+
+func New(opts Options) *Releaser {
+	if opts.TagPrefix == "" {
+		opts.TagPrefix = "v"
+	}
+	if opts.Remote == "" {
+		opts.Remote = "origin"
+	}
+	explicit := opts.Release != 0
+	if !explicit {
+		opts.Release = semver.Patch
+	}
+	return &Releaser{opts: opts, git: &git.Plus{Cwd: opts.Cwd, Verbose: opts.Verbose}, explicit: explicit}
+}
+
+// Options 返回补全默认值后的发布选项
+func (r *Releaser) Options() Options {
+	return r.opts
+}
+
+// Git 返回发布使用的 git 命令封装
+func (r *Releaser) Git() *git.Plus {
+	return r.git
+}
+
+// Line 当前分支为维护分支时返回对应的版本线，否则返回 nil
+func (r *Releaser) Line() (*Line, error) {
+	if r.line != nil {
+		return r.line, nil
+	}
+	branch, err := r.Branch()
+	if err != nil {
+		return nil, err
+	}
+	r.line, err = MaintenanceLine(branch, r.opts.Maintenance)
+	return r.line, err
+}
+
+// Current 获取当前版本，维护分支上只查找该主版本内的最新版本，仓库中不存在版本标签时返回 nil
+func (r *Releaser) Current() (semver.Semver, error) {
+	line, err := r.Line()
+	if err != nil {
+		return nil, err
+	}
+	if line != nil {
+		return r.latestInLine(line)
+	}
+	// 只匹配完整的版本号，避免 v1、v1.2 等浮动标签
+	tag, err := r.git.LatestTag(r.opts.TagPrefix + "*.*.*")
+	if err != nil {
+		// 没有任何标签时视为首次发布
+		log.Debug("no tag found: %s", err)
+		return nil, nil
+	}
+	return r.ParseTag(tag)
+}
+
+// ParseTag 去除标签前缀并解析版本号
+func (r *Releaser) ParseTag(tag string) (semver.Semver, error) {
+	return semver.Version(strings.TrimPrefix(tag, r.opts.TagPrefix))
+}
+
+// TagName 版本对应的标签名称
+func (r *Releaser) TagName(version semver.Semver) string {
+	return r.opts.TagPrefix + version.String()
+}
+
+// Next 计算下一个版本，首次发布时以 0.0.0 为基础递增；预发布版本标识符无效或按 preIdMode 拒绝切换时返回错误
+func (r *Releaser) Next(current semver.Semver) (semver.Semver, error) {
+	if current == nil {
+		current, _ = semver.Version("0.0.0")
+	}
+	return current.IncrementE(
+		semver.WithReleaseType(r.opts.Release),
+		semver.WithIdentifier(semver.PreReleaseIdentifier(r.opts.PreId)),
+		semver.WithPreIdMode(r.opts.PreIdMode),
+	)
+}
+
+// Release 计算下一个版本，创建标签并按需推送
+func (r *Releaser) Release() (*Result, error) {
+	if r.detectQueue() != nil {
+		if err := r.resolveQueue(); err != nil {
+			return nil, err
+		}
+	}
+	res, err := r.WithLock(func() (*Result, error) {
+		current, err := r.Current()
+		if err != nil {
+			return nil, err
+		}
+		if r.opts.Target != "" {
+			return r.releaseTarget(current)
+		}
+		if res, err := r.superseded(current); err != nil || res != nil {
+			return res, err
+		}
+		if !r.explicit {
+			// 未显式指定时按提交推断，合并请求标签及代码差异推断在此基础上调整
+			if _, err := r.classified(current); err != nil {
+				return nil, err
+			}
+			r.opts.Release = r.inferred
+		}
+		if res, err := r.applyLabels(current); err != nil || res != nil {
+			return res, err
+		}
+		inferred, err := r.applyImpact(current)
+		if err != nil {
+			return nil, err
+		}
+		if !inferred {
+			if res, err := r.checkChange(current); err != nil || res != nil {
+				return res, err
+			}
+		}
+		if err := r.checkAPI(current); err != nil {
+			return nil, err
+		}
+		next, err := r.Next(current)
+		if err != nil {
+			return nil, err
+		}
+		return r.ReleaseVersion(current, next)
+	})
+	if res != nil {
+		res.Queue = r.queue
+		res.Impact = r.impact
+	}
+	return res, err
+}
+
+// WithLock 需要推送标签且启用发布锁时，获取锁并拉取远程标签后执行 fn，保证基于最新的标签计算版本
+func (r *Releaser) WithLock(fn func() (*Result, error)) (*Result, error) {
+	if !r.opts.Lock.Enabled || r.opts.DryRun || !(r.opts.Push || r.opts.Publish) {
+		return fn()
+	}
+	locker, err := lock.New(r.opts.Lock, r.git, r.opts.Remote)
+	if err != nil {
+		return nil, err
+	}
+	l, err := locker.Acquire(lock.Owner())
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err := l.Release(); err != nil {
+			log.Warn("release lock: %s", err)
+		}
+	}()
+	if err = r.git.FetchRemoteTags(r.opts.Remote); err != nil {
+		return nil, err
+	}
+	return fn()
+}
+
+// ReleaseVersion 发布指定的版本
+func (r *Releaser) ReleaseVersion(current, next semver.Semver) (*Result, error) {
+	return r.releaseVersion(current, next, nil)
+}
+
+func (r *Releaser) releaseVersion(current, next semver.Semver, override *Override) (*Result, error) {
+	// 指定目标版本及发布计划不经过 Next，同样拒绝无效的预发布版本标识符
+	if err := semver.PreReleaseIdentifier(r.opts.PreId).Validate(); err != nil {
+		return nil, err
+	}
+	span := telemetry.StartRelease(next.String(), r.opts.DryRun)
+	started := time.Now()
+	res, err := r.runRelease(current, next, override, started)
+	span.End(err)
+	if !r.opts.DryRun {
+		r.observe(current, next, started, err)
+	}
+	return res, err
+}
+
+// observe 记录发布指标供 autoctl serve 暴露，并推送到配置的 Pushgateway，推送失败只记录警告
+func (r *Releaser) observe(current, next semver.Semver, started time.Time, err error) {
+	rel := metrics.Release{
+		Repository: r.opts.Provider.Repo,
+		Version:    next.String(),
+		Tag:        r.TagName(next),
+		Success:    err == nil,
+		Time:       time.Now(),
+	}
+	rel.Duration = rel.Time.Sub(started)
+	if current != nil {
+		rel.Previous = current.String()
+	}
+	metrics.Observe(rel)
+	if perr := metrics.Push(r.opts.Metrics, rel); perr != nil {
+		log.Warn("%s", perr)
+	}
+}
+
+// runRelease 依次执行发布的各步骤，started 为开始发布的时间
+func (r *Releaser) runRelease(current, next semver.Semver, override *Override, started time.Time) (*Result, error) {
+	if current != nil && next.Compare(current) <= 0 && (override == nil || !override.Downgrade) {
+		return nil, fmt.Errorf("next version %s must be greater than current version %s", next, current)
+	}
+	line, err := r.Line()
+	if err != nil {
+		return nil, err
+	}
+	if line != nil && !line.Contains(next) {
+		return nil, fmt.Errorf("maintenance branch %s only allows releasing %d.x versions, but got %s", line.Branch, line.Major, next)
+	}
+	if r.opts.Submodules.Bump {
+		if r.opts.DryRun {
+			log.Info("dry run: skip bumping submodules")
+		} else if err = r.bumpSubmodules(); err != nil {
+			return nil, err
+		}
+	}
+	head, err := r.git.Head()
+	if err != nil {
+		return nil, err
+	}
+	res := &Result{Previous: current, Version: next, Tag: r.TagName(next), Commit: head, Override: override, DryRun: r.opts.DryRun, lap: started}
+	if line != nil {
+		res.Branch = line.Branch
+		res.Maintenance = true
+	} else {
+		res.Branch, _ = r.Branch()
+		if err = r.checkBranch(res.Branch); err != nil && !r.hotfix {
+			return nil, err
+		}
+	}
+	if err = r.checkConstraints(res.Branch, next); err != nil {
+		return nil, err
+	}
+	frozen, err := r.checkFreeze(current, next)
+	if err != nil {
+		return nil, err
+	}
+	var records []*commit.CommitRecord
+	if res.Changelog, records, err = r.changelog(current, next); err != nil {
+		return nil, err
+	}
+	if frozen != nil {
+		res.Changelog.Notice = fmt.Sprintf("Released during the %s.", frozen)
+	}
+	if r.opts.Aliases.Enabled() {
+		if res.Aliases, err = r.aliases(next); err != nil {
+			return nil, err
+		}
+	}
+	if len(r.opts.Artifacts) > 0 {
+		if res.Artifacts, err = artifact.Collect(r.opts.Cwd, r.opts.Artifacts); err != nil {
+			return nil, err
+		}
+	}
+	if err = r.checkPolicy(res); err != nil {
+		return nil, err
+	}
+	push := r.opts.Push || r.opts.Publish
+	if push && !r.opts.SkipVerify {
+		if err = r.VerifyPush(res.Tag); err != nil {
+			return nil, err
+		}
+	}
+	res.timed(StepPrepared, true)
+	if r.opts.DryRun {
+		log.Info("dry run: skip creating tag %s", res.Tag)
+		return res, nil
+	}
+	if r.opts.Approval.Enabled {
+		if err = r.approve(res); err != nil {
+			return nil, err
+		}
+	}
+	res.timed(StepApproved, r.opts.Approval.Enabled)
+	if r.opts.SBOM.Enabled {
+		if err = r.generateSBOM(res); err != nil {
+			return nil, err
+		}
+	}
+	res.timed(StepSBOM, r.opts.SBOM.Enabled)
+	if r.opts.Provenance.Enabled {
+		if err = r.generateProvenance(res, started); err != nil {
+			return nil, err
+		}
+	}
+	res.timed(StepProvenance, r.opts.Provenance.Enabled)
+	if r.opts.BuildNumber.Enabled {
+		if err = r.allocateBuildNumber(res); err != nil {
+			return nil, err
+		}
+	}
+	res.timed(StepBuildNumber, r.opts.BuildNumber.Enabled)
+	if err = r.runHooks(hooks.BeforeRelease, res); err != nil {
+		return nil, err
+	}
+	if err = r.git.CreateTag(res.Tag, tagMessage(res)); err != nil {
+		return nil, err
+	}
+	log.Info("created tag %s", res.Tag)
+	if r.opts.Submodules.Tag {
+		if err = r.tagSubmodules(res.Tag, push); err != nil {
+			return nil, err
+		}
+	}
+	meta, err := r.newMetadata(res, records, started)
+	if err != nil {
+		return nil, err
+	}
+	res.Steps = meta.Steps
+	done := func(step string, ok bool) {
+		meta.Done(step, ok)
+		res.timed(step, ok)
+	}
+	done(StepTagged, true)
+	if push {
+		if err = r.pushTag(res.Tag); err != nil {
+			return nil, err
+		}
+		done(StepPushed, true)
+	}
+	if len(res.Aliases) > 0 {
+		if err = r.moveAliases(res, push); err != nil {
+			return res, err
+		}
+		done(StepAliases, true)
+	}
+	if r.opts.Metadata.Enabled {
+		// 推送标签之后的步骤失败时也记录已完成的步骤
+		defer func() {
+			if err := r.writeMetadata(meta, res); err != nil {
+				log.Warn("write release metadata: %s", err)
+			}
+		}()
+	}
+	if r.opts.Publish {
+		if err = r.publish(res); err != nil {
+			return res, err
+		}
+		done(StepPublished, true)
+	}
+	if r.opts.Milestones.Enabled && len(res.Version.PreRelease()) == 0 {
+		if err = r.closeMilestone(res); err != nil {
+			return res, err
+		}
+	}
+	if r.opts.Security.Advisories && len(res.Changelog.Security) > 0 {
+		if err = r.createAdvisories(res); err != nil {
+			return res, err
+		}
+	}
+	if err = r.store(res); err != nil {
+		return res, err
+	}
+	done(StepStored, len(res.Storage) > 0)
+	if err = r.upload(res); err != nil {
+		return res, err
+	}
+	done(StepUploaded, len(res.Packages) > 0)
+	if err = r.pushOCI(res); err != nil {
+		return res, err
+	}
+	done(StepOCI, len(res.OCI) > 0)
+	if err = r.updateTaps(res); err != nil {
+		return res, err
+	}
+	done(StepTaps, len(res.Taps) > 0)
+	if err = r.deploy(res); err != nil {
+		return res, err
+	}
+	done(StepDeployed, len(res.Deployments) > 0)
+	if err = r.notify(res); err != nil {
+		return res, err
+	}
+	done(StepNotified, len(r.opts.Webhooks) > 0)
+	if err = r.runHooks(hooks.AfterRelease, res); err != nil {
+		return res, fmt.Errorf("release %s succeeded but %w", res.Tag, err)
+	}
+	return res, nil
+}
+
+// runHooks 执行发布阶段的钩子，脚本可以通过 AUTOCTL_VERSION、AUTOCTL_PREVIOUS、AUTOCTL_TAG、AUTOCTL_COMMIT 环境变量读取本次发布
+func (r *Releaser) runHooks(stage string, res *Result) error {
+	env := map[string]string{
+		"AUTOCTL_VERSION": res.Version.String(),
+		"AUTOCTL_TAG":     res.Tag,
+		"AUTOCTL_COMMIT":  res.Commit,
+	}
+	if res.Previous != nil {
+		env["AUTOCTL_PREVIOUS"] = res.Previous.String()
+	}
+	return r.opts.Hooks.Run(stage, r.opts.Cwd, env)
+}
+
+// walk 流式读取 from 之后的提交，开启 sinceTag 且不在维护分支时从 HEAD 读取到最近的版本标签为止，
+// 提交按 convention 配置转换为约定式提交
+func (r *Releaser) walk(from string, fn func(record *commit.CommitRecord) error) error {
+	parser, err := r.opts.Convention.Compile()
+	if err != nil {
+		return err
+	}
+	if parser != nil {
+		next := fn
+		fn = func(record *commit.CommitRecord) error {
+			parser.Normalize(record)
+			return next(record)
+		}
+	}
+	if r.opts.SinceTag && from != "" && r.line == nil {
+		tag, err := r.git.WalkLogsSinceTag([]string{r.opts.TagPrefix + "*"}, fn)
+		if err == nil && tag != from {
+			log.Debug("stopped at tag %s instead of %s", tag, from)
+		}
+		return err
+	}
+	revRange := "HEAD"
+	if from != "" {
+		revRange = from + "..HEAD"
+	}
+	return r.git.WalkLogs(revRange, fn)
+}
+
+// collect 获取 from 之后的全部提交
+func (r *Releaser) collect(from string) ([]*commit.CommitRecord, error) {
+	records := make([]*commit.CommitRecord, 0)
+	err := r.walk(from, func(record *commit.CommitRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	return records, err
+}
+
+// changelog 生成上一个版本（维护分支上为同一版本线内的上一个版本）到当前提交的变更日志，同时返回读取的提交
+func (r *Releaser) changelog(current, next semver.Semver) (*changelog.Changelog, []*commit.CommitRecord, error) {
+	from, previous := "", ""
+	if current != nil {
+		from, previous = r.TagName(current), current.String()
+	}
+	records, err := r.collect(from)
+	if err != nil {
+		return nil, nil, err
+	}
+	cl := changelog.Build(next.String(), previous, time.Now().Format("2006-01-02"), records, changelog.Options{Bots: r.opts.Bots})
+	if err = r.securityFiles(cl, from, next); err != nil {
+		return nil, nil, err
+	}
+	if r.opts.Dependencies.Enabled && from != "" {
+		if cl.Dependencies, err = deps.Diff(r.git, r.opts.Dependencies, from, "HEAD"); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err = r.addLinks(cl, from, r.TagName(next)); err != nil {
+		return nil, nil, err
+	}
+	if r.opts.Milestones.Enabled && len(next.PreRelease()) == 0 {
+		r.milestoneLink(cl, next)
+	}
+	if r.opts.Migrations.Enabled {
+		if err = r.migrations(cl, from, next); err != nil {
+			return nil, nil, err
+		}
+	}
+	if r.opts.Components.Enabled {
+		if err = r.components(cl, from); err != nil {
+			return nil, nil, err
+		}
+	}
+	if r.opts.Support.Enabled() {
+		if cl.EndOfLife, err = r.endOfLife(next); err != nil {
+			return nil, nil, err
+		}
+	}
+	if r.opts.Summary.Enabled && !cl.IsEmpty() {
+		if err = r.summarize(cl); err != nil {
+			if r.opts.Summary.Required {
+				return nil, nil, err
+			}
+			log.Warn("skip release summary: %s", err)
+		}
+	}
+	return cl, records, nil
+}
+
+// endOfLife 按支持策略计算发布 next 后停止支持的版本说明
+func (r *Releaser) endOfLife(next semver.Semver) ([]string, error) {
+	list, _, err := tags.List(r.git, r.opts.TagPrefix)
+	if err != nil {
+		return nil, err
+	}
+	versions := make([]semver.Semver, 0, len(list))
+	for _, t := range list {
+		versions = append(versions, t.Version)
+	}
+	return support.Notes(r.opts.Support.Dropped(versions, next)), nil
+}
+
+// summarize 生成变更摘要并写入变更日志
+func (r *Releaser) summarize(cl *changelog.Changelog) error {
+	s, err := summary.New(r.opts.Summary)
+	if err != nil {
+		return err
+	}
+	cl.Highlights, err = s.Summarize(summary.NewRequest(cl))
+	return err
+}
+
+// approve 在代码托管平台上等待发布审批
+func (r *Releaser) approve(res *Result) error {
+	p, err := provider.New(r.opts.Provider)
+	if err != nil {
+		return err
+	}
+	return approval.Wait(r.opts.Approval, p, res.Commit, fmt.Sprintf("release %s", res.Tag))
+}
+
+// latest 是否在平台上标记为最新版本，维护 stable 浮动标签时与其保持一致，否则维护分支及预发布版本不标记
+func (r *Releaser) latest(res *Result) bool {
+	if r.opts.Aliases.has(AliasStable) {
+		return IsStable(res.Aliases)
+	}
+	return !res.Maintenance && len(res.Version.PreRelease()) == 0
+}
+
+// publish 在代码托管平台创建版本发布，维护分支及预发布版本不标记为最新版本
+func (r *Releaser) publish(res *Result) error {
+	p, err := provider.New(r.opts.Provider)
+	if err != nil {
+		return err
+	}
+	prerelease := len(res.Version.PreRelease()) > 0
+	created, err := p.CreateRelease(&provider.Release{
+		Tag:        res.Tag,
+		Name:       res.Tag,
+		Body:       res.Changelog.Markdown(),
+		Prerelease: prerelease,
+		Latest:     r.latest(res),
+	})
+	if err != nil {
+		return fmt.Errorf("create %s release %s: %w", p.Name(), res.Tag, err)
+	}
+	res.ReleaseURL = created.URL
+	log.Info("created %s release %s", p.Name(), created.URL)
+	for i, a := range res.Artifacts {
+		if res.Artifacts[i].URL, err = p.UploadAsset(created, a.Name, a.Path); err != nil {
+			return fmt.Errorf("upload %s to %s release %s: %w", a.Name, p.Name(), res.Tag, err)
+		}
+		log.Info("uploaded %s", a.Name)
+	}
+	return nil
+}
+
+// store 上传发布产物及变更日志到所有对象存储，正式版本同时更新固定目录
+func (r *Releaser) store(res *Result) error {
+	if len(r.opts.Storage) == 0 {
+		return nil
+	}
+	rel := storage.Release{
+		Version: res.Version.String(),
+		Tag:     res.Tag,
+		Latest:  !res.Maintenance && len(res.Version.PreRelease()) == 0,
+		Files:   storage.Files(res.Artifacts, res.Changelog.Markdown()),
+	}
+	for _, cfg := range r.opts.Storage {
+		b, err := storage.New(cfg)
+		if err != nil {
+			return fmt.Errorf("release %s succeeded but %w", res.Tag, err)
+		}
+		objects, err := storage.Upload(b, cfg, rel)
+		res.Storage = append(res.Storage, objects...)
+		if err != nil {
+			return fmt.Errorf("release %s succeeded but %w", res.Tag, err)
+		}
+		log.Info("uploaded %d files to %s", len(objects), cfg.String())
+	}
+	return nil
+}
+
+// upload 上传发布产物到所有制品仓库
+func (r *Releaser) upload(res *Result) error {
+	if len(r.opts.Repositories) == 0 || len(res.Artifacts) == 0 {
+		return nil
+	}
+	rel := binrepo.Release{Version: res.Version.String(), Tag: res.Tag, Commit: res.Commit, Files: res.Artifacts}
+	for _, cfg := range r.opts.Repositories {
+		p, err := binrepo.New(cfg)
+		if err != nil {
+			return fmt.Errorf("release %s succeeded but %w", res.Tag, err)
+		}
+		log.AddSecret(p.Password())
+		objects, err := p.Publish(rel)
+		res.Packages = append(res.Packages, objects...)
+		if err != nil {
+			return fmt.Errorf("release %s succeeded but %w", res.Tag, err)
+		}
+		log.Info("uploaded %d files to %s", len(objects), cfg)
+	}
+	return nil
+}
+
+// pushOCI 以 OCI 制品推送发布产物到所有镜像仓库
+func (r *Releaser) pushOCI(res *Result) error {
+	annotations := map[string]string{registry.AnnotationVersion: res.Version.String(), registry.AnnotationRevision: res.Commit}
+	for _, target := range r.opts.OCI {
+		files, err := target.Select(res.Artifacts)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			continue
+		}
+		ref, err := registry.ParseReference(target.Repository)
+		if err != nil {
+			return err
+		}
+		client, err := registry.New(target.Registry)
+		if err != nil {
+			return err
+		}
+		tags := target.Tags(res.Version)
+		if target.Floating && r.opts.Aliases.Enabled() {
+			// 维护浮动标签时镜像的浮动标签与其一致
+			tags = tags[:1]
+			for _, a := range res.Aliases {
+				tags = append(tags, a.Docker)
+			}
+		}
+		digest, err := client.Push(ref, target.ArtifactType, files, annotations, tags...)
+		if err != nil {
+			return fmt.Errorf("release %s succeeded but push %s: %w", res.Tag, target.Repository, err)
+		}
+		ref.Tag, ref.Digest = tags[0], digest
+		res.OCI = append(res.OCI, ref.String())
+		log.Info("pushed %d files to %s", len(files), ref)
+	}
+	return nil
+}
+
+// updateTaps 正式版本发布后更新 Homebrew 公式及 Scoop 清单，名称默认为仓库名称
+func (r *Releaser) updateTaps(res *Result) error {
+	h, s := r.opts.Homebrew, r.opts.Scoop
+	if res.Maintenance || len(res.Version.PreRelease()) > 0 || !(h.Enabled() || s.Enabled()) {
+		return nil
+	}
+	name := path.Base(r.opts.Provider.Repo)
+	if r.opts.Provider.Repo == "" {
+		abs, _ := filepath.Abs(r.opts.Cwd)
+		name = filepath.Base(abs)
+	}
+	data := tap.Data{Version: res.Version.String(), Tag: res.Tag, BuildNumber: res.BuildNumber, Assets: res.Artifacts}
+	commit := gitops.Data{Version: res.Version.String(), Tag: res.Tag, Commit: res.Commit, BuildNumber: res.BuildNumber}
+	if res.Previous != nil {
+		commit.Previous = res.Previous.String()
+	}
+	if h.Enabled() {
+		if h.Name == "" {
+			h.Name = name
+		}
+		updated, err := h.Update(data, commit)
+		if err != nil {
+			return fmt.Errorf("release %s succeeded but homebrew %w", res.Tag, err)
+		}
+		res.Taps = append(res.Taps, updated)
+	}
+	if s.Enabled() {
+		if s.Name == "" {
+			s.Name = name
+		}
+		updated, err := s.Update(data, commit)
+		if err != nil {
+			return fmt.Errorf("release %s succeeded but scoop %w", res.Tag, err)
+		}
+		res.Taps = append(res.Taps, updated)
+	}
+	return nil
+}
+
+// generateSBOM 生成 SBOM 及签名文件并追加到发布产物
+func (r *Releaser) generateSBOM(res *Result) error {
+	name := r.opts.Provider.Repo
+	if name == "" {
+		name, _ = filepath.Abs(r.opts.Cwd)
+	}
+	files, err := sbom.Generate(r.opts.SBOM, r.opts.Cwd, filepath.Base(name), res.Version.String())
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		a, err := artifact.New(file)
+		if err != nil {
+			return err
+		}
+		res.Artifacts = append(res.Artifacts, *a)
+	}
+	return nil
+}
+
+// deploy 更新所有部署仓库中的版本，任一失败时停止
+func (r *Releaser) deploy(res *Result) error {
+	data := gitops.Data{Version: res.Version.String(), Tag: res.Tag, Commit: res.Commit, BuildNumber: res.BuildNumber}
+	if res.Previous != nil {
+		data.Previous = res.Previous.String()
+	}
+	for _, target := range r.opts.GitOps {
+		deployed, err := gitops.Apply(target, data)
+		if err != nil {
+			return fmt.Errorf("release %s succeeded but %w", res.Tag, err)
+		}
+		res.Deployments = append(res.Deployments, deployed)
+	}
+	return nil
+}
+
+// generateProvenance 为已有的发布产物生成来源证明并追加到发布产物
+func (r *Releaser) generateProvenance(res *Result, started time.Time) error {
+	uri, err := r.git.RemoteURL(r.opts.Remote)
+	if err != nil {
+		return err
+	}
+	src := provenance.Source{URI: uri, Ref: "refs/tags/" + res.Tag, Commit: res.Commit}
+	params := map[string]interface{}{
+		"version":   res.Version.String(),
+		"tag":       res.Tag,
+		"branch":    res.Branch,
+		"release":   r.opts.Release.String(),
+		"preid":     r.opts.PreId,
+		"remote":    r.opts.Remote,
+		"artifacts": r.opts.Artifacts,
+	}
+	statement, err := provenance.NewStatement(r.opts.Provenance, src, res.Artifacts, params, started)
+	if err != nil {
+		return err
+	}
+	files, err := provenance.Write(r.opts.Provenance, r.opts.Cwd, statement, res.Artifacts)
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		a, err := artifact.New(file)
+		if err != nil {
+			return err
+		}
+		res.Artifacts = append(res.Artifacts, *a)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package release
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+func TestReleaser_NextPreId(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.2.0-alpha.3")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "fix: crash")
+
+	_, err := New(Options{Cwd: repo, DryRun: true, Release: semver.PreRelease, PreId: "beta..1"}).Release()
+	if err == nil || !strings.Contains(err.Error(), "invalid prerelease identifier") {
+		t.Errorf("expected invalid identifier error, but %v got", err)
+	}
+	_, err = New(Options{Cwd: repo, DryRun: true, Release: semver.PreRelease, PreId: "beta", PreIdMode: semver.PreIdRefuse}).Release()
+	if !errors.Is(err, semver.ErrPreIdSwitchRefused) {
+		t.Errorf("expected ErrPreIdSwitchRefused, but %v got", err)
+	}
+	_, err = New(Options{Cwd: repo, DryRun: true, Target: "1.3.0", PreId: "beta..1"}).Release()
+	if err == nil || !strings.Contains(err.Error(), "invalid prerelease identifier") {
+		t.Errorf("expected invalid identifier error for target version, but %v got", err)
+	}
+	res, err := New(Options{Cwd: repo, DryRun: true, Release: semver.PreRelease, PreId: "beta"}).Release()
+	if err != nil || res.Version.String() != "1.2.0-beta" {
+		t.Errorf("expected 1.2.0-beta, but %v %v got", res, err)
+	}
+}
@@ -0,0 +1,122 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/tmpl"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+const (
+	DefaultSecurityDir      = ".changes/security" // 安全变更文件目录
+	DefaultAdvisorySeverity = "medium"            // 未指定严重程度时安全公告的严重程度
+)
+
+// SecurityConfig 发布说明中的 Security 分组及平台安全公告，对应配置文件 release.security 节点；
+// 范围为 security 或带有 Security、CVE 脚注的提交始终归入 Security 分组
+type SecurityConfig struct {
+	Dir        string `mapstructure:"dir"`        // 安全变更文件目录，默认 .changes/security，收集自上个版本以来新增的 *.md 文件
+	Link       string `mapstructure:"link"`       // 安全变更文件链接模板，如 https://github.com/org/repo/blob/{{ .Tag }}/{{ .Path }}，默认使用相对路径
+	Advisories bool   `mapstructure:"advisories"` // 发布后为每个安全修复在平台上创建安全公告草稿，已带有 GHSA 编号的跳过
+	Ecosystem  string `mapstructure:"ecosystem"`  // 安全公告中受影响软件包的生态，如 go、npm、maven，创建安全公告时必填
+	Package    string `mapstructure:"package"`    // 受影响的软件包名称，默认仓库名称
+}
+
+func (c SecurityConfig) dir() string {
+	if c.Dir == "" {
+		return DefaultSecurityDir
+	}
+	return c.Dir
+}
+
+// Validate 创建安全公告时检查软件包生态
+func (c SecurityConfig) Validate() error {
+	if c.Advisories && c.Ecosystem == "" {
+		return errors.New("security.ecosystem is required to create security advisories")
+	}
+	return nil
+}
+
+// securityFiles 收集自 from 以来新增的安全变更文件
+func (r *Releaser) securityFiles(cl *changelog.Changelog, from string, next semver.Semver) error {
+	if from == "" {
+		from = emptyTree
+	}
+	files, err := r.git.AddedFiles(from, "HEAD", path.Join(r.opts.Security.dir(), "*.md"))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		content, err := r.git.Show("HEAD", file)
+		if err != nil {
+			return err
+		}
+		link := ""
+		if r.opts.Security.Link != "" {
+			data := map[string]string{"Tag": r.TagName(next), "Version": next.String(), "Path": file}
+			if link, err = tmpl.Render("security", r.opts.Security.Link, data); err != nil {
+				return err
+			}
+		}
+		cl.Security = append(cl.Security, changelog.SecurityDocument(file, content, link))
+	}
+	return nil
+}
+
+// createAdvisories 为发布说明中的安全修复创建平台安全公告草稿，修复版本为本次发布的版本
+func (r *Releaser) createAdvisories(res *Result) error {
+	p, err := provider.New(r.opts.Provider)
+	if err != nil {
+		return err
+	}
+	publisher, ok := p.(provider.AdvisoryPublisher)
+	if !ok {
+		return fmt.Errorf("provider %s does not support security advisories", p.Name())
+	}
+	cfg := r.opts.Security
+	pkg := cfg.Package
+	if pkg == "" {
+		pkg = path.Base(r.opts.Provider.Repo)
+	}
+	for _, fix := range res.Changelog.Security {
+		advisory := &provider.Advisory{
+			Summary:     fix.Title,
+			Description: fix.Description,
+			Severity:    fix.Severity,
+			Ecosystem:   cfg.Ecosystem,
+			Package:     pkg,
+			Vulnerable:  "< " + res.Version.String(),
+			Patched:     res.Version.String(),
+		}
+		skip := false
+		for _, id := range fix.IDs {
+			switch {
+			case strings.HasPrefix(id, "GHSA-"):
+				skip = true
+			case advisory.CVE == "":
+				advisory.CVE = id
+			}
+		}
+		if skip {
+			continue
+		}
+		if advisory.Description == "" {
+			advisory.Description = fix.Title
+		}
+		if advisory.Severity == "" {
+			advisory.Severity = DefaultAdvisorySeverity
+		}
+		created, err := publisher.CreateAdvisory(advisory)
+		if err != nil {
+			return fmt.Errorf("create %s security advisory %q: %w", p.Name(), fix.Title, err)
+		}
+		log.Info("created draft security advisory %s", created.URL)
+	}
+	return nil
+}
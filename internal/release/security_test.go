@@ -0,0 +1,34 @@
+package release
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReleaser_SecurityFiles(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.0.0")
+	dir := filepath.Join(repo, ".changes", "security")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	content := "# Path traversal in archive extraction\n\nSeverity: critical\n\nFixes GHSA-vp9c-fpxx-744v.\n"
+	_ = os.WriteFile(filepath.Join(dir, "traversal.md"), []byte(content), 0o644)
+	gitRun(t, repo, "add", ".")
+	gitRun(t, repo, "commit", "-q", "-m", "fix: sanitize archive paths")
+
+	res, err := New(Options{Cwd: repo, DryRun: true}).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixes := res.Changelog.Security
+	if len(fixes) != 1 || fixes[0].Severity != "critical" || len(fixes[0].IDs) != 1 || fixes[0].IDs[0] != "GHSA-vp9c-fpxx-744v" {
+		t.Fatalf("unexpected security fixes %+v", fixes)
+	}
+	want := "* Path traversal in archive extraction — [GHSA-vp9c-fpxx-744v](https://github.com/advisories/GHSA-vp9c-fpxx-744v), critical severity, see [.changes/security/traversal.md](.changes/security/traversal.md)"
+	if md := res.Changelog.Markdown(); !strings.Contains(md, want) {
+		t.Errorf("expected %q in changelog, but\n%s", want, md)
+	}
+}
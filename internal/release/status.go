@@ -0,0 +1,83 @@
+package release
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/provider"
+)
+
+// 展示发布影响的提交状态名称
+const (
+	StatusVersion   = "autoctl/version"
+	StatusChangelog = "autoctl/changelog"
+)
+
+// Statuses 将演练结果转换为提交状态，合并请求页面据此展示合并后的下一个版本及变更日志概要；
+// 演练失败时版本状态为 failure，没有可发布的提交时两个状态均为 success
+func Statuses(res *Result, err error) []*provider.CommitStatus {
+	version := &provider.CommitStatus{Context: StatusVersion, State: provider.StateSuccess}
+	notes := &provider.CommitStatus{Context: StatusChangelog, State: provider.StateSuccess}
+	var noChange *NoChangeError
+	switch {
+	case errors.As(err, &noChange):
+		version.Description = fmt.Sprintf("autoctl: no release, no releasable commits since %s", noChange.Tag)
+		notes.Description = "changelog preview: no changes"
+	case err != nil:
+		version.State = provider.StateFailure
+		version.Description = fmt.Sprintf("autoctl: release would fail, %s", err)
+		notes.State = provider.StateError
+		notes.Description = "changelog preview: unavailable"
+	case res.Skipped:
+		version.Description = fmt.Sprintf("autoctl: no release, version stays %s", res.Version)
+		notes.Description = "changelog preview: no changes"
+	default:
+		version.Description = fmt.Sprintf("autoctl: next version %s", res.Version)
+		if res.Previous != nil {
+			version.Description += fmt.Sprintf(" (from %s)", res.Previous)
+		}
+		notes.Description = "changelog preview: " + changelogSummary(res.Changelog)
+	}
+	return []*provider.CommitStatus{version, notes}
+}
+
+// changelogSummary 变更日志各分组的提交数量，如 2 Features, 1 Bug Fixes, 1 breaking
+func changelogSummary(cl *changelog.Changelog) string {
+	if cl == nil || cl.IsEmpty() {
+		return "no changes"
+	}
+	var parts []string
+	for _, s := range cl.Sections {
+		parts = append(parts, fmt.Sprintf("%d %s", len(s.Commits), s.Title))
+	}
+	if n := len(cl.Breaking); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d breaking", n))
+	}
+	if n := len(cl.Security); n > 0 {
+		parts = append(parts, fmt.Sprintf("%d security", n))
+	}
+	if len(parts) == 0 {
+		return "no changes"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ReportStatus 在代码托管平台上设置提交状态
+func (r *Releaser) ReportStatus(statuses []*provider.CommitStatus) error {
+	p, err := provider.New(r.opts.Provider)
+	if err != nil {
+		return err
+	}
+	reporter, ok := p.(provider.StatusReporter)
+	if !ok {
+		return fmt.Errorf("provider %s does not support commit statuses", p.Name())
+	}
+	for _, s := range statuses {
+		if err = reporter.SetCommitStatus(s); err != nil {
+			return fmt.Errorf("set commit status %s: %w", s.Context, err)
+		}
+	}
+	return nil
+}
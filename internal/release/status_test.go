@@ -0,0 +1,33 @@
+package release
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/provider"
+)
+
+func TestStatuses(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.3.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: export")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "fix: crash")
+
+	res, err := New(Options{Cwd: repo, DryRun: true}).Release()
+	statuses := Statuses(res, err)
+	if statuses[0].Description != "autoctl: next version 1.4.0 (from 1.3.0)" || statuses[0].State != provider.StateSuccess {
+		t.Errorf("unexpected version status %+v", statuses[0])
+	}
+	if statuses[1].Description != "changelog preview: 1 Features, 1 Bug Fixes" {
+		t.Errorf("unexpected changelog status %+v", statuses[1])
+	}
+
+	statuses = Statuses(nil, errors.New("branch dev is not allowed"))
+	if statuses[0].State != provider.StateFailure || statuses[1].State != provider.StateError {
+		t.Errorf("expected failed statuses, but %+v %+v got", statuses[0], statuses[1])
+	}
+	statuses = Statuses(nil, &NoChangeError{Tag: "v1.3.0"})
+	if statuses[0].State != provider.StateSuccess || statuses[1].Description != "changelog preview: no changes" {
+		t.Errorf("unexpected no change statuses %+v %+v", statuses[0], statuses[1])
+	}
+}
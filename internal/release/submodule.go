@@ -0,0 +1,109 @@
+package release
+
+import (
+	"fmt"
+
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// DefaultSubmoduleMessage 更新子模块记录的提交信息
+const DefaultSubmoduleMessage = "chore(release): bump submodules"
+
+// SubmodulesConfig 子模块，对应配置文件 release.submodules 节点
+type SubmodulesConfig struct {
+	Bump    bool     `mapstructure:"bump"`    // 打标签前将子模块更新为远程跟踪分支的最新提交，并提交父仓库中的记录
+	Tag     bool     `mapstructure:"tag"`     // 在子模块记录的提交上创建同名标签，推送时推送到子模块的远程仓库
+	Paths   []string `mapstructure:"paths"`   // 处理的子模块路径，为空时处理全部子模块
+	Message string   `mapstructure:"message"` // 更新子模块记录的提交信息，默认 chore(release): bump submodules
+}
+
+// submodules 配置中指定的子模块，未初始化的子模块不处理
+func (r *Releaser) submodules() ([]git.Submodule, error) {
+	all, err := r.git.Submodules()
+	if err != nil {
+		return nil, err
+	}
+	selected := make([]git.Submodule, 0, len(all))
+	for _, s := range all {
+		if !s.Initialized {
+			log.Warn("skip uninitialized submodule %s", s.Path)
+			continue
+		}
+		if len(r.opts.Submodules.Paths) == 0 {
+			selected = append(selected, s)
+			continue
+		}
+		for _, p := range r.opts.Submodules.Paths {
+			if p == s.Path {
+				selected = append(selected, s)
+				break
+			}
+		}
+	}
+	return selected, nil
+}
+
+// bumpSubmodules 更新子模块并提交父仓库中的记录，没有变化时不提交
+func (r *Releaser) bumpSubmodules() error {
+	if err := r.git.UpdateSubmodules(r.opts.Submodules.Paths...); err != nil {
+		return err
+	}
+	subs, err := r.submodules()
+	if err != nil {
+		return err
+	}
+	paths := make([]string, 0, len(subs))
+	for _, s := range subs {
+		paths = append(paths, s.Path)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	top, err := r.git.TopLevel()
+	if err != nil {
+		return err
+	}
+	root := &git.Plus{Cwd: top, Verbose: r.opts.Verbose}
+	changed, err := root.HasChanges(paths...)
+	if err != nil || !changed {
+		return err
+	}
+	message := r.opts.Submodules.Message
+	if message == "" {
+		message = DefaultSubmoduleMessage
+	}
+	if err = root.Commit(message, paths...); err != nil {
+		return err
+	}
+	log.Info("bumped submodules %v", paths)
+	return nil
+}
+
+// tagSubmodules 在子模块记录的提交上创建与父仓库相同的标签，push 为 true 时推送到子模块的 origin
+func (r *Releaser) tagSubmodules(tag string, push bool) error {
+	subs, err := r.submodules()
+	if err != nil {
+		return err
+	}
+	for _, s := range subs {
+		sub, err := r.git.SubmodulePlus(s.Path)
+		if err != nil {
+			return err
+		}
+		if sub.TagExists(tag) {
+			log.Warn("submodule %s already has tag %s", s.Path, tag)
+			continue
+		}
+		if _, err = sub.Run("tag", "-a", tag, "-m", fmt.Sprintf("release %s", tag), s.Commit); err != nil {
+			return fmt.Errorf("submodule %s: %w", s.Path, err)
+		}
+		if push {
+			if err = sub.PushTag("origin", tag); err != nil {
+				return fmt.Errorf("submodule %s: %w", s.Path, err)
+			}
+		}
+		log.Info("tagged submodule %s at %.7s", s.Path, s.Commit)
+	}
+	return nil
+}
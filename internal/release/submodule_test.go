@@ -0,0 +1,56 @@
+package release
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReleaser_Worktree(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.0.0")
+	worktree := filepath.Join(t.TempDir(), "wt")
+	gitRun(t, repo, "worktree", "add", "-q", "-b", "feature", worktree)
+	gitRun(t, worktree, "commit", "-q", "--allow-empty", "-m", "feat: from worktree")
+
+	r := New(Options{Cwd: worktree})
+	if ok, err := r.Git().IsWorktree(); err != nil || !ok {
+		t.Fatalf("expected worktree, but %v %v got", ok, err)
+	}
+	res, err := New(Options{Cwd: worktree}).Release()
+	if err != nil || res.Tag != "v1.1.0" {
+		t.Fatalf("expected v1.1.0 released from the worktree, but %v %v got", res, err)
+	}
+	// 标签保存在公共目录，主工作区同样可见
+	if out := gitRun(t, repo, "tag", "-l", "v1.1.0"); strings.TrimSpace(out) != "v1.1.0" {
+		t.Errorf("expected tag visible in the main worktree, but %q got", out)
+	}
+}
+
+func TestReleaser_Submodules(t *testing.T) {
+	lib := newRepo(t)
+	repo := newRepo(t)
+	gitRun(t, repo, "-c", "protocol.file.allow=always", "submodule", "add", "-q", lib, "lib")
+	gitRun(t, repo, "commit", "-q", "-m", "chore: add lib")
+	gitRun(t, repo, "tag", "v1.0.0")
+	gitRun(t, lib, "commit", "-q", "--allow-empty", "-m", "fix: lib crash")
+	libHead := strings.TrimSpace(gitRun(t, lib, "rev-parse", "HEAD"))
+
+	t.Setenv("GIT_CONFIG_COUNT", "1")
+	t.Setenv("GIT_CONFIG_KEY_0", "protocol.file.allow")
+	t.Setenv("GIT_CONFIG_VALUE_0", "always")
+	res, err := New(Options{Cwd: repo, Submodules: SubmodulesConfig{Bump: true, Tag: true}}).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tag != "v1.0.1" {
+		t.Fatalf("expected v1.0.1, but %s got", res.Tag)
+	}
+	if msg := gitRun(t, repo, "log", "-1", "--format=%s", "v1.0.1"); strings.TrimSpace(msg) != DefaultSubmoduleMessage {
+		t.Errorf("expected tag on the submodule bump commit, but %q got", msg)
+	}
+	sub := filepath.Join(repo, "lib")
+	if commit := strings.TrimSpace(gitRun(t, sub, "rev-parse", "v1.0.1^{commit}")); commit != libHead {
+		t.Errorf("expected submodule tagged at %s, but %s got", libHead, commit)
+	}
+}
@@ -0,0 +1,67 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// Override 人工指定目标版本的记录
+type Override struct {
+	Reason    string `json:"reason,omitempty"`    // 指定原因
+	Downgrade bool   `json:"downgrade,omitempty"` // 目标版本是否不大于当前版本
+}
+
+// ParseTarget 解析人工指定的目标版本，允许带有标签前缀
+func (r *Releaser) ParseTarget(target string) (semver.Semver, error) {
+	v, err := r.ParseTag(target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target version %q: %w", target, err)
+	}
+	return v, nil
+}
+
+// releaseTarget 发布人工指定的目标版本，不读取合并请求标签，也不检查是否存在可发布的提交；
+// 目标版本必须大于当前版本，允许降级时只要求与当前版本不同
+func (r *Releaser) releaseTarget(current semver.Semver) (*Result, error) {
+	next, err := r.ParseTarget(r.opts.Target)
+	if err != nil {
+		return nil, err
+	}
+	override := &Override{Reason: r.opts.Reason}
+	if current != nil {
+		switch c := next.Compare(current); {
+		case c == 0:
+			return nil, fmt.Errorf("target version %s equals current version %s", next, current)
+		case c < 0 && !r.opts.Downgrade:
+			return nil, fmt.Errorf("target version %s must be greater than current version %s, use --allow-downgrade to release it anyway", next, current)
+		case c < 0:
+			override.Downgrade = true
+			log.Warn("target version %s is lower than current version %s", next, current)
+		}
+	}
+	if override.Reason == "" {
+		log.Warn("no reason given for releasing target version %s", next)
+	}
+	return r.releaseVersion(current, next, override)
+}
+
+// tagMessage 标签说明，人工指定目标版本时附带原因
+func tagMessage(res *Result) string {
+	msg := fmt.Sprintf("release %s", res.Tag)
+	if res.Override == nil {
+		return msg
+	}
+	lines := []string{msg, ""}
+	if res.Previous != nil {
+		lines = append(lines, fmt.Sprintf("Version set manually from %s.", res.Previous))
+	} else {
+		lines = append(lines, "Version set manually.")
+	}
+	if res.Override.Reason != "" {
+		lines = append(lines, "Reason: "+res.Override.Reason)
+	}
+	return strings.Join(lines, "\n")
+}
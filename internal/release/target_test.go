@@ -0,0 +1,37 @@
+package release
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReleaser_Target(t *testing.T) {
+	repo := newRepo(t)
+	gitRun(t, repo, "tag", "v1.2.0")
+	gitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "chore: tidy")
+
+	res, err := New(Options{Cwd: repo, Target: "v2.0.0", Reason: "new licensing model"}).Release()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Tag != "v2.0.0" || res.Override == nil || res.Override.Downgrade {
+		t.Fatalf("expected v2.0.0 released as override, but %+v got", res)
+	}
+	msg := gitRun(t, repo, "tag", "-l", "--format=%(contents)", "v2.0.0")
+	if !strings.Contains(msg, "Version set manually from 1.2.0.") || !strings.Contains(msg, "Reason: new licensing model") {
+		t.Errorf("unexpected tag message %q", msg)
+	}
+
+	if _, err = New(Options{Cwd: repo, Target: "2.0.0"}).Release(); err == nil {
+		t.Error("expected error releasing the current version")
+	}
+	if _, err = New(Options{Cwd: repo, Target: "1.5.0"}).Release(); err == nil || !strings.Contains(err.Error(), "--allow-downgrade") {
+		t.Errorf("expected downgrade error, but %v got", err)
+	}
+	if res, err = New(Options{Cwd: repo, Target: "1.5.0", Downgrade: true}).Release(); err != nil || !res.Override.Downgrade {
+		t.Errorf("expected downgrade to 1.5.0, but %v %v got", res, err)
+	}
+	if _, err = New(Options{Cwd: repo, Target: "next"}).Release(); err == nil {
+		t.Error("expected invalid target error")
+	}
+}
@@ -0,0 +1,189 @@
+// Package report 单次执行的报告，命令结束后写入 JSON 文件，便于作为 CI 制品上传并由看板读取
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/fileutil"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// 执行状态
+const (
+	StatusSuccess     = "success"
+	StatusFailed      = "failed"
+	StatusInterrupted = "interrupted"
+)
+
+// Report 执行报告
+type Report struct {
+	Command  string                 `json:"command"`            // 命令，如 release、kustomize apply
+	Args     []string               `json:"args,omitempty"`     // 位置参数
+	Flags    map[string]string      `json:"flags,omitempty"`    // 显式设置的命令行参数，密钥已屏蔽
+	Config   string                 `json:"config,omitempty"`   // 使用的配置文件
+	Started  time.Time              `json:"started"`            // 开始时间
+	Finished time.Time              `json:"finished"`           // 结束时间
+	Duration time.Duration          `json:"duration"`           // 耗时，单位纳秒
+	Status   string                 `json:"status"`             // success | failed | interrupted
+	ExitCode int                    `json:"exitCode"`           // 退出码
+	Error    string                 `json:"error,omitempty"`    // 失败原因
+	Versions map[string]string      `json:"versions,omitempty"` // 计算的版本，如 previous、next
+	Steps    []Step                 `json:"steps,omitempty"`    // 执行的步骤及耗时
+	Warnings []string               `json:"warnings,omitempty"` // 警告及错误日志
+	Links    map[string]string      `json:"links,omitempty"`    // 相关链接，如 release 发布页面、job 流水线任务
+	Results  map[string]interface{} `json:"results,omitempty"`  // 命令的完整结果，如 release 为发布结果
+}
+
+// Step 执行的步骤
+type Step struct {
+	Name     string        `json:"name"`
+	Duration time.Duration `json:"duration"` // 单位纳秒
+}
+
+var (
+	mu      sync.Mutex
+	file    string
+	current = &Report{Started: time.Now()}
+)
+
+// Enable 命令结束后将报告写入 name，为空时不写入
+func Enable(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	file = name
+}
+
+// Enabled 是否需要写入报告，未开启时调用方可跳过收集开销较大的内容
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return file != ""
+}
+
+// SetConfig 记录使用的配置文件
+func SetConfig(name string) {
+	mu.Lock()
+	defer mu.Unlock()
+	current.Config = name
+}
+
+// SetVersion 记录计算的版本，如 SetVersion("next", "1.2.0")
+func SetVersion(name, version string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current.Versions == nil {
+		current.Versions = map[string]string{}
+	}
+	current.Versions[name] = version
+}
+
+// AddStep 记录执行的步骤
+func AddStep(name string, d time.Duration) {
+	mu.Lock()
+	defer mu.Unlock()
+	current.Steps = append(current.Steps, Step{Name: name, Duration: d})
+}
+
+// AddLink 记录相关链接，url 为空时忽略
+func AddLink(name, url string) {
+	if url == "" {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if current.Links == nil {
+		current.Links = map[string]string{}
+	}
+	current.Links[name] = log.Redact(url)
+}
+
+// SetResult 记录命令的结果
+func SetResult(name string, v interface{}) {
+	mu.Lock()
+	defer mu.Unlock()
+	if current.Results == nil {
+		current.Results = map[string]interface{}{}
+	}
+	current.Results[name] = v
+}
+
+// Warn 记录警告
+func Warn(message string) {
+	mu.Lock()
+	defer mu.Unlock()
+	current.Warnings = append(current.Warnings, message)
+}
+
+// Finish 记录执行的命令及结果并写入报告，未开启时不做任何事
+func Finish(cmd *cobra.Command, err error, exitCode int, interrupted bool) error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == "" {
+		return nil
+	}
+	r := current
+	if cmd != nil {
+		r.Command = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+		r.Args = cmd.Flags().Args()
+		cmd.Flags().Visit(func(f *pflag.Flag) {
+			if r.Flags == nil {
+				r.Flags = map[string]string{}
+			}
+			r.Flags[f.Name] = log.Redact(f.Value.String())
+		})
+	}
+	r.Finished = time.Now()
+	r.Duration = r.Finished.Sub(r.Started)
+	r.ExitCode = exitCode
+	switch {
+	case err == nil:
+		r.Status = StatusSuccess
+	case interrupted:
+		r.Status = StatusInterrupted
+	default:
+		r.Status = StatusFailed
+	}
+	if err != nil {
+		r.Error = log.Redact(err.Error())
+	}
+	if u := jobURL(); u != "" {
+		if r.Links == nil {
+			r.Links = map[string]string{}
+		}
+		r.Links["job"] = u
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if dir := filepath.Dir(file); dir != "." {
+		if err = os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+	if err = fileutil.WriteFile(file, append([]byte(log.Redact(string(data))), '\n'), 0o644); err != nil {
+		return fmt.Errorf("write run report: %w", err)
+	}
+	return nil
+}
+
+// jobURL 当前 CI 任务的地址
+func jobURL() string {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		return fmt.Sprintf("%s/%s/actions/runs/%s", os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_RUN_ID"))
+	case os.Getenv("GITLAB_CI") == "true":
+		return os.Getenv("CI_JOB_URL")
+	default:
+		// Jenkins
+		return os.Getenv("BUILD_URL")
+	}
+}
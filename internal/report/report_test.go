@@ -0,0 +1,58 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestFinish(t *testing.T) {
+	t.Setenv("GITHUB_ACTIONS", "")
+	t.Setenv("GITLAB_CI", "true")
+	t.Setenv("CI_JOB_URL", "https://gitlab.example.com/org/app/-/jobs/1")
+	name := filepath.Join(t.TempDir(), "out", "run-report.json")
+	Enable(name)
+	defer Enable("")
+
+	root := &cobra.Command{Use: "autoctl"}
+	cmd := &cobra.Command{Use: "release", Run: func(*cobra.Command, []string) {}}
+	cmd.Flags().Bool("push", false, "")
+	root.AddCommand(cmd)
+	root.SetArgs([]string{"release", "minor", "--push"})
+	executed, _ := root.ExecuteC()
+
+	SetVersion("next", "1.2.0")
+	AddStep("tagged", time.Second)
+	AddLink("release", "https://github.com/org/app/releases/tag/v1.2.0")
+	AddLink("empty", "")
+	Warn("release lock expired")
+	if err := Finish(executed, errors.New("push rejected"), 1, false); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := Report{}
+	if err = json.Unmarshal(data, &r); err != nil {
+		t.Fatal(err)
+	}
+	if r.Command != "release" || len(r.Args) != 1 || r.Args[0] != "minor" || r.Flags["push"] != "true" {
+		t.Errorf("unexpected inputs %+v", r)
+	}
+	if r.Status != StatusFailed || r.ExitCode != 1 || r.Error != "push rejected" || r.Versions["next"] != "1.2.0" {
+		t.Errorf("unexpected outcome %+v", r)
+	}
+	if len(r.Steps) != 1 || r.Steps[0].Duration != time.Second || len(r.Warnings) != 1 {
+		t.Errorf("unexpected steps or warnings %+v", r)
+	}
+	if len(r.Links) != 2 || r.Links["job"] != "https://gitlab.example.com/org/app/-/jobs/1" {
+		t.Errorf("unexpected links %v", r.Links)
+	}
+}
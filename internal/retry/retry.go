@@ -0,0 +1,90 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/runctx"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// Policy 重试策略，等待时间按指数退避递增
+type Policy struct {
+	Attempts int           `mapstructure:"attempts"` // 最大尝试次数（含首次），小于等于 0 时使用默认值
+	Delay    time.Duration `mapstructure:"delay"`    // 首次重试前的等待时间
+	MaxDelay time.Duration `mapstructure:"maxDelay"` // 单次等待时间上限
+}
+
+// DefaultPolicy 默认最多尝试 3 次，等待 1s、2s
+var DefaultPolicy = Policy{Attempts: 3, Delay: time.Second, MaxDelay: 30 * time.Second}
+
+// Merge 以 p 为准，未设置的字段使用 other 中的值
+func (p Policy) Merge(other Policy) Policy {
+	if p.Attempts <= 0 {
+		p.Attempts = other.Attempts
+	}
+	if p.Delay == 0 {
+		p.Delay = other.Delay
+	}
+	if p.MaxDelay == 0 {
+		p.MaxDelay = other.MaxDelay
+	}
+	return p
+}
+
+// Backoff 第 n 次重试（从 1 开始）前的等待时间
+func (p Policy) Backoff(n int) time.Duration {
+	p = p.Merge(DefaultPolicy)
+	delay := p.Delay
+	for i := 1; i < n && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	return delay
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent 标记不可重试的错误，Do 遇到后立即返回
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Do 执行 fn 直到成功、返回 Permanent 错误或达到最大尝试次数，返回最后一次的错误
+func Do(p Policy, name string, fn func() error) error {
+	p = p.Merge(DefaultPolicy)
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(); err == nil {
+			return nil
+		}
+		var permanent *permanentError
+		if errors.As(err, &permanent) {
+			return permanent.err
+		}
+		if attempt >= p.Attempts {
+			return err
+		}
+		delay := p.Backoff(attempt)
+		log.Warn("%s failed (attempt %d/%d), retry in %s: %s", name, attempt, p.Attempts, delay, err)
+		if cerr := runctx.Sleep(delay); cerr != nil {
+			return fmt.Errorf("%w: %v", err, cerr)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package retry
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPolicy_Backoff(t *testing.T) {
+	p := Policy{Delay: time.Second, MaxDelay: 5 * time.Second}
+	expected := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, e := range expected {
+		if actual := p.Backoff(i + 1); actual != e {
+			t.Errorf("backoff %d expected %s, but %s got", i+1, e, actual)
+		}
+	}
+}
+
+func TestDo(t *testing.T) {
+	p := Policy{Attempts: 3, Delay: time.Millisecond}
+	transient := errors.New("connection reset")
+
+	calls := 0
+	err := Do(p, "succeed", func() error {
+		calls++
+		if calls < 3 {
+			return transient
+		}
+		return nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("expected success after 3 calls, but %d calls with err %v", calls, err)
+	}
+
+	calls = 0
+	err = Do(p, "exhausted", func() error {
+		calls++
+		return transient
+	})
+	if !errors.Is(err, transient) || calls != 3 {
+		t.Errorf("expected %v after 3 calls, but %d calls with err %v", transient, calls, err)
+	}
+
+	calls = 0
+	err = Do(p, "permanent", func() error {
+		calls++
+		return Permanent(transient)
+	})
+	if err != transient || calls != 1 {
+		t.Errorf("expected unwrapped permanent error after 1 call, but %d calls with err %v", calls, err)
+	}
+}
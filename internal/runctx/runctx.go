@@ -0,0 +1,162 @@
+// Package runctx 单次执行的取消上下文，收到 SIGINT、SIGTERM 或超过总超时时间时取消进行中的 git 命令、外部命令及 HTTP 请求，
+// 避免卡住的镜像仓库或代码托管平台请求让流水线空等到作业超时
+package runctx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// ErrInterrupted 收到中断信号
+var ErrInterrupted = errors.New("interrupted")
+
+// Config 各步骤的超时时间，对应配置文件 timeouts 节点，HTTP 请求的超时时间由 http.timeout 及各服务的 http 节点配置
+type Config struct {
+	Total   time.Duration `mapstructure:"total"`   // 单次执行的总超时时间，默认不限制
+	Git     time.Duration `mapstructure:"git"`     // 单个 git 命令的超时时间，如 clone、push，默认不限制
+	Command time.Duration `mapstructure:"command"` // 单个外部命令的超时时间，如 aws、cosign、syft 及配置的脚本，默认不限制
+}
+
+var (
+	mu   sync.RWMutex
+	root = context.Background()
+	cfg  Config
+)
+
+// Setup 设置超时时间并监听中断信号，返回的 stop 释放信号监听并恢复为不会取消的上下文；
+// 第一次中断时取消进行中的操作并正常退出，以便清理临时工作区，再次中断时立即结束进程
+func Setup(c Config) (stop func()) {
+	ctx, stopSignal := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	cancelTotal := context.CancelFunc(func() {})
+	if c.Total > 0 {
+		ctx, cancelTotal = context.WithTimeout(ctx, c.Total)
+	}
+	mu.Lock()
+	root, cfg = ctx, c
+	mu.Unlock()
+	git.SetContext(ctx, c.Git)
+	done := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+		select {
+		case <-done:
+			// stop 释放监听时取消的上下文
+			return
+		default:
+		}
+		if Interrupted() {
+			log.Warn("interrupted, cancelling (press Ctrl+C again to exit immediately)")
+		} else {
+			log.Warn("timed out after %s, cancelling", c.Total)
+		}
+		stopSignal()
+	}()
+	return func() {
+		close(done)
+		cancelTotal()
+		stopSignal()
+		// 命令结束后的清理及导出不受本次取消影响
+		mu.Lock()
+		root = context.Background()
+		mu.Unlock()
+		git.SetContext(context.Background(), c.Git)
+	}
+}
+
+// Context 单次执行的上下文
+func Context() context.Context {
+	mu.RLock()
+	defer mu.RUnlock()
+	return root
+}
+
+// Interrupted 是否因收到中断信号而取消，超过总超时时间时为 context.DeadlineExceeded
+func Interrupted() bool {
+	return errors.Is(Context().Err(), context.Canceled)
+}
+
+type timeoutKey struct{}
+
+// Command 外部命令的上下文，超过 timeouts.command 或单次执行取消时终止命令
+func Command() (context.Context, context.CancelFunc) {
+	mu.RLock()
+	d := cfg.Command
+	mu.RUnlock()
+	return WithTimeout(Context(), d)
+}
+
+// WithTimeout d 大于 0 时为 parent 设置超时时间，Wrap 据此说明超时原因
+func WithTimeout(parent context.Context, d time.Duration) (context.Context, context.CancelFunc) {
+	if d <= 0 {
+		return context.WithCancel(parent)
+	}
+	ctx, cancel := context.WithTimeout(context.WithValue(parent, timeoutKey{}, d), d)
+	return ctx, cancel
+}
+
+// Merge 返回 parent 的子上下文，单次执行取消时一并取消，如 HTTP 请求自带的上下文
+func Merge(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(parent)
+	r := Context()
+	if r.Done() == nil {
+		return ctx, cancel
+	}
+	go func() {
+		select {
+		case <-r.Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// Wrap 操作因超时或中断结束时在错误中说明原因
+func Wrap(ctx context.Context, err error) error {
+	if err == nil || ctx.Err() == nil {
+		return err
+	}
+	if Context().Err() != nil {
+		// 单次执行取消，而非该操作自身超时
+		if Interrupted() {
+			return fmt.Errorf("%w: %v", err, ErrInterrupted)
+		}
+		mu.RLock()
+		d := cfg.Total
+		mu.RUnlock()
+		return fmt.Errorf("%w: timed out after %s", err, d)
+	}
+	if d, ok := ctx.Value(timeoutKey{}).(time.Duration); ok {
+		return fmt.Errorf("%w: timed out after %s", err, d)
+	}
+	return fmt.Errorf("%w: %v", err, ctx.Err())
+}
+
+// Sleep 等待 d，单次执行取消时提前返回其原因
+func Sleep(d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	ctx := Context()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		if Interrupted() {
+			return ErrInterrupted
+		}
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,42 @@
+package runctx
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommand_Timeout(t *testing.T) {
+	stop := Setup(Config{Command: 50 * time.Millisecond})
+	defer stop()
+	ctx, cancel := Command()
+	defer cancel()
+	err := Wrap(ctx, exec.CommandContext(ctx, "sleep", "5").Run())
+	if err == nil || !strings.Contains(err.Error(), "timed out after 50ms") {
+		t.Errorf("expected command timed out, but %v got", err)
+	}
+	if Interrupted() {
+		t.Error("expected not interrupted")
+	}
+}
+
+func TestSetup_Total(t *testing.T) {
+	stop := Setup(Config{Total: 50 * time.Millisecond})
+	defer stop()
+	ctx, cancel := Merge(context.Background())
+	defer cancel()
+	if err := Sleep(5 * time.Second); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected sleep cancelled by the total timeout, but %v got", err)
+	}
+	select {
+	case <-ctx.Done():
+	case <-time.After(time.Second):
+		t.Fatal("expected merged context cancelled")
+	}
+	if err := Wrap(ctx, errors.New("GET /v2/")); err == nil || err.Error() != "GET /v2/: timed out after 50ms" {
+		t.Errorf("unexpected error %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+type cdxComponent struct {
+	BOMRef  string `json:"bom-ref"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+}
+
+type cdxDependency struct {
+	Ref       string   `json:"ref"`
+	DependsOn []string `json:"dependsOn,omitempty"`
+}
+
+// CycloneDXDocument 生成 CycloneDX 1.5 JSON，components 的第一个为主模块
+func CycloneDXDocument(name, version string, components []Component) ([]byte, error) {
+	if len(components) == 0 {
+		return nil, fmt.Errorf("no component found")
+	}
+	main := cdxComponent{BOMRef: components[0].PURL + "@" + version, Type: "application", Name: name, Version: version, PURL: components[0].PURL + "@" + version}
+	deps := cdxDependency{Ref: main.BOMRef}
+	list := make([]cdxComponent, 0, len(components)-1)
+	for _, c := range components[1:] {
+		list = append(list, cdxComponent{BOMRef: c.PURL, Type: "library", Name: c.Name, Version: c.Version, PURL: c.PURL})
+		deps.DependsOn = append(deps.DependsOn, c.PURL)
+	}
+	doc := map[string]interface{}{
+		"bomFormat":    "CycloneDX",
+		"specVersion":  "1.5",
+		"serialNumber": "urn:uuid:" + uuid(),
+		"version":      1,
+		"metadata": map[string]interface{}{
+			"timestamp": time.Now().UTC().Format(time.RFC3339),
+			"tools":     []map[string]string{{"vendor": "coffee377", "name": "autoctl"}},
+			"component": main,
+		},
+		"components":   list,
+		"dependencies": []cdxDependency{deps},
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+type spdxPackage struct {
+	SPDXID           string            `json:"SPDXID"`
+	Name             string            `json:"name"`
+	VersionInfo      string            `json:"versionInfo,omitempty"`
+	DownloadLocation string            `json:"downloadLocation"`
+	FilesAnalyzed    bool              `json:"filesAnalyzed"`
+	ExternalRefs     []spdxExternalRef `json:"externalRefs,omitempty"`
+}
+
+type spdxExternalRef struct {
+	Category string `json:"referenceCategory"`
+	Type     string `json:"referenceType"`
+	Locator  string `json:"referenceLocator"`
+}
+
+type spdxRelationship struct {
+	Element string `json:"spdxElementId"`
+	Type    string `json:"relationshipType"`
+	Related string `json:"relatedSpdxElement"`
+}
+
+var spdxIDReg = regexp.MustCompile(`[^a-zA-Z0-9.\-]+`)
+
+func spdxID(c Component) string {
+	return "SPDXRef-Package-" + spdxIDReg.ReplaceAllString(c.Name+"-"+c.Version, "-")
+}
+
+// SPDXDocument 生成 SPDX 2.3 JSON，components 的第一个为主模块
+func SPDXDocument(name, version string, components []Component) ([]byte, error) {
+	if len(components) == 0 {
+		return nil, fmt.Errorf("no component found")
+	}
+	packages := make([]spdxPackage, 0, len(components))
+	relationships := make([]spdxRelationship, 0, len(components))
+	mainID := ""
+	for i, c := range components {
+		if i == 0 {
+			c.Version = version
+			c.PURL += "@" + version
+		}
+		p := spdxPackage{
+			SPDXID:           spdxID(c),
+			Name:             c.Name,
+			VersionInfo:      c.Version,
+			DownloadLocation: "NOASSERTION",
+			ExternalRefs:     []spdxExternalRef{{Category: "PACKAGE-MANAGER", Type: "purl", Locator: c.PURL}},
+		}
+		packages = append(packages, p)
+		if i == 0 {
+			mainID = p.SPDXID
+			relationships = append(relationships, spdxRelationship{Element: "SPDXRef-DOCUMENT", Type: "DESCRIBES", Related: mainID})
+		} else {
+			relationships = append(relationships, spdxRelationship{Element: mainID, Type: "DEPENDS_ON", Related: p.SPDXID})
+		}
+	}
+	doc := map[string]interface{}{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              name + "-" + version,
+		"documentNamespace": "https://spdx.org/spdxdocs/" + name + "-" + version + "-" + uuid(),
+		"creationInfo": map[string]interface{}{
+			"created":  time.Now().UTC().Format(time.RFC3339),
+			"creators": []string{"Tool: autoctl"},
+		},
+		"packages":      packages,
+		"relationships": relationships,
+	}
+	return json.MarshalIndent(doc, "", "  ")
+}
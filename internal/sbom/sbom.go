@@ -0,0 +1,203 @@
+package sbom
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/runctx"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+const (
+	CycloneDX = "cyclonedx"
+	SPDX      = "spdx"
+
+	ToolAuto = "auto"
+	ToolGo   = "go"
+	ToolSyft = "syft"
+)
+
+// Config SBOM 生成配置，对应配置文件 release.sbom 节点
+type Config struct {
+	Enabled bool   `mapstructure:"enabled"` // 发布时生成 SBOM 并作为产物上传
+	Format  string `mapstructure:"format"`  // cyclonedx（默认）| spdx
+	Tool    string `mapstructure:"tool"`    // auto（默认，存在 syft 时使用 syft）| go | syft
+	Output  string `mapstructure:"output"`  // 输出文件，默认 sbom.cdx.json 或 sbom.spdx.json，相对路径基于工作目录
+	Sign    bool   `mapstructure:"sign"`    // 使用 cosign sign-blob 签名
+	Key     string `mapstructure:"key"`     // cosign 私钥，为空时使用无密钥签名
+}
+
+// Component 软件组件
+type Component struct {
+	Name    string
+	Version string
+	PURL    string
+}
+
+func (c Config) withDefaults() Config {
+	if c.Format == "" {
+		c.Format = CycloneDX
+	}
+	if c.Tool == "" {
+		c.Tool = ToolAuto
+	}
+	if c.Output == "" {
+		c.Output = "sbom.cdx.json"
+		if c.Format == SPDX {
+			c.Output = "sbom.spdx.json"
+		}
+	}
+	return c
+}
+
+// Generate 生成 SBOM 文件，返回文件路径，启用签名时同时返回签名文件路径
+func Generate(cfg Config, dir, name, version string) ([]string, error) {
+	cfg = cfg.withDefaults()
+	if cfg.Format != CycloneDX && cfg.Format != SPDX {
+		return nil, fmt.Errorf("unsupported sbom format %q, valid values are %s, %s", cfg.Format, CycloneDX, SPDX)
+	}
+	output := cfg.Output
+	if !filepath.IsAbs(output) && dir != "" {
+		output = filepath.Join(dir, output)
+	}
+	tool := cfg.Tool
+	if tool == ToolAuto {
+		tool = ToolGo
+		if _, err := exec.LookPath(ToolSyft); err == nil {
+			tool = ToolSyft
+		}
+	}
+	switch tool {
+	case ToolSyft:
+		if err := runSyft(dir, cfg.Format, output); err != nil {
+			return nil, err
+		}
+	case ToolGo:
+		components, err := GoModules(dir)
+		if err != nil {
+			return nil, err
+		}
+		var data []byte
+		if cfg.Format == SPDX {
+			data, err = SPDXDocument(name, version, components)
+		} else {
+			data, err = CycloneDXDocument(name, version, components)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err = os.WriteFile(output, data, 0o644); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported sbom tool %q, valid values are %s, %s, %s", cfg.Tool, ToolAuto, ToolGo, ToolSyft)
+	}
+	log.Info("generated %s sbom %s with %s", cfg.Format, output, tool)
+	files := []string{output}
+	if cfg.Sign {
+		signature, err := SignBlob(output, cfg.Key)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, signature)
+	}
+	return files, nil
+}
+
+type goModule struct {
+	Path    string
+	Version string
+	Main    bool
+	Replace *goModule
+}
+
+// GoModules 通过 go list -m all 获取模块依赖图，第一个组件为主模块
+func GoModules(dir string) ([]Component, error) {
+	ctx, cancel := runctx.Command()
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "go", "list", "-m", "-json", "all")
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	start := time.Now()
+	output, err := cmd.Output()
+	log.TraceCommand(cmd, start, err)
+	if err = runctx.Wrap(ctx, err); err != nil {
+		return nil, fmt.Errorf("go list -m all: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	components := make([]Component, 0)
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		m := goModule{}
+		if err = decoder.Decode(&m); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if m.Replace != nil && m.Replace.Version != "" {
+			m.Path, m.Version = m.Replace.Path, m.Replace.Version
+		}
+		c := Component{Name: m.Path, Version: m.Version, PURL: "pkg:golang/" + m.Path}
+		if m.Version != "" {
+			c.PURL += "@" + m.Version
+		}
+		components = append(components, c)
+	}
+	return components, nil
+}
+
+func runSyft(dir, format, output string) error {
+	syftFormat := "cyclonedx-json"
+	if format == SPDX {
+		syftFormat = "spdx-json"
+	}
+	ctx, cancel := runctx.Command()
+	defer cancel()
+	cmd := exec.CommandContext(ctx, ToolSyft, "scan", "dir:.", "--quiet", "-o", syftFormat+"="+output)
+	cmd.Dir = dir
+	start := time.Now()
+	out, err := cmd.CombinedOutput()
+	log.TraceCommand(cmd, start, err)
+	if err = runctx.Wrap(ctx, err); err != nil {
+		return fmt.Errorf("syft: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// SignBlob 使用 cosign 签名文件，返回签名文件路径（文件名追加 .sig）
+func SignBlob(file, key string) (string, error) {
+	signature := file + ".sig"
+	args := []string{"sign-blob", "--yes", "--output-signature", signature}
+	if key != "" {
+		args = append(args, "--key", key)
+	} else {
+		args = append(args, "--output-certificate", file+".pem")
+	}
+	ctx, cancel := runctx.Command()
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "cosign", append(args, file)...)
+	start := time.Now()
+	out, err := cmd.CombinedOutput()
+	log.TraceCommand(cmd, start, err)
+	if err = runctx.Wrap(ctx, err); err != nil {
+		return "", fmt.Errorf("cosign sign-blob: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	log.Info("signed %s", file)
+	return signature, nil
+}
+
+func uuid() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
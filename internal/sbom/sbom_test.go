@@ -0,0 +1,66 @@
+package sbom
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var components = []Component{
+	{Name: "github.com/coffee377/autoctl", PURL: "pkg:golang/github.com/coffee377/autoctl"},
+	{Name: "github.com/spf13/cobra", Version: "v1.7.0", PURL: "pkg:golang/github.com/spf13/cobra@v1.7.0"},
+}
+
+func TestCycloneDXDocument(t *testing.T) {
+	data, err := CycloneDXDocument("autoctl", "1.2.0", components)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := struct {
+		BOMFormat string `json:"bomFormat"`
+		Metadata  struct {
+			Component cdxComponent `json:"component"`
+		} `json:"metadata"`
+		Components []cdxComponent `json:"components"`
+	}{}
+	_ = json.Unmarshal(data, &doc)
+	if doc.BOMFormat != "CycloneDX" || doc.Metadata.Component.PURL != "pkg:golang/github.com/coffee377/autoctl@1.2.0" {
+		t.Errorf("unexpected metadata %+v", doc)
+	}
+	if len(doc.Components) != 1 || doc.Components[0].PURL != "pkg:golang/github.com/spf13/cobra@v1.7.0" {
+		t.Errorf("unexpected components %+v", doc.Components)
+	}
+}
+
+func TestSPDXDocument(t *testing.T) {
+	data, err := SPDXDocument("autoctl", "1.2.0", components)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := struct {
+		Packages      []spdxPackage      `json:"packages"`
+		Relationships []spdxRelationship `json:"relationships"`
+	}{}
+	_ = json.Unmarshal(data, &doc)
+	if len(doc.Packages) != 2 || doc.Packages[1].SPDXID != "SPDXRef-Package-github.com-spf13-cobra-v1.7.0" {
+		t.Errorf("unexpected packages %+v", doc.Packages)
+	}
+	if len(doc.Relationships) != 2 || doc.Relationships[1].Type != "DEPENDS_ON" {
+		t.Errorf("unexpected relationships %+v", doc.Relationships)
+	}
+}
+
+func TestGenerate_Go(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n\ngo 1.18\n"), 0o644)
+	t.Setenv("GOFLAGS", "-mod=mod")
+	t.Setenv("GOWORK", "off")
+	files, err := Generate(Config{Tool: ToolGo}, dir, "app", "1.0.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0] != filepath.Join(dir, "sbom.cdx.json") {
+		t.Errorf("unexpected files %v", files)
+	}
+}
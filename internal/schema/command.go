@@ -0,0 +1,75 @@
+package schema
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/runctx"
+	"github.com/coffee377/autoctl/internal/shell"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// command 通过外部命令检查兼容性，如 oasdiff、buf 等工具的包装脚本
+type command struct {
+	script string
+}
+
+func newCommand(c Check) (Checker, error) {
+	if c.Type == TypeCommand && c.Command == "" {
+		return nil, fmt.Errorf("schema check command is required")
+	}
+	return command{script: c.Command}, nil
+}
+
+func (c command) Compare(file string, before, after []byte) ([]Change, error) {
+	dir, err := os.MkdirTemp("", "autoctl-schema-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	env := append(os.Environ(), "AUTOCTL_SCHEMA_FILE="+file)
+	for name, data := range map[string][]byte{"BEFORE": before, "AFTER": after} {
+		// 文件在该版本中不存在时为空文件
+		tmp := filepath.Join(dir, strings.ToLower(name)+filepath.Ext(file))
+		if err = os.WriteFile(tmp, data, 0o644); err != nil {
+			return nil, err
+		}
+		env = append(env, "AUTOCTL_SCHEMA_"+name+"="+tmp)
+	}
+	ctx, cancel := runctx.Command()
+	defer cancel()
+	cmd := shell.Command(ctx, c.script)
+	cmd.Env = env
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	start := time.Now()
+	err = cmd.Run()
+	log.TraceCommand(cmd, start, err)
+	var exit *exec.ExitError
+	if err != nil && (!errors.As(err, &exit) || ctx.Err() != nil) {
+		return nil, runctx.Wrap(ctx, err)
+	}
+	if err == nil {
+		return nil, nil
+	}
+	var changes []Change
+	for _, line := range strings.Split(stdout.String(), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			changes = append(changes, Change{Path: line, Kind: KindIncompatible, Breaking: true})
+		}
+	}
+	if len(changes) == 0 {
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = err.Error()
+		}
+		changes = append(changes, Change{Path: msg, Kind: KindIncompatible, Breaking: true})
+	}
+	return changes, nil
+}
@@ -0,0 +1,196 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var httpMethods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// openAPI 比较接口、参数及模型：删除接口、参数、模型或属性，类型变化，新增必填参数或属性为不兼容变更
+type openAPI struct{}
+
+type object = map[string]interface{}
+
+func (openAPI) Compare(_ string, before, after []byte) ([]Change, error) {
+	b, err := parseOpenAPI(before)
+	if err != nil {
+		return nil, err
+	}
+	a, err := parseOpenAPI(after)
+	if err != nil {
+		return nil, err
+	}
+	changes := compareOperations(mapOf(b["paths"]), mapOf(a["paths"]))
+	changes = append(changes, compareModels(models(b), models(a))...)
+	return changes, nil
+}
+
+func parseOpenAPI(data []byte) (object, error) {
+	doc := object{}
+	if data == nil {
+		return doc, nil
+	}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// models OpenAPI 3 的 components.schemas 或 Swagger 2 的 definitions
+func models(doc object) object {
+	if schemas := mapOf(mapOf(doc["components"])["schemas"]); len(schemas) > 0 {
+		return schemas
+	}
+	return mapOf(doc["definitions"])
+}
+
+func compareOperations(before, after object) []Change {
+	var changes []Change
+	for _, p := range sortedKeys(before, after) {
+		for _, method := range httpMethods {
+			b, inBefore := mapOf(before[p])[method]
+			a, inAfter := mapOf(after[p])[method]
+			op := strings.ToUpper(method) + " " + p
+			switch {
+			case inBefore && !inAfter:
+				changes = append(changes, removed(op, "operation"))
+			case !inBefore && inAfter:
+				changes = append(changes, added(op, "operation"))
+			case inBefore && inAfter:
+				changes = append(changes, compareParameters(op, parameters(before[p], b), parameters(after[p], a))...)
+			}
+		}
+	}
+	return changes
+}
+
+// parameters 路径及操作的参数，键为 in:name
+func parameters(item, op interface{}) map[string]object {
+	params := map[string]object{}
+	for _, list := range []interface{}{mapOf(item)["parameters"], mapOf(op)["parameters"]} {
+		items, _ := list.([]interface{})
+		for _, p := range items {
+			param := mapOf(p)
+			params[fmt.Sprint(param["in"], ":", param["name"])] = param
+		}
+	}
+	return params
+}
+
+func compareParameters(op string, before, after map[string]object) []Change {
+	var changes []Change
+	for _, key := range sortedKeys(before, after) {
+		b, inBefore := before[key]
+		a, inAfter := after[key]
+		path := op + " " + key
+		switch {
+		case !inAfter:
+			changes = append(changes, removed(path, "parameter"))
+		case !inBefore && isTrue(a["required"]):
+			changes = append(changes, Change{Path: path, Kind: KindRequired, Breaking: true, Detail: "new required parameter"})
+		case !inBefore:
+			changes = append(changes, added(path, "parameter"))
+		case !isTrue(b["required"]) && isTrue(a["required"]):
+			changes = append(changes, changed(KindRequired, path, "optional", "required"))
+		default:
+			// Swagger 2 的参数类型直接定义在参数上
+			bt, at := typeOf(b), typeOf(a)
+			if s := mapOf(b["schema"]); len(s) > 0 {
+				bt = typeOf(s)
+			}
+			if s := mapOf(a["schema"]); len(s) > 0 {
+				at = typeOf(s)
+			}
+			if bt != at {
+				changes = append(changes, changed(KindType, path, bt, at))
+			}
+		}
+	}
+	return changes
+}
+
+func compareModels(before, after object) []Change {
+	var changes []Change
+	for _, name := range sortedKeys(before, after) {
+		b, inBefore := before[name]
+		a, inAfter := after[name]
+		switch {
+		case !inAfter:
+			changes = append(changes, removed(name, "schema"))
+		case !inBefore:
+			changes = append(changes, added(name, "schema"))
+		default:
+			changes = append(changes, compareSchema(name, mapOf(b), mapOf(a))...)
+		}
+	}
+	return changes
+}
+
+// compareSchema 递归比较属性及数组元素
+func compareSchema(path string, before, after object) []Change {
+	if bt, at := typeOf(before), typeOf(after); bt != at {
+		return []Change{changed(KindType, path, bt, at)}
+	}
+	var changes []Change
+	if items := mapOf(before["items"]); len(items) > 0 {
+		changes = append(changes, compareSchema(path+"[]", items, mapOf(after["items"]))...)
+	}
+	bp, ap := mapOf(before["properties"]), mapOf(after["properties"])
+	br, ar := required(before), required(after)
+	for _, name := range sortedKeys(bp, ap) {
+		b, inBefore := bp[name]
+		a, inAfter := ap[name]
+		prop := path + "." + name
+		switch {
+		case !inAfter:
+			changes = append(changes, removed(prop, "property"))
+		case !inBefore && ar[name]:
+			changes = append(changes, Change{Path: prop, Kind: KindRequired, Breaking: true, Detail: "new required property"})
+		case !inBefore:
+			changes = append(changes, added(prop, "property"))
+		default:
+			if !br[name] && ar[name] {
+				changes = append(changes, changed(KindRequired, prop, "optional", "required"))
+			}
+			changes = append(changes, compareSchema(prop, mapOf(b), mapOf(a))...)
+		}
+	}
+	return changes
+}
+
+// typeOf 模型的类型，引用时为引用的模型，如 integer(int64)、array、#/components/schemas/User
+func typeOf(s object) string {
+	if ref, ok := s["$ref"].(string); ok {
+		return ref
+	}
+	t := fmt.Sprint(s["type"])
+	if s["type"] == nil {
+		t = ""
+	}
+	if format, ok := s["format"].(string); ok {
+		t += "(" + format + ")"
+	}
+	return t
+}
+
+func required(s object) map[string]bool {
+	names := map[string]bool{}
+	list, _ := s["required"].([]interface{})
+	for _, n := range list {
+		names[fmt.Sprint(n)] = true
+	}
+	return names
+}
+
+func mapOf(v interface{}) object {
+	m, _ := v.(object)
+	return m
+}
+
+func isTrue(v interface{}) bool {
+	b, _ := v.(bool)
+	return b
+}
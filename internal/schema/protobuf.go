@@ -0,0 +1,259 @@
+package schema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// protobuf 按字段编号比较消息、按数值比较枚举值，并比较服务方法的请求及响应：
+// 删除消息、字段、枚举值或方法，字段类型变化，字段或枚举值改名，方法签名变化为不兼容变更
+type protobuf struct{}
+
+// protoField 字段或枚举值
+type protoField struct {
+	Name string
+	Type string // 枚举值为空
+}
+
+// protoFile 解析后的定义，消息及枚举以包内的全名为键，如 User.Address
+type protoFile struct {
+	messages map[string]map[string]protoField // 消息及枚举，字段以编号为键
+	enums    map[string]bool
+	rpcs     map[string]string // 方法以 Service.Method 为键，值为签名
+}
+
+func (protobuf) Compare(_ string, before, after []byte) ([]Change, error) {
+	b, err := parseProto(string(before))
+	if err != nil {
+		return nil, err
+	}
+	a, err := parseProto(string(after))
+	if err != nil {
+		return nil, err
+	}
+	var changes []Change
+	for _, name := range sortedKeys(b.messages, a.messages) {
+		kind := "message"
+		if b.enums[name] || a.enums[name] {
+			kind = "enum"
+		}
+		bf, inBefore := b.messages[name]
+		af, inAfter := a.messages[name]
+		switch {
+		case !inAfter:
+			changes = append(changes, removed(name, kind))
+		case !inBefore:
+			changes = append(changes, added(name, kind))
+		default:
+			changes = append(changes, compareFields(name, bf, af)...)
+		}
+	}
+	for _, name := range sortedKeys(b.rpcs, a.rpcs) {
+		bs, inBefore := b.rpcs[name]
+		as, inAfter := a.rpcs[name]
+		switch {
+		case !inAfter:
+			changes = append(changes, removed(name, "rpc"))
+		case !inBefore:
+			changes = append(changes, added(name, "rpc"))
+		case bs != as:
+			changes = append(changes, changed(KindType, name, bs, as))
+		}
+	}
+	return changes, nil
+}
+
+func compareFields(message string, before, after map[string]protoField) []Change {
+	var changes []Change
+	for _, number := range sortedKeys(before, after) {
+		b, inBefore := before[number]
+		a, inAfter := after[number]
+		path := fmt.Sprintf("%s = %s", message, number)
+		switch {
+		case !inAfter:
+			changes = append(changes, removed(message+"."+b.Name, "field "+number))
+		case !inBefore:
+			changes = append(changes, added(message+"."+a.Name, "field "+number))
+		case b.Type != a.Type:
+			changes = append(changes, changed(KindType, message+"."+a.Name, b.Type, a.Type))
+		case b.Name != a.Name:
+			changes = append(changes, changed(KindRenamed, path, b.Name, a.Name))
+		}
+	}
+	return changes
+}
+
+// parseProto 解析消息、枚举及服务，忽略选项、保留编号及扩展
+func parseProto(src string) (*protoFile, error) {
+	f := &protoFile{messages: map[string]map[string]protoField{}, enums: map[string]bool{}, rpcs: map[string]string{}}
+	tokens := tokenize(src)
+	type scope struct{ kind, name string }
+	var stack []scope
+	// qualified 嵌套的消息及枚举使用 Outer.Inner 作为名称，oneof 中的字段属于外层消息
+	qualified := func(name string) string {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].kind == "message" {
+				return stack[i].name + "." + name
+			}
+		}
+		return name
+	}
+	current := func() scope {
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i].kind != "oneof" {
+				return stack[i]
+			}
+		}
+		return scope{}
+	}
+	for i := 0; i < len(tokens); i++ {
+		tok := tokens[i]
+		switch {
+		case tok == "}":
+			if len(stack) == 0 {
+				return nil, fmt.Errorf("unexpected }")
+			}
+			stack = stack[:len(stack)-1]
+		case (tok == "message" || tok == "enum" || tok == "service" || tok == "oneof") && i+2 < len(tokens) && tokens[i+2] == "{":
+			name := tokens[i+1]
+			if tok == "message" || tok == "enum" {
+				name = qualified(name)
+				f.messages[name] = map[string]protoField{}
+				f.enums[name] = tok == "enum"
+			}
+			stack = append(stack, scope{tok, name})
+			i += 2
+		case tok == "rpc" && current().kind == "service":
+			end := statementEnd(tokens, i)
+			stmt := tokens[i:end]
+			if len(stmt) > 1 {
+				f.rpcs[current().name+"."+stmt[1]] = strings.Join(stmt[2:], " ")
+			}
+			i = end
+			if i < len(tokens) && tokens[i] == "{" {
+				i = skipBlock(tokens, i)
+			}
+		case tok == ";":
+		default:
+			end := statementEnd(tokens, i)
+			stmt := tokens[i:end]
+			if end < len(tokens) && tokens[end] == "{" {
+				// extend、option 等带有代码块的语句
+				end = skipBlock(tokens, end)
+			}
+			i = end
+			s := current()
+			if s.kind != "message" && s.kind != "enum" || len(stmt) < 3 || stmt[0] == "option" || stmt[0] == "reserved" || stmt[0] == "extensions" {
+				continue
+			}
+			eq := indexOf(stmt, "=")
+			if eq < 1 || eq+1 >= len(stmt) {
+				continue
+			}
+			field := protoField{Name: stmt[eq-1]}
+			if s.kind == "message" {
+				field.Type = strings.Join(stmt[:eq-1], " ")
+			}
+			f.messages[s.name][stmt[eq+1]] = field
+		}
+	}
+	if len(stack) > 0 {
+		return nil, fmt.Errorf("missing } of %s %s", stack[len(stack)-1].kind, stack[len(stack)-1].name)
+	}
+	return f, nil
+}
+
+// tokenize 去除注释，按空白及符号切分，字符串作为一个记号
+func tokenize(src string) []string {
+	var tokens []string
+	var buf strings.Builder
+	flush := func() {
+		if buf.Len() > 0 {
+			tokens = append(tokens, buf.String())
+			buf.Reset()
+		}
+	}
+	for i := 0; i < len(src); i++ {
+		c := src[i]
+		switch {
+		case strings.HasPrefix(src[i:], "//"):
+			flush()
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(src[i:], "/*"):
+			flush()
+			end := strings.Index(src[i+2:], "*/")
+			if end < 0 {
+				return tokens
+			}
+			i += end + 3
+		case c == '"' || c == '\'':
+			flush()
+			j := i + 1
+			for j < len(src) && src[j] != c {
+				if src[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(src) {
+				j = len(src) - 1
+			}
+			tokens = append(tokens, src[i:j+1])
+			i = j
+		case strings.ContainsRune("{}()[]<>;=,", rune(c)):
+			flush()
+			tokens = append(tokens, string(c))
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			flush()
+		default:
+			buf.WriteByte(c)
+		}
+	}
+	flush()
+	return tokens
+}
+
+// statementEnd 语句结束的 ; 或代码块开始的 { 的位置
+func statementEnd(tokens []string, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "[", "(", "<":
+			depth++
+		case "]", ")", ">":
+			depth--
+		case ";", "{":
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return i
+}
+
+// skipBlock 跳过 i 处 { 开始的代码块，返回匹配的 } 的位置
+func skipBlock(tokens []string, i int) int {
+	depth := 0
+	for ; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "{":
+			depth++
+		case "}":
+			if depth--; depth == 0 {
+				return i
+			}
+		}
+	}
+	return i
+}
+
+func indexOf(tokens []string, s string) int {
+	for i, t := range tokens {
+		if t == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,165 @@
+// Package schema 比较 OpenAPI 规范、protobuf 等接口定义在两个版本之间的兼容性
+package schema
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+// 内置的检查类型
+const (
+	TypeOpenAPI  = "openapi"  // OpenAPI 3 或 Swagger 2 规范，YAML 或 JSON
+	TypeProtobuf = "protobuf" // protobuf 定义文件
+	TypeCommand  = "command"  // 外部检查命令
+)
+
+// 变更的种类
+const (
+	KindRemoved  = "removed"  // 删除了接口、模型、字段或枚举值
+	KindType     = "type"     // 字段或参数的类型发生变化
+	KindRequired = "required" // 新增必填字段或参数，或可选变为必填
+	KindRenamed  = "renamed"  // protobuf 字段或枚举值改名
+	KindAdded    = "added"    // 新增接口、模型、字段或可选参数
+
+	KindIncompatible = "incompatible" // 外部检查命令报告的不兼容变更
+)
+
+// Checker 比较一个文件在两个版本中的内容，before 或 after 为 nil 表示文件在该版本中不存在
+type Checker interface {
+	Compare(file string, before, after []byte) ([]Change, error)
+}
+
+// Factory 根据检查配置创建 Checker
+type Factory func(c Check) (Checker, error)
+
+var factories = map[string]Factory{
+	TypeOpenAPI:  func(Check) (Checker, error) { return openAPI{}, nil },
+	TypeProtobuf: func(Check) (Checker, error) { return protobuf{}, nil },
+	TypeCommand:  newCommand,
+}
+
+// Register 注册检查类型，同名时覆盖内置类型
+func Register(name string, factory Factory) {
+	factories[name] = factory
+}
+
+// Check 一项接口定义兼容性检查，对应配置文件 release.apiCheck.schemas 中的一项
+type Check struct {
+	Type    string   `mapstructure:"type"`    // 检查类型 openapi | protobuf | command，或通过 Register 注册的类型
+	Files   []string `mapstructure:"files"`   // 比较的文件，支持 git pathspec 通配符，如 api/openapi.yaml、:(glob)proto/**/*.proto
+	Command string   `mapstructure:"command"` // type 为 command 时的检查命令，环境变量 AUTOCTL_SCHEMA_FILE、AUTOCTL_SCHEMA_BEFORE、AUTOCTL_SCHEMA_AFTER 为文件及两个版本内容的临时文件，退出码非 0 时输出的每一行为一项不兼容变更
+}
+
+// Validate 检查类型及文件
+func (c Check) Validate() error {
+	factory, ok := factories[c.Type]
+	if !ok {
+		return fmt.Errorf("unknown schema check type %q", c.Type)
+	}
+	if len(c.Files) == 0 {
+		return fmt.Errorf("schema check %s requires files", c.Type)
+	}
+	_, err := factory(c)
+	return err
+}
+
+// Change 一项接口定义变更
+type Change struct {
+	Check    string `json:"check"`            // 检查类型
+	File     string `json:"file"`             // 接口定义文件
+	Path     string `json:"path"`             // 变更位置，如 GET /users、User.email
+	Kind     string `json:"kind"`             // 种类 removed | type | required | renamed | added | incompatible
+	Breaking bool   `json:"breaking"`         // 是否为不兼容变更
+	Detail   string `json:"detail,omitempty"` // 变化前后的类型或说明
+}
+
+func (c Change) String() string {
+	s := fmt.Sprintf("%s %s:%s", c.Kind, c.File, c.Path)
+	if c.Detail != "" {
+		s += " (" + c.Detail + ")"
+	}
+	return s
+}
+
+// Report 两个版本之间的接口定义变更
+type Report struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Changes []Change `json:"changes,omitempty"`
+}
+
+// Breaking 不兼容的变更
+func (r *Report) Breaking() []Change {
+	var changes []Change
+	for _, c := range r.Changes {
+		if c.Breaking {
+			changes = append(changes, c)
+		}
+	}
+	return changes
+}
+
+// Compare 比较 from 与 to 两个引用之间变更的接口定义文件
+func Compare(plus *git.Plus, checks []Check, from, to string) (*Report, error) {
+	report := &Report{From: from, To: to}
+	for _, c := range checks {
+		checker, err := factories[c.Type](c)
+		if err != nil {
+			return nil, err
+		}
+		files, err := plus.ChangedFiles(from, to, c.Files...)
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+		for _, file := range files {
+			before, err := plus.Show(from, file)
+			if err != nil {
+				return nil, err
+			}
+			after, err := plus.Show(to, file)
+			if err != nil {
+				return nil, err
+			}
+			changes, err := checker.Compare(file, before, after)
+			if err != nil {
+				return nil, fmt.Errorf("%s schema %s: %w", c.Type, file, err)
+			}
+			for _, change := range changes {
+				change.Check, change.File = c.Type, file
+				report.Changes = append(report.Changes, change)
+			}
+		}
+	}
+	return report, nil
+}
+
+// removed 删除为不兼容变更
+func removed(path, detail string) Change {
+	return Change{Path: path, Kind: KindRemoved, Breaking: true, Detail: detail}
+}
+
+func added(path, detail string) Change {
+	return Change{Path: path, Kind: KindAdded, Detail: detail}
+}
+
+func changed(kind, path, before, after string) Change {
+	return Change{Path: path, Kind: kind, Breaking: true, Detail: before + " => " + after}
+}
+
+func sortedKeys[V any](maps ...map[string]V) []string {
+	seen := map[string]bool{}
+	keys := make([]string, 0)
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
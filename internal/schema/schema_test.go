@@ -0,0 +1,194 @@
+package schema
+
+import (
+	"testing"
+)
+
+const openAPIv1 = `openapi: 3.0.0
+paths:
+  /users:
+    get:
+      parameters:
+        - {name: page, in: query, schema: {type: integer}}
+        - {name: size, in: query, schema: {type: integer}}
+    delete: {}
+components:
+  schemas:
+    User:
+      type: object
+      required: [id]
+      properties:
+        id: {type: integer, format: int64}
+        name: {type: string}
+        tags: {type: array, items: {type: string}}
+    Legacy: {type: object}
+`
+
+const openAPIv2 = `{
+  "openapi": "3.0.0",
+  "paths": {
+    "/users": {
+      "get": {"parameters": [
+        {"name": "page", "in": "query", "schema": {"type": "string"}},
+        {"name": "tenant", "in": "header", "required": true, "schema": {"type": "string"}},
+        {"name": "q", "in": "query", "schema": {"type": "string"}}
+      ]},
+      "post": {}
+    }
+  },
+  "components": {"schemas": {
+    "User": {"type": "object", "required": ["id", "email"], "properties": {
+      "id": {"type": "integer", "format": "int64"},
+      "email": {"type": "string"},
+      "nickname": {"type": "string"},
+      "tags": {"type": "array", "items": {"type": "integer"}}
+    }}
+  }}
+}`
+
+func index(changes []Change) map[string]Change {
+	m := map[string]Change{}
+	for _, c := range changes {
+		m[c.Path] = c
+	}
+	return m
+}
+
+func TestOpenAPI(t *testing.T) {
+	changes, err := openAPI{}.Compare("openapi.yaml", []byte(openAPIv1), []byte(openAPIv2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := index(changes)
+	want := map[string]struct {
+		kind     string
+		breaking bool
+	}{
+		"DELETE /users":            {KindRemoved, true},
+		"POST /users":              {KindAdded, false},
+		"GET /users query:page":    {KindType, true},
+		"GET /users query:size":    {KindRemoved, true},
+		"GET /users header:tenant": {KindRequired, true},
+		"GET /users query:q":       {KindAdded, false},
+		"Legacy":                   {KindRemoved, true},
+		"User.name":                {KindRemoved, true},
+		"User.email":               {KindRequired, true},
+		"User.nickname":            {KindAdded, false},
+		"User.tags[]":              {KindType, true},
+	}
+	for path, w := range want {
+		if c, ok := got[path]; !ok || c.Kind != w.kind || c.Breaking != w.breaking {
+			t.Errorf("expected %s %s breaking=%v, but %+v got", path, w.kind, w.breaking, c)
+		}
+	}
+	if len(changes) != len(want) {
+		t.Errorf("expected %d changes, but %+v got", len(want), changes)
+	}
+}
+
+const protoV1 = `syntax = "proto3";
+package user.v1;
+
+// 用户
+message User {
+  int64 id = 1;
+  string name = 2;
+  repeated string tags = 3 [deprecated = true];
+  message Address { string city = 1; }
+  oneof contact {
+    string email = 4;
+    string phone = 5;
+  }
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+  STATUS_DELETED = 2;
+}
+
+service UserService {
+  rpc Get(GetRequest) returns (User);
+  rpc Delete(GetRequest) returns (User) {
+    option (google.api.http) = { delete: "/v1/users/{id}" };
+  }
+}
+`
+
+const protoV2 = `syntax = "proto3";
+package user.v1;
+
+/* 用户 */
+message User {
+  string id = 1;
+  string full_name = 2;
+  repeated string tags = 3;
+  message Address { string city = 1; string street = 2; }
+  oneof contact {
+    string email = 4;
+  }
+  map<string, string> labels = 6;
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_ACTIVE = 1;
+}
+
+service UserService {
+  rpc Get(GetRequest) returns (stream User);
+  rpc List(ListRequest) returns (ListResponse);
+}
+`
+
+func TestProtobuf(t *testing.T) {
+	changes, err := protobuf{}.Compare("user.proto", []byte(protoV1), []byte(protoV2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := index(changes)
+	want := map[string]struct {
+		kind     string
+		breaking bool
+	}{
+		"User.id":               {KindType, true},
+		"User = 2":              {KindRenamed, true},
+		"User.phone":            {KindRemoved, true},
+		"User.labels":           {KindAdded, false},
+		"User.Address.street":   {KindAdded, false},
+		"Status.STATUS_DELETED": {KindRemoved, true},
+		"UserService.Get":       {KindType, true},
+		"UserService.Delete":    {KindRemoved, true},
+		"UserService.List":      {KindAdded, false},
+	}
+	for path, w := range want {
+		if c, ok := got[path]; !ok || c.Kind != w.kind || c.Breaking != w.breaking {
+			t.Errorf("expected %s %s breaking=%v, but %+v got", path, w.kind, w.breaking, c)
+		}
+	}
+	if len(changes) != len(want) {
+		t.Errorf("expected %d changes, but %+v got", len(want), changes)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	checker, err := newCommand(Check{Type: TypeCommand, Command: `cmp -s "$AUTOCTL_SCHEMA_BEFORE" "$AUTOCTL_SCHEMA_AFTER" || { echo "$AUTOCTL_SCHEMA_FILE changed"; exit 1; }`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	changes, err := checker.Compare("api.graphql", []byte("type Query"), []byte("type Query"))
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("expected no changes, but %+v %v got", changes, err)
+	}
+	changes, err = checker.Compare("api.graphql", []byte("type Query"), nil)
+	if err != nil || len(changes) != 1 || changes[0].Path != "api.graphql changed" || !changes[0].Breaking {
+		t.Fatalf("expected breaking change reported by the command, but %+v %v got", changes, err)
+	}
+	if err = (Check{Type: "graphql", Files: []string{"api.graphql"}}).Validate(); err == nil {
+		t.Error("expected unknown type invalid")
+	}
+	Register("graphql", func(Check) (Checker, error) { return checker, nil })
+	if err = (Check{Type: "graphql", Files: []string{"api.graphql"}}).Validate(); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,306 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/coffee377/autoctl/internal/audit"
+	"github.com/coffee377/autoctl/internal/chatops"
+	"github.com/coffee377/autoctl/internal/metrics"
+	"github.com/coffee377/autoctl/internal/provider"
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+const (
+	DefaultAddr = "127.0.0.1:8080"
+	EnvToken    = "AUTOCTL_SERVE_TOKEN" // 发布接口的访问令牌，优先于配置文件
+)
+
+// Config 接口服务配置，对应配置文件 serve 节点
+type Config struct {
+	Addr  string `mapstructure:"addr"`  // 监听地址，默认 127.0.0.1:8080
+	Token string `mapstructure:"token"` // 发布接口的访问令牌，未设置时禁用发布接口
+}
+
+// Server 以 REST 接口提供版本计算、校验、变更日志及发布
+type Server struct {
+	opts  release.Options
+	token string
+	mu    sync.Mutex   // 同一时间只执行一次发布
+	bot   *chatops.Bot // 评论命令触发发布，未启用时为空
+}
+
+// New 创建接口服务，opts 为配置文件中的发布选项，各接口可按请求参数覆盖版本变动类型等
+func New(opts release.Options, cfg Config) *Server {
+	token := cfg.Token
+	if v := os.Getenv(EnvToken); v != "" {
+		token = v
+	}
+	log.AddSecret(token)
+	return &Server{opts: opts, token: token}
+}
+
+// Handler 接口路由
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.Handle("/metrics", metrics.Handler())
+	mux.HandleFunc("/api/v1/version", s.method(http.MethodGet, s.version))
+	mux.HandleFunc("/api/v1/next", s.method(http.MethodGet, s.next))
+	mux.HandleFunc("/api/v1/validate", s.method(http.MethodGet, s.validate))
+	mux.HandleFunc("/api/v1/changelog", s.method(http.MethodGet, s.authorize(s.changelog)))
+	mux.HandleFunc("/api/v1/release", s.method(http.MethodPost, s.authorize(s.release)))
+	if s.bot != nil {
+		mux.HandleFunc("/api/v1/chatops", s.bot.Handler())
+	}
+	return mux
+}
+
+// WithChatOps 启用评论命令触发发布，回复发布结果需要平台支持评论
+func (s *Server) WithChatOps(cfg chatops.Config) error {
+	var commenter provider.Commenter
+	if s.opts.Provider.Repo != "" {
+		p, err := provider.New(s.opts.Provider)
+		if err != nil {
+			return err
+		}
+		commenter, _ = p.(provider.Commenter)
+	}
+	log.AddSecret(cfg.Secret)
+	s.bot = chatops.NewBot(cfg, func(cmd *chatops.Command) (*release.Result, error) {
+		return s.Release(ReleaseRequest{Release: cmd.Release, PreId: cmd.PreId, DryRun: cmd.DryRun, actor: cmd.User})
+	}, commenter)
+	return nil
+}
+
+// Release 按请求发布，同一时间只执行一次发布
+func (s *Server) Release(req ReleaseRequest) (*release.Result, error) {
+	opts, err := s.options(req.Release, req.PreId)
+	if err != nil {
+		return nil, err
+	}
+	opts.DryRun = req.DryRun
+	if req.Push != nil {
+		opts.Push = *req.Push
+	}
+	if req.Publish != nil {
+		opts.Publish = *req.Publish
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	releaser := release.New(opts)
+	res, err := releaser.Release()
+	action := "serve"
+	if req.actor != "" {
+		action = "chatops"
+	}
+	flags := map[string]string{"preid": req.PreId, "dry-run": strconv.FormatBool(req.DryRun)}
+	releaser.Audit(&audit.Entry{Action: action, Actor: req.actor, Args: []string{req.Release}, Flags: flags}, res, err)
+	return res, err
+}
+
+// ReleaseRequest 发布请求
+type ReleaseRequest struct {
+	Release string `json:"release,omitempty"` // 版本变动类型，默认 patch
+	PreId   string `json:"preid,omitempty"`   // 预发布版本标识符
+	DryRun  bool   `json:"dryRun,omitempty"`  // 仅计算版本及变更日志
+	Push    *bool  `json:"push,omitempty"`    // 是否推送标签，默认使用配置文件
+	Publish *bool  `json:"publish,omitempty"` // 是否在代码托管平台创建版本发布，默认使用配置文件
+
+	actor string // 评论命令触发时为评论者
+}
+
+// VersionResponse 版本计算结果
+type VersionResponse struct {
+	Current string `json:"current,omitempty"` // 当前版本，首次发布时为空
+	Next    string `json:"next,omitempty"`    // 下一个版本
+	Tag     string `json:"tag,omitempty"`     // 当前或下一个版本的标签
+}
+
+// ValidateResponse 版本号校验结果
+type ValidateResponse struct {
+	Valid     bool   `json:"valid"`
+	Version   string `json:"version,omitempty"`   // 规范化后的版本号
+	Satisfies *bool  `json:"satisfies,omitempty"` // 指定 constraint 时是否满足约束
+	Error     string `json:"error,omitempty"`
+}
+
+func (s *Server) version(w http.ResponseWriter, r *http.Request) {
+	releaser := release.New(s.opts)
+	current, err := releaser.Current()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	res := VersionResponse{}
+	if current != nil {
+		res.Current = current.String()
+		res.Tag = releaser.TagName(current)
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) next(w http.ResponseWriter, r *http.Request) {
+	opts, err := s.options(r.URL.Query().Get("release"), r.URL.Query().Get("preid"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	releaser := release.New(opts)
+	current, err := releaser.Current()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	next, err := releaser.Next(current)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	res := VersionResponse{Next: next.String(), Tag: releaser.TagName(next)}
+	if current != nil {
+		res.Current = current.String()
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+func (s *Server) validate(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	v, err := semver.Version(query.Get("version"))
+	if err != nil {
+		writeJSON(w, http.StatusOK, ValidateResponse{Error: err.Error()})
+		return
+	}
+	res := ValidateResponse{Valid: true, Version: v.String()}
+	if text := query.Get("constraint"); text != "" {
+		c, err := semver.ParseConstraint(text)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		ok := c.Check(v)
+		res.Satisfies = &ok
+	}
+	writeJSON(w, http.StatusOK, res)
+}
+
+// changelog 按待发布提交生成下一个版本的变更日志，format=markdown 时返回 Markdown 文本，
+// 与发布请求互斥执行，避免读取到发布过程中的标签
+func (s *Server) changelog(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	opts, err := s.options(query.Get("release"), query.Get("preid"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	s.mu.Lock()
+	cl, err := release.New(opts).PendingChangelog()
+	s.mu.Unlock()
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	if query.Get("format") == "markdown" {
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		_, _ = w.Write([]byte(cl.Markdown()))
+		return
+	}
+	writeJSON(w, http.StatusOK, cl)
+}
+
+func (s *Server) release(w http.ResponseWriter, r *http.Request) {
+	req := ReleaseRequest{}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if _, err := s.options(req.Release, req.PreId); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	res, err := s.Release(req)
+	if err != nil {
+		writeError(w, statusOf(err), err)
+		return
+	}
+	log.Info("release %s requested by %s", res.Tag, r.RemoteAddr)
+	writeJSON(w, http.StatusOK, res)
+}
+
+// options 按请求参数覆盖版本变动类型及预发布版本标识符
+func (s *Server) options(releaseType, preid string) (release.Options, error) {
+	opts := s.opts
+	if releaseType != "" {
+		changed, err := semver.ParseReleaseType(releaseType)
+		if err != nil {
+			return opts, err
+		}
+		opts.Release = changed
+	}
+	if preid != "" {
+		if err := semver.PreReleaseIdentifier(preid).Validate(); err != nil {
+			return opts, err
+		}
+		opts.PreId = preid
+	}
+	return opts, nil
+}
+
+// method 限制请求方法
+func (s *Server) method(method string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != method && !(method == http.MethodGet && r.Method == http.MethodHead) {
+			w.Header().Set("Allow", method)
+			writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+// authorize 校验 Authorization: Bearer <token>，未配置令牌时拒绝所有请求
+func (s *Server) authorize(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token == "" {
+			writeError(w, http.StatusForbidden, errors.New("release api is disabled, set serve.token or "+EnvToken))
+			return
+		}
+		token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="autoctl"`)
+			writeError(w, http.StatusUnauthorized, errors.New("invalid token"))
+			return
+		}
+		next(w, r)
+	}
+}
+
+// statusOf 没有可发布的提交时返回 409，其它错误返回 500
+func statusOf(err error) int {
+	var noChange *release.NoChangeError
+	if errors.As(err, &noChange) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": log.Redact(err.Error())})
+}
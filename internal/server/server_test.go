@@ -0,0 +1,89 @@
+package server
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/release"
+	"github.com/coffee377/autoctl/internal/testutil"
+)
+
+func newRepo(t *testing.T) string {
+	t.Helper()
+	repo := testutil.NewRepo(t, t.TempDir())
+	testutil.GitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: init")
+	testutil.GitRun(t, repo, "tag", "v1.0.0")
+	testutil.GitRun(t, repo, "commit", "-q", "--allow-empty", "-m", "feat: add api")
+	return repo
+}
+
+func request(t *testing.T, h http.Handler, method, target, token, body string) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	data, _ := io.ReadAll(rec.Body)
+	return rec.Code, string(data)
+}
+
+func TestServer(t *testing.T) {
+	repo := newRepo(t)
+	t.Setenv(EnvToken, "")
+	h := New(release.Options{Cwd: repo}, Config{Token: "secret"}).Handler()
+
+	if code, body := request(t, h, http.MethodGet, "/api/v1/next?release=minor", "", ""); code != http.StatusOK || !strings.Contains(body, `"next":"1.1.0"`) {
+		t.Errorf("expected next 1.1.0, but %d %s got", code, body)
+	}
+	if code, body := request(t, h, http.MethodGet, "/api/v1/next?release=prerelease&preid=beta..1", "", ""); code != http.StatusBadRequest || !strings.Contains(body, "invalid prerelease identifier") {
+		t.Errorf("expected invalid preid rejected, but %d %s got", code, body)
+	}
+	if code, _ := request(t, h, http.MethodPost, "/api/v1/release", "secret", `{"release":"prerelease","preid":"beta..1"}`); code != http.StatusBadRequest {
+		t.Errorf("expected invalid preid rejected, but %d got", code)
+	}
+	if _, body := request(t, h, http.MethodGet, "/api/v1/validate?version=1.2.3&constraint=%5E1.0.0", "", ""); !strings.Contains(body, `"satisfies":true`) {
+		t.Errorf("expected 1.2.3 satisfies ^1.0.0, but %s got", body)
+	}
+	if _, body := request(t, h, http.MethodGet, "/api/v1/validate?version=01.2", "", ""); !strings.Contains(body, `"valid":false`) {
+		t.Errorf("expected invalid version, but %s got", body)
+	}
+	if code, _ := request(t, h, http.MethodGet, "/api/v1/changelog", "", ""); code != http.StatusUnauthorized {
+		t.Errorf("expected changelog requires token, but %d got", code)
+	}
+	if code, body := request(t, h, http.MethodGet, "/api/v1/changelog?format=markdown", "secret", ""); code != http.StatusOK || !strings.Contains(body, "* add api") || !strings.Contains(body, "1.1.0") {
+		t.Errorf("expected changelog with add api, but %d %s got", code, body)
+	}
+	if code, _ := request(t, h, http.MethodGet, "/api/v1/release", "secret", ""); code != http.StatusMethodNotAllowed {
+		t.Errorf("expected method not allowed, but %d got", code)
+	}
+	if code, _ := request(t, h, http.MethodPost, "/api/v1/release", "wrong", `{"release":"minor"}`); code != http.StatusUnauthorized {
+		t.Errorf("expected unauthorized, but %d got", code)
+	}
+	code, body := request(t, h, http.MethodPost, "/api/v1/release", "secret", `{"release":"minor"}`)
+	res := struct {
+		Tag string `json:"tag"`
+	}{}
+	if code != http.StatusOK || json.Unmarshal([]byte(body), &res) != nil || res.Tag != "v1.1.0" {
+		t.Fatalf("expected v1.1.0 released, but %d %s got", code, body)
+	}
+	if out := testutil.GitRun(t, repo, "tag", "-l", "v1.1.0"); strings.TrimSpace(out) != "v1.1.0" {
+		t.Errorf("expected tag v1.1.0 created, but %q got", out)
+	}
+	if _, body = request(t, h, http.MethodGet, "/api/v1/version", "", ""); !strings.Contains(body, `"tag":"v1.1.0"`) {
+		t.Errorf("expected current v1.1.0, but %s got", body)
+	}
+	if _, body = request(t, h, http.MethodGet, "/metrics", "", ""); !strings.Contains(body, `autoctl_release_info{previous="1.0.0",repository="",tag="v1.1.0",version="1.1.0"} 1`) {
+		t.Errorf("expected release metrics of v1.1.0, but %s got", body)
+	}
+
+	disabled := New(release.Options{Cwd: repo}, Config{}).Handler()
+	if code, _ = request(t, disabled, http.MethodPost, "/api/v1/release", "", "{}"); code != http.StatusForbidden {
+		t.Errorf("expected release api disabled, but %d got", code)
+	}
+}
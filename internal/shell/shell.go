@@ -0,0 +1,111 @@
+// Package shell 在当前系统的 shell 中执行配置的脚本，如发布钩子、翻译命令、密码命令：
+// Unix 默认为 sh，Windows 默认为 Windows PowerShell，可通过配置文件 shells 节点切换为 bash、pwsh 或 cmd
+package shell
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+	"unicode/utf16"
+)
+
+// 支持的 shell
+const (
+	Sh         = "sh"
+	Bash       = "bash"
+	PowerShell = "powershell" // Windows PowerShell 5.1
+	Pwsh       = "pwsh"       // PowerShell 7，也可以在 Unix 上使用
+	Cmd        = "cmd"
+)
+
+// Config 执行脚本的 shell，对应配置文件 shells 节点
+type Config struct {
+	Windows string `mapstructure:"windows"` // Windows 上的 shell powershell | pwsh | cmd，默认 powershell
+	Unix    string `mapstructure:"unix"`    // 其它系统上的 shell sh | bash | pwsh，默认 sh
+}
+
+var (
+	mu  sync.RWMutex
+	cfg Config
+)
+
+// Setup 校验并设置执行脚本的 shell
+func Setup(c Config) error {
+	for _, v := range []struct {
+		key, value string
+		valid      []string
+	}{
+		{"shells.windows", c.Windows, []string{PowerShell, Pwsh, Cmd}},
+		{"shells.unix", c.Unix, []string{Sh, Bash, Pwsh}},
+	} {
+		if v.value == "" {
+			continue
+		}
+		found := false
+		for _, name := range v.valid {
+			found = found || strings.EqualFold(v.value, name)
+		}
+		if !found {
+			return fmt.Errorf("invalid %s %q, valid values are %s", v.key, v.value, strings.Join(v.valid, "|"))
+		}
+	}
+	mu.Lock()
+	cfg = c
+	mu.Unlock()
+	return nil
+}
+
+// Windows 是否为 Windows 系统
+func Windows() bool {
+	return runtime.GOOS == "windows"
+}
+
+// Name 当前系统执行脚本使用的 shell
+func Name() string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if Windows() {
+		if cfg.Windows != "" {
+			return strings.ToLower(cfg.Windows)
+		}
+		return PowerShell
+	}
+	if cfg.Unix != "" {
+		return strings.ToLower(cfg.Unix)
+	}
+	return Sh
+}
+
+// Command 创建在当前系统的 shell 中执行脚本的命令，脚本中可以通过 $NAME（sh、bash）、$env:NAME（PowerShell）或 %NAME%（cmd）引用环境变量
+func Command(ctx context.Context, script string) *exec.Cmd {
+	return command(ctx, Name(), script)
+}
+
+func command(ctx context.Context, name, script string) *exec.Cmd {
+	switch name {
+	case PowerShell, Pwsh:
+		// 以 UTF-16LE 的 Base64 编码传递脚本，路径中的空格、引号及 $ 无需转义
+		return exec.CommandContext(ctx, name, "-NoLogo", "-NoProfile", "-NonInteractive", "-EncodedCommand", Encode(script))
+	case Cmd:
+		c := exec.CommandContext(ctx, "cmd.exe")
+		rawArgs(c, `cmd.exe /d /s /c "`+script+`"`)
+		return c
+	default:
+		return exec.CommandContext(ctx, name, "-c", script)
+	}
+}
+
+// Encode PowerShell -EncodedCommand 的参数：出错时停止执行，最后一个外部命令的退出码作为进程退出码
+func Encode(script string) string {
+	script = "$ErrorActionPreference = 'Stop'\n" + script + "\nif ($LASTEXITCODE) { exit $LASTEXITCODE }\n"
+	units := utf16.Encode([]rune(script))
+	data := make([]byte, 0, len(units)*2)
+	for _, u := range units {
+		data = append(data, byte(u), byte(u>>8))
+	}
+	return base64.StdEncoding.EncodeToString(data)
+}
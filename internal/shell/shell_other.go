@@ -0,0 +1,13 @@
+//go:build !windows
+
+package shell
+
+import (
+	"os/exec"
+	"strings"
+)
+
+// rawArgs 其它系统上不存在 cmd.exe，按空白拆分命令行，执行时报告命令不存在
+func rawArgs(c *exec.Cmd, line string) {
+	c.Args = strings.Fields(line)
+}
@@ -0,0 +1,81 @@
+package shell
+
+import (
+	"context"
+	"encoding/base64"
+	"os"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+func decode(t *testing.T, s string) string {
+	t.Helper()
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	units := make([]uint16, 0, len(data)/2)
+	for i := 0; i+1 < len(data); i += 2 {
+		units = append(units, uint16(data[i])|uint16(data[i+1])<<8)
+	}
+	return string(utf16.Decode(units))
+}
+
+func TestEncode(t *testing.T) {
+	script := `& "C:\Program Files\tool.exe" --name "$env:AUTOCTL_TAG" 发布`
+	got := decode(t, Encode(script))
+	if !strings.Contains(got, "\n"+script+"\n") {
+		t.Errorf("expected script preserved, but got %q", got)
+	}
+	if !strings.HasPrefix(got, "$ErrorActionPreference = 'Stop'") || !strings.Contains(got, "exit $LASTEXITCODE") {
+		t.Errorf("expected stop on error and exit code propagated, but got %q", got)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	ctx := context.Background()
+	if got := command(ctx, Bash, "echo $HOME").Args; strings.Join(got, " ") != "bash -c echo $HOME" {
+		t.Errorf("bash args = %q", got)
+	}
+	args := command(ctx, Pwsh, "Write-Output hi").Args
+	if len(args) != 6 || args[4] != "-EncodedCommand" || !strings.Contains(decode(t, args[5]), "Write-Output hi") {
+		t.Errorf("pwsh args = %q", args)
+	}
+	if args = command(ctx, Cmd, "echo hi").Args; args[0] != "cmd.exe" {
+		t.Errorf("cmd args = %q", args)
+	}
+}
+
+func TestSetup(t *testing.T) {
+	defer func() { _ = Setup(Config{}) }()
+	if err := Setup(Config{Unix: "fish"}); err == nil {
+		t.Error("expected invalid shell.unix error, got nil")
+	}
+	if err := Setup(Config{Windows: "Pwsh", Unix: "bash"}); err != nil {
+		t.Fatal(err)
+	}
+	want := Bash
+	if Windows() {
+		want = Pwsh
+	}
+	if got := Name(); got != want {
+		t.Errorf("Name() = %s, want %s", got, want)
+	}
+}
+
+func TestCommand_Run(t *testing.T) {
+	script := `echo "$AUTOCTL_TAG" && exit 3`
+	if Windows() {
+		script = "Write-Output $env:AUTOCTL_TAG\ncmd /c exit 3"
+	}
+	cmd := Command(context.Background(), script)
+	cmd.Env = append(os.Environ(), "AUTOCTL_TAG=v1.0.0 beta")
+	output, err := cmd.Output()
+	if strings.TrimSpace(string(output)) != "v1.0.0 beta" {
+		t.Errorf("expected environment variable in output, but got %q", output)
+	}
+	if cmd.ProcessState == nil || cmd.ProcessState.ExitCode() != 3 {
+		t.Errorf("expected exit code 3, got %v", err)
+	}
+}
@@ -0,0 +1,11 @@
+package shell
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// rawArgs 原样传递包含程序名的完整命令行，cmd.exe 不按 CommandLineToArgvW 的规则解析转义的引号
+func rawArgs(c *exec.Cmd, line string) {
+	c.SysProcAttr = &syscall.SysProcAttr{CmdLine: line}
+}
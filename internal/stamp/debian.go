@@ -0,0 +1,101 @@
+package stamp
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// debianHeader debian/changelog 条目首行，如 autoctl (1.2.0-1) unstable; urgency=medium
+var debianHeader = regexp.MustCompile(`^([a-z0-9][a-z0-9.+-]+) \(([^)]+)\)`)
+
+// prependDebian 在 debian/changelog 开头添加版本条目，首个条目已是该版本时不变更
+func (t Target) prependDebian(content []byte, data Data) ([]byte, bool, error) {
+	if len(content) == 0 {
+		entry, err := t.debianEntry("", data)
+		return []byte(entry), err == nil, err
+	}
+	first := strings.SplitN(string(content), "\n", 2)[0]
+	existing := ""
+	if m := debianHeader.FindStringSubmatch(first); m != nil {
+		existing = m[1]
+		if m[2] == DebianVersion(data.Version, t.revision()) {
+			return content, false, nil
+		}
+	}
+	entry, err := t.debianEntry(existing, data)
+	if err != nil {
+		return nil, false, err
+	}
+	return append([]byte(entry+"\n"), content...), true, nil
+}
+
+// debianEntry 生成与 dch 格式一致的条目
+// https://www.debian.org/doc/debian-policy/ch-source.html#debian-changelog-debian-changelog
+func (t Target) debianEntry(existing string, data Data) (string, error) {
+	pkg := t.Package
+	if pkg == "" {
+		pkg = existing
+	}
+	if pkg == "" {
+		return "", fmt.Errorf("debian package name is required for a new changelog")
+	}
+	dist := t.Distribution
+	if dist == "" {
+		dist = "unstable"
+		if len(data.Version.PreRelease()) > 0 {
+			dist = "UNRELEASED"
+		}
+	}
+	urgency := t.Urgency
+	if urgency == "" {
+		urgency = "medium"
+	}
+	maintainer := t.Maintainer
+	if maintainer == "" && os.Getenv("DEBEMAIL") != "" {
+		maintainer = fmt.Sprintf("%s <%s>", os.Getenv("DEBFULLNAME"), os.Getenv("DEBEMAIL"))
+	}
+	if maintainer == "" {
+		maintainer = data.Maintainer
+	}
+	if maintainer == "" {
+		return "", fmt.Errorf("debian maintainer is required, set maintainer or DEBFULLNAME and DEBEMAIL")
+	}
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("%s (%s) %s; urgency=%s\n\n", pkg, DebianVersion(data.Version, t.revision()), dist, urgency))
+	items := debianItems(data)
+	if len(items) == 0 {
+		items = []string{fmt.Sprintf("New upstream release %s.", data.Version)}
+	}
+	for _, item := range items {
+		sb.WriteString("  * " + item + "\n")
+	}
+	// 日期为 RFC 2822 格式
+	sb.WriteString(fmt.Sprintf("\n -- %s  %s\n", maintainer, data.Date.Format("Mon, 02 Jan 2006 15:04:05 -0700")))
+	return sb.String(), nil
+}
+
+func debianItems(data Data) []string {
+	if data.Changelog == nil {
+		return nil
+	}
+	items := make([]string, 0)
+	for _, c := range data.Changelog.Breaking {
+		note := c.BreakingNote
+		if note == "" {
+			note = c.Subject
+		}
+		items = append(items, "BREAKING: "+note)
+	}
+	for _, s := range data.Changelog.Sections {
+		for _, c := range s.Commits {
+			if c.Scope != "" {
+				items = append(items, fmt.Sprintf("%s(%s): %s", s.Type, c.Scope, c.Subject))
+			} else {
+				items = append(items, fmt.Sprintf("%s: %s", s.Type, c.Subject))
+			}
+		}
+	}
+	return items
+}
@@ -0,0 +1,227 @@
+package stamp
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+const (
+	Android = "android"
+	IOS     = "ios"
+
+	// DefaultVersionCode 默认的 versionCode 公式，正式版本的 pre 为 99，保证排在同一版本的预发布版本之后
+	DefaultVersionCode = "major * 1000000 + minor * 10000 + patch * 100 + pre"
+	// maxVersionCode Google Play 允许的最大 versionCode
+	maxVersionCode = 2100000000
+)
+
+// VersionCode 按公式计算单调递增的版本号，公式支持 + - * / 及括号，变量：
+// major、minor、patch；pre 为预发布版本的最后一个数字标识符，正式版本为 99；build 为 Target.Build
+func VersionCode(formula string, v semver.Semver, build uint64) (int64, error) {
+	if formula == "" {
+		formula = DefaultVersionCode
+	}
+	pre := int64(99)
+	if ids := v.PreRelease(); len(ids) > 0 {
+		pre = 0
+		for i := len(ids) - 1; i >= 0; i-- {
+			if ids[i].IsNumeric {
+				pre = int64(ids[i].Num)
+				break
+			}
+		}
+		if pre >= 99 {
+			return 0, fmt.Errorf("prerelease number of %s must be less than 99", v)
+		}
+	}
+	vars := map[string]int64{
+		"major": int64(v.Major()),
+		"minor": int64(v.Minor()),
+		"patch": int64(v.Patch()),
+		"pre":   pre,
+		"build": int64(build),
+	}
+	e := &expr{s: formula, vars: vars}
+	code, err := e.parse()
+	if err != nil {
+		return 0, fmt.Errorf("invalid version code formula %q: %w", formula, err)
+	}
+	if code <= 0 || code > maxVersionCode {
+		return 0, fmt.Errorf("version code %d of %s must be between 1 and %d", code, v, maxVersionCode)
+	}
+	return code, nil
+}
+
+// expr 四则运算表达式
+type expr struct {
+	s    string
+	pos  int
+	vars map[string]int64
+}
+
+func (e *expr) parse() (int64, error) {
+	n, err := e.sum()
+	if err == nil && e.peek() != 0 {
+		err = fmt.Errorf("unexpected %q", e.s[e.pos:])
+	}
+	return n, err
+}
+
+func (e *expr) peek() byte {
+	for e.pos < len(e.s) && e.s[e.pos] == ' ' {
+		e.pos++
+	}
+	if e.pos >= len(e.s) {
+		return 0
+	}
+	return e.s[e.pos]
+}
+
+func (e *expr) sum() (int64, error) {
+	n, err := e.product()
+	for err == nil {
+		op := e.peek()
+		if op != '+' && op != '-' {
+			break
+		}
+		e.pos++
+		var m int64
+		if m, err = e.product(); op == '+' {
+			n += m
+		} else {
+			n -= m
+		}
+	}
+	return n, err
+}
+
+func (e *expr) product() (int64, error) {
+	n, err := e.operand()
+	for err == nil {
+		op := e.peek()
+		if op != '*' && op != '/' {
+			break
+		}
+		e.pos++
+		var m int64
+		if m, err = e.operand(); err != nil {
+			break
+		}
+		if op == '*' {
+			n *= m
+		} else if m == 0 {
+			err = fmt.Errorf("division by zero")
+		} else {
+			n /= m
+		}
+	}
+	return n, err
+}
+
+func (e *expr) operand() (int64, error) {
+	c := e.peek()
+	switch {
+	case c == '(':
+		e.pos++
+		n, err := e.sum()
+		if err == nil && e.peek() != ')' {
+			err = fmt.Errorf("missing )")
+		}
+		e.pos++
+		return n, err
+	case c >= '0' && c <= '9':
+		start := e.pos
+		for e.pos < len(e.s) && e.s[e.pos] >= '0' && e.s[e.pos] <= '9' {
+			e.pos++
+		}
+		return strconv.ParseInt(e.s[start:e.pos], 10, 64)
+	case unicode.IsLetter(rune(c)):
+		start := e.pos
+		for e.pos < len(e.s) && unicode.IsLetter(rune(e.s[e.pos])) {
+			e.pos++
+		}
+		name := e.s[start:e.pos]
+		n, ok := e.vars[name]
+		if !ok {
+			return 0, fmt.Errorf("unknown variable %s", name)
+		}
+		return n, nil
+	case c == 0:
+		return 0, fmt.Errorf("unexpected end")
+	}
+	return 0, fmt.Errorf("unexpected %q", c)
+}
+
+// mobileVersions 移动应用的版本名称及构建版本号
+func (t Target) mobileVersions(v semver.Semver) (string, int64, error) {
+	build, err := t.build()
+	if err != nil {
+		return "", 0, err
+	}
+	code, err := VersionCode(t.VersionCode, v, build)
+	return v.String(), code, err
+}
+
+var (
+	gradleCode = regexp.MustCompile(`(?m)^(\s*versionCode\s*=?\s*)\d+`)
+	gradleName = regexp.MustCompile(`(?m)^(\s*versionName\s*=?\s*)["'][^"']*["']`)
+	propsCode  = regexp.MustCompile(`(?m)^(\s*(?:VERSION_CODE|versionCode)\s*=\s*)\S*`)
+	propsName  = regexp.MustCompile(`(?m)^(\s*(?:VERSION_NAME|versionName)\s*=\s*)\S*`)
+)
+
+// stampAndroid 更新 build.gradle、build.gradle.kts 中的 versionCode、versionName，或 gradle.properties 中的 VERSION_CODE、VERSION_NAME
+func (t Target) stampAndroid(content []byte, v semver.Semver) ([]byte, bool, error) {
+	name, code, err := t.mobileVersions(v)
+	if err != nil {
+		return nil, false, err
+	}
+	codeRe, nameRe, quote := gradleCode, gradleName, `"`
+	if strings.HasSuffix(t.path(), ".properties") {
+		codeRe, nameRe, quote = propsCode, propsName, ""
+	}
+	if !codeRe.Match(content) {
+		return nil, false, fmt.Errorf("no versionCode found")
+	}
+	updated := codeRe.ReplaceAll(content, []byte("${1}"+strconv.FormatInt(code, 10)))
+	updated = nameRe.ReplaceAll(updated, []byte("${1}"+quote+name+quote))
+	return updated, string(updated) != string(content), nil
+}
+
+var (
+	plistShort     = regexp.MustCompile(`(<key>CFBundleShortVersionString</key>\s*<string>)([^<$]*)(</string>)`)
+	plistBundle    = regexp.MustCompile(`(<key>CFBundleVersion</key>\s*<string>)([^<$]*)(</string>)`)
+	pbxMarketing   = regexp.MustCompile(`(MARKETING_VERSION = )[^;]*;`)
+	pbxProjVersion = regexp.MustCompile(`(CURRENT_PROJECT_VERSION = )[^;]*;`)
+)
+
+// stampIOS 更新 Info.plist 的 CFBundleShortVersionString、CFBundleVersion，或 project.pbxproj 的 MARKETING_VERSION、CURRENT_PROJECT_VERSION
+// App Store 的版本号只允许三段数字，预发布版本使用 X.Y.Z 并以构建版本号区分；引用 $(MARKETING_VERSION) 等变量的值不变
+func (t Target) stampIOS(content []byte, v semver.Semver) ([]byte, bool, error) {
+	_, code, err := t.mobileVersions(v)
+	if err != nil {
+		return nil, false, err
+	}
+	short := fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch())
+	bundle := strconv.FormatInt(code, 10)
+	var updated []byte
+	if filepath.Ext(t.path()) == ".pbxproj" {
+		if !pbxMarketing.Match(content) && !pbxProjVersion.Match(content) {
+			return nil, false, fmt.Errorf("no MARKETING_VERSION or CURRENT_PROJECT_VERSION found")
+		}
+		updated = pbxMarketing.ReplaceAll(content, []byte("${1}"+short+";"))
+		updated = pbxProjVersion.ReplaceAll(updated, []byte("${1}"+bundle+";"))
+	} else {
+		if !plistShort.Match(content) && !plistBundle.Match(content) {
+			return nil, false, fmt.Errorf("no CFBundleShortVersionString or CFBundleVersion found")
+		}
+		updated = plistShort.ReplaceAll(content, []byte("${1}"+short+"${3}"))
+		updated = plistBundle.ReplaceAll(updated, []byte("${1}"+bundle+"${3}"))
+	}
+	return updated, string(updated) != string(content), nil
+}
@@ -0,0 +1,30 @@
+package stamp
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+var (
+	specVersion = regexp.MustCompile(`(?mi)^(Version:\s*)(\S+)[ \t]*$`)
+	specRelease = regexp.MustCompile(`(?mi)^(Release:\s*)(\S+)[ \t]*$`)
+)
+
+// stampSpec 更新 RPM spec 文件的 Version 及 Release 字段，Release 保留原有的 %{?dist} 等宏后缀
+func (t Target) stampSpec(content []byte, v semver.Semver) ([]byte, bool, error) {
+	if !specVersion.Match(content) {
+		return nil, false, fmt.Errorf("no Version field")
+	}
+	updated := specVersion.ReplaceAll(content, []byte("${1}"+RPMVersion(v)))
+	if m := specRelease.FindSubmatch(updated); m != nil {
+		release := t.revision()
+		if i := strings.Index(string(m[2]), "%"); i >= 0 {
+			release += string(m[2][i:])
+		}
+		updated = specRelease.ReplaceAll(updated, []byte("${1}"+release))
+	}
+	return updated, string(updated) != string(content), nil
+}
@@ -0,0 +1,177 @@
+package stamp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/fileutil"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+const (
+	Debian      = "debian"
+	RPM         = "rpm"
+	VersionInfo = "versioninfo"
+	RC          = "rc"
+)
+
+// Target 同步版本号的打包文件，对应配置文件 sync 节点中的一项
+type Target struct {
+	Type         string `mapstructure:"type"`         // debian | rpm | versioninfo | rc | android | ios
+	Path         string `mapstructure:"path"`         // 相对于工作目录的路径，默认 debian/changelog、<package>.spec、versioninfo.json、<package>.rc 或 app/build.gradle，ios 需要指定 Info.plist 或 project.pbxproj
+	Package      string `mapstructure:"package"`      // 软件包名称，debian/changelog 已有条目时默认沿用
+	Revision     string `mapstructure:"revision"`     // 打包修订号，Debian 为 -<revision>，RPM 为 Release 字段，默认 1
+	Distribution string `mapstructure:"distribution"` // Debian 发行版，默认 unstable，预发布版本默认 UNRELEASED
+	Urgency      string `mapstructure:"urgency"`      // Debian 紧急程度，默认 medium
+	Maintainer   string `mapstructure:"maintainer"`   // 维护者，如 Name <email>，默认读取 DEBFULLNAME、DEBEMAIL 环境变量或 git 用户
+	Build        string `mapstructure:"build"`        // Windows 版本的第四段，支持环境变量如 $GITHUB_RUN_NUMBER 及 {{ .BuildNumber }}，默认 0
+	MSI          bool   `mapstructure:"msi"`          // 检查版本是否满足 MSI ProductVersion 的限制
+	VersionCode  string `mapstructure:"versionCode"`  // Android versionCode 及 iOS CFBundleVersion 的计算公式，默认 DefaultVersionCode
+}
+
+// Data 同步的版本信息
+type Data struct {
+	Version     semver.Semver
+	Changelog   *changelog.Changelog // 该版本的变更，用于生成 debian/changelog 条目
+	Date        time.Time
+	Maintainer  string // 未配置维护者时使用
+	BuildNumber int64  // 单调递增的构建号，Build 中通过 {{ .BuildNumber }} 引用
+}
+
+func (t Target) path() string {
+	if t.Path != "" {
+		return t.Path
+	}
+	switch t.Type {
+	case RPM:
+		return t.Package + ".spec"
+	case VersionInfo:
+		return "versioninfo.json"
+	case RC:
+		return t.Package + ".rc"
+	case Android:
+		return "app/build.gradle"
+	}
+	return "debian/changelog"
+}
+
+func (t Target) revision() string {
+	if t.Revision != "" {
+		return t.Revision
+	}
+	return "1"
+}
+
+// Validate 检查配置
+func (t Target) Validate() error {
+	switch t.Type {
+	case Debian, VersionInfo, Android:
+	case RPM, RC:
+		if t.Path == "" && t.Package == "" {
+			return fmt.Errorf("%s sync target requires path or package", t.Type)
+		}
+	case IOS:
+		if t.Path == "" {
+			return fmt.Errorf("ios sync target requires the path of Info.plist or project.pbxproj")
+		}
+	default:
+		return fmt.Errorf("unsupported sync type %q, one of %s|%s|%s|%s|%s|%s", t.Type, Debian, RPM, VersionInfo, RC, Android, IOS)
+	}
+	t, err := t.withBuildNumber(0)
+	if err != nil {
+		return err
+	}
+	_, err = t.build()
+	return err
+}
+
+// Apply 将版本写入工作目录下的打包文件，返回文件路径及是否变更；debian/changelog 及 Windows 版本资源不存在时生成
+func Apply(dir string, t Target, data Data) (string, bool, error) {
+	if err := t.Validate(); err != nil {
+		return "", false, err
+	}
+	path, err := fileutil.Join(dir, t.path())
+	if err != nil {
+		return "", false, err
+	}
+	if t, err = t.withBuildNumber(data.BuildNumber); err != nil {
+		return path, false, err
+	}
+	var stamp func(content []byte) ([]byte, bool, error)
+	switch t.Type {
+	case Debian:
+		stamp = func(content []byte) ([]byte, bool, error) {
+			return t.prependDebian(content, data)
+		}
+	case RPM:
+		stamp = func(content []byte) ([]byte, bool, error) {
+			return t.stampSpec(content, data.Version)
+		}
+	case Android:
+		stamp = func(content []byte) ([]byte, bool, error) {
+			return t.stampAndroid(content, data.Version)
+		}
+	case IOS:
+		stamp = func(content []byte) ([]byte, bool, error) {
+			return t.stampIOS(content, data.Version)
+		}
+	case VersionInfo, RC:
+		build, _ := t.build()
+		w, err := NewWindowsVersion(data.Version, build, t.MSI)
+		if err != nil {
+			return path, false, err
+		}
+		stamp = func(content []byte) ([]byte, bool, error) {
+			if t.Type == RC {
+				return t.stampRC(content, data.Version, w)
+			}
+			return t.stampVersionInfo(content, data.Version, w)
+		}
+	}
+	if _, err = os.Stat(path); os.IsNotExist(err) && (t.Type == Debian || t.Type == VersionInfo || t.Type == RC) {
+		content, _, err := stamp(nil)
+		if err != nil {
+			return path, false, fmt.Errorf("%s: %w", t.path(), err)
+		}
+		if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return path, false, err
+		}
+		return path, true, fileutil.WriteFile(path, content, 0o644)
+	}
+	changed, err := fileutil.Update(path, stamp)
+	if err != nil {
+		return path, false, fmt.Errorf("%s: %w", t.path(), err)
+	}
+	return path, changed, nil
+}
+
+// distroVersion 发行版使用的上游版本：预发布分隔符 - 替换为 ~ 使其排在正式版本之前，标识符中的 - 替换为 .，忽略构建元数据
+// 如 1.2.0-rc.1 为 1.2.0~rc.1，1.2.0-beta-2+sha.abc 为 1.2.0~beta.2
+func distroVersion(v semver.Semver) string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major(), v.Minor(), v.Patch())
+	if pre := v.PreRelease(); len(pre) > 0 {
+		ids := make([]string, 0, len(pre))
+		for _, id := range pre {
+			ids = append(ids, strings.ReplaceAll(id.Raw, "-", "."))
+		}
+		s += "~" + strings.Join(ids, ".")
+	}
+	return s
+}
+
+// DebianVersion Debian 软件包版本，如 1.2.0~rc.1-1
+func DebianVersion(v semver.Semver, revision string) string {
+	if revision == "" {
+		revision = "1"
+	}
+	return distroVersion(v) + "-" + revision
+}
+
+// RPMVersion RPM Version 字段，如 1.2.0~rc.1，需要 rpm 4.10 及以上版本
+func RPMVersion(v semver.Semver) string {
+	return distroVersion(v)
+}
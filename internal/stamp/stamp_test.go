@@ -0,0 +1,160 @@
+package stamp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+func TestDistroVersion(t *testing.T) {
+	tests := map[string]string{
+		"1.2.0":              "1.2.0-1",
+		"1.2.0-rc.1":         "1.2.0~rc.1-1",
+		"1.2.0-beta-2+sha.1": "1.2.0~beta.2-1",
+	}
+	for s, expected := range tests {
+		v, _ := semver.Version(s)
+		if got := DebianVersion(v, ""); got != expected {
+			t.Errorf("DebianVersion(%s) expected %s, but %s got", s, expected, got)
+		}
+	}
+}
+
+func TestApply_Debian(t *testing.T) {
+	t.Setenv("DEBEMAIL", "")
+	dir := t.TempDir()
+	file := filepath.Join(dir, "debian", "changelog")
+	_ = os.MkdirAll(filepath.Dir(file), 0o755)
+	old := "autoctl (1.1.0-1) unstable; urgency=medium\n\n  * Initial release.\n\n -- Dev <dev@example.com>  Mon, 01 Jan 2024 00:00:00 +0000\n"
+	_ = os.WriteFile(file, []byte(old), 0o644)
+	v, _ := semver.Version("1.2.0-rc.1")
+	data := Data{
+		Version:    v,
+		Date:       time.Date(2024, 5, 21, 8, 30, 0, 0, time.UTC),
+		Maintainer: "Dev <dev@example.com>",
+		Changelog: &changelog.Changelog{Sections: []changelog.Section{
+			{Type: "feat", Commits: []changelog.Commit{{Scope: "cli", Subject: "add sync"}}},
+		}},
+	}
+	if _, changed, err := Apply(dir, Target{Type: Debian}, data); err != nil || !changed {
+		t.Fatalf("expected changelog updated, but %v got", err)
+	}
+	content, _ := os.ReadFile(file)
+	expected := "autoctl (1.2.0~rc.1-1) UNRELEASED; urgency=medium\n\n  * feat(cli): add sync\n\n -- Dev <dev@example.com>  Tue, 21 May 2024 08:30:00 +0000\n\n" + old
+	if string(content) != expected {
+		t.Errorf("expected\n%s\nbut\n%s\ngot", expected, content)
+	}
+	if _, changed, err := Apply(dir, Target{Type: Debian}, data); err != nil || changed {
+		t.Errorf("expected same version not added twice, but %v got", err)
+	}
+}
+
+func TestApply_RPM(t *testing.T) {
+	dir := t.TempDir()
+	spec := "Name: autoctl\nVersion: 1.1.0\nRelease: 3%{?dist}\nSummary: cli\n"
+	_ = os.WriteFile(filepath.Join(dir, "autoctl.spec"), []byte(spec), 0o644)
+	v, _ := semver.Version("1.2.0-rc.1")
+	if _, changed, err := Apply(dir, Target{Type: RPM, Package: "autoctl"}, Data{Version: v}); err != nil || !changed {
+		t.Fatalf("expected spec updated, but %v got", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, "autoctl.spec"))
+	if !strings.Contains(string(content), "Version: 1.2.0~rc.1\nRelease: 1%{?dist}\n") {
+		t.Errorf("unexpected spec\n%s", content)
+	}
+}
+
+func TestNewWindowsVersion(t *testing.T) {
+	v, _ := semver.Version("1.2.3-rc.1")
+	w, err := NewWindowsVersion(v, 42, true)
+	if err != nil || w.FileVersion() != "1.2.3.42" || w.ProductVersion() != "1.2.3" {
+		t.Errorf("unexpected windows version %+v %v", w, err)
+	}
+	big, _ := semver.Version("256.0.0")
+	if _, err = NewWindowsVersion(big, 0, true); err == nil {
+		t.Error("expected msi major version limit error")
+	}
+	if _, err = NewWindowsVersion(big, 0, false); err != nil {
+		t.Errorf("expected file version allowed, but %v got", err)
+	}
+}
+
+func TestApply_Windows(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("BUILD_NUMBER", "7")
+	v, _ := semver.Version("1.2.0-rc.1")
+	info := `{"StringFileInfo":{"CompanyName":"coffee377"}}`
+	_ = os.WriteFile(filepath.Join(dir, "versioninfo.json"), []byte(info), 0o644)
+	if _, changed, err := Apply(dir, Target{Type: VersionInfo, Build: "$BUILD_NUMBER"}, Data{Version: v}); err != nil || !changed {
+		t.Fatalf("expected versioninfo.json updated, but %v got", err)
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, "versioninfo.json"))
+	for _, s := range []string{`"CompanyName": "coffee377"`, `"FileVersion": "1.2.0.7"`, `"ProductVersion": "1.2.0-rc.1"`, `"Build": 7`} {
+		if !strings.Contains(string(content), s) {
+			t.Errorf("expected versioninfo.json contains %s, but\n%s got", s, content)
+		}
+	}
+
+	if _, changed, err := Apply(dir, Target{Type: RC, Package: "autoctl"}, Data{Version: v}); err != nil || !changed {
+		t.Fatalf("expected autoctl.rc generated, but %v got", err)
+	}
+	next, _ := semver.Version("1.3.0")
+	if _, _, err := Apply(dir, Target{Type: RC, Package: "autoctl", Build: "{{ .BuildNumber }}"}, Data{Version: next, BuildNumber: 12}); err != nil {
+		t.Fatal(err)
+	}
+	content, _ = os.ReadFile(filepath.Join(dir, "autoctl.rc"))
+	for _, s := range []string{"FILEVERSION 1,3,0,12", "PRODUCTVERSION 1,3,0,12", `VALUE "FileVersion", "1.3.0.12"`, `VALUE "ProductVersion", "1.3.0"`} {
+		if !strings.Contains(string(content), s) {
+			t.Errorf("expected autoctl.rc contains %s, but\n%s got", s, content)
+		}
+	}
+}
+
+func TestVersionCode(t *testing.T) {
+	tests := []struct {
+		formula, version string
+		expected         int64
+	}{
+		{"", "1.2.3", 1020399},
+		{"", "1.2.3-rc.2", 1020302},
+		{"(major * 100 + minor) * 1000 + build", "2.5.0", 205042},
+	}
+	for _, tt := range tests {
+		v, _ := semver.Version(tt.version)
+		if code, err := VersionCode(tt.formula, v, 42); err != nil || code != tt.expected {
+			t.Errorf("VersionCode(%q, %s) expected %d, but %d %v got", tt.formula, tt.version, tt.expected, code, err)
+		}
+	}
+	v, _ := semver.Version("1.0.0")
+	if _, err := VersionCode("major * epoch", v, 0); err == nil {
+		t.Error("expected unknown variable error")
+	}
+}
+
+func TestApply_Mobile(t *testing.T) {
+	dir := t.TempDir()
+	_ = os.MkdirAll(filepath.Join(dir, "app"), 0o755)
+	gradle := "android {\n    defaultConfig {\n        versionCode 1\n        versionName \"1.0\"\n    }\n}\n"
+	_ = os.WriteFile(filepath.Join(dir, "app", "build.gradle"), []byte(gradle), 0o644)
+	plist := "<dict>\n\t<key>CFBundleShortVersionString</key>\n\t<string>1.0</string>\n\t<key>CFBundleVersion</key>\n\t<string>1</string>\n</dict>\n"
+	_ = os.WriteFile(filepath.Join(dir, "Info.plist"), []byte(plist), 0o644)
+	v, _ := semver.Version("1.2.0-beta.3")
+	if _, _, err := Apply(dir, Target{Type: Android}, Data{Version: v}); err != nil {
+		t.Fatal(err)
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, "app", "build.gradle"))
+	if !strings.Contains(string(content), "versionCode 1020003\n        versionName \"1.2.0-beta.3\"") {
+		t.Errorf("unexpected build.gradle\n%s", content)
+	}
+	if _, _, err := Apply(dir, Target{Type: IOS, Path: "Info.plist"}, Data{Version: v}); err != nil {
+		t.Fatal(err)
+	}
+	content, _ = os.ReadFile(filepath.Join(dir, "Info.plist"))
+	if !strings.Contains(string(content), "<string>1.2.0</string>") || !strings.Contains(string(content), "<string>1020003</string>") {
+		t.Errorf("unexpected Info.plist\n%s", content)
+	}
+}
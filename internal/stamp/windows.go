@@ -0,0 +1,156 @@
+package stamp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/tmpl"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// WindowsVersion Windows 文件版本及 MSI 产品版本
+type WindowsVersion struct {
+	Major, Minor, Patch, Build uint16
+}
+
+// FileVersion 四段式文件版本，如 1.2.0.5
+func (w WindowsVersion) FileVersion() string {
+	return fmt.Sprintf("%d.%d.%d.%d", w.Major, w.Minor, w.Patch, w.Build)
+}
+
+// ProductVersion MSI ProductVersion，只有前三段参与升级比较
+func (w WindowsVersion) ProductVersion() string {
+	return fmt.Sprintf("%d.%d.%d", w.Major, w.Minor, w.Patch)
+}
+
+// NewWindowsVersion 将版本转换为 Windows 版本，每段不超过 65535；
+// msi 为 true 时检查 MSI ProductVersion 的限制：主版本及次版本不超过 255
+// Windows 版本无法表示预发布版本，预发布版本与正式版本的前三段相同，需要通过 build 区分
+func NewWindowsVersion(v semver.Semver, build uint64, msi bool) (WindowsVersion, error) {
+	parts := []uint64{v.Major(), v.Minor(), v.Patch(), build}
+	limits := []uint64{65535, 65535, 65535, 65535}
+	if msi {
+		limits[0], limits[1] = 255, 255
+	}
+	names := []string{"major", "minor", "patch", "build"}
+	for i, p := range parts {
+		if p > limits[i] {
+			return WindowsVersion{}, fmt.Errorf("%s version %d of %s exceeds the windows limit %d", names[i], p, v, limits[i])
+		}
+	}
+	return WindowsVersion{uint16(parts[0]), uint16(parts[1]), uint16(parts[2]), uint16(parts[3])}, nil
+}
+
+// withBuildNumber 渲染 Build 中的 {{ .BuildNumber }} 模板
+func (t Target) withBuildNumber(n int64) (Target, error) {
+	if !strings.Contains(t.Build, "{{") {
+		return t, nil
+	}
+	build, err := tmpl.Render("build", t.Build, struct{ BuildNumber int64 }{n})
+	if err != nil {
+		return t, err
+	}
+	t.Build = build
+	return t, nil
+}
+
+// build 第四段版本号，支持环境变量如 $GITHUB_RUN_NUMBER，默认 0
+func (t Target) build() (uint64, error) {
+	s := strings.TrimSpace(os.ExpandEnv(t.Build))
+	if s == "" {
+		return 0, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid windows build number %q", s)
+	}
+	return n, nil
+}
+
+// stampVersionInfo 更新 goversioninfo 的 versioninfo.json，保留其它字段，文件为空时生成
+// https://github.com/josephspurrier/goversioninfo
+func (t Target) stampVersionInfo(content []byte, v semver.Semver, w WindowsVersion) ([]byte, bool, error) {
+	info := map[string]interface{}{}
+	if len(strings.TrimSpace(string(content))) > 0 {
+		if err := json.Unmarshal(content, &info); err != nil {
+			return nil, false, err
+		}
+	}
+	section := func(name string) map[string]interface{} {
+		m, ok := info[name].(map[string]interface{})
+		if !ok {
+			m = map[string]interface{}{}
+			info[name] = m
+		}
+		return m
+	}
+	fixed := map[string]interface{}{"Major": w.Major, "Minor": w.Minor, "Patch": w.Patch, "Build": w.Build}
+	section("FixedFileInfo")["FileVersion"] = fixed
+	section("FixedFileInfo")["ProductVersion"] = fixed
+	strs := section("StringFileInfo")
+	strs["FileVersion"] = w.FileVersion()
+	strs["ProductVersion"] = v.String()
+	if _, ok := strs["ProductName"]; !ok && t.Package != "" {
+		strs["ProductName"] = t.Package
+	}
+	if _, ok := info["VarFileInfo"]; !ok {
+		info["VarFileInfo"] = map[string]interface{}{"Translation": map[string]string{"LangID": "0409", "CharsetID": "04B0"}}
+	}
+	data, err := json.MarshalIndent(info, "", "  ")
+	if err != nil {
+		return nil, false, err
+	}
+	data = append(data, '\n')
+	return data, string(data) != string(content), nil
+}
+
+var (
+	rcFixed  = regexp.MustCompile(`(?m)^(\s*(?:FILEVERSION|PRODUCTVERSION)\s+)\d+\s*,\s*\d+\s*,\s*\d+\s*,\s*\d+`)
+	rcFile   = regexp.MustCompile(`(?m)^(\s*VALUE\s+"FileVersion"\s*,\s*)"[^"]*"`)
+	rcProd   = regexp.MustCompile(`(?m)^(\s*VALUE\s+"ProductVersion"\s*,\s*)"[^"]*"`)
+	rcHeader = regexp.MustCompile(`(?m)^\s*\S+\s+VERSIONINFO\b`)
+)
+
+// stampRC 更新资源脚本中 VERSIONINFO 的版本，文件为空时生成
+func (t Target) stampRC(content []byte, v semver.Semver, w WindowsVersion) ([]byte, bool, error) {
+	if len(strings.TrimSpace(string(content))) == 0 {
+		return []byte(rcTemplate(t.Package, v, w)), true, nil
+	}
+	if !rcHeader.Match(content) {
+		return nil, false, fmt.Errorf("no VERSIONINFO resource")
+	}
+	comma := fmt.Sprintf("%d,%d,%d,%d", w.Major, w.Minor, w.Patch, w.Build)
+	updated := rcFixed.ReplaceAll(content, []byte("${1}"+comma))
+	updated = rcFile.ReplaceAll(updated, []byte(`${1}"`+w.FileVersion()+`"`))
+	updated = rcProd.ReplaceAll(updated, []byte(`${1}"`+v.String()+`"`))
+	return updated, string(updated) != string(content), nil
+}
+
+func rcTemplate(name string, v semver.Semver, w WindowsVersion) string {
+	comma := fmt.Sprintf("%d,%d,%d,%d", w.Major, w.Minor, w.Patch, w.Build)
+	return fmt.Sprintf(`1 VERSIONINFO
+FILEVERSION %[1]s
+PRODUCTVERSION %[1]s
+FILEOS 0x40004
+FILETYPE 0x1
+BEGIN
+  BLOCK "StringFileInfo"
+  BEGIN
+    BLOCK "040904B0"
+    BEGIN
+      VALUE "FileVersion", "%[2]s"
+      VALUE "ProductName", "%[3]s"
+      VALUE "ProductVersion", "%[4]s"
+    END
+  END
+  BLOCK "VarFileInfo"
+  BEGIN
+    VALUE "Translation", 0x409, 1200
+  END
+END
+`, comma, w.FileVersion(), name, v)
+}
@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// awsProfile 读取 AWS 共享凭证文件中的配置，文件默认为 ~/.aws/credentials，可通过 AWS_SHARED_CREDENTIALS_FILE 指定
+func awsProfile(profile string) (map[string]string, error) {
+	if profile == "" {
+		profile = "default"
+	}
+	file := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if file == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, err
+		}
+		file = filepath.Join(home, ".aws", "credentials")
+	}
+	f, err := os.Open(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s not found", ErrNoCredentials, file)
+		}
+		return nil, err
+	}
+	defer f.Close()
+	var (
+		section string
+		values  = map[string]string{}
+		found   bool
+	)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			found = found || section == profile
+			continue
+		}
+		if section != profile {
+			continue
+		}
+		if k, v, ok := strings.Cut(line, "="); ok {
+			values[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("%w: profile %s not found in %s", ErrNoCredentials, profile, file)
+	}
+	return values, nil
+}
+
+// aliyunCredentials 阿里云 CLI 配置中的访问密钥
+type aliyunCredentials struct {
+	Name            string `json:"name"`
+	AccessKeyID     string `json:"access_key_id"`
+	AccessKeySecret string `json:"access_key_secret"`
+	StsToken        string `json:"sts_token"`
+}
+
+// aliyunProfile 读取阿里云 CLI 配置 ~/.aliyun/config.json 中的配置，为空时使用其中的当前配置
+func aliyunProfile(profile string) (*aliyunCredentials, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return nil, err
+	}
+	file := filepath.Join(home, ".aliyun", "config.json")
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s not found", ErrNoCredentials, file)
+		}
+		return nil, err
+	}
+	config := struct {
+		Current  string              `json:"current"`
+		Profiles []aliyunCredentials `json:"profiles"`
+	}{}
+	if err = json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", file, err)
+	}
+	if profile == "" {
+		profile = config.Current
+	}
+	for _, p := range config.Profiles {
+		if p.Name == profile {
+			return &p, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: profile %s not found in %s", ErrNoCredentials, profile, file)
+}
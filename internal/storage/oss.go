@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// oss 阿里云对象存储
+type oss struct {
+	cfg    Config
+	client *http.Client
+	id     string
+	secret string
+	token  string // STS 临时凭证的安全令牌
+	now    func() time.Time
+}
+
+func newOSS(cfg Config, client *http.Client) (*oss, error) {
+	if cfg.Region == "" && cfg.Endpoint == "" {
+		return nil, fmt.Errorf("storage %s: region or endpoint is required", cfg.String())
+	}
+	b := &oss{cfg: cfg, client: client, id: cfg.AccessKeyID, secret: cfg.SecretAccessKey, now: time.Now}
+	if b.id == "" {
+		if cfg.Profile == "" && os.Getenv("OSS_ACCESS_KEY_ID") != "" {
+			b.id, b.secret, b.token = os.Getenv("OSS_ACCESS_KEY_ID"), os.Getenv("OSS_ACCESS_KEY_SECRET"), os.Getenv("OSS_SESSION_TOKEN")
+		} else {
+			creds, err := aliyunProfile(cfg.Profile)
+			if err != nil {
+				return nil, fmt.Errorf("storage %s: %w", cfg.String(), err)
+			}
+			b.id, b.secret, b.token = creds.AccessKeyID, creds.AccessKeySecret, creds.StsToken
+		}
+	}
+	if b.id == "" || b.secret == "" {
+		return nil, fmt.Errorf("storage %s: %w, set accessKeyId and secretAccessKey, a profile or OSS_ACCESS_KEY_ID and OSS_ACCESS_KEY_SECRET", cfg.String(), ErrNoCredentials)
+	}
+	return b, nil
+}
+
+// objectURL 未设置服务地址时使用 https://<bucket>.<region>.aliyuncs.com，否则使用路径形式
+func (b *oss) objectURL(key string) string {
+	if b.cfg.Endpoint == "" {
+		return fmt.Sprintf("https://%s.%s.aliyuncs.com/%s", b.cfg.Bucket, b.cfg.Region, escapePath(key))
+	}
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(b.cfg.Endpoint, "/"), b.cfg.Bucket, escapePath(key))
+}
+
+// Put https://help.aliyun.com/zh/oss/developer-reference/putobject
+func (b *oss) Put(key string, body io.ReadSeeker, size int64, _ string, contentType string) (string, error) {
+	u := b.objectURL(key)
+	req, err := newPut(u, body, size)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if b.token != "" {
+		req.Header.Set("X-Oss-Security-Token", b.token)
+	}
+	// 签名中的资源为未编码的 /<bucket>/<key>
+	signOSS(req, "/"+b.cfg.Bucket+"/"+key, b.id, b.secret, b.now())
+	return u, send(b.client, req)
+}
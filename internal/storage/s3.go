@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// s3 Amazon S3 及兼容服务（MinIO 等），GCS 使用 HMAC 密钥时同样通过 S3 兼容接口上传
+type s3 struct {
+	cfg    Config
+	client *http.Client
+	id     string
+	secret string
+	token  string // 临时凭证的会话令牌
+	now    func() time.Time
+}
+
+func newS3(cfg Config, client *http.Client) (*s3, error) {
+	if cfg.Region == "" {
+		cfg.Region = os.Getenv("AWS_REGION")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	b := &s3{cfg: cfg, client: client, id: cfg.AccessKeyID, secret: cfg.SecretAccessKey, now: time.Now}
+	if b.id == "" {
+		profile := cfg.Profile
+		if profile == "" {
+			profile = os.Getenv("AWS_PROFILE")
+		}
+		if profile == "" && os.Getenv("AWS_ACCESS_KEY_ID") != "" {
+			b.id, b.secret, b.token = os.Getenv("AWS_ACCESS_KEY_ID"), os.Getenv("AWS_SECRET_ACCESS_KEY"), os.Getenv("AWS_SESSION_TOKEN")
+		} else {
+			creds, err := awsProfile(profile)
+			if err != nil {
+				return nil, fmt.Errorf("storage %s: %w", cfg.String(), err)
+			}
+			b.id, b.secret, b.token = creds["aws_access_key_id"], creds["aws_secret_access_key"], creds["aws_session_token"]
+		}
+	}
+	if b.id == "" || b.secret == "" {
+		return nil, fmt.Errorf("storage %s: %w, set accessKeyId and secretAccessKey, a profile or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", cfg.String(), ErrNoCredentials)
+	}
+	return b, nil
+}
+
+// newGCS 通过 S3 兼容接口访问 GCS，需要服务账号的 HMAC 密钥
+// https://cloud.google.com/storage/docs/interoperability
+func newGCS(cfg Config, client *http.Client) (*s3, error) {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://storage.googleapis.com"
+	}
+	cfg.Region = "auto"
+	if cfg.AccessKeyID == "" {
+		cfg.AccessKeyID, cfg.SecretAccessKey = os.Getenv("GCS_ACCESS_KEY_ID"), os.Getenv("GCS_SECRET_ACCESS_KEY")
+	}
+	if cfg.AccessKeyID == "" || cfg.SecretAccessKey == "" {
+		return nil, fmt.Errorf("storage %s: %w, set accessKeyId and secretAccessKey or GCS_ACCESS_KEY_ID and GCS_SECRET_ACCESS_KEY with a HMAC key", cfg.String(), ErrNoCredentials)
+	}
+	return &s3{cfg: cfg, client: client, id: cfg.AccessKeyID, secret: cfg.SecretAccessKey, now: time.Now}, nil
+}
+
+// objectURL 未设置服务地址时使用虚拟主机形式，否则使用路径形式
+func (b *s3) objectURL(key string) string {
+	if b.cfg.Endpoint == "" {
+		return fmt.Sprintf("https://%s.s3.%s.amazonaws.com/%s", b.cfg.Bucket, b.cfg.Region, escapePath(key))
+	}
+	return fmt.Sprintf("%s/%s/%s", strings.TrimSuffix(b.cfg.Endpoint, "/"), b.cfg.Bucket, escapePath(key))
+}
+
+// Put https://docs.aws.amazon.com/AmazonS3/latest/API/API_PutObject.html
+func (b *s3) Put(key string, body io.ReadSeeker, size int64, sha256 string, contentType string) (string, error) {
+	u := b.objectURL(key)
+	req, err := newPut(u, body, size)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType)
+	if b.token != "" {
+		req.Header.Set("X-Amz-Security-Token", b.token)
+	}
+	signV4(req, sha256, b.cfg.Region, b.id, b.secret, b.now())
+	return u, send(b.client, req)
+}
+
+// newPut 创建上传请求，请求体可重放以便重试
+func newPut(u string, body io.ReadSeeker, size int64) (*http.Request, error) {
+	req, err := http.NewRequest(http.MethodPut, u, io.NopCloser(body))
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = size
+	req.GetBody = func() (io.ReadCloser, error) {
+		if _, err := body.Seek(0, io.SeekStart); err != nil {
+			return nil, err
+		}
+		return io.NopCloser(body), nil
+	}
+	return req, nil
+}
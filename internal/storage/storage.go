@@ -0,0 +1,247 @@
+package storage
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/artifact"
+	"github.com/coffee377/autoctl/internal/httpclient"
+)
+
+const (
+	S3  = "s3"
+	OSS = "oss"
+	GCS = "gcs"
+
+	DefaultPrefix = "releases/{version}"
+	DefaultLatest = "releases/latest"
+	// LatestFile 固定目录中记录最新版本的文件
+	LatestFile = "latest.json"
+)
+
+// Config 对象存储上传目标，对应配置文件 storage 节点中的一项
+type Config struct {
+	Name            string            `mapstructure:"name"`            // 名称，用于日志输出
+	Type            string            `mapstructure:"type"`            // s3 | oss | gcs
+	Bucket          string            `mapstructure:"bucket"`          // 存储桶
+	Region          string            `mapstructure:"region"`          // 区域，S3 默认读取 AWS_REGION 或 us-east-1，OSS 如 oss-cn-hangzhou
+	Endpoint        string            `mapstructure:"endpoint"`        // 自定义服务地址，如 MinIO，设置后使用路径形式访问存储桶
+	Profile         string            `mapstructure:"profile"`         // 凭证配置名称，S3 读取 ~/.aws/credentials，OSS 读取 ~/.aliyun/config.json
+	AccessKeyID     string            `mapstructure:"accessKeyId"`     // 访问密钥，为空时读取凭证配置或环境变量
+	SecretAccessKey string            `mapstructure:"secretAccessKey"` // 访问密钥对应的私钥
+	Prefix          string            `mapstructure:"prefix"`          // 版本目录，{version} 替换为版本号，默认 releases/{version}
+	Latest          string            `mapstructure:"latest"`          // 正式版本同时上传到的固定目录，默认 releases/latest，设为 - 时不上传
+	PublicURL       string            `mapstructure:"publicUrl"`       // 下载地址前缀，如 CDN 域名，为空时使用存储桶地址
+	HTTP            httpclient.Config `mapstructure:"http"`            // 代理、TLS 及重试配置，未设置的字段继承全局 http 节点
+}
+
+func (c Config) String() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.Type + "://" + c.Bucket
+}
+
+// Object 已上传的对象
+type Object struct {
+	Target string `json:"target"` // 上传目标名称
+	Key    string `json:"key"`
+	URL    string `json:"url"`
+}
+
+// File 待上传的文件
+type File struct {
+	Name   string // 对象名称，位于版本目录下
+	Path   string // 本地文件路径，为空时上传 Data
+	Data   []byte
+	SHA256 string // 文件摘要，为空时计算
+}
+
+// Bucket 对象存储
+type Bucket interface {
+	// Put 上传对象，返回下载地址
+	Put(key string, body io.ReadSeeker, size int64, sha256 string, contentType string) (string, error)
+}
+
+// New 根据类型创建对象存储客户端
+func New(cfg Config) (Bucket, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("storage %s: bucket is required", cfg.String())
+	}
+	client, err := httpclient.New(cfg.HTTP)
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(cfg.Type) {
+	case S3:
+		return newS3(cfg, client)
+	case GCS:
+		return newGCS(cfg, client)
+	case OSS:
+		return newOSS(cfg, client)
+	default:
+		return nil, fmt.Errorf("unsupported storage type %q, one of %s|%s|%s", cfg.Type, S3, OSS, GCS)
+	}
+}
+
+// Validate 检查配置及凭证，不访问网络
+func (c Config) Validate() error {
+	_, err := New(c)
+	return err
+}
+
+// Release 上传的发布内容
+type Release struct {
+	Version string
+	Tag     string
+	Latest  bool // 是否同时上传到固定目录，预发布版本及维护分支发布为 false
+	Files   []File
+}
+
+// latestIndex 固定目录中的 latest.json
+type latestIndex struct {
+	Version   string    `json:"version"`
+	Tag       string    `json:"tag"`
+	Prefix    string    `json:"prefix"`
+	Files     []string  `json:"files"`
+	Published time.Time `json:"published"`
+}
+
+// Keys 返回版本目录及固定目录，不上传到固定目录时后者为空
+func (c Config) Keys(version string, latest bool) (string, string) {
+	prefix := c.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+	prefix = strings.Trim(strings.ReplaceAll(prefix, "{version}", version), "/")
+	if !latest || c.Latest == "-" {
+		return prefix, ""
+	}
+	fixed := c.Latest
+	if fixed == "" {
+		fixed = DefaultLatest
+	}
+	return prefix, strings.Trim(fixed, "/")
+}
+
+// Upload 将文件上传到版本目录，需要时再上传到固定目录并写入 latest.json，固定目录在版本目录全部上传成功后才更新
+func Upload(b Bucket, cfg Config, rel Release) ([]Object, error) {
+	prefix, fixed := cfg.Keys(rel.Version, rel.Latest)
+	objects := make([]Object, 0, len(rel.Files))
+	names := make([]string, 0, len(rel.Files))
+	for _, f := range rel.Files {
+		key := path.Join(prefix, f.Name)
+		u, err := put(b, key, f)
+		if err != nil {
+			return objects, fmt.Errorf("upload %s to %s: %w", key, cfg.String(), err)
+		}
+		objects = append(objects, Object{Target: cfg.String(), Key: key, URL: cfg.url(key, u)})
+		names = append(names, f.Name)
+	}
+	if fixed == "" {
+		return objects, nil
+	}
+	for _, f := range rel.Files {
+		key := path.Join(fixed, f.Name)
+		if _, err := put(b, key, f); err != nil {
+			return objects, fmt.Errorf("upload %s to %s: %w", key, cfg.String(), err)
+		}
+	}
+	data, err := json.MarshalIndent(latestIndex{
+		Version:   rel.Version,
+		Tag:       rel.Tag,
+		Prefix:    prefix,
+		Files:     names,
+		Published: time.Now().UTC(),
+	}, "", "  ")
+	if err != nil {
+		return objects, err
+	}
+	key := path.Join(fixed, LatestFile)
+	u, err := put(b, key, File{Name: LatestFile, Data: data})
+	if err != nil {
+		return objects, fmt.Errorf("upload %s to %s: %w", key, cfg.String(), err)
+	}
+	return append(objects, Object{Target: cfg.String(), Key: key, URL: cfg.url(key, u)}), nil
+}
+
+// url 配置了下载地址前缀时使用该前缀
+func (c Config) url(key, u string) string {
+	if c.PublicURL == "" {
+		return u
+	}
+	return strings.TrimSuffix(c.PublicURL, "/") + "/" + escapePath(key)
+}
+
+func put(b Bucket, key string, f File) (string, error) {
+	contentType := mime.TypeByExtension(path.Ext(f.Name))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	if f.Path == "" {
+		sum := sha256.Sum256(f.Data)
+		return b.Put(key, bytes.NewReader(f.Data), int64(len(f.Data)), hex.EncodeToString(sum[:]), contentType)
+	}
+	file, err := os.Open(f.Path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	sum := f.SHA256
+	if sum == "" {
+		h := sha256.New()
+		if _, err = io.Copy(h, file); err != nil {
+			return "", err
+		}
+		if _, err = file.Seek(0, io.SeekStart); err != nil {
+			return "", err
+		}
+		sum = hex.EncodeToString(h.Sum(nil))
+	}
+	return b.Put(key, file, info.Size(), sum, contentType)
+}
+
+// Files 发布产物及变更日志对应的待上传文件
+func Files(artifacts []artifact.Artifact, changelog string) []File {
+	files := make([]File, 0, len(artifacts)+1)
+	for _, a := range artifacts {
+		files = append(files, File{Name: filepath.Base(a.Name), Path: a.Path, SHA256: a.SHA256})
+	}
+	if changelog != "" {
+		files = append(files, File{Name: "CHANGELOG.md", Data: []byte(changelog)})
+	}
+	return files
+}
+
+// send 发送已签名的上传请求，失败时返回响应中的错误信息
+func send(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("%s %s: %s %s", req.Method, req.URL.Redacted(), resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+// ErrNoCredentials 没有找到访问密钥
+var ErrNoCredentials = errors.New("no credentials")
@@ -0,0 +1,88 @@
+package storage
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestUpload(t *testing.T) {
+	var (
+		mu      sync.Mutex
+		objects = map[string]string{}
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut || !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=id/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		objects[r.URL.Path] = string(data)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.tar.gz")
+	if err := os.WriteFile(file, []byte("binary"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := Config{Type: S3, Bucket: "dist", Endpoint: srv.URL, AccessKeyID: "id", SecretAccessKey: "secret"}
+	b, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []File{{Name: "app.tar.gz", Path: file}, {Name: "CHANGELOG.md", Data: []byte("# 1.2.0")}}
+	uploaded, err := Upload(b, cfg, Release{Version: "1.2.0", Tag: "v1.2.0", Latest: true, Files: files})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uploaded) != 3 || uploaded[0].URL != srv.URL+"/dist/releases/1.2.0/app.tar.gz" {
+		t.Errorf("unexpected objects %+v", uploaded)
+	}
+	for _, key := range []string{"/dist/releases/1.2.0/app.tar.gz", "/dist/releases/1.2.0/CHANGELOG.md", "/dist/releases/latest/app.tar.gz", "/dist/releases/latest/CHANGELOG.md"} {
+		if _, ok := objects[key]; !ok {
+			t.Errorf("expected %s uploaded, but %v got", key, objects)
+		}
+	}
+	index := latestIndex{}
+	if err = json.Unmarshal([]byte(objects["/dist/releases/latest/latest.json"]), &index); err != nil || index.Version != "1.2.0" || len(index.Files) != 2 {
+		t.Errorf("unexpected latest.json %+v %v", index, err)
+	}
+
+	objects = map[string]string{}
+	cfg.Prefix = "{version}"
+	if uploaded, err = Upload(b, cfg, Release{Version: "1.3.0-beta.1", Files: files}); err != nil || len(uploaded) != 2 || len(objects) != 2 {
+		t.Errorf("expected prerelease not uploaded to latest, but %+v %v got", objects, err)
+	}
+	if _, ok := objects["/dist/1.3.0-beta.1/app.tar.gz"]; !ok {
+		t.Errorf("expected custom prefix, but %v got", objects)
+	}
+}
+
+func TestNew(t *testing.T) {
+	for _, env := range []string{"OSS_ACCESS_KEY_ID", "AWS_ACCESS_KEY_ID", "AWS_PROFILE"} {
+		t.Setenv(env, "")
+	}
+	t.Setenv("HOME", t.TempDir())
+	if _, err := New(Config{Type: "ftp", Bucket: "b"}); err == nil {
+		t.Error("expected unsupported type error")
+	}
+	if _, err := New(Config{Type: OSS, Bucket: "b", Region: "oss-cn-hangzhou"}); err == nil || !strings.Contains(err.Error(), ErrNoCredentials.Error()) {
+		t.Errorf("expected no credentials error, but %v got", err)
+	}
+	credentials := filepath.Join(t.TempDir(), "credentials")
+	_ = os.WriteFile(credentials, []byte("[default]\naws_access_key_id = a\n\n[ci]\naws_access_key_id = AKID\naws_secret_access_key = SECRET\n"), 0o600)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentials)
+	b, err := New(Config{Type: S3, Bucket: "b", Profile: "ci"})
+	if err != nil || b.(*s3).id != "AKID" || b.(*s3).secret != "SECRET" {
+		t.Errorf("expected profile ci credentials, but %+v %v got", b, err)
+	}
+}
@@ -0,0 +1,167 @@
+package summary
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+	"github.com/coffee377/autoctl/internal/httpclient"
+	"github.com/coffee377/autoctl/internal/runctx"
+	"github.com/coffee377/autoctl/internal/shell"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// DefaultTimeout 外部命令的默认执行超时时间
+const DefaultTimeout = 60 * time.Second
+
+// Config 变更摘要生成配置，对应配置文件 release.summary 节点，command 与 url 二选一
+type Config struct {
+	Enabled  bool              `mapstructure:"enabled"`  // 发布时生成变更摘要，插入到变更日志最前面
+	Command  string            `mapstructure:"command"`  // 外部命令，通过当前系统的 shell 执行，标准输入为 Request JSON，标准输出为摘要
+	URL      string            `mapstructure:"url"`      // HTTP 端点，POST Request JSON，响应为纯文本或 {"summary": "..."}
+	Headers  map[string]string `mapstructure:"headers"`  // 自定义请求头
+	TokenEnv string            `mapstructure:"tokenEnv"` // 从环境变量读取令牌，作为 Authorization: Bearer 请求头
+	Timeout  time.Duration     `mapstructure:"timeout"`  // 外部命令超时时间，默认 60s，HTTP 端点使用 http.timeout
+	Required bool              `mapstructure:"required"` // 生成失败时中止发布，默认仅输出警告
+	HTTP     httpclient.Config `mapstructure:"http"`     // 代理、TLS 及重试配置，未设置的字段继承全局 http 节点
+}
+
+// Request 发送给摘要生成器的内容
+type Request struct {
+	Version   string               `json:"version"`
+	Previous  string               `json:"previous,omitempty"`
+	Changelog *changelog.Changelog `json:"changelog"` // 结构化的提交列表
+	Markdown  string               `json:"markdown"`  // 渲染后的变更日志
+}
+
+// NewRequest 根据变更日志创建请求
+func NewRequest(cl *changelog.Changelog) *Request {
+	return &Request{Version: cl.Version, Previous: cl.Previous, Changelog: cl, Markdown: cl.Markdown()}
+}
+
+// Summarizer 将变更日志总结为面向用户的摘要
+type Summarizer interface {
+	Summarize(req *Request) (string, error)
+}
+
+// New 根据配置创建摘要生成器
+func New(cfg Config) (Summarizer, error) {
+	switch {
+	case cfg.Command != "" && cfg.URL != "":
+		return nil, errors.New("summary command and url are mutually exclusive")
+	case cfg.Command != "":
+		if cfg.Timeout <= 0 {
+			cfg.Timeout = DefaultTimeout
+		}
+		return &command{cfg: cfg}, nil
+	case cfg.URL != "":
+		client, err := httpclient.New(cfg.HTTP)
+		if err != nil {
+			return nil, err
+		}
+		return &endpoint{cfg: cfg, client: client}, nil
+	}
+	return nil, errors.New("summary command or url is required")
+}
+
+// command 通过外部命令生成摘要
+type command struct {
+	cfg Config
+}
+
+func (c *command) Summarize(req *Request) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	cmd := shell.Command(context.Background(), c.cfg.Command)
+	cmd.Stdin = bytes.NewReader(data)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	start := time.Now()
+	if err = cmd.Start(); err != nil {
+		log.TraceCommand(cmd, start, err)
+		return "", fmt.Errorf("summary command: %w", err)
+	}
+	// 命令启动的子进程可能继续占用输出，超时或中断后不再等待
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+	ctx := runctx.Context()
+	select {
+	case err = <-done:
+		log.TraceCommand(cmd, start, err)
+	case <-ctx.Done():
+		_ = cmd.Process.Kill()
+		err = runctx.Wrap(ctx, errors.New("summary command"))
+		log.TraceCommand(cmd, start, err)
+		return "", err
+	case <-time.After(c.cfg.Timeout):
+		_ = cmd.Process.Kill()
+		log.TraceCommand(cmd, start, fmt.Errorf("timed out after %s", c.cfg.Timeout))
+		return "", fmt.Errorf("summary command timed out after %s", c.cfg.Timeout)
+	}
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("summary command: %w: %s", err, msg)
+		}
+		return "", fmt.Errorf("summary command: %w", err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// endpoint 通过 HTTP 端点生成摘要
+type endpoint struct {
+	cfg    Config
+	client *http.Client
+}
+
+func (e *endpoint) Summarize(req *Request) (string, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return "", err
+	}
+	r, err := http.NewRequest(http.MethodPost, e.cfg.URL, bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Accept", "application/json, text/plain")
+	if e.cfg.TokenEnv != "" {
+		if token := os.Getenv(e.cfg.TokenEnv); token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	for k, v := range e.cfg.Headers {
+		r.Header.Set(k, v)
+	}
+	resp, err := e.client.Do(r)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return "", fmt.Errorf("POST %s: %d %s", e.cfg.URL, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		v := struct {
+			Summary string `json:"summary"`
+		}{}
+		if err = json.Unmarshal(body, &v); err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(v.Summary), nil
+	}
+	return strings.TrimSpace(string(body)), nil
+}
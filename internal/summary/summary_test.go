@@ -0,0 +1,74 @@
+package summary
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/changelog"
+)
+
+func request() *Request {
+	return NewRequest(&changelog.Changelog{Version: "1.3.0", Previous: "1.2.0", Sections: []changelog.Section{
+		{Type: "feat", Title: "Features", Commits: []changelog.Commit{{Hash: "1111111", Type: "feat", Subject: "add plan"}}},
+	}})
+}
+
+func TestCommand_Summarize(t *testing.T) {
+	s, err := New(Config{Command: `grep -q '"subject":"add plan"' && echo "  Plan your releases.  "`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actual, err := s.Summarize(request()); err != nil || actual != "Plan your releases." {
+		t.Errorf("expected summary from stdout, but %q %v got", actual, err)
+	}
+	s, _ = New(Config{Command: "echo quota exceeded >&2; exit 3"})
+	if _, err = s.Summarize(request()); err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("expected error with stderr, but %v got", err)
+	}
+	s, _ = New(Config{Command: "sleep 5", Timeout: 50 * time.Millisecond})
+	if _, err = s.Summarize(request()); err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected timeout error, but %v got", err)
+	}
+}
+
+func TestEndpoint_Summarize(t *testing.T) {
+	t.Setenv("SUMMARY_TOKEN", "secret")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		req := Request{}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Version != "1.3.0" || !strings.Contains(req.Markdown, "add plan") {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if r.URL.Path == "/text" {
+			_, _ = w.Write([]byte("Plan your releases.\n"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"summary": "Plan your releases."}`))
+	}))
+	defer server.Close()
+	for _, path := range []string{"/json", "/text"} {
+		s, err := New(Config{URL: server.URL + path, TokenEnv: "SUMMARY_TOKEN"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual, err := s.Summarize(request()); err != nil || actual != "Plan your releases." {
+			t.Errorf("%s: expected summary, but %q %v got", path, actual, err)
+		}
+	}
+	s, _ := New(Config{URL: server.URL})
+	if _, err := s.Summarize(request()); err == nil {
+		t.Error("expected unauthorized error, got nil")
+	}
+	if _, err := New(Config{URL: server.URL, Command: "cat"}); err == nil {
+		t.Error("expected mutually exclusive error, got nil")
+	}
+}
@@ -0,0 +1,109 @@
+package support
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// Policy 版本支持策略，对应配置文件 support 节点，如支持最近 2 个主版本中每个主版本的最近 3 个次版本
+type Policy struct {
+	Majors int `mapstructure:"majors" json:"majors"` // 支持的主版本数量，0 表示不限制
+	Minors int `mapstructure:"minors" json:"minors"` // 每个主版本支持的次版本数量，0 表示不限制
+}
+
+// Enabled 是否配置了支持策略
+func (p Policy) Enabled() bool {
+	return p.Majors > 0 || p.Minors > 0
+}
+
+func (p Policy) String() string {
+	parts := make([]string, 0, 2)
+	if p.Majors > 0 {
+		parts = append(parts, fmt.Sprintf("latest %d majors", p.Majors))
+	}
+	if p.Minors > 0 {
+		parts = append(parts, fmt.Sprintf("last %d minors of each", p.Minors))
+	}
+	if len(parts) == 0 {
+		return "all versions"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Line 同一次版本号的版本线，如 1.2.x
+type Line struct {
+	Major  uint64 `json:"major"`
+	Minor  uint64 `json:"minor"`
+	Latest string `json:"latest"` // 版本线内最新的正式版本
+}
+
+func (l Line) String() string {
+	return fmt.Sprintf("%d.%d.x", l.Major, l.Minor)
+}
+
+// Lines 将正式版本按次版本号分组，按版本从新到旧排列，忽略预发布版本
+func Lines(versions []semver.Semver) []Line {
+	sorted := make(semver.Versions, 0, len(versions))
+	for _, v := range versions {
+		if v != nil && len(v.PreRelease()) == 0 {
+			sorted = append(sorted, v)
+		}
+	}
+	sort.Stable(sort.Reverse(sorted))
+	var lines []Line
+	for _, v := range sorted {
+		if n := len(lines); n > 0 && lines[n-1].Major == v.Major() && lines[n-1].Minor == v.Minor() {
+			continue
+		}
+		lines = append(lines, Line{Major: v.Major(), Minor: v.Minor(), Latest: v.String()})
+	}
+	return lines
+}
+
+// Split 按支持策略将版本线划分为仍在支持及已停止支持的版本线
+func (p Policy) Split(lines []Line) (supported, eol []Line) {
+	majors, minors := 0, 0
+	for i, l := range lines {
+		if i == 0 || l.Major != lines[i-1].Major {
+			majors, minors = majors+1, 0
+		}
+		minors++
+		if p.Majors > 0 && majors > p.Majors || p.Minors > 0 && minors > p.Minors {
+			eol = append(eol, l)
+		} else {
+			supported = append(supported, l)
+		}
+	}
+	return supported, eol
+}
+
+// Dropped 发布 next 后停止支持的版本线，即发布前仍在支持而发布后不再支持的版本线
+func (p Policy) Dropped(versions []semver.Semver, next semver.Semver) []Line {
+	if !p.Enabled() || next == nil || len(next.PreRelease()) > 0 {
+		return nil
+	}
+	before, _ := p.Split(Lines(versions))
+	_, after := p.Split(Lines(append(append([]semver.Semver(nil), versions...), next)))
+	var dropped []Line
+	for _, l := range after {
+		for _, b := range before {
+			if b.Major == l.Major && b.Minor == l.Minor {
+				dropped = append(dropped, l)
+				break
+			}
+		}
+	}
+	return dropped
+}
+
+// Notes 停止支持的版本线说明，每条一行
+func Notes(lines []Line) []string {
+	notes := make([]string, 0, len(lines))
+	for _, l := range lines {
+		notes = append(notes, fmt.Sprintf("%s is no longer supported, the last release is %s", l, l.Latest))
+	}
+	return notes
+}
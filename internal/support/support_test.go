@@ -0,0 +1,52 @@
+package support
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+func versions(list ...string) []semver.Semver {
+	vs := make([]semver.Semver, 0, len(list))
+	for _, s := range list {
+		v, _ := semver.Version(s)
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+func names(lines []Line) []string {
+	list := make([]string, 0, len(lines))
+	for _, l := range lines {
+		list = append(list, l.String())
+	}
+	return list
+}
+
+func TestPolicy_Split(t *testing.T) {
+	lines := Lines(versions("1.0.0", "1.1.0", "1.1.1", "1.2.0", "2.0.0", "2.1.0-rc.1", "3.0.0", "3.1.0"))
+	if lines[4].Latest != "1.1.1" {
+		t.Errorf("expected 1.1.x latest 1.1.1, but %+v got", lines[4])
+	}
+	supported, eol := Policy{Majors: 2, Minors: 1}.Split(lines)
+	if s, e := names(supported), names(eol); !reflect.DeepEqual(s, []string{"3.1.x", "2.0.x"}) ||
+		!reflect.DeepEqual(e, []string{"3.0.x", "1.2.x", "1.1.x", "1.0.x"}) {
+		t.Errorf("unexpected supported %v and eol %v", s, e)
+	}
+}
+
+func TestPolicy_Dropped(t *testing.T) {
+	p := Policy{Minors: 3}
+	list := versions("1.0.0", "1.1.0", "1.2.0", "1.2.1")
+	next := versions("1.3.0")[0]
+	if dropped := names(p.Dropped(list, next)); !reflect.DeepEqual(dropped, []string{"1.0.x"}) {
+		t.Errorf("expected 1.0.x dropped, but %v got", dropped)
+	}
+	if dropped := p.Dropped(list, versions("1.2.2")[0]); len(dropped) != 0 {
+		t.Errorf("expected nothing dropped by a patch, but %v got", dropped)
+	}
+	if dropped := p.Dropped(list, versions("1.3.0-rc.1")[0]); len(dropped) != 0 {
+		t.Errorf("expected nothing dropped by a prerelease, but %v got", dropped)
+	}
+}
@@ -0,0 +1,117 @@
+package tags
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// Stable 正式版本的发布通道
+const Stable = "stable"
+
+// Tag 版本标签
+type Tag struct {
+	Name      string        `json:"name"`
+	Version   semver.Semver `json:"version"`
+	Channel   string        `json:"channel"` // 发布通道，正式版本为 stable，预发布版本为首个标识符，如 rc
+	Commit    string        `json:"commit"`
+	Date      time.Time     `json:"date"`
+	Annotated bool          `json:"annotated"`
+	Signed    bool          `json:"signed"`
+}
+
+// Prerelease 是否为预发布版本
+func (t Tag) Prerelease() bool {
+	return t.Channel != Stable
+}
+
+// Channel 版本的发布通道，预发布标识符以数字开头时为 prerelease
+func Channel(v semver.Semver) string {
+	pre := v.PreRelease()
+	if len(pre) == 0 {
+		return Stable
+	}
+	if pre[0].IsNumeric {
+		return "prerelease"
+	}
+	return strings.ToLower(pre[0].Raw)
+}
+
+// List 列出前缀为 prefix 的版本标签，按版本号从新到旧排列；无法解析为版本号的标签名称通过 invalid 返回
+func List(plus *git.Plus, prefix string) (list []Tag, invalid []string, err error) {
+	refs, err := plus.TagRefs(prefix + "*")
+	if err != nil {
+		return nil, nil, err
+	}
+	list = make([]Tag, 0, len(refs))
+	for _, ref := range refs {
+		v, err := semver.Version(strings.TrimPrefix(ref.Name, prefix))
+		if err != nil {
+			invalid = append(invalid, ref.Name)
+			continue
+		}
+		list = append(list, Tag{
+			Name: ref.Name, Version: v, Channel: Channel(v), Commit: ref.Commit,
+			Date: ref.Date, Annotated: ref.Annotated, Signed: ref.Signed,
+		})
+	}
+	sort.SliceStable(list, func(i, j int) bool { return list[i].Version.Compare(list[j].Version) > 0 })
+	return list, invalid, nil
+}
+
+// Filter 标签筛选条件，零值匹配所有标签
+type Filter struct {
+	Channels   []string      // 发布通道
+	Prerelease bool          // 仅预发布版本
+	Stable     bool          // 仅正式版本
+	OlderThan  time.Duration // 仅早于该时长之前创建的标签
+}
+
+// Match 标签是否满足筛选条件
+func (f Filter) Match(t Tag, now time.Time) bool {
+	if f.Prerelease && !t.Prerelease() || f.Stable && t.Prerelease() {
+		return false
+	}
+	if len(f.Channels) > 0 {
+		matched := false
+		for _, c := range f.Channels {
+			matched = matched || strings.EqualFold(c, t.Channel)
+		}
+		if !matched {
+			return false
+		}
+	}
+	return f.OlderThan <= 0 || now.Sub(t.Date) > f.OlderThan
+}
+
+// Select 筛选标签
+func (f Filter) Select(list []Tag, now time.Time) []Tag {
+	selected := make([]Tag, 0, len(list))
+	for _, t := range list {
+		if f.Match(t, now) {
+			selected = append(selected, t)
+		}
+	}
+	return selected
+}
+
+// ParseAge 解析时长，在 time.ParseDuration 基础上支持 d（天）及 w（周），如 90d
+func ParseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n := strings.TrimSuffix(s, suffix); n != s {
+			if days, err := strconv.ParseFloat(n, 64); err == nil {
+				return time.Duration(days * float64(unit)), nil
+			}
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid age %q, e.g. 90d, 2w, 36h", s)
+	}
+	return d, nil
+}
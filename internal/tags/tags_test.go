@@ -0,0 +1,94 @@
+package tags
+
+import (
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/testutil"
+	"github.com/coffee377/autoctl/pkg/git"
+)
+
+func newRepo(t *testing.T) string {
+	t.Helper()
+	dir := testutil.NewRepo(t, t.TempDir())
+	for _, args := range [][]string{
+		{"commit", "-q", "--allow-empty", "-m", "feat: init"},
+		{"tag", "v1.0.0"},
+		{"tag", "v1.1.0-rc.0"},
+		{"tag", "v1.1.0-beta.1"},
+		{"tag", "release-2023"},
+		{"tag", "v1.0.0+build.1"},
+	} {
+		testutil.GitRun(t, dir, args...)
+	}
+	t.Setenv("GIT_COMMITTER_DATE", "2023-01-01T12:00:00")
+	testutil.GitRun(t, dir, "tag", "-a", "v0.9.0-rc.1", "-m", "rc")
+	return dir
+}
+
+func names(list []Tag) []string {
+	n := make([]string, 0, len(list))
+	for _, t := range list {
+		n = append(n, t.Name)
+	}
+	return n
+}
+
+func TestList(t *testing.T) {
+	plus := &git.Plus{Cwd: newRepo(t)}
+	list, invalid, err := List(plus, "v")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"v1.1.0-rc.0", "v1.1.0-beta.1", "v1.0.0", "v1.0.0+build.1", "v0.9.0-rc.1"}
+	if actual := names(list); len(actual) != len(expected) || actual[0] != expected[0] || actual[1] != expected[1] || actual[4] != expected[4] {
+		t.Errorf("expected %v, but %v got", expected, actual)
+	}
+	if len(invalid) != 0 {
+		t.Errorf("expected tags without prefix not listed, but %v got", invalid)
+	}
+	if list[4].Channel != "rc" || !list[4].Annotated || list[4].Date.Year() != 2023 {
+		t.Errorf("unexpected tag %+v", list[4])
+	}
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	if actual := names(Filter{Channels: []string{"rc"}}.Select(list, now)); len(actual) != 2 {
+		t.Errorf("expected rc tags, but %v got", actual)
+	}
+	if actual := names(Filter{Stable: true}.Select(list, now)); len(actual) != 2 {
+		t.Errorf("expected stable tags, but %v got", actual)
+	}
+	age, _ := ParseAge("90d")
+	if actual := names(Filter{Prerelease: true, OlderThan: age}.Select(list, now)); len(actual) != 1 || actual[0] != "v0.9.0-rc.1" {
+		t.Errorf("expected prerelease older than 90d, but %v got", actual)
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	for s, expected := range map[string]time.Duration{"90d": 90 * 24 * time.Hour, "2w": 14 * 24 * time.Hour, "36h": 36 * time.Hour} {
+		if actual, err := ParseAge(s); err != nil || actual != expected {
+			t.Errorf("ParseAge(%q) expected %s, but %s %v got", s, expected, actual, err)
+		}
+	}
+	if _, err := ParseAge("soon"); err == nil {
+		t.Error("expected error, got nil")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	plus := &git.Plus{Cwd: newRepo(t)}
+	refs, err := plus.TagRefs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	problems := map[string]int{}
+	for _, ref := range refs {
+		problems[ref.Name] = len(Verify(plus, ref, "v", Rules{Annotated: true}).Problems)
+	}
+	expected := map[string]int{"v1.0.0": 1, "v1.1.0-rc.0": 1, "v1.1.0-beta.1": 1, "release-2023": 2, "v1.0.0+build.1": 2, "v0.9.0-rc.1": 0}
+	for name, n := range expected {
+		if problems[name] != n {
+			t.Errorf("expected %d problems for %s, but %d got", n, name, problems[name])
+		}
+	}
+}
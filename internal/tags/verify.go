@@ -0,0 +1,48 @@
+package tags
+
+import (
+	"strings"
+
+	"github.com/coffee377/autoctl/pkg/git"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+// Rules 标签校验规则
+type Rules struct {
+	Annotated bool // 必须为附注标签
+	Signed    bool // 必须签名，签名的标签始终校验签名
+}
+
+// Result 标签校验结果
+type Result struct {
+	Name     string   `json:"name"`
+	Problems []string `json:"problems,omitempty"`
+}
+
+// Verify 校验标签格式及签名
+func Verify(plus *git.Plus, ref git.TagRef, prefix string, rules Rules) Result {
+	res := Result{Name: ref.Name}
+	if !strings.HasPrefix(ref.Name, prefix) {
+		res.Problems = append(res.Problems, "tag must start with "+prefix)
+	} else if v, err := semver.Version(strings.TrimPrefix(ref.Name, prefix)); err != nil {
+		res.Problems = append(res.Problems, err.Error())
+	} else if len(v.Build()) > 0 {
+		res.Problems = append(res.Problems, "tag must not contain build metadata")
+	}
+	if rules.Annotated && !ref.Annotated {
+		res.Problems = append(res.Problems, "tag is not annotated")
+	}
+	if ref.Signed {
+		if err := plus.VerifyTag(ref.Name); err != nil {
+			res.Problems = append(res.Problems, "bad signature: "+lastLine(err.Error()))
+		}
+	} else if rules.Signed {
+		res.Problems = append(res.Problems, "tag is not signed")
+	}
+	return res
+}
+
+func lastLine(s string) string {
+	lines := strings.Split(strings.TrimSpace(s), "\n")
+	return strings.TrimSpace(lines[len(lines)-1])
+}
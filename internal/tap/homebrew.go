@@ -0,0 +1,135 @@
+package tap
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/coffee377/autoctl/internal/gitops"
+)
+
+const DefaultFormulaDir = "Formula"
+
+// Homebrew Homebrew 公式，对应配置文件 homebrew 节点
+type Homebrew struct {
+	Name        string        `mapstructure:"name"`        // 公式名称，默认为仓库名称
+	Description string        `mapstructure:"description"` // 描述
+	Homepage    string        `mapstructure:"homepage"`    // 主页
+	License     string        `mapstructure:"license"`     // 许可证，如 MIT
+	URL         string        `mapstructure:"url"`         // 下载地址模板，支持 {{ .Version }}、{{ .Tag }}、{{ .BuildNumber }}、{{ .Name }}，默认使用上传到版本发布的地址
+	Directory   string        `mapstructure:"directory"`   // 公式所在目录，默认 Formula
+	Install     string        `mapstructure:"install"`     // install 方法内容，默认 bin.install "<name>"
+	Test        string        `mapstructure:"test"`        // test 块内容，默认 system "#{bin}/<name>", "--version"
+	Tap         gitops.Target `mapstructure:"tap"`         // tap 仓库，files 不需要设置
+}
+
+// Enabled 是否配置了 tap 仓库
+func (h Homebrew) Enabled() bool {
+	return h.Tap.Repo != ""
+}
+
+// Path 公式在 tap 仓库中的路径
+func (h Homebrew) Path() string {
+	dir := h.Directory
+	if dir == "" {
+		dir = DefaultFormulaDir
+	}
+	return path.Join(dir, h.Name+".rb")
+}
+
+// ClassName 公式的类名，如 auto-ctl 为 AutoCtl
+func ClassName(name string) string {
+	var sb strings.Builder
+	upper := true
+	for _, r := range strings.ReplaceAll(name, "@", "AT") {
+		if r == '-' || r == '_' || r == '.' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// Formula 生成 macOS 及 Linux 安装包的公式
+func (h Homebrew) Formula(data Data) ([]byte, error) {
+	pkgs, err := packages(data, h.URL)
+	if err != nil {
+		return nil, err
+	}
+	var sb strings.Builder
+	sb.WriteString("# typed: false\n# frozen_string_literal: true\n\n")
+	sb.WriteString("# This file was generated by autoctl. DO NOT EDIT.\n")
+	sb.WriteString(fmt.Sprintf("class %s < Formula\n", ClassName(h.Name)))
+	if h.Description != "" {
+		sb.WriteString(fmt.Sprintf("  desc %q\n", h.Description))
+	}
+	if h.Homepage != "" {
+		sb.WriteString(fmt.Sprintf("  homepage %q\n", h.Homepage))
+	}
+	sb.WriteString(fmt.Sprintf("  version %q\n", data.Version))
+	if h.License != "" {
+		sb.WriteString(fmt.Sprintf("  license %q\n", h.License))
+	}
+	found := false
+	for _, os := range [][2]string{{"darwin", "on_macos"}, {"linux", "on_linux"}} {
+		var block strings.Builder
+		for _, arch := range [][2]string{{"amd64", "Hardware::CPU.intel?"}, {"arm64", "Hardware::CPU.arm?"}} {
+			pkg, ok := pkgs[Platform{OS: os[0], Arch: arch[0]}]
+			if !ok {
+				continue
+			}
+			block.WriteString(fmt.Sprintf("    if %s\n      url %q\n      sha256 %q\n    end\n", arch[1], pkg.URL, pkg.SHA256))
+		}
+		if block.Len() > 0 {
+			found = true
+			sb.WriteString(fmt.Sprintf("\n  %s do\n%s  end\n", os[1], block.String()))
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("homebrew %s: no darwin or linux archive for amd64 or arm64", h.Name)
+	}
+	install := h.Install
+	if install == "" {
+		install = fmt.Sprintf("bin.install %q", h.Name)
+	}
+	test := h.Test
+	if test == "" {
+		test = fmt.Sprintf("system \"#{bin}/%s\", \"--version\"", h.Name)
+	}
+	sb.WriteString(fmt.Sprintf("\n  def install\n%s  end\n", indent(install, "    ")))
+	sb.WriteString(fmt.Sprintf("\n  test do\n%s  end\nend\n", indent(test, "    ")))
+	return []byte(sb.String()), nil
+}
+
+func indent(text, prefix string) string {
+	var sb strings.Builder
+	for _, line := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		sb.WriteString(prefix + line + "\n")
+	}
+	return sb.String()
+}
+
+// Update 生成公式并提交到 tap 仓库
+func (h Homebrew) Update(data Data, commit gitops.Data) (*gitops.Result, error) {
+	formula, err := h.Formula(data)
+	if err != nil {
+		return nil, err
+	}
+	target := h.Tap
+	if target.Name == "" {
+		target.Name = h.Name
+	}
+	if target.Branch == "" {
+		target.Branch = "autoctl/homebrew-{{ .Name }}-{{ .Tag }}"
+	}
+	if target.Message == "" {
+		target.Message = "chore(homebrew): update {{ .Name }} to {{ .Version }}"
+	}
+	return gitops.WriteFiles(target, commit, map[string][]byte{h.Path(): formula})
+}
@@ -0,0 +1,96 @@
+package tap
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+
+	"github.com/coffee377/autoctl/internal/gitops"
+)
+
+const DefaultBucketDir = "bucket"
+
+// Scoop Scoop 清单，对应配置文件 scoop 节点
+type Scoop struct {
+	Name        string        `mapstructure:"name"`        // 清单名称，默认为仓库名称
+	Description string        `mapstructure:"description"` // 描述
+	Homepage    string        `mapstructure:"homepage"`    // 主页
+	License     string        `mapstructure:"license"`     // 许可证，如 MIT
+	URL         string        `mapstructure:"url"`         // 下载地址模板，支持 {{ .Version }}、{{ .Tag }}、{{ .BuildNumber }}、{{ .Name }}，默认使用上传到版本发布的地址
+	Directory   string        `mapstructure:"directory"`   // 清单所在目录，默认 bucket
+	Bin         []string      `mapstructure:"bin"`         // 可执行文件，默认 <name>.exe
+	Bucket      gitops.Target `mapstructure:"bucket"`      // bucket 仓库，files 不需要设置
+}
+
+// Enabled 是否配置了 bucket 仓库
+func (s Scoop) Enabled() bool {
+	return s.Bucket.Repo != ""
+}
+
+// Path 清单在 bucket 仓库中的路径
+func (s Scoop) Path() string {
+	dir := s.Directory
+	if dir == "" {
+		dir = DefaultBucketDir
+	}
+	return path.Join(dir, s.Name+".json")
+}
+
+type scoopArch struct {
+	URL  string `json:"url"`
+	Hash string `json:"hash"`
+}
+
+// Manifest 生成 Windows 安装包的清单
+// https://github.com/ScoopInstaller/Scoop/wiki/App-Manifests
+func (s Scoop) Manifest(data Data) ([]byte, error) {
+	pkgs, err := packages(data, s.URL)
+	if err != nil {
+		return nil, err
+	}
+	archs := map[string]scoopArch{}
+	for arch, key := range map[string]string{"amd64": "64bit", "386": "32bit", "arm64": "arm64"} {
+		if pkg, ok := pkgs[Platform{OS: "windows", Arch: arch}]; ok {
+			archs[key] = scoopArch{URL: pkg.URL, Hash: pkg.SHA256}
+		}
+	}
+	if len(archs) == 0 {
+		return nil, fmt.Errorf("scoop %s: no windows archive", s.Name)
+	}
+	bin := s.Bin
+	if len(bin) == 0 {
+		bin = []string{s.Name + ".exe"}
+	}
+	manifest := struct {
+		Version      string               `json:"version"`
+		Description  string               `json:"description,omitempty"`
+		Homepage     string               `json:"homepage,omitempty"`
+		License      string               `json:"license,omitempty"`
+		Architecture map[string]scoopArch `json:"architecture"`
+		Bin          []string             `json:"bin"`
+	}{data.Version, s.Description, s.Homepage, s.License, archs, bin}
+	out, err := json.MarshalIndent(manifest, "", "    ")
+	if err != nil {
+		return nil, err
+	}
+	return append(out, '\n'), nil
+}
+
+// Update 生成清单并提交到 bucket 仓库
+func (s Scoop) Update(data Data, commit gitops.Data) (*gitops.Result, error) {
+	manifest, err := s.Manifest(data)
+	if err != nil {
+		return nil, err
+	}
+	target := s.Bucket
+	if target.Name == "" {
+		target.Name = s.Name
+	}
+	if target.Branch == "" {
+		target.Branch = "autoctl/scoop-{{ .Name }}-{{ .Tag }}"
+	}
+	if target.Message == "" {
+		target.Message = "chore(scoop): update {{ .Name }} to {{ .Version }}"
+	}
+	return gitops.WriteFiles(target, commit, map[string][]byte{s.Path(): manifest})
+}
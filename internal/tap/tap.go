@@ -0,0 +1,108 @@
+package tap
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/artifact"
+	"github.com/coffee377/autoctl/internal/tmpl"
+)
+
+// Platform 发布产物对应的操作系统及架构
+type Platform struct {
+	OS   string // darwin | linux | windows
+	Arch string // amd64 | arm64 | 386 | arm
+}
+
+var (
+	osPatterns = map[string]*regexp.Regexp{
+		"darwin":  regexp.MustCompile(`(?i)darwin|macos|osx`),
+		"linux":   regexp.MustCompile(`(?i)linux`),
+		"windows": regexp.MustCompile(`(?i)windows|win64|win32`),
+	}
+	archPatterns = []struct {
+		arch    string
+		pattern *regexp.Regexp
+	}{
+		{"arm64", regexp.MustCompile(`(?i)arm64|aarch64`)},
+		{"amd64", regexp.MustCompile(`(?i)amd64|x86_64|x64|win64`)},
+		{"arm", regexp.MustCompile(`(?i)armv[67]|arm`)},
+		{"386", regexp.MustCompile(`(?i)386|i686|x86|win32`)},
+	}
+	// archives 可作为安装包的压缩文件
+	archives = []string{".tar.gz", ".tgz", ".tar.xz", ".zip"}
+)
+
+// Detect 按文件名识别产物的平台，无法识别或不是压缩文件时返回 false
+func Detect(name string) (Platform, bool) {
+	name = filepath.Base(name)
+	archive := false
+	for _, ext := range archives {
+		archive = archive || strings.HasSuffix(strings.ToLower(name), ext)
+	}
+	if !archive {
+		return Platform{}, false
+	}
+	p := Platform{}
+	for os, pattern := range osPatterns {
+		if pattern.MatchString(name) {
+			p.OS = os
+		}
+	}
+	for _, a := range archPatterns {
+		if a.pattern.MatchString(name) {
+			p.Arch = a.arch
+			break
+		}
+	}
+	return p, p.OS != "" && p.Arch != ""
+}
+
+// Package 某个平台的安装包
+type Package struct {
+	Platform
+	Name   string
+	URL    string
+	SHA256 string
+}
+
+// Data 生成安装清单的数据
+type Data struct {
+	Version     string
+	Tag         string
+	BuildNumber int64               // 单调递增的构建号，未启用时为 0
+	Assets      []artifact.Artifact // 已上传的发布产物，URL 为下载地址
+}
+
+// packages 识别各平台的安装包，URL 模板不为空时按模板生成下载地址，否则使用产物上传后的地址
+func packages(data Data, urlTemplate string) (map[Platform]Package, error) {
+	pkgs := map[Platform]Package{}
+	for _, a := range data.Assets {
+		p, ok := Detect(a.Name)
+		if !ok {
+			continue
+		}
+		if _, exists := pkgs[p]; exists {
+			return nil, fmt.Errorf("more than one %s/%s archive: %s and %s", p.OS, p.Arch, pkgs[p].Name, a.Name)
+		}
+		u := a.URL
+		if urlTemplate != "" {
+			var err error
+			name := filepath.Base(a.Name)
+			if u, err = render(urlTemplate, map[string]interface{}{"Version": data.Version, "Tag": data.Tag, "BuildNumber": data.BuildNumber, "Name": name}); err != nil {
+				return nil, err
+			}
+		}
+		if u == "" {
+			return nil, fmt.Errorf("%s has no download url, publish the release or set url", a.Name)
+		}
+		pkgs[p] = Package{Platform: p, Name: filepath.Base(a.Name), URL: u, SHA256: a.SHA256}
+	}
+	return pkgs, nil
+}
+
+func render(text string, data interface{}) (string, error) {
+	return tmpl.Render("tap", text, data)
+}
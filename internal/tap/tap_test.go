@@ -0,0 +1,79 @@
+package tap
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/coffee377/autoctl/internal/artifact"
+)
+
+var assets = []artifact.Artifact{
+	{Name: "autoctl_1.2.0_darwin_arm64.tar.gz", SHA256: "d-arm", URL: "https://dl/darwin_arm64"},
+	{Name: "autoctl_1.2.0_darwin_x86_64.tar.gz", SHA256: "d-amd", URL: "https://dl/darwin_amd64"},
+	{Name: "autoctl_1.2.0_linux_amd64.tar.gz", SHA256: "l-amd", URL: "https://dl/linux_amd64"},
+	{Name: "autoctl_1.2.0_windows_amd64.zip", SHA256: "w-amd", URL: "https://dl/windows_amd64"},
+	{Name: "checksums.txt", SHA256: "c"},
+}
+
+func TestDetect(t *testing.T) {
+	tests := map[string]Platform{
+		"app-macos-aarch64.tar.gz": {OS: "darwin", Arch: "arm64"},
+		"app_Linux_x86_64.tgz":     {OS: "linux", Arch: "amd64"},
+		"app-windows-386.zip":      {OS: "windows", Arch: "386"},
+		"app-linux-armv7.tar.xz":   {OS: "linux", Arch: "arm"},
+	}
+	for name, expected := range tests {
+		if p, ok := Detect(name); !ok || p != expected {
+			t.Errorf("Detect(%s) expected %+v, but %+v got", name, expected, p)
+		}
+	}
+	if _, ok := Detect("app_linux_amd64.deb"); ok {
+		t.Error("expected packages other than archives ignored")
+	}
+}
+
+func TestHomebrew_Formula(t *testing.T) {
+	h := Homebrew{Name: "autoctl", Description: "Automation cli", License: "MIT"}
+	data, err := h.Formula(Data{Version: "1.2.0", Tag: "v1.2.0", Assets: assets})
+	if err != nil {
+		t.Fatal(err)
+	}
+	formula := string(data)
+	for _, s := range []string{
+		"class Autoctl < Formula",
+		`version "1.2.0"`,
+		"  on_macos do\n    if Hardware::CPU.intel?\n      url \"https://dl/darwin_amd64\"\n      sha256 \"d-amd\"\n    end\n    if Hardware::CPU.arm?",
+		"  on_linux do\n    if Hardware::CPU.intel?\n      url \"https://dl/linux_amd64\"",
+		`    bin.install "autoctl"`,
+	} {
+		if !strings.Contains(formula, s) {
+			t.Errorf("expected formula contains %q, but\n%s got", s, formula)
+		}
+	}
+	if ClassName("auto-ctl@2") != "AutoCtlAT2" {
+		t.Errorf("unexpected class name %s", ClassName("auto-ctl@2"))
+	}
+}
+
+func TestScoop_Manifest(t *testing.T) {
+	s := Scoop{Name: "autoctl", URL: "https://mirror/{{ .Tag }}/{{ .Name }}"}
+	data, err := s.Manifest(Data{Version: "1.2.0", Tag: "v1.2.0", Assets: assets})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := struct {
+		Version      string
+		Architecture map[string]scoopArch
+		Bin          []string
+	}{}
+	if err = json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m.Version != "1.2.0" || m.Architecture["64bit"] != (scoopArch{URL: "https://mirror/v1.2.0/autoctl_1.2.0_windows_amd64.zip", Hash: "w-amd"}) || m.Bin[0] != "autoctl.exe" {
+		t.Errorf("unexpected manifest %s", data)
+	}
+	if _, err = s.Manifest(Data{Version: "1.2.0", Assets: assets[:3]}); err == nil {
+		t.Error("expected no windows archive error")
+	}
+}
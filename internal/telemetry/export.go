@@ -0,0 +1,311 @@
+package telemetry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/httpclient"
+	"github.com/coffee377/autoctl/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+// OTLP 协议中的枚举值
+const (
+	spanKindInternal  = 1
+	statusOK          = 1
+	statusError       = 2
+	temporalityDelta  = 1
+	scopeName         = "github.com/coffee377/autoctl"
+	statusSuccess     = "success"
+	statusFailed      = "failed"
+	statusInterrupted = "interrupted"
+)
+
+// durationBounds 耗时直方图的分桶上限，单位秒
+var durationBounds = []float64{1, 5, 10, 30, 60, 120, 300, 600, 1800, 3600}
+
+type keyValue struct {
+	Key   string   `json:"key"`
+	Value anyValue `json:"value"`
+}
+
+type anyValue struct {
+	StringValue string `json:"stringValue"`
+}
+
+type resource struct {
+	Attributes []keyValue `json:"attributes"`
+}
+
+type scope struct {
+	Name string `json:"name"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string     `json:"traceId"`
+	SpanID            string     `json:"spanId"`
+	ParentSpanID      string     `json:"parentSpanId,omitempty"`
+	Name              string     `json:"name"`
+	Kind              int        `json:"kind"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string     `json:"endTimeUnixNano"`
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	Status            otlpStatus `json:"status"`
+}
+
+type scopeSpans struct {
+	Scope scope      `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type resourceSpans struct {
+	Resource   resource     `json:"resource"`
+	ScopeSpans []scopeSpans `json:"scopeSpans"`
+}
+
+// traces /v1/traces 的请求体
+type traces struct {
+	ResourceSpans []resourceSpans `json:"resourceSpans"`
+}
+
+type dataPoint struct {
+	Attributes        []keyValue `json:"attributes,omitempty"`
+	StartTimeUnixNano string     `json:"startTimeUnixNano"`
+	TimeUnixNano      string     `json:"timeUnixNano"`
+	// 直方图
+	Count          string    `json:"count,omitempty"`
+	Sum            *float64  `json:"sum,omitempty"`
+	BucketCounts   []string  `json:"bucketCounts,omitempty"`
+	ExplicitBounds []float64 `json:"explicitBounds,omitempty"`
+	// 计数
+	AsInt string `json:"asInt,omitempty"`
+}
+
+type aggregation struct {
+	AggregationTemporality int         `json:"aggregationTemporality"`
+	IsMonotonic            bool        `json:"isMonotonic,omitempty"`
+	DataPoints             []dataPoint `json:"dataPoints"`
+}
+
+type metric struct {
+	Name        string       `json:"name"`
+	Description string       `json:"description,omitempty"`
+	Unit        string       `json:"unit,omitempty"`
+	Histogram   *aggregation `json:"histogram,omitempty"`
+	Sum         *aggregation `json:"sum,omitempty"`
+}
+
+type scopeMetrics struct {
+	Scope   scope    `json:"scope"`
+	Metrics []metric `json:"metrics"`
+}
+
+type resourceMetrics struct {
+	Resource     resource       `json:"resource"`
+	ScopeMetrics []scopeMetrics `json:"scopeMetrics"`
+}
+
+// metrics /v1/metrics 的请求体
+type metrics struct {
+	ResourceMetrics []resourceMetrics `json:"resourceMetrics"`
+}
+
+// Finish 结束根跨度并导出链路及指标，未开启时不做任何事；导出失败不影响命令的结果，由调用方记录警告
+func Finish(cmd *cobra.Command, err error, interrupted bool) error {
+	mu.Lock()
+	c := cfg
+	if c == nil {
+		mu.Unlock()
+		return nil
+	}
+	r := root
+	r.end = time.Now()
+	if cmd != nil {
+		r.name = cmd.CommandPath()
+		r.attributes = map[string]string{"autoctl.command": strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")}
+	}
+	status := statusSuccess
+	if err != nil {
+		status = statusFailed
+		if interrupted {
+			status = statusInterrupted
+		}
+		r.err = log.Redact(err.Error())
+	}
+	all := append([]*Span{r}, spans...)
+	mu.Unlock()
+
+	res := resource{Attributes: []keyValue{attr("service.name", c.ServiceName)}}
+	for k, v := range c.Attributes {
+		res.Attributes = append(res.Attributes, attr(k, v))
+	}
+	t := encodeTraces(res, all)
+	m := encodeMetrics(res, r, status, all)
+	client, err := httpclient.New(c.HTTP)
+	if err != nil {
+		return fmt.Errorf("export telemetry: %w", err)
+	}
+	if err = post(client, c, "/v1/traces", t); err != nil {
+		return err
+	}
+	return post(client, c, "/v1/metrics", m)
+}
+
+func encodeTraces(res resource, all []*Span) *traces {
+	traceID, parentID := traceParent()
+	if traceID == "" {
+		traceID = newID(16)
+	}
+	all[0].id = newID(8)
+	ss := scopeSpans{Scope: scope{Name: scopeName}}
+	for _, s := range all {
+		o := otlpSpan{
+			TraceID:           traceID,
+			SpanID:            s.id,
+			ParentSpanID:      parentID,
+			Name:              s.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: nanos(s.start),
+			EndTimeUnixNano:   nanos(s.end),
+			Status:            otlpStatus{Code: statusOK},
+		}
+		if s.parent != nil {
+			o.ParentSpanID = s.parent.id
+		}
+		for k, v := range s.attributes {
+			o.Attributes = append(o.Attributes, attr(k, v))
+		}
+		if s.err != "" {
+			o.Status = otlpStatus{Code: statusError, Message: s.err}
+		}
+		ss.Spans = append(ss.Spans, o)
+	}
+	return &traces{ResourceSpans: []resourceSpans{{Resource: res, ScopeSpans: []scopeSpans{ss}}}}
+}
+
+func encodeMetrics(res resource, r *Span, status string, all []*Span) *metrics {
+	command := r.attributes["autoctl.command"]
+	ms := []metric{{
+		Name:        "autoctl.command.duration",
+		Description: "Duration of autoctl commands",
+		Unit:        "s",
+		Histogram: &aggregation{AggregationTemporality: temporalityDelta, DataPoints: []dataPoint{
+			histogram(r.start, r.end, map[string]string{"autoctl.command": command, "status": status}),
+		}},
+	}}
+	var durations, releases, failures []dataPoint
+	for _, s := range all {
+		if !s.release {
+			continue
+		}
+		status, failed := statusSuccess, 0
+		if s.err != "" {
+			status, failed = statusFailed, 1
+		}
+		attrs := map[string]string{"status": status, "autoctl.release.dry_run": s.attributes["autoctl.release.dry_run"]}
+		durations = append(durations, histogram(s.start, s.end, attrs))
+		releases = append(releases, count(r.start, s.end, attrs, 1))
+		failures = append(failures, count(r.start, s.end, nil, failed))
+	}
+	if len(durations) > 0 {
+		ms = append(ms, metric{
+			Name:        "autoctl.release.duration",
+			Description: "Duration of releases, from computing the version to the last step",
+			Unit:        "s",
+			Histogram:   &aggregation{AggregationTemporality: temporalityDelta, DataPoints: durations},
+		}, metric{
+			Name:        "autoctl.releases",
+			Description: "Number of releases by status",
+			Unit:        "{release}",
+			Sum:         &aggregation{AggregationTemporality: temporalityDelta, IsMonotonic: true, DataPoints: releases},
+		}, metric{
+			Name:        "autoctl.release.failures",
+			Description: "Number of failed releases",
+			Unit:        "{release}",
+			Sum:         &aggregation{AggregationTemporality: temporalityDelta, IsMonotonic: true, DataPoints: failures},
+		})
+	}
+	return &metrics{ResourceMetrics: []resourceMetrics{{Resource: res, ScopeMetrics: []scopeMetrics{{Scope: scope{Name: scopeName}, Metrics: ms}}}}}
+}
+
+// histogram 单次观测的耗时直方图
+func histogram(start, end time.Time, attrs map[string]string) dataPoint {
+	seconds := end.Sub(start).Seconds()
+	buckets := make([]string, len(durationBounds)+1)
+	i := 0
+	for i < len(durationBounds) && seconds > durationBounds[i] {
+		i++
+	}
+	for j := range buckets {
+		buckets[j] = "0"
+	}
+	buckets[i] = "1"
+	return dataPoint{
+		Attributes:        attrList(attrs),
+		StartTimeUnixNano: nanos(start),
+		TimeUnixNano:      nanos(end),
+		Count:             "1",
+		Sum:               &seconds,
+		BucketCounts:      buckets,
+		ExplicitBounds:    durationBounds,
+	}
+}
+
+// count 增量计数
+func count(start, end time.Time, attrs map[string]string, n int) dataPoint {
+	return dataPoint{Attributes: attrList(attrs), StartTimeUnixNano: nanos(start), TimeUnixNano: nanos(end), AsInt: strconv.Itoa(n)}
+}
+
+func attr(k, v string) keyValue {
+	return keyValue{Key: k, Value: anyValue{StringValue: v}}
+}
+
+func attrList(attrs map[string]string) []keyValue {
+	var list []keyValue
+	for k, v := range attrs {
+		list = append(list, attr(k, v))
+	}
+	return list
+}
+
+func nanos(t time.Time) string {
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// post 以 JSON 编码发送到 Collector，非 2xx 响应视为失败
+func post(client *http.Client, c *Config, path string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, c.Endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range c.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "autoctl")
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("export telemetry: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("export telemetry %s: %d %s", path, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+// Package telemetry 以 OpenTelemetry 协议导出单次执行的链路及指标：命令为根跨度，发布及其各步骤为子跨度，
+// 同时导出命令、发布的耗时及发布失败次数，命令结束后通过 OTLP/HTTP（JSON 编码）发送到 Collector，便于平台团队观测大量流水线
+package telemetry
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/httpclient"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+const (
+	// DefaultEndpoint 只开启而未设置地址时使用 Collector 的默认地址
+	DefaultEndpoint = "http://localhost:4318"
+	// DefaultServiceName 默认的服务名称
+	DefaultServiceName = "autoctl"
+	// DefaultTimeout 默认的导出超时时间，Collector 不可用时不拖慢流水线
+	DefaultTimeout = 10 * time.Second
+)
+
+// Config 遥测配置，对应配置文件 telemetry 节点，未设置的字段读取 OTEL_* 标准环境变量
+type Config struct {
+	Enabled     bool              `mapstructure:"enabled"`     // 开启导出，设置了 endpoint 或 OTEL_EXPORTER_OTLP_ENDPOINT 环境变量时自动开启
+	Endpoint    string            `mapstructure:"endpoint"`    // OTLP/HTTP 地址，如 http://otel-collector:4318，链路及指标分别发送到 /v1/traces、/v1/metrics
+	Headers     map[string]string `mapstructure:"headers"`     // 请求头，如认证令牌，默认读取 OTEL_EXPORTER_OTLP_HEADERS
+	ServiceName string            `mapstructure:"serviceName"` // 资源的 service.name，默认读取 OTEL_SERVICE_NAME，均未设置时为 autoctl
+	Attributes  map[string]string `mapstructure:"attributes"`  // 附加的资源属性，如 team、environment，与 OTEL_RESOURCE_ATTRIBUTES 合并
+	HTTP        httpclient.Config `mapstructure:"http"`        // 代理及 TLS 配置，未设置的字段继承全局 http 节点，超时时间默认 10s
+}
+
+// resolve 使用 OTEL_* 环境变量补全未设置的字段，返回是否需要导出
+func (c *Config) resolve() bool {
+	if c.Endpoint == "" {
+		c.Endpoint = os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT")
+	}
+	if c.Endpoint == "" {
+		if !c.Enabled {
+			return false
+		}
+		c.Endpoint = DefaultEndpoint
+	}
+	c.Endpoint = strings.TrimSuffix(c.Endpoint, "/")
+	if c.ServiceName == "" {
+		c.ServiceName = os.Getenv("OTEL_SERVICE_NAME")
+	}
+	if c.ServiceName == "" {
+		c.ServiceName = DefaultServiceName
+	}
+	if len(c.Headers) == 0 {
+		c.Headers = parsePairs(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	}
+	attributes := parsePairs(os.Getenv("OTEL_RESOURCE_ATTRIBUTES"))
+	for k, v := range c.Attributes {
+		attributes[k] = v
+	}
+	c.Attributes = attributes
+	if c.HTTP.Timeout == 0 {
+		c.HTTP.Timeout = DefaultTimeout
+	}
+	return true
+}
+
+// parsePairs 解析 k1=v1,k2=v2 形式的环境变量
+func parsePairs(s string) map[string]string {
+	pairs := map[string]string{}
+	for _, item := range strings.Split(s, ",") {
+		if k, v, ok := strings.Cut(item, "="); ok && strings.TrimSpace(k) != "" {
+			pairs[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return pairs
+}
+
+// Span 跨度，未开启导出时为 nil，其方法均可安全调用
+type Span struct {
+	id         string
+	parent     *Span
+	name       string
+	start, end time.Time
+	attributes map[string]string
+	err        string
+	release    bool // 发布跨度，据此导出发布耗时及失败次数
+}
+
+var (
+	mu      sync.Mutex
+	cfg     *Config // 为空时不导出
+	root    = &Span{name: "autoctl", start: time.Now()}
+	current = root // 新跨度的上级
+	spans   []*Span
+)
+
+// Setup 按配置开启导出，未设置地址且未开启时不做任何事
+func Setup(c Config) {
+	if !c.resolve() {
+		return
+	}
+	for _, v := range c.Headers {
+		log.AddSecret(v)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	cfg = &c
+}
+
+// Enabled 是否导出
+func Enabled() bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return cfg != nil
+}
+
+// Start 开始跨度，之后开始或记录的跨度均为其子跨度，直到调用 End
+func Start(name string, attrs map[string]string) *Span {
+	mu.Lock()
+	defer mu.Unlock()
+	if cfg == nil {
+		return nil
+	}
+	s := &Span{id: newID(8), parent: current, name: name, start: time.Now(), attributes: attrs}
+	current = s
+	return s
+}
+
+// StartRelease 开始发布跨度，结束时计入发布耗时及失败次数
+func StartRelease(version string, dryRun bool) *Span {
+	s := Start("release", map[string]string{"autoctl.release.version": version, "autoctl.release.dry_run": boolString(dryRun)})
+	if s != nil {
+		s.release = true
+	}
+	return s
+}
+
+// End 结束跨度，err 不为空时标记为失败
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	s.end = time.Now()
+	if err != nil {
+		s.err = log.Redact(err.Error())
+	}
+	spans = append(spans, s)
+	if current == s {
+		current = s.parent
+	}
+}
+
+// Record 记录已结束的步骤，如发布的 tagged、pushed
+func Record(name string, start, end time.Time) {
+	mu.Lock()
+	defer mu.Unlock()
+	if cfg == nil {
+		return
+	}
+	spans = append(spans, &Span{id: newID(8), parent: current, name: name, start: start, end: end})
+}
+
+func boolString(b bool) string {
+	if b {
+		return "true"
+	}
+	return "false"
+}
+
+// newID 随机的链路或跨度标识，十六进制编码
+func newID(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// traceParent 从 TRACEPARENT 环境变量读取上级链路，如 CI 系统或 otel-cli 创建的流水线链路
+func traceParent() (traceID, spanID string) {
+	parts := strings.Split(os.Getenv("TRACEPARENT"), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", ""
+	}
+	if _, err := hex.DecodeString(parts[1] + parts[2]); err != nil {
+		return "", ""
+	}
+	return parts[1], parts[2]
+}
@@ -0,0 +1,102 @@
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+func TestFinish(t *testing.T) {
+	t.Setenv("TRACEPARENT", "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	t.Setenv("OTEL_RESOURCE_ATTRIBUTES", "team=platform")
+	var (
+		mu       sync.Mutex
+		received = map[string][]byte{}
+		auth     string
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		received[r.URL.Path], _ = io.ReadAll(r.Body)
+		auth = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+	Setup(Config{Endpoint: server.URL + "/", Headers: map[string]string{"Authorization": "Bearer token"}})
+	defer func() { cfg, spans, current = nil, nil, root }()
+
+	release := StartRelease("1.2.0", false)
+	now := time.Now()
+	Record("tagged", now, now.Add(time.Second))
+	release.End(errors.New("push rejected"))
+	Record("outside", now, now)
+
+	autoctl := &cobra.Command{Use: "autoctl"}
+	autoctl.AddCommand(&cobra.Command{Use: "release", Run: func(*cobra.Command, []string) {}})
+	autoctl.SetArgs([]string{"release"})
+	executed, _ := autoctl.ExecuteC()
+	if err := Finish(executed, errors.New("push rejected"), false); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer token" {
+		t.Errorf("expected configured headers, but %q got", auth)
+	}
+
+	tr := traces{}
+	if err := json.Unmarshal(received["/v1/traces"], &tr); err != nil {
+		t.Fatal(err)
+	}
+	if attrs := tr.ResourceSpans[0].Resource.Attributes; len(attrs) != 2 {
+		t.Errorf("expected service.name and team resource attributes, but %v got", attrs)
+	}
+	parents := map[string]string{}
+	ids := map[string]string{}
+	for _, s := range tr.ResourceSpans[0].ScopeSpans[0].Spans {
+		if s.TraceID != "0af7651916cd43dd8448eb211c80319c" {
+			t.Errorf("expected trace id from TRACEPARENT, but %s got", s.TraceID)
+		}
+		parents[s.Name], ids[s.Name] = s.ParentSpanID, s.SpanID
+	}
+	if parents["autoctl release"] != "b7ad6b7169203331" || parents["release"] != ids["autoctl release"] ||
+		parents["tagged"] != ids["release"] || parents["outside"] != ids["autoctl release"] {
+		t.Errorf("unexpected span hierarchy %v of %v", parents, ids)
+	}
+
+	m := metrics{}
+	if err := json.Unmarshal(received["/v1/metrics"], &m); err != nil {
+		t.Fatal(err)
+	}
+	values := map[string]*aggregation{}
+	for _, metric := range m.ResourceMetrics[0].ScopeMetrics[0].Metrics {
+		values[metric.Name] = metric.Histogram
+		if metric.Sum != nil {
+			values[metric.Name] = metric.Sum
+		}
+	}
+	if len(values) != 4 || values["autoctl.release.failures"].DataPoints[0].AsInt != "1" ||
+		values["autoctl.release.duration"].DataPoints[0].Count != "1" {
+		t.Errorf("unexpected metrics %s", received["/v1/metrics"])
+	}
+}
+
+func TestStart_disabled(t *testing.T) {
+	t.Setenv("OTEL_EXPORTER_OTLP_ENDPOINT", "")
+	Setup(Config{})
+	if Enabled() {
+		t.Fatal("expected telemetry disabled without endpoint")
+	}
+	s := StartRelease("1.0.0", false)
+	s.End(nil)
+	if s != nil || len(spans) != 0 {
+		t.Errorf("expected no spans recorded, but %v got", spans)
+	}
+	if err := Finish(nil, nil, false); err != nil {
+		t.Error(err)
+	}
+}
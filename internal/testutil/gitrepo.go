@@ -0,0 +1,39 @@
+// Package testutil 测试中共用的 git 仓库辅助函数
+package testutil
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// GitIdentity 设置提交作者及提交者，测试不依赖全局 git 配置
+func GitIdentity(t testing.TB) {
+	t.Helper()
+	for k, v := range map[string]string{
+		"GIT_AUTHOR_NAME": "autoctl", "GIT_AUTHOR_EMAIL": "autoctl@example.com",
+		"GIT_COMMITTER_NAME": "autoctl", "GIT_COMMITTER_EMAIL": "autoctl@example.com",
+	} {
+		t.Setenv(k, v)
+	}
+}
+
+// GitRun 在 dir 中执行 git 命令并返回输出，dir 为空时使用当前目录，失败时终止测试
+func GitRun(t testing.TB, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v: %s", strings.Join(args, " "), err, output)
+	}
+	return string(output)
+}
+
+// NewRepo 设置提交者后在 dir 中初始化默认分支为 main 的仓库，返回仓库目录
+func NewRepo(t testing.TB, dir string) string {
+	t.Helper()
+	GitIdentity(t)
+	GitRun(t, "", "init", "-q", "-b", "main", dir)
+	return dir
+}
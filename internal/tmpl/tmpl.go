@@ -0,0 +1,323 @@
+// Package tmpl 为变更日志、标签、提交信息、部署文件等模板提供统一的辅助函数及用户定义的模板片段
+package tmpl
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+var (
+	mu       sync.RWMutex
+	helpers  = map[string]string{} // 用户定义的模板片段，名称 => 模板
+	regCache sync.Map              // 正则表达式缓存
+)
+
+// Config 模板配置，对应配置文件 templates 节点
+type Config struct {
+	Helpers map[string]string `mapstructure:"helpers"` // 用户定义的模板片段，名称 => 模板
+}
+
+// SetHelpers 设置用户定义的模板片段，对应配置文件 templates.helpers 节点，
+// 模板中通过 {{ template "name" . }} 或 {{ include "name" . }} 引用
+func SetHelpers(h map[string]string) error {
+	t := base("helpers")
+	for name, text := range h {
+		if _, err := t.New(name).Parse(text); err != nil {
+			return fmt.Errorf("template helper %s: %w", name, err)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	helpers = make(map[string]string, len(h))
+	for name, text := range h {
+		helpers[name] = text
+	}
+	return nil
+}
+
+// New 创建包含辅助函数及用户定义片段的模板，缺少字段时执行报错
+func New(name string) *template.Template {
+	t := base(name)
+	mu.RLock()
+	defer mu.RUnlock()
+	for n, text := range helpers {
+		// Config 模板配置，对应配置文件 templates 节点
+		type Config struct {
+			Helpers map[string]string `mapstructure:"helpers"` // 用户定义的模板片段，名称 => 模板
+		}
+
+		// SetHelpers 已检查语法
+		template.Must(t.New(n).Parse(text))
+	}
+	return t
+}
+
+func base(name string) *template.Template {
+	t := template.New(name).Option("missingkey=error")
+	return t.Funcs(FuncMap()).Funcs(template.FuncMap{"include": include(t)})
+}
+
+// Render 渲染模板
+func Render(name, text string, data interface{}) (string, error) {
+	t, err := New(name).Parse(text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err = t.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// include 以函数形式执行模板片段，结果可以继续通过管道处理，如 {{ include "title" . | upper }}
+func include(t *template.Template) func(name string, data interface{}) (string, error) {
+	return func(name string, data interface{}) (string, error) {
+		var buf bytes.Buffer
+		if err := t.ExecuteTemplate(&buf, name, data); err != nil {
+			return "", err
+		}
+		return buf.String(), nil
+	}
+}
+
+// FuncMap 模板辅助函数
+func FuncMap() template.FuncMap {
+	return template.FuncMap{
+		// 日期
+		"now":  time.Now,
+		"date": date,
+		"utc":  func(t time.Time) time.Time { return t.UTC() },
+		// 字符串
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+		"title":      title,
+		"camel":      camel,
+		"snake":      func(s string) string { return strings.Join(words(s), "_") },
+		"kebab":      func(s string) string { return strings.Join(words(s), "-") },
+		"trim":       strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"contains":   func(sub, s string) bool { return strings.Contains(s, sub) },
+		"hasPrefix":  func(prefix, s string) bool { return strings.HasPrefix(s, prefix) },
+		"hasSuffix":  func(suffix, s string) bool { return strings.HasSuffix(s, suffix) },
+		"split":      func(sep, s string) []string { return strings.Split(s, sep) },
+		"join":       func(sep string, s []string) string { return strings.Join(s, sep) },
+		"truncate":   truncate,
+		"default":    orDefault,
+		// 正则表达式
+		"regexMatch":   regexMatch,
+		"regexReplace": regexReplace,
+		"regexFind":    regexFind,
+		// 版本号
+		"semver":        semver.Version,
+		"incVersion":    incVersion,
+		"compareSemver": compareSemver,
+		"finalize":      finalize,
+		"formatVersion": formatVersion,
+		// Markdown 及 URL
+		"mdEscape":    mdEscape,
+		"urlJoin":     urlJoin,
+		"queryEscape": url.QueryEscape,
+		"pathEscape":  url.PathEscape,
+	}
+}
+
+// date 按 Go 时间格式化时间，t 为空时使用当前时间，如 {{ date "2006-01-02" }}、{{ date "20060102" .Time }}
+func date(layout string, t ...time.Time) string {
+	if len(t) == 0 {
+		return time.Now().Format(layout)
+	}
+	return t[0].Format(layout)
+}
+
+// words 将 camelCase、snake_case、kebab-case 及空格分隔的字符串拆分为小写单词
+func words(s string) []string {
+	var (
+		res  []string
+		word []rune
+	)
+	flush := func() {
+		if len(word) > 0 {
+			res = append(res, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]) && unicode.IsUpper(runes[i-1]))):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return res
+}
+
+func title(s string) string {
+	ws := strings.Fields(s)
+	for i, w := range ws {
+		runes := []rune(w)
+		runes[0] = unicode.ToUpper(runes[0])
+		ws[i] = string(runes)
+	}
+	return strings.Join(ws, " ")
+}
+
+func camel(s string) string {
+	ws := words(s)
+	for i := 1; i < len(ws); i++ {
+		runes := []rune(ws[i])
+		runes[0] = unicode.ToUpper(runes[0])
+		ws[i] = string(runes)
+	}
+	return strings.Join(ws, "")
+}
+
+// truncate 截断为最多 n 个字符，截断时以 … 结尾
+func truncate(n int, s string) string {
+	runes := []rune(s)
+	if n <= 0 || len(runes) <= n {
+		return s
+	}
+	return string(runes[:n-1]) + "…"
+}
+
+// orDefault 值为空时使用默认值，如 {{ .Scope | default "core" }}
+func orDefault(def string, v interface{}) string {
+	if v == nil {
+		return def
+	}
+	if s := fmt.Sprint(v); s != "" {
+		return s
+	}
+	return def
+}
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	if reg, ok := regCache.Load(pattern); ok {
+		return reg.(*regexp.Regexp), nil
+	}
+	reg, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regCache.Store(pattern, reg)
+	return reg, nil
+}
+
+func regexMatch(pattern, s string) (bool, error) {
+	reg, err := compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return reg.MatchString(s), nil
+}
+
+// regexReplace 替换所有匹配，repl 中可以使用 $1 引用分组
+func regexReplace(pattern, repl, s string) (string, error) {
+	reg, err := compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	return reg.ReplaceAllString(s, repl), nil
+}
+
+func regexFind(pattern, s string) (string, error) {
+	reg, err := compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	return reg.FindString(s), nil
+}
+
+// toVersion 模板中的版本号可以是字符串或 semver.Semver
+func toVersion(v interface{}) (semver.Semver, error) {
+	switch v := v.(type) {
+	case semver.Semver:
+		return v, nil
+	case string:
+		return semver.Version(strings.TrimPrefix(v, "v"))
+	default:
+		return semver.Version(fmt.Sprint(v))
+	}
+}
+
+// incVersion 按版本变动类型递增版本号，如 {{ incVersion "minor" .Version }}
+func incVersion(release string, v interface{}) (string, error) {
+	version, err := toVersion(v)
+	if err != nil {
+		return "", err
+	}
+	changed, err := semver.ParseReleaseType(release)
+	if err != nil {
+		return "", err
+	}
+	return version.Increment(semver.WithReleaseType(changed)).String(), nil
+}
+
+func compareSemver(a, b interface{}) (int, error) {
+	va, err := toVersion(a)
+	if err != nil {
+		return 0, err
+	}
+	vb, err := toVersion(b)
+	if err != nil {
+		return 0, err
+	}
+	return va.Compare(vb), nil
+}
+
+// finalize 去除预发布及构建元数据，如 1.2.0-rc.1 => 1.2.0
+func finalize(v interface{}) (string, error) {
+	version, err := toVersion(v)
+	if err != nil {
+		return "", err
+	}
+	return version.FinalizeVersion(), nil
+}
+
+// formatVersion 按格式或格式名称输出版本号，如 {{ formatVersion "M.m" .Version }}、{{ formatVersion "docker" .Version }}
+func formatVersion(layout string, v interface{}) (string, error) {
+	version, err := toVersion(v)
+	if err != nil {
+		return "", err
+	}
+	return version.Format(layout), nil
+}
+
+var mdSpecial = strings.NewReplacer(
+	`\`, `\\`, "`", "\\`", "*", `\*`, "_", `\_`, "[", `\[`, "]", `\]`,
+	"<", `\<`, ">", `\>`, "#", `\#`, "|", `\|`,
+)
+
+// mdEscape 转义 Markdown 特殊字符，避免提交标题中的字符破坏变更日志格式
+func mdEscape(s string) string {
+	return mdSpecial.Replace(s)
+}
+
+// urlJoin 拼接地址及路径，如 {{ urlJoin "https://github.com" .Repo "releases" .Tag }}
+func urlJoin(base string, elem ...string) string {
+	parts := []string{strings.TrimSuffix(base, "/")}
+	for _, e := range elem {
+		if e = strings.Trim(e, "/"); e != "" {
+			parts = append(parts, e)
+		}
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,57 @@
+package tmpl
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRender(t *testing.T) {
+	data := map[string]interface{}{
+		"Tag":     "v1.2.0-rc.1",
+		"Version": "1.2.0-rc.1",
+		"Repo":    "org/app",
+		"Subject": "fix *bold* [link]",
+		"Time":    time.Date(2024, 5, 21, 0, 0, 0, 0, time.UTC),
+		"Scope":   "",
+	}
+	tests := map[string]string{
+		`{{ date "20060102" .Time }}`:                                   "20240521",
+		`{{ "releaseNotesTitle" | snake }}`:                             "release_notes_title",
+		`{{ "HTTPServer ready" | kebab }}`:                              "http-server-ready",
+		`{{ "release notes" | camel }}`:                                 "releaseNotes",
+		`{{ "release notes" | title }}`:                                 "Release Notes",
+		`{{ regexReplace "-rc\\.(\\d+)" "-RC$1" .Version }}`:            "1.2.0-RC1",
+		`{{ incVersion "minor" "v1.2.3" }}`:                             "1.3.0",
+		`{{ finalize .Version }}`:                                       "1.2.0",
+		`{{ compareSemver .Version "1.1.0" }}`:                          "1",
+		`{{ (semver .Version).Minor }}`:                                 "2",
+		`{{ .Subject | mdEscape }}`:                                     `fix \*bold\* \[link\]`,
+		`{{ truncate 5 "abcdefgh" }}`:                                   "abcd…",
+		`{{ urlJoin "https://github.com/" .Repo "releases/tag" .Tag }}`: "https://github.com/org/app/releases/tag/v1.2.0-rc.1",
+		`{{ .Scope | default "core" }}`:                                 "core",
+		`{{ .Tag | trimPrefix "v" | upper }}`:                           "1.2.0-RC.1",
+	}
+	for text, want := range tests {
+		got, err := Render("test", text, data)
+		if err != nil || got != want {
+			t.Errorf("Render(%s) = %q, %v, want %q", text, got, err, want)
+		}
+	}
+	if _, err := Render("test", "{{ .Missing }}", data); err == nil {
+		t.Error("expected missing key error, got nil")
+	}
+}
+
+func TestSetHelpers(t *testing.T) {
+	defer func() { _ = SetHelpers(nil) }()
+	if err := SetHelpers(map[string]string{"title": `{{ .Tag | upper }}`, "release": `Release {{ include "title" . }}`}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := Render("test", `{{ template "release" . }} / {{ include "title" . | lower }}`, map[string]string{"Tag": "v1.0.0"})
+	if err != nil || got != "Release V1.0.0 / v1.0.0" {
+		t.Errorf("unexpected %q %v", got, err)
+	}
+	if err = SetHelpers(map[string]string{"broken": "{{ .Tag "}); err == nil {
+		t.Error("expected syntax error, got nil")
+	}
+}
@@ -0,0 +1,198 @@
+package train
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/fileutil"
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+const (
+	DateLayout  = "2006-01-02"
+	DefaultFile = ".autoctl/plan.json"
+	// 计划最多向后推算的天数
+	maxDays = 2 * 366
+)
+
+// Cadence 发布节奏，如每隔两周的周二发布 minor 版本：{release: minor, weekday: tuesday, interval: 2}
+type Cadence struct {
+	Release  string `mapstructure:"release" json:"release"`   // 版本变动类型
+	Weekday  string `mapstructure:"weekday" json:"weekday"`   // 发布日 monday ~ sunday
+	Interval int    `mapstructure:"interval" json:"interval"` // 每隔几周发布一次，默认 1
+	Nth      int    `mapstructure:"nth" json:"nth"`           // 每月第几个发布日（1 ~ 5，-1 为最后一个），设置后忽略 interval
+	Start    string `mapstructure:"start" json:"start"`       // 计算 interval 的起始日期，interval 大于 1 时必须设置，避免重新生成计划时发布日偏移
+}
+
+// Config 发布列车配置，对应配置文件 train 节点
+type Config struct {
+	File     string    `mapstructure:"file"`     // 计划文件，相对路径基于工作目录，默认 .autoctl/plan.json
+	Cadences []Cadence `mapstructure:"cadences"` // 发布节奏
+}
+
+// Path 计划文件的路径
+func (c Config) Path(cwd string) string {
+	name := c.File
+	if name == "" {
+		name = DefaultFile
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(cwd, name)
+	}
+	return name
+}
+
+// Entry 计划中的一次发布
+type Entry struct {
+	Date     string `json:"date"`               // 发布日期
+	Release  string `json:"release"`            // 版本变动类型
+	Version  string `json:"version"`            // 计划版本
+	Released bool   `json:"released,omitempty"` // 是否已发布
+	Tag      string `json:"tag,omitempty"`      // 实际发布的标签
+}
+
+// Plan 发布计划
+type Plan struct {
+	Generated string  `json:"generated"` // 生成日期
+	Base      string  `json:"base"`      // 生成计划时的当前版本
+	Entries   []Entry `json:"entries"`
+}
+
+type cadence struct {
+	changed  semver.VersionChanged
+	weekday  time.Weekday
+	interval int
+	nth      int
+	start    time.Time
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday": time.Sunday, "monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday,
+	"thursday": time.Thursday, "friday": time.Friday, "saturday": time.Saturday,
+}
+
+func (c Cadence) parse(from time.Time) (cadence, error) {
+	res := cadence{interval: c.Interval, nth: c.Nth, start: from}
+	var err error
+	if res.changed, err = semver.ParseReleaseType(c.Release); err != nil {
+		return res, err
+	}
+	weekday, ok := weekdays[strings.ToLower(c.Weekday)]
+	if !ok {
+		return res, fmt.Errorf("invalid cadence weekday %q", c.Weekday)
+	}
+	res.weekday = weekday
+	if res.interval <= 0 {
+		res.interval = 1
+	}
+	if res.nth < -1 || res.nth > 5 {
+		return res, fmt.Errorf("invalid cadence nth %d, must between 1 and 5 or -1", res.nth)
+	}
+	if c.Start != "" {
+		if res.start, err = time.Parse(DateLayout, c.Start); err != nil {
+			return res, fmt.Errorf("invalid cadence start: %w", err)
+		}
+	} else if res.interval > 1 && res.nth == 0 {
+		// 以计划生成日期为起点时，每次重新生成计划都会改变隔周发布的日期
+		return res, fmt.Errorf("cadence of %s every %d weeks requires a start date, e.g. start: %s", c.Release, res.interval, from.Format(DateLayout))
+	}
+	return res, nil
+}
+
+func (c cadence) match(day time.Time) bool {
+	if day.Weekday() != c.weekday || day.Before(c.start) {
+		return false
+	}
+	if c.nth == -1 {
+		return day.AddDate(0, 0, 7).Month() != day.Month()
+	} else if c.nth > 0 {
+		return (day.Day()-1)/7+1 == c.nth
+	}
+	// 从起始日期之后的第一个发布日开始，每隔 interval 周
+	first := c.start
+	for first.Weekday() != c.weekday {
+		first = first.AddDate(0, 0, 1)
+	}
+	weeks := int(day.Sub(first).Hours()/24) / 7
+	return weeks%c.interval == 0
+}
+
+// 版本变动影响越大优先级越高，忽略预发布标志位
+func priority(changed semver.VersionChanged) int {
+	return int(changed &^ 1)
+}
+
+// Compute 从 from 开始推算 count 次计划发布，同一天命中多个节奏时取影响最大的版本变动类型
+func Compute(cfg Config, current semver.Semver, from time.Time, count int) (*Plan, error) {
+	if len(cfg.Cadences) == 0 {
+		return nil, errors.New("no release cadence configured")
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	cadences := make([]cadence, 0, len(cfg.Cadences))
+	for _, c := range cfg.Cadences {
+		parsed, err := c.parse(from)
+		if err != nil {
+			return nil, err
+		}
+		cadences = append(cadences, parsed)
+	}
+	if current == nil {
+		current, _ = semver.Version("0.0.0")
+	}
+	plan := &Plan{Generated: from.Format(DateLayout), Base: current.String()}
+	version := current
+	for day, i := from, 0; len(plan.Entries) < count && i < maxDays; day, i = day.AddDate(0, 0, 1), i+1 {
+		var changed semver.VersionChanged
+		for _, c := range cadences {
+			if c.match(day) && (changed == 0 || priority(c.changed) < priority(changed)) {
+				changed = c.changed
+			}
+		}
+		if changed == 0 {
+			continue
+		}
+		version = version.Increment(semver.WithReleaseType(changed))
+		plan.Entries = append(plan.Entries, Entry{Date: day.Format(DateLayout), Release: changed.String(), Version: version.String()})
+	}
+	return plan, nil
+}
+
+// Next 返回下一个未发布的计划
+func (p *Plan) Next() (*Entry, bool) {
+	for i := range p.Entries {
+		if !p.Entries[i].Released {
+			return &p.Entries[i], true
+		}
+	}
+	return nil, false
+}
+
+// Load 读取计划文件
+func Load(file string) (*Plan, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	plan := new(Plan)
+	if err = json.Unmarshal(data, plan); err != nil {
+		return nil, fmt.Errorf("parse plan file %s: %w", file, err)
+	}
+	return plan, nil
+}
+
+// Save 写入计划文件
+func (p *Plan) Save(file string) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return fileutil.WriteFile(file, append(data, '\n'), 0o644)
+}
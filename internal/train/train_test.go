@@ -0,0 +1,72 @@
+package train
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/coffee377/autoctl/pkg/semver"
+)
+
+func TestCompute(t *testing.T) {
+	current, _ := semver.Version("1.2.0")
+	// 2024-01-01 周一
+	from := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	cfg := Config{Cadences: []Cadence{
+		{Release: "minor", Weekday: "tuesday", Interval: 2, Start: "2024-01-01"},
+		{Release: "patch", Weekday: "tuesday"},
+		{Release: "major", Weekday: "friday", Nth: -1, Start: "2024-01-20"},
+	}}
+	plan, err := Compute(cfg, current, from, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []Entry{
+		{Date: "2024-01-02", Release: "minor", Version: "1.3.0"},
+		{Date: "2024-01-09", Release: "patch", Version: "1.3.1"},
+		{Date: "2024-01-16", Release: "minor", Version: "1.4.0"},
+		{Date: "2024-01-23", Release: "patch", Version: "1.4.1"},
+		{Date: "2024-01-26", Release: "major", Version: "2.0.0"},
+	}
+	if len(plan.Entries) != len(expected) {
+		t.Fatalf("expected %d entries, but %d got", len(expected), len(plan.Entries))
+	}
+	for i, e := range expected {
+		if plan.Entries[i] != e {
+			t.Errorf("entry %d expected %+v, but %+v got", i, e, plan.Entries[i])
+		}
+	}
+}
+
+func TestCompute_InvalidCadence(t *testing.T) {
+	if _, err := Compute(Config{}, nil, time.Now(), 1); err == nil {
+		t.Error("expected error without cadence, got nil")
+	}
+	cfg := Config{Cadences: []Cadence{{Release: "minor", Weekday: "someday"}}}
+	if _, err := Compute(cfg, nil, time.Now(), 1); err == nil {
+		t.Error("expected invalid weekday error, got nil")
+	}
+	cfg = Config{Cadences: []Cadence{{Release: "minor", Weekday: "tuesday", Interval: 2}}}
+	if _, err := Compute(cfg, nil, time.Now(), 1); err == nil {
+		t.Error("expected start required every 2 weeks, got nil")
+	}
+}
+
+func TestPlan_SaveLoad(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "plan", "plan.json")
+	plan := &Plan{Generated: "2024-01-01", Base: "1.0.0", Entries: []Entry{
+		{Date: "2024-01-02", Release: "minor", Version: "1.1.0", Released: true, Tag: "v1.1.0"},
+		{Date: "2024-01-09", Release: "patch", Version: "1.1.1"},
+	}}
+	if err := plan.Save(file); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	next, ok := loaded.Next()
+	if !ok || next.Version != "1.1.1" {
+		t.Errorf("expected next planned version 1.1.1, but %+v got", next)
+	}
+}
@@ -0,0 +1,136 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/artifact"
+	"github.com/coffee377/autoctl/internal/httpclient"
+)
+
+const (
+	EventRelease = "release"
+
+	HeaderEvent     = "X-Autoctl-Event"
+	HeaderDelivery  = "X-Autoctl-Delivery"
+	HeaderSignature = "X-Autoctl-Signature-256"
+)
+
+// Config 接收事件的 webhook 端点，对应配置文件 webhooks 节点中的一项
+type Config struct {
+	Name      string            `mapstructure:"name"`      // 名称，用于日志输出
+	URL       string            `mapstructure:"url"`       // 接收地址
+	Secret    string            `mapstructure:"secret"`    // HMAC-SHA256 签名密钥，为空时不签名
+	SecretEnv string            `mapstructure:"secretEnv"` // 从环境变量读取签名密钥，避免将密钥写入配置文件
+	Headers   map[string]string `mapstructure:"headers"`   // 自定义请求头
+	HTTP      httpclient.Config `mapstructure:"http"`      // 代理、TLS 及重试配置，未设置的字段继承全局 http 节点
+}
+
+func (c Config) name() string {
+	if c.Name != "" {
+		return c.Name
+	}
+	return c.URL
+}
+
+func (c Config) secret() string {
+	if c.Secret == "" && c.SecretEnv != "" {
+		return os.Getenv(c.SecretEnv)
+	}
+	return c.Secret
+}
+
+// Event 发布事件
+type Event struct {
+	ID          string              `json:"id"`
+	Type        string              `json:"type"`
+	Timestamp   time.Time           `json:"timestamp"`
+	Version     string              `json:"version"`
+	Previous    string              `json:"previous,omitempty"`
+	Tag         string              `json:"tag"`
+	Commit      string              `json:"commit"`
+	Branch      string              `json:"branch,omitempty"`
+	Prerelease  bool                `json:"prerelease"`
+	Maintenance bool                `json:"maintenance"`
+	Changelog   string              `json:"changelog,omitempty"` // Markdown 格式的变更日志
+	Artifacts   []artifact.Artifact `json:"artifacts,omitempty"`
+	URLs        map[string]string   `json:"urls,omitempty"`       // 代码托管平台等地址，如 release
+	EndOfLife   []string            `json:"endOfLife,omitempty"`  // 本次发布后停止支持的版本说明
+	Components  []Component         `json:"components,omitempty"` // 按 CODEOWNERS 归属的组件，聊天通知可据此提及负责团队
+}
+
+// Component 发布中有变更的组件
+type Component struct {
+	Name     string   `json:"name"`
+	Owners   []string `json:"owners,omitempty"`   // CODEOWNERS 中的负责人
+	Mentions []string `json:"mentions,omitempty"` // 需要提及的负责团队，未启用 release.components.notify 时为空
+	Commits  int      `json:"commits"`            // 归属到组件的提交数
+}
+
+// NewEvent 创建指定类型的事件并生成唯一标识
+func NewEvent(eventType string) *Event {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return &Event{ID: hex.EncodeToString(id), Type: eventType, Timestamp: time.Now().UTC()}
+}
+
+// Sign 计算请求体的签名，格式为 sha256=<hex>
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验签名，供接收方使用
+func Verify(secret string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
+
+// Send 向端点发送事件，非 2xx 响应视为失败
+func Send(cfg Config, event *Event) error {
+	if cfg.URL == "" {
+		return fmt.Errorf("webhook %s: url is required", cfg.name())
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	client, err := httpclient.New(cfg.HTTP)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, cfg.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for k, v := range cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "autoctl")
+	req.Header.Set(HeaderEvent, event.Type)
+	req.Header.Set(HeaderDelivery, event.ID)
+	if secret := cfg.secret(); secret != "" {
+		req.Header.Set(HeaderSignature, Sign(secret, body))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook %s: %w", cfg.name(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook %s: %d %s", cfg.name(), resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return nil
+}
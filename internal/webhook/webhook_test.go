@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSend(t *testing.T) {
+	var received Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if !Verify("s3cr3t", body, r.Header.Get(HeaderSignature)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(HeaderEvent) != EventRelease || r.Header.Get("X-Team") != "infra" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		_ = json.Unmarshal(body, &received)
+	}))
+	defer server.Close()
+
+	t.Setenv("WEBHOOK_SECRET", "s3cr3t")
+	event := NewEvent(EventRelease)
+	event.Version = "1.2.0"
+	event.URLs = map[string]string{"release": "https://github.com/coffee377/autoctl/releases/tag/v1.2.0"}
+	cfg := Config{URL: server.URL, SecretEnv: "WEBHOOK_SECRET", Headers: map[string]string{"X-Team": "infra"}}
+	if err := Send(cfg, event); err != nil {
+		t.Fatal(err)
+	}
+	if received.ID != event.ID || received.Version != "1.2.0" {
+		t.Errorf("unexpected received event %+v", received)
+	}
+
+	cfg.SecretEnv, cfg.Secret = "", "wrong"
+	if err := Send(cfg, event); err == nil {
+		t.Error("expected signature rejected, got nil")
+	}
+}
@@ -0,0 +1,93 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/coffee377/autoctl/internal/runctx"
+	"github.com/coffee377/autoctl/internal/shell"
+)
+
+// Config 工作区配置，对应配置文件 workspace 节点
+type Config struct {
+	Detectors []Custom `mapstructure:"detectors"` // 自定义检测器，先于内置检测器识别目录
+}
+
+// Custom 以清单文件识别软件包的检测器，无需修改代码即可支持 Bazel、自定义清单等布局
+type Custom struct {
+	Kind     string   `mapstructure:"type"`      // 软件包类型，如 bazel
+	Files    []string `mapstructure:"manifests"` // 标识软件包目录的清单文件名，如 BUILD.bazel
+	Name     string   `mapstructure:"name"`      // 从清单中提取名称的正则表达式，第一个分组为名称，为空时使用目录名
+	Command  string   `mapstructure:"command"`   // 在软件包目录中执行的命令，输出软件包名称，退出码非 0 或没有输出时不是软件包，优先于 name
+	Packages []string `mapstructure:"packages"`  // 根目录中的软件包路径模式，为空时扫描子目录
+
+	name *regexp.Regexp
+}
+
+// Register 校验并注册配置文件中声明的检测器
+func (c Config) Register() error {
+	detectors := make([]Detector, 0, len(c.Detectors))
+	for i, d := range c.Detectors {
+		if d.Kind == "" || len(d.Files) == 0 {
+			return fmt.Errorf("workspace.detectors[%d]: type and manifests are required", i)
+		}
+		if d.Name != "" {
+			re, err := regexp.Compile(d.Name)
+			if err != nil {
+				return fmt.Errorf("workspace.detectors[%d].name: %w", i, err)
+			}
+			d.name = re
+		}
+		detectors = append(detectors, &customDetector{d})
+	}
+	// 按声明顺序识别
+	for i := len(detectors) - 1; i >= 0; i-- {
+		Register(detectors[i])
+	}
+	return nil
+}
+
+type customDetector struct {
+	Custom
+}
+
+func (d *customDetector) Type() string { return d.Kind }
+
+func (d *customDetector) Manifests() []string { return d.Files }
+
+func (d *customDetector) Detect(dir string) (string, bool) {
+	var manifest []byte
+	found := false
+	for _, file := range d.Files {
+		if data, err := os.ReadFile(filepath.Join(dir, file)); err == nil {
+			manifest, found = data, true
+			break
+		}
+	}
+	if !found {
+		return "", false
+	}
+	if d.Command != "" {
+		ctx, cancel := runctx.Command()
+		defer cancel()
+		cmd := shell.Command(ctx, d.Command)
+		cmd.Dir = dir
+		output, err := cmd.Output()
+		name := strings.TrimSpace(string(output))
+		return name, err == nil && name != ""
+	}
+	if d.name != nil {
+		if m := d.name.FindSubmatch(manifest); len(m) > 1 {
+			return string(m[1]), true
+		}
+	}
+	abs, _ := filepath.Abs(dir)
+	return filepath.Base(abs), true
+}
+
+func (d *customDetector) Patterns(string) ([]string, error) {
+	return d.Packages, nil
+}
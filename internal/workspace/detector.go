@@ -0,0 +1,367 @@
+package workspace
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Detector 识别目录中的软件包，内置 npm、Go、Maven、Gradle、Cargo、Python 及 Helm；
+// 其它布局（如 Bazel、自定义清单）可通过 Register 注册，或在配置文件 workspace.detectors 中声明 Custom 检测器
+type Detector interface {
+	// Type 软件包类型，如 npm、go
+	Type() string
+	// Manifests 标识软件包目录的清单文件名，扫描子目录时据此查找软件包
+	Manifests() []string
+	// Detect 读取目录中的清单并返回软件包名称，目录不是该类型的软件包时返回 false
+	Detect(dir string) (name string, ok bool)
+}
+
+// Members 可在根目录声明工作区成员的检测器，如 pnpm-workspace.yaml、go.work、Maven 父 POM 的 modules
+type Members interface {
+	// Patterns 读取根目录中声明的软件包路径模式，支持 * 及末尾的 **，以 ! 开头的模式表示排除
+	Patterns(root string) ([]string, error)
+}
+
+var (
+	mu         sync.RWMutex
+	registered []Detector // 优先于内置检测器
+	builtin    = []Detector{npmDetector{}, goDetector{}, mavenDetector{}, gradleDetector{}, cargoDetector{}, pythonDetector{}, helmDetector{}}
+)
+
+// Register 注册检测器，先于内置检测器及之前注册的检测器识别目录
+func Register(detectors ...Detector) {
+	mu.Lock()
+	defer mu.Unlock()
+	registered = append(append([]Detector{}, detectors...), registered...)
+}
+
+// Detectors 按识别顺序返回全部检测器
+func Detectors() []Detector {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append(append([]Detector{}, registered...), builtin...)
+}
+
+type npmDetector struct{}
+
+func (npmDetector) Type() string { return NPM }
+
+func (npmDetector) Manifests() []string { return []string{"package.json"} }
+
+// Detect 存在 package.json 即为软件包，名称可以为空
+func (npmDetector) Detect(dir string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return "", false
+	}
+	v := struct {
+		Name string `json:"name"`
+	}{}
+	_ = json.Unmarshal(data, &v)
+	return v.Name, true
+}
+
+// Patterns 读取 pnpm-workspace.yaml、package.json workspaces 及 lerna.json
+func (npmDetector) Patterns(root string) ([]string, error) {
+	list := make([]string, 0)
+	if data, err := os.ReadFile(filepath.Join(root, "pnpm-workspace.yaml")); err == nil {
+		v := struct {
+			Packages []string `yaml:"packages"`
+		}{}
+		if err = yaml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		list = append(list, v.Packages...)
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "package.json")); err == nil {
+		v := struct {
+			Workspaces json.RawMessage `json:"workspaces"`
+		}{}
+		if err = json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		if len(v.Workspaces) > 0 {
+			// workspaces 可以是数组或 yarn 的 { packages: [] }
+			var ws []string
+			if json.Unmarshal(v.Workspaces, &ws) != nil {
+				obj := struct {
+					Packages []string `json:"packages"`
+				}{}
+				_ = json.Unmarshal(v.Workspaces, &obj)
+				ws = obj.Packages
+			}
+			list = append(list, ws...)
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(root, "lerna.json")); err == nil {
+		v := struct {
+			Packages []string `json:"packages"`
+		}{}
+		if err = json.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		list = append(list, v.Packages...)
+	}
+	return list, nil
+}
+
+type goDetector struct{}
+
+func (goDetector) Type() string { return Go }
+
+func (goDetector) Manifests() []string { return []string{"go.mod"} }
+
+func (goDetector) Detect(dir string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return "", false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`), true
+		}
+	}
+	return "", true
+}
+
+// Patterns 读取 go.work 中 use 声明的模块目录
+func (goDetector) Patterns(root string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(root, "go.work"))
+	if err != nil {
+		return nil, nil
+	}
+	return ParseGoWork(data), nil
+}
+
+// pom Maven 项目对象模型中用到的字段
+type pom struct {
+	ArtifactID string   `xml:"artifactId"`
+	Modules    []string `xml:"modules>module"`
+}
+
+func readPOM(dir string) (*pom, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, "pom.xml"))
+	if err != nil {
+		return nil, false
+	}
+	p := &pom{}
+	if xml.Unmarshal(data, p) != nil {
+		return nil, false
+	}
+	return p, true
+}
+
+type mavenDetector struct{}
+
+func (mavenDetector) Type() string { return Maven }
+
+func (mavenDetector) Manifests() []string { return []string{"pom.xml"} }
+
+// Detect 名称为 artifactId，继承自父 POM 的 groupId 不计入名称
+func (mavenDetector) Detect(dir string) (string, bool) {
+	p, ok := readPOM(dir)
+	if !ok {
+		return "", false
+	}
+	return p.ArtifactID, true
+}
+
+// Patterns 读取父 POM 的 modules
+func (mavenDetector) Patterns(root string) ([]string, error) {
+	p, ok := readPOM(root)
+	if !ok {
+		return nil, nil
+	}
+	return p.Modules, nil
+}
+
+var (
+	gradleInclude     = regexp.MustCompile(`^\s*include\b(.*)`)
+	gradleQuoted      = regexp.MustCompile(`["']([^"']+)["']`)
+	gradleRootProject = regexp.MustCompile(`rootProject\.name\s*=\s*["']([^"']+)["']`)
+)
+
+type gradleDetector struct{}
+
+func (gradleDetector) Type() string { return Gradle }
+
+func (gradleDetector) Manifests() []string { return []string{"build.gradle", "build.gradle.kts"} }
+
+// Detect 名称为 settings.gradle 中的 rootProject.name，未设置时为目录名
+func (d gradleDetector) Detect(dir string) (string, bool) {
+	found := false
+	for _, manifest := range d.Manifests() {
+		if _, err := os.Stat(filepath.Join(dir, manifest)); err == nil {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", false
+	}
+	if data, ok := readSettings(dir); ok {
+		if m := gradleRootProject.FindSubmatch(data); m != nil {
+			return string(m[1]), true
+		}
+	}
+	abs, _ := filepath.Abs(dir)
+	return filepath.Base(abs), true
+}
+
+// Patterns 读取 settings.gradle 中 include 的项目，:a:b 对应目录 a/b
+func (gradleDetector) Patterns(root string) ([]string, error) {
+	data, ok := readSettings(root)
+	if !ok {
+		return nil, nil
+	}
+	list := make([]string, 0)
+	for _, line := range strings.Split(string(data), "\n") {
+		m := gradleInclude.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		for _, q := range gradleQuoted.FindAllStringSubmatch(m[1], -1) {
+			list = append(list, strings.ReplaceAll(strings.TrimPrefix(q[1], ":"), ":", "/"))
+		}
+	}
+	return list, nil
+}
+
+func readSettings(dir string) ([]byte, bool) {
+	for _, name := range []string{"settings.gradle", "settings.gradle.kts"} {
+		if data, err := os.ReadFile(filepath.Join(dir, name)); err == nil {
+			return data, true
+		}
+	}
+	return nil, false
+}
+
+// cargoManifest Cargo.toml 中用到的字段
+type cargoManifest struct {
+	Package *struct {
+		Name string `toml:"name"`
+	} `toml:"package"`
+	Workspace *struct {
+		Members []string `toml:"members"`
+		Exclude []string `toml:"exclude"`
+	} `toml:"workspace"`
+}
+
+func readCargo(dir string) (*cargoManifest, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, "Cargo.toml"))
+	if err != nil {
+		return nil, false
+	}
+	m := &cargoManifest{}
+	if toml.Unmarshal(data, m) != nil {
+		return nil, false
+	}
+	return m, true
+}
+
+type cargoDetector struct{}
+
+func (cargoDetector) Type() string { return Cargo }
+
+func (cargoDetector) Manifests() []string { return []string{"Cargo.toml"} }
+
+// Detect 只声明 [workspace] 的虚拟清单不是软件包
+func (cargoDetector) Detect(dir string) (string, bool) {
+	m, ok := readCargo(dir)
+	if !ok || m.Package == nil {
+		return "", false
+	}
+	return m.Package.Name, true
+}
+
+// Patterns 读取 [workspace] 的 members 及 exclude
+func (cargoDetector) Patterns(root string) ([]string, error) {
+	m, ok := readCargo(root)
+	if !ok || m.Workspace == nil {
+		return nil, nil
+	}
+	list := append([]string{}, m.Workspace.Members...)
+	for _, exclude := range m.Workspace.Exclude {
+		list = append(list, "!"+exclude)
+	}
+	return list, nil
+}
+
+var (
+	setupCfgName = regexp.MustCompile(`(?m)^\s*name\s*=\s*(\S+)`)
+	setupPyName  = regexp.MustCompile(`\bname\s*=\s*["']([^"']+)["']`)
+)
+
+type pythonDetector struct{}
+
+func (pythonDetector) Type() string { return Python }
+
+func (pythonDetector) Manifests() []string {
+	return []string{"pyproject.toml", "setup.cfg", "setup.py"}
+}
+
+// Detect 依次读取 pyproject.toml 的 [project] 或 [tool.poetry]、setup.cfg 的 [metadata] 及 setup.py 中的名称，
+// 只包含工具配置的 pyproject.toml 不是软件包
+func (pythonDetector) Detect(dir string) (string, bool) {
+	if data, err := os.ReadFile(filepath.Join(dir, "pyproject.toml")); err == nil {
+		v := struct {
+			Project struct {
+				Name string `toml:"name"`
+			} `toml:"project"`
+			Tool struct {
+				Poetry struct {
+					Name string `toml:"name"`
+				} `toml:"poetry"`
+			} `toml:"tool"`
+		}{}
+		if toml.Unmarshal(data, &v) == nil {
+			if v.Project.Name != "" {
+				return v.Project.Name, true
+			}
+			if v.Tool.Poetry.Name != "" {
+				return v.Tool.Poetry.Name, true
+			}
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "setup.cfg")); err == nil {
+		if _, metadata, ok := strings.Cut(string(data), "[metadata]"); ok {
+			if section, _, _ := strings.Cut(metadata, "\n["); setupCfgName.MatchString(section) {
+				return setupCfgName.FindStringSubmatch(section)[1], true
+			}
+		}
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "setup.py")); err == nil {
+		if m := setupPyName.FindSubmatch(data); m != nil {
+			return string(m[1]), true
+		}
+	}
+	return "", false
+}
+
+type helmDetector struct{}
+
+func (helmDetector) Type() string { return Helm }
+
+func (helmDetector) Manifests() []string { return []string{"Chart.yaml"} }
+
+func (helmDetector) Detect(dir string) (string, bool) {
+	data, err := os.ReadFile(filepath.Join(dir, "Chart.yaml"))
+	if err != nil {
+		return "", false
+	}
+	v := struct {
+		Name string `yaml:"name"`
+	}{}
+	if yaml.Unmarshal(data, &v) != nil {
+		return "", false
+	}
+	return v.Name, true
+}
@@ -0,0 +1,86 @@
+package workspace
+
+import (
+	"path"
+	"strings"
+)
+
+// PathRules 变更检测的路径规则，按提交修改的文件决定提交是否触发发布，对应配置文件 release.paths 节点；
+// 路径相对仓库根目录，支持 * 及 **，不含 / 的模式匹配任意目录下的文件名，如 *.md
+type PathRules struct {
+	Ignore []string `mapstructure:"ignore"` // 只修改这些路径的提交不触发发布，如 docs/**、*.md、.github/**
+	Force  []string `mapstructure:"force"`  // 修改这些路径的提交至少发布 patch 版本，优先于 ignore，如 migrations/**
+}
+
+// Empty 是否没有配置任何规则
+func (r PathRules) Empty() bool {
+	return len(r.Ignore) == 0 && len(r.Force) == 0
+}
+
+// Forced 修改的文件中第一个匹配 force 的模式
+func (r PathRules) Forced(files []string) (string, bool) {
+	for _, file := range files {
+		if pattern, ok := matchAny(r.Force, file); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// Ignored 修改的文件是否全部匹配 ignore 且都不匹配 force，没有文件时为 false
+func (r PathRules) Ignored(files []string) bool {
+	return len(files) > 0 && len(r.Filter(files)) == 0
+}
+
+// Filter 去除匹配 ignore 且不匹配 force 的文件
+func (r PathRules) Filter(files []string) []string {
+	list := make([]string, 0, len(files))
+	for _, file := range files {
+		_, ignored := matchAny(r.Ignore, file)
+		_, forced := matchAny(r.Force, file)
+		if !ignored || forced {
+			list = append(list, file)
+		}
+	}
+	return list
+}
+
+func matchAny(patterns []string, file string) (string, bool) {
+	for _, pattern := range patterns {
+		if MatchPath(pattern, file) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// MatchPath 路径是否匹配模式，** 匹配零或多级目录，末尾的 /** 匹配目录下的全部文件，不含 / 的模式匹配任意目录下的文件名
+func MatchPath(pattern, file string) bool {
+	pattern = strings.TrimPrefix(strings.TrimPrefix(pattern, "/"), "./")
+	if !strings.Contains(pattern, "/") && pattern != "**" {
+		ok, _ := path.Match(pattern, path.Base(file))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(file, "/"))
+}
+
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segments) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], segments[0]); !ok {
+			return false
+		}
+		pattern, segments = pattern[1:], segments[1:]
+	}
+	return len(segments) == 0
+}
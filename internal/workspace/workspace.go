@@ -0,0 +1,209 @@
+package workspace
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// 内置检测器识别的软件包类型
+const (
+	NPM    = "npm"
+	Go     = "go"
+	Maven  = "maven"
+	Gradle = "gradle"
+	Cargo  = "cargo"
+	Python = "python"
+	Helm   = "helm"
+)
+
+// Package 工作区中的软件包
+type Package struct {
+	Name string `json:"name"` // 清单中的名称，如 package.json 中的 name、go.mod 中的 module、pom.xml 中的 artifactId
+	Path string `json:"path"` // 相对工作区根目录的路径
+	Type string `json:"type"` // 识别软件包的检测器类型 npm | go | maven | gradle | cargo | python | helm 或自定义类型
+}
+
+// Scope 软件包对应的提交范围，npm 包去除组织前缀，Go 模块使用目录名
+func (p Package) Scope() string {
+	if p.Type == NPM && p.Name != "" {
+		return path.Base(p.Name)
+	}
+	if p.Path != "." {
+		return path.Base(p.Path)
+	}
+	// 根目录模块使用模块路径的最后一段，忽略主版本后缀
+	name := p.Name
+	if base := path.Base(name); len(base) > 1 && base[0] == 'v' && strings.Trim(base[1:], "0123456789") == "" {
+		name = path.Dir(name)
+	}
+	return path.Base(name)
+}
+
+// skipDirs 扫描目录时忽略的目录
+var skipDirs = map[string]bool{"node_modules": true, "vendor": true, "testdata": true, "dist": true}
+
+// Discover 查找工作区中的软件包，优先使用 pnpm-workspace.yaml、package.json workspaces、lerna.json、go.work、
+// 父 POM 的 modules、settings.gradle 的 include 及 Cargo.toml 的 workspace.members 中声明的路径，均未声明时扫描子目录中的清单文件
+func Discover(dir string) ([]Package, error) {
+	if dir == "" {
+		dir = "."
+	}
+	patterns, err := patterns(dir)
+	if err != nil {
+		return nil, err
+	}
+	dirs := make([]string, 0)
+	if len(patterns) > 0 {
+		if dirs, err = expand(dir, patterns); err != nil {
+			return nil, err
+		}
+	} else if dirs, err = scan(dir); err != nil {
+		return nil, err
+	}
+	packages := make([]Package, 0, len(dirs))
+	for _, d := range dirs {
+		if p, ok := load(dir, d); ok {
+			packages = append(packages, p)
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool { return packages[i].Path < packages[j].Path })
+	return packages, nil
+}
+
+// patterns 读取检测器在根目录中声明的软件包路径模式
+func patterns(dir string) ([]string, error) {
+	list := make([]string, 0)
+	for _, d := range Detectors() {
+		m, ok := d.(Members)
+		if !ok {
+			continue
+		}
+		p, err := m.Patterns(dir)
+		if err != nil {
+			return nil, fmt.Errorf("%s workspace: %w", d.Type(), err)
+		}
+		list = append(list, p...)
+	}
+	return list, nil
+}
+
+// ParseGoWork 解析 go.work 中 use 声明的模块目录
+func ParseGoWork(data []byte) []string {
+	dirs := make([]string, 0)
+	block := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case block && fields[0] == ")":
+			block = false
+		case block:
+			dirs = append(dirs, strings.Trim(fields[0], `"`))
+		case fields[0] == "use" && len(fields) == 2 && fields[1] == "(":
+			block = true
+		case fields[0] == "use" && len(fields) == 2:
+			dirs = append(dirs, strings.Trim(fields[1], `"`))
+		}
+	}
+	return dirs
+}
+
+// expand 展开路径模式，支持 * 及末尾的 **，以 ! 开头的模式表示排除
+func expand(root string, patterns []string) ([]string, error) {
+	matched := map[string]bool{}
+	excluded := make([]string, 0)
+	for _, pattern := range patterns {
+		pattern = path.Clean(strings.TrimPrefix(filepath.ToSlash(pattern), "./"))
+		if strings.HasPrefix(pattern, "!") {
+			excluded = append(excluded, strings.TrimPrefix(pattern, "!"))
+			continue
+		}
+		if strings.HasSuffix(pattern, "/**") {
+			prefix := strings.TrimSuffix(pattern, "/**")
+			dirs, err := scan(filepath.Join(root, filepath.FromSlash(prefix)))
+			if err != nil && !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+			for _, d := range dirs {
+				matched[path.Join(prefix, d)] = true
+			}
+			continue
+		}
+		dirs, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range dirs {
+			rel, _ := filepath.Rel(root, d)
+			matched[filepath.ToSlash(rel)] = true
+		}
+	}
+	list := make([]string, 0, len(matched))
+	for d := range matched {
+		if !excludedBy(d, excluded) {
+			list = append(list, d)
+		}
+	}
+	return list, nil
+}
+
+func excludedBy(dir string, excluded []string) bool {
+	for _, pattern := range excluded {
+		if prefix := strings.TrimSuffix(pattern, "/**"); prefix != pattern && (dir == prefix || strings.HasPrefix(dir, prefix+"/")) {
+			return true
+		}
+		if ok, _ := path.Match(pattern, dir); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// scan 查找子目录中包含任一检测器清单文件的目录，返回相对 root 的路径
+func scan(root string) ([]string, error) {
+	manifests := make([]string, 0)
+	for _, d := range Detectors() {
+		manifests = append(manifests, d.Manifests()...)
+	}
+	dirs := make([]string, 0)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || p == root {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), ".") || skipDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		for _, manifest := range manifests {
+			if _, err := os.Stat(filepath.Join(p, manifest)); err == nil {
+				rel, _ := filepath.Rel(root, p)
+				dirs = append(dirs, filepath.ToSlash(rel))
+				break
+			}
+		}
+		return nil
+	})
+	return dirs, err
+}
+
+// load 依次使用各检测器识别目录，目录不是任何类型的软件包时返回 false
+func load(root, dir string) (Package, bool) {
+	base := filepath.Join(root, filepath.FromSlash(dir))
+	for _, d := range Detectors() {
+		if name, ok := d.Detect(base); ok {
+			return Package{Name: name, Path: dir, Type: d.Type()}, true
+		}
+	}
+	return Package{}, false
+}
@@ -0,0 +1,198 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func scopes(packages []Package) []string {
+	list := make([]string, 0, len(packages))
+	for _, p := range packages {
+		list = append(list, p.Scope())
+	}
+	return list
+}
+
+func TestDiscover_Workspaces(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"package.json":                   `{"name": "root", "workspaces": {"packages": ["packages/*", "!packages/legacy"]}}`,
+		"pnpm-workspace.yaml":            "packages:\n  - apps/**\n",
+		"packages/ui/package.json":       `{"name": "@teamwork/ui"}`,
+		"packages/legacy/package.json":   `{"name": "legacy"}`,
+		"packages/docs/README.md":        "no manifest",
+		"apps/web/admin/package.json":    `{"name": "admin"}`,
+		"apps/web/node_modules/x/a.json": `{}`,
+	})
+	packages, err := Discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"admin", "ui"}; !reflect.DeepEqual(scopes(packages), expected) {
+		t.Errorf("expected %v, but %v got", expected, packages)
+	}
+}
+
+func TestDiscover_GoWork(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.work":            "go 1.18\n\nuse (\n\t.\n\t./pkg/semver // semver\n)\nuse ./pkg/git\n",
+		"go.mod":             "module github.com/coffee377/autoctl/v2\n",
+		"pkg/semver/go.mod":  "module github.com/coffee377/autoctl/pkg/semver\n",
+		"pkg/git/go.mod":     "module github.com/coffee377/autoctl/pkg/git\n",
+		"pkg/log/go.mod":     "module github.com/coffee377/autoctl/pkg/log\n",
+		"internal/x/main.go": "package x\n",
+	})
+	packages, err := Discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"autoctl", "git", "semver"}; !reflect.DeepEqual(scopes(packages), expected) {
+		t.Errorf("expected %v, but %v got", expected, packages)
+	}
+}
+
+func TestDiscover_Scan(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":                 "module github.com/coffee377/autoctl\n",
+		"pkg/log/go.mod":         "module github.com/coffee377/autoctl/pkg/log\n",
+		"web/package.json":       `{"name": "@autoctl/console"}`,
+		".github/x/package.json": `{}`,
+		"vendor/a/go.mod":        "module a\n",
+	})
+	packages, err := Discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"log", "console"}; !reflect.DeepEqual(scopes(packages), expected) {
+		t.Errorf("expected %v, but %v got", expected, packages)
+	}
+}
+
+func names(packages []Package) []string {
+	list := make([]string, 0, len(packages))
+	for _, p := range packages {
+		list = append(list, p.Type+":"+p.Name)
+	}
+	return list
+}
+
+func TestDiscover_Members(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"pom.xml":                   "<project><artifactId>parent</artifactId><modules><module>api</module></modules></project>",
+		"api/pom.xml":               "<project><parent><artifactId>parent</artifactId></parent><artifactId>demo-api</artifactId></project>",
+		"settings.gradle.kts":       "rootProject.name = \"demo\"\ninclude(\":apps:web\", \":lib\")\n",
+		"apps/web/build.gradle.kts": "",
+		"Cargo.toml":                "[workspace]\nmembers = [\"crates/*\"]\nexclude = [\"crates/legacy\"]\n",
+		"crates/core/Cargo.toml":    "[package]\nname = \"demo-core\"\n",
+		"crates/legacy/Cargo.toml":  "[package]\nname = \"legacy\"\n",
+	})
+	packages, err := Discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"maven:demo-api", "gradle:web", "cargo:demo-core"}; !reflect.DeepEqual(names(packages), expected) {
+		t.Errorf("expected %v, but %v got", expected, packages)
+	}
+}
+
+func TestDiscover_ScanManifests(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"pyproject.toml":                   "[tool.black]\nline-length = 100\n",
+		"services/api/pyproject.toml":      "[tool.poetry]\nname = \"api\"\n",
+		"services/worker/setup.cfg":        "[metadata]\nname = worker\n\n[options]\nname = ignored\n",
+		"deploy/charts/app/Chart.yaml":     "apiVersion: v2\nname: app-chart\n",
+		"tools/codegen/pyproject.toml":     "[project]\nname = \"codegen\"\n",
+		"tools/lint/pyproject.toml":        "[tool.ruff]\n",
+		"services/gateway/build.gradle":    "",
+		"services/gateway/settings.gradle": "rootProject.name = 'gateway'\n",
+	})
+	packages, err := Discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{"helm:app-chart", "python:api", "gradle:gateway", "python:worker", "python:codegen"}
+	if !reflect.DeepEqual(names(packages), expected) {
+		t.Errorf("expected %v, but %v got", expected, packages)
+	}
+}
+
+func TestConfig_Register(t *testing.T) {
+	defer func() { registered = nil }()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"WORKSPACE":                 "",
+		"services/api/BUILD.bazel":  "go_binary(\n    name = \"api_server\",\n)\n",
+		"services/api/package.json": `{"name": "api"}`,
+		"libs/util/BUILD":           "# no targets\n",
+	})
+	cfg := Config{Detectors: []Custom{{Kind: "bazel", Files: []string{"BUILD.bazel", "BUILD"}, Name: `name = "([^"]+)"`}}}
+	if err := cfg.Register(); err != nil {
+		t.Fatal(err)
+	}
+	packages, err := Discover(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := []string{"bazel:util", "bazel:api_server"}; !reflect.DeepEqual(names(packages), expected) {
+		t.Errorf("expected custom detector before built-in ones, but %v got", packages)
+	}
+	if err = (Config{Detectors: []Custom{{Kind: "bazel"}}}).Register(); err == nil {
+		t.Error("expected error for detector without manifests")
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	for _, c := range []struct {
+		pattern, file string
+		want          bool
+	}{
+		{"docs/**", "docs/guide/intro.md", true},
+		{"docs/**", "api/docs/intro.md", false},
+		{"*.md", "README.md", true},
+		{"*.md", "packages/a/CHANGELOG.md", true},
+		{".github/**", ".github/workflows/ci.yml", true},
+		{"migrations/*.sql", "migrations/0001_init.sql", true},
+		{"migrations/*.sql", "migrations/v2/0001_init.sql", false},
+		{"**/testdata/**", "pkg/git/testdata/log.txt", true},
+		{"/docs/**", "docs/index.md", true},
+	} {
+		if got := MatchPath(c.pattern, c.file); got != c.want {
+			t.Errorf("expected %s matching %s %v, but %v got", c.pattern, c.file, c.want, got)
+		}
+	}
+}
+
+func TestPathRules(t *testing.T) {
+	rules := PathRules{Ignore: []string{"docs/**", "*.md"}, Force: []string{"migrations/**"}}
+	if !rules.Ignored([]string{"docs/index.md", "README.md"}) || rules.Ignored([]string{"README.md", "main.go"}) || rules.Ignored(nil) {
+		t.Error("expected commits ignored only when all files are ignored")
+	}
+	if pattern, ok := rules.Forced([]string{"README.md", "migrations/README.md"}); !ok || pattern != "migrations/**" {
+		t.Errorf("expected migrations/** forcing, but %s %v got", pattern, ok)
+	}
+	if rules.Ignored([]string{"migrations/README.md"}) {
+		t.Error("expected force taking precedence over ignore")
+	}
+	if files := rules.Filter([]string{"docs/a.md", "main.go", "migrations/a.md"}); len(files) != 2 {
+		t.Errorf("expected main.go and migrations/a.md kept, but %v got", files)
+	}
+}
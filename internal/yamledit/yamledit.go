@@ -0,0 +1,228 @@
+package yamledit
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// segmentReg 路径片段，如 image、containers[0]、images[name=nginx]
+var segmentReg = regexp.MustCompile(`^([^\[\]]*)((?:\[[^\[\]]+\])*)$`)
+
+var selectorReg = regexp.MustCompile(`\[([^\[\]]+)\]`)
+
+type selector struct {
+	index int    // 序列下标，小于 0 时按 key=value 匹配
+	key   string // 匹配的字段名
+	value string // 匹配的字段值
+}
+
+type segment struct {
+	key       string
+	selectors []selector
+}
+
+// parsePath 解析以 . 分隔的路径，序列元素可以使用下标 [0] 或字段匹配 [name=nginx]
+func parsePath(path string) ([]segment, error) {
+	if path == "" {
+		return nil, fmt.Errorf("empty yaml path")
+	}
+	segments := make([]segment, 0)
+	for _, part := range splitPath(path) {
+		m := segmentReg.FindStringSubmatch(part)
+		if m == nil || (m[1] == "" && m[2] == "") {
+			return nil, fmt.Errorf("invalid yaml path %q", path)
+		}
+		seg := segment{key: m[1]}
+		for _, s := range selectorReg.FindAllStringSubmatch(m[2], -1) {
+			if k, v, ok := strings.Cut(s[1], "="); ok {
+				seg.selectors = append(seg.selectors, selector{index: -1, key: strings.TrimSpace(k), value: strings.TrimSpace(v)})
+				continue
+			}
+			index, err := strconv.Atoi(s[1])
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("invalid index %q in yaml path %q", s[1], path)
+			}
+			seg.selectors = append(seg.selectors, selector{index: index})
+		}
+		segments = append(segments, seg)
+	}
+	return segments, nil
+}
+
+// splitPath 按 . 分割路径，忽略 [] 内的 .
+func splitPath(path string) []string {
+	parts := make([]string, 0)
+	depth, start := 0, 0
+	for i, c := range path {
+		switch c {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case '.':
+			if depth == 0 {
+				parts = append(parts, path[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, path[start:])
+}
+
+// Get 获取路径对应的标量值
+func Get(data []byte, path string) (string, error) {
+	node, err := lookup(data, path)
+	if err != nil {
+		return "", err
+	}
+	return node.Value, nil
+}
+
+// Set 修改路径对应的标量值，仅替换原值所在的文本，保留注释、缩进及引号风格
+// 值未变化时返回原内容及 false
+func Set(data []byte, path, value string) ([]byte, bool, error) {
+	node, err := lookup(data, path)
+	if err != nil {
+		return nil, false, err
+	}
+	if node.Value == value {
+		return data, false, nil
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	if node.Line < 1 || node.Line > len(lines) {
+		return nil, false, fmt.Errorf("yaml path %s: invalid position", path)
+	}
+	line := []rune(string(lines[node.Line-1]))
+	start := node.Column - 1
+	end, err := tokenEnd(line, start, node)
+	if err != nil {
+		return nil, false, fmt.Errorf("yaml path %s: %w", path, err)
+	}
+	replaced := string(line[:start]) + render(value, node.Style) + string(line[end:])
+	lines[node.Line-1] = []byte(replaced)
+	return bytes.Join(lines, nil), true, nil
+}
+
+func lookup(data []byte, path string) (*yaml.Node, error) {
+	segments, err := parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	doc := &yaml.Node{}
+	if err = yaml.Unmarshal(data, doc); err != nil {
+		return nil, err
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("yaml path %s: empty document", path)
+	}
+	node := doc.Content[0]
+	for _, seg := range segments {
+		if seg.key != "" {
+			if node = mappingValue(node, seg.key); node == nil {
+				return nil, fmt.Errorf("yaml path %s: key %s not found", path, seg.key)
+			}
+		}
+		for _, sel := range seg.selectors {
+			if node = sequenceItem(node, sel); node == nil {
+				return nil, fmt.Errorf("yaml path %s: no item matches %s", path, sel)
+			}
+		}
+	}
+	if node.Kind != yaml.ScalarNode {
+		return nil, fmt.Errorf("yaml path %s: not a scalar value", path)
+	}
+	return node, nil
+}
+
+func (s selector) String() string {
+	if s.index >= 0 {
+		return fmt.Sprintf("[%d]", s.index)
+	}
+	return fmt.Sprintf("[%s=%s]", s.key, s.value)
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind == yaml.AliasNode {
+		node = node.Alias
+	}
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func sequenceItem(node *yaml.Node, sel selector) *yaml.Node {
+	if node.Kind != yaml.SequenceNode {
+		return nil
+	}
+	if sel.index >= 0 {
+		if sel.index < len(node.Content) {
+			return node.Content[sel.index]
+		}
+		return nil
+	}
+	for _, item := range node.Content {
+		if v := mappingValue(item, sel.key); v != nil && v.Value == sel.value {
+			return item
+		}
+	}
+	return nil
+}
+
+// tokenEnd 计算标量在行内的结束位置
+func tokenEnd(line []rune, start int, node *yaml.Node) (int, error) {
+	if start < 0 || start > len(line) {
+		return 0, fmt.Errorf("invalid position")
+	}
+	switch node.Style {
+	case yaml.DoubleQuotedStyle:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+			} else if line[i] == '"' {
+				return i + 1, nil
+			}
+		}
+	case yaml.SingleQuotedStyle:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1, nil
+			}
+		}
+	case 0:
+		value := []rune(node.Value)
+		if start+len(value) <= len(line) && string(line[start:start+len(value)]) == node.Value {
+			return start + len(value), nil
+		}
+	}
+	return 0, fmt.Errorf("unsupported scalar layout, only single line scalars can be updated")
+}
+
+// render 按原有引号风格输出新值，原值无引号而新值会被解析为非字符串时使用双引号
+func render(value string, style yaml.Style) string {
+	switch style {
+	case yaml.SingleQuotedStyle:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	case yaml.DoubleQuotedStyle:
+		return strconv.Quote(value)
+	}
+	var parsed interface{}
+	if err := yaml.Unmarshal([]byte(value), &parsed); err != nil || parsed != value || strings.ContainsAny(value, "#\n") {
+		return strconv.Quote(value)
+	}
+	return value
+}
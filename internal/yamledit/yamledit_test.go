@@ -0,0 +1,62 @@
+package yamledit
+
+import (
+	"strings"
+	"testing"
+)
+
+const values = `# Helm values
+image:
+  repository: ghcr.io/coffee377/autoctl
+  tag: 1.1.0 # updated by autoctl
+  pullPolicy: IfNotPresent
+sidecar: {image: "busybox", tag: '1.36'}
+`
+
+const kustomization = `images:
+  - name: nginx
+    newTag: 1.25.0
+  - name: ghcr.io/coffee377/autoctl
+    newTag: "1.1.0"
+`
+
+func TestSet(t *testing.T) {
+	tests := []struct {
+		data  string
+		path  string
+		value string
+		old   string
+		new   string
+	}{
+		{values, "image.tag", "1.2.0", "tag: 1.1.0 #", "tag: 1.2.0 #"},
+		{values, "image.tag", "1.20", "tag: 1.1.0 #", `tag: "1.20" #`},
+		{values, "sidecar.tag", "1.37", "tag: '1.36'}", "tag: '1.37'}"},
+		{kustomization, "images[name=ghcr.io/coffee377/autoctl].newTag", "1.2.0", `newTag: "1.1.0"`, `newTag: "1.2.0"`},
+		{kustomization, "images[0].newTag", "1.26.0", "newTag: 1.25.0", "newTag: 1.26.0"},
+	}
+	for _, test := range tests {
+		data, changed, err := Set([]byte(test.data), test.path, test.value)
+		if err != nil {
+			t.Fatalf("%s: %s", test.path, err)
+		}
+		expected := strings.Replace(test.data, test.old, test.new, 1)
+		if !changed || string(data) != expected {
+			t.Errorf("%s: expected\n%s\nbut got\n%s", test.path, expected, data)
+		}
+		if actual, _ := Get(data, test.path); actual != test.value {
+			t.Errorf("%s: expected %q, but %q got", test.path, test.value, actual)
+		}
+	}
+}
+
+func TestSet_Errors(t *testing.T) {
+	for _, path := range []string{"image.digest", "image", "images[name=redis].newTag", "images[5].newTag", "a..b"} {
+		if _, _, err := Set([]byte(values+kustomization), path, "x"); err == nil {
+			t.Errorf("%s: expected error, got nil", path)
+		}
+	}
+	data, changed, err := Set([]byte(values), "image.tag", "1.1.0")
+	if err != nil || changed || string(data) != values {
+		t.Errorf("expected unchanged, but changed %v with err %v", changed, err)
+	}
+}
@@ -0,0 +1,139 @@
+package yank
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/coffee377/autoctl/internal/runctx"
+	"github.com/coffee377/autoctl/pkg/log"
+)
+
+// Marker 撤回版本在变更日志标题及版本发布标题中的标记，与 keepachangelog 约定一致
+const Marker = "[YANKED]"
+
+// Notice 撤回说明，reason 为空时只说明版本已撤回
+func Notice(version, reason string) string {
+	msg := fmt.Sprintf("**Yanked**: %s should not be used", version)
+	if reason != "" {
+		msg += ", " + strings.TrimSuffix(reason, ".")
+	}
+	return msg + "."
+}
+
+// Title 在标题末尾追加撤回标记，已标记时原样返回
+func Title(title string) string {
+	if strings.Contains(title, Marker) {
+		return title
+	}
+	return strings.TrimSpace(title) + " " + Marker
+}
+
+// Changelog 在变更日志中版本标题后追加撤回标记及说明，版本不存在或已标记时 changed 为 false
+func Changelog(content, version, reason string) (string, bool) {
+	heading := regexp.MustCompile(`(?m)^## (?:\[` + regexp.QuoteMeta(version) + `\]\(.*\)|` + regexp.QuoteMeta(version) + `)(?:\s.*)?$`)
+	loc := heading.FindStringIndex(content)
+	if loc == nil {
+		return content, false
+	}
+	line := content[loc[0]:loc[1]]
+	if strings.Contains(line, Marker) {
+		return content, false
+	}
+	replaced := Title(line) + "\n\n> " + Notice(version, reason)
+	return content[:loc[0]] + replaced + content[loc[1]:], true
+}
+
+var retractReg = regexp.MustCompile(`(?m)^\s*retract\s*\(\s*$`)
+
+// Retract 在 go.mod 中追加 retract 指令，注释为撤回原因；已撤回时 changed 为 false
+func Retract(data []byte, version, reason string) ([]byte, bool) {
+	content := string(data)
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	if retracted(content, version) {
+		return data, false
+	}
+	comment := ""
+	if reason != "" {
+		comment = "// " + strings.ReplaceAll(reason, "\n", " ") + "\n"
+	}
+	if loc := retractReg.FindStringIndex(content); loc != nil {
+		// 追加到已有的 retract 块
+		end := strings.Index(content[loc[1]:], ")")
+		if end >= 0 {
+			at := loc[1] + end
+			entry := strings.ReplaceAll(comment, "// ", "\t// ") + "\t" + version + "\n"
+			return []byte(content[:at] + entry + content[at:]), true
+		}
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return []byte(content + "\n" + comment + "retract " + version + "\n"), true
+}
+
+// retracted go.mod 中是否已撤回该版本，不展开版本范围
+func retracted(content, version string) bool {
+	block := false
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.Join(strings.Fields(line), " ")
+		switch {
+		case block && line == ")":
+			block = false
+		case block && line == version:
+			return true
+		case strings.HasPrefix(line, "retract") && strings.HasSuffix(line, "("):
+			block = true
+		case line == "retract "+version:
+			return true
+		}
+	}
+	return false
+}
+
+// PackageName 读取 package.json 中的包名，私有包或没有 package.json 时返回空字符串
+func PackageName(dir string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	pkg := struct {
+		Name    string `json:"name"`
+		Private bool   `json:"private"`
+	}{}
+	if err = json.Unmarshal(data, &pkg); err != nil {
+		return "", fmt.Errorf("package.json: %w", err)
+	}
+	if pkg.Private {
+		return "", nil
+	}
+	return pkg.Name, nil
+}
+
+// Deprecate 通过 npm deprecate 弃用 npm 包的版本，安装时提示 message
+func Deprecate(dir, name, version, message string) error {
+	ctx, cancel := runctx.Command()
+	defer cancel()
+	cmd := exec.CommandContext(ctx, "npm", "deprecate", fmt.Sprintf("%s@%s", name, version), message)
+	cmd.Dir = dir
+	start := time.Now()
+	out, err := cmd.CombinedOutput()
+	log.TraceCommand(cmd, start, err)
+	if err = runctx.Wrap(ctx, err); err != nil {
+		return fmt.Errorf("npm deprecate: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
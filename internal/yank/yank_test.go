@@ -0,0 +1,43 @@
+package yank
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestChangelog(t *testing.T) {
+	content := "# Changelog\n\n## [1.3.0](https://example.com/compare) (2024-02-01)\n\n* a\n\n## 1.2.0 (2024-01-01)\n\n* b\n"
+	updated, changed := Changelog(content, "1.2.0", "data loss on upgrade")
+	if !changed || !strings.Contains(updated, "## 1.2.0 (2024-01-01) [YANKED]\n\n> **Yanked**: 1.2.0 should not be used, data loss on upgrade.\n\n* b") {
+		t.Errorf("unexpected changelog\n%s", updated)
+	}
+	if _, changed = Changelog(updated, "1.2.0", "again"); changed {
+		t.Error("expected yanked version unchanged")
+	}
+	if updated, changed = Changelog(content, "1.3.0", ""); !changed || !strings.Contains(updated, "(2024-02-01) [YANKED]") {
+		t.Errorf("expected linked heading yanked\n%s", updated)
+	}
+	if _, changed = Changelog(content, "1.1.0", ""); changed {
+		t.Error("expected missing version unchanged")
+	}
+}
+
+func TestRetract(t *testing.T) {
+	gomod := "module example.com/app\n\ngo 1.21\n"
+	data, changed := Retract([]byte(gomod), "1.2.0", "data loss on upgrade")
+	if !changed || !strings.HasSuffix(string(data), "\n// data loss on upgrade\nretract v1.2.0\n") {
+		t.Errorf("unexpected go.mod\n%s", data)
+	}
+	if _, changed = Retract(data, "v1.2.0", ""); changed {
+		t.Error("expected retracted version unchanged")
+	}
+
+	block := "module example.com/app\n\nretract (\n\tv1.0.0 // broken\n)\n"
+	data, changed = Retract([]byte(block), "1.2.0", "")
+	if !changed || string(data) != "module example.com/app\n\nretract (\n\tv1.0.0 // broken\n\tv1.2.0\n)\n" {
+		t.Errorf("expected version added to the retract block\n%s", data)
+	}
+	if _, changed = Retract([]byte(block), "1.0.0", ""); changed {
+		t.Error("expected version in the retract block unchanged")
+	}
+}
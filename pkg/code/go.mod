@@ -1,3 +1,3 @@
 module github.com/coffee377/autoctl/pkg/code
 
-go 1.18
+go 1.20
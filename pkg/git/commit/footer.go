@@ -1,6 +1,7 @@
 package git
 
 import (
+	"regexp"
 	"strings"
 )
 
@@ -10,11 +11,24 @@ type Footnote struct {
 	Value string `json:"value"`
 }
 
+var footnoteReg = regexp.MustCompile("(?s)^(BREAKING[ -]CHANGE|[\\w-]+)(?:: | #)(.*)$")
+
+// IsFootnote 判断是否为脚注行 <token>: <value> 或 <token> #<value>
+func IsFootnote(raw string) bool {
+	return footnoteReg.MatchString(raw)
+}
+
 func NewFootnote(raw string) Footnote {
-	match := strings.Split(raw, ": ")
+	match := footnoteReg.FindStringSubmatch(raw)
+	if match == nil {
+		return Footnote{Value: raw}
+	}
 	footnote := Footnote{
-		Token: match[0],
-		Value: match[1],
+		Token: match[1],
+		Value: match[2],
+	}
+	if footnote.Token == "BREAKING-CHANGE" {
+		footnote.Token = "BREAKING CHANGE"
 	}
 	return footnote
 }
@@ -47,6 +61,9 @@ func CommitMessageFooterFromNotes(notes []string) *MessageFooter {
 
 func (f *MessageFooter) AddFooterItem(rawItem string) *MessageFooter {
 	footerItem := NewFootnote(rawItem)
+	if footerItem.Token == "" {
+		return f
+	}
 	if footerItem.Token == "Closes" || footerItem.Token == "Refs" {
 		f.Closes = strings.Split(footerItem.Value, ",")
 	} else if footerItem.Token == "BREAKING CHANGE" {
@@ -14,10 +14,15 @@ type MessageHeader struct {
 	Semver      string `json:"semver,omitempty"`      // 语义化版本
 }
 
+var headerReg = regexp.MustCompile("^(:\\w+:|\\w+)(\\(([\\w\\-./]*)\\))?(!)?:? (.+)$")
+
 func CommitMessageHeaderFromTitle(title string) *MessageHeader {
 	h := new(MessageHeader)
-	reg := regexp.MustCompile("^(:?\\w+:?)(\\((\\w*)\\))?(!)?:? (.+)$")
-	match := reg.FindStringSubmatch(strings.Trim(title, " "))
+	match := headerReg.FindStringSubmatch(strings.Trim(title, " "))
+	// 不符合约定式提交规范时，整个标题作为描述
+	if match == nil {
+		return h.setDescription(strings.TrimSpace(title))
+	}
 
 	h.setType(match[1])
 
@@ -16,6 +16,8 @@ type CommitMessage struct {
 
 var BlankLine = "[\r\n]\n"
 
+var blankLineReg = regexp.MustCompile(BlankLine)
+
 // NewCommitMessage creates an instance of CommitMessage.
 func NewCommitMessage(raw *string) *CommitMessage {
 	msg := &CommitMessage{}
@@ -32,18 +34,20 @@ func (message *CommitMessage) FromRaw(raw *string) *CommitMessage {
 	)
 
 	// 按空行进行分割
-	splits := regexp.MustCompile(BlankLine).Split(*raw, -1)
+	splits := blankLineReg.Split(*raw, -1)
 	pos := len(splits) - 1
 	// 获取标题
 	title = splits[:1][0]
 
 	if len(splits) > 1 {
-		// todo 要验证最后一项是否真的是脚注，可能是长描述
-		// 获取脚注
-		footers = strings.Split(splits[pos:][0], "\n")
-
-		// 获取长描述
-		longDescription = splits[1:pos]
+		// 最后一段的首行为脚注时作为脚注，否则为长描述
+		last := strings.Split(strings.TrimRight(splits[pos], "\r\n"), "\n")
+		if IsFootnote(last[0]) {
+			footers = mergeFootnotes(last)
+			longDescription = splits[1:pos]
+		} else {
+			longDescription = splits[1:]
+		}
 	}
 
 	message.dealHeader(title)
@@ -70,3 +74,16 @@ func (message *CommitMessage) dealFooter(footers []string) {
 		message.Footer = footer
 	}
 }
+
+// 脚注的值可以包含换行，非脚注行追加到上一个脚注
+func mergeFootnotes(lines []string) []string {
+	res := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if IsFootnote(line) || len(res) == 0 {
+			res = append(res, line)
+		} else {
+			res[len(res)-1] += "\n" + line
+		}
+	}
+	return res
+}
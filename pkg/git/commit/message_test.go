@@ -1,6 +1,7 @@
 package git
 
 import (
+	"github.com/stretchr/testify/assert"
 	"testing"
 )
 
@@ -23,3 +24,29 @@ func TestCommitMessage(t *testing.T) {
 		//fmt.Println("v%", message)
 	})
 }
+
+func TestCommitMessage_Footnotes(t *testing.T) {
+
+	t.Run("多行脚注", func(t *testing.T) {
+		raw := "feat: drop node 6\n\nBREAKING CHANGE: use JavaScript features\nnot available in Node 6.\nReviewed-by: Z"
+		message := NewCommitMessage(&raw)
+		assert.Equal(t, "use JavaScript features\nnot available in Node 6.", message.Footer.BreakingChange)
+		assert.Equal(t, []Footnote{{Token: "Reviewed-by", Value: "Z"}}, message.Footer.Items)
+	})
+
+	t.Run("最后一段不是脚注", func(t *testing.T) {
+		raw := "build(deps-dev): bump lodash from 4.17.20 to 4.17.21\n\nBumps lodash from 4.17.20 to 4.17.21.\n\n---\nupdated-dependencies:\n- dependency-name: lodash"
+		message := NewCommitMessage(&raw)
+		assert.Equal(t, "build", message.Header.Type)
+		assert.Equal(t, "deps-dev", message.Header.Scope)
+		assert.Nil(t, message.Footer)
+		assert.Len(t, message.Body.Description, 2)
+	})
+
+	t.Run("不符合规范的标题", func(t *testing.T) {
+		raw := "wip"
+		message := NewCommitMessage(&raw)
+		assert.Equal(t, "", message.Header.Type)
+		assert.Equal(t, raw, message.Header.Description)
+	})
+}
@@ -59,7 +59,9 @@ func (record *CommitRecord) From(formattedLog []byte) *CommitRecord {
 	//if !r.Match(message) {
 	//	log.Fatal("commit %s 提交格式不符合规范", r.Commit)
 	//}
-	for i, v := range bytes.Split(formattedLog, []byte(FormatSep)) {
+	// 提交信息中可能包含分隔符，只按字段数量分割
+	fields := reflect.ValueOf(record).Elem().NumField() - 2
+	for i, v := range bytes.SplitN(formattedLog, []byte(FormatSep), fields) {
 		record.setProps(i, string(v))
 	}
 	// 3. 时间戳类型转换
@@ -0,0 +1,85 @@
+package git
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"strconv"
+)
+
+const (
+	StreamFieldSep  = '\x1f' // 字段分隔符，不会出现在提交信息中
+	StreamRecordSep = '\x1e' // 记录分隔符
+	// StreamFormat 流式解析使用的 git log 格式化模板，%P 用于过滤合并提交
+	StreamFormat = "%H%x1f%P%x1f%at%x1f%ad%x1f%an%x1f%ae%x1f%B%x1e"
+
+	// MaxRecordSize 单条提交记录的最大字节数，限制流式解析占用的内存
+	MaxRecordSize = 16 << 20
+)
+
+// ErrStop 回调函数返回该错误时停止读取，不视为失败
+var ErrStop = errors.New("stop reading commit records")
+
+// StreamRecord 流式读取的提交记录
+type StreamRecord struct {
+	*CommitRecord
+	Parents []string // 父提交，合并提交有多个父提交
+}
+
+// ReadRecords 逐条解析 StreamFormat 格式的 git log 输出，内存占用只与单条提交记录的大小相关；
+// fn 返回 ErrStop 时停止读取并返回 nil
+func ReadRecords(r io.Reader, fn func(record *StreamRecord) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64<<10), MaxRecordSize)
+	scanner.Split(splitRecord)
+	for scanner.Scan() {
+		data := bytes.TrimLeft(scanner.Bytes(), "\n")
+		if len(data) == 0 {
+			continue
+		}
+		record, err := parseStreamRecord(data)
+		if err != nil {
+			return err
+		}
+		if err = fn(record); err != nil {
+			if errors.Is(err, ErrStop) {
+				return nil
+			}
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func splitRecord(data []byte, atEOF bool) (int, []byte, error) {
+	if i := bytes.IndexByte(data, StreamRecordSep); i >= 0 {
+		return i + 1, data[:i], nil
+	}
+	if atEOF && len(data) > 0 {
+		return len(data), data, nil
+	}
+	return 0, nil, nil
+}
+
+func parseStreamRecord(data []byte) (*StreamRecord, error) {
+	fields := bytes.SplitN(data, []byte{StreamFieldSep}, 7)
+	if len(fields) != 7 {
+		return nil, errors.New("malformed commit record")
+	}
+	record := &CommitRecord{
+		Commit:     string(fields[0]),
+		Time:       string(fields[2]),
+		Date:       string(fields[3]),
+		Author:     string(fields[4]),
+		Email:      string(fields[5]),
+		RawMessage: string(bytes.TrimRight(fields[6], "\n")),
+	}
+	record.Timestamp, _ = strconv.Atoi(record.Time)
+	record.Message = NewCommitMessage(&record.RawMessage)
+	var parents []string
+	for _, p := range bytes.Fields(fields[1]) {
+		parents = append(parents, string(p))
+	}
+	return &StreamRecord{CommitRecord: record, Parents: parents}, nil
+}
@@ -0,0 +1,76 @@
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func streamLog(n int) []byte {
+	var buf bytes.Buffer
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		_, _ = fmt.Fprintf(&buf, "%040d\x1f%040d\x1f1653057941\x1f2022/05/20 22:45:41\x1fWuYujie\x1fcoffee377@dingtalk.com\x1ffix(log): prevent racing %d\n\nSee; #123\n\x1e", i, i+1, i)
+	}
+	return buf.Bytes()
+}
+
+func TestReadRecords(t *testing.T) {
+	data := "a\x1fp1 p2\x1f1653057941\x1f2022/05/20 22:45:41\x1fWuYujie\x1fcoffee377@dingtalk.com\x1fMerge branch 'dev'\n\x1e\n" +
+		"b\x1fa\x1f1653057942\x1f2022/05/20 22:45:42\x1fWuYujie\x1fcoffee377@dingtalk.com\x1ffeat!: drop v1; v2 only\n\nBREAKING CHANGE: v1 removed\n\x1e"
+	records := make([]*StreamRecord, 0)
+	err := ReadRecords(strings.NewReader(data), func(record *StreamRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 2 || len(records[0].Parents) != 2 || records[1].Commit != "b" || records[1].Timestamp != 1653057942 {
+		t.Fatalf("unexpected records %+v", records)
+	}
+	if records[1].RawMessage != "feat!: drop v1; v2 only\n\nBREAKING CHANGE: v1 removed" || !records[1].Message.Header.Broken {
+		t.Errorf("unexpected message %q", records[1].RawMessage)
+	}
+
+	count := 0
+	err = ReadRecords(bytes.NewReader(streamLog(10)), func(record *StreamRecord) error {
+		if count++; count == 3 {
+			return ErrStop
+		}
+		return nil
+	})
+	if err != nil || count != 3 {
+		t.Errorf("expected stopped after 3 records, but %d %v got", count, err)
+	}
+	if err = ReadRecords(strings.NewReader("a\x1fb\x1e"), func(*StreamRecord) error { return nil }); err == nil {
+		t.Error("expected malformed record error")
+	}
+}
+
+func BenchmarkReadRecords(b *testing.B) {
+	data := streamLog(10000)
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := ReadRecords(bytes.NewReader(data), func(*StreamRecord) error { return nil }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkReadRecords_Stop 找到第一条记录即停止，耗时与历史长度无关
+func BenchmarkReadRecords_Stop(b *testing.B) {
+	data := streamLog(10000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := ReadRecords(bytes.NewReader(data), func(*StreamRecord) error { return ErrStop }); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
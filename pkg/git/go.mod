@@ -1,6 +1,6 @@
 module github.com/coffee377/autoctl/pkg/git
 
-go 1.18
+go 1.20
 
 require (
 	github.com/spf13/cobra v1.7.0
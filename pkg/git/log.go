@@ -0,0 +1,198 @@
+package git
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/coffee377/autoctl/pkg/git/commit"
+)
+
+// Logs 查询提交记录（不含合并提交），revRange 如 v1.0.0..HEAD，为空时查询 HEAD 的全部提交
+func (plus *Plus) Logs(revRange string, paths ...string) ([]*git.CommitRecord, error) {
+	records := make([]*git.CommitRecord, 0)
+	err := plus.WalkLogs(revRange, func(record *git.CommitRecord) error {
+		records = append(records, record)
+		return nil
+	}, paths...)
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// WalkLogs 流式读取提交记录（不含合并提交），按 git log 的顺序逐条交给 fn，
+// fn 返回 git.ErrStop 时停止读取，适用于提交数量巨大的仓库
+func (plus *Plus) WalkLogs(revRange string, fn func(record *git.CommitRecord) error, paths ...string) error {
+	args := logArgs("--no-merges")
+	if revRange != "" {
+		args = append(args, revRange)
+	}
+	if len(paths) > 0 {
+		args = append(args, "--")
+		args = append(args, paths...)
+	}
+	return plus.Stream(func(r io.Reader) error {
+		return git.ReadRecords(r, func(record *git.StreamRecord) error {
+			return fn(record.CommitRecord)
+		})
+	}, args...)
+}
+
+// WalkLogsSinceTag 从 HEAD 开始流式读取提交记录（不含合并提交），遇到第一个匹配 patterns 的标签指向的提交时停止，
+// 返回该标签，未遇到标签时读取全部提交并返回空字符串；无需预先计算 tag..HEAD 范围，适用于线性的提交历史
+func (plus *Plus) WalkLogsSinceTag(patterns []string, fn func(record *git.CommitRecord) error) (string, error) {
+	tagged, err := plus.TaggedCommits(patterns...)
+	if err != nil {
+		return "", err
+	}
+	tag := ""
+	err = plus.Stream(func(r io.Reader) error {
+		return git.ReadRecords(r, func(record *git.StreamRecord) error {
+			if t, ok := tagged[record.Commit]; ok {
+				tag = t
+				return git.ErrStop
+			}
+			if len(record.Parents) > 1 {
+				return nil
+			}
+			return fn(record.CommitRecord)
+		})
+	}, logArgs("HEAD")...)
+	return tag, err
+}
+
+// TaggedCommits 匹配 patterns 的标签指向的提交，附注标签解析为其指向的提交，键为提交哈希
+func (plus *Plus) TaggedCommits(patterns ...string) (map[string]string, error) {
+	args := []string{"for-each-ref", "--format=%(objectname) %(*objectname) %(refname:short)"}
+	if len(patterns) == 0 {
+		args = append(args, "refs/tags")
+	}
+	for _, p := range patterns {
+		args = append(args, "refs/tags/"+p)
+	}
+	output, err := plus.Run(args...)
+	if err != nil {
+		return nil, err
+	}
+	tagged := map[string]string{}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		switch len(fields) {
+		case 2:
+			tagged[fields[0]] = fields[1]
+		case 3:
+			tagged[fields[1]] = fields[2]
+		}
+	}
+	return tagged, nil
+}
+
+func logArgs(args ...string) []string {
+	return append([]string{"log", "--date=format:%Y/%m/%d %H:%M:%S", "--pretty=format:" + git.StreamFormat}, args...)
+}
+
+// Tags 列出匹配的标签
+func (plus *Plus) Tags(patterns ...string) ([]string, error) {
+	args := append([]string{"tag", "-l"}, patterns...)
+	output, err := plus.Run(args...)
+	if err != nil {
+		return nil, err
+	}
+	tags := make([]string, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			tags = append(tags, line)
+		}
+	}
+	return tags, nil
+}
+
+// Show 读取文件在指定引用中的内容，文件不存在时返回 nil
+func (plus *Plus) Show(ref, path string) ([]byte, error) {
+	if _, err := plus.Run("cat-file", "-e", ref+":"+path); err != nil {
+		return nil, nil
+	}
+	return plus.Run("show", ref+":"+path)
+}
+
+// ListFiles 列出引用中的文件，paths 相对于仓库根目录，为目录时递归列出目录中的文件
+func (plus *Plus) ListFiles(ref string, paths ...string) ([]string, error) {
+	output, err := plus.Run(append([]string{"ls-tree", "-r", "--name-only", "--full-tree", ref, "--"}, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// ChangedFiles 列出两个引用之间变更的文件，paths 支持 pathspec 通配符
+func (plus *Plus) ChangedFiles(from, to string, paths ...string) ([]string, error) {
+	return plus.diffNames([]string{"diff", "--name-only", from, to, "--"}, paths)
+}
+
+// AddedFiles 列出两个引用之间新增的文件，paths 支持 pathspec 通配符
+func (plus *Plus) AddedFiles(from, to string, paths ...string) ([]string, error) {
+	return plus.diffNames([]string{"diff", "--name-only", "--diff-filter=A", from, to, "--"}, paths)
+}
+
+func (plus *Plus) diffNames(args, paths []string) ([]string, error) {
+	output, err := plus.Run(append(args, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			files = append(files, line)
+		}
+	}
+	return files, nil
+}
+
+// CountCommits 统计提交数量，paths 不为空时只统计修改了这些路径的提交
+func (plus *Plus) CountCommits(revRange string, paths ...string) (int, error) {
+	args := append([]string{"rev-list", "--count", revRange, "--"}, paths...)
+	output, err := plus.Run(args...)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(strings.TrimSpace(string(output)))
+}
+
+// CommitFiles 列出范围内各提交修改的文件，合并提交没有文件
+func (plus *Plus) CommitFiles(revRange string) (map[string][]string, error) {
+	output, err := plus.Run("log", "--format=%x00%H", "--name-only", revRange, "--")
+	if err != nil {
+		return nil, err
+	}
+	files := map[string][]string{}
+	for _, block := range strings.Split(string(output), "\x00") {
+		lines := strings.Split(strings.TrimSpace(block), "\n")
+		if lines[0] == "" {
+			continue
+		}
+		list := make([]string, 0, len(lines)-1)
+		for _, line := range lines[1:] {
+			if line = strings.TrimSpace(line); line != "" {
+				list = append(list, line)
+			}
+		}
+		files[lines[0]] = list
+	}
+	return files, nil
+}
+
+// FirstParents 按第一父提交从新到旧列出范围内的提交，即合入目标分支的合并提交或直接提交
+func (plus *Plus) FirstParents(revRange string) ([]string, error) {
+	output, err := plus.Run("rev-list", "--first-parent", revRange, "--")
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(output)), nil
+}
@@ -0,0 +1,51 @@
+package git
+
+import (
+	"strings"
+)
+
+// Note 读取对象在 notes 引用中的注释，不存在时返回空字符串
+func (plus *Plus) Note(ref, object string) (string, error) {
+	if _, err := plus.Run("rev-parse", "--verify", "--quiet", ref); err != nil {
+		return "", nil
+	}
+	output, err := plus.Run("notes", "--ref", ref, "list", object)
+	if err != nil || len(strings.TrimSpace(string(output))) == 0 {
+		// 对象没有注释时 git notes list 以非零状态退出
+		return "", nil
+	}
+	output, err = plus.Run("notes", "--ref", ref, "show", object)
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
+
+// AddNote 在 notes 引用中为对象添加注释，已有注释时覆盖，返回 notes 引用指向的新提交
+func (plus *Plus) AddNote(ref, object, message string) (string, error) {
+	if _, err := plus.Run("notes", "--ref", ref, "add", "--force", "-m", message, object); err != nil {
+		return "", err
+	}
+	output, err := plus.Run("rev-parse", ref)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SyncRef 将本地引用更新为远程仓库中的引用，返回其指向的对象；远程引用不存在时删除本地引用并返回空字符串
+func (plus *Plus) SyncRef(remote, ref string) (string, error) {
+	object, err := plus.RemoteRef(remote, ref)
+	if err != nil {
+		return "", err
+	}
+	if object == "" {
+		_, _ = plus.Run("update-ref", "-d", ref)
+		return "", nil
+	}
+	if object, err = plus.FetchRef(remote, ref); err != nil {
+		return "", err
+	}
+	_, err = plus.Run("update-ref", ref, object)
+	return object, err
+}
@@ -2,16 +2,57 @@ package git
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/coffee377/autoctl/pkg/git/commit"
+	"io"
 
 	"github.com/coffee377/autoctl/pkg/log"
 	"github.com/spf13/cobra"
 	"os/exec"
 	"strings"
+	"sync"
+	"time"
 )
 
+var (
+	ctxMu   sync.RWMutex
+	baseCtx = context.Background()
+	timeout time.Duration
+)
+
+// SetContext 设置 git 命令的上下文及单个命令的超时时间，ctx 取消或超时时终止进行中的命令，d 为 0 时不限制
+func SetContext(ctx context.Context, d time.Duration) {
+	ctxMu.Lock()
+	defer ctxMu.Unlock()
+	baseCtx, timeout = ctx, d
+}
+
+func commandContext() (context.Context, context.CancelFunc) {
+	ctxMu.RLock()
+	defer ctxMu.RUnlock()
+	if timeout > 0 {
+		return context.WithTimeout(baseCtx, timeout)
+	}
+	return context.WithCancel(baseCtx)
+}
+
+// cancelled 命令因上下文取消或超时被终止时说明原因
+func cancelled(ctx context.Context, err error) error {
+	ctxMu.RLock()
+	defer ctxMu.RUnlock()
+	switch {
+	case err == nil || ctx.Err() == nil:
+		return err
+	case errors.Is(ctx.Err(), context.DeadlineExceeded) && timeout > 0:
+		return fmt.Errorf("%w: timed out after %s", err, timeout)
+	default:
+		return fmt.Errorf("%w: %v", err, ctx.Err())
+	}
+}
+
 type Plus struct {
 	Cwd     string
 	Verbose bool
@@ -22,19 +63,97 @@ type Plus struct {
 	cmd     *cobra.Command
 }
 
-// Exec 执行 git 命令
+// Exec 执行 git 命令，执行失败时直接退出
 func (plus *Plus) Exec(args ...string) []byte {
-	command := exec.Command("git", args...)
+	output, err := plus.Run(args...)
+	if err != nil {
+		log.Fatal("%s", err)
+	}
+	return output
+}
+
+// Run 执行 git 命令并返回标准输出，执行失败时错误信息包含标准错误输出
+func (plus *Plus) Run(args ...string) ([]byte, error) {
+	ctx, cancel := commandContext()
+	defer cancel()
+	command := exec.CommandContext(ctx, "git", args...)
 	command.Dir = plus.Cwd
 	if plus.Verbose {
 		n := strings.SplitN(command.String(), " ", 2)
 		log.Debug("git %s", n[1])
 	}
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+	start := time.Now()
 	output, err := command.Output()
+	log.TraceCommand(command, start, err)
+	if err = cancelled(ctx, err); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return output, fmt.Errorf("git %s: %w: %s", args[0], err, msg)
+		}
+		return output, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return output, nil
+}
+
+// Stream 执行 git 命令并将标准输出交给 fn 逐步读取，不在内存中保留完整输出；
+// fn 未读取到末尾即返回时终止命令，视为正常结束
+func (plus *Plus) Stream(fn func(r io.Reader) error, args ...string) error {
+	ctx, cancel := commandContext()
+	defer cancel()
+	command := exec.CommandContext(ctx, "git", args...)
+	command.Dir = plus.Cwd
+	if plus.Verbose {
+		n := strings.SplitN(command.String(), " ", 2)
+		log.Debug("git %s", n[1])
+	}
+	var stderr bytes.Buffer
+	command.Stderr = &stderr
+	stdout, err := command.StdoutPipe()
 	if err != nil {
-		log.Fatal("%s", err)
+		return err
 	}
-	return output
+	start := time.Now()
+	if err = command.Start(); err != nil {
+		log.TraceCommand(command, start, err)
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+	reader := &eofReader{r: stdout}
+	fnErr := fn(reader)
+	stopped := !reader.eof
+	if fnErr != nil || stopped {
+		_ = command.Process.Kill()
+	}
+	err = command.Wait()
+	if stopped {
+		err = nil
+	}
+	log.TraceCommand(command, start, err)
+	err = cancelled(ctx, err)
+	switch {
+	case fnErr != nil:
+		return fnErr
+	case err == nil:
+		return nil
+	case strings.TrimSpace(stderr.String()) != "":
+		return fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	default:
+		return fmt.Errorf("git %s: %w", args[0], err)
+	}
+}
+
+// eofReader 记录是否已读取到末尾
+type eofReader struct {
+	r   io.Reader
+	eof bool
+}
+
+func (e *eofReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if err == io.EOF {
+		e.eof = true
+	}
+	return n, err
 }
 
 // FetchAll 拉取所有远程仓库的最新内容到本地
@@ -0,0 +1,59 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CommitTree 基于当前提交的目录树创建一个没有父提交的提交对象，不改变任何分支
+func (plus *Plus) CommitTree(message string) (string, error) {
+	output, err := plus.Run("commit-tree", "HEAD^{tree}", "-m", message)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// RemoteRef 获取远程仓库中引用指向的对象，引用不存在时返回空字符串
+func (plus *Plus) RemoteRef(remote, ref string) (string, error) {
+	output, err := plus.Run("ls-remote", remote, ref)
+	if err != nil {
+		return "", err
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if fields := strings.Fields(line); len(fields) == 2 && fields[1] == ref {
+			return fields[0], nil
+		}
+	}
+	return "", nil
+}
+
+// FetchRef 拉取远程仓库中的引用，返回其指向的对象
+func (plus *Plus) FetchRef(remote, ref string) (string, error) {
+	if _, err := plus.Run("fetch", "--quiet", "--no-tags", remote, ref); err != nil {
+		return "", err
+	}
+	output, err := plus.Run("rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CompareAndSwapRef 远程引用仍指向 expect 时将其更新为 object，expect 为空表示引用必须不存在，object 为空表示删除引用
+func (plus *Plus) CompareAndSwapRef(remote, ref, expect, object string) error {
+	_, err := plus.Run("push", "--quiet", fmt.Sprintf("--force-with-lease=%s:%s", ref, expect), remote, object+":"+ref)
+	return err
+}
+
+// FetchRemoteTags 拉取远程仓库的所有标签
+func (plus *Plus) FetchRemoteTags(remote string) error {
+	_, err := plus.Run("fetch", "--quiet", "--tags", remote)
+	return err
+}
+
+// IsAncestor ancestor 是否为 descendant 的祖先提交，两者相同时同样返回 true
+func (plus *Plus) IsAncestor(ancestor, descendant string) bool {
+	_, err := plus.Run("merge-base", "--is-ancestor", ancestor, descendant)
+	return err == nil
+}
@@ -0,0 +1,53 @@
+package git
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Remotes 列出所有远程仓库名称
+func (plus *Plus) Remotes() ([]string, error) {
+	output, err := plus.Run("remote")
+	if err != nil {
+		return nil, err
+	}
+	remotes := make([]string, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			remotes = append(remotes, line)
+		}
+	}
+	return remotes, nil
+}
+
+// RemoteURL 获取远程仓库的推送地址
+func (plus *Plus) RemoteURL(remote string) (string, error) {
+	output, err := plus.Run("remote", "get-url", "--push", remote)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// VerifyPush 通过 push --dry-run 验证是否有权限向远程仓库推送 refspec，不会产生实际变更
+func (plus *Plus) VerifyPush(remote, refspec string) error {
+	if _, err := plus.Run("push", "--dry-run", "--porcelain", remote, refspec); err != nil {
+		return fmt.Errorf("no permission to push to %s: %w", remote, err)
+	}
+	return nil
+}
+
+// DeleteRemoteTag 删除远程仓库的标签
+func (plus *Plus) DeleteRemoteTag(remote, name string) error {
+	_, err := plus.Run("push", remote, ":refs/tags/"+name)
+	return err
+}
+
+// RemoteTagExists 判断远程仓库中是否存在标签
+func (plus *Plus) RemoteTagExists(remote, name string) (bool, error) {
+	output, err := plus.Run("ls-remote", "--tags", remote, "refs/tags/"+name)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
@@ -0,0 +1,60 @@
+package git
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Submodule 子模块及父仓库中记录的提交
+type Submodule struct {
+	Path        string // 相对于父仓库工作区根目录的路径
+	Commit      string // 父仓库记录的提交
+	Initialized bool   // 是否已初始化
+}
+
+// Submodules 列出父仓库的子模块，不递归
+func (plus *Plus) Submodules() ([]Submodule, error) {
+	top, err := plus.TopLevel()
+	if err != nil {
+		return nil, err
+	}
+	root := &Plus{Cwd: top, Verbose: plus.Verbose}
+	output, err := root.Run("submodule", "status")
+	if err != nil {
+		return nil, err
+	}
+	var res []Submodule
+	for _, line := range strings.Split(string(output), "\n") {
+		// 首字符为状态：空格 已检出、- 未初始化、+ 检出的提交与记录的不同、U 合并冲突
+		if len(line) < 2 {
+			continue
+		}
+		fields := strings.Fields(line[1:])
+		if len(fields) < 2 {
+			continue
+		}
+		res = append(res, Submodule{Path: filepath.ToSlash(fields[1]), Commit: fields[0], Initialized: line[0] != '-'})
+	}
+	return res, nil
+}
+
+// SubmodulePlus 在子模块中执行 git 命令
+func (plus *Plus) SubmodulePlus(path string) (*Plus, error) {
+	top, err := plus.TopLevel()
+	if err != nil {
+		return nil, err
+	}
+	return &Plus{Cwd: filepath.Join(top, filepath.FromSlash(path)), Verbose: plus.Verbose}, nil
+}
+
+// UpdateSubmodules 将子模块更新为其远程跟踪分支的最新提交（未初始化的先初始化），paths 为空时更新全部子模块，
+// 父仓库中的记录需要另行提交
+func (plus *Plus) UpdateSubmodules(paths ...string) error {
+	top, err := plus.TopLevel()
+	if err != nil {
+		return err
+	}
+	root := &Plus{Cwd: top, Verbose: plus.Verbose}
+	_, err = root.Run(append([]string{"submodule", "update", "--init", "--remote", "--"}, paths...)...)
+	return err
+}
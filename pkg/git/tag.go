@@ -0,0 +1,169 @@
+package git
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LatestTag 获取当前提交可达的最近一次标签，patterns 为 --match 匹配模式
+func (plus *Plus) LatestTag(patterns ...string) (string, error) {
+	args := []string{"describe", "--tags", "--abbrev=0"}
+	for _, p := range patterns {
+		args = append(args, "--match", p)
+	}
+	output, err := plus.Run(args...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// TagExists 判断标签是否存在
+func (plus *Plus) TagExists(name string) bool {
+	_, err := plus.Run("rev-parse", "-q", "--verify", "refs/tags/"+name)
+	return err == nil
+}
+
+// TagDates 获取标签的创建日期（YYYY-MM-DD），附注标签为打标签的日期，轻量标签为提交日期
+func (plus *Plus) TagDates(patterns ...string) (map[string]string, error) {
+	args := []string{"for-each-ref", "--format=%(refname:strip=2)%09%(creatordate:short)"}
+	if len(patterns) == 0 {
+		args = append(args, "refs/tags")
+	}
+	for _, p := range patterns {
+		args = append(args, "refs/tags/"+p)
+	}
+	output, err := plus.Run(args...)
+	if err != nil {
+		return nil, err
+	}
+	dates := map[string]string{}
+	for _, line := range strings.Split(string(output), "\n") {
+		if name, date, ok := strings.Cut(strings.TrimSpace(line), "\t"); ok {
+			dates[name] = date
+		}
+	}
+	return dates, nil
+}
+
+// TagRef 标签引用信息
+type TagRef struct {
+	Name      string
+	Commit    string    // 标签指向的提交
+	Date      time.Time // 附注标签为打标签的时间，轻量标签为提交时间
+	Annotated bool      // 是否为附注标签
+	Signed    bool      // 附注标签是否包含签名
+}
+
+// TagRefs 列出匹配的标签及其元数据
+func (plus *Plus) TagRefs(patterns ...string) ([]TagRef, error) {
+	args := []string{"for-each-ref", "--format=%(refname:strip=2)%09%(objecttype)%09%(creatordate:unix)%09%(objectname)%09%(*objectname)%09%(if)%(contents:signature)%(then)signed%(end)"}
+	if len(patterns) == 0 {
+		args = append(args, "refs/tags")
+	}
+	for _, p := range patterns {
+		args = append(args, "refs/tags/"+p)
+	}
+	output, err := plus.Run(args...)
+	if err != nil {
+		return nil, err
+	}
+	refs := make([]TagRef, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 6 {
+			continue
+		}
+		ref := TagRef{Name: fields[0], Commit: fields[3], Annotated: fields[1] == "tag", Signed: fields[5] == "signed"}
+		if ref.Annotated {
+			ref.Commit = fields[4]
+		}
+		if ts, err := strconv.ParseInt(fields[2], 10, 64); err == nil {
+			ref.Date = time.Unix(ts, 0)
+		}
+		refs = append(refs, ref)
+	}
+	return refs, nil
+}
+
+// VerifyTag 校验标签签名
+func (plus *Plus) VerifyTag(name string) error {
+	_, err := plus.Run("verify-tag", name)
+	return err
+}
+
+// CreateTag 创建附注标签，message 为空时创建轻量标签
+func (plus *Plus) CreateTag(name, message string) error {
+	if plus.TagExists(name) {
+		return fmt.Errorf("tag %s already exists", name)
+	}
+	args := []string{"tag"}
+	if message != "" {
+		args = append(args, "-a", name, "-m", message)
+	} else {
+		args = append(args, name)
+	}
+	_, err := plus.Run(args...)
+	return err
+}
+
+// DeleteTag 删除本地标签
+func (plus *Plus) DeleteTag(name string) error {
+	_, err := plus.Run("tag", "-d", name)
+	return err
+}
+
+// PushTag 推送标签到远程仓库
+func (plus *Plus) PushTag(remote, name string) error {
+	_, err := plus.Run("push", remote, "refs/tags/"+name)
+	return err
+}
+
+// MoveTag 创建或移动轻量标签到 target 指向的提交，用于 v1、stable 等浮动标签
+func (plus *Plus) MoveTag(name, target string) error {
+	_, err := plus.Run("tag", "-f", name, target+"^{commit}")
+	return err
+}
+
+// ForcePushTags 以原子方式强制推送多个标签，任一标签被拒绝时远程仓库中的标签均不更新
+func (plus *Plus) ForcePushTags(remote string, names ...string) error {
+	args := []string{"push", "--atomic", "--force", remote}
+	for _, name := range names {
+		args = append(args, "refs/tags/"+name)
+	}
+	_, err := plus.Run(args...)
+	return err
+}
+
+// Head 获取当前提交的哈希值
+func (plus *Plus) Head() (string, error) {
+	output, err := plus.Run("rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// CommitTime 获取提交的提交时间
+func (plus *Plus) CommitTime(ref string) (time.Time, error) {
+	output, err := plus.Run("show", "-s", "--format=%ct", ref)
+	if err != nil {
+		return time.Time{}, err
+	}
+	sec, err := strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// CurrentBranch 获取当前分支名称，分离头指针状态时返回 HEAD
+func (plus *Plus) CurrentBranch() (string, error) {
+	output, err := plus.Run("rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
@@ -0,0 +1,213 @@
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// CloneOptions 克隆选项
+type CloneOptions struct {
+	Branch string   // 检出的分支，为空时使用默认分支
+	Depth  int      // 大于 0 时为浅克隆，只获取最近的 Depth 个提交
+	Filter string   // 部分克隆的对象过滤器，如 blob:none 按需下载文件内容，保留完整的提交历史及标签
+	Sparse bool     // 稀疏检出，只检出工作区根目录的文件及 Paths 中的目录
+	Paths  []string // 稀疏检出的目录
+}
+
+// Clone 克隆仓库到 dir，branch 为空时使用默认分支，depth 大于 0 时为浅克隆
+func (plus *Plus) Clone(url, dir, branch string, depth int) error {
+	return plus.CloneWith(url, dir, CloneOptions{Branch: branch, Depth: depth})
+}
+
+// CloneWith 按选项克隆仓库到 dir，只需要提交信息及少量清单文件时，部分克隆加稀疏检出可以大幅减少网络传输及磁盘占用
+func (plus *Plus) CloneWith(url, dir string, opts CloneOptions) error {
+	args := []string{"clone", "--quiet"}
+	if opts.Branch != "" {
+		args = append(args, "--branch", opts.Branch)
+	}
+	if opts.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(opts.Depth))
+	}
+	if opts.Filter != "" {
+		args = append(args, "--filter="+opts.Filter)
+	}
+	if opts.Sparse {
+		args = append(args, "--no-checkout")
+	}
+	if _, err := plus.Run(append(args, url, dir)...); err != nil {
+		return err
+	}
+	if !opts.Sparse {
+		return nil
+	}
+	clone := &Plus{Cwd: dir, Verbose: plus.Verbose}
+	if _, err := clone.Run("sparse-checkout", "init", "--cone"); err != nil {
+		return err
+	}
+	if _, err := clone.Run(append([]string{"sparse-checkout", "set"}, opts.Paths...)...); err != nil {
+		return err
+	}
+	_, err := clone.Run("checkout", "--quiet")
+	return err
+}
+
+// CreateBranch 基于当前提交创建并切换到分支，分支已存在时重置
+func (plus *Plus) CreateBranch(name string) error {
+	_, err := plus.Run("checkout", "--quiet", "-B", name)
+	return err
+}
+
+// HasChanges 判断工作区是否有未提交的变更
+func (plus *Plus) HasChanges(paths ...string) (bool, error) {
+	args := append([]string{"status", "--porcelain", "--"}, paths...)
+	output, err := plus.Run(args...)
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(output)) != "", nil
+}
+
+// PendingFiles 列出未提交的变更文件，包括未跟踪的文件
+func (plus *Plus) PendingFiles(paths ...string) ([]string, error) {
+	args := append([]string{"status", "--porcelain", "--untracked-files=all", "--"}, paths...)
+	output, err := plus.Run(args...)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0)
+	for _, line := range strings.Split(string(output), "\n") {
+		// 每行为两位状态码、空格及文件路径，重命名时为 旧路径 -> 新路径
+		if len(line) > 3 {
+			name := line[3:]
+			if _, to, ok := strings.Cut(name, " -> "); ok {
+				name = to
+			}
+			files = append(files, strings.Trim(name, `"`))
+		}
+	}
+	return files, nil
+}
+
+// Commit 提交指定路径的变更，paths 为空时提交所有已跟踪文件的变更
+func (plus *Plus) Commit(message string, paths ...string) error {
+	if len(paths) > 0 {
+		if _, err := plus.Run(append([]string{"add", "--"}, paths...)...); err != nil {
+			return err
+		}
+	} else if _, err := plus.Run("add", "--update"); err != nil {
+		return err
+	}
+	_, err := plus.Run("commit", "--quiet", "-m", message)
+	return err
+}
+
+// Push 推送引用到远程仓库，force 为 true 时强制覆盖远程引用
+func (plus *Plus) Push(remote, refspec string, force bool) error {
+	args := []string{"push", "--quiet"}
+	if force {
+		args = append(args, "--force")
+	}
+	_, err := plus.Run(append(args, remote, refspec)...)
+	return err
+}
+
+// Pull 拉取远程标签并将分支快进到远程分支的最新提交
+func (plus *Plus) Pull(remote, branch string) error {
+	if _, err := plus.Run("fetch", "--quiet", "--tags", remote, branch); err != nil {
+		return err
+	}
+	if _, err := plus.Run("checkout", "--quiet", branch); err != nil {
+		return err
+	}
+	_, err := plus.Run("merge", "--quiet", "--ff-only", "FETCH_HEAD")
+	return err
+}
+
+// CreateBranchFrom 基于 start 创建并切换到分支，分支已存在时重置
+func (plus *Plus) CreateBranchFrom(name, start string) error {
+	_, err := plus.Run("checkout", "--quiet", "-B", name, start)
+	return err
+}
+
+// Checkout 切换到分支或提交
+func (plus *Plus) Checkout(ref string) error {
+	_, err := plus.Run("checkout", "--quiet", ref)
+	return err
+}
+
+// CherryPick 依次挑选提交到当前分支，提交信息中记录来源提交；发生冲突时中止挑选并返回错误，工作区恢复到挑选前的状态
+func (plus *Plus) CherryPick(commits ...string) error {
+	_, err := plus.Run(append([]string{"cherry-pick", "-x", "--allow-empty"}, commits...)...)
+	if err != nil {
+		if _, abortErr := plus.Run("cherry-pick", "--abort"); abortErr != nil {
+			return fmt.Errorf("%w, abort cherry-pick: %s", err, abortErr)
+		}
+	}
+	return err
+}
+
+// TopLevel 工作区根目录的绝对路径，在子目录、附加工作区及子模块中同样适用
+func (plus *Plus) TopLevel() (string, error) {
+	return plus.revParse("--show-toplevel")
+}
+
+// GitDir 当前工作区的 git 目录，附加工作区为 <主仓库>/.git/worktrees/<名称>，子模块为 <父仓库>/.git/modules/<路径>，
+// 不能假设为工作区根目录下的 .git 目录
+func (plus *Plus) GitDir() (string, error) {
+	return plus.revParse("--absolute-git-dir")
+}
+
+// CommonDir 所有工作区共享的 git 目录，标签、notes 等引用保存在此
+func (plus *Plus) CommonDir() (string, error) {
+	dir, err := plus.revParse("--git-common-dir")
+	if err != nil || filepath.IsAbs(dir) {
+		return dir, err
+	}
+	// 旧版本 git 返回相对于当前目录的路径
+	return filepath.Abs(filepath.Join(plus.Cwd, dir))
+}
+
+// IsWorktree 是否为 git worktree add 创建的附加工作区
+func (plus *Plus) IsWorktree() (bool, error) {
+	gitDir, err := plus.GitDir()
+	if err != nil {
+		return false, err
+	}
+	commonDir, err := plus.CommonDir()
+	if err != nil {
+		return false, err
+	}
+	return filepath.Clean(gitDir) != filepath.Clean(commonDir), nil
+}
+
+// GitPath git 目录中文件的实际路径，如 hooks、COMMIT_EDITMSG，附加工作区中共享的文件位于公共目录
+func (plus *Plus) GitPath(name string) (string, error) {
+	p, err := plus.revParse("--git-path", name)
+	if err != nil || filepath.IsAbs(p) {
+		return p, err
+	}
+	return filepath.Abs(filepath.Join(plus.Cwd, p))
+}
+
+// IsRepository 目录是否为仓库的工作区根目录，.git 为目录（普通仓库）或文件（附加工作区、子模块）均可
+func IsRepository(dir string) bool {
+	_, err := os.Stat(filepath.Join(dir, ".git"))
+	return err == nil
+}
+
+func (plus *Plus) revParse(args ...string) (string, error) {
+	output, err := plus.Run(append([]string{"rev-parse"}, args...)...)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// SparseClone 以稀疏检出克隆仓库到 dir，只检出工作区根目录的文件及 paths 中的目录，
+// 历史中的文件内容按需下载，保留完整的提交及标签以便计算版本；url 也可以是本地的裸仓库
+func (plus *Plus) SparseClone(url, dir, branch string, paths []string) error {
+	return plus.CloneWith(url, dir, CloneOptions{Branch: branch, Filter: "blob:none", Sparse: true, Paths: paths})
+}
@@ -69,7 +69,8 @@ func (l *stdLog) Format(entry *logrus.Entry) ([]byte, error) {
 	if level == "warning" {
 		level = "warn"
 	}
-	newLog := fmt.Sprintf("[%s] [ %s ] %s\n", timestamp, level, entry.Message)
+	// 屏蔽日志中的密钥
+	newLog := fmt.Sprintf("[%s] [ %s ] %s\n", timestamp, level, Redact(entry.Message))
 	b.WriteString(newLog)
 	return b.Bytes(), nil
 }
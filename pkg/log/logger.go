@@ -1,6 +1,8 @@
 package log
 
 import (
+	"io"
+
 	"github.com/sirupsen/logrus"
 )
 
@@ -53,3 +55,31 @@ func Fatal(format string, v ...interface{}) {
 func IsFatalEnabled() bool {
 	return logger.IsFatalEnabled()
 }
+
+// SetOutput 设置日志输出，默认为标准输出
+func SetOutput(w io.Writer) {
+	if std, ok := logger.(*stdLog); ok {
+		std.logrus.SetOutput(w)
+	}
+}
+
+// warnHook 输出警告及错误日志时回调
+type warnHook struct {
+	fn func(message string)
+}
+
+func (h warnHook) Levels() []logrus.Level {
+	return []logrus.Level{logrus.WarnLevel, logrus.ErrorLevel}
+}
+
+func (h warnHook) Fire(entry *logrus.Entry) error {
+	h.fn(Redact(entry.Message))
+	return nil
+}
+
+// OnWarn 输出警告及错误日志时调用 fn，消息中的密钥已屏蔽
+func OnWarn(fn func(message string)) {
+	if std, ok := logger.(*stdLog); ok {
+		std.logrus.AddHook(warnHook{fn: fn})
+	}
+}
@@ -0,0 +1,118 @@
+package log
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Command 一次外部命令的执行记录
+type Command struct {
+	Name     string        `json:"name"`
+	Args     []string      `json:"args,omitempty"`
+	Dir      string        `json:"dir"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"` // 纳秒
+	ExitCode int           `json:"exitCode"` // 未能启动或被信号终止时为 -1
+	Error    string        `json:"error,omitempty"`
+}
+
+// String 命令行形式，已屏蔽密钥
+func (c Command) String() string {
+	return Redact(strings.Join(append([]string{c.Name}, c.Args...), " "))
+}
+
+type tracer struct {
+	mu      sync.Mutex
+	enabled bool
+	out     io.Writer
+}
+
+var commandTracer = &tracer{}
+
+// EnableTrace 开启外部命令追踪，每条命令以 info 级别输出，out 不为空时同时以 JSON Lines 格式写入
+func EnableTrace(out io.Writer) {
+	commandTracer.mu.Lock()
+	defer commandTracer.mu.Unlock()
+	commandTracer.enabled = true
+	commandTracer.out = out
+}
+
+// DisableTrace 关闭外部命令追踪
+func DisableTrace() {
+	commandTracer.mu.Lock()
+	defer commandTracer.mu.Unlock()
+	commandTracer.enabled = false
+	commandTracer.out = nil
+}
+
+// IsCommandTraceEnabled 是否开启了外部命令追踪
+func IsCommandTraceEnabled() bool {
+	commandTracer.mu.Lock()
+	defer commandTracer.mu.Unlock()
+	return commandTracer.enabled
+}
+
+// TraceCommand 记录已执行结束的命令，err 为 cmd.Run、cmd.Wait 等方法的返回值
+func TraceCommand(cmd *exec.Cmd, start time.Time, err error) {
+	if !IsCommandTraceEnabled() {
+		return
+	}
+	c := Command{
+		Name:     filepath.Base(cmd.Path),
+		Dir:      cmd.Dir,
+		Start:    start,
+		Duration: time.Since(start),
+		ExitCode: exitCode(cmd, err),
+	}
+	if len(cmd.Args) > 1 {
+		c.Args = make([]string, len(cmd.Args)-1)
+		for i, arg := range cmd.Args[1:] {
+			c.Args[i] = Redact(arg)
+		}
+	}
+	if c.Dir == "" {
+		c.Dir, _ = os.Getwd()
+	}
+	if err != nil {
+		c.Error = Redact(err.Error())
+	}
+	record(c)
+}
+
+func record(c Command) {
+	commandTracer.mu.Lock()
+	defer commandTracer.mu.Unlock()
+	logger.InfoF("trace: %s (dir %s, %s, exit %d)", c.String(), c.Dir, c.Duration.Round(time.Millisecond), c.ExitCode)
+	if commandTracer.out == nil {
+		return
+	}
+	data, err := json.Marshal(c)
+	if err == nil {
+		_, err = fmt.Fprintln(commandTracer.out, string(data))
+	}
+	if err != nil {
+		logger.WarnF("write command trace: %v", err)
+	}
+}
+
+func exitCode(cmd *exec.Cmd, err error) int {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	if err != nil {
+		return -1
+	}
+	if cmd.ProcessState != nil {
+		return cmd.ProcessState.ExitCode()
+	}
+	return 0
+}
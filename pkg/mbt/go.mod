@@ -1,6 +1,6 @@
 module github.com/coffee377/autoctl/pkg/mbt
 
-go 1.18
+go 1.20
 
 require github.com/libgit2/git2go/v34 v34.0.0
 
@@ -1,6 +1,6 @@
 module github.com/coffee377/autoctl/pkg/security
 
-go 1.18
+go 1.20
 
 require (
 	github.com/stretchr/testify v1.8.4
@@ -0,0 +1,122 @@
+package semver
+
+// clone 复制版本，修改副本不影响原版本
+func (v *version) clone() *version {
+	ver := *v
+	o := options{}
+	if v.options != nil {
+		o = *v.options
+	}
+	ver.options = &o
+	ver.preRelease = append([]Identifier(nil), v.preRelease...)
+	ver.build = append([]Identifier(nil), v.build...)
+	return &ver
+}
+
+// WithMajor 返回主版本号替换为 major 的新版本，其余部分保持不变
+func (v *version) WithMajor(major uint64) Semver {
+	ver := v.clone()
+	ver.major = major
+	return ver
+}
+
+// WithMinor 返回次版本号替换为 minor 的新版本，其余部分保持不变
+func (v *version) WithMinor(minor uint64) Semver {
+	ver := v.clone()
+	ver.minor = minor
+	return ver
+}
+
+// WithPatch 返回修订号替换为 patch 的新版本，其余部分保持不变
+func (v *version) WithPatch(patch uint64) Semver {
+	ver := v.clone()
+	ver.patch = patch
+	return ver
+}
+
+// WithPreRelease 返回先行版本号替换为 identifiers 的新版本，不传时去掉先行版本号
+func (v *version) WithPreRelease(identifiers ...Identifier) Semver {
+	ver := v.clone()
+	ver.preRelease = append([]Identifier(nil), identifiers...)
+	return ver
+}
+
+// WithBuild 返回编译信息替换为 identifiers 的新版本，不传时去掉编译信息
+func (v *version) WithBuild(identifiers ...Identifier) Semver {
+	ver := v.clone()
+	ver.build = append([]Identifier(nil), identifiers...)
+	return ver
+}
+
+// VersionBuilder 逐个设置版本号的各部分来构造版本，无需拼接字符串再解析，
+// 标识符校验失败时记录第一个错误并在 Version 中返回
+type VersionBuilder struct {
+	v   version
+	err error
+}
+
+// NewVersionBuilder 创建版本构造器，初始版本为 0.0.0
+func NewVersionBuilder() *VersionBuilder {
+	return &VersionBuilder{v: version{options: &options{}}}
+}
+
+// From 以已有版本为基础构造
+func (b *VersionBuilder) From(v Semver) *VersionBuilder {
+	b.v.major, b.v.minor, b.v.patch = v.Major(), v.Minor(), v.Patch()
+	b.v.preRelease = append([]Identifier(nil), v.PreRelease()...)
+	b.v.build = append([]Identifier(nil), v.Build()...)
+	return b
+}
+
+// Major 设置主版本号
+func (b *VersionBuilder) Major(major uint64) *VersionBuilder {
+	b.v.major = major
+	return b
+}
+
+// Minor 设置次版本号
+func (b *VersionBuilder) Minor(minor uint64) *VersionBuilder {
+	b.v.minor = minor
+	return b
+}
+
+// Patch 设置修订号
+func (b *VersionBuilder) Patch(patch uint64) *VersionBuilder {
+	b.v.patch = patch
+	return b
+}
+
+// PreRelease 设置先行版本号，如 PreRelease("beta", "1") 对应 -beta.1
+func (b *VersionBuilder) PreRelease(identifiers ...string) *VersionBuilder {
+	b.v.preRelease = b.identifiers(identifiers, false)
+	return b
+}
+
+// Build 设置编译信息，如 Build("sha", "abc123") 对应 +sha.abc123
+func (b *VersionBuilder) Build(identifiers ...string) *VersionBuilder {
+	b.v.build = b.identifiers(identifiers, true)
+	return b
+}
+
+// Version 返回构造的版本，设置标识符时出现错误则返回该错误
+func (b *VersionBuilder) Version() (Semver, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	return b.v.clone(), nil
+}
+
+func (b *VersionBuilder) identifiers(raw []string, build bool) []Identifier {
+	res := make([]Identifier, 0, len(raw))
+	for _, s := range raw {
+		identifier, err := newIdentifier(s, build)
+		if err != nil {
+			if b.err == nil {
+				b.err = err
+			}
+			continue
+		}
+		res = append(res, identifier)
+	}
+	return res
+}
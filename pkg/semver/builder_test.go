@@ -0,0 +1,59 @@
+package semver
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestVersion_With(t *testing.T) {
+	v, _ := Version("1.2.3-beta.1+sha.abc")
+	beta := MustIdentifier("beta")
+	tests := []struct {
+		got      Semver
+		expected string
+	}{
+		{v.WithMajor(2), "2.2.3-beta.1+sha.abc"},
+		{v.WithMinor(0), "1.0.3-beta.1+sha.abc"},
+		{v.WithPatch(9), "1.2.9-beta.1+sha.abc"},
+		{v.WithPreRelease(), "1.2.3+sha.abc"},
+		{v.WithPreRelease(beta, IdentifierFromUint(2)), "1.2.3-beta.2+sha.abc"},
+		{v.WithBuild(), "1.2.3-beta.1"},
+		{v.WithMajor(3).WithMinor(1).WithPatch(0).WithPreRelease().WithBuild(), "3.1.0"},
+	}
+	for _, test := range tests {
+		if test.got.String() != test.expected {
+			t.Errorf("expected %s, but %s got", test.expected, test.got)
+		}
+	}
+	if v.String() != "1.2.3-beta.1+sha.abc" {
+		t.Errorf("original version changed to %s", v)
+	}
+}
+
+func TestVersionBuilder(t *testing.T) {
+	v, err := NewVersionBuilder().Major(1).Minor(4).Patch(0).PreRelease("rc", "1").Build("001").Version()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v.String() != "1.4.0-rc.1+001" {
+		t.Errorf("expected 1.4.0-rc.1+001, but %s got", v)
+	}
+	if !v.PreRelease()[1].IsNumeric {
+		t.Error("expected numeric prerelease identifier")
+	}
+
+	base, _ := Version("2.0.0-alpha")
+	v, err = NewVersionBuilder().From(base).Patch(1).PreRelease().Version()
+	if err != nil || v.String() != "2.0.1" {
+		t.Errorf("expected 2.0.1, but %v %v got", v, err)
+	}
+
+	_, err = NewVersionBuilder().PreRelease("beta", "01").Version()
+	if !errors.Is(err, ErrLeadingZero) {
+		t.Errorf("expected leading zero error, but %v got", err)
+	}
+	_, err = NewVersionBuilder().Build("").Version()
+	if !errors.Is(err, ErrEmptyIdentifier) {
+		t.Errorf("expected empty identifier error, but %v got", err)
+	}
+}
@@ -16,6 +16,16 @@ const (
 	PreRelease VersionChanged = 1 << 4
 )
 
+// PreIdMode 预发布版本切换为不同标识符时的计数方式，如 1.2.0-alpha.3 以 beta 递增
+type PreIdMode int
+
+const (
+	PreIdReplace  PreIdMode = iota // 替换为新标识符，不带计数：1.2.0-beta（默认）
+	PreIdReset                     // 替换为新标识符并从 0 开始计数：1.2.0-beta.0
+	PreIdContinue                  // 替换为新标识符并延续原计数：1.2.0-beta.4
+	PreIdRefuse                    // 拒绝切换标识符，IncrementE 返回 ErrPreIdSwitchRefused
+)
+
 const (
 	alpha PreReleaseIdentifier = "alpha"
 	beta  PreReleaseIdentifier = "beta"
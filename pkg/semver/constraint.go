@@ -0,0 +1,204 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// partialReg 约束中的版本号，次版本号及修订号可省略或使用 x、X、* 通配
+var partialReg = regexp.MustCompile(`^v?(\d+|[xX*])(?:\.(\d+|[xX*]))?(?:\.(\d+|[xX*]))?(?:-([0-9A-Za-z.-]+))?(?:\+[0-9A-Za-z.-]+)?$`)
+
+type comparator struct {
+	op      string // = != > >= < <=
+	version Semver
+}
+
+func (c comparator) check(v Semver, opts CompareOptions) bool {
+	n := v.CompareWith(c.version, opts)
+	switch c.op {
+	case "!=":
+		return n != 0
+	case ">":
+		return n > 0
+	case ">=":
+		return n >= 0
+	case "<":
+		return n < 0
+	case "<=":
+		return n <= 0
+	default:
+		return n == 0
+	}
+}
+
+// Constraint 版本约束，语法与 npm 基本一致：
+// 空格（或逗号）分隔的条件需同时满足，|| 分隔的条件满足其一即可，
+// 支持 = != > >= < <= ~ ^ 运算符及 1.x、1.2.* 等通配形式。
+// 未指定先行版本号的上限不包含该版本的先行版本，如 <2.0.0 不允许 2.0.0-rc.1
+type Constraint struct {
+	raw    string
+	ranges [][]comparator
+}
+
+// ParseConstraint 解析版本约束
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{raw: strings.TrimSpace(s)}
+	if c.raw == "" {
+		return nil, fmt.Errorf("empty version constraint")
+	}
+	for _, part := range strings.Split(c.raw, "||") {
+		fields := strings.Fields(strings.ReplaceAll(part, ",", " "))
+		if len(fields) == 0 {
+			return nil, fmt.Errorf("invalid version constraint %q: empty range", s)
+		}
+		comparators := make([]comparator, 0, len(fields))
+		for i := 0; i < len(fields); i++ {
+			field := fields[i]
+			// 运算符与版本号之间允许空格，如 >= 1.2.0
+			if strings.Trim(field, "=!<>~^") == "" && i+1 < len(fields) {
+				i++
+				field += fields[i]
+			}
+			cs, err := parseComparator(field)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+			}
+			comparators = append(comparators, cs...)
+		}
+		c.ranges = append(c.ranges, comparators)
+	}
+	return c, nil
+}
+
+// Check 版本是否满足约束，按语义化版本规范比较
+func (c *Constraint) Check(v Semver) bool {
+	return c.CheckWith(v, DefaultCompareOptions)
+}
+
+// CheckWith 使用指定的比较选项检查版本是否满足约束，与 SortWith 的排序结果一致
+func (c *Constraint) CheckWith(v Semver, opts CompareOptions) bool {
+	for _, r := range c.ranges {
+		ok := true
+		for _, cmp := range r {
+			if !cmp.check(v, opts) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Constraint) String() string {
+	return c.raw
+}
+
+// parseComparator 解析单个条件，~、^ 及通配形式转换为上下限
+func parseComparator(s string) ([]comparator, error) {
+	op := s[:len(s)-len(strings.TrimLeft(s, "=!<>~^"))]
+	rest := s[len(op):]
+	switch op {
+	case "", "=", "!=", ">", ">=", "<", "<=", "~", "^", "~>":
+	default:
+		return nil, fmt.Errorf("unknown operator %q", op)
+	}
+	match := partialReg.FindStringSubmatch(rest)
+	if match == nil {
+		return nil, fmt.Errorf("invalid version %q", rest)
+	}
+	// parts 为实际指定的版本号段数，通配符之后的部分视为未指定
+	nums := make([]uint64, 3)
+	parts := 0
+	for i := 1; i <= 3; i++ {
+		if match[i] == "" || strings.ContainsAny(match[i], "xX*") {
+			break
+		}
+		nums[i-1], _ = strconv.ParseUint(match[i], 10, 64)
+		parts++
+	}
+	pre := match[4]
+	if parts < 3 && pre != "" {
+		return nil, fmt.Errorf("prerelease requires a full version %q", rest)
+	}
+	if parts == 0 {
+		if op == "<" || op == "!=" {
+			return []comparator{{op: "<", version: mustVersion(0, 0, 0, "0")}}, nil
+		}
+		return []comparator{{op: ">=", version: mustVersion(0, 0, 0, "")}}, nil
+	}
+	lower, err := Version(strings.TrimPrefix(strings.SplitN(rest, "+", 2)[0], "v"))
+	if parts < 3 {
+		lower = mustVersion(nums[0], nums[1], nums[2], "")
+	} else if err != nil {
+		return nil, err
+	}
+	// upper 未指定部分递增后的上限，如 1.2 => 1.3.0-0
+	upper := func() Semver {
+		switch parts {
+		case 1:
+			return mustVersion(nums[0]+1, 0, 0, "0")
+		case 2:
+			return mustVersion(nums[0], nums[1]+1, 0, "0")
+		}
+		return mustVersion(nums[0], nums[1], nums[2]+1, "0")
+	}
+	switch op {
+	case "~", "~>":
+		if parts == 3 {
+			parts = 2
+		}
+		return []comparator{{">=", lower}, {"<", upper()}}, nil
+	case "^":
+		switch {
+		case nums[0] > 0 || parts == 1:
+			parts = 1
+		case nums[1] > 0 || parts == 2:
+			parts = 2
+		}
+		return []comparator{{">=", lower}, {"<", upper()}}, nil
+	case ">":
+		if parts < 3 {
+			return []comparator{{">=", upper()}}, nil
+		}
+		return []comparator{{">", lower}}, nil
+	case ">=":
+		return []comparator{{">=", lower}}, nil
+	case "<":
+		if pre == "" {
+			lower = mustVersion(nums[0], nums[1], nums[2], "0")
+		}
+		return []comparator{{"<", lower}}, nil
+	case "<=":
+		if parts < 3 {
+			return []comparator{{"<", upper()}}, nil
+		}
+		return []comparator{{"<=", lower}}, nil
+	case "!=":
+		if parts < 3 {
+			return nil, fmt.Errorf("%s requires a full version %q", op, rest)
+		}
+		return []comparator{{"!=", lower}}, nil
+	default:
+		if parts < 3 {
+			return []comparator{{">=", lower}, {"<", upper()}}, nil
+		}
+		return []comparator{{"=", lower}}, nil
+	}
+}
+
+func mustVersion(major, minor, patch uint64, pre string) Semver {
+	s := fmt.Sprintf("%d.%d.%d", major, minor, patch)
+	if pre != "" {
+		s += "-" + pre
+	}
+	v, err := Version(s)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
@@ -0,0 +1,78 @@
+package semver
+
+import (
+	"testing"
+)
+
+func TestConstraint_Check(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{"<2.0.0", "1.9.9", true},
+		{"<2.0.0", "2.0.0-rc.1", false},
+		{"<2.0.0-rc.2", "2.0.0-rc.1", true},
+		{">=1.2.0 <2.0.0", "1.1.9", false},
+		{">= 1.2.0, < 2.0.0", "1.5.0", true},
+		{"1.x", "1.9.0", true},
+		{"1.x", "2.0.0", false},
+		{"1.2.*", "1.2.9", true},
+		{"*", "0.0.1", true},
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.3.0", false},
+		{"^1.2.3", "1.9.0", true},
+		{"^1.2.3", "2.0.0-0", false},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.4", false},
+		{">1.2", "1.2.9", false},
+		{">1.2", "1.3.0", true},
+		{"<=1.2", "1.2.9", true},
+		{"!=1.2.3", "1.2.3", false},
+		{"=1.2.3", "1.2.3", true},
+		{"v1.2.3", "1.2.3", true},
+		{"<1.0.0 || >=3.0.0", "2.0.0", false},
+		{"<1.0.0 || >=3.0.0", "3.1.0", true},
+	}
+	for _, test := range tests {
+		c, err := ParseConstraint(test.constraint)
+		if err != nil {
+			t.Fatalf("%s: %s", test.constraint, err)
+		}
+		v, _ := Version(test.version)
+		if actual := c.Check(v); actual != test.expected {
+			t.Errorf("%s %s: expected %v, but %v got", test.constraint, test.version, test.expected, actual)
+		}
+	}
+}
+
+func TestConstraint_CheckWith(t *testing.T) {
+	tests := []struct {
+		constraint, version string
+		opts                CompareOptions
+		expected            bool
+	}{
+		{"=1.0.0-RC.1", "1.0.0-rc.1", DefaultCompareOptions, false},
+		{"=1.0.0-RC.1", "1.0.0-rc.1", CompareOptions{CaseInsensitive: true}, true},
+		{"<1.0.0-alpha10", "1.0.0-alpha9", DefaultCompareOptions, false},
+		{"<1.0.0-alpha10", "1.0.0-alpha9", CompareOptions{NumericAware: true}, true},
+	}
+	for _, test := range tests {
+		c, err := ParseConstraint(test.constraint)
+		if err != nil {
+			t.Fatalf("%s: %s", test.constraint, err)
+		}
+		v, _ := Version(test.version)
+		if actual := c.CheckWith(v, test.opts); actual != test.expected {
+			t.Errorf("%s %s %+v: expected %v, but %v got", test.constraint, test.version, test.opts, test.expected, actual)
+		}
+	}
+}
+
+func TestParseConstraint_Invalid(t *testing.T) {
+	for _, s := range []string{"", ">>1.0.0", "1.2.x-rc", "a.b.c", "1.0.0 ||", "!=1.x", "=1.0.0-01"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("expected %q invalid, but nil error got", s)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package semver
+
+import (
+	"strings"
+)
+
+// Comparable 版本的可比较形式，可以直接用 == 比较或作为 map 的键，
+// 先行版本号及编译信息以 . 连接的字符串保存
+type Comparable struct {
+	Major      uint64
+	Minor      uint64
+	Patch      uint64
+	PreRelease string
+	Build      string
+}
+
+// Semver 转换为版本
+func (c Comparable) Semver() (Semver, error) {
+	b := NewVersionBuilder().Major(c.Major).Minor(c.Minor).Patch(c.Patch)
+	if c.PreRelease != "" {
+		b.PreRelease(strings.Split(c.PreRelease, ".")...)
+	}
+	if c.Build != "" {
+		b.Build(strings.Split(c.Build, ".")...)
+	}
+	return b.Version()
+}
+
+// String 与对应版本的 String 相同
+func (c Comparable) String() string {
+	v, err := c.Semver()
+	if err != nil {
+		return ""
+	}
+	return v.String()
+}
+
+// Equal 按语义化版本规范判断是否相等，忽略编译信息，如 1.2.3+a 与 1.2.3+b 相等
+func (v *version) Equal(other Semver) bool {
+	return v.Compare(other) == 0
+}
+
+// EqualWith 使用指定的比较选项判断是否相等，BuildMeta 为 true 时编译信息不同的版本不相等
+func (v *version) EqualWith(other Semver, opts CompareOptions) bool {
+	return v.CompareWith(other, opts) == 0
+}
+
+// Key 版本的规范字符串，包含编译信息，可以作为缓存的键；
+// 两个版本的 Key 相同当且仅当以 CompareOptions{BuildMeta: true} 比较时相等
+func (v *version) Key() string {
+	return v.String()
+}
+
+// Comparable 版本的可比较形式
+func (v *version) Comparable() Comparable {
+	return Comparable{
+		Major:      v.major,
+		Minor:      v.minor,
+		Patch:      v.patch,
+		PreRelease: IdentifierString(v.preRelease),
+		Build:      IdentifierString(v.build),
+	}
+}
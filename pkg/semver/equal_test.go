@@ -0,0 +1,34 @@
+package semver
+
+import "testing"
+
+func TestVersion_Equal(t *testing.T) {
+	a, _ := Version("1.2.3-rc.1+sha.a")
+	b, _ := Version("1.2.3-rc.1+sha.b")
+	c, _ := Version("1.2.3-rc.1+sha.a")
+	if !a.Equal(b) {
+		t.Errorf("expected %s equal to %s ignoring build metadata", a, b)
+	}
+	if a.EqualWith(b, CompareOptions{BuildMeta: true}) {
+		t.Errorf("expected %s not equal to %s considering build metadata", a, b)
+	}
+	if a.Key() == b.Key() || a.Key() != c.Key() {
+		t.Errorf("unexpected keys %s %s %s", a.Key(), b.Key(), c.Key())
+	}
+}
+
+func TestVersion_Comparable(t *testing.T) {
+	a, _ := Version("1.2.3-rc.1+sha.a")
+	b, _ := Version("1.2.3-rc.1+sha.a")
+	seen := map[Comparable]bool{a.Comparable(): true}
+	if !seen[b.Comparable()] {
+		t.Error("expected equal versions to share the map key")
+	}
+	v, err := a.Comparable().Semver()
+	if err != nil || !v.EqualWith(a, CompareOptions{BuildMeta: true}) {
+		t.Errorf("expected %s, but %v %v got", a, v, err)
+	}
+	if s := (Comparable{Major: 2}).String(); s != "2.0.0" {
+		t.Errorf("expected 2.0.0, but %s got", s)
+	}
+}
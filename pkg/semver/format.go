@@ -0,0 +1,86 @@
+package semver
+
+import (
+	"strconv"
+)
+
+// 常用的版本格式，Format 也接受对应的名称，如 Format("docker")
+const (
+	LayoutFull       = "M.m.p-P+B" // 完整版本号，与 String 相同
+	LayoutCore       = "M.m.p"     // 去掉先行版本号及编译信息，与 FinalizeVersion 相同
+	LayoutNoBuild    = "M.m.p-P"   // 去掉编译信息
+	LayoutMajorMinor = "M.m"       // 去掉修订号，如浮动标签 1.2
+	LayoutMajor      = "M"         // 只保留主版本号
+	LayoutDocker     = "M.m.p-P_B" // 镜像标签不允许 +，编译信息以 _ 分隔
+	LayoutUnderscore = "M_m_p"     // 以 _ 分隔，如 1_2_3
+)
+
+// layouts 版本格式名称
+var layouts = map[string]string{
+	"full":        LayoutFull,
+	"core":        LayoutCore,
+	"no-build":    LayoutNoBuild,
+	"major-minor": LayoutMajorMinor,
+	"major":       LayoutMajor,
+	"docker":      LayoutDocker,
+	"underscore":  LayoutUnderscore,
+}
+
+// LookupLayout 按名称查找版本格式
+func LookupLayout(name string) (string, bool) {
+	layout, ok := layouts[name]
+	return layout, ok
+}
+
+// Format 按格式输出版本号，M、m、p、P、B 分别替换为主版本号、次版本号、修订号、先行版本号及编译信息，
+// 其它字符原样输出，以 \ 转义的字符同样原样输出；先行版本号或编译信息为空时连同其前一个分隔符一起省略，
+// 如 1.2.3 按 M.m.p-P+B 输出为 1.2.3；layout 为已知的格式名称时使用对应的格式
+func (v *version) Format(layout string) string {
+	if l, ok := layouts[layout]; ok {
+		layout = l
+	}
+	buffer := make([]byte, 0, len(layout)+8)
+	// sep 等待输出的分隔符，下一个占位符为空时丢弃
+	var sep []byte
+	flush := func() {
+		buffer = append(buffer, sep...)
+		sep = sep[:0]
+	}
+	for i := 0; i < len(layout); i++ {
+		c := layout[i]
+		switch c {
+		case 'M':
+			flush()
+			buffer = strconv.AppendUint(buffer, v.major, 10)
+		case 'm':
+			flush()
+			buffer = strconv.AppendUint(buffer, v.minor, 10)
+		case 'p':
+			flush()
+			buffer = strconv.AppendUint(buffer, v.patch, 10)
+		case 'P', 'B':
+			identifiers := v.preRelease
+			if c == 'B' {
+				identifiers = v.build
+			}
+			if len(identifiers) == 0 {
+				sep = sep[:0]
+				continue
+			}
+			flush()
+			buffer = append(buffer, IdentifierString(identifiers)...)
+		case '\\':
+			flush()
+			if i+1 < len(layout) {
+				i++
+				buffer = append(buffer, layout[i])
+			}
+		default:
+			// 只保留紧邻占位符的一个分隔符
+			flush()
+			sep = append(sep, c)
+		}
+	}
+	flush()
+	return string(buffer)
+}
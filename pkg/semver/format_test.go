@@ -0,0 +1,30 @@
+package semver
+
+import "testing"
+
+func TestVersion_Format(t *testing.T) {
+	tests := []struct {
+		version  string
+		layout   string
+		expected string
+	}{
+		{"1.2.3-beta.1+sha.abc", LayoutFull, "1.2.3-beta.1+sha.abc"},
+		{"1.2.3", LayoutFull, "1.2.3"},
+		{"1.2.3+sha.abc", LayoutFull, "1.2.3+sha.abc"},
+		{"1.2.3-beta.1+sha.abc", LayoutCore, "1.2.3"},
+		{"1.2.3-beta.1+sha.abc", LayoutNoBuild, "1.2.3-beta.1"},
+		{"1.2.3-beta.1+sha.abc", LayoutMajorMinor, "1.2"},
+		{"1.2.3-beta.1+sha.abc", LayoutDocker, "1.2.3-beta.1_sha.abc"},
+		{"1.2.3", "underscore", "1_2_3"},
+		{"1.2.3-rc.1", "docker", "1.2.3-rc.1"},
+		{"1.2.3", "vM.m", "v1.2"},
+		{"1.2.3-rc.1", `M.m.p (\Pre P)`, "1.2.3 (Pre rc.1)"},
+		{"1.2.3", `M.m.p (\Pre P)`, "1.2.3 (Pre)"},
+	}
+	for _, test := range tests {
+		v, _ := Version(test.version)
+		if actual := v.Format(test.layout); actual != test.expected {
+			t.Errorf("format %s with %q, expected %q, but %q got", test.version, test.layout, test.expected, actual)
+		}
+	}
+}
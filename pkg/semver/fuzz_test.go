@@ -0,0 +1,101 @@
+package semver
+
+import (
+	"testing"
+)
+
+func TestSelfTest(t *testing.T) {
+	checks, failures := SelfTest()
+	if checks == 0 {
+		t.Fatal("expected self test checks")
+	}
+	for _, err := range failures {
+		t.Error(err)
+	}
+}
+
+func TestVersion_Overflow(t *testing.T) {
+	if _, err := Version("18446744073709551616.0.0-rc.1"); err == nil {
+		t.Error("expected overflow error, but nil got")
+	}
+}
+
+func addCorpus(f *testing.F) {
+	valid, invalid := Corpus()
+	for _, s := range append(valid, invalid...) {
+		f.Add(s)
+	}
+}
+
+// FuzzVersion 解析不会崩溃，解析成功时 String 与原字符串一致且快速路径与正则解析结果一致
+func FuzzVersion(f *testing.F) {
+	addCorpus(f)
+	f.Fuzz(func(t *testing.T, s string) {
+		v, err := Version(s)
+		if err != nil {
+			return
+		}
+		if v.String() != s {
+			t.Fatalf("parse %q: String() returns %q", s, v.String())
+		}
+		again, err := Version(v.String())
+		if err != nil || again.CompareWithBuildMeta(v) != 0 {
+			t.Fatalf("parse %q again: %v %v", s, again, err)
+		}
+		if fast, ok := parseCore(s); ok && (fast.major != v.Major() || fast.minor != v.Minor() || fast.patch != v.Patch()) {
+			t.Fatalf("parse %q: fast path returns %d.%d.%d", s, fast.major, fast.minor, fast.patch)
+		}
+	})
+}
+
+// FuzzCompare 比较满足自反性及反对称性
+func FuzzCompare(f *testing.F) {
+	f.Add("1.0.0-alpha", "1.0.0-alpha.1")
+	f.Add("1.0.0-rc.1", "1.0.0")
+	f.Add("1.0.0+build.1", "1.0.0+build.2")
+	f.Fuzz(func(t *testing.T, x, y string) {
+		a, err := Version(x)
+		if err != nil {
+			return
+		}
+		b, err := Version(y)
+		if err != nil {
+			return
+		}
+		if a.Compare(a) != 0 {
+			t.Fatalf("compare %s with itself", a)
+		}
+		if a.Compare(b) != -b.Compare(a) {
+			t.Fatalf("compare %s and %s is not antisymmetric", a, b)
+		}
+		if a.CompareWithBuildMeta(b) != -b.CompareWithBuildMeta(a) {
+			t.Fatalf("compare %s and %s with build metadata is not antisymmetric", a, b)
+		}
+	})
+}
+
+// FuzzIncrement 递增后的版本大于原版本，且不修改原版本
+func FuzzIncrement(f *testing.F) {
+	for i := range incrementTypes {
+		f.Add("1.2.3-rc.1", uint8(i))
+		f.Add("0.0.0", uint8(i))
+	}
+	f.Fuzz(func(t *testing.T, s string, kind uint8) {
+		v, err := Version(s)
+		if err != nil {
+			return
+		}
+		// 版本号达到上限时无法递增
+		if v.Major() == ^uint64(0) || v.Minor() == ^uint64(0) || v.Patch() == ^uint64(0) {
+			return
+		}
+		changed := incrementTypes[int(kind)%len(incrementTypes)]
+		next := v.Increment(WithReleaseType(changed))
+		if next.Compare(v) <= 0 {
+			t.Fatalf("increment %s %s returns %s", changed, v, next)
+		}
+		if v.String() != s {
+			t.Fatalf("increment %s %s modifies the version to %s", changed, s, v)
+		}
+	})
+}
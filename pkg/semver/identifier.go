@@ -1,18 +1,46 @@
 package semver
 
 import (
-	"regexp"
+	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 )
 
+var (
+	ErrEmptyIdentifier    = errors.New("identifier must not be empty")
+	ErrInvalidCharacter   = errors.New("identifier must comprise only ASCII alphanumerics and hyphens [0-9A-Za-z-]")
+	ErrLeadingZero        = errors.New("numeric identifier must not include leading zeroes")
+	ErrIdentifierOverflow = errors.New("numeric identifier is out of range")
+)
+
+// IdentifierError 标识符校验错误
+type IdentifierError struct {
+	Identifier string // 原始字符串
+	Err        error  // 具体原因
+}
+
+func (e *IdentifierError) Error() string {
+	return fmt.Sprintf("invalid identifier %q: %s", e.Identifier, e.Err)
+}
+
+func (e *IdentifierError) Unwrap() error {
+	return e.Err
+}
+
 type Identifier struct {
 	Raw       string // 原始字符串
 	IsNumeric bool   // 是否数字类型
 	Num       uint64 // 数字类型时的具体值
 }
 
+// Compare 按语义化版本规范比较标识符
 func (identifier Identifier) Compare(other Identifier) int {
+	return identifier.CompareWith(other, DefaultCompareOptions)
+}
+
+// CompareWith 使用指定的比较选项比较标识符，数字标识符的优先级始终低于非数字标识符
+func (identifier Identifier) CompareWith(other Identifier, opts CompareOptions) int {
 	if identifier.IsNumeric && !other.IsNumeric {
 		return -1
 	} else if !identifier.IsNumeric && other.IsNumeric {
@@ -26,27 +54,119 @@ func (identifier Identifier) Compare(other Identifier) int {
 			return 0
 		}
 	} else {
-		return strings.Compare(identifier.Raw, other.Raw)
+		a, b := identifier.Raw, other.Raw
+		if opts.CaseInsensitive {
+			a, b = strings.ToLower(a), strings.ToLower(b)
+		}
+		if opts.NumericAware {
+			return compareNatural(a, b)
+		}
+		return strings.Compare(a, b)
 	}
 }
 
+// String 返回标识符原始字符串
+func (identifier Identifier) String() string {
+	return identifier.Raw
+}
+
+// NewIdentifier 创建先行版本号标识符，不返回校验错误，无效的标识符按字符串处理，需要校验时使用 ParseIdentifier
 func NewIdentifier(identifier string) Identifier {
-	res := Identifier{
-		Raw: identifier,
+	if res, err := newIdentifier(identifier, false); err == nil {
+		return res
 	}
-	result, _ := regexp.MatchString(NumberIdentifierReg, identifier)
-	res.IsNumeric = result
-	if result {
-		res.Num, _ = strconv.ParseUint(identifier, 10, 64)
+	return Identifier{Raw: identifier}
+}
+
+// MustIdentifier 与 ParseIdentifier 相同，但标识符无效时 panic，用于常量标识符
+func MustIdentifier(identifier string) Identifier {
+	res, err := ParseIdentifier(identifier)
+	if err != nil {
+		panic(err)
 	}
 	return res
 }
 
-func parseIdentifiers(str string) []Identifier {
+// ParseIdentifier 解析先行版本号标识符，标识符只能由 [0-9A-Za-z-] 组成且不能为空，数字标识符不能有前导零
+func ParseIdentifier(identifier string) (Identifier, error) {
+	return newIdentifier(identifier, false)
+}
+
+// ParseBuildIdentifier 解析构建信息标识符，与先行版本号不同，构建信息允许数字前导零
+func ParseBuildIdentifier(identifier string) (Identifier, error) {
+	return newIdentifier(identifier, true)
+}
+
+// IdentifierFromUint 创建数字类型标识符
+func IdentifierFromUint(num uint64) Identifier {
+	return Identifier{Raw: strconv.FormatUint(num, 10), IsNumeric: true, Num: num}
+}
+
+// IdentifierString 将标识符以 . 连接为字符串，如 alpha.1
+func IdentifierString(identifiers []Identifier) string {
+	var sb strings.Builder
+	for i, identifier := range identifiers {
+		if i > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(identifier.Raw)
+	}
+	return sb.String()
+}
+
+func newIdentifier(identifier string, build bool) (Identifier, error) {
+	if identifier == "" {
+		return Identifier{}, &IdentifierError{Identifier: identifier, Err: ErrEmptyIdentifier}
+	}
+	numeric := true
+	for i := 0; i < len(identifier); i++ {
+		c := identifier[i]
+		if isDigit(c) {
+			continue
+		}
+		numeric = false
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '-') {
+			return Identifier{}, &IdentifierError{Identifier: identifier, Err: ErrInvalidCharacter}
+		}
+	}
+	res := Identifier{Raw: identifier}
+	// 构建信息不区分数字类型，均按字符串处理
+	if !numeric || build {
+		return res, nil
+	}
+	if len(identifier) > 1 && identifier[0] == '0' {
+		return Identifier{}, &IdentifierError{Identifier: identifier, Err: ErrLeadingZero}
+	}
+	num, err := strconv.ParseUint(identifier, 10, 64)
+	if err != nil {
+		return Identifier{}, &IdentifierError{Identifier: identifier, Err: ErrIdentifierOverflow}
+	}
+	res.IsNumeric = true
+	res.Num = num
+	return res, nil
+}
+
+// parseIdentifiers 解析以 . 分割的标识符
+func parseIdentifiers(str string, build bool) ([]Identifier, error) {
 	splits := strings.Split(str, ".")
 	identifiers := make([]Identifier, 0, len(splits))
 	for _, split := range splits {
-		identifiers = append(identifiers, NewIdentifier(split))
+		identifier, err := newIdentifier(split, build)
+		if err != nil {
+			return nil, err
+		}
+		identifiers = append(identifiers, identifier)
+	}
+	return identifiers, nil
+}
+
+// Validate 校验预发布版本标识符，可以由多个以 . 分割的标识符组成，如 beta.1；空字符串表示未指定，不做校验
+func (identifier PreReleaseIdentifier) Validate() error {
+	if identifier == "" {
+		return nil
+	}
+	if _, err := parseIdentifiers(string(identifier), false); err != nil {
+		return fmt.Errorf("invalid prerelease identifier %q: %w", string(identifier), err)
 	}
-	return identifiers
+	return nil
 }
@@ -1,6 +1,7 @@
 package semver
 
 import (
+	"errors"
 	"testing"
 )
 
@@ -34,3 +35,59 @@ func TestComparatorIdentifier(t *testing.T) {
 		}
 	}
 }
+
+func TestParseIdentifier(t *testing.T) {
+	tests := []struct {
+		raw     string
+		numeric bool
+		err     error
+	}{
+		{"0", true, nil},
+		{"11", true, nil},
+		{"alpha", false, nil},
+		{"x-1", false, nil},
+		{"0a", false, nil},
+		{"", false, ErrEmptyIdentifier},
+		{"01", false, ErrLeadingZero},
+		{"beta!", false, ErrInvalidCharacter},
+		{"rc_1", false, ErrInvalidCharacter},
+		{"99999999999999999999", false, ErrIdentifierOverflow},
+	}
+	for _, test := range tests {
+		identifier, err := ParseIdentifier(test.raw)
+		if !errors.Is(err, test.err) {
+			t.Errorf("identifier %q expected error '%v', but '%v' got", test.raw, test.err, err)
+			continue
+		}
+		var identifierErr *IdentifierError
+		if err != nil && !errors.As(err, &identifierErr) {
+			t.Errorf("identifier %q expected *IdentifierError, but %T got", test.raw, err)
+		}
+		if err == nil && identifier.IsNumeric != test.numeric {
+			t.Errorf("identifier %q expected numeric %v, but %v got", test.raw, test.numeric, identifier.IsNumeric)
+		}
+	}
+
+	if _, err := ParseBuildIdentifier("001"); err != nil {
+		t.Errorf("build identifier allows leading zeroes, but got %v", err)
+	}
+	if identifier := NewIdentifier("beta!"); identifier.Raw != "beta!" || identifier.IsNumeric {
+		t.Errorf("expected invalid identifier kept as string, but %+v got", identifier)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("expected MustIdentifier panics on invalid identifier")
+		}
+	}()
+	MustIdentifier("01")
+}
+
+func TestIdentifierString(t *testing.T) {
+	identifiers := []Identifier{{Raw: "alpha"}, IdentifierFromUint(1)}
+	if s := IdentifierString(identifiers); s != "alpha.1" {
+		t.Errorf("expected alpha.1, but %s got", s)
+	}
+	if s := IdentifierString(nil); s != "" {
+		t.Errorf("expected empty string, but %s got", s)
+	}
+}
@@ -0,0 +1,109 @@
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	ErrNoPreviousVersion = errors.New("no previous version")
+	ErrVersionOverflow   = errors.New("version number is out of range")
+)
+
+// IncrementBy 按版本变动类型连续递增 n 次，n 为 0 时返回原版本
+// major、minor、patch 的 n 可以为负数，此时等同于 PreviousVersion 连续执行 -n 次；
+// 选项或预发布版本标识符无效、版本号超出 uint64 范围时返回错误
+func IncrementBy(v Semver, changed VersionChanged, n int, opts ...Option) (Semver, error) {
+	switch {
+	case n == 0:
+		return v, nil
+	case n < 0:
+		var err error
+		for ; n < 0 && err == nil; n++ {
+			v, err = PreviousVersion(v, changed)
+		}
+		return v, err
+	}
+	opts = append(opts, WithReleaseType(changed))
+	next, err := v.IncrementE(opts...)
+	if err != nil {
+		return nil, err
+	}
+	steps := uint64(n - 1)
+	overflow := fmt.Errorf("%w incrementing %s by %d %s", ErrVersionOverflow, v, n, changed)
+	switch changed {
+	case Major:
+		if next.Major() < v.Major() || next.Major() > math.MaxUint64-steps {
+			return nil, overflow
+		}
+		return core(next.Major()+steps, 0, 0)
+	case Minor:
+		if next.Minor() < v.Minor() || next.Minor() > math.MaxUint64-steps {
+			return nil, overflow
+		}
+		return core(next.Major(), next.Minor()+steps, 0)
+	case Patch:
+		if next.Patch() < v.Patch() || next.Patch() > math.MaxUint64-steps {
+			return nil, overflow
+		}
+		return core(next.Major(), next.Minor(), next.Patch()+steps)
+	}
+	for i := 1; i < n; i++ {
+		if next, err = next.IncrementE(opts...); err != nil {
+			return nil, err
+		}
+	}
+	return next, nil
+}
+
+// PreviousVersion 上一个 major、minor 或 patch 版本，只基于主版本号、次版本号及修订号计算，忽略先行版本号及编译信息
+// 如 1.3.2 的上一个 minor 版本为 1.2.0，对应版本号为 0 时返回 ErrNoPreviousVersion
+func PreviousVersion(v Semver, changed VersionChanged) (Semver, error) {
+	major, minor, patch := v.Major(), v.Minor(), v.Patch()
+	switch {
+	case changed == Major && major > 0:
+		return core(major-1, 0, 0)
+	case changed == Minor && minor > 0:
+		return core(major, minor-1, 0)
+	case changed == Patch && patch > 0:
+		return core(major, minor, patch-1)
+	case changed != Major && changed != Minor && changed != Patch:
+		return nil, fmt.Errorf("previous version only supports major, minor and patch, but %s got", changed)
+	}
+	return nil, fmt.Errorf("%w of %s for %s", ErrNoPreviousVersion, v, changed)
+}
+
+// Difference 两个版本之间主版本号、次版本号及修订号的差值
+type Difference struct {
+	Type  string `json:"type,omitempty"` // 最高位的差异类型 major | minor | patch | prerelease | build，相同版本为空
+	Major int64  `json:"major"`
+	Minor int64  `json:"minor"`
+	Patch int64  `json:"patch"`
+}
+
+// Distance 计算从 a 到 b 的距离，各字段为 b 减 a 的差值，如 1.2.0 到 1.5.0 相差 3 个 minor 版本
+func Distance(a, b Semver) Difference {
+	d := Difference{
+		Major: int64(b.Major()) - int64(a.Major()),
+		Minor: int64(b.Minor()) - int64(a.Minor()),
+		Patch: int64(b.Patch()) - int64(a.Patch()),
+	}
+	switch {
+	case d.Major != 0:
+		d.Type = Major.String()
+	case d.Minor != 0:
+		d.Type = Minor.String()
+	case d.Patch != 0:
+		d.Type = Patch.String()
+	case a.Compare(b) != 0:
+		d.Type = PreRelease.String()
+	case a.CompareWithBuildMeta(b) != 0:
+		d.Type = "build"
+	}
+	return d
+}
+
+func core(major, minor, patch uint64) (Semver, error) {
+	return NewVersionBuilder().Major(major).Minor(minor).Patch(patch).Version()
+}
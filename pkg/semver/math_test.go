@@ -0,0 +1,85 @@
+package semver
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestIncrementBy(t *testing.T) {
+	tests := []struct {
+		version  string
+		changed  VersionChanged
+		n        int
+		expected string
+	}{
+		{"1.2.3", Minor, 3, "1.5.0"},
+		{"1.2.3", Patch, 2, "1.2.5"},
+		{"1.2.3", Major, 0, "1.2.3"},
+		{"1.3.0-rc.1", Minor, 1, "1.3.0"},
+		{"1.3.0-rc.1", Minor, 2, "1.4.0"},
+		{"1.0.0-rc.1", PreRelease, 3, "1.0.0-rc.4"},
+		{"1.5.2", Minor, -3, "1.2.0"},
+	}
+	for _, tt := range tests {
+		v, _ := Version(tt.version)
+		got, err := IncrementBy(v, tt.changed, tt.n)
+		if err != nil || got.String() != tt.expected {
+			t.Errorf("IncrementBy(%s, %s, %d) expected %s, but %v %v got", tt.version, tt.changed, tt.n, tt.expected, got, err)
+		}
+	}
+
+	v, _ := Version("1.2.3")
+	if got, err := IncrementBy(v, PreRelease, 1, WithIdentifier("beta..1")); err == nil {
+		t.Errorf("expected invalid identifier error, but %v got", got)
+	}
+	for _, tt := range []struct {
+		version string
+		changed VersionChanged
+		n       int
+	}{
+		{"18446744073709551615.0.0", Major, 2},
+		{"18446744073709551615.0.0", Major, 1},
+		{"1.18446744073709551614.0", Minor, 3},
+	} {
+		v, _ := Version(tt.version)
+		if got, err := IncrementBy(v, tt.changed, tt.n); !errors.Is(err, ErrVersionOverflow) {
+			t.Errorf("IncrementBy(%s, %s, %d) expected overflow, but %v %v got", tt.version, tt.changed, tt.n, got, err)
+		}
+	}
+}
+
+func TestPreviousVersion(t *testing.T) {
+	v, _ := Version("2.3.4-beta.1")
+	for changed, expected := range map[VersionChanged]string{Major: "1.0.0", Minor: "2.2.0", Patch: "2.3.3"} {
+		if got, err := PreviousVersion(v, changed); err != nil || got.String() != expected {
+			t.Errorf("PreviousVersion(%s, %s) expected %s, but %v %v got", v, changed, expected, got, err)
+		}
+	}
+	v, _ = Version("1.0.0")
+	if _, err := PreviousVersion(v, Minor); !errors.Is(err, ErrNoPreviousVersion) {
+		t.Errorf("expected ErrNoPreviousVersion, but %v got", err)
+	}
+	if _, err := PreviousVersion(v, PreRelease); err == nil {
+		t.Error("expected prerelease unsupported")
+	}
+}
+
+func TestDistance(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected Difference
+	}{
+		{"1.2.0", "1.5.0", Difference{Type: "minor", Minor: 3}},
+		{"1.9.3", "2.0.0", Difference{Type: "major", Major: 1, Minor: -9, Patch: -3}},
+		{"1.2.3", "1.2.3-rc.1", Difference{Type: "prerelease"}},
+		{"1.2.3+a", "1.2.3+b", Difference{Type: "build"}},
+		{"1.2.3", "1.2.3", Difference{}},
+	}
+	for _, tt := range tests {
+		a, _ := Version(tt.a)
+		b, _ := Version(tt.b)
+		if got := Distance(a, b); got != tt.expected {
+			t.Errorf("Distance(%s, %s) expected %+v, but %+v got", tt.a, tt.b, tt.expected, got)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrPreIdSwitchRefused 计数方式为 refuse 时切换预发布版本标识符，如 1.2.0-alpha.3 以 beta 递增
+var ErrPreIdSwitchRefused = errors.New("switching the pre-release identifier is refused")
+
+var preIdModeNames = map[PreIdMode]string{
+	PreIdReplace:  "replace",
+	PreIdReset:    "reset",
+	PreIdContinue: "continue",
+	PreIdRefuse:   "refuse",
+}
+
+// PreIdModeNames 返回所有可用的计数方式名称
+func PreIdModeNames() []string {
+	return []string{"replace", "reset", "continue", "refuse"}
+}
+
+func (m PreIdMode) String() string {
+	if name, ok := preIdModeNames[m]; ok {
+		return name
+	}
+	return fmt.Sprintf("PreIdMode(%d)", int(m))
+}
+
+// ParsePreIdMode 将字符串解析为计数方式，空字符串为默认的 replace
+func ParsePreIdMode(s string) (PreIdMode, error) {
+	input := strings.ToLower(strings.TrimSpace(s))
+	if input == "" {
+		return PreIdReplace, nil
+	}
+	for mode, name := range preIdModeNames {
+		if name == input {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown preid mode %q, valid values are %s", s, strings.Join(PreIdModeNames(), ", "))
+}
@@ -0,0 +1,132 @@
+package semver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrUnknownReleaseType = errors.New("unknown release type")
+
+// releaseTypeNames 版本变动类型名称，与 npm semver 的 ReleaseType 保持一致
+var releaseTypeNames = []struct {
+	changed VersionChanged
+	name    string
+}{
+	{Major, "major"},
+	{PreMajor, "premajor"},
+	{Minor, "minor"},
+	{PreMinor, "preminor"},
+	{Patch, "patch"},
+	{PrePatch, "prepatch"},
+	{PreRelease, "prerelease"},
+}
+
+// ReleaseTypeError 版本变动类型解析错误
+type ReleaseTypeError struct {
+	Input      string // 原始输入
+	Suggestion string // 最相近的类型名称，可能为空
+}
+
+func (e *ReleaseTypeError) Error() string {
+	if e.Suggestion != "" {
+		return fmt.Sprintf("%s %q, did you mean %q?", ErrUnknownReleaseType, e.Input, e.Suggestion)
+	}
+	return fmt.Sprintf("%s %q, valid values are %s", ErrUnknownReleaseType, e.Input, strings.Join(ReleaseTypeNames(), ", "))
+}
+
+func (e *ReleaseTypeError) Unwrap() error {
+	return ErrUnknownReleaseType
+}
+
+// String 返回版本变动类型名称
+func (c VersionChanged) String() string {
+	for _, n := range releaseTypeNames {
+		if n.changed == c {
+			return n.name
+		}
+	}
+	return fmt.Sprintf("VersionChanged(%d)", int(c))
+}
+
+// ReleaseTypeNames 返回所有可用的版本变动类型名称
+func ReleaseTypeNames() []string {
+	names := make([]string, 0, len(releaseTypeNames))
+	for _, n := range releaseTypeNames {
+		names = append(names, n.name)
+	}
+	return names
+}
+
+// ParseReleaseType 将字符串（不区分大小写）解析为版本变动类型，无法识别时返回 *ReleaseTypeError
+func ParseReleaseType(s string) (VersionChanged, error) {
+	input := strings.ToLower(strings.TrimSpace(s))
+	for _, n := range releaseTypeNames {
+		if n.name == input {
+			return n.changed, nil
+		}
+	}
+	return 0, &ReleaseTypeError{Input: s, Suggestion: suggestReleaseType(input)}
+}
+
+// WithReleaseType 指定版本变动类型
+func WithReleaseType(changed VersionChanged) Option {
+	return func(options *options) error {
+		options.changed = changed
+		return nil
+	}
+}
+
+// 编辑距离不超过 2 或名称长度的三分之一时给出建议
+func suggestReleaseType(input string) string {
+	suggestion, min := "", -1
+	for _, n := range releaseTypeNames {
+		d := editDistance(input, n.name)
+		if d <= maxInt(2, len(n.name)/3) && (min == -1 || d < min) {
+			suggestion, min = n.name, d
+		}
+	}
+	return suggestion
+}
+
+// 编辑距离（相邻字符交换计为一次编辑）
+func editDistance(a, b string) int {
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := 0; j <= len(b); j++ {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			d[i][j] = minInt(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				d[i][j] = minInt(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(a)][len(b)]
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func minInt(values ...int) int {
+	m := values[0]
+	for _, v := range values[1:] {
+		if v < m {
+			m = v
+		}
+	}
+	return m
+}
@@ -0,0 +1,42 @@
+package semver
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseReleaseType(t *testing.T) {
+	for _, name := range ReleaseTypeNames() {
+		changed, err := ParseReleaseType(name)
+		if err != nil {
+			t.Fatalf("parse %s failed: %v", name, err)
+		}
+		if changed.String() != name {
+			t.Errorf("expected %s, but %s got", name, changed.String())
+		}
+	}
+
+	if changed, _ := ParseReleaseType(" Minor "); changed != Minor {
+		t.Errorf("expected minor, but %s got", changed)
+	}
+
+	tests := []struct {
+		input      string
+		suggestion string
+	}{
+		{"prepach", "prepatch"},
+		{"mnior", "minor"},
+		{"pre-release", "prerelease"},
+		{"hotfix", ""},
+	}
+	for _, test := range tests {
+		_, err := ParseReleaseType(test.input)
+		var typeErr *ReleaseTypeError
+		if !errors.As(err, &typeErr) || !errors.Is(err, ErrUnknownReleaseType) {
+			t.Fatalf("parse %s expected *ReleaseTypeError, but %v got", test.input, err)
+		}
+		if typeErr.Suggestion != test.suggestion {
+			t.Errorf("parse %s expected suggestion %q, but %q got", test.input, test.suggestion, typeErr.Suggestion)
+		}
+	}
+}
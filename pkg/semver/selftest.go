@@ -0,0 +1,82 @@
+package semver
+
+import (
+	"bufio"
+	"embed"
+	"fmt"
+	"strings"
+)
+
+//go:embed corpus/*.txt
+var corpus embed.FS
+
+// Corpus semver.org 的版本号语料，valid 为合法版本号，invalid 为非法版本号
+func Corpus() (valid, invalid []string) {
+	return readCorpus("corpus/valid.txt"), readCorpus("corpus/invalid.txt")
+}
+
+func readCorpus(name string) []string {
+	f, err := corpus.Open(name)
+	if err != nil {
+		panic(err)
+	}
+	defer f.Close()
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// incrementTypes 参与递增单调性检查的版本变动类型
+var incrementTypes = []VersionChanged{Major, Minor, Patch, PreMajor, PreMinor, PrePatch, PreRelease}
+
+// SelfTest 使用 semver.org 语料检查解析、比较及递增的基本性质，返回检查项数量及所有失败项：
+// 解析后 String 与原字符串一致，非法版本号解析失败，Compare 为全序关系，递增后的版本大于原版本
+func SelfTest() (int, []error) {
+	valid, invalid := Corpus()
+	checks, failures := 0, make([]error, 0)
+	check := func(ok bool, format string, args ...interface{}) {
+		checks++
+		if !ok {
+			failures = append(failures, fmt.Errorf(format, args...))
+		}
+	}
+	versions := make([]Semver, 0, len(valid))
+	for _, s := range valid {
+		v, err := Version(s)
+		check(err == nil, "parse %s: %v", s, err)
+		if err != nil {
+			continue
+		}
+		check(v.String() == s, "parse %s: String() returns %s", s, v.String())
+		versions = append(versions, v)
+	}
+	for _, s := range invalid {
+		_, err := Version(s)
+		check(err != nil, "parse %s: expected error", s)
+	}
+	for _, a := range versions {
+		check(a.Compare(a) == 0, "compare %s with itself", a)
+		for _, b := range versions {
+			check(a.Compare(b) == -b.Compare(a), "compare %s and %s is not antisymmetric", a, b)
+			for _, c := range versions {
+				if a.Compare(b) <= 0 && b.Compare(c) <= 0 {
+					check(a.Compare(c) <= 0, "compare %s <= %s <= %s is not transitive", a, b, c)
+				}
+			}
+		}
+	}
+	for _, v := range versions {
+		for _, changed := range incrementTypes {
+			before := v.String()
+			next := v.Increment(WithReleaseType(changed))
+			check(next.Compare(v) > 0, "increment %s %s returns %s", changed, v, next)
+			check(v.String() == before, "increment %s %s modifies the version to %s", changed, before, v)
+		}
+	}
+	return checks, failures
+}
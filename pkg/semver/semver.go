@@ -2,6 +2,7 @@ package semver
 
 import (
 	"errors"
+	"fmt"
 	"github.com/coffee377/autoctl/pkg/log"
 	"regexp"
 	"strconv"
@@ -10,6 +11,7 @@ import (
 type options struct {
 	changed    VersionChanged
 	identifier PreReleaseIdentifier
+	preIdMode  PreIdMode
 	//identifierBase bool
 }
 
@@ -24,6 +26,7 @@ type Semver interface {
 	Build() []Identifier
 
 	Increment(opts ...Option) Semver
+	IncrementE(opts ...Option) (Semver, error)
 
 	IncrementMajor() Semver
 	IncrementMinor() Semver
@@ -35,10 +38,23 @@ type Semver interface {
 
 	IncrementPreRelease(identifier PreReleaseIdentifier) Semver
 
+	WithMajor(major uint64) Semver
+	WithMinor(minor uint64) Semver
+	WithPatch(patch uint64) Semver
+	WithPreRelease(identifiers ...Identifier) Semver
+	WithBuild(identifiers ...Identifier) Semver
+
 	String() string
 	FinalizeVersion() string
+	Format(layout string) string
 	Compare(other Semver) int
 	CompareWithBuildMeta(other Semver) int
+	CompareWith(other Semver, opts CompareOptions) int
+
+	Equal(other Semver) bool
+	EqualWith(other Semver, opts CompareOptions) bool
+	Key() string
+	Comparable() Comparable
 }
 
 type version struct {
@@ -50,27 +66,69 @@ type version struct {
 	options    *options     // 配置选项
 }
 
+// versionReg 预编译的版本号正则，解析大量标签时避免重复编译
+var versionReg = regexp.MustCompile(VersionReg)
+
+var errInvalidVersion = errors.New("the version number does not match the semantic version number, please refer to https://semver.org/lang/zh-CN/")
+
 // parse parses version string and returns a validated Semver or error
 func parse(ver string) (version, error) {
-	reg := regexp.MustCompile(VersionReg)
-	if !reg.MatchString(ver) {
-		return version{}, errors.New("the version number does not match the semantic version number, please refer to https://semver.org/lang/zh-CN/")
+	if v, ok := parseCore(ver); ok {
+		return v, nil
+	}
+	match := versionReg.FindStringSubmatch(ver)
+	if match == nil {
+		return version{}, errInvalidVersion
 	}
-	match := reg.FindStringSubmatch(ver)
 	v := version{options: &options{}}
-	v.major, _ = strconv.ParseUint(match[1], 10, 64)
-	v.minor, _ = strconv.ParseUint(match[2], 10, 64)
-	v.patch, _ = strconv.ParseUint(match[3], 10, 64)
-
+	var err error
+	for i, n := range []*uint64{&v.major, &v.minor, &v.patch} {
+		if *n, err = strconv.ParseUint(match[i+1], 10, 64); err != nil {
+			return version{}, &IdentifierError{Identifier: match[i+1], Err: ErrIdentifierOverflow}
+		}
+	}
 	if match[4] != "" {
-		v.preRelease = parseIdentifiers(match[4])
+		if v.preRelease, err = parseIdentifiers(match[4], false); err != nil {
+			return version{}, err
+		}
 	}
 	if match[5] != "" {
-		v.build = parseIdentifiers(match[5])
+		if v.build, err = parseIdentifiers(match[5], true); err != nil {
+			return version{}, err
+		}
 	}
 	return v, nil
 }
 
+// parseCore 手写解析最常见的 X.Y.Z 形式，不含先行版本号及编译信息，其它形式或数值溢出时返回 false 交由正则解析
+func parseCore(ver string) (version, bool) {
+	var nums [3]uint64
+	part, start := 0, 0
+	for i := 0; i <= len(ver); i++ {
+		if i < len(ver) && ver[i] >= '0' && ver[i] <= '9' {
+			continue
+		}
+		if i < len(ver) && (ver[i] != '.' || part == 2) {
+			return version{}, false
+		}
+		digits := ver[start:i]
+		// 不允许为空及前导零
+		if len(digits) == 0 || len(digits) > 1 && digits[0] == '0' {
+			return version{}, false
+		}
+		n, err := strconv.ParseUint(digits, 10, 64)
+		if err != nil {
+			return version{}, false
+		}
+		nums[part] = n
+		part, start = part+1, i+1
+	}
+	if part != 3 {
+		return version{}, false
+	}
+	return version{major: nums[0], minor: nums[1], patch: nums[2], options: &options{}}, true
+}
+
 // Version is an alias for Parse and returns a pointer, parses version string and returns a validated Semver or error
 func Version(version string) (Semver, error) {
 	v, err := parse(version)
@@ -101,11 +159,34 @@ func (v *version) Build() []Identifier {
 	return v.build
 }
 
-// Increment increments the version
+// Increment increments the version，选项无效时记录警告并返回原版本
 func (v *version) Increment(opts ...Option) Semver {
-	ver := *v
-	increment(&ver, opts...)
-	return &ver
+	ver, err := v.IncrementE(opts...)
+	if err != nil {
+		log.Warn("%s", err)
+		return v.clone()
+	}
+	return ver
+}
+
+// IncrementE 与 Increment 相同，但选项或预发布版本标识符无效时返回错误，避免生成 1.2.3-beta..1 这样的无效版本
+func (v *version) IncrementE(opts ...Option) (Semver, error) {
+	// 复制选项及先行版本号，避免修改原版本
+	ver := v.clone()
+	for _, opt := range opts {
+		if err := opt(ver.options); err != nil {
+			return nil, err
+		}
+	}
+	if ver.options.changed&pre != 0 || ver.options.changed == PreRelease {
+		if err := ver.options.identifier.Validate(); err != nil {
+			return nil, err
+		}
+	}
+	if err := increment(ver); err != nil {
+		return nil, err
+	}
+	return ver, nil
 }
 
 func (v *version) IncrementMajor() Semver {
@@ -141,22 +222,12 @@ func (v *version) String() string {
 
 	if len(v.preRelease) > 0 {
 		buffer = append(buffer, '-')
-		buffer = append(buffer, v.preRelease[0].Raw...)
-
-		for _, pre := range v.preRelease[1:] {
-			buffer = append(buffer, '.')
-			buffer = append(buffer, pre.Raw...)
-		}
+		buffer = append(buffer, IdentifierString(v.preRelease)...)
 	}
 
 	if len(v.build) > 0 {
 		buffer = append(buffer, '+')
-		buffer = append(buffer, v.build[0].Raw...)
-
-		for _, build := range v.build[1:] {
-			buffer = append(buffer, '.')
-			buffer = append(buffer, build.Raw...)
-		}
+		buffer = append(buffer, IdentifierString(v.build)...)
 	}
 
 	return string(buffer)
@@ -168,12 +239,19 @@ func (v *version) FinalizeVersion() string {
 	return string(b)
 }
 
+// Compare 按语义化版本规范比较，忽略构建信息
 func (v *version) Compare(other Semver) int {
-	return compareVersion(v, other, true)
+	return compareVersion(v, other, CompareOptions{})
 }
 
+// CompareWithBuildMeta 先行版本号相同时继续比较构建信息
 func (v *version) CompareWithBuildMeta(other Semver) int {
-	return compareVersion(v, other, false)
+	return compareVersion(v, other, CompareOptions{BuildMeta: true})
+}
+
+// CompareWith 使用指定的比较选项进行比较
+func (v *version) CompareWith(other Semver, opts CompareOptions) int {
+	return compareVersion(v, other, opts)
 }
 
 // 判断是否是预发版本
@@ -205,18 +283,49 @@ func lastNumberIdentifierIncrease(v *version) {
 		if identifier.IsNumeric {
 			found = true
 			// 递增版本号
-			v.preRelease[l-i-1] = NewIdentifier(strconv.FormatUint(identifier.Num+1, 10))
+			v.preRelease[l-i-1] = IdentifierFromUint(identifier.Num + 1)
 			break
 		}
 	}
 	// 未找到含有数字的 Identifier
 	// 如果PreRelease数组中未找到数字类型，则在数组后追加 base
 	if !found {
-		v.preRelease = append(v.preRelease, NewIdentifier("1"))
+		v.preRelease = append(v.preRelease, IdentifierFromUint(1))
+	}
+}
+
+// 最后一个数字类型的 Identifier，不存在时返回 0
+func lastNumberIdentifier(identifiers []Identifier) (uint64, bool) {
+	for i := len(identifiers) - 1; i >= 0; i-- {
+		if identifiers[i].IsNumeric {
+			return identifiers[i].Num, true
+		}
+	}
+	return 0, false
+}
+
+// 切换为更高的预发布标识符，如 alpha.3 => beta，计数方式由 PreIdMode 决定
+func switchPreReleaseIdentifier(v *version, identifiers []Identifier) error {
+	// 新的标识符已显式包含计数
+	_, explicit := lastNumberIdentifier(identifiers)
+	switch v.options.preIdMode {
+	case PreIdReset:
+		if !explicit {
+			identifiers = append(identifiers, IdentifierFromUint(0))
+		}
+	case PreIdContinue:
+		if !explicit {
+			num, _ := lastNumberIdentifier(v.preRelease)
+			identifiers = append(identifiers, IdentifierFromUint(num+1))
+		}
+	case PreIdRefuse:
+		return fmt.Errorf("%w: %s to %s", ErrPreIdSwitchRefused, v.preRelease[0].Raw, identifiers[0].Raw)
 	}
+	v.preRelease = identifiers
+	return nil
 }
 
-func increment(v *version, opts ...Option) Semver {
+func increment(v *version, opts ...Option) error {
 	for _, opt := range opts {
 		_ = opt(v.options)
 	}
@@ -228,29 +337,25 @@ func increment(v *version, opts ...Option) Semver {
 		v.minor = 0
 		v.major++
 
-		increment(v, withPre())
-		break
+		return increment(v, withPre())
 	case PreMinor:
 		v.resetPreRelease()
 		v.patch = 0
 		v.minor++
 
-		increment(v, withPre())
-		break
+		return increment(v, withPre())
 	case PrePatch:
 		// 如果这已经是一个预发行版，它将会在下一个版本中删除任何可能已经存在的预发行版，因为它们在这一点上是不相关的
 		v.resetPreRelease()
 
-		increment(v, WithPatch())
-		increment(v, withPre())
-		break
+		_ = increment(v, WithPatch())
+		return increment(v, withPre())
 	case PreRelease:
 		// 如果输入是一个非预发布版本，其作用与 PrePatch 相同
 		if !v.isPreRelease() {
-			increment(v, WithPatch())
+			_ = increment(v, WithPatch())
 		}
-		increment(v, withPre())
-		break
+		return increment(v, withPre())
 	case Major:
 		// 如果这是一个 pre-major 版本，升级到相同的 major 版本，否则递增 major
 		// 1.0.0-5 => 1.0.0
@@ -280,9 +385,10 @@ func increment(v *version, opts ...Option) Semver {
 		v.resetPreRelease()
 	case pre:
 		identifier := v.options.identifier
-		identifiers := parseIdentifiers("0")
+		identifiers := []Identifier{IdentifierFromUint(0)}
 		if identifier != "" {
-			identifiers = parseIdentifiers(string(identifier))
+			// 标识符已在 IncrementE 中校验
+			identifiers, _ = parseIdentifiers(string(identifier), false)
 		}
 		// 不是预发版本
 		if !v.isPreRelease() {
@@ -293,15 +399,15 @@ func increment(v *version, opts ...Option) Semver {
 				if compare == 0 {
 					lastNumberIdentifierIncrease(v)
 				} else if compare == -1 {
-					v.preRelease = identifiers
+					return switchPreReleaseIdentifier(v, identifiers)
 				} else if compare == 1 {
 					// 不处理，使用原版本号，日志警告提示
 					log.Warn("预发布版本不能进行降级，因为 %s < %s", identifiers[0].Raw, v.preRelease[0].Raw)
 				}
-				return v
+				return nil
 			}
 			lastNumberIdentifierIncrease(v)
 		}
 	}
-	return v
+	return nil
 }
@@ -1,6 +1,8 @@
 package semver
 
 import (
+	"errors"
+	"regexp"
 	"testing"
 )
 
@@ -84,3 +86,152 @@ func TestVersion_IncrementMajor(t *testing.T) {
 		t.Errorf("IncrementPrePatch 错误")
 	}
 }
+
+func TestVersion_CompareWith(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		opts     CompareOptions
+		expected int
+	}{
+		{"1.10.0", "1.9.0", DefaultCompareOptions, 1},
+		{"1.0.0+build.2", "1.0.0+build.1", DefaultCompareOptions, 0},
+		{"1.0.0+build.2", "1.0.0+build.1", CompareOptions{BuildMeta: true}, 1},
+		{"1.0.0-RC.1", "1.0.0-rc.1", DefaultCompareOptions, -1},
+		{"1.0.0-RC.1", "1.0.0-rc.1", CompareOptions{CaseInsensitive: true}, 0},
+		{"1.0.0-alpha10", "1.0.0-alpha9", DefaultCompareOptions, -1},
+		{"1.0.0-alpha10", "1.0.0-alpha9", CompareOptions{NumericAware: true}, 1},
+		{"1.0.0-Beta2", "1.0.0-beta10", CompareOptions{CaseInsensitive: true, NumericAware: true}, -1},
+	}
+	for _, test := range tests {
+		a, _ := Version(test.a)
+		b, _ := Version(test.b)
+		if actual := a.CompareWith(b, test.opts); actual != test.expected {
+			t.Errorf("%s compare %s with %+v, expected %d, but %d got", test.a, test.b, test.opts, test.expected, actual)
+		}
+	}
+}
+
+func TestVersion_IncrementPreIdMode(t *testing.T) {
+	tests := []data{
+		{"1.2.0-alpha.3", []Option{WithPreRelease(), WithIdentifier(beta)}, "1.2.0-beta"},
+		{"1.2.0-alpha.3", []Option{WithPreRelease(), WithIdentifier(beta), WithPreIdMode(PreIdReplace)}, "1.2.0-beta"},
+		{"1.2.0-alpha.3", []Option{WithPreRelease(), WithIdentifier(beta), WithPreIdMode(PreIdReset)}, "1.2.0-beta.0"},
+		{"1.2.0-alpha.3", []Option{WithPreRelease(), WithIdentifier(beta), WithPreIdMode(PreIdContinue)}, "1.2.0-beta.4"},
+		{"1.2.0-alpha", []Option{WithPreRelease(), WithIdentifier(beta), WithPreIdMode(PreIdContinue)}, "1.2.0-beta.1"},
+		{"1.2.0-alpha.3", []Option{WithPreRelease(), WithIdentifier("beta.7"), WithPreIdMode(PreIdContinue)}, "1.2.0-beta.7"},
+		{"1.2.0-beta.3", []Option{WithPreRelease(), WithIdentifier(alpha), WithPreIdMode(PreIdContinue)}, "1.2.0-beta.3"},
+	}
+	for _, test := range tests {
+		semver, _ := Version(test.version)
+		result := semver.Increment(test.opts...)
+		if result.String() != test.expected {
+			t.Errorf("version number '%s' increment, expected '%s', but '%s' got", test.version, test.expected, result.String())
+		}
+		if semver.String() != test.version {
+			t.Errorf("version number '%s' should not be modified by increment, but '%s' got", test.version, semver.String())
+		}
+	}
+
+	refused, _ := Version("1.2.0-alpha.3")
+	if next, err := refused.IncrementE(WithPreRelease(), WithIdentifier(beta), WithPreIdMode(PreIdRefuse)); !errors.Is(err, ErrPreIdSwitchRefused) {
+		t.Errorf("expected ErrPreIdSwitchRefused, but %v %v got", next, err)
+	}
+	// 同一标识符仍可递增
+	if next, err := refused.IncrementE(WithPreRelease(), WithIdentifier(alpha), WithPreIdMode(PreIdRefuse)); err != nil || next.String() != "1.2.0-alpha.4" {
+		t.Errorf("expected 1.2.0-alpha.4, but %v %v got", next, err)
+	}
+
+	if _, err := ParsePreIdMode("restart"); err == nil {
+		t.Error("expected unknown preid mode error, got nil")
+	}
+	if mode, _ := ParsePreIdMode("Continue"); mode != PreIdContinue {
+		t.Errorf("expected continue, but %s got", mode)
+	}
+}
+
+func TestVersion_Parse(t *testing.T) {
+	tests := []struct {
+		version string
+		ok      bool
+	}{
+		{"0.0.0", true},
+		{"1.2.3", true},
+		{"10.20.30", true},
+		{"1.2.3-rc.1+build.5", true},
+		{"1.2", false},
+		{"1.2.3.", false},
+		{"1..3", false},
+		{"01.2.3", false},
+		{"1.02.3", false},
+		{"v1.2.3", false},
+		{"1.2.3 ", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		v, err := Version(test.version)
+		if (err == nil) != test.ok {
+			t.Errorf("%q: expected ok %v, but err %v got", test.version, test.ok, err)
+			continue
+		}
+		if err == nil && v.String() != test.version {
+			t.Errorf("%q: expected same string, but %s got", test.version, v.String())
+		}
+	}
+	// 快速路径与正则解析的结果一致
+	fast, _ := parseCore("18446744073709551615.3.4")
+	if fast.major != 18446744073709551615 || fast.minor != 3 || fast.patch != 4 {
+		t.Errorf("unexpected fast path result %+v", fast)
+	}
+	if _, ok := parseCore("18446744073709551616.0.0"); ok {
+		t.Error("expected overflow delegated to the regexp parser")
+	}
+}
+
+func BenchmarkVersion_Core(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Version("12.34.56"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVersion_PreRelease(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, err := Version("12.34.56-rc.1+build.5"); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkVersion_Regexp 每次编译正则的解析方式，作为对比
+func BenchmarkVersion_Regexp(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if !regexp.MustCompile(VersionReg).MatchString("12.34.56") {
+			b.Fatal("not matched")
+		}
+	}
+}
+
+func TestVersion_IncrementE(t *testing.T) {
+	v, _ := Version("1.2.3")
+	for _, identifier := range []PreReleaseIdentifier{"beta..1", "beta.01", "beta_1", "."} {
+		if next, err := v.IncrementE(WithPreReleaseIdentifier(identifier)); err == nil {
+			t.Errorf("expected error for identifier %q, but %s got", identifier, next)
+		}
+		if next := v.Increment(WithPrePatchIdentifier(identifier)); next.String() != "1.2.3" {
+			t.Errorf("expected invalid identifier %q to keep 1.2.3, but %s got", identifier, next)
+		}
+	}
+	next, err := v.IncrementE(WithPreMinorIdentifier("beta.1"))
+	if err != nil || next.String() != "1.3.0-beta.1" {
+		t.Errorf("expected 1.3.0-beta.1, but %v %v got", next, err)
+	}
+	// 非预发布递增不使用标识符
+	next, err = v.IncrementE(WithMinor(), WithIdentifier("beta..1"))
+	if err != nil || next.String() != "1.3.0" {
+		t.Errorf("expected 1.3.0, but %v %v got", next, err)
+	}
+}
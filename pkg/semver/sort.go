@@ -10,6 +10,27 @@ func (semver Versions) Len() int           { return len(semver) }
 func (semver Versions) Less(i, j int) bool { return semver[i].Compare(semver[j]) == -1 }
 func (semver Versions) Swap(i, j int)      { semver[i], semver[j] = semver[j], semver[i] }
 
+// SortWith 使用指定的比较选项排序
+func (semver Versions) SortWith(opts CompareOptions, desc bool) {
+	s := versionSorter{versions: semver, opts: opts}
+	if desc {
+		sort.Stable(sort.Reverse(s))
+	} else {
+		sort.Stable(s)
+	}
+}
+
+type versionSorter struct {
+	versions Versions
+	opts     CompareOptions
+}
+
+func (s versionSorter) Len() int { return len(s.versions) }
+func (s versionSorter) Less(i, j int) bool {
+	return s.versions[i].CompareWith(s.versions[j], s.opts) == -1
+}
+func (s versionSorter) Swap(i, j int) { s.versions.Swap(i, j) }
+
 func (semver Versions) Sort()     { semver.SortAsc() }
 func (semver Versions) SortAsc()  { semver.sort(false) }
 func (semver Versions) SortDesc() { semver.sort(true) }
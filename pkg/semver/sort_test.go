@@ -103,3 +103,18 @@ func BenchmarkVersionSort(b *testing.B) {
 	}
 	b.StopTimer()
 }
+
+func TestVersionsSortWith(t *testing.T) {
+	expect := []string{"1.0.0-rc9", "1.0.0-RC10", "1.0.0-rc11"}
+	v := make(Versions, 0, len(expect))
+	for _, version := range []string{"1.0.0-rc11", "1.0.0-rc9", "1.0.0-RC10"} {
+		semver, _ := Version(version)
+		v = append(v, semver)
+	}
+	v.SortWith(CompareOptions{CaseInsensitive: true, NumericAware: true}, false)
+	for i, version := range v {
+		if version.String() != expect[i] {
+			t.Errorf("index %d expected %s, but %s got", i, expect[i], version.String())
+		}
+	}
+}
@@ -1,14 +1,29 @@
 package semver
 
 import (
-	"strconv"
 	"strings"
 )
 
+// CompareOptions 版本比较选项
+type CompareOptions struct {
+	BuildMeta       bool // 先行版本号相同时是否继续比较构建信息，默认忽略构建信息
+	CaseInsensitive bool // 字母数字混合标识符是否忽略大小写比较，如 RC.1 == rc.1
+	NumericAware    bool // 字母数字混合标识符中的连续数字按数值比较，如 alpha9 < alpha10
+}
+
+// DefaultCompareOptions 语义化版本规范定义的比较方式
+var DefaultCompareOptions = CompareOptions{}
+
 func compare(a, b uint64) int {
-	return strings.Compare(strconv.FormatUint(a, 10), strconv.FormatUint(b, 10))
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
 }
-func compareIdentifier(a, b []Identifier) int {
+
+func compareIdentifier(a, b []Identifier, opts CompareOptions) int {
 	// Quick comparison if a version has no prerelease versions
 	if len(a) == 0 && len(b) == 0 {
 		return 0
@@ -21,7 +36,7 @@ func compareIdentifier(a, b []Identifier) int {
 	i := 0
 	// compareNum := math.Min(len(version.PreRelease), len(other.PreRelease))
 	for ; i < len(a) && i < len(b); i++ {
-		if comp := a[i].Compare(b[i]); comp == 0 {
+		if comp := a[i].CompareWith(b[i], opts); comp == 0 {
 			continue
 		} else {
 			return comp
@@ -39,16 +54,53 @@ func compareIdentifier(a, b []Identifier) int {
 }
 
 // 比较先行版本号
-func comparePre(a, b Semver) int {
-	return compareIdentifier(a.PreRelease(), b.PreRelease())
+func comparePre(a, b Semver, opts CompareOptions) int {
+	return compareIdentifier(a.PreRelease(), b.PreRelease(), opts)
 }
 
 // 比较构建信息
-func compareBuild(a, b Semver) int {
-	return compareIdentifier(a.Build(), b.Build())
+func compareBuild(a, b Semver, opts CompareOptions) int {
+	return compareIdentifier(a.Build(), b.Build(), opts)
+}
+
+// 自然顺序比较字符串，连续的数字按数值大小比较
+func compareNatural(a, b string) int {
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		if isDigit(a[i]) && isDigit(b[j]) {
+			si, sj := i, j
+			for i < len(a) && isDigit(a[i]) {
+				i++
+			}
+			for j < len(b) && isDigit(b[j]) {
+				j++
+			}
+			na, nb := strings.TrimLeft(a[si:i], "0"), strings.TrimLeft(b[sj:j], "0")
+			if len(na) != len(nb) {
+				return compare(uint64(len(na)), uint64(len(nb)))
+			}
+			if c := strings.Compare(na, nb); c != 0 {
+				return c
+			}
+			continue
+		}
+		if a[i] != b[j] {
+			if a[i] < b[j] {
+				return -1
+			}
+			return 1
+		}
+		i++
+		j++
+	}
+	return compare(uint64(len(a)-i), uint64(len(b)-j))
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
 }
 
-func compareVersion(a Semver, b Semver, ignoreBuildMeta bool) int {
+func compareVersion(a Semver, b Semver, opts CompareOptions) int {
 	// 主版本号比较
 	major := compare(a.Major(), b.Major())
 	if major != 0 {
@@ -68,10 +120,10 @@ func compareVersion(a Semver, b Semver, ignoreBuildMeta bool) int {
 	}
 
 	// 先行版本号比较
-	pre := comparePre(a, b)
+	pre := comparePre(a, b, opts)
 
-	if !ignoreBuildMeta && pre == 0 {
-		return compareBuild(a, b)
+	if opts.BuildMeta && pre == 0 {
+		return compareBuild(a, b, opts)
 	}
 
 	return pre
@@ -180,6 +232,14 @@ func WithIdentifier(identifier PreReleaseIdentifier) Option {
 	}
 }
 
+// WithPreIdMode 指定预发布版本切换标识符时的计数方式
+func WithPreIdMode(mode PreIdMode) Option {
+	return func(options *options) error {
+		options.preIdMode = mode
+		return nil
+	}
+}
+
 func WithAlpha() Option {
 	return WithIdentifier(alpha)
 }